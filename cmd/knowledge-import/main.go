@@ -0,0 +1,281 @@
+// Command knowledge-import uploads a CSV, JSON or GraphML file to the
+// knowledge import endpoint of a workspace and follows the import until it
+// finishes.
+//
+//	knowledge-import -workspace <id> -mapping people.json people.csv
+//
+// The server and access token default to the RKE_SERVER and RKE_TOKEN
+// environment variables. The exit status is 1 when the import failed or
+// skipped rows, so that it can be used in scripts.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/module/knowledge"
+)
+
+const defaultServer = "http://localhost:3000/api/v1"
+
+type options struct {
+	server    string
+	token     string
+	workspace string
+	mapping   string
+	format    string
+	dryRun    bool
+	wait      bool
+	interval  time.Duration
+	file      string
+}
+
+// response is the envelope every endpoint answers with.
+type response[T any] struct {
+	Message string `json:"message"`
+	Error   string `json:"error"`
+	Data    T      `json:"data"`
+}
+
+func main() {
+	opts, err := parseOptions(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	ok, err := run(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "knowledge-import: %v\n", err)
+		os.Exit(1)
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+func parseOptions(args []string) (*options, error) {
+	opts := &options{}
+
+	flags := flag.NewFlagSet("knowledge-import", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: knowledge-import -workspace <id> [flags] <file>")
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&opts.server, "server", envOr("RKE_SERVER", defaultServer), "API base URL")
+	flags.StringVar(&opts.token, "token", os.Getenv("RKE_TOKEN"), "access token")
+	flags.StringVar(&opts.workspace, "workspace", "", "workspace ID")
+	flags.StringVar(&opts.mapping, "mapping", "", "JSON file with the mapping, required for CSV and JSON files")
+	flags.StringVar(&opts.format, "format", "", "csv, json or graphml; detected from the file name by default")
+	flags.BoolVar(&opts.dryRun, "dry-run", false, "only check the file against the workspace schema")
+	flags.BoolVar(&opts.wait, "wait", true, "follow the import until it finishes")
+	flags.DurationVar(&opts.interval, "interval", 2*time.Second, "how often to check the progress")
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return nil, fmt.Errorf("exactly one file is required")
+	}
+	opts.file = flags.Arg(0)
+
+	if opts.workspace == "" {
+		return nil, fmt.Errorf("-workspace is required")
+	}
+	if opts.token == "" {
+		return nil, fmt.Errorf("-token or RKE_TOKEN is required")
+	}
+	if opts.interval <= 0 {
+		return nil, fmt.Errorf("-interval must be positive")
+	}
+
+	return opts, nil
+}
+
+// run uploads the file and, when waiting, reports the import once it has
+// finished. It reports whether every row was imported.
+func run(opts *options) (bool, error) {
+	client := &http.Client{}
+
+	job, err := upload(client, opts)
+	if err != nil {
+		return false, err
+	}
+	fmt.Printf("Import %s queued\n", job.ID.Hex())
+
+	if !opts.wait {
+		return true, nil
+	}
+
+	for job.Status == knowledge.ImportStatusQueued || job.Status == knowledge.ImportStatusProcessing {
+		time.Sleep(opts.interval)
+
+		if job, err = get[knowledge.ImportJob](client, opts, "/"+job.ID.Hex()); err != nil {
+			return false, err
+		}
+		printProgress(job)
+	}
+
+	if job.Status == knowledge.ImportStatusFailed {
+		fmt.Printf("Import failed: %s\n", job.Error)
+		return false, nil
+	}
+
+	if job.Progress.Errors > 0 {
+		report, err := get[knowledge.ImportErrors](client, opts, "/"+job.ID.Hex()+"/errors")
+		if err != nil {
+			return false, err
+		}
+		printErrors(report)
+	}
+
+	if job.DryRun {
+		fmt.Printf("Dry run finished: %d entities and %d relations would be imported, %d errors\n",
+			job.Progress.Entities, job.Progress.Relations, job.Progress.Errors)
+	} else {
+		fmt.Printf("Import finished: %d entities (%d new) and %d relations (%d new) imported, %d errors\n",
+			job.Progress.Entities, job.Progress.EntitiesCreated, job.Progress.Relations, job.Progress.RelationsCreated, job.Progress.Errors)
+	}
+
+	return job.Progress.Errors == 0, nil
+}
+
+// upload streams the file as multipart form data, without holding it in
+// memory.
+func upload(client *http.Client, opts *options) (*knowledge.ImportJob, error) {
+	var mapping []byte
+	if opts.mapping != "" {
+		data, err := os.ReadFile(opts.mapping)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mapping: %w", err)
+		}
+		var check knowledge.ImportMapping
+		if err := json.Unmarshal(data, &check); err != nil {
+			return nil, fmt.Errorf("invalid mapping %s: %w", opts.mapping, err)
+		}
+		mapping = data
+	}
+
+	file, err := os.Open(opts.file)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		writer.CloseWithError(writeForm(form, file, opts, mapping))
+	}()
+
+	req, err := http.NewRequest(http.MethodPost, endpoint(opts, ""), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	return do[knowledge.ImportJob](client, opts, req)
+}
+
+func writeForm(form *multipart.Writer, file io.Reader, opts *options, mapping []byte) error {
+	fields := map[string]string{"dry_run": strconv.FormatBool(opts.dryRun)}
+	if opts.format != "" {
+		fields["format"] = opts.format
+	}
+	if mapping != nil {
+		fields["mapping"] = string(mapping)
+	}
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			return err
+		}
+	}
+
+	part, err := form.CreateFormFile("file", filepath.Base(opts.file))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return err
+	}
+
+	return form.Close()
+}
+
+func get[T any](client *http.Client, opts *options, path string) (*T, error) {
+	req, err := http.NewRequest(http.MethodGet, endpoint(opts, path), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return do[T](client, opts, req)
+}
+
+func do[T any](client *http.Client, opts *options, req *http.Request) (*T, error) {
+	req.Header.Set("Authorization", "Bearer "+opts.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result response[T]
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("unexpected response with status %s: %w", resp.Status, err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		if result.Error != "" {
+			return nil, fmt.Errorf("%s: %s", result.Error, result.Message)
+		}
+		return nil, fmt.Errorf("request failed with status %s: %s", resp.Status, result.Message)
+	}
+
+	return &result.Data, nil
+}
+
+func endpoint(opts *options, path string) string {
+	return strings.TrimRight(opts.server, "/") + "/workspaces/" + url.PathEscape(opts.workspace) + "/knowledge/imports" + path
+}
+
+func printProgress(job *knowledge.ImportJob) {
+	progress := job.Progress
+	fmt.Printf("%s: %d bytes, %d rows read, %d entities, %d relations, %d errors\n",
+		job.Status, progress.BytesRead, progress.Rows, progress.Entities, progress.Relations, progress.Errors)
+}
+
+func printErrors(report *knowledge.ImportErrors) {
+	for _, rowError := range report.Errors {
+		if rowError.Target != "" {
+			fmt.Printf("row %d: %s: %s\n", rowError.Row, rowError.Target, rowError.Message)
+		} else {
+			fmt.Printf("row %d: %s\n", rowError.Row, rowError.Message)
+		}
+	}
+	if report.Truncated {
+		fmt.Printf("... and %d more errors\n", report.Count-len(report.Errors))
+	}
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
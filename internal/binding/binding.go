@@ -0,0 +1,199 @@
+// Package binding parses request bodies and query strings into structs and
+// evaluates their validate tags, so that handlers reject malformed input
+// before it reaches a service.
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Source names the part of the request a struct was bound from.
+type Source string
+
+const (
+	SourceBody  Source = "body"
+	SourceQuery Source = "query"
+)
+
+// FieldError describes one failed validate rule, naming the field as the
+// client sent it.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// Error is returned when a request cannot be bound. Fields is empty when the
+// input could not be parsed at all.
+type Error struct {
+	Source Source
+	Fields []FieldError
+	cause  error
+}
+
+func (e *Error) Error() string {
+	if len(e.Fields) == 0 {
+		return e.cause.Error()
+	}
+
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Field + " " + field.Message
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+var (
+	validate     *validator.Validate
+	validateOnce sync.Once
+)
+
+func validatorInstance() *validator.Validate {
+	validateOnce.Do(func() {
+		validate = validator.New(validator.WithRequiredStructEnabled())
+		validate.RegisterTagNameFunc(fieldName)
+	})
+
+	return validate
+}
+
+// fieldName names fields after the json, query or form key they are read
+// from, in that order.
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "query", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+
+	return field.Name
+}
+
+// Body parses the request body into out, which must be a pointer to a
+// struct, and validates it.
+func Body(c *fiber.Ctx, out any) error {
+	if err := c.BodyParser(out); err != nil {
+		return &Error{Source: SourceBody, cause: err}
+	}
+
+	return validateStruct(SourceBody, out)
+}
+
+// OptionalBody is Body for endpoints whose body may be omitted; an empty body
+// leaves out untouched and is not validated.
+func OptionalBody(c *fiber.Ctx, out any) error {
+	if len(c.Body()) == 0 {
+		return nil
+	}
+
+	return Body(c, out)
+}
+
+// Query parses the query string into out, which must be a pointer to a
+// struct, and validates it.
+func Query(c *fiber.Ctx, out any) error {
+	if err := c.QueryParser(out); err != nil {
+		return &Error{Source: SourceQuery, cause: err}
+	}
+
+	return validateStruct(SourceQuery, out)
+}
+
+func validateStruct(source Source, out any) error {
+	err := validatorInstance().Struct(out)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return &Error{Source: source, cause: fmt.Errorf("failed to validate request: %w", err)}
+	}
+
+	fields := make([]FieldError, len(validationErrors))
+	for i, fieldErr := range validationErrors {
+		fields[i] = FieldError{
+			Field:   fieldErr.Field(),
+			Rule:    fieldErr.Tag(),
+			Param:   fieldErr.Param(),
+			Message: ruleMessage(fieldErr),
+		}
+	}
+
+	return &Error{Source: source, Fields: fields, cause: err}
+}
+
+func ruleMessage(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+
+	var unit string
+	switch fieldErr.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " items"
+	}
+
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid URL"
+	case "min", "gte":
+		return "must be at least " + param + unit
+	case "max", "lte":
+		return "must be at most " + param + unit
+	case "len":
+		return "must be exactly " + param + unit
+	case "mongodb":
+		return "must be a valid ID"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	default:
+		if param != "" {
+			return "must satisfy " + fieldErr.Tag() + "=" + param
+		}
+		return "must satisfy " + fieldErr.Tag()
+	}
+}
+
+// Respond writes err as a 400 response in the format shared by every module:
+// the usual error and message keys plus, for validation failures, the list
+// of offending fields.
+func Respond(c *fiber.Ctx, err error) error {
+	title := "Invalid request"
+
+	var bindErr *Error
+	if errors.As(err, &bindErr) && bindErr.Source == SourceBody {
+		title = "Invalid request body"
+	}
+
+	body := fiber.Map{
+		"error":   title,
+		"message": err.Error(),
+	}
+	if bindErr != nil && len(bindErr.Fields) > 0 {
+		body["fields"] = bindErr.Fields
+	}
+
+	return c.Status(fiber.StatusBadRequest).JSON(body)
+}
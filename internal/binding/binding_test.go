@@ -0,0 +1,157 @@
+package binding
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type signupRequest struct {
+	Email    string   `json:"email" validate:"required,email"`
+	Username string   `json:"username" validate:"required,min=3,max=50"`
+	Tags     []string `json:"tags" validate:"omitempty,max=2"`
+}
+
+type searchQuery struct {
+	Query string `query:"q" validate:"required"`
+	Limit int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type errorResponse struct {
+	Error   string       `json:"error"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields"`
+}
+
+func newTestApp() *fiber.App {
+	app := fiber.New()
+	app.Post("/signup", func(c *fiber.Ctx) error {
+		var req signupRequest
+		if err := Body(c, &req); err != nil {
+			return Respond(c, err)
+		}
+		return c.JSON(req)
+	})
+	app.Post("/optional", func(c *fiber.Ctx) error {
+		var req signupRequest
+		if err := OptionalBody(c, &req); err != nil {
+			return Respond(c, err)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	app.Get("/search", func(c *fiber.Ctx) error {
+		var query searchQuery
+		if err := Query(c, &query); err != nil {
+			return Respond(c, err)
+		}
+		return c.JSON(query)
+	})
+
+	return app
+}
+
+func doRequest(t *testing.T, app *fiber.App, method, target, body string) (int, errorResponse) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var decoded errorResponse
+	if resp.StatusCode == fiber.StatusBadRequest {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+	}
+
+	return resp.StatusCode, decoded
+}
+
+func TestBody(t *testing.T) {
+	app := newTestApp()
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedFields []FieldError
+	}{
+		{
+			name:           "valid",
+			body:           `{"email":"ada@example.com","username":"ada"}`,
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:           "invalid fields",
+			body:           `{"email":"not-an-email","username":"ad","tags":["a","b","c"]}`,
+			expectedStatus: fiber.StatusBadRequest,
+			expectedFields: []FieldError{
+				{Field: "email", Rule: "email", Message: "must be a valid email address"},
+				{Field: "username", Rule: "min", Param: "3", Message: "must be at least 3 characters"},
+				{Field: "tags", Rule: "max", Param: "2", Message: "must be at most 2 items"},
+			},
+		},
+		{
+			name:           "missing fields",
+			body:           `{}`,
+			expectedStatus: fiber.StatusBadRequest,
+			expectedFields: []FieldError{
+				{Field: "email", Rule: "required", Message: "is required"},
+				{Field: "username", Rule: "required", Message: "is required"},
+			},
+		},
+		{
+			name:           "malformed JSON",
+			body:           `{"email":`,
+			expectedStatus: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, response := doRequest(t, app, fiber.MethodPost, "/signup", tt.body)
+
+			assert.Equal(t, tt.expectedStatus, status)
+			if status == fiber.StatusBadRequest {
+				assert.Equal(t, "Invalid request body", response.Error)
+				assert.NotEmpty(t, response.Message)
+				assert.Equal(t, tt.expectedFields, response.Fields)
+			}
+		})
+	}
+}
+
+func TestOptionalBody(t *testing.T) {
+	app := newTestApp()
+
+	status, _ := doRequest(t, app, fiber.MethodPost, "/optional", "")
+	assert.Equal(t, fiber.StatusNoContent, status)
+
+	status, response := doRequest(t, app, fiber.MethodPost, "/optional", `{"email":"nope"}`)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Len(t, response.Fields, 2)
+}
+
+func TestQuery(t *testing.T) {
+	app := newTestApp()
+
+	status, _ := doRequest(t, app, fiber.MethodGet, "/search?q=graph&limit=10", "")
+	assert.Equal(t, fiber.StatusOK, status)
+
+	status, response := doRequest(t, app, fiber.MethodGet, "/search?limit=500", "")
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Equal(t, "Invalid request", response.Error)
+	assert.Equal(t, "q is required; limit must be at most 100", response.Message)
+	assert.Equal(t, []FieldError{
+		{Field: "q", Rule: "required", Message: "is required"},
+		{Field: "limit", Rule: "max", Param: "100", Message: "must be at most 100"},
+	}, response.Fields)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/config"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/middleware"
 	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/jwt"
 	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/log"
 	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/minio"
@@ -55,7 +56,9 @@ type Container struct {
 	moduleManager  *ModuleManager
 	pendingModules []Module
 
-	app *fiber.App
+	app           *fiber.App
+	bodyLimit     int
+	maxUploadSize int64
 
 	startTime time.Time
 	mu        sync.RWMutex
@@ -70,6 +73,13 @@ type Options struct {
 	DisableVault  bool
 	DisableResend bool
 	Timezone      string
+	// BodyLimit is the largest request body accepted, in bytes. Zero keeps
+	// the Fiber default of 4 MiB.
+	BodyLimit int
+	// MaxUploadSize is the largest file accepted in a multipart upload, in
+	// bytes, which may take the body past BodyLimit. Each upload route checks
+	// its own size as well.
+	MaxUploadSize int64
 }
 
 func New(opts *Options) *Container {
@@ -83,6 +93,8 @@ func New(opts *Options) *Container {
 		startTime:      time.Now(),
 		shutdownFuncs:  make([]func() error, 0),
 		pendingModules: make([]Module, 0),
+		bodyLimit:      opts.BodyLimit,
+		maxUploadSize:  opts.MaxUploadSize,
 		ctx:            ctx,
 		cancel:         cancel,
 	}
@@ -1019,6 +1031,10 @@ func (c *Container) initializeServices() error {
 		return fmt.Errorf("failed to initialize module middleware: %w", err)
 	}
 
+	// Module services that run in the background stop through Shutdown or
+	// Close before the infrastructure clients they use are closed.
+	c.addShutdownFunc(c.registry.Shutdown)
+
 	c.logger.Info().Msg("All services initialized via module system")
 	return nil
 }
@@ -1035,8 +1051,15 @@ func (c *Container) initializeRouter() error {
 			})
 		},
 		DisableStartupMessage: true,
+		BodyLimit:             c.bodyLimit,
+		// Bodies are streamed so that uploads are not held in memory, and
+		// multipart forms are parsed only by the routes that read them.
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
 	})
 
+	c.app.Use(middleware.NewBodyLimitMiddleware(c.app.Config().BodyLimit, c.maxUploadSize))
+
 	apiV1 := c.app.Group("/api/v1")
 
 	if err := c.moduleManager.InitializeRoutes(apiV1); err != nil {
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// multipartFraming is the room left around the file of an upload for the
+// part boundaries and the other form fields.
+const multipartFraming = 1 << 20
+
+// NewBodyLimitMiddleware bounds request bodies for a server that streams them
+// instead of reading them up front, since a streamed body is not held to the
+// Fiber body limit. Bodies other than multipart uploads are read here and
+// refused with 413 past limit. Multipart uploads may carry a file of up to
+// maxUploadSize and stay streamed for the upload routes, which check them
+// again against their own size with NewUploadLimitMiddleware.
+func NewBodyLimitMiddleware(limit int, maxUploadSize int64) fiber.Handler {
+	uploadLimit := maxUploadSize + multipartFraming
+	if uploadLimit < int64(limit) {
+		uploadLimit = int64(limit)
+	}
+
+	return func(c *fiber.Ctx) error {
+		length := c.Request().Header.ContentLength()
+		stream := c.Request().BodyStream()
+
+		if !strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+			if length > limit {
+				return bodyTooLarge(c, int64(limit))
+			}
+			if stream == nil {
+				return c.Next()
+			}
+
+			body, err := io.ReadAll(io.LimitReader(stream, int64(limit)+1))
+			if err != nil {
+				c.Response().Header.SetConnectionClose()
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error":   "Bad request",
+					"message": "failed to read request body",
+				})
+			}
+			if len(body) > limit {
+				return bodyTooLarge(c, int64(limit))
+			}
+			c.Request().SetBodyRaw(body)
+
+			return c.Next()
+		}
+
+		// The rest of an upload is left unread when a route refuses it, and
+		// would otherwise be taken for the next request on the connection.
+		c.Response().Header.SetConnectionClose()
+
+		if length < 0 {
+			return c.Status(fiber.StatusLengthRequired).JSON(fiber.Map{
+				"error":   "Length required",
+				"message": "multipart uploads must set Content-Length",
+			})
+		}
+		if int64(length) > uploadLimit {
+			return bodyTooLarge(c, uploadLimit)
+		}
+
+		return c.Next()
+	}
+}
+
+// NewUploadLimitMiddleware refuses, from the declared length and before the
+// body is read, multipart uploads that cannot hold a file of at most maxSize
+// bytes. It belongs on the routes that accept uploads.
+func NewUploadLimitMiddleware(maxSize int64) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if int64(c.Request().Header.ContentLength()) > maxSize+multipartFraming {
+			return bodyTooLarge(c, maxSize+multipartFraming)
+		}
+
+		return c.Next()
+	}
+}
+
+func bodyTooLarge(c *fiber.Ctx, limit int64) error {
+	c.Response().Header.SetConnectionClose()
+	return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+		"error":   "Request body too large",
+		"message": fmt.Sprintf("request body is larger than %d bytes", limit),
+	})
+}
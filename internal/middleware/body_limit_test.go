@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBodyLimit = 1 << 10
+
+func newBodyLimitApp() *fiber.App {
+	app := fiber.New(fiber.Config{
+		BodyLimit:                    testBodyLimit,
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
+	})
+	app.Use(NewBodyLimitMiddleware(testBodyLimit, 8<<20))
+
+	app.Post("/json", func(c *fiber.Ctx) error {
+		return c.SendString(strconv.Itoa(len(c.Body())))
+	})
+	app.Post("/upload", NewUploadLimitMiddleware(4<<20), func(c *fiber.Ctx) error {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return c.SendStatus(fiber.StatusBadRequest)
+		}
+		return c.SendString(strconv.FormatInt(header.Size, 10))
+	})
+
+	return app
+}
+
+func multipartBody(t *testing.T, size int) (string, []byte) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	part, err := writer.CreateFormFile("file", "notes.txt")
+	require.NoError(t, err)
+	_, err = part.Write(bytes.Repeat([]byte("a"), size))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	return writer.FormDataContentType(), body.Bytes()
+}
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		upload     bool
+		size       int
+		chunked    bool
+		wantStatus int
+	}{
+		{name: "body within the limit", path: "/json", size: testBodyLimit, wantStatus: fiber.StatusOK},
+		{name: "body over the limit", path: "/json", size: testBodyLimit + 1, wantStatus: fiber.StatusRequestEntityTooLarge},
+		{name: "chunked body within the limit", path: "/json", size: testBodyLimit, chunked: true, wantStatus: fiber.StatusOK},
+		{name: "chunked body over the limit", path: "/json", size: 64 << 10, chunked: true, wantStatus: fiber.StatusRequestEntityTooLarge},
+		{name: "upload over the body limit", path: "/upload", upload: true, size: 2 << 20, wantStatus: fiber.StatusOK},
+		{name: "upload over the route size", path: "/upload", upload: true, size: 6 << 20, wantStatus: fiber.StatusRequestEntityTooLarge},
+		{name: "upload over the upload size", path: "/json", upload: true, size: 10 << 20, wantStatus: fiber.StatusRequestEntityTooLarge},
+		{name: "chunked upload", path: "/upload", upload: true, size: testBodyLimit, chunked: true, wantStatus: fiber.StatusLengthRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newBodyLimitApp()
+
+			contentType, body := fiber.MIMEApplicationJSON, bytes.Repeat([]byte("a"), tt.size)
+			if tt.upload {
+				contentType, body = multipartBody(t, tt.size)
+			}
+
+			req := httptest.NewRequest(fiber.MethodPost, tt.path, bytes.NewReader(body))
+			req.Header.Set(fiber.HeaderContentType, contentType)
+			if tt.chunked {
+				req.ContentLength = -1
+				req.TransferEncoding = []string{"chunked"}
+			}
+
+			resp, err := app.Test(req, -1)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			if tt.wantStatus == fiber.StatusOK {
+				got, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				assert.Equal(t, strconv.Itoa(tt.size), string(got))
+			}
+		})
+	}
+}
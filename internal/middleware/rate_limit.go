@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/ratelimit"
+)
+
+type RateLimitConfig struct {
+	// Name separates the counters of different routes sharing a limiter.
+	Name string
+	Rule ratelimit.Rule
+	// KeyFunc identifies the caller. Defaults to the client IP.
+	KeyFunc func(c *fiber.Ctx) string
+}
+
+// NewRateLimitMiddleware rejects requests over the configured rule with 429
+// and a Retry-After header. Limiter failures let the request through so that
+// an unavailable Redis does not take the API down with it.
+func NewRateLimitMiddleware(limiter ratelimit.Limiter, config RateLimitConfig) fiber.Handler {
+	keyFunc := config.KeyFunc
+	if keyFunc == nil {
+		keyFunc = func(c *fiber.Ctx) string {
+			return c.IP()
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		key := keyFunc(c)
+		if key == "" {
+			return c.Next()
+		}
+
+		result, err := limiter.Allow(c.Context(), config.Name+":"+key, config.Rule)
+		if err != nil {
+			fmt.Printf("Rate limiter unavailable for %s: %v\n", config.Name, err)
+			return c.Next()
+		}
+
+		c.Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+
+		if !result.Allowed {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(ratelimit.RetryAfterSeconds(result.RetryAfter)))
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error":   "Too many requests",
+				"message": "Rate limit exceeded, please try again later",
+			})
+		}
+
+		return c.Next()
+	}
+}
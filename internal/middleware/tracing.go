@@ -57,7 +57,7 @@ func NewTracingMiddleware(telemetryService telemetry.TelemetryService) fiber.Han
 			attribute.String("http.target", c.Path()),
 			attribute.String("http.scheme", c.Protocol()),
 			attribute.String("http.user_agent", c.Get("User-Agent")),
-			attribute.Int("http.request_content_length", len(c.Body())),
+			attribute.Int("http.request_content_length", c.Request().Header.ContentLength()),
 			attribute.String("http.remote_addr", c.IP()),
 		)
 
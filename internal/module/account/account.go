@@ -1,11 +1,18 @@
 package account
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 
 	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/container"
+	appmiddleware "github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/middleware"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/argon2"
 	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/jwt"
 	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/ratelimit"
 	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/resend"
 )
 
@@ -15,7 +22,7 @@ func NewService(
 	resendService resend.ResendService,
 	fromEmail string,
 ) AccountService {
-	return NewAccountService(mongoService, nil, jwtService, resendService, fromEmail)
+	return NewAccountService(mongoService, nil, jwtService, resendService, fromEmail, nil, nil, "", nil)
 }
 
 
@@ -32,6 +39,14 @@ type AccountModule struct {
 	fromEmail          string
 	useCacheForOTP     bool
 	useCacheForSession bool
+	passkeyConfig      *PasskeyConfig
+	magicLinkConfig    *MagicLinkConfig
+	oidcProviders      []OIDCProviderConfig
+	bootstrapAdmins    []string
+	auditRetention     time.Duration
+	loginReportURL     string
+	passwordPolicy     PasswordPolicy
+	passwordHashConfig *PasswordHashConfig
 }
 
 func NewAccountModule(fromEmail string) *AccountModule {
@@ -47,6 +62,8 @@ func NewAccountModule(fromEmail string) *AccountModule {
 		fromEmail:          fromEmail,
 		useCacheForOTP:     true,
 		useCacheForSession: true,
+		auditRetention:     DefaultAuditRetention,
+		passwordPolicy:     DefaultPasswordPolicy(),
 	}
 }
 
@@ -56,6 +73,57 @@ func (m *AccountModule) WithCacheConfig(useCacheForOTP, useCacheForSession bool)
 	return m
 }
 
+func (m *AccountModule) WithPasskeyConfig(config PasskeyConfig) *AccountModule {
+	m.passkeyConfig = &config
+	return m
+}
+
+// WithMagicLinkConfig enables passwordless sign-in through emailed links.
+func (m *AccountModule) WithMagicLinkConfig(config MagicLinkConfig) *AccountModule {
+	m.magicLinkConfig = &config
+	return m
+}
+
+func (m *AccountModule) WithOIDCProviders(providers ...OIDCProviderConfig) *AccountModule {
+	m.oidcProviders = append(m.oidcProviders, providers...)
+	return m
+}
+
+// WithBootstrapAdmins grants the admin role to the accounts with these emails
+// regardless of their stored roles.
+func (m *AccountModule) WithBootstrapAdmins(emails ...string) *AccountModule {
+	m.bootstrapAdmins = append(m.bootstrapAdmins, emails...)
+	return m
+}
+
+// WithAuditRetention sets how long audit events are kept. Zero keeps them
+// forever.
+func (m *AccountModule) WithAuditRetention(retention time.Duration) *AccountModule {
+	m.auditRetention = retention
+	return m
+}
+
+// WithLoginReportURL sets the "this wasn't me" link sent in new device
+// alerts. Without it the alerts are sent without a link.
+func (m *AccountModule) WithLoginReportURL(reportURL string) *AccountModule {
+	m.loginReportURL = reportURL
+	return m
+}
+
+// WithPasswordPolicy replaces DefaultPasswordPolicy for new passwords.
+func (m *AccountModule) WithPasswordPolicy(policy PasswordPolicy) *AccountModule {
+	m.passwordPolicy = policy
+	return m
+}
+
+// WithPasswordHashConfig changes the argon2 parameters of new password hashes
+// and enables a pepper kept in Vault. Hashes made with other settings are
+// replaced on the next successful login.
+func (m *AccountModule) WithPasswordHashConfig(config PasswordHashConfig) *AccountModule {
+	m.passwordHashConfig = &config
+	return m
+}
+
 func (m *AccountModule) RegisterServices(registry *container.ServiceRegistry) error {
 	mongoService := registry.GetMongo()
 	if mongoService == nil {
@@ -72,15 +140,101 @@ func (m *AccountModule) RegisterServices(registry *container.ServiceRegistry) er
 		return container.ServiceNotFoundError{ServiceName: "resend"}
 	}
 
+	if m.passwordHashConfig != nil {
+		hasher, err := newPasswordHasher(context.Background(), *m.passwordHashConfig, registry.GetVault())
+		if err != nil {
+			return fmt.Errorf("failed to configure password hashing: %w", err)
+		}
+		argon2.SetDefault(hasher)
+	}
+
 	var accountService AccountService
 
 	cacheService := registry.GetRedis()
-	accountService = NewAccountService(mongoService, cacheService, jwtService, resendService, m.fromEmail)
+
+	// Registered so that other modules can throttle their routes with
+	// middleware.NewRateLimitMiddleware against the same store.
+	limiter := NewRateLimiter(cacheService)
+	if err := registry.RegisterService("rate_limiter", limiter); err != nil {
+		return err
+	}
+
+	auditService := NewAuditService(mongoService, m.auditRetention)
+	if err := registry.RegisterService("audit", auditService); err != nil {
+		return err
+	}
+	auditService.Start()
+
+	accountService = NewAccountService(mongoService, cacheService, jwtService, resendService, m.fromEmail, limiter, auditService, m.loginReportURL, &m.passwordPolicy)
 
 	if err := registry.RegisterService("account", accountService); err != nil {
 		return err
 	}
 
+	apiKeyService := NewAPIKeyService(mongoService)
+	if err := registry.RegisterService("apikey", apiKeyService); err != nil {
+		return err
+	}
+
+	rbacService := NewRBACService(mongoService, m.bootstrapAdmins)
+	if err := registry.RegisterService("rbac", rbacService); err != nil {
+		return err
+	}
+
+	adminService := NewAdminService(mongoService, cacheService, jwtService, rbacService, auditService, accountService)
+	if err := registry.RegisterService("admin", adminService); err != nil {
+		return err
+	}
+
+	purger := NewAccountPurger(mongoService, cacheService, auditService)
+	if err := registry.RegisterService("account_purger", purger); err != nil {
+		return err
+	}
+	purger.Start()
+
+	// Other modules protect their routes with the same middleware so that
+	// session tokens, OAuth access tokens and API keys work everywhere.
+	middleware := NewMiddleware(accountService).
+		WithAPIKeyService(apiKeyService).
+		WithRBACService(rbacService)
+	if err := registry.RegisterService("account_middleware", middleware); err != nil {
+		return err
+	}
+
+	oauthService := NewOAuthService(mongoService, cacheService, jwtService)
+	if err := registry.RegisterService("oauth", oauthService); err != nil {
+		return err
+	}
+
+	if m.passkeyConfig != nil {
+		passkeyService, err := NewPasskeyService(mongoService, accountService, *m.passkeyConfig)
+		if err != nil {
+			return err
+		}
+
+		if err := registry.RegisterService("passkey", passkeyService); err != nil {
+			return err
+		}
+	}
+
+	if m.magicLinkConfig != nil {
+		magicLinkService, err := NewMagicLinkService(mongoService, cacheService, accountService, jwtService, resendService, m.fromEmail, limiter, *m.magicLinkConfig)
+		if err != nil {
+			return err
+		}
+
+		if err := registry.RegisterService("magic_link", magicLinkService); err != nil {
+			return err
+		}
+	}
+
+	if len(m.oidcProviders) > 0 {
+		federationService := NewFederationService(mongoService, accountService, m.oidcProviders)
+		if err := registry.RegisterService("federation", federationService); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -92,30 +246,147 @@ func (m *AccountModule) RegisterRoutes(router fiber.Router, registry *container.
 
 	accountService := accountServiceInterface.(AccountService)
 	handler := NewHandler(accountService)
-	middleware := NewMiddleware(accountService)
+
+	middlewareInterface, err := registry.GetService("account_middleware")
+	if err != nil {
+		return err
+	}
+
+	middleware := middlewareInterface.(*AccountMiddleware)
+	router.Use(middleware.AuditContext())
+
+	limiterInterface, err := registry.GetService("rate_limiter")
+	if err != nil {
+		return err
+	}
+
+	limiter := limiterInterface.(ratelimit.Limiter)
+	loginLimit := appmiddleware.NewRateLimitMiddleware(limiter, appmiddleware.RateLimitConfig{
+		Name: "ip:login",
+		Rule: LoginIPRateLimit,
+	})
+	otpLimit := appmiddleware.NewRateLimitMiddleware(limiter, appmiddleware.RateLimitConfig{
+		Name: "ip:otp",
+		Rule: OTPIPRateLimit,
+	})
 
 	accounts := router.Group("/accounts")
 
-	accounts.Post("/login", handler.Login)
+	accounts.Post("/login", loginLimit, handler.Login)
 	accounts.Post("/register", handler.Register)
 	accounts.Post("/logout", middleware.RequireAuth(), handler.Logout)
 	accounts.Post("/refresh", handler.RefreshToken)
 	accounts.Post("/validate", handler.ValidateToken)
-	accounts.Post("/forgot-password", handler.ForgotPassword)
-	accounts.Post("/reset-password", handler.ResetPassword)
-	accounts.Post("/verify-email", handler.VerifyEmail)
-	accounts.Post("/resend-verification", handler.ResendEmailVerification)
-	accounts.Post("/change-password", middleware.RequireAuth(), handler.ChangePassword)
+	accounts.Post("/forgot-password", otpLimit, handler.ForgotPassword)
+	accounts.Post("/reset-password", otpLimit, handler.ResetPassword)
+	accounts.Post("/verify-email", otpLimit, handler.VerifyEmail)
+	accounts.Post("/resend-verification", otpLimit, handler.ResendEmailVerification)
+	accounts.Post("/unlock/request", otpLimit, handler.RequestAccountUnlock)
+	accounts.Post("/unlock", otpLimit, handler.UnlockAccount)
+	accounts.Get("/login/report", otpLimit, handler.OpenLoginReport)
+	accounts.Post("/login/report", otpLimit, handler.ReportLogin)
+	accounts.Post("/change-password", middleware.RequireAuth(), middleware.RequireFirstParty(), handler.ChangePassword)
+	accounts.Post("/me/email-change", middleware.RequireAuth(), middleware.RequireFirstParty(), otpLimit, handler.RequestEmailChange)
+	accounts.Post("/me/email-change/confirm", middleware.RequireAuth(), middleware.RequireFirstParty(), otpLimit, handler.ConfirmEmailChange)
+
+	if passkeyServiceInterface, err := registry.GetService("passkey"); err == nil {
+		passkeyHandler := NewPasskeyHandler(passkeyServiceInterface.(PasskeyService))
+
+		accounts.Post("/login/passkey/begin", loginLimit, passkeyHandler.BeginLogin)
+		accounts.Post("/login/passkey/finish", loginLimit, passkeyHandler.FinishLogin)
+		accounts.Post("/passkeys/register/begin", middleware.RequireAuth(), middleware.RequireFirstParty(), passkeyHandler.BeginRegistration)
+		accounts.Post("/passkeys/register/finish", middleware.RequireAuth(), middleware.RequireFirstParty(), passkeyHandler.FinishRegistration)
+		accounts.Get("/passkeys", middleware.RequireAuth(), middleware.RequireFirstParty(), passkeyHandler.ListPasskeys)
+		accounts.Delete("/passkeys/:passkeyId", middleware.RequireAuth(), middleware.RequireFirstParty(), passkeyHandler.DeletePasskey)
+	}
+
+	if magicLinkServiceInterface, err := registry.GetService("magic_link"); err == nil {
+		magicLinkHandler := NewMagicLinkHandler(magicLinkServiceInterface.(MagicLinkService))
+
+		accounts.Post("/login/magic", otpLimit, magicLinkHandler.RequestMagicLink)
+		accounts.Get("/login/magic/verify", loginLimit, magicLinkHandler.OpenMagicLink)
+		accounts.Post("/login/magic/verify", loginLimit, magicLinkHandler.VerifyMagicLink)
+	}
+
+	if federationServiceInterface, err := registry.GetService("federation"); err == nil {
+		federationHandler := NewFederationHandler(federationServiceInterface.(FederationService))
+
+		accounts.Get("/oidc/providers", federationHandler.ListProviders)
+		accounts.Get("/oidc/:provider/authorize", federationHandler.Authorize)
+		accounts.Get("/oidc/:provider/callback", federationHandler.Callback)
+		accounts.Get("/me/identities", middleware.RequireAuth(), middleware.RequireScope(OAuthScopeProfile), federationHandler.ListIdentities)
+	}
+
+	if apiKeyServiceInterface, err := registry.GetService("apikey"); err == nil {
+		apiKeyHandler := NewAPIKeyHandler(apiKeyServiceInterface.(APIKeyService))
+
+		accounts.Post("/me/api-keys", middleware.RequireAuth(), middleware.RequireFirstParty(), apiKeyHandler.CreateAPIKey)
+		accounts.Get("/me/api-keys", middleware.RequireAuth(), middleware.RequireFirstParty(), apiKeyHandler.ListAPIKeys)
+		accounts.Delete("/me/api-keys/:keyId", middleware.RequireAuth(), middleware.RequireFirstParty(), apiKeyHandler.RevokeAPIKey)
+	}
+
+	adminServiceInterface, err := registry.GetService("admin")
+	if err != nil {
+		return err
+	}
+
+	rbacServiceInterface, err := registry.GetService("rbac")
+	if err != nil {
+		return err
+	}
+
+	adminHandler := NewAdminHandler(adminServiceInterface.(AdminService), rbacServiceInterface.(RBACService))
+	accounts.Get("/me/permissions", middleware.RequireAuth(), adminHandler.GetMyPermissions)
+
+	auditServiceInterface, err := registry.GetService("audit")
+	if err != nil {
+		return err
+	}
+
+	auditHandler := NewAuditHandler(auditServiceInterface.(AuditService))
+	accounts.Get("/me/activity", middleware.RequireAuth(), middleware.RequireFirstParty(), auditHandler.ListMyActivity)
 
 	accounts.Post("/", handler.CreateAccount)
-	accounts.Get("/me", middleware.RequireAuth(), handler.GetMe)
+	accounts.Get("/me", middleware.RequireAuth(), middleware.RequireScope(OAuthScopeProfile), handler.GetMe)
 
 	accounts.Get("/email", middleware.OptionalAuth(), handler.GetAccountByEmail)
 	accounts.Get("/username", middleware.OptionalAuth(), handler.GetAccountByUsername)
 
-	accounts.Get("/:id", middleware.RequireAuth(), middleware.ValidateAccountOwnership(), handler.GetAccount)
-	accounts.Put("/:id", middleware.RequireAuth(), middleware.ValidateAccountOwnership(), handler.UpdateAccount)
-	accounts.Delete("/:id", middleware.RequireAuth(), middleware.ValidateAccountOwnership(), handler.DeleteAccount)
+	accounts.Get("/:id", middleware.RequireAuth(), middleware.RequireScope(OAuthScopeProfile), middleware.ValidateAccountOwnership(), handler.GetAccount)
+	accounts.Put("/:id", middleware.RequireAuth(), middleware.RequireFirstParty(), middleware.ValidateAccountOwnership(), handler.UpdateAccount)
+	accounts.Delete("/:id", middleware.RequireAuth(), middleware.RequireFirstParty(), middleware.ValidateAccountOwnership(), handler.DeleteAccount)
+
+	admin := router.Group("/admin", middleware.RequireAuth(), middleware.RequireFirstParty())
+	admin.Get("/accounts", middleware.RequirePermission(PermissionAccountsRead), adminHandler.ListAccounts)
+	admin.Get("/accounts/export", middleware.RequirePermission(PermissionAccountsExport), adminHandler.ExportAccounts)
+	admin.Post("/accounts/bulk", middleware.RequirePermission(PermissionAccountsWrite), adminHandler.BulkAccounts)
+	admin.Get("/accounts/:id", middleware.RequirePermission(PermissionAccountsRead), adminHandler.GetAccount)
+	admin.Post("/accounts/:id/deactivate", middleware.RequirePermission(PermissionAccountsWrite), adminHandler.DeactivateAccount)
+	admin.Post("/accounts/:id/reactivate", middleware.RequirePermission(PermissionAccountsWrite), adminHandler.ReactivateAccount)
+	admin.Post("/accounts/:id/force-password-reset", middleware.RequirePermission(PermissionAccountsWrite), adminHandler.ForcePasswordReset)
+	admin.Post("/accounts/:id/restore", middleware.RequirePermission(PermissionAccountsWrite), adminHandler.RestoreAccount)
+	admin.Post("/accounts/:id/impersonate", middleware.RequirePermission(PermissionAccountsImpersonate), adminHandler.Impersonate)
+	admin.Put("/accounts/:id/roles", middleware.RequirePermission(PermissionRolesManage), adminHandler.AssignRoles)
+	admin.Get("/roles", middleware.RequirePermission(PermissionRolesRead), adminHandler.ListRoles)
+	admin.Post("/roles", middleware.RequirePermission(PermissionRolesManage), adminHandler.CreateRole)
+	admin.Put("/roles/:name", middleware.RequirePermission(PermissionRolesManage), adminHandler.UpdateRole)
+	admin.Delete("/roles/:name", middleware.RequirePermission(PermissionRolesManage), adminHandler.DeleteRole)
+	admin.Get("/audit-events", middleware.RequirePermission(PermissionAuditRead), auditHandler.ListEvents)
+	admin.Get("/audit-events/verify", middleware.RequirePermission(PermissionAuditRead), auditHandler.VerifyChain)
+
+	if oauthServiceInterface, err := registry.GetService("oauth"); err == nil {
+		oauthHandler := NewOAuthHandler(oauthServiceInterface.(OAuthService))
+
+		oauth := router.Group("/oauth")
+		oauth.Post("/clients", middleware.RequireAuth(), middleware.RequireFirstParty(), oauthHandler.RegisterClient)
+		oauth.Get("/clients", middleware.RequireAuth(), middleware.RequireFirstParty(), oauthHandler.ListClients)
+		oauth.Delete("/clients/:clientId", middleware.RequireAuth(), middleware.RequireFirstParty(), oauthHandler.DeleteClient)
+		oauth.Get("/authorize", middleware.RequireAuth(), middleware.RequireFirstParty(), oauthHandler.AuthorizePrompt)
+		oauth.Post("/authorize", middleware.RequireAuth(), middleware.RequireFirstParty(), oauthHandler.Authorize)
+		oauth.Post("/token", oauthHandler.Token)
+		oauth.Post("/introspect", oauthHandler.Introspect)
+		oauth.Post("/revoke", oauthHandler.Revoke)
+	}
 
 	return nil
 }
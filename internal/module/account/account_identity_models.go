@@ -8,7 +8,7 @@ import (
 
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8"`
+	Password string `json:"password" validate:"required"`
 }
 
 type LoginResponse struct {
@@ -82,6 +82,9 @@ type OTPPurpose string
 const (
 	OTPPurposeEmailVerification OTPPurpose = "email_verification"
 	OTPPurposePasswordReset     OTPPurpose = "password_reset"
+	OTPPurposeAccountUnlock     OTPPurpose = "account_unlock"
+	OTPPurposeEmailChange       OTPPurpose = "email_change"
+	OTPPurposeMagicLogin        OTPPurpose = "magic_login"
 )
 
 type OTP struct {
@@ -96,15 +99,18 @@ type OTP struct {
 }
 
 type Session struct {
-	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	AccountID   string             `json:"account_id" bson:"account_id"`
-	TokenHash   string             `json:"token_hash" bson:"token_hash"`
-	IsActive    bool               `json:"is_active" bson:"is_active"`
-	ExpiresAt   time.Time          `json:"expires_at" bson:"expires_at"`
-	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
-	LastUsedAt  time.Time          `json:"last_used_at" bson:"last_used_at"`
-	UserAgent   string             `json:"user_agent" bson:"user_agent"`
-	IPAddress   string             `json:"ip_address" bson:"ip_address"`
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	AccountID      string             `json:"account_id" bson:"account_id"`
+	TokenHash      string             `json:"token_hash" bson:"token_hash"`
+	IsActive       bool               `json:"is_active" bson:"is_active"`
+	ExpiresAt      time.Time          `json:"expires_at" bson:"expires_at"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+	LastUsedAt     time.Time          `json:"last_used_at" bson:"last_used_at"`
+	UserAgent      string             `json:"user_agent" bson:"user_agent"`
+	IPAddress      string             `json:"ip_address" bson:"ip_address"`
+	ClientID       string             `json:"client_id,omitempty" bson:"client_id,omitempty"`
+	Scopes         []string           `json:"scopes,omitempty" bson:"scopes,omitempty"`
+	ImpersonatorID string             `json:"impersonator_id,omitempty" bson:"impersonator_id,omitempty"`
 }
 
 type EmailTemplate struct {
@@ -51,11 +51,21 @@ func (m *MockMongoRepository[T]) Update(ctx context.Context, filter bson.M, upda
 	return args.Get(0).(*T), args.Error(1)
 }
 
+func (m *MockMongoRepository[T]) UpdateMany(ctx context.Context, filter bson.M, update bson.M, opts ...*options.UpdateOptions) (int64, error) {
+	args := m.Called(ctx, filter, update, opts)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockMongoRepository[T]) Delete(ctx context.Context, filter bson.M, opts ...*options.DeleteOptions) error {
 	args := m.Called(ctx, filter, opts)
 	return args.Error(0)
 }
 
+func (m *MockMongoRepository[T]) DeleteMany(ctx context.Context, filter bson.M, opts ...*options.DeleteOptions) (int64, error) {
+	args := m.Called(ctx, filter, opts)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockMongoRepository[T]) FindWithPagination(ctx context.Context, filter bson.M, pagination mongo.PaginationOptions, opts ...*options.FindOptions) (*mongo.PaginatedResult[T], error) {
 	args := m.Called(ctx, filter, pagination, opts)
 	if args.Get(0) == nil {
@@ -0,0 +1,525 @@
+package account
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/binding"
+)
+
+type AdminHandler struct {
+	service     AdminService
+	rbacService RBACService
+}
+
+func NewAdminHandler(service AdminService, rbacService RBACService) *AdminHandler {
+	return &AdminHandler{
+		service:     service,
+		rbacService: rbacService,
+	}
+}
+
+// ListAccounts godoc
+// @Summary List accounts
+// @Description Search, filter and page through all accounts. Pages are numbered by default; pass pagination=cursor, then the returned next_cursor, to page with a cursor instead. Requires the accounts:read permission.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param q query string false "Search terms matched against email, username, first and last name"
+// @Param is_active query bool false "Filter by active state"
+// @Param status query string false "Filter by status (pending, active, suspended, deleted)"
+// @Param role query string false "Filter by role"
+// @Param email_prefix query string false "Filter by email prefix"
+// @Param username_prefix query string false "Filter by username prefix"
+// @Param created_from query string false "Only accounts created at or after this RFC 3339 time"
+// @Param created_to query string false "Only accounts created before this RFC 3339 time"
+// @Param sort query string false "Sort field, prefixed with - for descending (created_at, updated_at, email, username)" default(-created_at)
+// @Param pagination query string false "Pagination mode (page, cursor)" default(page)
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(20)
+// @Success 200 {object} map[string]interface{} "Accounts retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Router /admin/accounts [get]
+func (h *AdminHandler) ListAccounts(c *fiber.Ctx) error {
+	var query AdminAccountListQuery
+	if err := binding.Query(c, &query); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	var (
+		accounts any
+		err      error
+	)
+	if query.usesCursor() {
+		accounts, err = h.service.ListAccountsByCursor(c.Context(), &query)
+	} else {
+		accounts, err = h.service.ListAccounts(c.Context(), &query)
+	}
+	if err != nil {
+		return adminAccountError(c, "Failed to list accounts", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Accounts retrieved successfully",
+		"data":    accounts,
+	})
+}
+
+// ExportAccounts godoc
+// @Summary Export accounts
+// @Description Download every account matching the list filters as CSV or NDJSON. Requires the accounts:export permission.
+// @Tags admin
+// @Security BearerAuth
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "Export format (csv, ndjson)" default(csv)
+// @Param q query string false "Search terms matched against email, username, first and last name"
+// @Param is_active query bool false "Filter by active state"
+// @Param status query string false "Filter by status (pending, active, suspended, deleted)"
+// @Param role query string false "Filter by role"
+// @Param email_prefix query string false "Filter by email prefix"
+// @Param username_prefix query string false "Filter by username prefix"
+// @Param created_from query string false "Only accounts created at or after this RFC 3339 time"
+// @Param created_to query string false "Only accounts created before this RFC 3339 time"
+// @Param sort query string false "Sort field, prefixed with - for descending (created_at, updated_at, email, username)" default(-created_at)
+// @Success 200 {file} file "Account export"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Router /admin/accounts/export [get]
+func (h *AdminHandler) ExportAccounts(c *fiber.Ctx) error {
+	var query AdminAccountExportQuery
+	if err := binding.Query(c, &query); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	actorID, _ := c.Locals("account_id").(string)
+	ctx := c.Context()
+
+	export, err := h.service.ExportAccounts(ctx, actorID, &query.AdminAccountListQuery, query.Format)
+	if err != nil {
+		return adminAccountError(c, "Failed to export accounts", err)
+	}
+
+	format := query.Format
+	if format == "" {
+		format = AccountExportFormatCSV
+	}
+	contentType := "text/csv; charset=utf-8"
+	if format == AccountExportFormatNDJSON {
+		contentType = "application/x-ndjson"
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Attachment("accounts-" + time.Now().UTC().Format("20060102T150405Z") + "." + format)
+
+	ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := export(w); err != nil {
+			fmt.Printf("Failed to export accounts: %v\n", err)
+		}
+		w.Flush()
+	})
+
+	return nil
+}
+
+// BulkAccounts godoc
+// @Summary Apply an action to many accounts
+// @Description Deactivate, reactivate or force a password reset on up to 100 accounts. The response lists the accounts that succeeded and why the others failed. Requires the accounts:write permission.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body AdminBulkAccountRequest true "Action and account IDs"
+// @Success 200 {object} map[string]interface{} "Bulk action completed"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Router /admin/accounts/bulk [post]
+func (h *AdminHandler) BulkAccounts(c *fiber.Ctx) error {
+	var req AdminBulkAccountRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	actorID, _ := c.Locals("account_id").(string)
+
+	response, err := h.service.BulkUpdateAccounts(c.Context(), actorID, &req)
+	if err != nil {
+		return adminAccountError(c, "Failed to apply bulk action", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Bulk action completed",
+		"data":    response,
+	})
+}
+
+// GetAccount godoc
+// @Summary Get any account
+// @Description Get an account by ID. Requires the accounts:read permission.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {object} map[string]interface{} "Account retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Account not found"
+// @Router /admin/accounts/{id} [get]
+func (h *AdminHandler) GetAccount(c *fiber.Ctx) error {
+	account, err := h.service.GetAccount(c.Context(), c.Params("id"))
+	if err != nil {
+		return adminAccountError(c, "Failed to get account", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Account retrieved successfully",
+		"data":    account,
+	})
+}
+
+// DeactivateAccount godoc
+// @Summary Deactivate an account
+// @Description Deactivate an account and end all of its sessions. Requires the accounts:write permission.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {object} map[string]interface{} "Account deactivated successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Account not found"
+// @Router /admin/accounts/{id}/deactivate [post]
+func (h *AdminHandler) DeactivateAccount(c *fiber.Ctx) error {
+	actorID, _ := c.Locals("account_id").(string)
+
+	account, err := h.service.DeactivateAccount(c.Context(), actorID, c.Params("id"))
+	if err != nil {
+		return adminAccountError(c, "Failed to deactivate account", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Account deactivated successfully",
+		"data":    account,
+	})
+}
+
+// RestoreAccount godoc
+// @Summary Restore a deleted account
+// @Description Undo an account deletion before the grace period ends and the account is purged. Requires the accounts:write permission.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {object} map[string]interface{} "Account restored successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Account not found"
+// @Router /admin/accounts/{id}/restore [post]
+func (h *AdminHandler) RestoreAccount(c *fiber.Ctx) error {
+	actorID, _ := c.Locals("account_id").(string)
+
+	account, err := h.service.RestoreAccount(c.Context(), actorID, c.Params("id"))
+	if err != nil {
+		return adminAccountError(c, "Failed to restore account", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Account restored successfully",
+		"data":    account,
+	})
+}
+
+// ReactivateAccount godoc
+// @Summary Reactivate a suspended account
+// @Description Lift the suspension of a deactivated account. Requires the accounts:write permission.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {object} map[string]interface{} "Account reactivated successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Account not found"
+// @Router /admin/accounts/{id}/reactivate [post]
+func (h *AdminHandler) ReactivateAccount(c *fiber.Ctx) error {
+	actorID, _ := c.Locals("account_id").(string)
+
+	account, err := h.service.ReactivateAccount(c.Context(), actorID, c.Params("id"))
+	if err != nil {
+		return adminAccountError(c, "Failed to reactivate account", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Account reactivated successfully",
+		"data":    account,
+	})
+}
+
+// ForcePasswordReset godoc
+// @Summary Force a password reset
+// @Description Block sign-in until the account resets its password, end all of its sessions and email it a reset code. Requires the accounts:write permission.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {object} map[string]interface{} "Password reset required"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Account not found"
+// @Router /admin/accounts/{id}/force-password-reset [post]
+func (h *AdminHandler) ForcePasswordReset(c *fiber.Ctx) error {
+	actorID, _ := c.Locals("account_id").(string)
+
+	account, err := h.service.ForcePasswordReset(c.Context(), actorID, c.Params("id"))
+	if err != nil {
+		return adminAccountError(c, "Failed to force password reset", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Password reset required",
+		"data":    account,
+	})
+}
+
+// Impersonate godoc
+// @Summary Impersonate an account
+// @Description Issue a short-lived session for another account. The token records the administrator as impersonator. Requires the accounts:impersonate permission.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {object} map[string]interface{} "Impersonation session created"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Account not found"
+// @Router /admin/accounts/{id}/impersonate [post]
+func (h *AdminHandler) Impersonate(c *fiber.Ctx) error {
+	actorID, _ := c.Locals("account_id").(string)
+
+	response, err := h.service.Impersonate(c.Context(), actorID, c.Params("id"), c.Get("User-Agent"), c.IP())
+	if err != nil {
+		return adminAccountError(c, "Failed to impersonate account", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Impersonation session created",
+		"data":    response,
+	})
+}
+
+// AssignRoles godoc
+// @Summary Assign roles to an account
+// @Description Replace the roles of an account. Requires the roles:manage permission.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param request body AssignRolesRequest true "Roles to assign"
+// @Success 200 {object} map[string]interface{} "Roles assigned successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Account or role not found"
+// @Router /admin/accounts/{id}/roles [put]
+func (h *AdminHandler) AssignRoles(c *fiber.Ctx) error {
+	var req AssignRolesRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	account, err := h.rbacService.AssignRoles(c.Context(), c.Params("id"), &req)
+	if err != nil {
+		return adminAccountError(c, "Failed to assign roles", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Roles assigned successfully",
+		"data":    account,
+	})
+}
+
+// ListRoles godoc
+// @Summary List roles
+// @Description List the system and custom roles with their permissions. Requires the roles:read permission.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Roles retrieved successfully"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Router /admin/roles [get]
+func (h *AdminHandler) ListRoles(c *fiber.Ctx) error {
+	roles, err := h.rbacService.ListRoles(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to list roles",
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Roles retrieved successfully",
+		"data":    roles,
+	})
+}
+
+// CreateRole godoc
+// @Summary Create a role
+// @Description Create a custom role. Requires the roles:manage permission.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateRoleRequest true "Role details"
+// @Success 201 {object} map[string]interface{} "Role created successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 409 {object} map[string]interface{} "Role already exists"
+// @Router /admin/roles [post]
+func (h *AdminHandler) CreateRole(c *fiber.Ctx) error {
+	var req CreateRoleRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	role, err := h.rbacService.CreateRole(c.Context(), &req)
+	if err != nil {
+		return roleError(c, "Failed to create role", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Role created successfully",
+		"data":    role,
+	})
+}
+
+// UpdateRole godoc
+// @Summary Update a role
+// @Description Update the description or permissions of a custom role. Requires the roles:manage permission.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param name path string true "Role name"
+// @Param request body UpdateRoleRequest true "Role changes"
+// @Success 200 {object} map[string]interface{} "Role updated successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Role not found"
+// @Router /admin/roles/{name} [put]
+func (h *AdminHandler) UpdateRole(c *fiber.Ctx) error {
+	var req UpdateRoleRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	role, err := h.rbacService.UpdateRole(c.Context(), c.Params("name"), &req)
+	if err != nil {
+		return roleError(c, "Failed to update role", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Role updated successfully",
+		"data":    role,
+	})
+}
+
+// DeleteRole godoc
+// @Summary Delete a role
+// @Description Delete a custom role. Requires the roles:manage permission.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param name path string true "Role name"
+// @Success 200 {object} map[string]interface{} "Role deleted successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Role not found"
+// @Router /admin/roles/{name} [delete]
+func (h *AdminHandler) DeleteRole(c *fiber.Ctx) error {
+	if err := h.rbacService.DeleteRole(c.Context(), c.Params("name")); err != nil {
+		return roleError(c, "Failed to delete role", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Role deleted successfully",
+	})
+}
+
+// GetMyPermissions godoc
+// @Summary Get my permissions
+// @Description List the permissions granted to the authenticated account by its roles
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Permissions retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /accounts/me/permissions [get]
+func (h *AdminHandler) GetMyPermissions(c *fiber.Ctx) error {
+	accountID, ok := c.Locals("account_id").(string)
+	if !ok || accountID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   "Unauthorized",
+			"message": "Account ID not found in context",
+		})
+	}
+
+	permissions, err := h.rbacService.GetPermissions(c.Context(), accountID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to get permissions",
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Permissions retrieved successfully",
+		"data":    permissions,
+	})
+}
+
+func adminAccountError(c *fiber.Ctx, title string, err error) error {
+	statusCode := fiber.StatusInternalServerError
+	if strings.Contains(err.Error(), "not found") {
+		statusCode = fiber.StatusNotFound
+	} else if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "cannot") {
+		statusCode = fiber.StatusBadRequest
+	}
+
+	return c.Status(statusCode).JSON(fiber.Map{
+		"error":   title,
+		"message": err.Error(),
+	})
+}
+
+func roleError(c *fiber.Ctx, title string, err error) error {
+	statusCode := fiber.StatusInternalServerError
+	if strings.Contains(err.Error(), "not found") {
+		statusCode = fiber.StatusNotFound
+	} else if strings.Contains(err.Error(), "already exists") {
+		statusCode = fiber.StatusConflict
+	} else if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "cannot be modified") {
+		statusCode = fiber.StatusBadRequest
+	}
+
+	return c.Status(statusCode).JSON(fiber.Map{
+		"error":   title,
+		"message": err.Error(),
+	})
+}
@@ -0,0 +1,164 @@
+package account
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	AdminAccountListDefaultLimit = 20
+	AdminAccountListMaxLimit     = 100
+	AdminAccountBulkMaxSize      = 100
+
+	adminAccountExportBatchSize = 500
+)
+
+const (
+	AdminBulkActionDeactivate         = "deactivate"
+	AdminBulkActionReactivate         = "reactivate"
+	AdminBulkActionForcePasswordReset = "force_password_reset"
+)
+
+const (
+	AccountExportFormatCSV    = "csv"
+	AccountExportFormatNDJSON = "ndjson"
+)
+
+// adminAccountSortFields are the fields accounts can be sorted by. Prefix one
+// with "-" to sort in descending order.
+var adminAccountSortFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"email":      true,
+	"username":   true,
+}
+
+// AdminAccountListQuery filters accounts for the admin list and export. Q
+// matches every whitespace-separated term against email, username and names.
+// CreatedFrom and CreatedTo are RFC 3339 timestamps.
+type AdminAccountListQuery struct {
+	Query          string        `query:"q"`
+	IsActive       *bool         `query:"is_active"`
+	Status         AccountStatus `query:"status" validate:"omitempty,oneof=pending active suspended deleted"`
+	Role           string        `query:"role"`
+	EmailPrefix    string        `query:"email_prefix"`
+	UsernamePrefix string        `query:"username_prefix"`
+	CreatedFrom    string        `query:"created_from"`
+	CreatedTo      string        `query:"created_to"`
+	Sort           string        `query:"sort" validate:"omitempty,oneof=created_at -created_at updated_at -updated_at email -email username -username"`
+	// Pagination selects "page" (the default) or "cursor". Passing a Cursor
+	// implies cursor pagination.
+	Pagination string `query:"pagination" validate:"omitempty,oneof=page cursor"`
+	Cursor     string `query:"cursor"`
+	Page       int64  `query:"page"`
+	Limit      int64  `query:"limit"`
+}
+
+func (q *AdminAccountListQuery) usesCursor() bool {
+	return q.Pagination == "cursor" || q.Cursor != ""
+}
+
+type AdminAccountExportQuery struct {
+	AdminAccountListQuery
+	Format string `query:"format" validate:"omitempty,oneof=csv ndjson"`
+}
+
+// AdminAccountCursorPage is a page of a cursor-paginated account list. Pass
+// NextCursor back as the cursor parameter to get the following page.
+type AdminAccountCursorPage struct {
+	Data       []*AccountResponse `json:"data"`
+	Limit      int64              `json:"limit"`
+	HasNext    bool               `json:"has_next"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+type AdminBulkAccountRequest struct {
+	Action     string   `json:"action" validate:"required,oneof=deactivate reactivate force_password_reset"`
+	AccountIDs []string `json:"account_ids" validate:"required,min=1,max=100,dive,mongodb"`
+}
+
+type AdminBulkAccountFailure struct {
+	AccountID string `json:"account_id"`
+	Error     string `json:"error"`
+}
+
+// AdminBulkAccountResponse reports the outcome for every account of a bulk
+// request; one account failing does not stop the others.
+type AdminBulkAccountResponse struct {
+	Action    string                    `json:"action"`
+	Succeeded []string                  `json:"succeeded"`
+	Failed    []AdminBulkAccountFailure `json:"failed"`
+}
+
+// accountCursor marks the last account of a page: the value of the sort
+// field and the ID, which breaks ties.
+type accountCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+func newAccountCursor(sort string, account *Account) string {
+	field, _ := parseAccountSort(sort)
+
+	var value string
+	switch field {
+	case "created_at":
+		value = account.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case "updated_at":
+		value = account.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	case "email":
+		value = account.Email
+	case "username":
+		value = account.Username
+	}
+
+	encoded, _ := json.Marshal(accountCursor{Sort: sort, Value: value, ID: account.ID.Hex()})
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// decodeAccountCursor returns the sort value and ID a cursor points at. A
+// cursor only continues the sort order it was issued for.
+func decodeAccountCursor(encoded, sort string) (any, primitive.ObjectID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, primitive.NilObjectID, fmt.Errorf("invalid cursor")
+	}
+
+	var cursor accountCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.Sort != sort {
+		return nil, primitive.NilObjectID, fmt.Errorf("invalid cursor")
+	}
+
+	id, err := primitive.ObjectIDFromHex(cursor.ID)
+	if err != nil {
+		return nil, primitive.NilObjectID, fmt.Errorf("invalid cursor")
+	}
+
+	field, _ := parseAccountSort(sort)
+	if field == "created_at" || field == "updated_at" {
+		value, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return nil, primitive.NilObjectID, fmt.Errorf("invalid cursor")
+		}
+		return value, id, nil
+	}
+
+	return cursor.Value, id, nil
+}
+
+// parseAccountSort splits a sort parameter into the field and the Mongo sort
+// direction, defaulting to the newest accounts first.
+func parseAccountSort(sort string) (string, int) {
+	if sort == "" {
+		return "created_at", -1
+	}
+	if sort[0] == '-' {
+		return sort[1:], -1
+	}
+	return sort, 1
+}
@@ -0,0 +1,717 @@
+package account
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/jwt"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/redis"
+)
+
+type AdminService interface {
+	ListAccounts(ctx context.Context, query *AdminAccountListQuery) (*mongo.PaginatedResult[*AccountResponse], error)
+	GetAccount(ctx context.Context, accountID string) (*AccountResponse, error)
+	DeactivateAccount(ctx context.Context, actorID, accountID string) (*AccountResponse, error)
+	RestoreAccount(ctx context.Context, actorID, accountID string) (*AccountResponse, error)
+	Impersonate(ctx context.Context, actorID, accountID, userAgent, ipAddress string) (*ImpersonationResponse, error)
+	ListAccountsByCursor(ctx context.Context, query *AdminAccountListQuery) (*AdminAccountCursorPage, error)
+	ReactivateAccount(ctx context.Context, actorID, accountID string) (*AccountResponse, error)
+	ForcePasswordReset(ctx context.Context, actorID, accountID string) (*AccountResponse, error)
+	BulkUpdateAccounts(ctx context.Context, actorID string, req *AdminBulkAccountRequest) (*AdminBulkAccountResponse, error)
+	ExportAccounts(ctx context.Context, actorID string, query *AdminAccountListQuery, format string) (func(w io.Writer) error, error)
+}
+
+// passwordResetSender sends the password reset code used by ForcePasswordReset.
+// It is implemented by the account service.
+type passwordResetSender interface {
+	sendPasswordResetCode(ctx context.Context, email string) error
+}
+
+type adminService struct {
+	accountRepository         AccountRepository
+	accountIdentityRepository AccountIdentityRepository
+	rbacService               RBACService
+	jwtService                *jwt.JWTService
+	auditService              AuditRecorder
+	passwordResets            passwordResetSender
+}
+
+func NewAdminService(
+	mongoService *mongo.MongoService,
+	cacheService redis.RedisService,
+	jwtService *jwt.JWTService,
+	rbacService RBACService,
+	auditService AuditRecorder,
+	accountService AccountService,
+) AdminService {
+	service := newAdminService(
+		NewAccountRepository(mongoService),
+		newAccountIdentityRepository(mongoService, cacheService),
+		rbacService,
+		jwtService,
+	)
+	service.auditService = auditService
+	if sender, ok := accountService.(passwordResetSender); ok {
+		service.passwordResets = sender
+	}
+
+	return service
+}
+
+func newAdminService(
+	accountRepository AccountRepository,
+	accountIdentityRepository AccountIdentityRepository,
+	rbacService RBACService,
+	jwtService *jwt.JWTService,
+) *adminService {
+	return &adminService{
+		accountRepository:         accountRepository,
+		accountIdentityRepository: accountIdentityRepository,
+		rbacService:               rbacService,
+		jwtService:                jwtService,
+	}
+}
+
+// ListAccounts pages through the accounts matching query with page numbers.
+func (s *adminService) ListAccounts(ctx context.Context, query *AdminAccountListQuery) (*mongo.PaginatedResult[*AccountResponse], error) {
+	filter, sort, err := adminAccountFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := adminAccountListLimit(query.Limit)
+	result, err := s.accountRepository.Search(ctx, filter, sort, mongo.PaginationOptions{
+		Page:  query.Page,
+		Limit: limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+
+	accounts := make([]*AccountResponse, len(result.Data))
+	for i := range result.Data {
+		accounts[i] = result.Data[i].ToResponse()
+	}
+
+	return &mongo.PaginatedResult[*AccountResponse]{
+		Data:       accounts,
+		Total:      result.Total,
+		Page:       result.Page,
+		Limit:      result.Limit,
+		TotalPages: result.TotalPages,
+		HasNext:    result.HasNext,
+		HasPrev:    result.HasPrev,
+	}, nil
+}
+
+// ListAccountsByCursor pages through the accounts matching query with keyset
+// pagination, which stays stable while accounts are added and does not slow
+// down on deep pages.
+func (s *adminService) ListAccountsByCursor(ctx context.Context, query *AdminAccountListQuery) (*AdminAccountCursorPage, error) {
+	filter, sort, err := adminAccountFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.Cursor != "" {
+		filter, err = afterAccountCursor(filter, query.Sort, query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	limit := adminAccountListLimit(query.Limit)
+	batch, err := s.accountRepository.FindBatch(ctx, filter, sort, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+
+	page := &AdminAccountCursorPage{
+		Data:    make([]*AccountResponse, 0, len(batch)),
+		Limit:   limit,
+		HasNext: int64(len(batch)) > limit,
+	}
+	if page.HasNext {
+		batch = batch[:limit]
+		page.NextCursor = newAccountCursor(query.Sort, &batch[len(batch)-1])
+	}
+
+	for i := range batch {
+		page.Data = append(page.Data, batch[i].ToResponse())
+	}
+
+	return page, nil
+}
+
+func adminAccountListLimit(limit int64) int64 {
+	if limit <= 0 {
+		return AdminAccountListDefaultLimit
+	}
+	if limit > AdminAccountListMaxLimit {
+		return AdminAccountListMaxLimit
+	}
+	return limit
+}
+
+// adminAccountFilter turns query into a Mongo filter and sort. Every search
+// term must match the email, username or a name, case-insensitively. The
+// email prefix is case-insensitive as well, since emails are stored as
+// entered.
+func adminAccountFilter(query *AdminAccountListQuery) (bson.M, bson.D, error) {
+	filter := bson.M{}
+	var clauses bson.A
+
+	for _, term := range strings.Fields(query.Query) {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(term), Options: "i"}
+		clauses = append(clauses, bson.M{"$or": bson.A{
+			bson.M{"email": pattern},
+			bson.M{"username": pattern},
+			bson.M{"first_name": pattern},
+			bson.M{"last_name": pattern},
+		}})
+	}
+
+	if query.EmailPrefix != "" {
+		filter["email"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(query.EmailPrefix), Options: "i"}
+	}
+
+	if query.UsernamePrefix != "" {
+		filter["username"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(query.UsernamePrefix)}
+	}
+
+	if query.IsActive != nil {
+		filter["is_active"] = *query.IsActive
+	}
+
+	if query.Status != "" {
+		filter["status"] = query.Status
+	}
+
+	if query.Role != "" {
+		filter["roles"] = query.Role
+	}
+
+	createdAt := bson.M{}
+	if query.CreatedFrom != "" {
+		from, err := time.Parse(time.RFC3339, query.CreatedFrom)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid created_from: expected an RFC 3339 timestamp")
+		}
+		createdAt["$gte"] = from
+	}
+	if query.CreatedTo != "" {
+		to, err := time.Parse(time.RFC3339, query.CreatedTo)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid created_to: expected an RFC 3339 timestamp")
+		}
+		createdAt["$lt"] = to
+	}
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+
+	switch len(clauses) {
+	case 0:
+	case 1:
+		filter["$or"] = clauses[0].(bson.M)["$or"]
+	default:
+		filter["$and"] = clauses
+	}
+
+	field, direction := parseAccountSort(query.Sort)
+	if !adminAccountSortFields[field] {
+		return nil, nil, fmt.Errorf("invalid sort: %s", query.Sort)
+	}
+
+	return filter, bson.D{{Key: field, Value: direction}, {Key: "_id", Value: direction}}, nil
+}
+
+// afterAccountCursor narrows filter to the accounts that sort after cursor.
+func afterAccountCursor(filter bson.M, sort, cursor string) (bson.M, error) {
+	value, id, err := decodeAccountCursor(cursor, sort)
+	if err != nil {
+		return nil, err
+	}
+
+	field, direction := parseAccountSort(sort)
+	operator := "$gt"
+	if direction < 0 {
+		operator = "$lt"
+	}
+
+	return bson.M{"$and": bson.A{
+		filter,
+		bson.M{"$or": bson.A{
+			bson.M{field: bson.M{operator: value}},
+			bson.M{field: value, "_id": bson.M{operator: id}},
+		}},
+	}}, nil
+}
+
+func (s *adminService) GetAccount(ctx context.Context, accountID string) (*AccountResponse, error) {
+	account, err := s.getAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return account.ToResponse(), nil
+}
+
+func (s *adminService) DeactivateAccount(ctx context.Context, actorID, accountID string) (*AccountResponse, error) {
+	if actorID == accountID {
+		return nil, fmt.Errorf("cannot deactivate your own account")
+	}
+
+	account, err := s.getAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.CurrentStatus() == AccountStatusDeleted {
+		return nil, fmt.Errorf("account not found")
+	}
+
+	updated, err := s.accountRepository.Update(ctx, account.ID, statusUpdate(AccountStatusSuspended))
+	if err != nil {
+		return nil, fmt.Errorf("failed to deactivate account: %w", err)
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("account not found")
+	}
+
+	recordAudit(ctx, s.auditService, &AuditEvent{
+		Type:     AuditEventAccountSuspend,
+		ActorID:  actorID,
+		TargetID: accountID,
+	})
+
+	if err := s.accountIdentityRepository.DeactivateAllUserSessions(ctx, accountID); err != nil {
+		fmt.Printf("Failed to deactivate sessions of account %s: %v\n", accountID, err)
+	} else {
+		recordAudit(ctx, s.auditService, &AuditEvent{
+			Type:     AuditEventSessionRevoke,
+			ActorID:  actorID,
+			TargetID: accountID,
+			Reason:   "account suspended",
+		})
+	}
+
+	return updated.ToResponse(), nil
+}
+
+// RestoreAccount undoes a deletion that has not been purged yet. The account
+// returns to pending when its email was never verified.
+func (s *adminService) RestoreAccount(ctx context.Context, actorID, accountID string) (*AccountResponse, error) {
+	account, err := s.getAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.CurrentStatus() != AccountStatusDeleted {
+		return nil, fmt.Errorf("cannot restore an account that is not deleted")
+	}
+
+	if purgeAt := account.PurgeAt(); purgeAt != nil && time.Now().After(*purgeAt) {
+		return nil, fmt.Errorf("account not found: deletion grace period has ended")
+	}
+
+	status := AccountStatusPending
+	if account.IsEmailVerified() {
+		status = AccountStatusActive
+	}
+
+	updateData := statusUpdate(status)
+	updateData["deleted_at"] = nil
+
+	updated, err := s.accountRepository.Update(ctx, account.ID, updateData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore account: %w", err)
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("account not found")
+	}
+
+	recordAudit(ctx, s.auditService, &AuditEvent{
+		Type:     AuditEventAccountRestore,
+		ActorID:  actorID,
+		TargetID: accountID,
+		Metadata: map[string]string{"status": string(status)},
+	})
+
+	return updated.ToResponse(), nil
+}
+
+// ReactivateAccount lifts a suspension. The account returns to pending when
+// its email was never verified.
+func (s *adminService) ReactivateAccount(ctx context.Context, actorID, accountID string) (*AccountResponse, error) {
+	account, err := s.getAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.CurrentStatus() != AccountStatusSuspended {
+		return nil, fmt.Errorf("cannot reactivate an account that is not suspended")
+	}
+
+	status := AccountStatusPending
+	if account.IsEmailVerified() {
+		status = AccountStatusActive
+	}
+
+	updated, err := s.accountRepository.Update(ctx, account.ID, statusUpdate(status))
+	if err != nil {
+		return nil, fmt.Errorf("failed to reactivate account: %w", err)
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("account not found")
+	}
+
+	recordAudit(ctx, s.auditService, &AuditEvent{
+		Type:     AuditEventAccountReactivate,
+		ActorID:  actorID,
+		TargetID: accountID,
+		Metadata: map[string]string{"status": string(status)},
+	})
+
+	return updated.ToResponse(), nil
+}
+
+// ForcePasswordReset blocks sign-in until the account resets its password,
+// signs it out everywhere and emails it a reset code.
+func (s *adminService) ForcePasswordReset(ctx context.Context, actorID, accountID string) (*AccountResponse, error) {
+	if actorID == accountID {
+		return nil, fmt.Errorf("cannot force a password reset on your own account")
+	}
+
+	account, err := s.getAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.CurrentStatus() == AccountStatusDeleted {
+		return nil, fmt.Errorf("account not found")
+	}
+
+	updated, err := s.accountRepository.Update(ctx, account.ID, bson.M{
+		"password_reset_required": true,
+		"updated_at":              time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to require password reset: %w", err)
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("account not found")
+	}
+
+	recordAudit(ctx, s.auditService, &AuditEvent{
+		Type:     AuditEventPasswordResetRequired,
+		ActorID:  actorID,
+		TargetID: accountID,
+	})
+
+	if err := s.accountIdentityRepository.DeactivateAllUserSessions(ctx, accountID); err != nil {
+		fmt.Printf("Failed to deactivate sessions of account %s: %v\n", accountID, err)
+	} else {
+		recordAudit(ctx, s.auditService, &AuditEvent{
+			Type:     AuditEventSessionRevoke,
+			ActorID:  actorID,
+			TargetID: accountID,
+			Reason:   "password reset required",
+		})
+	}
+
+	if s.passwordResets != nil {
+		if err := s.passwordResets.sendPasswordResetCode(ctx, account.Email); err != nil {
+			fmt.Printf("Failed to send password reset code: %v\n", err)
+		}
+	}
+
+	return updated.ToResponse(), nil
+}
+
+// BulkUpdateAccounts applies one action to every account in req and reports
+// the outcome per account.
+func (s *adminService) BulkUpdateAccounts(ctx context.Context, actorID string, req *AdminBulkAccountRequest) (*AdminBulkAccountResponse, error) {
+	var apply func(ctx context.Context, actorID, accountID string) (*AccountResponse, error)
+	switch req.Action {
+	case AdminBulkActionDeactivate:
+		apply = s.DeactivateAccount
+	case AdminBulkActionReactivate:
+		apply = s.ReactivateAccount
+	case AdminBulkActionForcePasswordReset:
+		apply = s.ForcePasswordReset
+	default:
+		return nil, fmt.Errorf("invalid bulk action: %s", req.Action)
+	}
+
+	if len(req.AccountIDs) > AdminAccountBulkMaxSize {
+		return nil, fmt.Errorf("invalid bulk request: at most %d accounts per request", AdminAccountBulkMaxSize)
+	}
+
+	response := &AdminBulkAccountResponse{
+		Action:    req.Action,
+		Succeeded: []string{},
+		Failed:    []AdminBulkAccountFailure{},
+	}
+
+	seen := make(map[string]bool, len(req.AccountIDs))
+	for _, accountID := range req.AccountIDs {
+		if seen[accountID] {
+			continue
+		}
+		seen[accountID] = true
+
+		if _, err := apply(ctx, actorID, accountID); err != nil {
+			response.Failed = append(response.Failed, AdminBulkAccountFailure{AccountID: accountID, Error: err.Error()})
+			continue
+		}
+		response.Succeeded = append(response.Succeeded, accountID)
+	}
+
+	return response, nil
+}
+
+// ExportAccounts validates query and returns a function that writes every
+// matching account to w as CSV or NDJSON. Accounts are read in batches so
+// that large exports do not have to fit in memory.
+func (s *adminService) ExportAccounts(ctx context.Context, actorID string, query *AdminAccountListQuery, format string) (func(w io.Writer) error, error) {
+	if format == "" {
+		format = AccountExportFormatCSV
+	}
+	if format != AccountExportFormatCSV && format != AccountExportFormatNDJSON {
+		return nil, fmt.Errorf("invalid export format: %s", format)
+	}
+
+	filter, sort, err := adminAccountFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(w io.Writer) error {
+		var writer accountExportWriter
+		if format == AccountExportFormatNDJSON {
+			writer = newNDJSONAccountWriter(w)
+		} else {
+			writer = newCSVAccountWriter(w)
+		}
+
+		count := 0
+		batchFilter := filter
+		for {
+			batch, err := s.accountRepository.FindBatch(ctx, batchFilter, sort, adminAccountExportBatchSize)
+			if err != nil {
+				return fmt.Errorf("failed to export accounts: %w", err)
+			}
+
+			for i := range batch {
+				if err := writer.Write(&batch[i]); err != nil {
+					return fmt.Errorf("failed to write account export: %w", err)
+				}
+			}
+			count += len(batch)
+
+			if len(batch) < adminAccountExportBatchSize {
+				break
+			}
+
+			batchFilter, err = afterAccountCursor(filter, query.Sort, newAccountCursor(query.Sort, &batch[len(batch)-1]))
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := writer.Flush(); err != nil {
+			return fmt.Errorf("failed to write account export: %w", err)
+		}
+
+		recordAudit(ctx, s.auditService, &AuditEvent{
+			Type:    AuditEventAccountExport,
+			ActorID: actorID,
+			Metadata: map[string]string{
+				"format": format,
+				"count":  fmt.Sprint(count),
+			},
+		})
+
+		return nil
+	}, nil
+}
+
+type accountExportWriter interface {
+	Write(account *Account) error
+	Flush() error
+}
+
+var accountExportColumns = []string{
+	"id", "email", "username", "first_name", "last_name", "status", "is_active",
+	"roles", "email_verified_at", "created_at", "updated_at",
+}
+
+type csvAccountWriter struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func newCSVAccountWriter(w io.Writer) *csvAccountWriter {
+	return &csvAccountWriter{writer: csv.NewWriter(w)}
+}
+
+func (c *csvAccountWriter) Write(account *Account) error {
+	if !c.headerWritten {
+		if err := c.writer.Write(accountExportColumns); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+
+	var emailVerifiedAt string
+	if account.EmailVerifiedAt != nil {
+		emailVerifiedAt = account.EmailVerifiedAt.UTC().Format(time.RFC3339)
+	}
+
+	return c.writer.Write([]string{
+		account.ID.Hex(),
+		csvSafe(account.Email),
+		csvSafe(account.Username),
+		csvSafe(account.FirstName),
+		csvSafe(account.LastName),
+		string(account.CurrentStatus()),
+		fmt.Sprint(account.IsActive),
+		csvSafe(strings.Join(account.Roles, ";")),
+		emailVerifiedAt,
+		account.CreatedAt.UTC().Format(time.RFC3339),
+		account.UpdatedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+func (c *csvAccountWriter) Flush() error {
+	if !c.headerWritten {
+		if err := c.writer.Write(accountExportColumns); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// csvSafe keeps spreadsheet applications from evaluating user-controlled
+// values as formulas.
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+type ndjsonAccountWriter struct {
+	encoder *json.Encoder
+}
+
+func newNDJSONAccountWriter(w io.Writer) *ndjsonAccountWriter {
+	return &ndjsonAccountWriter{encoder: json.NewEncoder(w)}
+}
+
+func (n *ndjsonAccountWriter) Write(account *Account) error {
+	return n.encoder.Encode(account.ToResponse())
+}
+
+func (n *ndjsonAccountWriter) Flush() error {
+	return nil
+}
+
+// Impersonate issues a short-lived session for another account. The token
+// records the administrator in impersonator_id so that actions taken with it
+// can be attributed.
+func (s *adminService) Impersonate(ctx context.Context, actorID, accountID, userAgent, ipAddress string) (*ImpersonationResponse, error) {
+	if actorID == accountID {
+		return nil, fmt.Errorf("cannot impersonate your own account")
+	}
+
+	account, err := s.getAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !account.IsActive {
+		return nil, fmt.Errorf("account is inactive")
+	}
+
+	privileged, err := s.rbacService.HasPermission(ctx, accountID, PermissionAccountsImpersonate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check target permissions: %w", err)
+	}
+	if privileged {
+		return nil, fmt.Errorf("cannot impersonate an account that can impersonate others")
+	}
+
+	claims := &AccountJWTClaims{
+		AccountID:      account.ID.Hex(),
+		Email:          account.Email,
+		Username:       account.Username,
+		ImpersonatorID: actorID,
+	}
+
+	token, err := s.jwtService.GenerateWithDuration(claims.ToCustomClaims(), ImpersonationTokenExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	expiresAt := time.Now().Add(ImpersonationTokenExpiry)
+	_, err = s.accountIdentityRepository.CreateSession(ctx, &Session{
+		AccountID:      account.ID.Hex(),
+		TokenHash:      hashSecret(token),
+		IsActive:       true,
+		ExpiresAt:      expiresAt,
+		UserAgent:      userAgent,
+		IPAddress:      ipAddress,
+		ImpersonatorID: actorID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	recordAudit(ctx, s.auditService, &AuditEvent{
+		Type:      AuditEventImpersonation,
+		ActorID:   actorID,
+		TargetID:  accountID,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Metadata:  map[string]string{"expires_at": expiresAt.UTC().Format(time.RFC3339)},
+	})
+
+	return &ImpersonationResponse{
+		Token:          token,
+		ExpiresAt:      expiresAt,
+		ImpersonatorID: actorID,
+		Account:        account.ToResponse(),
+	}, nil
+}
+
+func (s *adminService) getAccount(ctx context.Context, accountID string) (*Account, error) {
+	objectID, err := primitive.ObjectIDFromHex(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account ID format: %w", err)
+	}
+
+	account, err := s.accountRepository.GetByID(ctx, objectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	if account == nil {
+		return nil, fmt.Errorf("account not found")
+	}
+
+	return account, nil
+}
@@ -0,0 +1,386 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/jwt"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+func setupAdminService(t *testing.T) (*adminService, *MockAccountRepository, *MockAccountIdentityRepository, *MockRBACService, *jwt.JWTService) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockIdentityRepo := &MockAccountIdentityRepository{}
+	mockRBACService := &MockRBACService{}
+
+	jwtService, err := jwt.NewJWTService(jwt.JWTConfig{
+		SecretKey:     "test-secret-key-for-testing-purposes",
+		TokenDuration: 24 * time.Hour,
+		Issuer:        "test-platform",
+	})
+	require.NoError(t, err)
+
+	service := newAdminService(mockAccountRepo, mockIdentityRepo, mockRBACService, jwtService)
+
+	return service, mockAccountRepo, mockIdentityRepo, mockRBACService, jwtService
+}
+
+func TestAdminService_ListAccounts(t *testing.T) {
+	service, mockAccountRepo, _, _, _ := setupAdminService(t)
+	active := true
+
+	mockAccountRepo.On("Search", mock.Anything, mock.MatchedBy(func(filter bson.M) bool {
+		or, ok := filter["$or"].(bson.A)
+		if !ok || len(or) != 4 {
+			return false
+		}
+		email := or[0].(bson.M)["email"].(primitive.Regex)
+		return email.Pattern == `jane\.doe` && email.Options == "i" &&
+			filter["is_active"] == true && filter["roles"] == RoleSupport
+	}), bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}, mongo.PaginationOptions{Page: 2, Limit: AdminAccountListMaxLimit}).Return(&mongo.PaginatedResult[Account]{
+		Data:  []Account{*CreateTestAccount()},
+		Total: 1,
+		Page:  2,
+		Limit: AdminAccountListMaxLimit,
+	}, nil)
+
+	result, err := service.ListAccounts(context.Background(), &AdminAccountListQuery{
+		Query:    "jane.doe",
+		IsActive: &active,
+		Role:     RoleSupport,
+		Page:     2,
+		Limit:    1000,
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, result.Data, 1)
+	assert.Equal(t, int64(1), result.Total)
+	mockAccountRepo.AssertExpectations(t)
+}
+
+func TestAdminAccountFilter(t *testing.T) {
+	filter, sort, err := adminAccountFilter(&AdminAccountListQuery{
+		Query:          "jane doe",
+		Status:         AccountStatusSuspended,
+		EmailPrefix:    "Jane.",
+		UsernamePrefix: "jd",
+		CreatedFrom:    "2024-01-01T00:00:00Z",
+		CreatedTo:      "2024-02-01T00:00:00Z",
+		Sort:           "email",
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, filter["$and"], 2, "every search term must match")
+	assert.Equal(t, primitive.Regex{Pattern: `^Jane\.`, Options: "i"}, filter["email"])
+	assert.Equal(t, primitive.Regex{Pattern: `^jd`}, filter["username"])
+	assert.Equal(t, AccountStatusSuspended, filter["status"])
+	assert.Equal(t, bson.M{
+		"$gte": time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		"$lt":  time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}, filter["created_at"])
+	assert.Equal(t, bson.D{{Key: "email", Value: 1}, {Key: "_id", Value: 1}}, sort)
+
+	for _, prefix := range []string{"Alice", "alice", "ALICE@"} {
+		filter, _, err := adminAccountFilter(&AdminAccountListQuery{EmailPrefix: prefix})
+		require.NoError(t, err)
+		pattern := filter["email"].(primitive.Regex)
+		assert.Regexp(t, "(?"+pattern.Options+")"+pattern.Pattern, "Alice@example.com", prefix)
+	}
+
+	_, _, err = adminAccountFilter(&AdminAccountListQuery{CreatedFrom: "yesterday"})
+	assert.ErrorContains(t, err, "invalid created_from")
+
+	_, _, err = adminAccountFilter(&AdminAccountListQuery{Sort: "password_hash"})
+	assert.ErrorContains(t, err, "invalid sort")
+}
+
+func TestAdminService_ListAccountsByCursor(t *testing.T) {
+	service, mockAccountRepo, _, _, _ := setupAdminService(t)
+	sort := bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}
+
+	accounts := make([]Account, 3)
+	for i := range accounts {
+		accounts[i] = *CreateTestAccount(func(a *Account) {
+			a.CreatedAt = time.Date(2024, 1, 3-i, 0, 0, 0, 0, time.UTC)
+		})
+	}
+
+	mockAccountRepo.On("FindBatch", mock.Anything, bson.M{}, sort, int64(3)).Return(accounts, nil).Once()
+
+	page, err := service.ListAccountsByCursor(context.Background(), &AdminAccountListQuery{Pagination: "cursor", Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, page.Data, 2)
+	assert.True(t, page.HasNext)
+	require.NotEmpty(t, page.NextCursor)
+
+	mockAccountRepo.On("FindBatch", mock.Anything, mock.MatchedBy(func(filter bson.M) bool {
+		and, ok := filter["$and"].(bson.A)
+		if !ok || len(and) != 2 {
+			return false
+		}
+		after := and[1].(bson.M)["$or"].(bson.A)
+		return assert.ObjectsAreEqual(bson.M{"created_at": bson.M{"$lt": accounts[1].CreatedAt}}, after[0])
+	}), sort, int64(3)).Return(accounts[2:], nil).Once()
+
+	page, err = service.ListAccountsByCursor(context.Background(), &AdminAccountListQuery{Cursor: page.NextCursor, Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, page.Data, 1)
+	assert.False(t, page.HasNext)
+	assert.Empty(t, page.NextCursor)
+
+	_, err = service.ListAccountsByCursor(context.Background(), &AdminAccountListQuery{Cursor: page.NextCursor + "x", Sort: "email"})
+	assert.ErrorContains(t, err, "invalid cursor")
+	mockAccountRepo.AssertExpectations(t)
+}
+
+func TestAdminService_DeactivateAccount(t *testing.T) {
+	service, mockAccountRepo, mockIdentityRepo, _, _ := setupAdminService(t)
+	target := CreateTestAccount()
+
+	_, err := service.DeactivateAccount(context.Background(), target.ID.Hex(), target.ID.Hex())
+	assert.ErrorContains(t, err, "cannot deactivate your own account")
+
+	mockAccountRepo.On("GetByID", mock.Anything, target.ID).Return(target, nil)
+	mockAccountRepo.On("Update", mock.Anything, target.ID, mock.MatchedBy(func(update bson.M) bool {
+		return update["is_active"] == false
+	})).Return(CreateTestAccount(func(a *Account) {
+		a.ID = target.ID
+		a.IsActive = false
+	}), nil)
+	mockIdentityRepo.On("DeactivateAllUserSessions", mock.Anything, target.ID.Hex()).Return(nil).Once()
+
+	response, err := service.DeactivateAccount(context.Background(), primitive.NewObjectID().Hex(), target.ID.Hex())
+	require.NoError(t, err)
+	assert.False(t, response.IsActive)
+	mockIdentityRepo.AssertExpectations(t)
+}
+
+func TestAdminService_Impersonate(t *testing.T) {
+	actorID := primitive.NewObjectID().Hex()
+
+	tests := []struct {
+		name          string
+		target        *Account
+		privileged    bool
+		errorContains string
+	}{
+		{
+			name:   "regular account",
+			target: CreateTestAccount(),
+		},
+		{
+			name:          "inactive account",
+			target:        CreateTestAccount(func(a *Account) { a.IsActive = false }),
+			errorContains: "inactive",
+		},
+		{
+			name:          "another administrator",
+			target:        CreateTestAccount(func(a *Account) { a.Roles = []string{RoleAdmin} }),
+			privileged:    true,
+			errorContains: "cannot impersonate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockAccountRepo, mockIdentityRepo, mockRBACService, jwtService := setupAdminService(t)
+
+			var session Session
+			mockAccountRepo.On("GetByID", mock.Anything, tt.target.ID).Return(tt.target, nil)
+			mockRBACService.On("HasPermission", mock.Anything, tt.target.ID.Hex(), PermissionAccountsImpersonate).Return(tt.privileged, nil)
+			mockIdentityRepo.On("CreateSession", mock.Anything, mock.AnythingOfType("*account.Session")).
+				Run(func(args mock.Arguments) {
+					session = *args.Get(1).(*Session)
+				}).
+				Return(&session, nil)
+
+			response, err := service.Impersonate(context.Background(), actorID, tt.target.ID.Hex(), "agent", "127.0.0.1")
+
+			if tt.errorContains != "" {
+				assert.ErrorContains(t, err, tt.errorContains)
+				mockIdentityRepo.AssertNotCalled(t, "CreateSession", mock.Anything, mock.Anything)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, actorID, response.ImpersonatorID)
+			assert.Equal(t, actorID, session.ImpersonatorID)
+			assert.Equal(t, hashSecret(response.Token), session.TokenHash)
+			assert.WithinDuration(t, time.Now().Add(ImpersonationTokenExpiry), session.ExpiresAt, time.Minute)
+
+			claims, err := jwtService.Verify(response.Token)
+			require.NoError(t, err)
+			accountClaims := NewAccountJWTClaimsFromCustom(claims.CustomClaims)
+			assert.Equal(t, tt.target.ID.Hex(), accountClaims.AccountID)
+			assert.Equal(t, actorID, accountClaims.ImpersonatorID)
+		})
+	}
+}
+
+func TestAdminService_ImpersonationCannotBeRefreshed(t *testing.T) {
+	service, mockAccountRepo, mockIdentityRepo, mockRBACService, _ := setupAdminService(t)
+	accountService, mockAccounts, mockIdentities, _ := setupAccountService()
+	target := CreateTestAccount()
+
+	var session Session
+	mockAccountRepo.On("GetByID", mock.Anything, target.ID).Return(target, nil)
+	mockRBACService.On("HasPermission", mock.Anything, target.ID.Hex(), PermissionAccountsImpersonate).Return(false, nil)
+	mockIdentityRepo.On("CreateSession", mock.Anything, mock.AnythingOfType("*account.Session")).
+		Run(func(args mock.Arguments) {
+			session = *args.Get(1).(*Session)
+		}).
+		Return(&session, nil)
+
+	response, err := service.Impersonate(context.Background(), primitive.NewObjectID().Hex(), target.ID.Hex(), "agent", "127.0.0.1")
+	require.NoError(t, err)
+
+	mockIdentities.On("GetSessionByToken", mock.Anything, hashSecret(response.Token)).Return(&session, nil)
+	mockIdentities.On("UpdateSessionLastUsed", mock.Anything, mock.Anything).Return(nil)
+	mockAccounts.On("GetByID", mock.Anything, target.ID).Return(target, nil)
+
+	_, err = accountService.RefreshToken(context.Background(), response.Token, "agent", "127.0.0.1")
+
+	assert.EqualError(t, err, "invalid token: impersonation tokens cannot be refreshed")
+	mockIdentities.AssertNotCalled(t, "DeactivateSession", mock.Anything, mock.Anything)
+	mockIdentities.AssertNotCalled(t, "CreateSession", mock.Anything, mock.Anything)
+}
+
+func TestAdminService_ReactivateAccount(t *testing.T) {
+	service, mockAccountRepo, _, _, _ := setupAdminService(t)
+	actorID := primitive.NewObjectID().Hex()
+
+	active := CreateTestAccount()
+	mockAccountRepo.On("GetByID", mock.Anything, active.ID).Return(active, nil)
+	_, err := service.ReactivateAccount(context.Background(), actorID, active.ID.Hex())
+	assert.ErrorContains(t, err, "cannot reactivate")
+
+	verifiedAt := time.Now()
+	suspended := CreateTestAccount(func(a *Account) {
+		a.EmailVerifiedAt = &verifiedAt
+		a.IsActive = false
+		a.Status = AccountStatusSuspended
+	})
+	mockAccountRepo.On("GetByID", mock.Anything, suspended.ID).Return(suspended, nil)
+	mockAccountRepo.On("Update", mock.Anything, suspended.ID, mock.MatchedBy(func(update bson.M) bool {
+		return update["status"] == AccountStatusActive && update["is_active"] == true
+	})).Return(CreateTestAccount(func(a *Account) { a.ID = suspended.ID }), nil)
+
+	response, err := service.ReactivateAccount(context.Background(), actorID, suspended.ID.Hex())
+	require.NoError(t, err)
+	assert.True(t, response.IsActive)
+	mockAccountRepo.AssertExpectations(t)
+}
+
+type mockPasswordResetSender struct {
+	emails []string
+}
+
+func (m *mockPasswordResetSender) sendPasswordResetCode(ctx context.Context, email string) error {
+	m.emails = append(m.emails, email)
+	return nil
+}
+
+func TestAdminService_ForcePasswordReset(t *testing.T) {
+	service, mockAccountRepo, mockIdentityRepo, _, _ := setupAdminService(t)
+	sender := &mockPasswordResetSender{}
+	service.passwordResets = sender
+	target := CreateTestAccount()
+
+	_, err := service.ForcePasswordReset(context.Background(), target.ID.Hex(), target.ID.Hex())
+	assert.ErrorContains(t, err, "cannot force a password reset")
+
+	mockAccountRepo.On("GetByID", mock.Anything, target.ID).Return(target, nil)
+	mockAccountRepo.On("Update", mock.Anything, target.ID, mock.MatchedBy(func(update bson.M) bool {
+		return update["password_reset_required"] == true
+	})).Return(target, nil)
+	mockIdentityRepo.On("DeactivateAllUserSessions", mock.Anything, target.ID.Hex()).Return(nil).Once()
+
+	_, err = service.ForcePasswordReset(context.Background(), primitive.NewObjectID().Hex(), target.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, []string{target.Email}, sender.emails)
+	mockAccountRepo.AssertExpectations(t)
+	mockIdentityRepo.AssertExpectations(t)
+}
+
+func TestAdminService_BulkUpdateAccounts(t *testing.T) {
+	service, mockAccountRepo, mockIdentityRepo, _, _ := setupAdminService(t)
+	actorID := primitive.NewObjectID().Hex()
+	target := CreateTestAccount()
+	missing := primitive.NewObjectID()
+
+	mockAccountRepo.On("GetByID", mock.Anything, target.ID).Return(target, nil)
+	mockAccountRepo.On("GetByID", mock.Anything, missing).Return(nil, nil)
+	mockAccountRepo.On("Update", mock.Anything, target.ID, mock.Anything).Return(target, nil).Once()
+	mockIdentityRepo.On("DeactivateAllUserSessions", mock.Anything, target.ID.Hex()).Return(nil).Once()
+
+	response, err := service.BulkUpdateAccounts(context.Background(), actorID, &AdminBulkAccountRequest{
+		Action:     AdminBulkActionDeactivate,
+		AccountIDs: []string{target.ID.Hex(), missing.Hex(), actorID, target.ID.Hex()},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{target.ID.Hex()}, response.Succeeded)
+	require.Len(t, response.Failed, 2)
+	assert.Equal(t, missing.Hex(), response.Failed[0].AccountID)
+	assert.Contains(t, response.Failed[0].Error, "not found")
+	assert.Contains(t, response.Failed[1].Error, "cannot deactivate your own account")
+	mockAccountRepo.AssertExpectations(t)
+
+	_, err = service.BulkUpdateAccounts(context.Background(), actorID, &AdminBulkAccountRequest{Action: "delete"})
+	assert.ErrorContains(t, err, "invalid bulk action")
+}
+
+func TestAdminService_ExportAccounts(t *testing.T) {
+	sort := bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}
+	account := CreateTestAccount(func(a *Account) {
+		a.FirstName = "=HYPERLINK(\"http://evil\")"
+		a.Roles = []string{RoleSupport, "editor"}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		service, mockAccountRepo, _, _, _ := setupAdminService(t)
+		mockAccountRepo.On("FindBatch", mock.Anything, bson.M{}, sort, int64(adminAccountExportBatchSize)).Return([]Account{*account}, nil)
+
+		export, err := service.ExportAccounts(context.Background(), "admin", &AdminAccountListQuery{}, "")
+		require.NoError(t, err)
+
+		var out strings.Builder
+		require.NoError(t, export(&out))
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		require.Len(t, lines, 2)
+		assert.Equal(t, strings.Join(accountExportColumns, ","), lines[0])
+		assert.Contains(t, lines[1], `"'=HYPERLINK(""http://evil"")"`)
+		assert.Contains(t, lines[1], "support;editor")
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		service, mockAccountRepo, _, _, _ := setupAdminService(t)
+		mockAccountRepo.On("FindBatch", mock.Anything, bson.M{}, sort, int64(adminAccountExportBatchSize)).Return([]Account{*account, *account}, nil)
+
+		export, err := service.ExportAccounts(context.Background(), "admin", &AdminAccountListQuery{}, AccountExportFormatNDJSON)
+		require.NoError(t, err)
+
+		var out strings.Builder
+		require.NoError(t, export(&out))
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		require.Len(t, lines, 2)
+		var decoded AccountResponse
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &decoded))
+		assert.Equal(t, account.Email, decoded.Email)
+	})
+
+	service, _, _, _, _ := setupAdminService(t)
+	_, err := service.ExportAccounts(context.Background(), "admin", &AdminAccountListQuery{}, "xlsx")
+	assert.ErrorContains(t, err, "invalid export format")
+}
@@ -0,0 +1,136 @@
+package account
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/binding"
+)
+
+type APIKeyHandler struct {
+	service APIKeyService
+}
+
+func NewAPIKeyHandler(service APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		service: service,
+	}
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Create a named, scoped and expiring personal access token for the authenticated account. The key is only returned in this response.
+// @Tags api-keys
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateAPIKeyRequest true "API key details"
+// @Success 201 {object} map[string]interface{} "API key created successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /accounts/me/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *fiber.Ctx) error {
+	accountID, ok := c.Locals("account_id").(string)
+	if !ok || accountID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   "Unauthorized",
+			"message": "Account ID not found in context",
+		})
+	}
+
+	var req CreateAPIKeyRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	key, err := h.service.CreateAPIKey(c.Context(), accountID, &req)
+	if err != nil {
+		statusCode := fiber.StatusInternalServerError
+		if strings.Contains(err.Error(), "invalid API key") {
+			statusCode = fiber.StatusBadRequest
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"error":   "Failed to create API key",
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "API key created successfully",
+		"data":    key,
+	})
+}
+
+// ListAPIKeys godoc
+// @Summary List API keys
+// @Description List the API keys of the authenticated account without their secret values
+// @Tags api-keys
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "API keys retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /accounts/me/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *fiber.Ctx) error {
+	accountID, ok := c.Locals("account_id").(string)
+	if !ok || accountID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   "Unauthorized",
+			"message": "Account ID not found in context",
+		})
+	}
+
+	keys, err := h.service.ListAPIKeys(c.Context(), accountID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to list API keys",
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "API keys retrieved successfully",
+		"data":    keys,
+	})
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Permanently revoke an API key of the authenticated account
+// @Tags api-keys
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param keyId path string true "API key ID"
+// @Success 200 {object} map[string]interface{} "API key revoked successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "API key not found"
+// @Router /accounts/me/api-keys/{keyId} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *fiber.Ctx) error {
+	accountID, ok := c.Locals("account_id").(string)
+	if !ok || accountID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   "Unauthorized",
+			"message": "Account ID not found in context",
+		})
+	}
+
+	if err := h.service.RevokeAPIKey(c.Context(), accountID, c.Params("keyId")); err != nil {
+		statusCode := fiber.StatusNotFound
+		if strings.Contains(err.Error(), "invalid") {
+			statusCode = fiber.StatusBadRequest
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"error":   "Failed to revoke API key",
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "API key revoked successfully",
+	})
+}
@@ -0,0 +1,70 @@
+package account
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	APIKeyPrefix          = "pat_"
+	APIKeyHeader          = "X-API-Key"
+	APIKeyDefaultLifetime = 90 * 24 * time.Hour
+	APIKeyMaxLifetimeDays = 365
+)
+
+// APIKeyLastUsedInterval throttles last-used writes for keys that
+// authenticate many requests in a row.
+const APIKeyLastUsedInterval = time.Minute
+
+type APIKey struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	AccountID  string             `json:"account_id" bson:"account_id"`
+	Name       string             `json:"name" bson:"name"`
+	KeyPrefix  string             `json:"key_prefix" bson:"key_prefix"`
+	KeyHash    string             `json:"-" bson:"key_hash"`
+	Scopes     []string           `json:"scopes" bson:"scopes"`
+	ExpiresAt  time.Time          `json:"expires_at" bson:"expires_at"`
+	LastUsedAt *time.Time         `json:"last_used_at,omitempty" bson:"last_used_at,omitempty"`
+	LastUsedIP string             `json:"last_used_ip,omitempty" bson:"last_used_ip,omitempty"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" validate:"required,min=1,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+}
+
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	KeyPrefix  string     `json:"key_prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP string     `json:"last_used_ip,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type APIKeyCreatedResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+func (k *APIKey) IsExpired() bool {
+	return time.Now().After(k.ExpiresAt)
+}
+
+func (k *APIKey) ToResponse() *APIKeyResponse {
+	return &APIKeyResponse{
+		ID:         k.ID.Hex(),
+		Name:       k.Name,
+		KeyPrefix:  k.KeyPrefix,
+		Scopes:     k.Scopes,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		LastUsedIP: k.LastUsedIP,
+		CreatedAt:  k.CreatedAt,
+	}
+}
@@ -0,0 +1,103 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+const APIKeyCollectionName = "api_keys"
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *APIKey) (*APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*APIKey, error)
+	GetAPIKeysByAccountID(ctx context.Context, accountID string) ([]*APIKey, error)
+	UpdateAPIKeyLastUsed(ctx context.Context, id primitive.ObjectID, ipAddress string) error
+	DeleteAPIKey(ctx context.Context, accountID string, id primitive.ObjectID) error
+}
+
+type apiKeyRepository struct {
+	repo mongo.Repository[APIKey]
+}
+
+var _ APIKeyRepository = (*apiKeyRepository)(nil)
+
+func NewAPIKeyRepository(mongoService *mongo.MongoService) APIKeyRepository {
+	return &apiKeyRepository{
+		repo: mongo.NewRepository[APIKey](mongoService, APIKeyCollectionName),
+	}
+}
+
+func (r *apiKeyRepository) CreateAPIKey(ctx context.Context, key *APIKey) (*APIKey, error) {
+	key.ID = primitive.NewObjectID()
+
+	result, err := r.repo.Create(ctx, *key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *apiKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*APIKey, error) {
+	filter := bson.M{"key_hash": keyHash}
+
+	result, err := r.repo.FindOne(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *apiKeyRepository) GetAPIKeysByAccountID(ctx context.Context, accountID string) ([]*APIKey, error) {
+	filter := bson.M{"account_id": accountID}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	results, err := r.repo.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+
+	keys := make([]*APIKey, len(results))
+	for i := range results {
+		keys[i] = &results[i]
+	}
+
+	return keys, nil
+}
+
+func (r *apiKeyRepository) UpdateAPIKeyLastUsed(ctx context.Context, id primitive.ObjectID, ipAddress string) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{
+		"$set": bson.M{
+			"last_used_at": time.Now(),
+			"last_used_ip": ipAddress,
+		},
+	}
+
+	if _, err := r.repo.Update(ctx, filter, update); err != nil {
+		return fmt.Errorf("failed to update API key usage: %w", err)
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepository) DeleteAPIKey(ctx context.Context, accountID string, id primitive.ObjectID) error {
+	filter := bson.M{
+		"_id":        id,
+		"account_id": accountID,
+	}
+
+	if err := r.repo.Delete(ctx, filter); err != nil {
+		return fmt.Errorf("failed to delete API key: %w", err)
+	}
+
+	return nil
+}
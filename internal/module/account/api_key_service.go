@@ -0,0 +1,157 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, accountID string, req *CreateAPIKeyRequest) (*APIKeyCreatedResponse, error)
+	ListAPIKeys(ctx context.Context, accountID string) ([]*APIKeyResponse, error)
+	RevokeAPIKey(ctx context.Context, accountID, keyID string) error
+	Authenticate(ctx context.Context, key, ipAddress string) (*APIKey, *Account, error)
+}
+
+type apiKeyService struct {
+	repository        APIKeyRepository
+	accountRepository AccountRepository
+}
+
+func NewAPIKeyService(mongoService *mongo.MongoService) APIKeyService {
+	return newAPIKeyService(
+		NewAPIKeyRepository(mongoService),
+		NewAccountRepository(mongoService),
+	)
+}
+
+func newAPIKeyService(repository APIKeyRepository, accountRepository AccountRepository) *apiKeyService {
+	return &apiKeyService{
+		repository:        repository,
+		accountRepository: accountRepository,
+	}
+}
+
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, accountID string, req *CreateAPIKeyRequest) (*APIKeyCreatedResponse, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("invalid API key: name is required")
+	}
+
+	if len(req.Scopes) == 0 {
+		return nil, fmt.Errorf("invalid API key: at least one scope is required")
+	}
+
+	for _, scope := range req.Scopes {
+		if !slices.Contains(SupportedOAuthScopes, scope) {
+			return nil, fmt.Errorf("invalid API key: unsupported scope %q", scope)
+		}
+	}
+
+	lifetime := APIKeyDefaultLifetime
+	if req.ExpiresInDays != 0 {
+		if req.ExpiresInDays < 0 || req.ExpiresInDays > APIKeyMaxLifetimeDays {
+			return nil, fmt.Errorf("invalid API key: expires_in_days must be between 1 and %d", APIKeyMaxLifetimeDays)
+		}
+		lifetime = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	}
+
+	secret, err := generateRandomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key := APIKeyPrefix + secret
+
+	created, err := s.repository.CreateAPIKey(ctx, &APIKey{
+		AccountID: accountID,
+		Name:      req.Name,
+		KeyPrefix: key[:len(APIKeyPrefix)+8],
+		KeyHash:   hashSecret(key),
+		Scopes:    req.Scopes,
+		ExpiresAt: time.Now().Add(lifetime),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return &APIKeyCreatedResponse{
+		APIKeyResponse: *created.ToResponse(),
+		Key:            key,
+	}, nil
+}
+
+func (s *apiKeyService) ListAPIKeys(ctx context.Context, accountID string) ([]*APIKeyResponse, error) {
+	keys, err := s.repository.GetAPIKeysByAccountID(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+
+	responses := make([]*APIKeyResponse, len(keys))
+	for i, key := range keys {
+		responses[i] = key.ToResponse()
+	}
+
+	return responses, nil
+}
+
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, accountID, keyID string) error {
+	objectID, err := primitive.ObjectIDFromHex(keyID)
+	if err != nil {
+		return fmt.Errorf("invalid API key ID format: %w", err)
+	}
+
+	if err := s.repository.DeleteAPIKey(ctx, accountID, objectID); err != nil {
+		return fmt.Errorf("API key not found: %w", err)
+	}
+
+	return nil
+}
+
+// Authenticate resolves a raw key to its record and the owning account. The
+// last-used time is only written once per APIKeyLastUsedInterval.
+func (s *apiKeyService) Authenticate(ctx context.Context, key, ipAddress string) (*APIKey, *Account, error) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return nil, nil, fmt.Errorf("invalid API key")
+	}
+
+	apiKey, err := s.repository.GetAPIKeyByHash(ctx, hashSecret(key))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	if apiKey == nil {
+		return nil, nil, fmt.Errorf("invalid API key")
+	}
+
+	if apiKey.IsExpired() {
+		return nil, nil, fmt.Errorf("API key has expired")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(apiKey.AccountID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid API key")
+	}
+
+	account, err := s.accountRepository.GetByID(ctx, objectID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get account: %w", err)
+	}
+
+	if account == nil || !account.IsActive {
+		return nil, nil, fmt.Errorf("account is inactive")
+	}
+
+	if apiKey.LastUsedAt == nil || time.Since(*apiKey.LastUsedAt) > APIKeyLastUsedInterval || apiKey.LastUsedIP != ipAddress {
+		if err := s.repository.UpdateAPIKeyLastUsed(ctx, apiKey.ID, ipAddress); err != nil {
+			fmt.Printf("Failed to update API key usage: %v\n", err)
+		}
+	}
+
+	return apiKey, account, nil
+}
@@ -0,0 +1,198 @@
+package account
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func setupAPIKeyService() (*apiKeyService, *MockAPIKeyRepository, *MockAccountRepository) {
+	mockAPIKeyRepo := &MockAPIKeyRepository{}
+	mockAccountRepo := &MockAccountRepository{}
+
+	return newAPIKeyService(mockAPIKeyRepo, mockAccountRepo), mockAPIKeyRepo, mockAccountRepo
+}
+
+func TestAPIKeyService_CreateAPIKey(t *testing.T) {
+	tests := []struct {
+		name             string
+		req              CreateAPIKeyRequest
+		expectedLifetime time.Duration
+		errorContains    string
+	}{
+		{
+			name: "default lifetime",
+			req: CreateAPIKeyRequest{
+				Name:   "CI ingest",
+				Scopes: []string{OAuthScopeDocumentsWrite},
+			},
+			expectedLifetime: APIKeyDefaultLifetime,
+		},
+		{
+			name: "custom lifetime",
+			req: CreateAPIKeyRequest{
+				Name:          "Nightly export",
+				Scopes:        []string{OAuthScopeKnowledgeRead},
+				ExpiresInDays: 7,
+			},
+			expectedLifetime: 7 * 24 * time.Hour,
+		},
+		{
+			name: "lifetime above the maximum",
+			req: CreateAPIKeyRequest{
+				Name:          "Forever",
+				Scopes:        []string{OAuthScopeKnowledgeRead},
+				ExpiresInDays: APIKeyMaxLifetimeDays + 1,
+			},
+			errorContains: "expires_in_days",
+		},
+		{
+			name: "unsupported scope",
+			req: CreateAPIKeyRequest{
+				Name:   "Admin",
+				Scopes: []string{"admin"},
+			},
+			errorContains: "unsupported scope",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockAPIKeyRepo, _ := setupAPIKeyService()
+
+			var stored APIKey
+			mockAPIKeyRepo.On("CreateAPIKey", mock.Anything, mock.AnythingOfType("*account.APIKey")).
+				Run(func(args mock.Arguments) {
+					stored = *args.Get(1).(*APIKey)
+				}).
+				Return(&stored, nil)
+
+			response, err := service.CreateAPIKey(context.Background(), "507f1f77bcf86cd799439011", &tt.req)
+
+			if tt.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				mockAPIKeyRepo.AssertNotCalled(t, "CreateAPIKey", mock.Anything, mock.Anything)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(response.Key, APIKeyPrefix))
+			assert.True(t, strings.HasPrefix(response.Key, stored.KeyPrefix))
+			assert.Equal(t, hashSecret(response.Key), stored.KeyHash)
+			assert.NotContains(t, stored.KeyHash, response.Key)
+			assert.WithinDuration(t, time.Now().Add(tt.expectedLifetime), stored.ExpiresAt, time.Minute)
+		})
+	}
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	account := CreateTestAccount()
+	recent := time.Now().Add(-10 * time.Second)
+
+	tests := []struct {
+		name          string
+		key           string
+		apiKey        *APIKey
+		account       *Account
+		expectUpdate  bool
+		errorContains string
+	}{
+		{
+			name: "valid key records usage",
+			key:  "pat_valid",
+			apiKey: &APIKey{
+				ID:        primitive.NewObjectID(),
+				AccountID: account.ID.Hex(),
+				Scopes:    []string{OAuthScopeDocumentsWrite},
+				ExpiresAt: time.Now().Add(time.Hour),
+			},
+			account:      account,
+			expectUpdate: true,
+		},
+		{
+			name: "recently used key skips the usage write",
+			key:  "pat_valid",
+			apiKey: &APIKey{
+				ID:         primitive.NewObjectID(),
+				AccountID:  account.ID.Hex(),
+				ExpiresAt:  time.Now().Add(time.Hour),
+				LastUsedAt: &recent,
+				LastUsedIP: "10.0.0.1",
+			},
+			account: account,
+		},
+		{
+			name:          "missing prefix",
+			key:           "not-a-key",
+			errorContains: "invalid API key",
+		},
+		{
+			name:          "unknown key",
+			key:           "pat_unknown",
+			errorContains: "invalid API key",
+		},
+		{
+			name: "expired key",
+			key:  "pat_expired",
+			apiKey: &APIKey{
+				AccountID: account.ID.Hex(),
+				ExpiresAt: time.Now().Add(-time.Minute),
+			},
+			errorContains: "expired",
+		},
+		{
+			name: "inactive account",
+			key:  "pat_inactive",
+			apiKey: &APIKey{
+				AccountID: account.ID.Hex(),
+				ExpiresAt: time.Now().Add(time.Hour),
+			},
+			account: CreateTestAccount(func(a *Account) {
+				a.ID = account.ID
+				a.IsActive = false
+			}),
+			errorContains: "inactive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockAPIKeyRepo, mockAccountRepo := setupAPIKeyService()
+
+			if tt.apiKey != nil {
+				mockAPIKeyRepo.On("GetAPIKeyByHash", mock.Anything, hashSecret(tt.key)).Return(tt.apiKey, nil)
+			} else {
+				mockAPIKeyRepo.On("GetAPIKeyByHash", mock.Anything, hashSecret(tt.key)).Return(nil, nil)
+			}
+			if tt.account != nil {
+				mockAccountRepo.On("GetByID", mock.Anything, account.ID).Return(tt.account, nil)
+			}
+			if tt.expectUpdate {
+				mockAPIKeyRepo.On("UpdateAPIKeyLastUsed", mock.Anything, tt.apiKey.ID, "10.0.0.1").Return(nil).Once()
+			}
+
+			apiKey, resolved, err := service.Authenticate(context.Background(), tt.key, "10.0.0.1")
+
+			if tt.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.apiKey, apiKey)
+			assert.Equal(t, account.ID, resolved.ID)
+			if !tt.expectUpdate {
+				mockAPIKeyRepo.AssertNotCalled(t, "UpdateAPIKeyLastUsed", mock.Anything, mock.Anything, mock.Anything)
+			}
+			mockAPIKeyRepo.AssertExpectations(t)
+		})
+	}
+}
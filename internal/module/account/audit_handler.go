@@ -0,0 +1,133 @@
+package account
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/binding"
+)
+
+type AuditHandler struct {
+	service AuditService
+}
+
+func NewAuditHandler(service AuditService) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+	}
+}
+
+// ListMyActivity godoc
+// @Summary List my account activity
+// @Description List security events performed by or against the authenticated account, newest first
+// @Tags audit
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param type query string false "Filter by event type, such as auth.login"
+// @Param outcome query string false "Filter by outcome (success, failure)"
+// @Param from query string false "Only events at or after this RFC 3339 time"
+// @Param to query string false "Only events at or before this RFC 3339 time"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(50)
+// @Success 200 {object} map[string]interface{} "Activity retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /accounts/me/activity [get]
+func (h *AuditHandler) ListMyActivity(c *fiber.Ctx) error {
+	accountID, ok := c.Locals("account_id").(string)
+	if !ok || accountID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   "Unauthorized",
+			"message": "Account ID not found in context",
+		})
+	}
+
+	var query AuditQuery
+	if err := binding.Query(c, &query); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	events, err := h.service.ListAccountActivity(c.Context(), accountID, &query)
+	if err != nil {
+		return auditError(c, "Failed to list activity", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Activity retrieved successfully",
+		"data":    events,
+	})
+}
+
+// ListEvents godoc
+// @Summary List audit events
+// @Description Search the security audit log, newest first. Requires the audit:read permission.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param actor_id query string false "Filter by the account that performed the action"
+// @Param target_id query string false "Filter by the account the action was performed on"
+// @Param type query string false "Filter by event type, such as auth.login"
+// @Param outcome query string false "Filter by outcome (success, failure)"
+// @Param from query string false "Only events at or after this RFC 3339 time"
+// @Param to query string false "Only events at or before this RFC 3339 time"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(50)
+// @Success 200 {object} map[string]interface{} "Audit events retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Router /admin/audit-events [get]
+func (h *AuditHandler) ListEvents(c *fiber.Ctx) error {
+	var query AuditQuery
+	if err := binding.Query(c, &query); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	events, err := h.service.ListEvents(c.Context(), &query)
+	if err != nil {
+		return auditError(c, "Failed to list audit events", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Audit events retrieved successfully",
+		"data":    events,
+	})
+}
+
+// VerifyChain godoc
+// @Summary Verify the audit log
+// @Description Check the hash chain of the stored audit events and report the first event that was altered or removed. Requires the audit:read permission.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Audit log verified"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Router /admin/audit-events/verify [get]
+func (h *AuditHandler) VerifyChain(c *fiber.Ctx) error {
+	result, err := h.service.VerifyChain(c.Context())
+	if err != nil {
+		return auditError(c, "Failed to verify audit log", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Audit log verified",
+		"data":    result,
+	})
+}
+
+func auditError(c *fiber.Ctx, title string, err error) error {
+	statusCode := fiber.StatusInternalServerError
+	if strings.Contains(err.Error(), "invalid") {
+		statusCode = fiber.StatusBadRequest
+	}
+
+	return c.Status(statusCode).JSON(fiber.Map{
+		"error":   title,
+		"message": err.Error(),
+	})
+}
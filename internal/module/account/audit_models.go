@@ -0,0 +1,167 @@
+package account
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AuditEventType string
+
+const (
+	AuditEventLogin                 AuditEventType = "auth.login"
+	AuditEventLogout                AuditEventType = "auth.logout"
+	AuditEventNewDevice             AuditEventType = "auth.new_device"
+	AuditEventLoginReport           AuditEventType = "auth.login_report"
+	AuditEventPasswordChange        AuditEventType = "account.password_change"
+	AuditEventPasswordReset         AuditEventType = "account.password_reset"
+	AuditEventPasswordResetRequired AuditEventType = "account.password_reset_required"
+	AuditEventEmailVerification     AuditEventType = "account.email_verification"
+	AuditEventEmailChange           AuditEventType = "account.email_change"
+	AuditEventAccountLock           AuditEventType = "account.lock"
+	AuditEventAccountUnlock         AuditEventType = "account.unlock"
+	AuditEventAccountDelete         AuditEventType = "account.delete"
+	AuditEventAccountRestore        AuditEventType = "account.restore"
+	AuditEventAccountPurge          AuditEventType = "account.purge"
+	AuditEventAccountSuspend        AuditEventType = "account.suspend"
+	AuditEventAccountReactivate     AuditEventType = "account.reactivate"
+	AuditEventSessionRevoke         AuditEventType = "session.revoke"
+	AuditEventImpersonation         AuditEventType = "admin.impersonate"
+	AuditEventAccountExport         AuditEventType = "admin.export"
+)
+
+type AuditOutcome string
+
+const (
+	AuditOutcomeSuccess AuditOutcome = "success"
+	AuditOutcomeFailure AuditOutcome = "failure"
+)
+
+const (
+	// DefaultAuditRetention applies unless the module is configured with
+	// WithAuditRetention. A retention of zero keeps events forever.
+	DefaultAuditRetention  = 365 * 24 * time.Hour
+	AuditRetentionInterval = 24 * time.Hour
+
+	AuditListDefaultLimit = 50
+	AuditListMaxLimit     = 200
+
+	auditVerifyBatchSize = 500
+)
+
+// AuditEvent is an append-only record of a security-relevant action. Each
+// event stores the hash of the one before it, so that editing or removing an
+// event breaks the chain from that point on.
+type AuditEvent struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Sequence       int64              `json:"sequence" bson:"sequence"`
+	Type           AuditEventType     `json:"type" bson:"type"`
+	Outcome        AuditOutcome       `json:"outcome" bson:"outcome"`
+	ActorID        string             `json:"actor_id,omitempty" bson:"actor_id,omitempty"`
+	ImpersonatorID string             `json:"impersonator_id,omitempty" bson:"impersonator_id,omitempty"`
+	TargetID       string             `json:"target_id,omitempty" bson:"target_id,omitempty"`
+	IPAddress      string             `json:"ip_address,omitempty" bson:"ip_address,omitempty"`
+	UserAgent      string             `json:"user_agent,omitempty" bson:"user_agent,omitempty"`
+	TraceID        string             `json:"trace_id,omitempty" bson:"trace_id,omitempty"`
+	Reason         string             `json:"reason,omitempty" bson:"reason,omitempty"`
+	Metadata       map[string]string  `json:"metadata,omitempty" bson:"metadata,omitempty"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+	PrevHash       string             `json:"prev_hash" bson:"prev_hash"`
+	Hash           string             `json:"hash" bson:"hash"`
+}
+
+// AuditChainHead tracks the last appended event. Appending compares and swaps
+// it, so that concurrent writers, even on different instances, cannot fork
+// the chain.
+type AuditChainHead struct {
+	ID       string `bson:"_id"`
+	Sequence int64  `bson:"sequence"`
+	Hash     string `bson:"hash"`
+}
+
+// AuditQuery filters audit events. From and To are RFC 3339 timestamps.
+type AuditQuery struct {
+	ActorID  string         `query:"actor_id"`
+	TargetID string         `query:"target_id"`
+	Type     AuditEventType `query:"type"`
+	Outcome  AuditOutcome   `query:"outcome"`
+	From     string         `query:"from"`
+	To       string         `query:"to"`
+	Page     int64          `query:"page"`
+	Limit    int64          `query:"limit"`
+}
+
+type AuditVerification struct {
+	Valid         bool   `json:"valid"`
+	Checked       int64  `json:"checked"`
+	FirstSequence int64  `json:"first_sequence,omitempty"`
+	LastSequence  int64  `json:"last_sequence,omitempty"`
+	BrokenAt      int64  `json:"broken_at,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+}
+
+// auditHashPayload lists the fields covered by the hash. Metadata is a map,
+// which encoding/json writes with sorted keys, so the encoding is stable.
+type auditHashPayload struct {
+	Sequence       int64             `json:"sequence"`
+	Type           AuditEventType    `json:"type"`
+	Outcome        AuditOutcome      `json:"outcome"`
+	ActorID        string            `json:"actor_id"`
+	ImpersonatorID string            `json:"impersonator_id"`
+	TargetID       string            `json:"target_id"`
+	IPAddress      string            `json:"ip_address"`
+	UserAgent      string            `json:"user_agent"`
+	TraceID        string            `json:"trace_id"`
+	Reason         string            `json:"reason"`
+	Metadata       map[string]string `json:"metadata"`
+	CreatedAt      string            `json:"created_at"`
+}
+
+// link places the event after head in the chain and seals it.
+func (e *AuditEvent) link(head *AuditChainHead) {
+	e.Sequence = head.Sequence + 1
+	e.PrevHash = head.Hash
+	e.Hash = e.computeHash()
+}
+
+func (e *AuditEvent) computeHash() string {
+	// An empty map is not stored, so it must hash the same as a missing one.
+	metadata := e.Metadata
+	if len(metadata) == 0 {
+		metadata = nil
+	}
+
+	payload, _ := json.Marshal(auditHashPayload{
+		Sequence:       e.Sequence,
+		Type:           e.Type,
+		Outcome:        e.Outcome,
+		ActorID:        e.ActorID,
+		ImpersonatorID: e.ImpersonatorID,
+		TargetID:       e.TargetID,
+		IPAddress:      e.IPAddress,
+		UserAgent:      e.UserAgent,
+		TraceID:        e.TraceID,
+		Reason:         e.Reason,
+		Metadata:       metadata,
+		CreatedAt:      e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+
+	hash := sha256.New()
+	hash.Write([]byte(e.PrevHash))
+	hash.Write(payload)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// auditRequestInfoKey stores the request details of the current call. It is
+// set as a Fiber local, and handlers pass c.Context() to services, so the
+// services can read it back with ctx.Value.
+type auditRequestInfoKey struct{}
+
+type auditRequestInfo struct {
+	IPAddress string
+	UserAgent string
+	TraceID   string
+}
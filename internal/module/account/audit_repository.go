@@ -0,0 +1,170 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+const (
+	AuditEventCollectionName = "audit_events"
+	AuditChainCollectionName = "audit_chain"
+
+	auditChainHeadID     = "audit_events"
+	auditAppendAttempts  = 10
+	auditAppendRetryWait = 10 * time.Millisecond
+)
+
+// AuditRepository only appends and reads events. The sole removal path is
+// retention, which drops the oldest events and leaves the rest of the chain
+// verifiable from the first remaining one.
+type AuditRepository interface {
+	AppendAuditEvent(ctx context.Context, event *AuditEvent) (*AuditEvent, error)
+	ListAuditEvents(ctx context.Context, filter bson.M, pagination mongo.PaginationOptions) (*mongo.PaginatedResult[AuditEvent], error)
+	ListAuditEventsAfter(ctx context.Context, sequence int64, limit int64) ([]AuditEvent, error)
+	GetAuditChainHead(ctx context.Context) (*AuditChainHead, error)
+	DeleteAuditEventsBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+type auditRepository struct {
+	eventRepo mongo.Repository[AuditEvent]
+	chainRepo mongo.Repository[AuditChainHead]
+}
+
+var _ AuditRepository = (*auditRepository)(nil)
+
+func NewAuditRepository(mongoService *mongo.MongoService) AuditRepository {
+	return &auditRepository{
+		eventRepo: mongo.NewRepository[AuditEvent](mongoService, AuditEventCollectionName),
+		chainRepo: mongo.NewRepository[AuditChainHead](mongoService, AuditChainCollectionName),
+	}
+}
+
+// AppendAuditEvent links the event to the current head and moves the head
+// forward with a compare-and-swap on its sequence, retrying when another
+// writer got there first. The event is inserted once its place in the chain
+// is reserved; if that insert fails, verification reports the gap.
+func (r *auditRepository) AppendAuditEvent(ctx context.Context, event *AuditEvent) (*AuditEvent, error) {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	event.CreatedAt = event.CreatedAt.UTC().Truncate(time.Millisecond)
+
+	for attempt := 0; attempt < auditAppendAttempts; attempt++ {
+		head, err := r.getOrCreateHead(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		event.ID = primitive.NewObjectID()
+		event.link(head)
+
+		moved, err := r.chainRepo.Update(ctx,
+			bson.M{"_id": auditChainHeadID, "sequence": head.Sequence},
+			bson.M{"$set": bson.M{"sequence": event.Sequence, "hash": event.Hash}},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to advance audit chain: %w", err)
+		}
+		if moved == nil {
+			time.Sleep(auditAppendRetryWait)
+			continue
+		}
+
+		result, err := r.eventRepo.Create(ctx, *event)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create audit event: %w", err)
+		}
+
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("failed to append audit event: audit chain is busy")
+}
+
+func (r *auditRepository) getOrCreateHead(ctx context.Context) (*AuditChainHead, error) {
+	head, err := r.GetAuditChainHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if head != nil {
+		return head, nil
+	}
+
+	// A concurrent writer may create the head first, in which case the insert
+	// fails on the duplicate _id and the existing head is used instead.
+	if _, err := r.chainRepo.Create(ctx, AuditChainHead{ID: auditChainHeadID}); err == nil {
+		return &AuditChainHead{ID: auditChainHeadID}, nil
+	}
+
+	head, err = r.GetAuditChainHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if head == nil {
+		return nil, fmt.Errorf("failed to create audit chain head")
+	}
+
+	return head, nil
+}
+
+func (r *auditRepository) ListAuditEvents(ctx context.Context, filter bson.M, pagination mongo.PaginationOptions) (*mongo.PaginatedResult[AuditEvent], error) {
+	opts := options.Find().SetSort(bson.D{{Key: "sequence", Value: -1}})
+
+	result, err := r.eventRepo.FindWithPagination(ctx, filter, pagination, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *auditRepository) ListAuditEventsAfter(ctx context.Context, sequence int64, limit int64) ([]AuditEvent, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "sequence", Value: 1}}).
+		SetLimit(limit)
+
+	result, err := r.eventRepo.Find(ctx, bson.M{"sequence": bson.M{"$gt": sequence}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *auditRepository) GetAuditChainHead(ctx context.Context) (*AuditChainHead, error) {
+	head, err := r.chainRepo.FindOne(ctx, bson.M{"_id": auditChainHeadID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit chain head: %w", err)
+	}
+
+	return head, nil
+}
+
+// DeleteAuditEventsBefore removes a prefix of the chain: everything up to the
+// newest event older than cutoff, so that no hole is left behind when two
+// events were appended out of timestamp order.
+func (r *auditRepository) DeleteAuditEventsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "sequence", Value: -1}})
+
+	last, err := r.eventRepo.FindOne(ctx, bson.M{"created_at": bson.M{"$lt": cutoff}}, opts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find expired audit events: %w", err)
+	}
+	if last == nil {
+		return 0, nil
+	}
+
+	deleted, err := r.eventRepo.DeleteMany(ctx, bson.M{"sequence": bson.M{"$lte": last.Sequence}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired audit events: %w", err)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,312 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+// AuditRecorder appends security events. Recording never fails the action
+// being recorded; errors are logged instead.
+type AuditRecorder interface {
+	Record(ctx context.Context, event *AuditEvent)
+}
+
+// AuditService is registered as "audit". Besides recording events it answers
+// queries for admins and account owners, verifies the hash chain and drops
+// events older than the configured retention.
+type AuditService interface {
+	AuditRecorder
+	ListEvents(ctx context.Context, query *AuditQuery) (*mongo.PaginatedResult[AuditEvent], error)
+	ListAccountActivity(ctx context.Context, accountID string, query *AuditQuery) (*mongo.PaginatedResult[AuditEvent], error)
+	VerifyChain(ctx context.Context) (*AuditVerification, error)
+	PurgeExpiredEvents(ctx context.Context) (int64, error)
+	Start()
+	Shutdown() error
+}
+
+type auditService struct {
+	repository AuditRepository
+	retention  time.Duration
+
+	mu       sync.Mutex
+	interval time.Duration
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+var _ AuditService = (*auditService)(nil)
+
+func NewAuditService(mongoService *mongo.MongoService, retention time.Duration) AuditService {
+	return newAuditService(NewAuditRepository(mongoService), retention)
+}
+
+func newAuditService(repository AuditRepository, retention time.Duration) *auditService {
+	return &auditService{
+		repository: repository,
+		retention:  retention,
+		interval:   AuditRetentionInterval,
+	}
+}
+
+// recordAudit lets services hold an optional recorder.
+func recordAudit(ctx context.Context, recorder AuditRecorder, event *AuditEvent) {
+	if recorder == nil {
+		return
+	}
+
+	recorder.Record(ctx, event)
+}
+
+// Record fills in whatever the caller left out from the request: the client
+// address, user agent and trace ID captured by AuditContext, and the
+// authenticated account as the actor. Without a target, an event is about the
+// actor's own account.
+func (s *auditService) Record(ctx context.Context, event *AuditEvent) {
+	if info, ok := ctx.Value(auditRequestInfoKey{}).(*auditRequestInfo); ok {
+		if event.IPAddress == "" {
+			event.IPAddress = info.IPAddress
+		}
+		if event.UserAgent == "" {
+			event.UserAgent = info.UserAgent
+		}
+		if event.TraceID == "" {
+			event.TraceID = info.TraceID
+		}
+	}
+
+	if event.ActorID == "" {
+		event.ActorID, _ = ctx.Value("account_id").(string)
+	}
+	if event.ImpersonatorID == "" {
+		event.ImpersonatorID, _ = ctx.Value("impersonator_id").(string)
+	}
+	if event.TargetID == "" {
+		event.TargetID = event.ActorID
+	}
+	if event.Outcome == "" {
+		event.Outcome = AuditOutcomeSuccess
+	}
+
+	if _, err := s.repository.AppendAuditEvent(ctx, event); err != nil {
+		fmt.Printf("Failed to record audit event %s: %v\n", event.Type, err)
+	}
+}
+
+func (s *auditService) ListEvents(ctx context.Context, query *AuditQuery) (*mongo.PaginatedResult[AuditEvent], error) {
+	filter, err := auditFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.ActorID != "" {
+		filter["actor_id"] = query.ActorID
+	}
+	if query.TargetID != "" {
+		filter["target_id"] = query.TargetID
+	}
+
+	return s.list(ctx, filter, query)
+}
+
+// ListAccountActivity returns the events an account performed or was the
+// subject of, such as failed logins against it.
+func (s *auditService) ListAccountActivity(ctx context.Context, accountID string, query *AuditQuery) (*mongo.PaginatedResult[AuditEvent], error) {
+	filter, err := auditFilter(query)
+	if err != nil {
+		return nil, err
+	}
+	filter["$or"] = bson.A{
+		bson.M{"actor_id": accountID},
+		bson.M{"target_id": accountID},
+	}
+
+	return s.list(ctx, filter, query)
+}
+
+func (s *auditService) list(ctx context.Context, filter bson.M, query *AuditQuery) (*mongo.PaginatedResult[AuditEvent], error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = AuditListDefaultLimit
+	}
+	if limit > AuditListMaxLimit {
+		limit = AuditListMaxLimit
+	}
+
+	return s.repository.ListAuditEvents(ctx, filter, mongo.PaginationOptions{
+		Page:  query.Page,
+		Limit: limit,
+	})
+}
+
+func auditFilter(query *AuditQuery) (bson.M, error) {
+	filter := bson.M{}
+
+	if query.Type != "" {
+		filter["type"] = query.Type
+	}
+	if query.Outcome != "" {
+		filter["outcome"] = query.Outcome
+	}
+
+	createdAt := bson.M{}
+	if query.From != "" {
+		from, err := time.Parse(time.RFC3339, query.From)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from: %w", err)
+		}
+		createdAt["$gte"] = from
+	}
+	if query.To != "" {
+		to, err := time.Parse(time.RFC3339, query.To)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to: %w", err)
+		}
+		createdAt["$lte"] = to
+	}
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+
+	return filter, nil
+}
+
+// VerifyChain walks every stored event in sequence order and checks that the
+// sequence has no gaps, that each event links to the hash of the one before
+// it, that each hash matches the event's contents and that the last event is
+// the chain head. The first stored event is trusted as the start of the chain
+// unless it is the very first event ever written, since retention removes
+// older ones.
+func (s *auditService) VerifyChain(ctx context.Context) (*AuditVerification, error) {
+	result := &AuditVerification{Valid: true}
+
+	var previous *AuditEvent
+	var after int64
+	for {
+		events, err := s.repository.ListAuditEventsAfter(ctx, after, auditVerifyBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range events {
+			event := &events[i]
+
+			if reason := checkAuditLink(previous, event); reason != "" {
+				result.Valid = false
+				result.BrokenAt = event.Sequence
+				result.Reason = reason
+				return result, nil
+			}
+
+			if result.Checked == 0 {
+				result.FirstSequence = event.Sequence
+			}
+			result.Checked++
+			result.LastSequence = event.Sequence
+			previous = event
+		}
+
+		if len(events) < auditVerifyBatchSize {
+			break
+		}
+		after = events[len(events)-1].Sequence
+	}
+
+	head, err := s.repository.GetAuditChainHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case head == nil && previous != nil:
+		result.Valid = false
+		result.Reason = "audit chain head is missing"
+	case head != nil && previous != nil && (head.Sequence != previous.Sequence || head.Hash != previous.Hash):
+		result.Valid = false
+		result.BrokenAt = previous.Sequence + 1
+		result.Reason = "events after the last stored event are missing"
+	}
+
+	return result, nil
+}
+
+func checkAuditLink(previous, event *AuditEvent) string {
+	switch {
+	case previous == nil && event.Sequence == 1 && event.PrevHash != "":
+		return "first event does not start the chain"
+	case previous != nil && event.Sequence != previous.Sequence+1:
+		return fmt.Sprintf("events %d to %d are missing", previous.Sequence+1, event.Sequence-1)
+	case previous != nil && event.PrevHash != previous.Hash:
+		return "event does not link to the previous event"
+	case event.Hash != event.computeHash():
+		return "event contents do not match its hash"
+	}
+
+	return ""
+}
+
+// PurgeExpiredEvents removes events older than the retention period. A
+// retention of zero keeps everything.
+func (s *auditService) PurgeExpiredEvents(ctx context.Context) (int64, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+
+	return s.repository.DeleteAuditEventsBefore(ctx, time.Now().Add(-s.retention))
+}
+
+func (s *auditService) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil || s.retention <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go s.run(ctx, s.done)
+}
+
+func (s *auditService) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.PurgeExpiredEvents(ctx)
+			if err != nil {
+				fmt.Printf("Audit retention failed: %v\n", err)
+			} else if deleted > 0 {
+				fmt.Printf("Removed %d expired audit events\n", deleted)
+			}
+		}
+	}
+}
+
+func (s *auditService) Shutdown() error {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	<-done
+	return nil
+}
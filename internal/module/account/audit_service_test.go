@@ -0,0 +1,287 @@
+package account
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+// memoryAuditRepository keeps the chain in memory so that tests can append
+// events and then tamper with them.
+type memoryAuditRepository struct {
+	events []AuditEvent
+	head   *AuditChainHead
+}
+
+func (r *memoryAuditRepository) AppendAuditEvent(ctx context.Context, event *AuditEvent) (*AuditEvent, error) {
+	if r.head == nil {
+		r.head = &AuditChainHead{ID: auditChainHeadID}
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	}
+
+	event.link(r.head)
+	r.head = &AuditChainHead{ID: auditChainHeadID, Sequence: event.Sequence, Hash: event.Hash}
+	r.events = append(r.events, *event)
+
+	return event, nil
+}
+
+func (r *memoryAuditRepository) ListAuditEvents(ctx context.Context, filter bson.M, pagination mongo.PaginationOptions) (*mongo.PaginatedResult[AuditEvent], error) {
+	return &mongo.PaginatedResult[AuditEvent]{Data: r.events, Total: int64(len(r.events))}, nil
+}
+
+func (r *memoryAuditRepository) ListAuditEventsAfter(ctx context.Context, sequence int64, limit int64) ([]AuditEvent, error) {
+	sort.Slice(r.events, func(i, j int) bool { return r.events[i].Sequence < r.events[j].Sequence })
+
+	var result []AuditEvent
+	for _, event := range r.events {
+		if event.Sequence > sequence && int64(len(result)) < limit {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryAuditRepository) GetAuditChainHead(ctx context.Context) (*AuditChainHead, error) {
+	return r.head, nil
+}
+
+func (r *memoryAuditRepository) DeleteAuditEventsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	var kept []AuditEvent
+	for _, event := range r.events {
+		if !event.CreatedAt.Before(cutoff) {
+			kept = append(kept, event)
+		}
+	}
+
+	deleted := int64(len(r.events) - len(kept))
+	r.events = kept
+	return deleted, nil
+}
+
+func appendTestAuditEvents(t *testing.T, repo *memoryAuditRepository, count int) {
+	t.Helper()
+
+	for i := 0; i < count; i++ {
+		_, err := repo.AppendAuditEvent(context.Background(), &AuditEvent{
+			Type:     AuditEventLogin,
+			Outcome:  AuditOutcomeSuccess,
+			ActorID:  "account-1",
+			TargetID: "account-1",
+			Metadata: map[string]string{"method": "password"},
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestAuditService_Record(t *testing.T) {
+	repo := &memoryAuditRepository{}
+	service := newAuditService(repo, 0)
+
+	ctx := context.WithValue(context.Background(), auditRequestInfoKey{}, &auditRequestInfo{
+		IPAddress: "10.0.0.1",
+		UserAgent: "test-agent",
+		TraceID:   "trace-1",
+	})
+	ctx = context.WithValue(ctx, "account_id", "account-1")
+
+	service.Record(ctx, &AuditEvent{Type: AuditEventLogout})
+	service.Record(ctx, &AuditEvent{
+		Type:      AuditEventImpersonation,
+		ActorID:   "admin-1",
+		TargetID:  "account-2",
+		IPAddress: "10.0.0.2",
+	})
+
+	require.Len(t, repo.events, 2)
+
+	logout := repo.events[0]
+	assert.Equal(t, AuditOutcomeSuccess, logout.Outcome)
+	assert.Equal(t, "account-1", logout.ActorID)
+	assert.Equal(t, "account-1", logout.TargetID)
+	assert.Equal(t, "10.0.0.1", logout.IPAddress)
+	assert.Equal(t, "test-agent", logout.UserAgent)
+	assert.Equal(t, "trace-1", logout.TraceID)
+	assert.Empty(t, logout.PrevHash)
+
+	impersonation := repo.events[1]
+	assert.Equal(t, "admin-1", impersonation.ActorID)
+	assert.Equal(t, "account-2", impersonation.TargetID)
+	assert.Equal(t, "10.0.0.2", impersonation.IPAddress)
+	assert.Equal(t, logout.Hash, impersonation.PrevHash)
+	assert.Equal(t, int64(2), impersonation.Sequence)
+}
+
+func TestAuditService_RecordFailureDoesNotPanic(t *testing.T) {
+	mockRepo := &MockAuditRepository{}
+	mockRepo.On("AppendAuditEvent", mock.Anything, mock.Anything).Return(nil, assert.AnError)
+
+	service := newAuditService(mockRepo, 0)
+	service.Record(context.Background(), &AuditEvent{Type: AuditEventLogin})
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAuditService_VerifyChain(t *testing.T) {
+	tests := []struct {
+		name           string
+		tamper         func(repo *memoryAuditRepository)
+		expectValid    bool
+		expectBrokenAt int64
+		expectReason   string
+	}{
+		{
+			name:        "untouched chain",
+			tamper:      func(repo *memoryAuditRepository) {},
+			expectValid: true,
+		},
+		{
+			name: "edited event",
+			tamper: func(repo *memoryAuditRepository) {
+				repo.events[2].Outcome = AuditOutcomeFailure
+			},
+			expectBrokenAt: 3,
+			expectReason:   "do not match its hash",
+		},
+		{
+			name: "edited event with recomputed hash",
+			tamper: func(repo *memoryAuditRepository) {
+				repo.events[2].IPAddress = "10.9.9.9"
+				repo.events[2].Hash = repo.events[2].computeHash()
+			},
+			expectBrokenAt: 4,
+			expectReason:   "does not link",
+		},
+		{
+			name: "removed event",
+			tamper: func(repo *memoryAuditRepository) {
+				repo.events = append(repo.events[:2], repo.events[3:]...)
+			},
+			expectBrokenAt: 4,
+			expectReason:   "events 3 to 3 are missing",
+		},
+		{
+			name: "removed latest event",
+			tamper: func(repo *memoryAuditRepository) {
+				repo.events = repo.events[:4]
+			},
+			expectBrokenAt: 5,
+			expectReason:   "after the last stored event",
+		},
+		{
+			name: "oldest events removed by retention",
+			tamper: func(repo *memoryAuditRepository) {
+				repo.events = repo.events[2:]
+			},
+			expectValid: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memoryAuditRepository{}
+			appendTestAuditEvents(t, repo, 5)
+			tt.tamper(repo)
+
+			result, err := newAuditService(repo, 0).VerifyChain(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectValid, result.Valid)
+			assert.Equal(t, tt.expectBrokenAt, result.BrokenAt)
+			if tt.expectReason != "" {
+				assert.Contains(t, result.Reason, tt.expectReason)
+			}
+		})
+	}
+}
+
+func TestAuditService_ListAccountActivity(t *testing.T) {
+	mockRepo := &MockAuditRepository{}
+	service := newAuditService(mockRepo, 0)
+
+	mockRepo.On("ListAuditEvents", mock.Anything, mock.MatchedBy(func(filter bson.M) bool {
+		or, ok := filter["$or"].(bson.A)
+		createdAt, _ := filter["created_at"].(bson.M)
+		return ok && len(or) == 2 &&
+			filter["type"] == AuditEventLogin &&
+			createdAt["$gte"] != nil
+	}), mongo.PaginationOptions{Page: 2, Limit: AuditListMaxLimit}).
+		Return(&mongo.PaginatedResult[AuditEvent]{}, nil)
+
+	_, err := service.ListAccountActivity(context.Background(), "account-1", &AuditQuery{
+		Type:  AuditEventLogin,
+		From:  "2026-01-01T00:00:00Z",
+		Page:  2,
+		Limit: 1000,
+	})
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+
+	_, err = service.ListAccountActivity(context.Background(), "account-1", &AuditQuery{From: "yesterday"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid from")
+}
+
+func TestAuditService_PurgeExpiredEvents(t *testing.T) {
+	repo := &memoryAuditRepository{}
+	appendTestAuditEvents(t, repo, 3)
+	repo.events[0].CreatedAt = time.Now().Add(-48 * time.Hour)
+
+	deleted, err := newAuditService(repo, 0).PurgeExpiredEvents(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+
+	deleted, err = newAuditService(repo, 24*time.Hour).PurgeExpiredEvents(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	assert.Len(t, repo.events, 2)
+}
+
+func TestAccountService_LoginRecordsAuditEvents(t *testing.T) {
+	service, mockAccountRepo, mockIdentityRepo, _ := setupAccountService()
+	repo := &memoryAuditRepository{}
+	service.auditService = newAuditService(repo, 0)
+
+	account := CreateTestAccount()
+	mockAccountRepo.On("GetByEmail", mock.Anything, account.Email).Return(account, nil)
+	mockAccountRepo.On("GetByEmail", mock.Anything, "missing@example.com").Return(nil, nil)
+	mockIdentityRepo.On("CreateSession", mock.Anything, mock.Anything).Return(CreateTestSession(), nil)
+
+	_, err := service.Login(context.Background(), &LoginRequest{Email: "missing@example.com", Password: "password123"}, "agent", "10.0.0.1")
+	require.Error(t, err)
+
+	_, err = service.Login(context.Background(), &LoginRequest{Email: account.Email, Password: "wrong-password"}, "agent", "10.0.0.1")
+	require.Error(t, err)
+
+	_, err = service.Login(context.Background(), &LoginRequest{Email: account.Email, Password: "password123"}, "agent", "10.0.0.1")
+	require.NoError(t, err)
+
+	require.Len(t, repo.events, 3)
+
+	assert.Equal(t, AuditOutcomeFailure, repo.events[0].Outcome)
+	assert.Equal(t, "unknown email", repo.events[0].Reason)
+	assert.Empty(t, repo.events[0].TargetID)
+
+	assert.Equal(t, AuditOutcomeFailure, repo.events[1].Outcome)
+	assert.Equal(t, "invalid password", repo.events[1].Reason)
+	assert.Equal(t, account.ID.Hex(), repo.events[1].TargetID)
+
+	assert.Equal(t, AuditOutcomeSuccess, repo.events[2].Outcome)
+	assert.Equal(t, account.ID.Hex(), repo.events[2].ActorID)
+	assert.Equal(t, "10.0.0.1", repo.events[2].IPAddress)
+	assert.Equal(t, "password", repo.events[2].Metadata["method"])
+
+	result, err := service.auditService.(AuditService).VerifyChain(context.Background())
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+}
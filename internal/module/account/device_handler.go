@@ -0,0 +1,70 @@
+package account
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/binding"
+)
+
+// OpenLoginReport godoc
+// @Summary Report a login from an alert email
+// @Description Target of the "this wasn't me" link in a new device alert. Signs the account out everywhere, requires a password reset before the next sign-in and emails a reset code.
+// @Tags authentication
+// @Produce json
+// @Param token query string true "Report token from the alert email"
+// @Success 200 {object} map[string]interface{} "Login reported"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Invalid or expired link"
+// @Failure 404 {object} map[string]interface{} "Account not found"
+// @Router /accounts/login/report [get]
+func (h *AccountHandler) OpenLoginReport(c *fiber.Ctx) error {
+	var req ReportLoginRequest
+	if err := binding.Query(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	return h.reportLogin(c, &req)
+}
+
+// ReportLogin godoc
+// @Summary Report a login
+// @Description Same as following the "this wasn't me" link, for clients that post the token instead.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body ReportLoginRequest true "Report token from the alert email"
+// @Success 200 {object} map[string]interface{} "Login reported"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Invalid or expired link"
+// @Failure 404 {object} map[string]interface{} "Account not found"
+// @Router /accounts/login/report [post]
+func (h *AccountHandler) ReportLogin(c *fiber.Ctx) error {
+	var req ReportLoginRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	return h.reportLogin(c, &req)
+}
+
+func (h *AccountHandler) reportLogin(c *fiber.Ctx, req *ReportLoginRequest) error {
+	if err := h.service.ReportLogin(c.Context(), req.Token); err != nil {
+		statusCode := fiber.StatusInternalServerError
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "expired") {
+			statusCode = fiber.StatusUnauthorized
+		} else if strings.Contains(err.Error(), "not found") {
+			statusCode = fiber.StatusNotFound
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"error":   "Failed to report login",
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Login reported. Every session has been signed out; reset your password with the code sent to your email to sign in again.",
+	})
+}
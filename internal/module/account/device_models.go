@@ -0,0 +1,78 @@
+package account
+
+import (
+	"html"
+	"net"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// LoginReportTokenExpiry is how long the "this wasn't me" link in a new
+	// device alert stays valid.
+	LoginReportTokenExpiry = 7 * 24 * time.Hour
+
+	loginReportTokenPurpose = "login_report"
+)
+
+// KnownDevice is a device and network an account has signed in from. A login
+// whose device fingerprint or network is not among them triggers an alert.
+type KnownDevice struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	AccountID   string             `json:"account_id" bson:"account_id"`
+	Fingerprint string             `json:"fingerprint" bson:"fingerprint"`
+	Network     string             `json:"network" bson:"network"`
+	UserAgent   string             `json:"user_agent" bson:"user_agent"`
+	IPAddress   string             `json:"ip_address" bson:"ip_address"`
+	FirstSeenAt time.Time          `json:"first_seen_at" bson:"first_seen_at"`
+	LastSeenAt  time.Time          `json:"last_seen_at" bson:"last_seen_at"`
+}
+
+type ReportLoginRequest struct {
+	Token string `json:"token" query:"token" validate:"required"`
+}
+
+// deviceFingerprint identifies a browser or client by its user agent. It is
+// coarse on purpose: a browser update changes it, which only costs an extra
+// alert.
+func deviceFingerprint(userAgent string) string {
+	return hashSecret(userAgent)
+}
+
+// networkPrefix groups addresses that usually belong to the same network, a
+// /24 for IPv4 and a /48 for IPv6, so that moving between addresses handed out
+// by the same provider does not look like a new location.
+func networkPrefix(ipAddress string) string {
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return ipAddress
+	}
+
+	if ipv4 := ip.To4(); ipv4 != nil {
+		return (&net.IPNet{IP: ipv4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+
+	return (&net.IPNet{IP: ip.Mask(net.CIDRMask(48, 128)), Mask: net.CIDRMask(48, 128)}).String()
+}
+
+func GetNewLoginAlertTemplate(userAgent, ipAddress string, at time.Time, reportLink string) EmailTemplate {
+	when := at.UTC().Format("2006-01-02 15:04 MST")
+
+	// The user agent comes from whoever signed in, so it must not be trusted
+	// as markup.
+	htmlDevice := html.EscapeString(userAgent)
+
+	htmlReport := ""
+	textReport := ""
+	if reportLink != "" {
+		htmlReport = "<p>If this wasn't you, <a href=\"" + html.EscapeString(reportLink) + "\">secure your account</a>. This signs out every session and asks you to reset your password.</p>"
+		textReport = " If this wasn't you, secure your account at " + reportLink + ". This signs out every session and asks you to reset your password."
+	}
+
+	return EmailTemplate{
+		Subject:  "New Sign-in to Your Account",
+		HtmlBody: "<h1>New Sign-in</h1><p>Your account was signed in to from a device or network we have not seen before.</p><p>Device: " + htmlDevice + "<br>IP address: " + html.EscapeString(ipAddress) + "<br>Time: " + when + "</p><p>If this was you, you can ignore this email.</p>" + htmlReport,
+		TextBody: "New Sign-in - Your account was signed in to from a device or network we have not seen before. Device: " + userAgent + ". IP address: " + ipAddress + ". Time: " + when + ". If this was you, you can ignore this email." + textReport,
+	}
+}
@@ -0,0 +1,88 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+const KnownDeviceCollectionName = "known_devices"
+
+type KnownDeviceRepository interface {
+	ListKnownDevices(ctx context.Context, accountID string) ([]KnownDevice, error)
+	RecordKnownDevice(ctx context.Context, device *KnownDevice) (*KnownDevice, error)
+	DeleteKnownDevice(ctx context.Context, accountID string, id primitive.ObjectID) error
+}
+
+type knownDeviceRepository struct {
+	repo mongo.Repository[KnownDevice]
+}
+
+var _ KnownDeviceRepository = (*knownDeviceRepository)(nil)
+
+func NewKnownDeviceRepository(mongoService *mongo.MongoService) KnownDeviceRepository {
+	return &knownDeviceRepository{
+		repo: mongo.NewRepository[KnownDevice](mongoService, KnownDeviceCollectionName),
+	}
+}
+
+func (r *knownDeviceRepository) ListKnownDevices(ctx context.Context, accountID string) ([]KnownDevice, error) {
+	devices, err := r.repo.Find(ctx, bson.M{"account_id": accountID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list known devices: %w", err)
+	}
+
+	return devices, nil
+}
+
+// RecordKnownDevice refreshes the matching device and network pair, or adds
+// it when the account has not used it before.
+func (r *knownDeviceRepository) RecordKnownDevice(ctx context.Context, device *KnownDevice) (*KnownDevice, error) {
+	now := time.Now()
+
+	filter := bson.M{
+		"account_id":  device.AccountID,
+		"fingerprint": device.Fingerprint,
+		"network":     device.Network,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"user_agent":   device.UserAgent,
+			"ip_address":   device.IPAddress,
+			"last_seen_at": now,
+		},
+	}
+
+	existing, err := r.repo.Update(ctx, filter, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update known device: %w", err)
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	device.ID = primitive.NewObjectID()
+	device.FirstSeenAt = now
+	device.LastSeenAt = now
+
+	result, err := r.repo.Create(ctx, *device)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create known device: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *knownDeviceRepository) DeleteKnownDevice(ctx context.Context, accountID string, id primitive.ObjectID) error {
+	_, err := r.repo.DeleteMany(ctx, bson.M{"_id": id, "account_id": accountID})
+	if err != nil {
+		return fmt.Errorf("failed to delete known device: %w", err)
+	}
+
+	return nil
+}
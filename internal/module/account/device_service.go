@@ -0,0 +1,207 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/resend"
+)
+
+// checkLoginDevice remembers the device and network of a login and emails the
+// owner when either is new. The first login of an account only seeds the
+// history.
+func (s *accountService) checkLoginDevice(ctx context.Context, account *Account, userAgent, ipAddress string) {
+	if s.knownDeviceRepository == nil {
+		return
+	}
+
+	accountID := account.ID.Hex()
+
+	devices, err := s.knownDeviceRepository.ListKnownDevices(ctx, accountID)
+	if err != nil {
+		fmt.Printf("Failed to list known devices of account %s: %v\n", accountID, err)
+		return
+	}
+
+	fingerprint := deviceFingerprint(userAgent)
+	network := networkPrefix(ipAddress)
+
+	firstLogin := len(devices) == 0
+	knownDevice, knownNetwork := firstLogin, firstLogin
+	for _, device := range devices {
+		if device.Fingerprint == fingerprint {
+			knownDevice = true
+		}
+		if device.Network == network {
+			knownNetwork = true
+		}
+	}
+
+	device, err := s.knownDeviceRepository.RecordKnownDevice(ctx, &KnownDevice{
+		AccountID:   accountID,
+		Fingerprint: fingerprint,
+		Network:     network,
+		UserAgent:   userAgent,
+		IPAddress:   ipAddress,
+	})
+	if err != nil {
+		fmt.Printf("Failed to record device of account %s: %v\n", accountID, err)
+		return
+	}
+
+	if knownDevice && knownNetwork {
+		return
+	}
+
+	recordAudit(ctx, s.auditService, &AuditEvent{
+		Type:      AuditEventNewDevice,
+		ActorID:   accountID,
+		TargetID:  accountID,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Metadata: map[string]string{
+			"new_device":  strconv.FormatBool(!knownDevice),
+			"new_network": strconv.FormatBool(!knownNetwork),
+		},
+	})
+
+	if err := s.sendLoginAlert(ctx, account, device, userAgent, ipAddress); err != nil {
+		fmt.Printf("Failed to send login alert to account %s: %v\n", accountID, err)
+	}
+}
+
+func (s *accountService) sendLoginAlert(ctx context.Context, account *Account, device *KnownDevice, userAgent, ipAddress string) error {
+	if s.resendService == nil {
+		return nil
+	}
+
+	var reportLink string
+	if s.loginReportURL != "" {
+		token, err := s.jwtService.GenerateWithDuration(map[string]any{
+			"purpose":         loginReportTokenPurpose,
+			"account_id":      account.ID.Hex(),
+			"known_device_id": device.ID.Hex(),
+		}, LoginReportTokenExpiry)
+		if err != nil {
+			return fmt.Errorf("failed to sign login report link: %w", err)
+		}
+
+		reportLink, err = withTokenQuery(s.loginReportURL, token)
+		if err != nil {
+			return err
+		}
+	}
+
+	template := GetNewLoginAlertTemplate(userAgent, ipAddress, time.Now(), reportLink)
+	_, err := s.resendService.SendEmail(ctx, &resend.EmailRequest{
+		From:    s.fromEmail,
+		To:      []string{account.Email},
+		Subject: template.Subject,
+		Html:    template.HtmlBody,
+		Text:    template.TextBody,
+	})
+	return err
+}
+
+// ReportLogin handles the "this wasn't me" link of a login alert. It signs
+// the account out everywhere, forgets the reported device so that it alerts
+// again, and blocks sign-in until the password is reset with the code it
+// emails.
+func (s *accountService) ReportLogin(ctx context.Context, token string) error {
+	claims, err := s.jwtService.Verify(token)
+	if err != nil {
+		return fmt.Errorf("invalid or expired report link")
+	}
+
+	purpose, _ := claims.GetCustomClaim("purpose")
+	accountIDClaim, _ := claims.GetCustomClaim("account_id")
+	deviceIDClaim, _ := claims.GetCustomClaim("known_device_id")
+	accountID, _ := accountIDClaim.(string)
+	deviceID, _ := deviceIDClaim.(string)
+	if purpose != loginReportTokenPurpose || accountID == "" {
+		return fmt.Errorf("invalid or expired report link")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(accountID)
+	if err != nil {
+		return fmt.Errorf("invalid or expired report link")
+	}
+
+	account, err := s.repository.GetByID(ctx, objectID)
+	if err != nil || account == nil || account.CurrentStatus() == AccountStatusDeleted {
+		return fmt.Errorf("account not found")
+	}
+
+	_, err = s.repository.Update(ctx, account.ID, bson.M{
+		"password_reset_required": true,
+		"updated_at":              time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to require password reset: %w", err)
+	}
+
+	s.revokeSessions(ctx, accountID, "login reported")
+
+	if deviceObjectID, err := primitive.ObjectIDFromHex(deviceID); err == nil && s.knownDeviceRepository != nil {
+		if err := s.knownDeviceRepository.DeleteKnownDevice(ctx, accountID, deviceObjectID); err != nil {
+			fmt.Printf("Failed to forget reported device: %v\n", err)
+		}
+	}
+
+	recordAudit(ctx, s.auditService, &AuditEvent{
+		Type:     AuditEventLoginReport,
+		ActorID:  accountID,
+		TargetID: accountID,
+		Metadata: map[string]string{"known_device_id": deviceID},
+	})
+
+	if err := s.sendPasswordResetCode(ctx, account.Email); err != nil {
+		fmt.Printf("Failed to send password reset code: %v\n", err)
+	}
+
+	return nil
+}
+
+func (s *accountService) sendPasswordResetCode(ctx context.Context, email string) error {
+	otp, err := s.accountIdentityRepository.CreateOTP(ctx, email, OTPPurposePasswordReset)
+	if err != nil {
+		return fmt.Errorf("failed to create password reset OTP: %w", err)
+	}
+
+	if s.resendService == nil {
+		return nil
+	}
+
+	template := GetPasswordResetTemplate(otp.Code)
+	_, err = s.resendService.SendEmail(ctx, &resend.EmailRequest{
+		From:    s.fromEmail,
+		To:      []string{email},
+		Subject: template.Subject,
+		Html:    template.HtmlBody,
+		Text:    template.TextBody,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send password reset email: %w", err)
+	}
+
+	return nil
+}
+
+func withTokenQuery(baseURL, token string) (string, error) {
+	link, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid link URL %q: %w", baseURL, err)
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String(), nil
+}
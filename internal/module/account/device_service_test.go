@@ -0,0 +1,177 @@
+package account
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/resend"
+)
+
+func setupDeviceService() (*accountService, *MockAccountRepository, *MockAccountIdentityRepository, *MockKnownDeviceRepository, *MockResendService) {
+	service, mockAccountRepo, mockIdentityRepo, _ := setupAccountService()
+	mockDeviceRepo := &MockKnownDeviceRepository{}
+	mockResend := &MockResendService{}
+	service.knownDeviceRepository = mockDeviceRepo
+	service.resendService = mockResend
+	service.loginReportURL = "https://app.example.com/login/report"
+
+	return service, mockAccountRepo, mockIdentityRepo, mockDeviceRepo, mockResend
+}
+
+func TestNetworkPrefix(t *testing.T) {
+	tests := []struct {
+		ipAddress string
+		expected  string
+	}{
+		{"203.0.113.42", "203.0.113.0/24"},
+		{"203.0.113.7", "203.0.113.0/24"},
+		{"2001:db8:1234:5678::1", "2001:db8:1234::/48"},
+		{"not-an-ip", "not-an-ip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ipAddress, func(t *testing.T) {
+			assert.Equal(t, tt.expected, networkPrefix(tt.ipAddress))
+		})
+	}
+}
+
+func TestAccountService_CheckLoginDevice(t *testing.T) {
+	const userAgent = "Mozilla/5.0 (X11; Linux x86_64) Firefox/140.0"
+	const ipAddress = "203.0.113.42"
+
+	known := KnownDevice{
+		Fingerprint: deviceFingerprint(userAgent),
+		Network:     networkPrefix(ipAddress),
+	}
+	otherDevice := KnownDevice{Fingerprint: deviceFingerprint("curl/8.0"), Network: known.Network}
+	otherNetwork := KnownDevice{Fingerprint: known.Fingerprint, Network: networkPrefix("198.51.100.1")}
+
+	tests := []struct {
+		name        string
+		devices     []KnownDevice
+		expectAlert bool
+	}{
+		{name: "first login seeds history", devices: nil},
+		{name: "known device and network", devices: []KnownDevice{known}},
+		{name: "same network, new device", devices: []KnownDevice{otherDevice}, expectAlert: true},
+		{name: "same device, new network", devices: []KnownDevice{otherNetwork}, expectAlert: true},
+		{name: "device and network seen separately", devices: []KnownDevice{otherDevice, otherNetwork}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, _, mockDeviceRepo, mockResend := setupDeviceService()
+			account := CreateTestAccount()
+			recorded := &KnownDevice{ID: primitive.NewObjectID()}
+
+			mockDeviceRepo.On("ListKnownDevices", mock.Anything, account.ID.Hex()).Return(tt.devices, nil)
+			mockDeviceRepo.On("RecordKnownDevice", mock.Anything, mock.MatchedBy(func(device *KnownDevice) bool {
+				return device.Fingerprint == known.Fingerprint && device.Network == known.Network
+			})).Return(recorded, nil)
+
+			var sent *resend.EmailRequest
+			mockResend.On("SendEmail", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				sent = args.Get(1).(*resend.EmailRequest)
+			}).Return(&resend.EmailResponse{}, nil)
+
+			service.checkLoginDevice(context.Background(), account, userAgent, ipAddress)
+
+			mockDeviceRepo.AssertExpectations(t)
+			if !tt.expectAlert {
+				mockResend.AssertNotCalled(t, "SendEmail", mock.Anything, mock.Anything)
+				return
+			}
+
+			require.NotNil(t, sent)
+			assert.Equal(t, []string{account.Email}, sent.To)
+			assert.Contains(t, sent.Text, ipAddress)
+			assert.Contains(t, sent.Text, service.loginReportURL+"?token=")
+		})
+	}
+}
+
+func TestAccountService_LoginAlertEscapesUserAgent(t *testing.T) {
+	template := GetNewLoginAlertTemplate("<script>alert(1)</script>", "203.0.113.42", CreateTestAccount().CreatedAt, "")
+
+	assert.NotContains(t, template.HtmlBody, "<script>")
+	assert.Contains(t, template.HtmlBody, "&lt;script&gt;")
+}
+
+func TestAccountService_ReportLogin(t *testing.T) {
+	service, mockAccountRepo, mockIdentityRepo, mockDeviceRepo, mockResend := setupDeviceService()
+	account := CreateTestAccount()
+	deviceID := primitive.NewObjectID()
+
+	var alert *resend.EmailRequest
+	mockResend.On("SendEmail", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		if alert == nil {
+			alert = args.Get(1).(*resend.EmailRequest)
+		}
+	}).Return(&resend.EmailResponse{}, nil)
+
+	require.NoError(t, service.sendLoginAlert(context.Background(), account, &KnownDevice{ID: deviceID}, "agent", "203.0.113.42"))
+	require.NotNil(t, alert)
+
+	start := strings.Index(alert.Text, service.loginReportURL)
+	require.NotEqual(t, -1, start)
+	link, err := url.Parse(strings.Fields(alert.Text[start:])[0])
+	require.NoError(t, err)
+	token := strings.TrimSuffix(link.Query().Get("token"), ".")
+
+	mockAccountRepo.On("GetByID", mock.Anything, account.ID).Return(account, nil)
+	mockAccountRepo.On("Update", mock.Anything, account.ID, mock.MatchedBy(func(update bson.M) bool {
+		return update["password_reset_required"] == true
+	})).Return(account, nil)
+	mockIdentityRepo.On("DeactivateAllUserSessions", mock.Anything, account.ID.Hex()).Return(nil)
+	mockDeviceRepo.On("DeleteKnownDevice", mock.Anything, account.ID.Hex(), deviceID).Return(nil)
+	mockIdentityRepo.On("CreateOTP", mock.Anything, account.Email, OTPPurposePasswordReset).Return(CreateTestOTP(), nil)
+
+	require.NoError(t, service.ReportLogin(context.Background(), token))
+
+	mockAccountRepo.AssertExpectations(t)
+	mockIdentityRepo.AssertExpectations(t)
+	mockDeviceRepo.AssertExpectations(t)
+	mockResend.AssertNumberOfCalls(t, "SendEmail", 2)
+}
+
+func TestAccountService_ReportLoginRejectsOtherTokens(t *testing.T) {
+	service, _, _, _, _ := setupDeviceService()
+	account := CreateTestAccount()
+
+	sessionToken, err := service.jwtService.Generate(CreateTestAccountJWTClaims().ToCustomClaims())
+	require.NoError(t, err)
+	magicLinkToken, err := service.jwtService.GenerateWithDuration(map[string]any{
+		"purpose":    magicLinkTokenPurpose,
+		"account_id": account.ID.Hex(),
+	}, LoginReportTokenExpiry)
+	require.NoError(t, err)
+
+	for _, token := range []string{"garbage", sessionToken, magicLinkToken} {
+		err := service.ReportLogin(context.Background(), token)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid or expired report link")
+	}
+}
+
+func TestAccountService_LoginRequiresPasswordReset(t *testing.T) {
+	service, mockAccountRepo, _, _, _ := setupDeviceService()
+	account := CreateTestAccount(func(a *Account) {
+		a.PasswordResetRequired = true
+	})
+
+	mockAccountRepo.On("GetByEmail", mock.Anything, account.Email).Return(account, nil)
+
+	_, err := service.Login(context.Background(), &LoginRequest{Email: account.Email, Password: "password123"}, "agent", "203.0.113.42")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "password reset required")
+}
@@ -0,0 +1,102 @@
+package account
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/binding"
+)
+
+// RequestEmailChange godoc
+// @Summary Request an email change
+// @Description Send a confirmation code to the new email address and notify the current one. Accounts with a password must provide it.
+// @Tags accounts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body RequestEmailChangeRequest true "New email address"
+// @Success 200 {object} map[string]interface{} "Confirmation code sent"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized - incorrect password"
+// @Failure 403 {object} map[string]interface{} "Forbidden - account inactive"
+// @Failure 409 {object} map[string]interface{} "Email already in use"
+// @Failure 429 {object} map[string]interface{} "Too many requests"
+// @Router /accounts/me/email-change [post]
+func (h *AccountHandler) RequestEmailChange(c *fiber.Ctx) error {
+	var req RequestEmailChangeRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	accountID, _ := c.Locals("account_id").(string)
+
+	if err := h.service.RequestEmailChange(c.Context(), accountID, &req, c.Get("User-Agent"), c.IP()); err != nil {
+		return c.Status(emailChangeStatus(c, err)).JSON(fiber.Map{
+			"error":   "Email change request failed",
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "A confirmation code has been sent to the new email address",
+	})
+}
+
+// ConfirmEmailChange godoc
+// @Summary Confirm an email change
+// @Description Confirm the pending email change with the code sent to the new address. Every other session of the account is signed out.
+// @Tags accounts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body ConfirmEmailChangeRequest true "Confirmation code"
+// @Success 200 {object} map[string]interface{} "Email changed successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized - invalid or expired OTP"
+// @Failure 404 {object} map[string]interface{} "No pending email change"
+// @Failure 409 {object} map[string]interface{} "Email already in use"
+// @Failure 429 {object} map[string]interface{} "Too many requests"
+// @Router /accounts/me/email-change/confirm [post]
+func (h *AccountHandler) ConfirmEmailChange(c *fiber.Ctx) error {
+	var req ConfirmEmailChangeRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	accountID, _ := c.Locals("account_id").(string)
+	token := strings.TrimPrefix(c.Get("Authorization"), "Bearer ")
+
+	account, err := h.service.ConfirmEmailChange(c.Context(), accountID, token, &req, c.Get("User-Agent"), c.IP())
+	if err != nil {
+		return c.Status(emailChangeStatus(c, err)).JSON(fiber.Map{
+			"error":   "Email change failed",
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Email changed successfully",
+		"data":    account,
+	})
+}
+
+func emailChangeStatus(c *fiber.Ctx, err error) int {
+	statusCode := fiber.StatusInternalServerError
+	message := err.Error()
+
+	switch {
+	case strings.Contains(message, "email change failed"), strings.Contains(message, "incorrect"):
+		statusCode = fiber.StatusUnauthorized
+	case strings.Contains(message, "inactive"):
+		statusCode = fiber.StatusForbidden
+	case strings.Contains(message, "not found"):
+		statusCode = fiber.StatusNotFound
+	case strings.Contains(message, "already in use"):
+		statusCode = fiber.StatusConflict
+	case strings.Contains(message, "invalid"):
+		statusCode = fiber.StatusBadRequest
+	}
+
+	return throttledStatus(c, err, statusCode)
+}
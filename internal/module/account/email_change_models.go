@@ -0,0 +1,63 @@
+package account
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type EmailChangeStatus string
+
+const (
+	EmailChangeStatusPending   EmailChangeStatus = "pending"
+	EmailChangeStatusCompleted EmailChangeStatus = "completed"
+)
+
+// EmailChange records a request to move an account to a new address. Pending
+// changes expire with their OTP; completed ones are kept as a record of who
+// changed the address, when and from where.
+type EmailChange struct {
+	ID                 primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	AccountID          string             `json:"account_id" bson:"account_id"`
+	OldEmail           string             `json:"old_email" bson:"old_email"`
+	NewEmail           string             `json:"new_email" bson:"new_email"`
+	Status             EmailChangeStatus  `json:"status" bson:"status"`
+	RequestedIPAddress string             `json:"requested_ip_address" bson:"requested_ip_address"`
+	RequestedUserAgent string             `json:"requested_user_agent" bson:"requested_user_agent"`
+	CompletedIPAddress string             `json:"completed_ip_address,omitempty" bson:"completed_ip_address,omitempty"`
+	CompletedUserAgent string             `json:"completed_user_agent,omitempty" bson:"completed_user_agent,omitempty"`
+	ExpiresAt          time.Time          `json:"expires_at" bson:"expires_at"`
+	CompletedAt        *time.Time         `json:"completed_at,omitempty" bson:"completed_at,omitempty"`
+	CreatedAt          time.Time          `json:"created_at" bson:"created_at"`
+}
+
+type RequestEmailChangeRequest struct {
+	NewEmail string `json:"new_email" validate:"required,email"`
+	// Password is required for accounts that have one; accounts created
+	// through passkeys or an identity provider may not.
+	Password string `json:"password"`
+}
+
+type ConfirmEmailChangeRequest struct {
+	OTP string `json:"otp" validate:"required,len=6"`
+}
+
+func (e *EmailChange) IsExpired() bool {
+	return time.Now().After(e.ExpiresAt)
+}
+
+func GetEmailChangeVerificationTemplate(otp, newEmail string) EmailTemplate {
+	return EmailTemplate{
+		Subject:  "Confirm Your New Email Address",
+		HtmlBody: "<h1>Confirm Email Change</h1><p>Use this code to confirm " + newEmail + " as the new email address of your account: <strong>" + otp + "</strong></p><p>This code will expire in 5 minutes. If you did not request this change, you can ignore this email.</p>",
+		TextBody: "Confirm Email Change - Use this code to confirm " + newEmail + " as the new email address of your account: " + otp + ". This code will expire in 5 minutes. If you did not request this change, you can ignore this email.",
+	}
+}
+
+func GetEmailChangeRequestedTemplate(newEmail string) EmailTemplate {
+	return EmailTemplate{
+		Subject:  "Email Change Requested",
+		HtmlBody: "<h1>Email Change Requested</h1><p>Someone asked to change the email address of your account to " + newEmail + ". If you did not make this request, change your password and contact support immediately.</p>",
+		TextBody: "Email Change Requested - Someone asked to change the email address of your account to " + newEmail + ". If you did not make this request, change your password and contact support immediately.",
+	}
+}
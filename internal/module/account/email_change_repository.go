@@ -0,0 +1,88 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+const EmailChangeCollectionName = "email_changes"
+
+type EmailChangeRepository interface {
+	CreateEmailChange(ctx context.Context, change *EmailChange) (*EmailChange, error)
+	GetPendingEmailChange(ctx context.Context, accountID string) (*EmailChange, error)
+	CompleteEmailChange(ctx context.Context, id primitive.ObjectID, ipAddress, userAgent string) error
+}
+
+type emailChangeRepository struct {
+	repo mongo.Repository[EmailChange]
+}
+
+var _ EmailChangeRepository = (*emailChangeRepository)(nil)
+
+func NewEmailChangeRepository(mongoService *mongo.MongoService) EmailChangeRepository {
+	return &emailChangeRepository{
+		repo: mongo.NewRepository[EmailChange](mongoService, EmailChangeCollectionName),
+	}
+}
+
+// CreateEmailChange replaces any pending change of the same account, so only
+// the most recently requested address can be confirmed.
+func (r *emailChangeRepository) CreateEmailChange(ctx context.Context, change *EmailChange) (*EmailChange, error) {
+	_, err := r.repo.DeleteMany(ctx, bson.M{
+		"account_id": change.AccountID,
+		"status":     EmailChangeStatusPending,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to replace pending email change: %w", err)
+	}
+
+	change.ID = primitive.NewObjectID()
+	change.Status = EmailChangeStatusPending
+	change.CreatedAt = time.Now()
+
+	result, err := r.repo.Create(ctx, *change)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create email change: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *emailChangeRepository) GetPendingEmailChange(ctx context.Context, accountID string) (*EmailChange, error) {
+	filter := bson.M{
+		"account_id": accountID,
+		"status":     EmailChangeStatusPending,
+	}
+
+	result, err := r.repo.FindOne(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending email change: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *emailChangeRepository) CompleteEmailChange(ctx context.Context, id primitive.ObjectID, ipAddress, userAgent string) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{
+		"$set": bson.M{
+			"status":               EmailChangeStatusCompleted,
+			"completed_ip_address": ipAddress,
+			"completed_user_agent": userAgent,
+			"completed_at":         time.Now(),
+		},
+	}
+
+	_, err := r.repo.Update(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to complete email change: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/argon2"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/resend"
+)
+
+// RequestEmailChange sends a code to the new address and warns the current
+// one. The code is stored against the current address so that it can only
+// confirm a change for this account.
+func (s *accountService) RequestEmailChange(ctx context.Context, accountID string, req *RequestEmailChangeRequest, userAgent, ipAddress string) error {
+	account, err := s.getActiveAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	newEmail := strings.TrimSpace(req.NewEmail)
+	if newEmail == "" {
+		return fmt.Errorf("invalid email: new email is required")
+	}
+	if strings.EqualFold(newEmail, account.Email) {
+		return fmt.Errorf("invalid email: new email must differ from the current one")
+	}
+
+	if account.PasswordHash != "" {
+		isValid, err := argon2.VerifyPassword(req.Password, account.PasswordHash)
+		if err != nil || !isValid {
+			return fmt.Errorf("current password is incorrect")
+		}
+	}
+
+	if err := s.checkRateLimit(ctx, rateLimitKey("otp", "request", newEmail), OTPRequestRateLimit, "too many codes requested for this email"); err != nil {
+		return err
+	}
+
+	exists, err := s.repository.ExistsByEmail(ctx, newEmail)
+	if err != nil {
+		return fmt.Errorf("failed to check email availability: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("email is already in use")
+	}
+
+	_, err = s.emailChangeRepository.CreateEmailChange(ctx, &EmailChange{
+		AccountID:          accountID,
+		OldEmail:           account.Email,
+		NewEmail:           newEmail,
+		RequestedIPAddress: ipAddress,
+		RequestedUserAgent: userAgent,
+		ExpiresAt:          time.Now().Add(OTPExpiry),
+	})
+	if err != nil {
+		return err
+	}
+
+	otp, err := s.accountIdentityRepository.CreateOTP(ctx, account.Email, OTPPurposeEmailChange)
+	if err != nil {
+		return fmt.Errorf("failed to create email change OTP: %w", err)
+	}
+
+	if s.resendService == nil {
+		return nil
+	}
+
+	template := GetEmailChangeVerificationTemplate(otp.Code, newEmail)
+	_, err = s.resendService.SendEmail(ctx, &resend.EmailRequest{
+		From:    s.fromEmail,
+		To:      []string{newEmail},
+		Subject: template.Subject,
+		Html:    template.HtmlBody,
+		Text:    template.TextBody,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send email change verification: %w", err)
+	}
+
+	notice := GetEmailChangeRequestedTemplate(newEmail)
+	_, err = s.resendService.SendEmail(ctx, &resend.EmailRequest{
+		From:    s.fromEmail,
+		To:      []string{account.Email},
+		Subject: notice.Subject,
+		Html:    notice.HtmlBody,
+		Text:    notice.TextBody,
+	})
+	if err != nil {
+		fmt.Printf("Failed to send email change notice: %v\n", err)
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange swaps in the pending address and signs out every other
+// session, keeping the one identified by token.
+func (s *accountService) ConfirmEmailChange(ctx context.Context, accountID, token string, req *ConfirmEmailChangeRequest, userAgent, ipAddress string) (*AccountResponse, error) {
+	if err := s.checkRateLimit(ctx, rateLimitKey("otp", "verify", accountID), OTPVerifyRateLimit, "too many verification attempts for this account"); err != nil {
+		return nil, err
+	}
+
+	account, err := s.getActiveAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	change, err := s.emailChangeRepository.GetPendingEmailChange(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if change == nil || change.IsExpired() || change.OldEmail != account.Email {
+		return nil, fmt.Errorf("pending email change not found")
+	}
+
+	_, err = s.accountIdentityRepository.ValidateOTP(ctx, account.Email, OTPPurposeEmailChange, req.OTP)
+	if err != nil {
+		recordAudit(ctx, s.auditService, &AuditEvent{
+			Type:     AuditEventEmailChange,
+			Outcome:  AuditOutcomeFailure,
+			TargetID: accountID,
+			Reason:   err.Error(),
+		})
+		return nil, fmt.Errorf("email change failed: %w", err)
+	}
+
+	updated, err := s.repository.ChangeEmail(ctx, account.ID, account.Email, change.NewEmail)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("email is already in use")
+	}
+
+	if err := s.emailChangeRepository.CompleteEmailChange(ctx, change.ID, ipAddress, userAgent); err != nil {
+		fmt.Printf("Failed to record email change %s: %v\n", change.ID.Hex(), err)
+	}
+
+	if err := s.accountIdentityRepository.DeleteOTP(ctx, account.Email, OTPPurposeEmailChange); err != nil {
+		fmt.Printf("Failed to delete OTP: %v\n", err)
+	}
+
+	recordAudit(ctx, s.auditService, &AuditEvent{
+		Type:      AuditEventEmailChange,
+		TargetID:  accountID,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Metadata: map[string]string{
+			"old_email": change.OldEmail,
+			"new_email": change.NewEmail,
+		},
+	})
+
+	s.deactivateOtherSessions(ctx, accountID, token)
+
+	return updated.ToResponse(), nil
+}
+
+func (s *accountService) getActiveAccount(ctx context.Context, accountID string) (*Account, error) {
+	objectID, err := primitive.ObjectIDFromHex(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account ID format: %w", err)
+	}
+
+	account, err := s.repository.GetByID(ctx, objectID)
+	if err != nil || account == nil {
+		return nil, fmt.Errorf("account not found")
+	}
+
+	if !account.IsActive {
+		return nil, account.inactiveError()
+	}
+
+	return account, nil
+}
+
+func (s *accountService) deactivateOtherSessions(ctx context.Context, accountID, token string) {
+	sessions, err := s.accountIdentityRepository.GetSessionsByAccountID(ctx, accountID)
+	if err != nil {
+		fmt.Printf("Failed to list sessions of account %s: %v\n", accountID, err)
+		return
+	}
+
+	currentHash := hashSecret(token)
+	for _, session := range sessions {
+		if !session.IsActive || session.TokenHash == currentHash {
+			continue
+		}
+
+		if err := s.accountIdentityRepository.DeactivateSession(ctx, session.TokenHash); err != nil {
+			fmt.Printf("Failed to deactivate session %s: %v\n", session.ID.Hex(), err)
+		}
+	}
+
+	recordAudit(ctx, s.auditService, &AuditEvent{
+		Type:     AuditEventSessionRevoke,
+		TargetID: accountID,
+		Reason:   "email changed",
+	})
+}
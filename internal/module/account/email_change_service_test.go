@@ -0,0 +1,150 @@
+package account
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupEmailChangeService() (*accountService, *MockAccountRepository, *MockAccountIdentityRepository, *MockEmailChangeRepository) {
+	service, mockAccountRepo, mockIdentityRepo, _ := setupAccountService()
+	mockEmailChangeRepo := &MockEmailChangeRepository{}
+	service.emailChangeRepository = mockEmailChangeRepo
+
+	return service, mockAccountRepo, mockIdentityRepo, mockEmailChangeRepo
+}
+
+func TestAccountService_RequestEmailChange(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       *RequestEmailChangeRequest
+		emailInUse    bool
+		expectedError string
+	}{
+		{
+			name:    "sends code for the current account",
+			request: &RequestEmailChangeRequest{NewEmail: "new@example.com", Password: "password123"},
+		},
+		{
+			name:          "wrong password",
+			request:       &RequestEmailChangeRequest{NewEmail: "new@example.com", Password: "wrong-password"},
+			expectedError: "current password is incorrect",
+		},
+		{
+			name:          "same address",
+			request:       &RequestEmailChangeRequest{NewEmail: "Test@Example.com", Password: "password123"},
+			expectedError: "must differ",
+		},
+		{
+			name:          "address taken",
+			request:       &RequestEmailChangeRequest{NewEmail: "new@example.com", Password: "password123"},
+			emailInUse:    true,
+			expectedError: "already in use",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockAccountRepo, mockIdentityRepo, mockEmailChangeRepo := setupEmailChangeService()
+			account := CreateTestAccount()
+
+			mockAccountRepo.On("GetByID", mock.Anything, account.ID).Return(account, nil)
+			mockAccountRepo.On("ExistsByEmail", mock.Anything, "new@example.com").Return(tt.emailInUse, nil)
+			mockEmailChangeRepo.On("CreateEmailChange", mock.Anything, mock.MatchedBy(func(change *EmailChange) bool {
+				return change.AccountID == account.ID.Hex() && change.OldEmail == account.Email &&
+					change.NewEmail == "new@example.com" && change.RequestedIPAddress == "127.0.0.1"
+			})).Return(&EmailChange{}, nil)
+			mockIdentityRepo.On("CreateOTP", mock.Anything, account.Email, OTPPurposeEmailChange).Return(CreateTestOTP(), nil)
+
+			err := service.RequestEmailChange(context.Background(), account.ID.Hex(), tt.request, "agent", "127.0.0.1")
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				mockIdentityRepo.AssertNotCalled(t, "CreateOTP", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			require.NoError(t, err)
+			mockEmailChangeRepo.AssertExpectations(t)
+			mockIdentityRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAccountService_ConfirmEmailChange(t *testing.T) {
+	service, mockAccountRepo, mockIdentityRepo, mockEmailChangeRepo := setupEmailChangeService()
+	account := CreateTestAccount()
+	change := &EmailChange{
+		AccountID: account.ID.Hex(),
+		OldEmail:  account.Email,
+		NewEmail:  "new@example.com",
+		Status:    EmailChangeStatusPending,
+		ExpiresAt: time.Now().Add(OTPExpiry),
+	}
+	updated := CreateTestAccount(func(a *Account) {
+		a.ID = account.ID
+		a.Email = change.NewEmail
+	})
+	current := CreateTestSession(func(s *Session) { s.TokenHash = hashSecret("current-token") })
+	other := CreateTestSession(func(s *Session) { s.TokenHash = hashSecret("other-token") })
+
+	mockAccountRepo.On("GetByID", mock.Anything, account.ID).Return(account, nil)
+	mockEmailChangeRepo.On("GetPendingEmailChange", mock.Anything, account.ID.Hex()).Return(change, nil)
+	mockIdentityRepo.On("ValidateOTP", mock.Anything, account.Email, OTPPurposeEmailChange, "123456").Return(CreateTestOTP(), nil)
+	mockAccountRepo.On("ChangeEmail", mock.Anything, account.ID, account.Email, change.NewEmail).Return(updated, nil)
+	mockEmailChangeRepo.On("CompleteEmailChange", mock.Anything, change.ID, "127.0.0.1", "agent").Return(nil)
+	mockIdentityRepo.On("DeleteOTP", mock.Anything, account.Email, OTPPurposeEmailChange).Return(nil)
+	mockIdentityRepo.On("GetSessionsByAccountID", mock.Anything, account.ID.Hex()).Return([]*Session{current, other}, nil)
+	mockIdentityRepo.On("DeactivateSession", mock.Anything, other.TokenHash).Return(nil).Once()
+
+	response, err := service.ConfirmEmailChange(context.Background(), account.ID.Hex(), "current-token",
+		&ConfirmEmailChangeRequest{OTP: "123456"}, "agent", "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, change.NewEmail, response.Email)
+
+	mockAccountRepo.AssertExpectations(t)
+	mockEmailChangeRepo.AssertExpectations(t)
+	mockIdentityRepo.AssertExpectations(t)
+	mockIdentityRepo.AssertNotCalled(t, "DeactivateSession", mock.Anything, current.TokenHash)
+}
+
+func TestAccountService_ConfirmEmailChange_AddressTakenMeanwhile(t *testing.T) {
+	service, mockAccountRepo, mockIdentityRepo, mockEmailChangeRepo := setupEmailChangeService()
+	account := CreateTestAccount()
+	change := &EmailChange{
+		OldEmail:  account.Email,
+		NewEmail:  "new@example.com",
+		ExpiresAt: time.Now().Add(OTPExpiry),
+	}
+
+	mockAccountRepo.On("GetByID", mock.Anything, account.ID).Return(account, nil)
+	mockEmailChangeRepo.On("GetPendingEmailChange", mock.Anything, account.ID.Hex()).Return(change, nil)
+	mockIdentityRepo.On("ValidateOTP", mock.Anything, account.Email, OTPPurposeEmailChange, "123456").Return(CreateTestOTP(), nil)
+	mockAccountRepo.On("ChangeEmail", mock.Anything, account.ID, account.Email, change.NewEmail).Return(nil, nil)
+
+	_, err := service.ConfirmEmailChange(context.Background(), account.ID.Hex(), "token",
+		&ConfirmEmailChangeRequest{OTP: "123456"}, "agent", "127.0.0.1")
+	assert.EqualError(t, err, "email is already in use")
+	mockEmailChangeRepo.AssertNotCalled(t, "CompleteEmailChange", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockIdentityRepo.AssertNotCalled(t, "GetSessionsByAccountID", mock.Anything, mock.Anything)
+}
+
+func TestAccountService_ConfirmEmailChange_NoPendingChange(t *testing.T) {
+	service, mockAccountRepo, _, mockEmailChangeRepo := setupEmailChangeService()
+	account := CreateTestAccount()
+	expired := &EmailChange{
+		OldEmail:  account.Email,
+		NewEmail:  "new@example.com",
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}
+
+	mockAccountRepo.On("GetByID", mock.Anything, account.ID).Return(account, nil)
+	mockEmailChangeRepo.On("GetPendingEmailChange", mock.Anything, account.ID.Hex()).Return(expired, nil)
+
+	_, err := service.ConfirmEmailChange(context.Background(), account.ID.Hex(), "token",
+		&ConfirmEmailChangeRequest{OTP: "123456"}, "agent", "127.0.0.1")
+	assert.EqualError(t, err, "pending email change not found")
+}
@@ -0,0 +1,154 @@
+package account
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/binding"
+)
+
+type FederationHandler struct {
+	service FederationService
+}
+
+func NewFederationHandler(service FederationService) *FederationHandler {
+	return &FederationHandler{
+		service: service,
+	}
+}
+
+// ListProviders godoc
+// @Summary List identity providers
+// @Description List the OpenID Connect providers that can be used to sign in
+// @Tags federation
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Providers retrieved successfully"
+// @Router /accounts/oidc/providers [get]
+func (h *FederationHandler) ListProviders(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"message": "Providers retrieved successfully",
+		"data":    h.service.ListProviders(),
+	})
+}
+
+// Authorize godoc
+// @Summary Begin federated login
+// @Description Create an authorization request (state, nonce and PKCE challenge) for the given provider and return the URL to redirect the user to
+// @Tags federation
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Success 200 {object} map[string]interface{} "Authorization URL generated"
+// @Failure 404 {object} map[string]interface{} "Provider not found"
+// @Failure 502 {object} map[string]interface{} "Identity provider unavailable"
+// @Router /accounts/oidc/{provider}/authorize [get]
+func (h *FederationHandler) Authorize(c *fiber.Ctx) error {
+	response, err := h.service.BeginLogin(c.Context(), c.Params("provider"))
+	if err != nil {
+		statusCode := fiber.StatusInternalServerError
+		if strings.Contains(err.Error(), "not found") {
+			statusCode = fiber.StatusNotFound
+		} else if strings.Contains(err.Error(), "discover") {
+			statusCode = fiber.StatusBadGateway
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"error":   "Failed to begin federated login",
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Authorization URL generated",
+		"data":    response,
+	})
+}
+
+// Callback godoc
+// @Summary Complete federated login
+// @Description Exchange the authorization code returned by the provider, verify the ID token and create a session
+// @Tags federation
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param code query string true "Authorization code"
+// @Param state query string true "Authorization state"
+// @Success 200 {object} map[string]interface{} "Login successful"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized - invalid state or ID token"
+// @Failure 403 {object} map[string]interface{} "Forbidden - account inactive"
+// @Failure 409 {object} map[string]interface{} "Conflict - account cannot be linked"
+// @Router /accounts/oidc/{provider}/callback [get]
+func (h *FederationHandler) Callback(c *fiber.Ctx) error {
+	if errorCode := c.Query("error"); errorCode != "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   "Login failed",
+			"message": "identity provider returned " + errorCode + ": " + c.Query("error_description"),
+		})
+	}
+
+	var req OIDCCallbackRequest
+	if err := binding.Query(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	userAgent := c.Get("User-Agent")
+	ipAddress := c.IP()
+
+	response, err := h.service.CompleteLogin(c.Context(), c.Params("provider"), &req, userAgent, ipAddress)
+	if err != nil {
+		statusCode := fiber.StatusUnauthorized
+		if strings.Contains(err.Error(), "not found") {
+			statusCode = fiber.StatusNotFound
+		} else if strings.Contains(err.Error(), "inactive") {
+			statusCode = fiber.StatusForbidden
+		} else if strings.Contains(err.Error(), "cannot link") {
+			statusCode = fiber.StatusConflict
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"error":   "Login failed",
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Login successful",
+		"data":    response,
+	})
+}
+
+// ListIdentities godoc
+// @Summary List linked identities
+// @Description List the external identities linked to the authenticated account
+// @Tags federation
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Identities retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /accounts/me/identities [get]
+func (h *FederationHandler) ListIdentities(c *fiber.Ctx) error {
+	accountID, ok := c.Locals("account_id").(string)
+	if !ok || accountID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   "Unauthorized",
+			"message": "Account ID not found in context",
+		})
+	}
+
+	identities, err := h.service.ListIdentities(c.Context(), accountID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to list identities",
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Identities retrieved successfully",
+		"data":    identities,
+	})
+}
@@ -0,0 +1,88 @@
+package account
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const OIDCAuthStateExpiry = 10 * time.Minute
+
+type OIDCProviderConfig struct {
+	Name         string   `json:"name"`
+	DisplayName  string   `json:"display_name"`
+	IssuerURL    string   `json:"issuer_url"`
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"-"`
+	RedirectURL  string   `json:"redirect_url"`
+	Scopes       []string `json:"scopes"`
+}
+
+type FederatedIdentity struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	AccountID     string             `json:"account_id" bson:"account_id"`
+	Provider      string             `json:"provider" bson:"provider"`
+	Subject       string             `json:"subject" bson:"subject"`
+	Email         string             `json:"email" bson:"email"`
+	EmailVerified bool               `json:"email_verified" bson:"email_verified"`
+	CreatedAt     time.Time          `json:"created_at" bson:"created_at"`
+	LastLoginAt   time.Time          `json:"last_login_at" bson:"last_login_at"`
+}
+
+type OIDCAuthState struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	State        string             `json:"state" bson:"state"`
+	Provider     string             `json:"provider" bson:"provider"`
+	Nonce        string             `json:"-" bson:"nonce"`
+	CodeVerifier string             `json:"-" bson:"code_verifier"`
+	ExpiresAt    time.Time          `json:"expires_at" bson:"expires_at"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+}
+
+type OIDCClaims struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	GivenName         string `json:"given_name"`
+	FamilyName        string `json:"family_name"`
+	PreferredUsername string `json:"preferred_username"`
+	Picture           string `json:"picture"`
+}
+
+type OIDCCallbackRequest struct {
+	Code  string `query:"code" validate:"required"`
+	State string `query:"state" validate:"required"`
+}
+
+type OIDCAuthorizationResponse struct {
+	AuthorizationURL string    `json:"authorization_url"`
+	State            string    `json:"state"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+type OIDCProviderInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+type FederatedIdentityResponse struct {
+	ID          string    `json:"id"`
+	Provider    string    `json:"provider"`
+	Email       string    `json:"email"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastLoginAt time.Time `json:"last_login_at"`
+}
+
+func (s *OIDCAuthState) IsExpired() bool {
+	return time.Now().After(s.ExpiresAt)
+}
+
+func (i *FederatedIdentity) ToResponse() *FederatedIdentityResponse {
+	return &FederatedIdentityResponse{
+		ID:          i.ID.Hex(),
+		Provider:    i.Provider,
+		Email:       i.Email,
+		CreatedAt:   i.CreatedAt,
+		LastLoginAt: i.LastLoginAt,
+	}
+}
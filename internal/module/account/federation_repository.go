@@ -0,0 +1,134 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+const (
+	FederatedIdentityCollectionName = "federated_identities"
+	OIDCAuthStateCollectionName     = "oidc_auth_states"
+)
+
+type FederationRepository interface {
+	CreateIdentity(ctx context.Context, identity *FederatedIdentity) (*FederatedIdentity, error)
+	GetIdentity(ctx context.Context, provider, subject string) (*FederatedIdentity, error)
+	GetIdentitiesByAccountID(ctx context.Context, accountID string) ([]*FederatedIdentity, error)
+	UpdateIdentityLastLogin(ctx context.Context, id primitive.ObjectID, email string, emailVerified bool) error
+
+	CreateAuthState(ctx context.Context, state *OIDCAuthState) (*OIDCAuthState, error)
+	ConsumeAuthState(ctx context.Context, state string) (*OIDCAuthState, error)
+}
+
+type federationRepository struct {
+	identityRepo mongo.Repository[FederatedIdentity]
+	stateRepo    mongo.Repository[OIDCAuthState]
+}
+
+var _ FederationRepository = (*federationRepository)(nil)
+
+func NewFederationRepository(mongoService *mongo.MongoService) FederationRepository {
+	return &federationRepository{
+		identityRepo: mongo.NewRepository[FederatedIdentity](mongoService, FederatedIdentityCollectionName),
+		stateRepo:    mongo.NewRepository[OIDCAuthState](mongoService, OIDCAuthStateCollectionName),
+	}
+}
+
+func (r *federationRepository) CreateIdentity(ctx context.Context, identity *FederatedIdentity) (*FederatedIdentity, error) {
+	identity.ID = primitive.NewObjectID()
+
+	result, err := r.identityRepo.Create(ctx, *identity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create federated identity: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *federationRepository) GetIdentity(ctx context.Context, provider, subject string) (*FederatedIdentity, error) {
+	filter := bson.M{
+		"provider": provider,
+		"subject":  subject,
+	}
+
+	result, err := r.identityRepo.FindOne(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get federated identity: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *federationRepository) GetIdentitiesByAccountID(ctx context.Context, accountID string) ([]*FederatedIdentity, error) {
+	filter := bson.M{"account_id": accountID}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	results, err := r.identityRepo.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get federated identities: %w", err)
+	}
+
+	identities := make([]*FederatedIdentity, len(results))
+	for i := range results {
+		identities[i] = &results[i]
+	}
+
+	return identities, nil
+}
+
+func (r *federationRepository) UpdateIdentityLastLogin(ctx context.Context, id primitive.ObjectID, email string, emailVerified bool) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{
+		"$set": bson.M{
+			"email":          email,
+			"email_verified": emailVerified,
+			"last_login_at":  time.Now(),
+		},
+	}
+
+	_, err := r.identityRepo.Update(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update federated identity: %w", err)
+	}
+
+	return nil
+}
+
+func (r *federationRepository) CreateAuthState(ctx context.Context, state *OIDCAuthState) (*OIDCAuthState, error) {
+	state.ID = primitive.NewObjectID()
+
+	result, err := r.stateRepo.Create(ctx, *state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OIDC state: %w", err)
+	}
+
+	return result, nil
+}
+
+// ConsumeAuthState loads and removes an authorization state so that each
+// callback can be redeemed at most once.
+func (r *federationRepository) ConsumeAuthState(ctx context.Context, state string) (*OIDCAuthState, error) {
+	filter := bson.M{"state": state}
+
+	result, err := r.stateRepo.FindOne(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OIDC state: %w", err)
+	}
+
+	if result == nil {
+		return nil, nil
+	}
+
+	if err := r.stateRepo.Delete(ctx, bson.M{"_id": result.ID}); err != nil {
+		return nil, fmt.Errorf("failed to consume OIDC state: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,370 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/oauth2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+type FederationService interface {
+	ListProviders() []*OIDCProviderInfo
+	BeginLogin(ctx context.Context, provider string) (*OIDCAuthorizationResponse, error)
+	CompleteLogin(ctx context.Context, provider string, req *OIDCCallbackRequest, userAgent, ipAddress string) (*LoginResponse, error)
+	ListIdentities(ctx context.Context, accountID string) ([]*FederatedIdentityResponse, error)
+}
+
+type federationService struct {
+	providers         map[string]*oidcProvider
+	repository        FederationRepository
+	accountRepository AccountRepository
+	accountService    AccountService
+}
+
+// oidcProvider resolves the issuer's discovery document on first use so that
+// an unreachable IdP does not prevent the server from starting.
+type oidcProvider struct {
+	config OIDCProviderConfig
+
+	mu           sync.Mutex
+	oauth2Config *oauth2.Config
+	verifier     *oidc.IDTokenVerifier
+}
+
+func NewFederationService(
+	mongoService *mongo.MongoService,
+	accountService AccountService,
+	providers []OIDCProviderConfig,
+) FederationService {
+	return newFederationService(
+		NewFederationRepository(mongoService),
+		NewAccountRepository(mongoService),
+		accountService,
+		providers,
+	)
+}
+
+func newFederationService(
+	repository FederationRepository,
+	accountRepository AccountRepository,
+	accountService AccountService,
+	providers []OIDCProviderConfig,
+) *federationService {
+	registered := make(map[string]*oidcProvider, len(providers))
+	for _, config := range providers {
+		if len(config.Scopes) == 0 {
+			config.Scopes = []string{"profile", "email"}
+		}
+		registered[config.Name] = &oidcProvider{config: config}
+	}
+
+	return &federationService{
+		providers:         registered,
+		repository:        repository,
+		accountRepository: accountRepository,
+		accountService:    accountService,
+	}
+}
+
+func (s *federationService) ListProviders() []*OIDCProviderInfo {
+	providers := make([]*OIDCProviderInfo, 0, len(s.providers))
+	for _, provider := range s.providers {
+		displayName := provider.config.DisplayName
+		if displayName == "" {
+			displayName = provider.config.Name
+		}
+		providers = append(providers, &OIDCProviderInfo{
+			Name:        provider.config.Name,
+			DisplayName: displayName,
+		})
+	}
+
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i].Name < providers[j].Name
+	})
+
+	return providers
+}
+
+func (s *federationService) BeginLogin(ctx context.Context, provider string) (*OIDCAuthorizationResponse, error) {
+	p, err := s.resolveProvider(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := generateRandomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate state: %w", err)
+	}
+
+	nonce, err := generateRandomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	authState := &OIDCAuthState{
+		State:        state,
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: oauth2.GenerateVerifier(),
+		ExpiresAt:    time.Now().Add(OIDCAuthStateExpiry),
+		CreatedAt:    time.Now(),
+	}
+
+	if _, err := s.repository.CreateAuthState(ctx, authState); err != nil {
+		return nil, fmt.Errorf("failed to store authorization state: %w", err)
+	}
+
+	authorizationURL := p.oauth2Config.AuthCodeURL(
+		state,
+		oidc.Nonce(nonce),
+		oauth2.S256ChallengeOption(authState.CodeVerifier),
+	)
+
+	return &OIDCAuthorizationResponse{
+		AuthorizationURL: authorizationURL,
+		State:            state,
+		ExpiresAt:        authState.ExpiresAt,
+	}, nil
+}
+
+func (s *federationService) CompleteLogin(ctx context.Context, provider string, req *OIDCCallbackRequest, userAgent, ipAddress string) (*LoginResponse, error) {
+	p, err := s.resolveProvider(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	authState, err := s.repository.ConsumeAuthState(ctx, req.State)
+	if err != nil || authState == nil {
+		return nil, fmt.Errorf("invalid authorization state")
+	}
+
+	if authState.Provider != provider {
+		return nil, fmt.Errorf("invalid authorization state")
+	}
+
+	if authState.IsExpired() {
+		return nil, fmt.Errorf("authorization state has expired")
+	}
+
+	token, err := p.oauth2Config.Exchange(ctx, req.Code, oauth2.VerifierOption(authState.CodeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, fmt.Errorf("identity provider did not return an id_token")
+	}
+
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+
+	if idToken.Nonce != authState.Nonce {
+		return nil, fmt.Errorf("invalid id_token: nonce mismatch")
+	}
+
+	var claims OIDCClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("invalid id_token claims: %w", err)
+	}
+	claims.Subject = idToken.Subject
+
+	account, err := s.resolveAccount(ctx, provider, &claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.accountService.IssueSession(ctx, account.ID.Hex(), userAgent, ipAddress)
+}
+
+func (s *federationService) ListIdentities(ctx context.Context, accountID string) ([]*FederatedIdentityResponse, error) {
+	identities, err := s.repository.GetIdentitiesByAccountID(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list federated identities: %w", err)
+	}
+
+	responses := make([]*FederatedIdentityResponse, len(identities))
+	for i, identity := range identities {
+		responses[i] = identity.ToResponse()
+	}
+
+	return responses, nil
+}
+
+// resolveAccount maps a verified set of IdP claims to a local account. A
+// previously linked identity wins; otherwise the IdP must assert that the
+// email is verified, and the account using it is linked or, when there is
+// none yet, a new account is created.
+func (s *federationService) resolveAccount(ctx context.Context, provider string, claims *OIDCClaims) (*Account, error) {
+	identity, err := s.repository.GetIdentity(ctx, provider, claims.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get federated identity: %w", err)
+	}
+
+	if identity != nil {
+		account, err := s.getAccount(ctx, identity.AccountID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.repository.UpdateIdentityLastLogin(ctx, identity.ID, claims.Email, claims.EmailVerified); err != nil {
+			fmt.Printf("Failed to update federated identity: %v\n", err)
+		}
+
+		return account, nil
+	}
+
+	if claims.Email == "" {
+		return nil, fmt.Errorf("identity provider did not share an email address")
+	}
+
+	account, err := s.accountRepository.GetByEmail(ctx, claims.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account by email: %w", err)
+	}
+
+	if account != nil && !claims.EmailVerified {
+		return nil, fmt.Errorf("cannot link account: email is not verified by the identity provider")
+	}
+
+	if account == nil && !claims.EmailVerified {
+		return nil, fmt.Errorf("cannot create account: email is not verified by the identity provider")
+	}
+
+	if account == nil {
+		account, err = s.createAccount(ctx, claims)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	_, err = s.repository.CreateIdentity(ctx, &FederatedIdentity{
+		AccountID:     account.ID.Hex(),
+		Provider:      provider,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		CreatedAt:     now,
+		LastLoginAt:   now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to link federated identity: %w", err)
+	}
+
+	return account, nil
+}
+
+func (s *federationService) getAccount(ctx context.Context, accountID string) (*Account, error) {
+	objectID, err := primitive.ObjectIDFromHex(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account ID format: %w", err)
+	}
+
+	account, err := s.accountRepository.GetByID(ctx, objectID)
+	if err != nil || account == nil {
+		return nil, fmt.Errorf("linked account not found")
+	}
+
+	return account, nil
+}
+
+func (s *federationService) createAccount(ctx context.Context, claims *OIDCClaims) (*Account, error) {
+	username, err := s.availableUsername(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	// Providers are only trusted for email addresses they have verified, so
+	// the new account starts out verified and active.
+	now := time.Now()
+	account := &Account{
+		Email:           claims.Email,
+		Username:        username,
+		FirstName:       claims.GivenName,
+		LastName:        claims.FamilyName,
+		Avatar:          claims.Picture,
+		Status:          AccountStatusActive,
+		IsActive:        true,
+		EmailVerifiedAt: &now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	created, err := s.accountRepository.Create(ctx, account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create account: %w", err)
+	}
+
+	return created, nil
+}
+
+func (s *federationService) availableUsername(ctx context.Context, claims *OIDCClaims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	candidate := base
+	for attempt := 0; attempt < 5; attempt++ {
+		exists, err := s.accountRepository.ExistsByUsername(ctx, candidate)
+		if err != nil {
+			return "", fmt.Errorf("failed to check username existence: %w", err)
+		}
+		if !exists {
+			return candidate, nil
+		}
+
+		suffix, err := generateRandomToken(3)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate username: %w", err)
+		}
+		candidate = base + "-" + strings.ToLower(suffix)
+	}
+
+	return "", fmt.Errorf("failed to generate a unique username")
+}
+
+func (s *federationService) resolveProvider(ctx context.Context, name string) (*oidcProvider, error) {
+	p, ok := s.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("identity provider %s not found", name)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.oauth2Config != nil {
+		return p, nil
+	}
+
+	discoveryCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	provider, err := oidc.NewProvider(discoveryCtx, p.config.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover identity provider %s: %w", name, err)
+	}
+
+	p.verifier = provider.Verifier(&oidc.Config{ClientID: p.config.ClientID})
+	p.oauth2Config = &oauth2.Config{
+		ClientID:     p.config.ClientID,
+		ClientSecret: p.config.ClientSecret,
+		RedirectURL:  p.config.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       append([]string{oidc.ScopeOpenID}, p.config.Scopes...),
+	}
+
+	return p, nil
+}
@@ -0,0 +1,352 @@
+package account
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testOIDCClientID    = "platform-client"
+	testOIDCRedirectURL = "http://localhost:3000/api/v1/accounts/oidc/acme/callback"
+)
+
+// mockOIDCProvider is a tiny OpenID provider that serves discovery, JWKS and
+// a token endpoint which enforces the PKCE challenge sent on authorization.
+type mockOIDCProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]url.Values
+	claims map[string]any
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &mockOIDCProvider{
+		key:    key,
+		grants: make(map[string]url.Values),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                                p.server.URL,
+			"authorization_endpoint":                p.server.URL + "/authorize",
+			"token_endpoint":                        p.server.URL + "/token",
+			"jwks_uri":                              p.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test-key",
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", p.token)
+
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+
+	return p
+}
+
+// authorize plays the part of the user approving the request in a browser
+// and returns the code that would be delivered to the redirect URI.
+func (p *mockOIDCProvider) authorize(t *testing.T, authorizationURL string, claims map[string]any) string {
+	parsed, err := url.Parse(authorizationURL)
+	require.NoError(t, err)
+
+	query := parsed.Query()
+	assert.Equal(t, testOIDCClientID, query.Get("client_id"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Contains(t, query.Get("scope"), "openid")
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	code := "code-" + query.Get("state")[:8]
+	p.grants[code] = query
+	p.claims = claims
+	return code
+}
+
+func (p *mockOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+
+	p.mu.Lock()
+	grant, ok := p.grants[r.PostForm.Get("code")]
+	delete(p.grants, r.PostForm.Get("code"))
+	claims := p.claims
+	p.mu.Unlock()
+
+	verifierHash := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	challenge := base64.RawURLEncoding.EncodeToString(verifierHash[:])
+	if !ok || grant.Get("code_challenge") != challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	idClaims := gojwt.MapClaims{
+		"iss":   p.server.URL,
+		"aud":   testOIDCClientID,
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nonce": grant.Get("nonce"),
+	}
+	for key, value := range claims {
+		idClaims[key] = value
+	}
+
+	token := gojwt.NewWithClaims(gojwt.SigningMethodRS256, idClaims)
+	token.Header["kid"] = "test-key"
+	idToken, _ := token.SignedString(p.key)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func setupFederationService(t *testing.T) (*federationService, *mockOIDCProvider, *MockFederationRepository, *MockAccountRepository, *MockAccountService) {
+	provider := newMockOIDCProvider(t)
+	mockFederationRepo := &MockFederationRepository{}
+	mockAccountRepo := &MockAccountRepository{}
+	mockAccountService := &MockAccountService{}
+
+	service := newFederationService(mockFederationRepo, mockAccountRepo, mockAccountService, []OIDCProviderConfig{{
+		Name:         "acme",
+		DisplayName:  "Acme SSO",
+		IssuerURL:    provider.server.URL,
+		ClientID:     testOIDCClientID,
+		ClientSecret: "secret",
+		RedirectURL:  testOIDCRedirectURL,
+	}})
+
+	return service, provider, mockFederationRepo, mockAccountRepo, mockAccountService
+}
+
+// beginFederatedLogin runs BeginLogin and wires the stored state back through
+// ConsumeAuthState, mimicking the Mongo round trip.
+func beginFederatedLogin(t *testing.T, service *federationService, mockFederationRepo *MockFederationRepository) (*OIDCAuthorizationResponse, *OIDCAuthState) {
+	stored := &OIDCAuthState{}
+	mockFederationRepo.On("CreateAuthState", mock.Anything, mock.AnythingOfType("*account.OIDCAuthState")).
+		Run(func(args mock.Arguments) {
+			*stored = *args.Get(1).(*OIDCAuthState)
+		}).
+		Return(stored, nil).Once()
+
+	response, err := service.BeginLogin(context.Background(), "acme")
+	require.NoError(t, err)
+	assert.Equal(t, stored.State, response.State)
+	assert.NotEmpty(t, stored.Nonce)
+	assert.NotEmpty(t, stored.CodeVerifier)
+
+	mockFederationRepo.On("ConsumeAuthState", mock.Anything, stored.State).Return(stored, nil).Once()
+
+	return response, stored
+}
+
+func TestFederationService_CompleteLogin(t *testing.T) {
+	existing := CreateTestAccount(func(a *Account) {
+		a.Email = "jane@acme.test"
+	})
+
+	tests := []struct {
+		name      string
+		claims    map[string]any
+		setupMock func(*MockFederationRepository, *MockAccountRepository, *MockAccountService)
+		wantErr   bool
+		errMsg    string
+	}{
+		{
+			name: "first login creates and links a new account",
+			claims: map[string]any{
+				"sub":            "acme-123",
+				"email":          "new@acme.test",
+				"email_verified": true,
+				"given_name":     "New",
+				"family_name":    "User",
+			},
+			setupMock: func(fedRepo *MockFederationRepository, accountRepo *MockAccountRepository, accountService *MockAccountService) {
+				created := CreateTestAccount(func(a *Account) {
+					a.Email = "new@acme.test"
+					a.Username = "new"
+				})
+				fedRepo.On("GetIdentity", mock.Anything, "acme", "acme-123").Return(nil, nil)
+				accountRepo.On("GetByEmail", mock.Anything, "new@acme.test").Return(nil, nil)
+				accountRepo.On("ExistsByUsername", mock.Anything, "new").Return(false, nil)
+				accountRepo.On("Create", mock.Anything, mock.MatchedBy(func(a *Account) bool {
+					return a.Email == "new@acme.test" && a.Username == "new" && a.FirstName == "New" && a.IsActive
+				})).Return(created, nil)
+				fedRepo.On("CreateIdentity", mock.Anything, mock.MatchedBy(func(i *FederatedIdentity) bool {
+					return i.AccountID == created.ID.Hex() && i.Provider == "acme" && i.Subject == "acme-123"
+				})).Return(&FederatedIdentity{}, nil)
+				accountService.On("IssueSession", mock.Anything, created.ID.Hex(), "Mozilla/5.0", "192.168.1.1").
+					Return(&LoginResponse{Token: "issued-token"}, nil)
+			},
+		},
+		{
+			name: "verified email links existing account",
+			claims: map[string]any{
+				"sub":            "acme-456",
+				"email":          "jane@acme.test",
+				"email_verified": true,
+			},
+			setupMock: func(fedRepo *MockFederationRepository, accountRepo *MockAccountRepository, accountService *MockAccountService) {
+				fedRepo.On("GetIdentity", mock.Anything, "acme", "acme-456").Return(nil, nil)
+				accountRepo.On("GetByEmail", mock.Anything, "jane@acme.test").Return(existing, nil)
+				fedRepo.On("CreateIdentity", mock.Anything, mock.MatchedBy(func(i *FederatedIdentity) bool {
+					return i.AccountID == existing.ID.Hex()
+				})).Return(&FederatedIdentity{}, nil)
+				accountService.On("IssueSession", mock.Anything, existing.ID.Hex(), "Mozilla/5.0", "192.168.1.1").
+					Return(&LoginResponse{Token: "issued-token"}, nil)
+			},
+		},
+		{
+			name: "unverified email does not link existing account",
+			claims: map[string]any{
+				"sub":            "acme-789",
+				"email":          "jane@acme.test",
+				"email_verified": false,
+			},
+			setupMock: func(fedRepo *MockFederationRepository, accountRepo *MockAccountRepository, accountService *MockAccountService) {
+				fedRepo.On("GetIdentity", mock.Anything, "acme", "acme-789").Return(nil, nil)
+				accountRepo.On("GetByEmail", mock.Anything, "jane@acme.test").Return(existing, nil)
+			},
+			wantErr: true,
+			errMsg:  "cannot link account",
+		},
+		{
+			name: "unverified email does not create an account",
+			claims: map[string]any{
+				"sub":            "acme-790",
+				"email":          "admin@acme.test",
+				"email_verified": false,
+			},
+			setupMock: func(fedRepo *MockFederationRepository, accountRepo *MockAccountRepository, accountService *MockAccountService) {
+				fedRepo.On("GetIdentity", mock.Anything, "acme", "acme-790").Return(nil, nil)
+				accountRepo.On("GetByEmail", mock.Anything, "admin@acme.test").Return(nil, nil)
+			},
+			wantErr: true,
+			errMsg:  "cannot create account",
+		},
+		{
+			name: "returning identity signs in linked account",
+			claims: map[string]any{
+				"sub":            "acme-456",
+				"email":          "jane@acme.test",
+				"email_verified": true,
+			},
+			setupMock: func(fedRepo *MockFederationRepository, accountRepo *MockAccountRepository, accountService *MockAccountService) {
+				identity := &FederatedIdentity{AccountID: existing.ID.Hex(), Provider: "acme", Subject: "acme-456"}
+				fedRepo.On("GetIdentity", mock.Anything, "acme", "acme-456").Return(identity, nil)
+				accountRepo.On("GetByID", mock.Anything, existing.ID).Return(existing, nil)
+				fedRepo.On("UpdateIdentityLastLogin", mock.Anything, identity.ID, "jane@acme.test", true).Return(nil)
+				accountService.On("IssueSession", mock.Anything, existing.ID.Hex(), "Mozilla/5.0", "192.168.1.1").
+					Return(&LoginResponse{Token: "issued-token"}, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, provider, mockFederationRepo, mockAccountRepo, mockAccountService := setupFederationService(t)
+			tt.setupMock(mockFederationRepo, mockAccountRepo, mockAccountService)
+
+			begin, _ := beginFederatedLogin(t, service, mockFederationRepo)
+			code := provider.authorize(t, begin.AuthorizationURL, tt.claims)
+
+			result, err := service.CompleteLogin(context.Background(), "acme", &OIDCCallbackRequest{
+				Code:  code,
+				State: begin.State,
+			}, "Mozilla/5.0", "192.168.1.1")
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				assert.Nil(t, result)
+				mockAccountService.AssertNotCalled(t, "IssueSession", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "issued-token", result.Token)
+			}
+
+			mockFederationRepo.AssertExpectations(t)
+			mockAccountRepo.AssertExpectations(t)
+			mockAccountService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFederationService_CompleteLogin_RejectsInvalidState(t *testing.T) {
+	t.Run("unknown state", func(t *testing.T) {
+		service, _, mockFederationRepo, _, _ := setupFederationService(t)
+		mockFederationRepo.On("ConsumeAuthState", mock.Anything, "forged").Return(nil, nil)
+
+		result, err := service.CompleteLogin(context.Background(), "acme", &OIDCCallbackRequest{
+			Code:  "code",
+			State: "forged",
+		}, "", "")
+
+		assert.Nil(t, result)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid authorization state")
+	})
+
+	t.Run("PKCE verifier mismatch", func(t *testing.T) {
+		service, provider, mockFederationRepo, _, _ := setupFederationService(t)
+
+		begin, stored := beginFederatedLogin(t, service, mockFederationRepo)
+		code := provider.authorize(t, begin.AuthorizationURL, map[string]any{"sub": "acme-123"})
+		stored.CodeVerifier = "a-different-verifier-that-does-not-match-the-challenge"
+
+		result, err := service.CompleteLogin(context.Background(), "acme", &OIDCCallbackRequest{
+			Code:  code,
+			State: begin.State,
+		}, "", "")
+
+		assert.Nil(t, result)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to exchange authorization code")
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		service, _, _, _, _ := setupFederationService(t)
+
+		_, err := service.BeginLogin(context.Background(), "missing")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/binding"
 )
 
 type AccountHandler struct {
@@ -29,11 +31,8 @@ func NewAccountHandler(service AccountService) *AccountHandler {
 // @Router /accounts [post]
 func (h *AccountHandler) CreateAccount(c *fiber.Ctx) error {
 	var req CreateAccountRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "Invalid request body",
-			"message": err.Error(),
-		})
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
 	}
 
 	account, err := h.service.CreateAccount(c.Context(), &req)
@@ -199,11 +198,8 @@ func (h *AccountHandler) UpdateAccount(c *fiber.Ctx) error {
 	}
 
 	var req UpdateAccountRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "Invalid request body",
-			"message": err.Error(),
-		})
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
 	}
 
 	account, err := h.service.UpdateAccount(c.Context(), id, &req)
@@ -229,7 +225,7 @@ func (h *AccountHandler) UpdateAccount(c *fiber.Ctx) error {
 
 // DeleteAccount godoc
 // @Summary Delete account
-// @Description Delete account by ID (requires authentication and ownership validation)
+// @Description Schedule the account for deletion. It can be restored by an administrator until it is purged after 30 days (requires authentication and ownership validation)
 // @Tags accounts
 // @Security BearerAuth
 // @Accept json
@@ -279,14 +275,12 @@ func (h *AccountHandler) DeleteAccount(c *fiber.Ctx) error {
 // @Failure 400 {object} map[string]interface{} "Bad request"
 // @Failure 401 {object} map[string]interface{} "Unauthorized - invalid credentials"
 // @Failure 403 {object} map[string]interface{} "Forbidden - account inactive"
+// @Failure 429 {object} map[string]interface{} "Too many requests"
 // @Router /accounts/login [post]
 func (h *AccountHandler) Login(c *fiber.Ctx) error {
 	var req LoginRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "Invalid request body",
-			"message": err.Error(),
-		})
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
 	}
 
 	userAgent := c.Get("User-Agent")
@@ -298,6 +292,7 @@ func (h *AccountHandler) Login(c *fiber.Ctx) error {
 		if strings.Contains(err.Error(), "inactive") {
 			statusCode = fiber.StatusForbidden
 		}
+		statusCode = throttledStatus(c, err, statusCode)
 		
 		return c.Status(statusCode).JSON(fiber.Map{
 			"error":   "Login failed",
@@ -361,16 +356,13 @@ func (h *AccountHandler) Logout(c *fiber.Ctx) error {
 // @Produce json
 // @Param request body RegisterRequest true "Registration details"
 // @Success 201 {object} map[string]interface{} "Account created successfully"
-// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 400 {object} map[string]interface{} "Bad request or password rejected by the password policy"
 // @Failure 409 {object} map[string]interface{} "Conflict - account already exists"
 // @Router /accounts/register [post]
 func (h *AccountHandler) Register(c *fiber.Ctx) error {
 	var req RegisterRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "Invalid request body",
-			"message": err.Error(),
-		})
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
 	}
 
 	response, err := h.service.Register(c.Context(), &req)
@@ -379,6 +371,7 @@ func (h *AccountHandler) Register(c *fiber.Ctx) error {
 		if strings.Contains(err.Error(), "already exists") {
 			statusCode = fiber.StatusConflict
 		}
+		statusCode = passwordPolicyStatus(err, statusCode)
 		
 		return c.Status(statusCode).JSON(fiber.Map{
 			"error":   "Registration failed",
@@ -402,14 +395,12 @@ func (h *AccountHandler) Register(c *fiber.Ctx) error {
 // @Success 200 {object} map[string]interface{} "Email verified successfully"
 // @Failure 400 {object} map[string]interface{} "Bad request"
 // @Failure 401 {object} map[string]interface{} "Unauthorized - invalid or expired OTP"
+// @Failure 429 {object} map[string]interface{} "Too many requests"
 // @Router /accounts/verify-email [post]
 func (h *AccountHandler) VerifyEmail(c *fiber.Ctx) error {
 	var req VerifyEmailRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "Invalid request body",
-			"message": err.Error(),
-		})
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
 	}
 
 	err := h.service.VerifyEmail(c.Context(), &req)
@@ -418,6 +409,7 @@ func (h *AccountHandler) VerifyEmail(c *fiber.Ctx) error {
 		if strings.Contains(err.Error(), "expired") || strings.Contains(err.Error(), "invalid") {
 			statusCode = fiber.StatusUnauthorized
 		}
+		statusCode = throttledStatus(c, err, statusCode)
 		
 		return c.Status(statusCode).JSON(fiber.Map{
 			"error":   "Email verification failed",
@@ -441,14 +433,12 @@ func (h *AccountHandler) VerifyEmail(c *fiber.Ctx) error {
 // @Failure 400 {object} map[string]interface{} "Bad request"
 // @Failure 404 {object} map[string]interface{} "Account not found"
 // @Failure 409 {object} map[string]interface{} "Email already verified"
+// @Failure 429 {object} map[string]interface{} "Too many requests"
 // @Router /accounts/resend-verification [post]
 func (h *AccountHandler) ResendEmailVerification(c *fiber.Ctx) error {
 	var req ResendVerificationRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "Invalid request body",
-			"message": err.Error(),
-		})
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
 	}
 
 	err := h.service.ResendEmailVerification(c.Context(), &req)
@@ -459,6 +449,7 @@ func (h *AccountHandler) ResendEmailVerification(c *fiber.Ctx) error {
 		} else if strings.Contains(err.Error(), "already verified") {
 			statusCode = fiber.StatusConflict
 		}
+		statusCode = throttledStatus(c, err, statusCode)
 		
 		return c.Status(statusCode).JSON(fiber.Map{
 			"error":   "Failed to resend verification",
@@ -481,19 +472,17 @@ func (h *AccountHandler) ResendEmailVerification(c *fiber.Ctx) error {
 // @Success 200 {object} map[string]interface{} "Password reset email sent"
 // @Failure 400 {object} map[string]interface{} "Bad request"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Failure 429 {object} map[string]interface{} "Too many requests"
 // @Router /accounts/forgot-password [post]
 func (h *AccountHandler) ForgotPassword(c *fiber.Ctx) error {
 	var req ForgotPasswordRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "Invalid request body",
-			"message": err.Error(),
-		})
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
 	}
 
 	err := h.service.ForgotPassword(c.Context(), &req)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(throttledStatus(c, err, fiber.StatusInternalServerError)).JSON(fiber.Map{
 			"error":   "Failed to send reset email",
 			"message": err.Error(),
 		})
@@ -512,16 +501,14 @@ func (h *AccountHandler) ForgotPassword(c *fiber.Ctx) error {
 // @Produce json
 // @Param request body ResetPasswordRequest true "Password reset details"
 // @Success 200 {object} map[string]interface{} "Password reset successfully"
-// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 400 {object} map[string]interface{} "Bad request or password rejected by the password policy"
 // @Failure 401 {object} map[string]interface{} "Unauthorized - invalid or expired OTP"
+// @Failure 429 {object} map[string]interface{} "Too many requests"
 // @Router /accounts/reset-password [post]
 func (h *AccountHandler) ResetPassword(c *fiber.Ctx) error {
 	var req ResetPasswordRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "Invalid request body",
-			"message": err.Error(),
-		})
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
 	}
 
 	err := h.service.ResetPassword(c.Context(), &req)
@@ -530,7 +517,8 @@ func (h *AccountHandler) ResetPassword(c *fiber.Ctx) error {
 		if strings.Contains(err.Error(), "expired") || strings.Contains(err.Error(), "invalid") {
 			statusCode = fiber.StatusUnauthorized
 		}
-		
+		statusCode = throttledStatus(c, err, statusCode)
+
 		return c.Status(statusCode).JSON(fiber.Map{
 			"error":   "Password reset failed",
 			"message": err.Error(),
@@ -551,17 +539,14 @@ func (h *AccountHandler) ResetPassword(c *fiber.Ctx) error {
 // @Produce json
 // @Param request body ChangePasswordRequest true "Change password details"
 // @Success 200 {object} map[string]interface{} "Password changed successfully"
-// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 400 {object} map[string]interface{} "Bad request or password rejected by the password policy"
 // @Failure 401 {object} map[string]interface{} "Unauthorized - incorrect password"
 // @Failure 403 {object} map[string]interface{} "Forbidden - account inactive"
 // @Router /accounts/change-password [post]
 func (h *AccountHandler) ChangePassword(c *fiber.Ctx) error {
 	var req ChangePasswordRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "Invalid request body",
-			"message": err.Error(),
-		})
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
 	}
 
 	accountID := c.Locals("account_id").(string)
@@ -580,6 +565,7 @@ func (h *AccountHandler) ChangePassword(c *fiber.Ctx) error {
 		} else if strings.Contains(err.Error(), "inactive") {
 			statusCode = fiber.StatusForbidden
 		}
+		statusCode = passwordPolicyStatus(err, statusCode)
 		
 		return c.Status(statusCode).JSON(fiber.Map{
 			"error":   "Password change failed",
@@ -0,0 +1,90 @@
+package account
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type AccountStatus string
+
+const (
+	// AccountStatusPending accounts have registered but not verified their email.
+	AccountStatusPending   AccountStatus = "pending"
+	AccountStatusActive    AccountStatus = "active"
+	AccountStatusSuspended AccountStatus = "suspended"
+	// AccountStatusDeleted accounts are kept for AccountDeletionGracePeriod
+	// and can be restored until they are purged.
+	AccountStatusDeleted AccountStatus = "deleted"
+)
+
+const (
+	AccountDeletionGracePeriod = 30 * 24 * time.Hour
+	AccountPurgeInterval       = time.Hour
+	AccountPurgeBatchSize      = 100
+)
+
+// OTPPurposes lists every purpose so that purging an account can remove all
+// of its outstanding codes.
+var OTPPurposes = []OTPPurpose{
+	OTPPurposeEmailVerification,
+	OTPPurposePasswordReset,
+	OTPPurposeAccountUnlock,
+	OTPPurposeEmailChange,
+	OTPPurposeMagicLogin,
+}
+
+// CurrentStatus falls back to is_active for accounts stored before the
+// status field existed.
+func (a *Account) CurrentStatus() AccountStatus {
+	if a.Status != "" {
+		return a.Status
+	}
+
+	if a.IsActive {
+		return AccountStatusActive
+	}
+	return AccountStatusPending
+}
+
+// IsEmailVerified treats accounts activated before email_verified_at existed
+// as verified, since activation used to require verification.
+func (a *Account) IsEmailVerified() bool {
+	return a.EmailVerifiedAt != nil || (a.Status == "" && a.IsActive)
+}
+
+// PurgeAt is when a deleted account becomes eligible for purging.
+func (a *Account) PurgeAt() *time.Time {
+	if a.DeletedAt == nil {
+		return nil
+	}
+
+	purgeAt := a.DeletedAt.Add(AccountDeletionGracePeriod)
+	return &purgeAt
+}
+
+// inactiveError explains why an account that is not active cannot sign in.
+// Every message contains "inactive" so that handlers map it to 403.
+func (a *Account) inactiveError() error {
+	switch a.CurrentStatus() {
+	case AccountStatusPending:
+		return fmt.Errorf("account is inactive: email address is not verified")
+	case AccountStatusSuspended:
+		return fmt.Errorf("account is inactive: account is suspended")
+	case AccountStatusDeleted:
+		return fmt.Errorf("account is inactive: account is scheduled for deletion")
+	default:
+		return fmt.Errorf("account is inactive")
+	}
+}
+
+// statusUpdate keeps is_active in step with status, since existing checks
+// and indexes still read is_active.
+func statusUpdate(status AccountStatus) bson.M {
+	return bson.M{
+		"status":     status,
+		"is_active":  status == AccountStatusActive,
+		"updated_at": time.Now(),
+	}
+}
@@ -0,0 +1,81 @@
+package account
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+// accountOwnedCollectionNames hold documents keyed by the owner's account_id.
+var accountOwnedCollectionNames = []string{
+	SessionCollectionName,
+	APIKeyCollectionName,
+	FederatedIdentityCollectionName,
+	OAuthAuthorizationCodeCollectionName,
+	OAuthConsentCollectionName,
+	OAuthRefreshTokenCollectionName,
+	PasskeyCollectionName,
+	PasskeyCeremonyCollectionName,
+	EmailChangeCollectionName,
+	MagicLinkCollectionName,
+	KnownDeviceCollectionName,
+}
+
+// AccountDataRepository removes everything stored for an account outside the
+// accounts collection.
+type AccountDataRepository interface {
+	DeleteAccountData(ctx context.Context, account *Account) (int64, error)
+}
+
+type accountDataRepository struct {
+	ownedRepos map[string]mongo.Repository[bson.M]
+	clientRepo mongo.Repository[bson.M]
+	otpRepo    mongo.Repository[bson.M]
+}
+
+var _ AccountDataRepository = (*accountDataRepository)(nil)
+
+func NewAccountDataRepository(mongoService *mongo.MongoService) AccountDataRepository {
+	ownedRepos := make(map[string]mongo.Repository[bson.M], len(accountOwnedCollectionNames))
+	for _, name := range accountOwnedCollectionNames {
+		ownedRepos[name] = mongo.NewRepository[bson.M](mongoService, name)
+	}
+
+	return &accountDataRepository{
+		ownedRepos: ownedRepos,
+		clientRepo: mongo.NewRepository[bson.M](mongoService, OAuthClientCollectionName),
+		otpRepo:    mongo.NewRepository[bson.M](mongoService, OTPCollectionName),
+	}
+}
+
+// DeleteAccountData returns how many documents were removed. It stops at the
+// first failure; calling it again finishes the job.
+func (r *accountDataRepository) DeleteAccountData(ctx context.Context, account *Account) (int64, error) {
+	accountID := account.ID.Hex()
+
+	var total int64
+	for name, repo := range r.ownedRepos {
+		deleted, err := repo.DeleteMany(ctx, bson.M{"account_id": accountID})
+		if err != nil {
+			return total, fmt.Errorf("failed to delete %s: %w", name, err)
+		}
+		total += deleted
+	}
+
+	deleted, err := r.clientRepo.DeleteMany(ctx, bson.M{"owner_account_id": accountID})
+	if err != nil {
+		return total, fmt.Errorf("failed to delete %s: %w", OAuthClientCollectionName, err)
+	}
+	total += deleted
+
+	deleted, err = r.otpRepo.DeleteMany(ctx, bson.M{"email": account.Email})
+	if err != nil {
+		return total, fmt.Errorf("failed to delete %s: %w", OTPCollectionName, err)
+	}
+	total += deleted
+
+	return total, nil
+}
@@ -0,0 +1,197 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/redis"
+)
+
+// AccountPurgeHook removes data another module keeps for an account that is
+// being purged. A hook that fails leaves the account in place so that the
+// next run retries every hook, so hooks must be safe to run more than once.
+type AccountPurgeHook func(ctx context.Context, account *Account) error
+
+// AccountPurger permanently removes accounts whose deletion grace period has
+// ended. It is registered as "account_purger" so that other modules can add
+// hooks for their own data with OnPurge.
+type AccountPurger interface {
+	OnPurge(hook AccountPurgeHook)
+	PurgeDeletedAccounts(ctx context.Context) (int, error)
+	Start()
+	Shutdown() error
+}
+
+type accountPurger struct {
+	accountRepository         AccountRepository
+	accountIdentityRepository AccountIdentityRepository
+	accountDataRepository     AccountDataRepository
+	auditService              AuditRecorder
+
+	mu    sync.RWMutex
+	hooks []AccountPurgeHook
+
+	interval time.Duration
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+func NewAccountPurger(mongoService *mongo.MongoService, cacheService redis.RedisService, auditService AuditRecorder) AccountPurger {
+	purger := newAccountPurger(
+		NewAccountRepository(mongoService),
+		newAccountIdentityRepository(mongoService, cacheService),
+		NewAccountDataRepository(mongoService),
+	)
+	purger.auditService = auditService
+
+	return purger
+}
+
+func newAccountPurger(
+	accountRepository AccountRepository,
+	accountIdentityRepository AccountIdentityRepository,
+	accountDataRepository AccountDataRepository,
+) *accountPurger {
+	return &accountPurger{
+		accountRepository:         accountRepository,
+		accountIdentityRepository: accountIdentityRepository,
+		accountDataRepository:     accountDataRepository,
+		interval:                  AccountPurgeInterval,
+	}
+}
+
+func (p *accountPurger) OnPurge(hook AccountPurgeHook) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.hooks = append(p.hooks, hook)
+}
+
+// PurgeDeletedAccounts purges up to AccountPurgeBatchSize expired accounts
+// and returns how many were removed. Accounts that fail are logged and left
+// for the next run.
+func (p *accountPurger) PurgeDeletedAccounts(ctx context.Context) (int, error) {
+	filter := bson.M{
+		"status":     AccountStatusDeleted,
+		"deleted_at": bson.M{"$lte": time.Now().Add(-AccountDeletionGracePeriod)},
+	}
+
+	result, err := p.accountRepository.List(ctx, filter, mongo.PaginationOptions{
+		Page:  1,
+		Limit: AccountPurgeBatchSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list deleted accounts: %w", err)
+	}
+
+	purged := 0
+	for i := range result.Data {
+		account := &result.Data[i]
+		if err := p.purgeAccount(ctx, account); err != nil {
+			fmt.Printf("Failed to purge account %s: %v\n", account.ID.Hex(), err)
+			continue
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+// purgeAccount deletes the account document last, so that an interrupted
+// purge is found and finished by the next run.
+func (p *accountPurger) purgeAccount(ctx context.Context, account *Account) error {
+	if err := p.accountIdentityRepository.DeactivateAllUserSessions(ctx, account.ID.Hex()); err != nil {
+		return fmt.Errorf("failed to deactivate sessions: %w", err)
+	}
+
+	// Outstanding codes may only live in the cache; a missing code is not an
+	// error here.
+	for _, purpose := range OTPPurposes {
+		_ = p.accountIdentityRepository.DeleteOTP(ctx, account.Email, purpose)
+	}
+
+	p.mu.RLock()
+	hooks := append([]AccountPurgeHook(nil), p.hooks...)
+	p.mu.RUnlock()
+
+	for _, hook := range hooks {
+		if err := hook(ctx, account); err != nil {
+			return fmt.Errorf("purge hook failed: %w", err)
+		}
+	}
+
+	deleted, err := p.accountDataRepository.DeleteAccountData(ctx, account)
+	if err != nil {
+		return fmt.Errorf("failed to delete account data: %w", err)
+	}
+
+	if err := p.accountRepository.Delete(ctx, account.ID); err != nil {
+		return fmt.Errorf("failed to delete account: %w", err)
+	}
+
+	recordAudit(ctx, p.auditService, &AuditEvent{
+		Type:     AuditEventAccountPurge,
+		TargetID: account.ID.Hex(),
+		Metadata: map[string]string{"documents_deleted": strconv.FormatInt(deleted, 10)},
+	})
+
+	return nil
+}
+
+// Start runs PurgeDeletedAccounts every AccountPurgeInterval until Shutdown.
+func (p *accountPurger) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+
+	go p.run(ctx, p.done)
+}
+
+func (p *accountPurger) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := p.PurgeDeletedAccounts(ctx)
+			if err != nil {
+				fmt.Printf("Account purge failed: %v\n", err)
+			} else if purged > 0 {
+				fmt.Printf("Purged %d deleted accounts\n", purged)
+			}
+		}
+	}
+}
+
+func (p *accountPurger) Shutdown() error {
+	p.mu.Lock()
+	cancel, done := p.cancel, p.done
+	p.cancel, p.done = nil, nil
+	p.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	<-done
+	return nil
+}
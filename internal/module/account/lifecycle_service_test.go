@@ -0,0 +1,247 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+func TestAccount_CurrentStatus(t *testing.T) {
+	assert.Equal(t, AccountStatusActive, CreateTestAccount().CurrentStatus(), "legacy active accounts")
+	assert.Equal(t, AccountStatusPending, CreateTestAccount(func(a *Account) { a.IsActive = false }).CurrentStatus(), "legacy inactive accounts")
+	assert.Equal(t, AccountStatusSuspended, CreateTestAccount(func(a *Account) {
+		a.Status = AccountStatusSuspended
+		a.IsActive = false
+	}).CurrentStatus())
+
+	assert.True(t, CreateTestAccount().IsEmailVerified(), "legacy active accounts were verified to activate")
+	assert.False(t, CreateTestAccount(func(a *Account) { a.Status = AccountStatusSuspended }).IsEmailVerified())
+}
+
+func TestAccountService_Login_ExplainsInactiveStatus(t *testing.T) {
+	service, mockAccountRepo, _, _ := setupAccountService()
+	account := CreateTestAccount(func(a *Account) {
+		a.Status = AccountStatusSuspended
+		a.IsActive = false
+	})
+
+	mockAccountRepo.On("GetByEmail", mock.Anything, account.Email).Return(account, nil)
+
+	_, err := service.Login(context.Background(), CreateTestLoginRequest(), "agent", "127.0.0.1")
+	assert.EqualError(t, err, "account is inactive: account is suspended")
+}
+
+func TestAccountService_VerifyEmail_KeepsSuspendedAccountSuspended(t *testing.T) {
+	service, mockAccountRepo, mockIdentityRepo, _ := setupAccountService()
+	account := CreateTestAccount(func(a *Account) {
+		a.Status = AccountStatusSuspended
+		a.IsActive = false
+	})
+	request := &VerifyEmailRequest{Email: account.Email, OTP: "123456"}
+
+	mockIdentityRepo.On("ValidateOTP", mock.Anything, account.Email, OTPPurposeEmailVerification, "123456").Return(CreateTestOTP(), nil)
+	mockIdentityRepo.On("DeleteOTP", mock.Anything, account.Email, OTPPurposeEmailVerification).Return(nil)
+	mockAccountRepo.On("GetByEmail", mock.Anything, account.Email).Return(account, nil)
+	mockAccountRepo.On("Update", mock.Anything, account.ID, mock.MatchedBy(func(update bson.M) bool {
+		_, verified := update["email_verified_at"].(time.Time)
+		_, changesStatus := update["status"]
+		return verified && !changesStatus
+	})).Return(account, nil).Once()
+
+	require.NoError(t, service.VerifyEmail(context.Background(), request))
+	mockAccountRepo.AssertExpectations(t)
+}
+
+func TestAccountService_VerifyEmail_ActivatesPendingAccount(t *testing.T) {
+	service, mockAccountRepo, mockIdentityRepo, _ := setupAccountService()
+	account := CreateTestAccount(func(a *Account) {
+		a.Status = AccountStatusPending
+		a.IsActive = false
+	})
+	request := &VerifyEmailRequest{Email: account.Email, OTP: "123456"}
+
+	mockIdentityRepo.On("ValidateOTP", mock.Anything, account.Email, OTPPurposeEmailVerification, "123456").Return(CreateTestOTP(), nil)
+	mockIdentityRepo.On("DeleteOTP", mock.Anything, account.Email, OTPPurposeEmailVerification).Return(nil)
+	mockAccountRepo.On("GetByEmail", mock.Anything, account.Email).Return(account, nil)
+	mockAccountRepo.On("Update", mock.Anything, account.ID, mock.MatchedBy(func(update bson.M) bool {
+		return update["status"] == AccountStatusActive && update["is_active"] == true
+	})).Return(account, nil).Once()
+
+	require.NoError(t, service.VerifyEmail(context.Background(), request))
+	mockAccountRepo.AssertExpectations(t)
+}
+
+func TestAccountService_ResendEmailVerification_AllowsSuspendedUnverifiedAccount(t *testing.T) {
+	service, mockAccountRepo, mockIdentityRepo, _ := setupAccountService()
+	account := CreateTestAccount(func(a *Account) {
+		a.Status = AccountStatusSuspended
+		a.IsActive = false
+	})
+
+	mockAccountRepo.On("GetByEmail", mock.Anything, account.Email).Return(account, nil)
+	mockIdentityRepo.On("CreateOTP", mock.Anything, account.Email, OTPPurposeEmailVerification).Return(CreateTestOTP(), nil)
+
+	err := service.ResendEmailVerification(context.Background(), &ResendVerificationRequest{Email: account.Email})
+	require.NoError(t, err)
+
+	verifiedAt := time.Now()
+	account.EmailVerifiedAt = &verifiedAt
+	err = service.ResendEmailVerification(context.Background(), &ResendVerificationRequest{Email: account.Email})
+	assert.EqualError(t, err, "email is already verified")
+}
+
+func TestAccountPurger_PurgeDeletedAccounts(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	mockIdentityRepo := &MockAccountIdentityRepository{}
+	mockDataRepo := &MockAccountDataRepository{}
+	purger := newAccountPurger(mockAccountRepo, mockIdentityRepo, mockDataRepo)
+
+	deletedAt := time.Now().Add(-AccountDeletionGracePeriod - time.Hour)
+	purged := *CreateTestAccount(func(a *Account) {
+		a.Status = AccountStatusDeleted
+		a.DeletedAt = &deletedAt
+	})
+	failing := *CreateTestAccount(func(a *Account) {
+		a.Email = "failing@example.com"
+		a.Status = AccountStatusDeleted
+		a.DeletedAt = &deletedAt
+	})
+
+	mockAccountRepo.On("List", mock.Anything, mock.MatchedBy(func(filter bson.M) bool {
+		return filter["status"] == AccountStatusDeleted
+	}), mock.Anything).Return(&mongo.PaginatedResult[Account]{Data: []Account{purged, failing}}, nil)
+	mockIdentityRepo.On("DeactivateAllUserSessions", mock.Anything, mock.Anything).Return(nil)
+	mockIdentityRepo.On("DeleteOTP", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("not found"))
+	mockDataRepo.On("DeleteAccountData", mock.Anything, mock.Anything).Return(int64(3), nil)
+	mockAccountRepo.On("Delete", mock.Anything, purged.ID).Return(nil).Once()
+
+	var hooked []string
+	purger.OnPurge(func(ctx context.Context, account *Account) error {
+		hooked = append(hooked, account.Email)
+		if account.Email == failing.Email {
+			return errors.New("graph unavailable")
+		}
+		return nil
+	})
+
+	count, err := purger.PurgeDeletedAccounts(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []string{purged.Email, failing.Email}, hooked)
+
+	mockAccountRepo.AssertExpectations(t)
+	mockAccountRepo.AssertNotCalled(t, "Delete", mock.Anything, failing.ID)
+	mockIdentityRepo.AssertNumberOfCalls(t, "DeleteOTP", 2*len(OTPPurposes))
+	mockDataRepo.AssertNumberOfCalls(t, "DeleteAccountData", 1)
+}
+
+func TestAccountPurger_StartAndShutdown(t *testing.T) {
+	mockAccountRepo := &MockAccountRepository{}
+	purger := newAccountPurger(mockAccountRepo, &MockAccountIdentityRepository{}, &MockAccountDataRepository{})
+	purger.interval = 10 * time.Millisecond
+
+	listed := make(chan struct{}, 1)
+	mockAccountRepo.On("List", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) {
+			select {
+			case listed <- struct{}{}:
+			default:
+			}
+		}).
+		Return(&mongo.PaginatedResult[Account]{}, nil)
+
+	purger.Start()
+	select {
+	case <-listed:
+	case <-time.After(time.Second):
+		t.Fatal("purger did not run")
+	}
+
+	require.NoError(t, purger.Shutdown())
+	require.NoError(t, purger.Shutdown(), "shutting down twice is a no-op")
+}
+
+func TestAdminService_RestoreAccount(t *testing.T) {
+	recent := time.Now().Add(-time.Hour)
+	expired := time.Now().Add(-AccountDeletionGracePeriod - time.Hour)
+
+	tests := []struct {
+		name           string
+		account        *Account
+		expectedStatus AccountStatus
+		expectedError  string
+	}{
+		{
+			name: "verified account becomes active",
+			account: CreateTestAccount(func(a *Account) {
+				a.Status = AccountStatusDeleted
+				a.IsActive = false
+				a.EmailVerifiedAt = &recent
+				a.DeletedAt = &recent
+			}),
+			expectedStatus: AccountStatusActive,
+		},
+		{
+			name: "unverified account returns to pending",
+			account: CreateTestAccount(func(a *Account) {
+				a.Status = AccountStatusDeleted
+				a.IsActive = false
+				a.DeletedAt = &recent
+			}),
+			expectedStatus: AccountStatusPending,
+		},
+		{
+			name: "grace period has ended",
+			account: CreateTestAccount(func(a *Account) {
+				a.Status = AccountStatusDeleted
+				a.DeletedAt = &expired
+			}),
+			expectedError: "grace period has ended",
+		},
+		{
+			name:          "account is not deleted",
+			account:       CreateTestAccount(),
+			expectedError: "cannot restore",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockAccountRepo, _, _, _ := setupAdminService(t)
+			auditRepo := &memoryAuditRepository{}
+			service.auditService = newAuditService(auditRepo, 0)
+			actorID := primitive.NewObjectID().Hex()
+
+			mockAccountRepo.On("GetByID", mock.Anything, tt.account.ID).Return(tt.account, nil)
+			if tt.expectedError == "" {
+				mockAccountRepo.On("Update", mock.Anything, tt.account.ID, mock.MatchedBy(func(update bson.M) bool {
+					deletedAt, cleared := update["deleted_at"]
+					return cleared && deletedAt == nil && update["status"] == tt.expectedStatus
+				})).Return(tt.account, nil).Once()
+			}
+
+			_, err := service.RestoreAccount(context.Background(), actorID, tt.account.ID.Hex())
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				assert.Empty(t, auditRepo.events)
+			} else {
+				assert.NoError(t, err)
+				require.Len(t, auditRepo.events, 1)
+				assert.Equal(t, AuditEventAccountRestore, auditRepo.events[0].Type)
+				assert.Equal(t, actorID, auditRepo.events[0].ActorID)
+				assert.Equal(t, tt.account.ID.Hex(), auditRepo.events[0].TargetID)
+			}
+
+			mockAccountRepo.AssertExpectations(t)
+		})
+	}
+}
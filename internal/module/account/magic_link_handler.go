@@ -0,0 +1,128 @@
+package account
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/binding"
+)
+
+type MagicLinkHandler struct {
+	service MagicLinkService
+}
+
+func NewMagicLinkHandler(service MagicLinkService) *MagicLinkHandler {
+	return &MagicLinkHandler{
+		service: service,
+	}
+}
+
+// RequestMagicLink godoc
+// @Summary Request a sign-in link
+// @Description Email a single-use sign-in link that only works on the requesting device. The device secret is returned and also set as a cookie. Always succeeds so that it does not reveal whether the account exists.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body RequestMagicLinkRequest true "Account email"
+// @Success 200 {object} map[string]interface{} "Sign-in link sent if the account exists"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 429 {object} map[string]interface{} "Too many requests"
+// @Router /accounts/login/magic [post]
+func (h *MagicLinkHandler) RequestMagicLink(c *fiber.Ctx) error {
+	var req RequestMagicLinkRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	response, err := h.service.RequestMagicLink(c.Context(), &req, c.Get("User-Agent"), c.IP())
+	if err != nil {
+		return c.Status(throttledStatus(c, err, fiber.StatusInternalServerError)).JSON(fiber.Map{
+			"error":   "Failed to send sign-in link",
+			"message": err.Error(),
+		})
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     MagicLinkDeviceCookie,
+		Value:    response.DeviceSecret,
+		Expires:  response.ExpiresAt,
+		HTTPOnly: true,
+		Secure:   c.Protocol() == "https",
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	return c.JSON(fiber.Map{
+		"message": "If the account exists, a sign-in link has been sent",
+		"data":    response,
+	})
+}
+
+// OpenMagicLink godoc
+// @Summary Open a sign-in link
+// @Description Redeem the emailed sign-in link in the browser that requested it and start a session
+// @Tags authentication
+// @Produce json
+// @Param token query string true "Sign-in link token"
+// @Success 200 {object} map[string]interface{} "Login successful"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized - invalid, used or expired link"
+// @Failure 429 {object} map[string]interface{} "Too many requests"
+// @Router /accounts/login/magic/verify [get]
+func (h *MagicLinkHandler) OpenMagicLink(c *fiber.Ctx) error {
+	req := VerifyMagicLinkRequest{Token: c.Query("token")}
+	return h.verify(c, &req)
+}
+
+// VerifyMagicLink godoc
+// @Summary Verify a sign-in link or code
+// @Description Redeem the sign-in link token, or the email and code, with the device secret returned when the link was requested, and start a session
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body VerifyMagicLinkRequest true "Link token or email and code, and device secret"
+// @Success 200 {object} map[string]interface{} "Login successful"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized - invalid, used or expired link"
+// @Failure 429 {object} map[string]interface{} "Too many requests"
+// @Router /accounts/login/magic/verify [post]
+func (h *MagicLinkHandler) VerifyMagicLink(c *fiber.Ctx) error {
+	var req VerifyMagicLinkRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	return h.verify(c, &req)
+}
+
+func (h *MagicLinkHandler) verify(c *fiber.Ctx, req *VerifyMagicLinkRequest) error {
+	if req.DeviceSecret == "" {
+		req.DeviceSecret = c.Cookies(MagicLinkDeviceCookie)
+	}
+
+	response, err := h.service.VerifyMagicLink(c.Context(), req, c.Get("User-Agent"), c.IP())
+	if err != nil {
+		statusCode := fiber.StatusInternalServerError
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "expired") ||
+			strings.Contains(err.Error(), "magic login failed") {
+			statusCode = fiber.StatusUnauthorized
+		} else if strings.Contains(err.Error(), "not found") {
+			statusCode = fiber.StatusNotFound
+		} else if strings.Contains(err.Error(), "inactive") {
+			statusCode = fiber.StatusForbidden
+		}
+		statusCode = throttledStatus(c, err, statusCode)
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"error":   "Magic link login failed",
+			"message": err.Error(),
+		})
+	}
+
+	c.ClearCookie(MagicLinkDeviceCookie)
+
+	return c.JSON(fiber.Map{
+		"message": "Login successful",
+		"data":    response,
+	})
+}
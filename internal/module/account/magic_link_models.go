@@ -0,0 +1,77 @@
+package account
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// MagicLinkExpiry matches OTPExpiry because the link and the optional
+	// code are sent in the same email.
+	MagicLinkExpiry = OTPExpiry
+
+	// MagicLinkDeviceCookie carries the device secret that binds a link to
+	// the browser that requested it.
+	MagicLinkDeviceCookie = "magic_login_device"
+
+	magicLinkTokenPurpose = "magic_login"
+)
+
+type MagicLinkConfig struct {
+	// URL is where the emailed link points; the token is added as the token
+	// query parameter.
+	URL string `json:"url"`
+	// IncludeCode also emails a one-time code that can be typed into the
+	// requesting device instead of opening the link.
+	IncludeCode bool `json:"include_code"`
+}
+
+// MagicLink is a single-use sign-in link. It is only redeemable by the device
+// holding the secret whose hash is DeviceHash.
+type MagicLink struct {
+	ID                 primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	AccountID          string             `json:"account_id" bson:"account_id"`
+	Email              string             `json:"email" bson:"email"`
+	DeviceHash         string             `json:"-" bson:"device_hash"`
+	RequestedIPAddress string             `json:"requested_ip_address" bson:"requested_ip_address"`
+	RequestedUserAgent string             `json:"requested_user_agent" bson:"requested_user_agent"`
+	UsedIPAddress      string             `json:"used_ip_address,omitempty" bson:"used_ip_address,omitempty"`
+	ExpiresAt          time.Time          `json:"expires_at" bson:"expires_at"`
+	UsedAt             *time.Time         `json:"used_at,omitempty" bson:"used_at,omitempty"`
+	CreatedAt          time.Time          `json:"created_at" bson:"created_at"`
+}
+
+type RequestMagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type RequestMagicLinkResponse struct {
+	// DeviceSecret must accompany the token or code when the link is
+	// redeemed. Browsers also receive it as the MagicLinkDeviceCookie cookie.
+	DeviceSecret string    `json:"device_secret"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// VerifyMagicLinkRequest redeems either the emailed link token, or the email
+// address and code.
+type VerifyMagicLinkRequest struct {
+	Token        string `json:"token" query:"token"`
+	Email        string `json:"email"`
+	OTP          string `json:"otp"`
+	DeviceSecret string `json:"device_secret"`
+}
+
+func GetMagicLinkTemplate(link, otp string) EmailTemplate {
+	codeHtml, codeText := "", ""
+	if otp != "" {
+		codeHtml = "<p>Or enter this code on the device where you asked to sign in: <strong>" + otp + "</strong></p>"
+		codeText = " Or enter this code on the device where you asked to sign in: " + otp + "."
+	}
+
+	return EmailTemplate{
+		Subject:  "Your Sign-In Link",
+		HtmlBody: "<h1>Sign In</h1><p><a href=\"" + link + "\">Click here to sign in</a>. The link only works once, on the device where you asked to sign in.</p>" + codeHtml + "<p>This link will expire in 5 minutes. If you did not ask to sign in, you can ignore this email.</p>",
+		TextBody: "Sign In - Open this link to sign in: " + link + ". The link only works once, on the device where you asked to sign in." + codeText + " This link will expire in 5 minutes. If you did not ask to sign in, you can ignore this email.",
+	}
+}
@@ -0,0 +1,85 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+const MagicLinkCollectionName = "magic_links"
+
+type MagicLinkRepository interface {
+	CreateMagicLink(ctx context.Context, link *MagicLink) (*MagicLink, error)
+	ConsumeMagicLink(ctx context.Context, id primitive.ObjectID, deviceHash, ipAddress string) (*MagicLink, error)
+	ConsumeMagicLinkByEmail(ctx context.Context, email, deviceHash, ipAddress string) (*MagicLink, error)
+}
+
+type magicLinkRepository struct {
+	repo mongo.Repository[MagicLink]
+}
+
+var _ MagicLinkRepository = (*magicLinkRepository)(nil)
+
+func NewMagicLinkRepository(mongoService *mongo.MongoService) MagicLinkRepository {
+	return &magicLinkRepository{
+		repo: mongo.NewRepository[MagicLink](mongoService, MagicLinkCollectionName),
+	}
+}
+
+// CreateMagicLink invalidates the account's unused links, so at most one link
+// per account can be redeemed at a time.
+func (r *magicLinkRepository) CreateMagicLink(ctx context.Context, link *MagicLink) (*MagicLink, error) {
+	_, err := r.repo.DeleteMany(ctx, bson.M{
+		"account_id": link.AccountID,
+		"used_at":    nil,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to replace magic link: %w", err)
+	}
+
+	link.ID = primitive.NewObjectID()
+	link.CreatedAt = time.Now()
+
+	result, err := r.repo.Create(ctx, *link)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create magic link: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *magicLinkRepository) ConsumeMagicLink(ctx context.Context, id primitive.ObjectID, deviceHash, ipAddress string) (*MagicLink, error) {
+	return r.consume(ctx, bson.M{"_id": id}, deviceHash, ipAddress)
+}
+
+func (r *magicLinkRepository) ConsumeMagicLinkByEmail(ctx context.Context, email, deviceHash, ipAddress string) (*MagicLink, error) {
+	return r.consume(ctx, bson.M{"email": email}, deviceHash, ipAddress)
+}
+
+// consume marks a matching link as used in a single update, so that two
+// concurrent attempts cannot both redeem it. It returns nil when no unused,
+// unexpired link for this device matches.
+func (r *magicLinkRepository) consume(ctx context.Context, filter bson.M, deviceHash, ipAddress string) (*MagicLink, error) {
+	filter["device_hash"] = deviceHash
+	filter["used_at"] = nil
+	filter["expires_at"] = bson.M{"$gt": time.Now()}
+
+	update := bson.M{
+		"$set": bson.M{
+			"used_at":         time.Now(),
+			"used_ip_address": ipAddress,
+		},
+	}
+
+	result, err := r.repo.Update(ctx, filter, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume magic link: %w", err)
+	}
+
+	return result, nil
+}
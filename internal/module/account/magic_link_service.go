@@ -0,0 +1,237 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/jwt"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/ratelimit"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/redis"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/resend"
+)
+
+type MagicLinkService interface {
+	RequestMagicLink(ctx context.Context, req *RequestMagicLinkRequest, userAgent, ipAddress string) (*RequestMagicLinkResponse, error)
+	VerifyMagicLink(ctx context.Context, req *VerifyMagicLinkRequest, userAgent, ipAddress string) (*LoginResponse, error)
+}
+
+type magicLinkService struct {
+	repository                MagicLinkRepository
+	accountRepository         AccountRepository
+	accountIdentityRepository AccountIdentityRepository
+	accountService            AccountService
+	jwtService                *jwt.JWTService
+	resendService             resend.ResendService
+	fromEmail                 string
+	limiter                   ratelimit.Limiter
+	config                    MagicLinkConfig
+}
+
+func NewMagicLinkService(
+	mongoService *mongo.MongoService,
+	cacheService redis.RedisService,
+	accountService AccountService,
+	jwtService *jwt.JWTService,
+	resendService resend.ResendService,
+	fromEmail string,
+	limiter ratelimit.Limiter,
+	config MagicLinkConfig,
+) (MagicLinkService, error) {
+	if _, err := url.Parse(config.URL); err != nil || config.URL == "" {
+		return nil, fmt.Errorf("invalid magic link URL %q", config.URL)
+	}
+
+	return &magicLinkService{
+		repository:                NewMagicLinkRepository(mongoService),
+		accountRepository:         NewAccountRepository(mongoService),
+		accountIdentityRepository: newAccountIdentityRepository(mongoService, cacheService),
+		accountService:            accountService,
+		jwtService:                jwtService,
+		resendService:             resendService,
+		fromEmail:                 fromEmail,
+		limiter:                   limiter,
+		config:                    config,
+	}, nil
+}
+
+// RequestMagicLink always returns a device secret, whether or not the email
+// belongs to an account that can sign in, so that the response does not
+// reveal which addresses are registered.
+func (s *magicLinkService) RequestMagicLink(ctx context.Context, req *RequestMagicLinkRequest, userAgent, ipAddress string) (*RequestMagicLinkResponse, error) {
+	if err := checkRateLimit(ctx, s.limiter, rateLimitKey("otp", "request", req.Email), OTPRequestRateLimit, "too many codes requested for this email"); err != nil {
+		return nil, err
+	}
+
+	deviceSecret, err := generateRandomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate device secret: %w", err)
+	}
+
+	response := &RequestMagicLinkResponse{
+		DeviceSecret: deviceSecret,
+		ExpiresAt:    time.Now().Add(MagicLinkExpiry),
+	}
+
+	account, err := s.accountRepository.GetByEmail(ctx, req.Email)
+	if err != nil || account == nil || !account.IsActive || account.IsLocked() {
+		return response, nil
+	}
+
+	link, err := s.repository.CreateMagicLink(ctx, &MagicLink{
+		AccountID:          account.ID.Hex(),
+		Email:              account.Email,
+		DeviceHash:         hashSecret(deviceSecret),
+		RequestedIPAddress: ipAddress,
+		RequestedUserAgent: userAgent,
+		ExpiresAt:          response.ExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.jwtService.GenerateWithDuration(map[string]any{
+		"purpose":       magicLinkTokenPurpose,
+		"magic_link_id": link.ID.Hex(),
+	}, MagicLinkExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign magic link: %w", err)
+	}
+
+	var code string
+	if s.config.IncludeCode {
+		otp, err := s.accountIdentityRepository.CreateOTP(ctx, account.Email, OTPPurposeMagicLogin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create magic login OTP: %w", err)
+		}
+		code = otp.Code
+	}
+
+	if s.resendService != nil {
+		template := GetMagicLinkTemplate(s.linkURL(token), code)
+		_, err = s.resendService.SendEmail(ctx, &resend.EmailRequest{
+			From:    s.fromEmail,
+			To:      []string{account.Email},
+			Subject: template.Subject,
+			Html:    template.HtmlBody,
+			Text:    template.TextBody,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to send magic link: %w", err)
+		}
+	}
+
+	return response, nil
+}
+
+// VerifyMagicLink redeems the link, or its code, on the device that requested
+// it and starts a session. Either way the link cannot be used again.
+func (s *magicLinkService) VerifyMagicLink(ctx context.Context, req *VerifyMagicLinkRequest, userAgent, ipAddress string) (*LoginResponse, error) {
+	if req.DeviceSecret == "" {
+		return nil, fmt.Errorf("invalid magic link: it must be opened on the device that requested it")
+	}
+	deviceHash := hashSecret(req.DeviceSecret)
+
+	var link *MagicLink
+	var err error
+
+	switch {
+	case req.Token != "":
+		link, err = s.consumeToken(ctx, req.Token, deviceHash, ipAddress)
+	case req.Email != "" && req.OTP != "":
+		link, err = s.consumeCode(ctx, req.Email, req.OTP, deviceHash, ipAddress)
+	default:
+		return nil, fmt.Errorf("invalid magic link: token or email and code are required")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if s.config.IncludeCode {
+		if err := s.accountIdentityRepository.DeleteOTP(ctx, link.Email, OTPPurposeMagicLogin); err != nil {
+			fmt.Printf("Failed to delete OTP: %v\n", err)
+		}
+	}
+
+	accountID, err := primitive.ObjectIDFromHex(link.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account ID format: %w", err)
+	}
+
+	account, err := s.accountRepository.GetByID(ctx, accountID)
+	if err != nil || account == nil {
+		return nil, fmt.Errorf("account not found")
+	}
+
+	if account.IsLocked() {
+		return nil, lockedError(account)
+	}
+
+	return s.accountService.IssueSession(ctx, link.AccountID, userAgent, ipAddress)
+}
+
+func (s *magicLinkService) consumeToken(ctx context.Context, token, deviceHash, ipAddress string) (*MagicLink, error) {
+	claims, err := s.jwtService.Verify(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired magic link")
+	}
+
+	purpose, _ := claims.GetCustomClaim("purpose")
+	linkIDClaim, _ := claims.GetCustomClaim("magic_link_id")
+	linkIDHex, _ := linkIDClaim.(string)
+	if purpose != magicLinkTokenPurpose || linkIDHex == "" {
+		return nil, fmt.Errorf("invalid or expired magic link")
+	}
+
+	linkID, err := primitive.ObjectIDFromHex(linkIDHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired magic link")
+	}
+
+	link, err := s.repository.ConsumeMagicLink(ctx, linkID, deviceHash, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return nil, fmt.Errorf("invalid or expired magic link")
+	}
+
+	return link, nil
+}
+
+func (s *magicLinkService) consumeCode(ctx context.Context, email, code, deviceHash, ipAddress string) (*MagicLink, error) {
+	if !s.config.IncludeCode {
+		return nil, fmt.Errorf("invalid magic link: sign-in codes are not enabled")
+	}
+
+	if err := checkRateLimit(ctx, s.limiter, rateLimitKey("otp", "verify", email), OTPVerifyRateLimit, "too many verification attempts for this email"); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.accountIdentityRepository.ValidateOTP(ctx, email, OTPPurposeMagicLogin, code); err != nil {
+		return nil, fmt.Errorf("magic login failed: %w", err)
+	}
+
+	link, err := s.repository.ConsumeMagicLinkByEmail(ctx, email, deviceHash, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return nil, fmt.Errorf("invalid or expired magic link")
+	}
+
+	return link, nil
+}
+
+func (s *magicLinkService) linkURL(token string) string {
+	link, _ := url.Parse(s.config.URL)
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String()
+}
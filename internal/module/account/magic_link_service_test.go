@@ -0,0 +1,181 @@
+package account
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type magicLinkTestContext struct {
+	service          *magicLinkService
+	mockRepo         *MockMagicLinkRepository
+	mockAccountRepo  *MockAccountRepository
+	mockIdentityRepo *MockAccountIdentityRepository
+	mockAccountSvc   *MockAccountService
+}
+
+func setupMagicLinkService(includeCode bool) *magicLinkTestContext {
+	_, mockAccountRepo, mockIdentityRepo, jwtService := setupAccountService()
+
+	tc := &magicLinkTestContext{
+		mockRepo:         &MockMagicLinkRepository{},
+		mockAccountRepo:  mockAccountRepo,
+		mockIdentityRepo: mockIdentityRepo,
+		mockAccountSvc:   &MockAccountService{},
+	}
+	tc.service = &magicLinkService{
+		repository:                tc.mockRepo,
+		accountRepository:         mockAccountRepo,
+		accountIdentityRepository: mockIdentityRepo,
+		accountService:            tc.mockAccountSvc,
+		jwtService:                jwtService,
+		config: MagicLinkConfig{
+			URL:         "https://app.example.com/magic?source=email",
+			IncludeCode: includeCode,
+		},
+	}
+
+	return tc
+}
+
+func (tc *magicLinkTestContext) signLink(t *testing.T, claims map[string]any) string {
+	token, err := tc.service.jwtService.GenerateWithDuration(claims, MagicLinkExpiry)
+	require.NoError(t, err)
+	return token
+}
+
+func TestMagicLinkService_RequestMagicLink(t *testing.T) {
+	tc := setupMagicLinkService(true)
+	account := CreateTestAccount()
+
+	tc.mockAccountRepo.On("GetByEmail", mock.Anything, account.Email).Return(account, nil)
+	tc.mockRepo.On("CreateMagicLink", mock.Anything, mock.AnythingOfType("*account.MagicLink")).
+		Return(&MagicLink{ID: primitive.NewObjectID(), AccountID: account.ID.Hex()}, nil)
+	tc.mockIdentityRepo.On("CreateOTP", mock.Anything, account.Email, OTPPurposeMagicLogin).Return(CreateTestOTP(), nil)
+
+	response, err := tc.service.RequestMagicLink(context.Background(), &RequestMagicLinkRequest{Email: account.Email}, "agent", "127.0.0.1")
+	require.NoError(t, err)
+	assert.NotEmpty(t, response.DeviceSecret)
+
+	link := tc.mockRepo.Calls[0].Arguments.Get(1).(*MagicLink)
+	assert.Equal(t, hashSecret(response.DeviceSecret), link.DeviceHash, "the link is bound to the requesting device")
+	assert.Equal(t, account.ID.Hex(), link.AccountID)
+	tc.mockIdentityRepo.AssertExpectations(t)
+
+	assert.Equal(t, "https://app.example.com/magic?source=email&token=abc", tc.service.linkURL("abc"))
+}
+
+func TestMagicLinkService_RequestMagicLink_UnknownEmail(t *testing.T) {
+	tc := setupMagicLinkService(false)
+
+	tc.mockAccountRepo.On("GetByEmail", mock.Anything, "nobody@example.com").Return(nil, nil)
+
+	response, err := tc.service.RequestMagicLink(context.Background(), &RequestMagicLinkRequest{Email: "nobody@example.com"}, "agent", "127.0.0.1")
+	require.NoError(t, err)
+	assert.NotEmpty(t, response.DeviceSecret, "unknown addresses get the same response")
+	tc.mockRepo.AssertNotCalled(t, "CreateMagicLink", mock.Anything, mock.Anything)
+}
+
+func TestMagicLinkService_VerifyMagicLink(t *testing.T) {
+	account := CreateTestAccount()
+	linkID := primitive.NewObjectID()
+	link := &MagicLink{ID: linkID, AccountID: account.ID.Hex(), Email: account.Email}
+
+	tests := []struct {
+		name          string
+		request       func(tc *magicLinkTestContext) *VerifyMagicLinkRequest
+		setup         func(tc *magicLinkTestContext)
+		expectedError string
+	}{
+		{
+			name: "link opened on the requesting device",
+			request: func(tc *magicLinkTestContext) *VerifyMagicLinkRequest {
+				token := tc.signLink(t, map[string]any{"purpose": magicLinkTokenPurpose, "magic_link_id": linkID.Hex()})
+				return &VerifyMagicLinkRequest{Token: token, DeviceSecret: "device"}
+			},
+			setup: func(tc *magicLinkTestContext) {
+				tc.mockRepo.On("ConsumeMagicLink", mock.Anything, linkID, hashSecret("device"), "127.0.0.1").Return(link, nil)
+				tc.mockAccountRepo.On("GetByID", mock.Anything, account.ID).Return(account, nil)
+				tc.mockAccountSvc.On("IssueSession", mock.Anything, account.ID.Hex(), "agent", "127.0.0.1").
+					Return(&LoginResponse{Token: "session-token"}, nil)
+			},
+		},
+		{
+			name: "link already used or opened elsewhere",
+			request: func(tc *magicLinkTestContext) *VerifyMagicLinkRequest {
+				token := tc.signLink(t, map[string]any{"purpose": magicLinkTokenPurpose, "magic_link_id": linkID.Hex()})
+				return &VerifyMagicLinkRequest{Token: token, DeviceSecret: "other-device"}
+			},
+			setup: func(tc *magicLinkTestContext) {
+				tc.mockRepo.On("ConsumeMagicLink", mock.Anything, linkID, hashSecret("other-device"), "127.0.0.1").Return(nil, nil)
+			},
+			expectedError: "invalid or expired magic link",
+		},
+		{
+			name: "session token is not a magic link",
+			request: func(tc *magicLinkTestContext) *VerifyMagicLinkRequest {
+				token := tc.signLink(t, CreateTestAccountJWTClaims().ToCustomClaims())
+				return &VerifyMagicLinkRequest{Token: token, DeviceSecret: "device"}
+			},
+			setup:         func(*magicLinkTestContext) {},
+			expectedError: "invalid or expired magic link",
+		},
+		{
+			name: "missing device secret",
+			request: func(*magicLinkTestContext) *VerifyMagicLinkRequest {
+				return &VerifyMagicLinkRequest{Token: "token"}
+			},
+			setup:         func(*magicLinkTestContext) {},
+			expectedError: "device that requested it",
+		},
+		{
+			name: "codes disabled",
+			request: func(*magicLinkTestContext) *VerifyMagicLinkRequest {
+				return &VerifyMagicLinkRequest{Email: account.Email, OTP: "123456", DeviceSecret: "device"}
+			},
+			setup:         func(*magicLinkTestContext) {},
+			expectedError: "codes are not enabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := setupMagicLinkService(false)
+			tt.setup(tc)
+
+			response, err := tc.service.VerifyMagicLink(context.Background(), tt.request(tc), "agent", "127.0.0.1")
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				tc.mockAccountSvc.AssertNotCalled(t, "IssueSession", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "session-token", response.Token)
+			tc.mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMagicLinkService_VerifyMagicLink_WithCode(t *testing.T) {
+	tc := setupMagicLinkService(true)
+	account := CreateTestAccount()
+	link := &MagicLink{ID: primitive.NewObjectID(), AccountID: account.ID.Hex(), Email: account.Email, ExpiresAt: time.Now().Add(MagicLinkExpiry)}
+
+	tc.mockIdentityRepo.On("ValidateOTP", mock.Anything, account.Email, OTPPurposeMagicLogin, "123456").Return(CreateTestOTP(), nil)
+	tc.mockRepo.On("ConsumeMagicLinkByEmail", mock.Anything, account.Email, hashSecret("device"), "127.0.0.1").Return(link, nil)
+	tc.mockIdentityRepo.On("DeleteOTP", mock.Anything, account.Email, OTPPurposeMagicLogin).Return(nil)
+	tc.mockAccountRepo.On("GetByID", mock.Anything, account.ID).Return(account, nil)
+	tc.mockAccountSvc.On("IssueSession", mock.Anything, account.ID.Hex(), "agent", "127.0.0.1").Return(&LoginResponse{Token: "session-token"}, nil)
+
+	response, err := tc.service.VerifyMagicLink(context.Background(),
+		&VerifyMagicLinkRequest{Email: account.Email, OTP: "123456", DeviceSecret: "device"}, "agent", "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "session-token", response.Token)
+	tc.mockIdentityRepo.AssertExpectations(t)
+}
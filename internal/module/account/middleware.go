@@ -1,13 +1,19 @@
 package account
 
 import (
+	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/telemetry"
 )
 
 type AccountMiddleware struct {
-	service AccountService
+	service       AccountService
+	apiKeyService APIKeyService
+	rbacService   RBACService
 }
 
 func NewAccountMiddleware(service AccountService) *AccountMiddleware {
@@ -16,6 +22,34 @@ func NewAccountMiddleware(service AccountService) *AccountMiddleware {
 	}
 }
 
+// WithAPIKeyService lets RequireAuth and OptionalAuth accept API keys in
+// addition to session tokens.
+func (m *AccountMiddleware) WithAPIKeyService(apiKeyService APIKeyService) *AccountMiddleware {
+	m.apiKeyService = apiKeyService
+	return m
+}
+
+// WithRBACService enables RequirePermission and lets ValidateAccountOwnership
+// admit accounts holding the matching accounts permission.
+func (m *AccountMiddleware) WithRBACService(rbacService RBACService) *AccountMiddleware {
+	m.rbacService = rbacService
+	return m
+}
+
+// AuditContext captures the client address, user agent and trace ID of the
+// request so that audit events recorded by the services carry them.
+func (m *AccountMiddleware) AuditContext() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(auditRequestInfoKey{}, &auditRequestInfo{
+			IPAddress: c.IP(),
+			UserAgent: c.Get("User-Agent"),
+			TraceID:   telemetry.GetTraceIDFromContext(c.UserContext()),
+		})
+
+		return c.Next()
+	}
+}
+
 func (m *AccountMiddleware) ValidateAccountOwnership() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		accountID := c.Locals("account_id")
@@ -32,6 +66,14 @@ func (m *AccountMiddleware) ValidateAccountOwnership() fiber.Handler {
 		}
 
 		if accountID.(string) != requestedAccountID {
+			permission := PermissionAccountsWrite
+			if c.Method() == fiber.MethodGet {
+				permission = PermissionAccountsRead
+			}
+			if m.hasPermission(c, accountID.(string), permission) {
+				return c.Next()
+			}
+
 			account, err := m.service.GetAccountByID(c.Context(), accountID.(string))
 			if err != nil {
 				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
@@ -84,6 +126,17 @@ func (m *AccountMiddleware) RequireActiveAccount() fiber.Handler {
 
 func (m *AccountMiddleware) RequireAuth() fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if apiKey := extractAPIKey(c); apiKey != "" {
+			if !m.authenticateAPIKey(c, apiKey) {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error":   "Unauthorized",
+					"message": "Invalid or expired API key",
+				})
+			}
+
+			return c.Next()
+		}
+
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
@@ -112,6 +165,13 @@ func (m *AccountMiddleware) RequireAuth() fiber.Handler {
 		c.Locals("account_id", response.Claims.AccountID)
 		c.Locals("email", response.Claims.Email)
 		c.Locals("username", response.Claims.Username)
+		if response.Claims.IsDelegated() {
+			c.Locals("client_id", response.Claims.ClientID)
+			c.Locals("scopes", response.Claims.Scopes)
+		}
+		if response.Claims.ImpersonatorID != "" {
+			c.Locals("impersonator_id", response.Claims.ImpersonatorID)
+		}
 
 		return c.Next()
 	}
@@ -119,6 +179,11 @@ func (m *AccountMiddleware) RequireAuth() fiber.Handler {
 
 func (m *AccountMiddleware) OptionalAuth() fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if apiKey := extractAPIKey(c); apiKey != "" {
+			m.authenticateAPIKey(c, apiKey)
+			return c.Next()
+		}
+
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
 			return c.Next()
@@ -138,7 +203,134 @@ func (m *AccountMiddleware) OptionalAuth() fiber.Handler {
 		c.Locals("account_id", response.Claims.AccountID)
 		c.Locals("email", response.Claims.Email)
 		c.Locals("username", response.Claims.Username)
+		if response.Claims.IsDelegated() {
+			c.Locals("client_id", response.Claims.ClientID)
+			c.Locals("scopes", response.Claims.Scopes)
+		}
+		if response.Claims.ImpersonatorID != "" {
+			c.Locals("impersonator_id", response.Claims.ImpersonatorID)
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireScope must run after RequireAuth. Tokens from a first-party login
+// may use every scope; OAuth access tokens and API keys must have been
+// granted all of the listed scopes.
+func (m *AccountMiddleware) RequireScope(scopes ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Locals("account_id") == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error":   "Unauthorized",
+				"message": "Authentication required",
+			})
+		}
+
+		granted, restricted := c.Locals("scopes").([]string)
+		if !restricted {
+			return c.Next()
+		}
+
+		for _, scope := range scopes {
+			if !slices.Contains(granted, scope) {
+				c.Set(fiber.HeaderWWWAuthenticate, fmt.Sprintf(`Bearer error="insufficient_scope", scope="%s"`, strings.Join(scopes, " ")))
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"error":   "Forbidden",
+					"message": fmt.Sprintf("Token is missing the required scope %s", scope),
+				})
+			}
+		}
 
 		return c.Next()
 	}
-}
\ No newline at end of file
+}
+
+// RequireFirstParty must run after RequireAuth and rejects OAuth access
+// tokens, API keys and impersonation sessions, for endpoints that manage
+// credentials or grant access.
+func (m *AccountMiddleware) RequireFirstParty() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		_, restricted := c.Locals("scopes").([]string)
+		if restricted || c.Locals("impersonator_id") != nil {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":   "Forbidden",
+				"message": "This endpoint requires a first-party login",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// RequirePermission must run after RequireAuth. Permissions come from the
+// account's roles and are resolved on every request.
+func (m *AccountMiddleware) RequirePermission(permission string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		accountID, ok := c.Locals("account_id").(string)
+		if !ok || accountID == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error":   "Unauthorized",
+				"message": "Authentication required",
+			})
+		}
+
+		if !m.hasPermission(c, accountID, permission) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":   "Forbidden",
+				"message": fmt.Sprintf("Permission %s is required", permission),
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// hasPermission never grants role permissions to OAuth access tokens or API
+// keys; those are limited to their scopes.
+func (m *AccountMiddleware) hasPermission(c *fiber.Ctx, accountID, permission string) bool {
+	if m.rbacService == nil {
+		return false
+	}
+
+	if _, restricted := c.Locals("scopes").([]string); restricted {
+		return false
+	}
+
+	allowed, err := m.rbacService.HasPermission(c.Context(), accountID, permission)
+	return err == nil && allowed
+}
+
+func (m *AccountMiddleware) authenticateAPIKey(c *fiber.Ctx, key string) bool {
+	if m.apiKeyService == nil {
+		return false
+	}
+
+	apiKey, account, err := m.apiKeyService.Authenticate(c.Context(), key, c.IP())
+	if err != nil {
+		return false
+	}
+
+	c.Locals("account_id", apiKey.AccountID)
+	c.Locals("email", account.Email)
+	c.Locals("username", account.Username)
+	c.Locals("api_key_id", apiKey.ID.Hex())
+	c.Locals("scopes", apiKey.Scopes)
+
+	return true
+}
+
+// extractAPIKey reads a key from the X-API-Key header or from a bearer token
+// carrying the pat_ prefix.
+func extractAPIKey(c *fiber.Ctx) string {
+	if key := c.Get(APIKeyHeader); key != "" {
+		return key
+	}
+
+	scheme, token, found := strings.Cut(c.Get("Authorization"), " ")
+	if found && scheme == "Bearer" && strings.HasPrefix(token, APIKeyPrefix) {
+		return token
+	}
+
+	return ""
+}
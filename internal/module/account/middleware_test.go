@@ -10,6 +10,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func TestNewAccountMiddleware(t *testing.T) {
@@ -263,6 +264,256 @@ func TestAccountMiddleware_RequireActiveAccount(t *testing.T) {
 	}
 }
 
+func TestAccountMiddleware_RequireScope(t *testing.T) {
+	tests := []struct {
+		name           string
+		setupContext   func(*fiber.Ctx)
+		expectedStatus int
+		expectNext     bool
+		errorContains  string
+	}{
+		{
+			name: "first-party token passes every scope",
+			setupContext: func(c *fiber.Ctx) {
+				c.Locals("account_id", "507f1f77bcf86cd799439011")
+			},
+			expectedStatus: fiber.StatusOK,
+			expectNext:     true,
+		},
+		{
+			name: "client token with granted scope",
+			setupContext: func(c *fiber.Ctx) {
+				c.Locals("account_id", "507f1f77bcf86cd799439011")
+				c.Locals("client_id", "client-1")
+				c.Locals("scopes", []string{OAuthScopeProfile, OAuthScopeEmail})
+			},
+			expectedStatus: fiber.StatusOK,
+			expectNext:     true,
+		},
+		{
+			name: "client token missing scope",
+			setupContext: func(c *fiber.Ctx) {
+				c.Locals("account_id", "507f1f77bcf86cd799439011")
+				c.Locals("client_id", "client-1")
+				c.Locals("scopes", []string{OAuthScopeEmail})
+			},
+			expectedStatus: fiber.StatusForbidden,
+			expectNext:     false,
+			errorContains:  "missing the required scope profile",
+		},
+		{
+			name:           "unauthenticated request",
+			setupContext:   func(c *fiber.Ctx) {},
+			expectedStatus: fiber.StatusUnauthorized,
+			expectNext:     false,
+			errorContains:  "Authentication required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			middleware := NewAccountMiddleware(&MockAccountService{})
+
+			app := fiber.New()
+			nextCalled := false
+
+			app.Get("/protected", func(c *fiber.Ctx) error {
+				tt.setupContext(c)
+				return c.Next()
+			}, middleware.RequireScope(OAuthScopeProfile), func(c *fiber.Ctx) error {
+				nextCalled = true
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/protected", nil))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tt.expectNext, nextCalled)
+
+			if tt.errorContains != "" {
+				body, err := io.ReadAll(resp.Body)
+				assert.NoError(t, err)
+				assert.Contains(t, string(body), tt.errorContains)
+			}
+
+			if tt.expectedStatus == fiber.StatusForbidden {
+				assert.Contains(t, resp.Header.Get(fiber.HeaderWWWAuthenticate), "insufficient_scope")
+			}
+		})
+	}
+}
+
+func TestAccountMiddleware_RequirePermission(t *testing.T) {
+	tests := []struct {
+		name           string
+		setupContext   func(*fiber.Ctx)
+		setupMocks     func(*MockRBACService)
+		expectedStatus int
+		expectNext     bool
+	}{
+		{
+			name: "account holds the permission",
+			setupContext: func(c *fiber.Ctx) {
+				c.Locals("account_id", "507f1f77bcf86cd799439011")
+			},
+			setupMocks: func(m *MockRBACService) {
+				m.On("HasPermission", mock.Anything, "507f1f77bcf86cd799439011", PermissionAccountsRead).Return(true, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+			expectNext:     true,
+		},
+		{
+			name: "account lacks the permission",
+			setupContext: func(c *fiber.Ctx) {
+				c.Locals("account_id", "507f1f77bcf86cd799439011")
+			},
+			setupMocks: func(m *MockRBACService) {
+				m.On("HasPermission", mock.Anything, "507f1f77bcf86cd799439011", PermissionAccountsRead).Return(false, nil)
+			},
+			expectedStatus: fiber.StatusForbidden,
+			expectNext:     false,
+		},
+		{
+			name: "scoped credential never uses roles",
+			setupContext: func(c *fiber.Ctx) {
+				c.Locals("account_id", "507f1f77bcf86cd799439011")
+				c.Locals("scopes", []string{OAuthScopeProfile})
+			},
+			setupMocks:     func(m *MockRBACService) {},
+			expectedStatus: fiber.StatusForbidden,
+			expectNext:     false,
+		},
+		{
+			name:           "unauthenticated request",
+			setupContext:   func(c *fiber.Ctx) {},
+			setupMocks:     func(m *MockRBACService) {},
+			expectedStatus: fiber.StatusUnauthorized,
+			expectNext:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRBACService := &MockRBACService{}
+			tt.setupMocks(mockRBACService)
+			middleware := NewAccountMiddleware(&MockAccountService{}).WithRBACService(mockRBACService)
+
+			app := fiber.New()
+			nextCalled := false
+
+			app.Get("/admin", func(c *fiber.Ctx) error {
+				tt.setupContext(c)
+				return c.Next()
+			}, middleware.RequirePermission(PermissionAccountsRead), func(c *fiber.Ctx) error {
+				nextCalled = true
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/admin", nil))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tt.expectNext, nextCalled)
+			mockRBACService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAccountMiddleware_RequireAuth_APIKey(t *testing.T) {
+	account := CreateTestAccount()
+	apiKey := &APIKey{
+		ID:        primitive.NewObjectID(),
+		AccountID: account.ID.Hex(),
+		Scopes:    []string{OAuthScopeDocumentsWrite},
+	}
+
+	tests := []struct {
+		name           string
+		headers        map[string]string
+		setupMocks     func(*MockAccountService, *MockAPIKeyService)
+		expectedStatus int
+		expectedScopes []string
+	}{
+		{
+			name:    "X-API-Key header",
+			headers: map[string]string{APIKeyHeader: "pat_secret"},
+			setupMocks: func(_ *MockAccountService, apiKeyService *MockAPIKeyService) {
+				apiKeyService.On("Authenticate", mock.Anything, "pat_secret", mock.Anything).Return(apiKey, account, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+			expectedScopes: apiKey.Scopes,
+		},
+		{
+			name:    "bearer token with key prefix",
+			headers: map[string]string{"Authorization": "Bearer pat_secret"},
+			setupMocks: func(_ *MockAccountService, apiKeyService *MockAPIKeyService) {
+				apiKeyService.On("Authenticate", mock.Anything, "pat_secret", mock.Anything).Return(apiKey, account, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+			expectedScopes: apiKey.Scopes,
+		},
+		{
+			name:    "session token",
+			headers: map[string]string{"Authorization": "Bearer session-jwt"},
+			setupMocks: func(accountService *MockAccountService, _ *MockAPIKeyService) {
+				accountService.On("ValidateToken", mock.Anything, "session-jwt").Return(&ValidateTokenResponse{
+					Valid:  true,
+					Claims: &AccountJWTClaims{AccountID: account.ID.Hex()},
+				}, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:    "rejected key",
+			headers: map[string]string{APIKeyHeader: "pat_revoked"},
+			setupMocks: func(_ *MockAccountService, apiKeyService *MockAPIKeyService) {
+				apiKeyService.On("Authenticate", mock.Anything, "pat_revoked", mock.Anything).Return(nil, nil, errors.New("invalid API key"))
+			},
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accountService := &MockAccountService{}
+			apiKeyService := &MockAPIKeyService{}
+			tt.setupMocks(accountService, apiKeyService)
+
+			middleware := NewAccountMiddleware(accountService).WithAPIKeyService(apiKeyService)
+
+			app := fiber.New()
+			var accountID any
+			var scopes any
+
+			app.Get("/protected", middleware.RequireAuth(), func(c *fiber.Ctx) error {
+				accountID = c.Locals("account_id")
+				scopes = c.Locals("scopes")
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/protected", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			if tt.expectedStatus == fiber.StatusOK {
+				assert.Equal(t, account.ID.Hex(), accountID)
+				if tt.expectedScopes != nil {
+					assert.Equal(t, tt.expectedScopes, scopes)
+				} else {
+					assert.Nil(t, scopes)
+				}
+			}
+
+			accountService.AssertExpectations(t)
+			apiKeyService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestAccountMiddleware_Integration(t *testing.T) {
 	mockService := &MockAccountService{}
 
@@ -1,22 +1,36 @@
 package account
 
 import (
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Account struct {
-	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Email        string             `json:"email" bson:"email"`
-	Username     string             `json:"username" bson:"username"`
-	FirstName    string             `json:"first_name" bson:"first_name"`
-	LastName     string             `json:"last_name" bson:"last_name"`
-	Avatar       string             `json:"avatar" bson:"avatar"`
-	PasswordHash string             `json:"-" bson:"password_hash"`
-	IsActive     bool               `json:"is_active" bson:"is_active"`
-	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt    time.Time          `json:"updated_at" bson:"updated_at"`
+	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Email           string             `json:"email" bson:"email"`
+	Username        string             `json:"username" bson:"username"`
+	FirstName       string             `json:"first_name" bson:"first_name"`
+	LastName        string             `json:"last_name" bson:"last_name"`
+	Avatar          string             `json:"avatar" bson:"avatar"`
+	PasswordHash    string             `json:"-" bson:"password_hash"`
+	Roles           []string           `json:"roles,omitempty" bson:"roles,omitempty"`
+	Status          AccountStatus      `json:"status" bson:"status,omitempty"`
+	IsActive        bool               `json:"is_active" bson:"is_active"`
+	EmailVerifiedAt *time.Time         `json:"email_verified_at,omitempty" bson:"email_verified_at,omitempty"`
+	DeletedAt       *time.Time         `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	LockedUntil     *time.Time         `json:"locked_until,omitempty" bson:"locked_until,omitempty"`
+	LockoutCount    int                `json:"-" bson:"lockout_count,omitempty"`
+	// PasswordResetRequired blocks sign-in after a login was reported as not
+	// the owner's, until the password is reset.
+	PasswordResetRequired bool `json:"-" bson:"password_reset_required,omitempty"`
+	// PasswordHistory holds previous password hashes, newest first, so the
+	// password policy can refuse reuse.
+	PasswordHistory   []string   `json:"-" bson:"password_history,omitempty"`
+	PasswordChangedAt *time.Time `json:"-" bson:"password_changed_at,omitempty"`
+	CreatedAt       time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at" bson:"updated_at"`
 }
 
 type CreateAccountRequest struct {
@@ -36,30 +50,55 @@ type UpdateAccountRequest struct {
 }
 
 type AccountResponse struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	Username  string    `json:"username"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	Avatar    string    `json:"avatar"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              string        `json:"id"`
+	Email           string        `json:"email"`
+	Username        string        `json:"username"`
+	FirstName       string        `json:"first_name"`
+	LastName        string        `json:"last_name"`
+	Avatar          string        `json:"avatar"`
+	Roles           []string      `json:"roles,omitempty"`
+	Status          AccountStatus `json:"status"`
+	IsActive        bool          `json:"is_active"`
+	EmailVerifiedAt *time.Time    `json:"email_verified_at,omitempty"`
+	DeletedAt       *time.Time    `json:"deleted_at,omitempty"`
+	LockedUntil     *time.Time    `json:"locked_until,omitempty"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
 }
 
 
 type AccountJWTClaims struct {
-	AccountID string `json:"account_id"`
-	Email     string `json:"email"`
-	Username  string `json:"username"`
+	AccountID      string   `json:"account_id"`
+	Email          string   `json:"email"`
+	Username       string   `json:"username"`
+	ClientID       string   `json:"client_id,omitempty"`
+	Scopes         []string `json:"scopes,omitempty"`
+	ImpersonatorID string   `json:"impersonator_id,omitempty"`
 }
 
 func (c *AccountJWTClaims) ToCustomClaims() map[string]any {
-	return map[string]any{
+	customClaims := map[string]any{
 		"account_id": c.AccountID,
 		"email":      c.Email,
 		"username":   c.Username,
 	}
+
+	if c.ClientID != "" {
+		customClaims["client_id"] = c.ClientID
+		customClaims["scope"] = strings.Join(c.Scopes, " ")
+	}
+
+	if c.ImpersonatorID != "" {
+		customClaims["impersonator_id"] = c.ImpersonatorID
+	}
+
+	return customClaims
+}
+
+// IsDelegated reports whether the token was issued to an OAuth client rather
+// than to the account holder through a first-party login.
+func (c *AccountJWTClaims) IsDelegated() bool {
+	return c.ClientID != ""
 }
 
 func NewAccountJWTClaimsFromCustom(customClaims map[string]any) *AccountJWTClaims {
@@ -77,19 +116,36 @@ func NewAccountJWTClaimsFromCustom(customClaims map[string]any) *AccountJWTClaim
 		claims.Username = username
 	}
 
+	if clientID, ok := customClaims["client_id"].(string); ok {
+		claims.ClientID = clientID
+	}
+
+	if scope, ok := customClaims["scope"].(string); ok {
+		claims.Scopes = strings.Fields(scope)
+	}
+
+	if impersonatorID, ok := customClaims["impersonator_id"].(string); ok {
+		claims.ImpersonatorID = impersonatorID
+	}
+
 	return claims
 }
 
 func (a *Account) ToResponse() *AccountResponse {
 	return &AccountResponse{
-		ID:        a.ID.Hex(),
-		Email:     a.Email,
-		Username:  a.Username,
-		FirstName: a.FirstName,
-		LastName:  a.LastName,
-		Avatar:    a.Avatar,
-		IsActive:  a.IsActive,
-		CreatedAt: a.CreatedAt,
-		UpdatedAt: a.UpdatedAt,
+		ID:              a.ID.Hex(),
+		Email:           a.Email,
+		Username:        a.Username,
+		FirstName:       a.FirstName,
+		LastName:        a.LastName,
+		Avatar:          a.Avatar,
+		Roles:           a.Roles,
+		Status:          a.CurrentStatus(),
+		IsActive:        a.IsActive,
+		EmailVerifiedAt: a.EmailVerifiedAt,
+		DeletedAt:       a.DeletedAt,
+		LockedUntil:     a.LockedUntil,
+		CreatedAt:       a.CreatedAt,
+		UpdatedAt:       a.UpdatedAt,
 	}
 }
@@ -170,6 +170,7 @@ func TestAccount_ToResponse(t *testing.T) {
 				LastName:  "User",
 				Avatar:    "https://example.com/avatar.jpg",
 				IsActive:  true,
+				Status:    AccountStatusActive,
 				CreatedAt: now,
 				UpdatedAt: now,
 			},
@@ -195,6 +196,7 @@ func TestAccount_ToResponse(t *testing.T) {
 				LastName:  "",
 				Avatar:    "",
 				IsActive:  false,
+				Status:    AccountStatusPending,
 				CreatedAt: now,
 				UpdatedAt: now,
 			},
@@ -220,6 +222,7 @@ func TestAccount_ToResponse(t *testing.T) {
 				LastName:  "User",
 				Avatar:    "avatar.jpg",
 				IsActive:  true,
+				Status:    AccountStatusActive,
 				CreatedAt: time.Time{},
 				UpdatedAt: time.Time{},
 			},
@@ -0,0 +1,372 @@
+package account
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/binding"
+)
+
+type OAuthHandler struct {
+	service OAuthService
+}
+
+func NewOAuthHandler(service OAuthService) *OAuthHandler {
+	return &OAuthHandler{
+		service: service,
+	}
+}
+
+// RegisterClient godoc
+// @Summary Register an OAuth client
+// @Description Register a third-party application owned by the authenticated account. The client secret of a confidential client is only returned once.
+// @Tags oauth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body RegisterOAuthClientRequest true "Client registration"
+// @Success 201 {object} map[string]interface{} "Client registered successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /oauth/clients [post]
+func (h *OAuthHandler) RegisterClient(c *fiber.Ctx) error {
+	accountID, ok := c.Locals("account_id").(string)
+	if !ok || accountID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   "Unauthorized",
+			"message": "Account ID not found in context",
+		})
+	}
+
+	var req RegisterOAuthClientRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	client, err := h.service.RegisterClient(c.Context(), accountID, &req)
+	if err != nil {
+		statusCode := fiber.StatusInternalServerError
+		if strings.Contains(err.Error(), "invalid client") {
+			statusCode = fiber.StatusBadRequest
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"error":   "Failed to register client",
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Client registered successfully",
+		"data":    client,
+	})
+}
+
+// ListClients godoc
+// @Summary List OAuth clients
+// @Description List the OAuth clients registered by the authenticated account
+// @Tags oauth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Clients retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /oauth/clients [get]
+func (h *OAuthHandler) ListClients(c *fiber.Ctx) error {
+	accountID, ok := c.Locals("account_id").(string)
+	if !ok || accountID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   "Unauthorized",
+			"message": "Account ID not found in context",
+		})
+	}
+
+	clients, err := h.service.ListClients(c.Context(), accountID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to list clients",
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Clients retrieved successfully",
+		"data":    clients,
+	})
+}
+
+// DeleteClient godoc
+// @Summary Delete an OAuth client
+// @Description Delete an OAuth client registered by the authenticated account
+// @Tags oauth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param clientId path string true "Client ID"
+// @Success 200 {object} map[string]interface{} "Client deleted successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Client not found"
+// @Router /oauth/clients/{clientId} [delete]
+func (h *OAuthHandler) DeleteClient(c *fiber.Ctx) error {
+	accountID, ok := c.Locals("account_id").(string)
+	if !ok || accountID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   "Unauthorized",
+			"message": "Account ID not found in context",
+		})
+	}
+
+	if err := h.service.DeleteClient(c.Context(), accountID, c.Params("clientId")); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Failed to delete client",
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Client deleted successfully",
+	})
+}
+
+// AuthorizePrompt godoc
+// @Summary Inspect an authorization request
+// @Description Validate an authorization code request for the authenticated account. Returns the client and requested scopes to show on a consent screen, or the redirect URL directly when consent was already given.
+// @Tags oauth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param response_type query string true "Must be code"
+// @Param client_id query string true "Client ID"
+// @Param redirect_uri query string false "Registered redirect URI"
+// @Param scope query string false "Space-delimited scopes"
+// @Param state query string false "Opaque client state"
+// @Param code_challenge query string false "PKCE code challenge"
+// @Param code_challenge_method query string false "Must be S256"
+// @Success 200 {object} map[string]interface{} "Authorization request is valid"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Client not found"
+// @Router /oauth/authorize [get]
+func (h *OAuthHandler) AuthorizePrompt(c *fiber.Ctx) error {
+	accountID, ok := c.Locals("account_id").(string)
+	if !ok || accountID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   "Unauthorized",
+			"message": "Account ID not found in context",
+		})
+	}
+
+	var req OAuthAuthorizeRequest
+	if err := binding.Query(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	response, err := h.service.PrepareAuthorization(c.Context(), accountID, &req)
+	if err != nil {
+		return authorizeError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Authorization request is valid",
+		"data":    response,
+	})
+}
+
+// Authorize godoc
+// @Summary Approve or deny an authorization request
+// @Description Record the account holder's decision and return the URL to redirect the user agent back to the client
+// @Tags oauth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body OAuthConsentRequest true "Authorization request and decision"
+// @Success 200 {object} map[string]interface{} "Authorization decision recorded"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Client not found"
+// @Router /oauth/authorize [post]
+func (h *OAuthHandler) Authorize(c *fiber.Ctx) error {
+	accountID, ok := c.Locals("account_id").(string)
+	if !ok || accountID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   "Unauthorized",
+			"message": "Account ID not found in context",
+		})
+	}
+
+	var req OAuthConsentRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	response, err := h.service.Authorize(c.Context(), accountID, &req)
+	if err != nil {
+		return authorizeError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Authorization decision recorded",
+		"data":    response,
+	})
+}
+
+// Token godoc
+// @Summary OAuth token endpoint
+// @Description Exchange an authorization code (with PKCE), client credentials or a refresh token for an access token. Clients authenticate with HTTP Basic or client_id/client_secret form fields.
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "authorization_code, client_credentials or refresh_token"
+// @Param code formData string false "Authorization code"
+// @Param redirect_uri formData string false "Redirect URI used in the authorization request"
+// @Param code_verifier formData string false "PKCE code verifier"
+// @Param refresh_token formData string false "Refresh token"
+// @Param scope formData string false "Space-delimited scopes"
+// @Param client_id formData string false "Client ID"
+// @Param client_secret formData string false "Client secret"
+// @Success 200 {object} OAuthTokenResponse
+// @Failure 400 {object} OAuthError
+// @Failure 401 {object} OAuthError
+// @Router /oauth/token [post]
+func (h *OAuthHandler) Token(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	var req OAuthTokenRequest
+	if err := binding.Body(c, &req); err != nil {
+		return oauthErrorResponse(c, newOAuthError("invalid_request", err.Error()))
+	}
+	req.ClientID, req.ClientSecret = clientCredentials(c, req.ClientID, req.ClientSecret)
+
+	response, err := h.service.Token(c.Context(), &req, c.Get("User-Agent"), c.IP())
+	if err != nil {
+		return oauthErrorResponse(c, err)
+	}
+
+	return c.JSON(response)
+}
+
+// Introspect godoc
+// @Summary OAuth token introspection
+// @Description Report whether a token issued to the authenticated client is active (RFC 7662)
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "Token to introspect"
+// @Param token_type_hint formData string false "access_token or refresh_token"
+// @Param client_id formData string false "Client ID"
+// @Param client_secret formData string false "Client secret"
+// @Success 200 {object} OAuthIntrospectionResponse
+// @Failure 400 {object} OAuthError
+// @Failure 401 {object} OAuthError
+// @Router /oauth/introspect [post]
+func (h *OAuthHandler) Introspect(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	var req OAuthTokenActionRequest
+	if err := binding.Body(c, &req); err != nil {
+		return oauthErrorResponse(c, newOAuthError("invalid_request", err.Error()))
+	}
+	req.ClientID, req.ClientSecret = clientCredentials(c, req.ClientID, req.ClientSecret)
+
+	response, err := h.service.Introspect(c.Context(), &req)
+	if err != nil {
+		return oauthErrorResponse(c, err)
+	}
+
+	return c.JSON(response)
+}
+
+// Revoke godoc
+// @Summary OAuth token revocation
+// @Description Revoke an access or refresh token issued to the authenticated client (RFC 7009). Revoking a refresh token also revokes the tokens rotated from it.
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "Token to revoke"
+// @Param token_type_hint formData string false "access_token or refresh_token"
+// @Param client_id formData string false "Client ID"
+// @Param client_secret formData string false "Client secret"
+// @Success 200 "Token revoked or unknown"
+// @Failure 400 {object} OAuthError
+// @Failure 401 {object} OAuthError
+// @Router /oauth/revoke [post]
+func (h *OAuthHandler) Revoke(c *fiber.Ctx) error {
+	var req OAuthTokenActionRequest
+	if err := binding.Body(c, &req); err != nil {
+		return oauthErrorResponse(c, newOAuthError("invalid_request", err.Error()))
+	}
+	req.ClientID, req.ClientSecret = clientCredentials(c, req.ClientID, req.ClientSecret)
+
+	if err := h.service.Revoke(c.Context(), &req); err != nil {
+		return oauthErrorResponse(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func authorizeError(c *fiber.Ctx, err error) error {
+	statusCode := fiber.StatusInternalServerError
+	if strings.Contains(err.Error(), "not found") {
+		statusCode = fiber.StatusNotFound
+	} else if strings.Contains(err.Error(), "invalid request") {
+		statusCode = fiber.StatusBadRequest
+	}
+
+	return c.Status(statusCode).JSON(fiber.Map{
+		"error":   "Authorization failed",
+		"message": err.Error(),
+	})
+}
+
+// oauthErrorResponse renders errors in the RFC 6749 format expected by OAuth
+// client libraries rather than the API's usual error envelope.
+func oauthErrorResponse(c *fiber.Ctx, err error) error {
+	var oauthErr *OAuthError
+	if !errors.As(err, &oauthErr) {
+		oauthErr = &OAuthError{
+			Code:   "server_error",
+			Status: fiber.StatusInternalServerError,
+		}
+	}
+
+	if oauthErr.Code == "invalid_client" {
+		c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="oauth"`)
+	}
+
+	return c.Status(oauthErr.Status).JSON(oauthErr)
+}
+
+// clientCredentials prefers HTTP Basic client authentication and falls back
+// to the client_id and client_secret form fields.
+func clientCredentials(c *fiber.Ctx, formClientID, formClientSecret string) (string, string) {
+	scheme, encoded, found := strings.Cut(c.Get(fiber.HeaderAuthorization), " ")
+	if !found || !strings.EqualFold(scheme, "Basic") {
+		return formClientID, formClientSecret
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return formClientID, formClientSecret
+	}
+
+	rawID, rawSecret, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return formClientID, formClientSecret
+	}
+
+	clientID, err := url.QueryUnescape(rawID)
+	if err != nil {
+		return formClientID, formClientSecret
+	}
+
+	clientSecret, err := url.QueryUnescape(rawSecret)
+	if err != nil {
+		return formClientID, formClientSecret
+	}
+
+	return clientID, clientSecret
+}
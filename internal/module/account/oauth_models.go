@@ -0,0 +1,250 @@
+package account
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	OAuthGrantAuthorizationCode = "authorization_code"
+	OAuthGrantClientCredentials = "client_credentials"
+	OAuthGrantRefreshToken      = "refresh_token"
+)
+
+const (
+	OAuthScopeProfile        = "profile"
+	OAuthScopeEmail          = "email"
+	OAuthScopeKnowledgeRead  = "knowledge:read"
+	OAuthScopeKnowledgeWrite = "knowledge:write"
+	OAuthScopeDocumentsRead  = "documents:read"
+	OAuthScopeDocumentsWrite = "documents:write"
+)
+
+// SupportedOAuthScopes lists every scope a client may register for and an
+// account holder may grant.
+var SupportedOAuthScopes = []string{
+	OAuthScopeProfile,
+	OAuthScopeEmail,
+	OAuthScopeKnowledgeRead,
+	OAuthScopeKnowledgeWrite,
+	OAuthScopeDocumentsRead,
+	OAuthScopeDocumentsWrite,
+}
+
+const (
+	OAuthAuthorizationCodeExpiry = 10 * time.Minute
+	OAuthAccessTokenExpiry       = time.Hour
+	OAuthRefreshTokenExpiry      = 30 * 24 * time.Hour
+)
+
+const (
+	OAuthTokenTypeAccess  = "access_token"
+	OAuthTokenTypeRefresh = "refresh_token"
+)
+
+type OAuthClient struct {
+	ID               primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	ClientID         string             `json:"client_id" bson:"client_id"`
+	ClientSecretHash string             `json:"-" bson:"client_secret_hash,omitempty"`
+	OwnerAccountID   string             `json:"owner_account_id" bson:"owner_account_id"`
+	Name             string             `json:"name" bson:"name"`
+	RedirectURIs     []string           `json:"redirect_uris" bson:"redirect_uris"`
+	GrantTypes       []string           `json:"grant_types" bson:"grant_types"`
+	Scopes           []string           `json:"scopes" bson:"scopes"`
+	Confidential     bool               `json:"confidential" bson:"confidential"`
+	CreatedAt        time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+type OAuthAuthorizationCode struct {
+	ID                  primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	CodeHash            string             `json:"-" bson:"code_hash"`
+	ClientID            string             `json:"client_id" bson:"client_id"`
+	AccountID           string             `json:"account_id" bson:"account_id"`
+	RedirectURI         string             `json:"redirect_uri" bson:"redirect_uri"`
+	Scopes              []string           `json:"scopes" bson:"scopes"`
+	CodeChallenge       string             `json:"-" bson:"code_challenge,omitempty"`
+	CodeChallengeMethod string             `json:"-" bson:"code_challenge_method,omitempty"`
+	ExpiresAt           time.Time          `json:"expires_at" bson:"expires_at"`
+	CreatedAt           time.Time          `json:"created_at" bson:"created_at"`
+}
+
+type OAuthConsent struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	AccountID string             `json:"account_id" bson:"account_id"`
+	ClientID  string             `json:"client_id" bson:"client_id"`
+	Scopes    []string           `json:"scopes" bson:"scopes"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// OAuthRefreshToken is stored hashed. Tokens rotated from the same grant share
+// a FamilyID so that replaying an already rotated token revokes the family.
+type OAuthRefreshToken struct {
+	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TokenHash       string             `json:"-" bson:"token_hash"`
+	FamilyID        string             `json:"family_id" bson:"family_id"`
+	ClientID        string             `json:"client_id" bson:"client_id"`
+	AccountID       string             `json:"account_id" bson:"account_id"`
+	Scopes          []string           `json:"scopes" bson:"scopes"`
+	AccessTokenHash string             `json:"-" bson:"access_token_hash"`
+	Revoked         bool               `json:"revoked" bson:"revoked"`
+	ExpiresAt       time.Time          `json:"expires_at" bson:"expires_at"`
+	CreatedAt       time.Time          `json:"created_at" bson:"created_at"`
+}
+
+type RegisterOAuthClientRequest struct {
+	Name         string   `json:"name" validate:"required,min=1,max=100"`
+	RedirectURIs []string `json:"redirect_uris"`
+	GrantTypes   []string `json:"grant_types" validate:"required,min=1"`
+	Scopes       []string `json:"scopes" validate:"required,min=1"`
+	Confidential bool     `json:"confidential"`
+}
+
+type OAuthAuthorizeRequest struct {
+	ResponseType        string `json:"response_type" query:"response_type" form:"response_type" validate:"required"`
+	ClientID            string `json:"client_id" query:"client_id" form:"client_id" validate:"required"`
+	RedirectURI         string `json:"redirect_uri" query:"redirect_uri" form:"redirect_uri"`
+	Scope               string `json:"scope" query:"scope" form:"scope"`
+	State               string `json:"state" query:"state" form:"state"`
+	CodeChallenge       string `json:"code_challenge" query:"code_challenge" form:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method" query:"code_challenge_method" form:"code_challenge_method"`
+}
+
+type OAuthConsentRequest struct {
+	OAuthAuthorizeRequest
+	Approve bool `json:"approve" form:"approve"`
+}
+
+type OAuthTokenRequest struct {
+	GrantType    string `form:"grant_type" validate:"required"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+type OAuthTokenActionRequest struct {
+	Token         string `form:"token" validate:"required"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientID      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
+
+type OAuthClientResponse struct {
+	ClientID     string    `json:"client_id"`
+	Name         string    `json:"name"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	GrantTypes   []string  `json:"grant_types"`
+	Scopes       []string  `json:"scopes"`
+	Confidential bool      `json:"confidential"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type OAuthClientCreatedResponse struct {
+	OAuthClientResponse
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+type OAuthConsentPromptResponse struct {
+	Client          *OAuthClientResponse `json:"client"`
+	RequestedScopes []string             `json:"requested_scopes"`
+	ConsentRequired bool                 `json:"consent_required"`
+	RedirectTo      string               `json:"redirect_to,omitempty"`
+}
+
+type OAuthAuthorizeResponse struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// OAuthIntrospectionResponse follows RFC 7662; only Active is set for tokens
+// that are unknown, expired or revoked.
+type OAuthIntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+}
+
+// OAuthError carries an RFC 6749 error code together with the HTTP status the
+// token, introspection and revocation endpoints should answer with.
+type OAuthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+	Status      int    `json:"-"`
+}
+
+func (e *OAuthError) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
+
+func newOAuthError(code, description string) *OAuthError {
+	status := http.StatusBadRequest
+	if code == "invalid_client" {
+		status = http.StatusUnauthorized
+	}
+
+	return &OAuthError{
+		Code:        code,
+		Description: description,
+		Status:      status,
+	}
+}
+
+func (c *OAuthClient) HasRedirectURI(redirectURI string) bool {
+	return slices.Contains(c.RedirectURIs, redirectURI)
+}
+
+func (c *OAuthClient) AllowsGrant(grantType string) bool {
+	return slices.Contains(c.GrantTypes, grantType)
+}
+
+func (c *OAuthClient) ToResponse() *OAuthClientResponse {
+	return &OAuthClientResponse{
+		ClientID:     c.ClientID,
+		Name:         c.Name,
+		RedirectURIs: c.RedirectURIs,
+		GrantTypes:   c.GrantTypes,
+		Scopes:       c.Scopes,
+		Confidential: c.Confidential,
+		CreatedAt:    c.CreatedAt,
+	}
+}
+
+func (c *OAuthAuthorizationCode) IsExpired() bool {
+	return time.Now().After(c.ExpiresAt)
+}
+
+func (t *OAuthRefreshToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+func (c *OAuthConsent) Covers(scopes []string) bool {
+	for _, scope := range scopes {
+		if !slices.Contains(c.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,254 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+const (
+	OAuthClientCollectionName            = "oauth_clients"
+	OAuthAuthorizationCodeCollectionName = "oauth_authorization_codes"
+	OAuthConsentCollectionName           = "oauth_consents"
+	OAuthRefreshTokenCollectionName      = "oauth_refresh_tokens"
+)
+
+type OAuthRepository interface {
+	CreateClient(ctx context.Context, client *OAuthClient) (*OAuthClient, error)
+	GetClientByClientID(ctx context.Context, clientID string) (*OAuthClient, error)
+	GetClientsByOwner(ctx context.Context, ownerAccountID string) ([]*OAuthClient, error)
+	DeleteClient(ctx context.Context, ownerAccountID, clientID string) error
+
+	CreateAuthorizationCode(ctx context.Context, code *OAuthAuthorizationCode) (*OAuthAuthorizationCode, error)
+	ConsumeAuthorizationCode(ctx context.Context, codeHash string) (*OAuthAuthorizationCode, error)
+
+	GetConsent(ctx context.Context, accountID, clientID string) (*OAuthConsent, error)
+	SaveConsent(ctx context.Context, accountID, clientID string, scopes []string) error
+
+	CreateRefreshToken(ctx context.Context, token *OAuthRefreshToken) (*OAuthRefreshToken, error)
+	GetRefreshToken(ctx context.Context, tokenHash string) (*OAuthRefreshToken, error)
+	ConsumeRefreshToken(ctx context.Context, id primitive.ObjectID) (*OAuthRefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, id primitive.ObjectID) error
+	GetRefreshTokenFamily(ctx context.Context, familyID string) ([]*OAuthRefreshToken, error)
+}
+
+type oauthRepository struct {
+	clientRepo       mongo.Repository[OAuthClient]
+	codeRepo         mongo.Repository[OAuthAuthorizationCode]
+	consentRepo      mongo.Repository[OAuthConsent]
+	refreshTokenRepo mongo.Repository[OAuthRefreshToken]
+}
+
+var _ OAuthRepository = (*oauthRepository)(nil)
+
+func NewOAuthRepository(mongoService *mongo.MongoService) OAuthRepository {
+	return &oauthRepository{
+		clientRepo:       mongo.NewRepository[OAuthClient](mongoService, OAuthClientCollectionName),
+		codeRepo:         mongo.NewRepository[OAuthAuthorizationCode](mongoService, OAuthAuthorizationCodeCollectionName),
+		consentRepo:      mongo.NewRepository[OAuthConsent](mongoService, OAuthConsentCollectionName),
+		refreshTokenRepo: mongo.NewRepository[OAuthRefreshToken](mongoService, OAuthRefreshTokenCollectionName),
+	}
+}
+
+func (r *oauthRepository) CreateClient(ctx context.Context, client *OAuthClient) (*OAuthClient, error) {
+	client.ID = primitive.NewObjectID()
+
+	result, err := r.clientRepo.Create(ctx, *client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OAuth client: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *oauthRepository) GetClientByClientID(ctx context.Context, clientID string) (*OAuthClient, error) {
+	filter := bson.M{"client_id": clientID}
+
+	result, err := r.clientRepo.FindOne(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OAuth client: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *oauthRepository) GetClientsByOwner(ctx context.Context, ownerAccountID string) ([]*OAuthClient, error) {
+	filter := bson.M{"owner_account_id": ownerAccountID}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	results, err := r.clientRepo.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OAuth clients: %w", err)
+	}
+
+	clients := make([]*OAuthClient, len(results))
+	for i := range results {
+		clients[i] = &results[i]
+	}
+
+	return clients, nil
+}
+
+func (r *oauthRepository) DeleteClient(ctx context.Context, ownerAccountID, clientID string) error {
+	filter := bson.M{
+		"client_id":        clientID,
+		"owner_account_id": ownerAccountID,
+	}
+
+	if err := r.clientRepo.Delete(ctx, filter); err != nil {
+		return fmt.Errorf("failed to delete OAuth client: %w", err)
+	}
+
+	return nil
+}
+
+func (r *oauthRepository) CreateAuthorizationCode(ctx context.Context, code *OAuthAuthorizationCode) (*OAuthAuthorizationCode, error) {
+	code.ID = primitive.NewObjectID()
+
+	result, err := r.codeRepo.Create(ctx, *code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create authorization code: %w", err)
+	}
+
+	return result, nil
+}
+
+// ConsumeAuthorizationCode loads and removes an authorization code so that
+// each code can be exchanged at most once.
+func (r *oauthRepository) ConsumeAuthorizationCode(ctx context.Context, codeHash string) (*OAuthAuthorizationCode, error) {
+	filter := bson.M{"code_hash": codeHash}
+
+	result, err := r.codeRepo.FindOne(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authorization code: %w", err)
+	}
+
+	if result == nil {
+		return nil, nil
+	}
+
+	if err := r.codeRepo.Delete(ctx, bson.M{"_id": result.ID}); err != nil {
+		return nil, fmt.Errorf("failed to consume authorization code: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *oauthRepository) GetConsent(ctx context.Context, accountID, clientID string) (*OAuthConsent, error) {
+	filter := bson.M{
+		"account_id": accountID,
+		"client_id":  clientID,
+	}
+
+	result, err := r.consentRepo.FindOne(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OAuth consent: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *oauthRepository) SaveConsent(ctx context.Context, accountID, clientID string, scopes []string) error {
+	existing, err := r.GetConsent(ctx, accountID, clientID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if existing == nil {
+		_, err := r.consentRepo.Create(ctx, OAuthConsent{
+			ID:        primitive.NewObjectID(),
+			AccountID: accountID,
+			ClientID:  clientID,
+			Scopes:    scopes,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create OAuth consent: %w", err)
+		}
+		return nil
+	}
+
+	filter := bson.M{"_id": existing.ID}
+	update := bson.M{
+		"$addToSet": bson.M{"scopes": bson.M{"$each": scopes}},
+		"$set":      bson.M{"updated_at": now},
+	}
+
+	if _, err := r.consentRepo.Update(ctx, filter, update); err != nil {
+		return fmt.Errorf("failed to update OAuth consent: %w", err)
+	}
+
+	return nil
+}
+
+func (r *oauthRepository) CreateRefreshToken(ctx context.Context, token *OAuthRefreshToken) (*OAuthRefreshToken, error) {
+	token.ID = primitive.NewObjectID()
+
+	result, err := r.refreshTokenRepo.Create(ctx, *token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *oauthRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*OAuthRefreshToken, error) {
+	filter := bson.M{"token_hash": tokenHash}
+
+	result, err := r.refreshTokenRepo.FindOne(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	return result, nil
+}
+
+// ConsumeRefreshToken revokes a refresh token in a single update, so that
+// two concurrent rotations cannot both succeed. It returns nil when the token
+// was already revoked.
+func (r *oauthRepository) ConsumeRefreshToken(ctx context.Context, id primitive.ObjectID) (*OAuthRefreshToken, error) {
+	filter := bson.M{"_id": id, "revoked": false}
+	update := bson.M{"$set": bson.M{"revoked": true}}
+
+	result, err := r.refreshTokenRepo.Update(ctx, filter, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume refresh token: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *oauthRepository) RevokeRefreshToken(ctx context.Context, id primitive.ObjectID) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{"revoked": true}}
+
+	if _, err := r.refreshTokenRepo.Update(ctx, filter, update); err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *oauthRepository) GetRefreshTokenFamily(ctx context.Context, familyID string) ([]*OAuthRefreshToken, error) {
+	filter := bson.M{"family_id": familyID}
+
+	results, err := r.refreshTokenRepo.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token family: %w", err)
+	}
+
+	tokens := make([]*OAuthRefreshToken, len(results))
+	for i := range results {
+		tokens[i] = &results[i]
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,751 @@
+package account
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/oauth2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/jwt"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/redis"
+)
+
+type OAuthService interface {
+	RegisterClient(ctx context.Context, ownerAccountID string, req *RegisterOAuthClientRequest) (*OAuthClientCreatedResponse, error)
+	ListClients(ctx context.Context, ownerAccountID string) ([]*OAuthClientResponse, error)
+	DeleteClient(ctx context.Context, ownerAccountID, clientID string) error
+
+	PrepareAuthorization(ctx context.Context, accountID string, req *OAuthAuthorizeRequest) (*OAuthConsentPromptResponse, error)
+	Authorize(ctx context.Context, accountID string, req *OAuthConsentRequest) (*OAuthAuthorizeResponse, error)
+
+	Token(ctx context.Context, req *OAuthTokenRequest, userAgent, ipAddress string) (*OAuthTokenResponse, error)
+	Introspect(ctx context.Context, req *OAuthTokenActionRequest) (*OAuthIntrospectionResponse, error)
+	Revoke(ctx context.Context, req *OAuthTokenActionRequest) error
+}
+
+type oauthService struct {
+	repository                OAuthRepository
+	accountRepository         AccountRepository
+	accountIdentityRepository AccountIdentityRepository
+	jwtService                *jwt.JWTService
+}
+
+func NewOAuthService(
+	mongoService *mongo.MongoService,
+	cacheService redis.RedisService,
+	jwtService *jwt.JWTService,
+) OAuthService {
+	return newOAuthService(
+		NewOAuthRepository(mongoService),
+		NewAccountRepository(mongoService),
+		newAccountIdentityRepository(mongoService, cacheService),
+		jwtService,
+	)
+}
+
+func newOAuthService(
+	repository OAuthRepository,
+	accountRepository AccountRepository,
+	accountIdentityRepository AccountIdentityRepository,
+	jwtService *jwt.JWTService,
+) *oauthService {
+	return &oauthService{
+		repository:                repository,
+		accountRepository:         accountRepository,
+		accountIdentityRepository: accountIdentityRepository,
+		jwtService:                jwtService,
+	}
+}
+
+func (s *oauthService) RegisterClient(ctx context.Context, ownerAccountID string, req *RegisterOAuthClientRequest) (*OAuthClientCreatedResponse, error) {
+	if err := validateClientRegistration(req); err != nil {
+		return nil, err
+	}
+
+	clientID, err := generateRandomToken(16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate client ID: %w", err)
+	}
+
+	client := &OAuthClient{
+		ClientID:       clientID,
+		OwnerAccountID: ownerAccountID,
+		Name:           req.Name,
+		RedirectURIs:   req.RedirectURIs,
+		GrantTypes:     req.GrantTypes,
+		Scopes:         req.Scopes,
+		Confidential:   req.Confidential,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	var clientSecret string
+	if req.Confidential {
+		clientSecret, err = generateRandomToken(32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate client secret: %w", err)
+		}
+		client.ClientSecretHash = hashSecret(clientSecret)
+	}
+
+	created, err := s.repository.CreateClient(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register client: %w", err)
+	}
+
+	return &OAuthClientCreatedResponse{
+		OAuthClientResponse: *created.ToResponse(),
+		ClientSecret:        clientSecret,
+	}, nil
+}
+
+func (s *oauthService) ListClients(ctx context.Context, ownerAccountID string) ([]*OAuthClientResponse, error) {
+	clients, err := s.repository.GetClientsByOwner(ctx, ownerAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clients: %w", err)
+	}
+
+	responses := make([]*OAuthClientResponse, len(clients))
+	for i, client := range clients {
+		responses[i] = client.ToResponse()
+	}
+
+	return responses, nil
+}
+
+func (s *oauthService) DeleteClient(ctx context.Context, ownerAccountID, clientID string) error {
+	if err := s.repository.DeleteClient(ctx, ownerAccountID, clientID); err != nil {
+		return fmt.Errorf("OAuth client not found: %w", err)
+	}
+
+	return nil
+}
+
+// PrepareAuthorization validates an authorization request and tells the
+// caller whether the account holder still has to approve it. When an earlier
+// consent already covers the requested scopes the code is issued right away.
+func (s *oauthService) PrepareAuthorization(ctx context.Context, accountID string, req *OAuthAuthorizeRequest) (*OAuthConsentPromptResponse, error) {
+	client, redirectURI, scopes, err := s.validateAuthorizeRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &OAuthConsentPromptResponse{
+		Client:          client.ToResponse(),
+		RequestedScopes: scopes,
+		ConsentRequired: true,
+	}
+
+	consent, err := s.repository.GetConsent(ctx, accountID, client.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consent: %w", err)
+	}
+
+	if consent != nil && consent.Covers(scopes) {
+		redirectTo, err := s.issueAuthorizationCode(ctx, accountID, client, redirectURI, scopes, req)
+		if err != nil {
+			return nil, err
+		}
+
+		response.ConsentRequired = false
+		response.RedirectTo = redirectTo
+	}
+
+	return response, nil
+}
+
+func (s *oauthService) Authorize(ctx context.Context, accountID string, req *OAuthConsentRequest) (*OAuthAuthorizeResponse, error) {
+	client, redirectURI, scopes, err := s.validateAuthorizeRequest(ctx, &req.OAuthAuthorizeRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	if !req.Approve {
+		return &OAuthAuthorizeResponse{
+			RedirectTo: buildRedirectURI(redirectURI, map[string]string{
+				"error":             "access_denied",
+				"error_description": "the account holder denied the request",
+				"state":             req.State,
+			}),
+		}, nil
+	}
+
+	if err := s.repository.SaveConsent(ctx, accountID, client.ClientID, scopes); err != nil {
+		return nil, fmt.Errorf("failed to save consent: %w", err)
+	}
+
+	redirectTo, err := s.issueAuthorizationCode(ctx, accountID, client, redirectURI, scopes, &req.OAuthAuthorizeRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OAuthAuthorizeResponse{RedirectTo: redirectTo}, nil
+}
+
+func (s *oauthService) Token(ctx context.Context, req *OAuthTokenRequest, userAgent, ipAddress string) (*OAuthTokenResponse, error) {
+	switch req.GrantType {
+	case OAuthGrantAuthorizationCode, OAuthGrantClientCredentials, OAuthGrantRefreshToken:
+	default:
+		return nil, newOAuthError("unsupported_grant_type", fmt.Sprintf("grant type %q is not supported", req.GrantType))
+	}
+
+	client, err := s.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if !client.AllowsGrant(req.GrantType) {
+		return nil, newOAuthError("unauthorized_client", fmt.Sprintf("client is not allowed to use the %s grant", req.GrantType))
+	}
+
+	switch req.GrantType {
+	case OAuthGrantAuthorizationCode:
+		return s.exchangeAuthorizationCode(ctx, client, req, userAgent, ipAddress)
+	case OAuthGrantClientCredentials:
+		return s.exchangeClientCredentials(ctx, client, req, userAgent, ipAddress)
+	default:
+		return s.exchangeRefreshToken(ctx, client, req, userAgent, ipAddress)
+	}
+}
+
+// Introspect implements RFC 7662. Clients may only introspect tokens that
+// were issued to them; anything else is reported as inactive.
+func (s *oauthService) Introspect(ctx context.Context, req *OAuthTokenActionRequest) (*OAuthIntrospectionResponse, error) {
+	client, err := s.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	lookups := []func(context.Context, *OAuthClient, string) (*OAuthIntrospectionResponse, error){
+		s.introspectAccessToken,
+		s.introspectRefreshToken,
+	}
+	if req.TokenTypeHint == OAuthTokenTypeRefresh {
+		slices.Reverse(lookups)
+	}
+
+	for _, lookup := range lookups {
+		response, err := lookup(ctx, client, req.Token)
+		if err != nil {
+			return nil, err
+		}
+		if response != nil {
+			return response, nil
+		}
+	}
+
+	return &OAuthIntrospectionResponse{Active: false}, nil
+}
+
+// Revoke implements RFC 7009. Unknown tokens and tokens belonging to other
+// clients are ignored so that the response never reveals whether they exist.
+func (s *oauthService) Revoke(ctx context.Context, req *OAuthTokenActionRequest) error {
+	client, err := s.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return err
+	}
+
+	if claims, err := s.jwtService.Verify(req.Token); err == nil {
+		accountClaims := NewAccountJWTClaimsFromCustom(claims.CustomClaims)
+		if accountClaims.ClientID != client.ClientID {
+			return nil
+		}
+
+		if err := s.accountIdentityRepository.DeactivateSession(ctx, hashSecret(req.Token)); err != nil {
+			return fmt.Errorf("failed to revoke access token: %w", err)
+		}
+		return nil
+	}
+
+	refreshToken, err := s.repository.GetRefreshToken(ctx, hashSecret(req.Token))
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	if refreshToken == nil || refreshToken.ClientID != client.ClientID {
+		return nil
+	}
+
+	return s.revokeRefreshTokenFamily(ctx, refreshToken.FamilyID)
+}
+
+func (s *oauthService) exchangeAuthorizationCode(ctx context.Context, client *OAuthClient, req *OAuthTokenRequest, userAgent, ipAddress string) (*OAuthTokenResponse, error) {
+	if req.Code == "" {
+		return nil, newOAuthError("invalid_request", "code is required")
+	}
+
+	code, err := s.repository.ConsumeAuthorizationCode(ctx, hashSecret(req.Code))
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume authorization code: %w", err)
+	}
+
+	if code == nil || code.ClientID != client.ClientID || code.IsExpired() {
+		return nil, newOAuthError("invalid_grant", "authorization code is invalid or has expired")
+	}
+
+	if req.RedirectURI != code.RedirectURI {
+		return nil, newOAuthError("invalid_grant", "redirect_uri does not match the authorization request")
+	}
+
+	if code.CodeChallenge != "" {
+		if req.CodeVerifier == "" {
+			return nil, newOAuthError("invalid_grant", "code_verifier is required")
+		}
+
+		challenge := oauth2.S256ChallengeFromVerifier(req.CodeVerifier)
+		if subtle.ConstantTimeCompare([]byte(challenge), []byte(code.CodeChallenge)) != 1 {
+			return nil, newOAuthError("invalid_grant", "code_verifier does not match the code challenge")
+		}
+	}
+
+	account, err := s.getActiveAccount(ctx, code.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, client, account, code.Scopes, client.AllowsGrant(OAuthGrantRefreshToken), "", userAgent, ipAddress)
+}
+
+// exchangeClientCredentials issues a token that acts as the account which
+// registered the client, limited to the client's scopes.
+func (s *oauthService) exchangeClientCredentials(ctx context.Context, client *OAuthClient, req *OAuthTokenRequest, userAgent, ipAddress string) (*OAuthTokenResponse, error) {
+	if !client.Confidential {
+		return nil, newOAuthError("unauthorized_client", "public clients cannot use the client_credentials grant")
+	}
+
+	scopes, err := narrowScopes(req.Scope, client.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := s.getActiveAccount(ctx, client.OwnerAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, client, account, scopes, false, "", userAgent, ipAddress)
+}
+
+// exchangeRefreshToken rotates the presented refresh token. Presenting a token
+// that was already rotated or revoked is treated as theft and revokes every
+// token in its family.
+func (s *oauthService) exchangeRefreshToken(ctx context.Context, client *OAuthClient, req *OAuthTokenRequest, userAgent, ipAddress string) (*OAuthTokenResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, newOAuthError("invalid_request", "refresh_token is required")
+	}
+
+	refreshToken, err := s.repository.GetRefreshToken(ctx, hashSecret(req.RefreshToken))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	if refreshToken == nil || refreshToken.ClientID != client.ClientID {
+		return nil, newOAuthError("invalid_grant", "refresh token is invalid")
+	}
+
+	if refreshToken.Revoked {
+		return nil, s.refreshTokenReused(ctx, refreshToken)
+	}
+
+	if refreshToken.IsExpired() {
+		return nil, newOAuthError("invalid_grant", "refresh token has expired")
+	}
+
+	scopes, err := narrowScopes(req.Scope, refreshToken.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	// A concurrent request may have rotated the token since it was read; only
+	// the request that revokes it may continue.
+	consumed, err := s.repository.ConsumeRefreshToken(ctx, refreshToken.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if consumed == nil {
+		return nil, s.refreshTokenReused(ctx, refreshToken)
+	}
+
+	account, err := s.getActiveAccount(ctx, refreshToken.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, client, account, scopes, true, refreshToken.FamilyID, userAgent, ipAddress)
+}
+
+// refreshTokenReused revokes the family of a refresh token that was presented
+// after it had been rotated or revoked.
+func (s *oauthService) refreshTokenReused(ctx context.Context, refreshToken *OAuthRefreshToken) error {
+	if err := s.revokeRefreshTokenFamily(ctx, refreshToken.FamilyID); err != nil {
+		return err
+	}
+	return newOAuthError("invalid_grant", "refresh token has been revoked")
+}
+
+// issueTokens stores the access token as a regular session carrying the
+// client and scopes, so RequireAuth accepts it like any other login.
+func (s *oauthService) issueTokens(
+	ctx context.Context,
+	client *OAuthClient,
+	account *Account,
+	scopes []string,
+	withRefreshToken bool,
+	familyID string,
+	userAgent, ipAddress string,
+) (*OAuthTokenResponse, error) {
+	claims := &AccountJWTClaims{
+		AccountID: account.ID.Hex(),
+		Email:     account.Email,
+		Username:  account.Username,
+		ClientID:  client.ClientID,
+		Scopes:    scopes,
+	}
+
+	accessToken, err := s.jwtService.GenerateWithDuration(claims.ToCustomClaims(), OAuthAccessTokenExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	session := &Session{
+		AccountID: account.ID.Hex(),
+		TokenHash: hashSecret(accessToken),
+		IsActive:  true,
+		ExpiresAt: time.Now().Add(OAuthAccessTokenExpiry),
+		UserAgent: userAgent,
+		IPAddress: ipAddress,
+		ClientID:  client.ClientID,
+		Scopes:    scopes,
+	}
+
+	if _, err := s.accountIdentityRepository.CreateSession(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	response := &OAuthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(OAuthAccessTokenExpiry.Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	}
+
+	if !withRefreshToken {
+		return response, nil
+	}
+
+	refreshToken, err := generateRandomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	if familyID == "" {
+		familyID = primitive.NewObjectID().Hex()
+	}
+
+	_, err = s.repository.CreateRefreshToken(ctx, &OAuthRefreshToken{
+		TokenHash:       hashSecret(refreshToken),
+		FamilyID:        familyID,
+		ClientID:        client.ClientID,
+		AccountID:       account.ID.Hex(),
+		Scopes:          scopes,
+		AccessTokenHash: session.TokenHash,
+		ExpiresAt:       time.Now().Add(OAuthRefreshTokenExpiry),
+		CreatedAt:       time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	response.RefreshToken = refreshToken
+	return response, nil
+}
+
+func (s *oauthService) introspectAccessToken(ctx context.Context, client *OAuthClient, token string) (*OAuthIntrospectionResponse, error) {
+	claims, err := s.jwtService.Verify(token)
+	if err != nil {
+		return nil, nil
+	}
+
+	accountClaims := NewAccountJWTClaimsFromCustom(claims.CustomClaims)
+	if accountClaims.ClientID != client.ClientID {
+		return nil, nil
+	}
+
+	session, err := s.accountIdentityRepository.GetSessionByToken(ctx, hashSecret(token))
+	if err != nil || session == nil || !session.IsActive || session.IsExpired() {
+		return nil, nil
+	}
+
+	if _, err := s.getActiveAccount(ctx, accountClaims.AccountID); err != nil {
+		return nil, nil
+	}
+
+	response := &OAuthIntrospectionResponse{
+		Active:    true,
+		Scope:     strings.Join(accountClaims.Scopes, " "),
+		ClientID:  accountClaims.ClientID,
+		Username:  accountClaims.Username,
+		TokenType: "Bearer",
+		Subject:   accountClaims.AccountID,
+	}
+	if claims.ExpiresAt != nil {
+		response.ExpiresAt = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		response.IssuedAt = claims.IssuedAt.Unix()
+	}
+
+	return response, nil
+}
+
+func (s *oauthService) introspectRefreshToken(ctx context.Context, client *OAuthClient, token string) (*OAuthIntrospectionResponse, error) {
+	refreshToken, err := s.repository.GetRefreshToken(ctx, hashSecret(token))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	if refreshToken == nil || refreshToken.ClientID != client.ClientID || refreshToken.Revoked || refreshToken.IsExpired() {
+		return nil, nil
+	}
+
+	return &OAuthIntrospectionResponse{
+		Active:    true,
+		Scope:     strings.Join(refreshToken.Scopes, " "),
+		ClientID:  refreshToken.ClientID,
+		TokenType: OAuthTokenTypeRefresh,
+		Subject:   refreshToken.AccountID,
+		ExpiresAt: refreshToken.ExpiresAt.Unix(),
+		IssuedAt:  refreshToken.CreatedAt.Unix(),
+	}, nil
+}
+
+func (s *oauthService) revokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	tokens, err := s.repository.GetRefreshTokenFamily(ctx, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	for _, token := range tokens {
+		if !token.Revoked {
+			if err := s.repository.RevokeRefreshToken(ctx, token.ID); err != nil {
+				return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+			}
+		}
+
+		if err := s.accountIdentityRepository.DeactivateSession(ctx, token.AccessTokenHash); err != nil {
+			fmt.Printf("Failed to deactivate OAuth session: %v\n", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *oauthService) authenticateClient(ctx context.Context, clientID, clientSecret string) (*OAuthClient, error) {
+	if clientID == "" {
+		return nil, newOAuthError("invalid_client", "client authentication is required")
+	}
+
+	client, err := s.repository.GetClientByClientID(ctx, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	if client == nil {
+		return nil, newOAuthError("invalid_client", "client authentication failed")
+	}
+
+	if client.Confidential {
+		secretHash := hashSecret(clientSecret)
+		if clientSecret == "" || subtle.ConstantTimeCompare([]byte(secretHash), []byte(client.ClientSecretHash)) != 1 {
+			return nil, newOAuthError("invalid_client", "client authentication failed")
+		}
+	}
+
+	return client, nil
+}
+
+func (s *oauthService) validateAuthorizeRequest(ctx context.Context, req *OAuthAuthorizeRequest) (*OAuthClient, string, []string, error) {
+	if req.ResponseType != "code" {
+		return nil, "", nil, fmt.Errorf("invalid request: unsupported response_type %q", req.ResponseType)
+	}
+
+	client, err := s.repository.GetClientByClientID(ctx, req.ClientID)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	if client == nil {
+		return nil, "", nil, fmt.Errorf("OAuth client not found")
+	}
+
+	if !client.AllowsGrant(OAuthGrantAuthorizationCode) {
+		return nil, "", nil, fmt.Errorf("invalid request: client is not allowed to use the authorization_code grant")
+	}
+
+	redirectURI := req.RedirectURI
+	if redirectURI == "" && len(client.RedirectURIs) == 1 {
+		redirectURI = client.RedirectURIs[0]
+	}
+
+	if !client.HasRedirectURI(redirectURI) {
+		return nil, "", nil, fmt.Errorf("invalid request: redirect_uri is not registered for this client")
+	}
+
+	scopes, err := narrowScopes(req.Scope, client.Scopes)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	if req.CodeChallenge == "" && !client.Confidential {
+		return nil, "", nil, fmt.Errorf("invalid request: code_challenge is required for public clients")
+	}
+
+	if req.CodeChallenge != "" && req.CodeChallengeMethod != "S256" {
+		return nil, "", nil, fmt.Errorf("invalid request: code_challenge_method must be S256")
+	}
+
+	return client, redirectURI, scopes, nil
+}
+
+func (s *oauthService) issueAuthorizationCode(
+	ctx context.Context,
+	accountID string,
+	client *OAuthClient,
+	redirectURI string,
+	scopes []string,
+	req *OAuthAuthorizeRequest,
+) (string, error) {
+	code, err := generateRandomToken(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate authorization code: %w", err)
+	}
+
+	_, err = s.repository.CreateAuthorizationCode(ctx, &OAuthAuthorizationCode{
+		CodeHash:            hashSecret(code),
+		ClientID:            client.ClientID,
+		AccountID:           accountID,
+		RedirectURI:         redirectURI,
+		Scopes:              scopes,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		ExpiresAt:           time.Now().Add(OAuthAuthorizationCodeExpiry),
+		CreatedAt:           time.Now(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store authorization code: %w", err)
+	}
+
+	return buildRedirectURI(redirectURI, map[string]string{
+		"code":  code,
+		"state": req.State,
+	}), nil
+}
+
+func (s *oauthService) getActiveAccount(ctx context.Context, accountID string) (*Account, error) {
+	objectID, err := primitive.ObjectIDFromHex(accountID)
+	if err != nil {
+		return nil, newOAuthError("invalid_grant", "account not found")
+	}
+
+	account, err := s.accountRepository.GetByID(ctx, objectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+
+	if account == nil || !account.IsActive {
+		return nil, newOAuthError("invalid_grant", "account is inactive or no longer exists")
+	}
+
+	return account, nil
+}
+
+func validateClientRegistration(req *RegisterOAuthClientRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return fmt.Errorf("invalid client: name is required")
+	}
+
+	if len(req.GrantTypes) == 0 {
+		return fmt.Errorf("invalid client: at least one grant type is required")
+	}
+
+	for _, grantType := range req.GrantTypes {
+		switch grantType {
+		case OAuthGrantAuthorizationCode, OAuthGrantClientCredentials, OAuthGrantRefreshToken:
+		default:
+			return fmt.Errorf("invalid client: unsupported grant type %q", grantType)
+		}
+	}
+
+	if slices.Contains(req.GrantTypes, OAuthGrantClientCredentials) && !req.Confidential {
+		return fmt.Errorf("invalid client: the client_credentials grant requires a confidential client")
+	}
+
+	if slices.Contains(req.GrantTypes, OAuthGrantRefreshToken) && !slices.Contains(req.GrantTypes, OAuthGrantAuthorizationCode) {
+		return fmt.Errorf("invalid client: the refresh_token grant requires the authorization_code grant")
+	}
+
+	if slices.Contains(req.GrantTypes, OAuthGrantAuthorizationCode) && len(req.RedirectURIs) == 0 {
+		return fmt.Errorf("invalid client: at least one redirect URI is required")
+	}
+
+	for _, redirectURI := range req.RedirectURIs {
+		parsed, err := url.Parse(redirectURI)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" || parsed.Fragment != "" {
+			return fmt.Errorf("invalid client: redirect URI %q must be an absolute URI without a fragment", redirectURI)
+		}
+	}
+
+	if len(req.Scopes) == 0 {
+		return fmt.Errorf("invalid client: at least one scope is required")
+	}
+
+	for _, scope := range req.Scopes {
+		if !slices.Contains(SupportedOAuthScopes, scope) {
+			return fmt.Errorf("invalid client: unsupported scope %q", scope)
+		}
+	}
+
+	return nil
+}
+
+// narrowScopes parses a space-delimited scope parameter and checks that it
+// stays within the allowed set. An empty parameter requests every allowed
+// scope.
+func narrowScopes(requested string, allowed []string) ([]string, error) {
+	scopes := strings.Fields(requested)
+	if len(scopes) == 0 {
+		return slices.Clone(allowed), nil
+	}
+
+	for _, scope := range scopes {
+		if !slices.Contains(allowed, scope) {
+			return nil, newOAuthError("invalid_scope", fmt.Sprintf("scope %q is not allowed", scope))
+		}
+	}
+
+	return scopes, nil
+}
+
+func buildRedirectURI(redirectURI string, params map[string]string) string {
+	parsed, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+
+	query := parsed.Query()
+	for key, value := range params {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String()
+}
@@ -0,0 +1,680 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/jwt"
+)
+
+const (
+	testOAuthRedirectURI  = "https://client.example.com/callback"
+	testOAuthClientSecret = "client-secret"
+)
+
+func setupOAuthService(t *testing.T) (*oauthService, *MockOAuthRepository, *MockAccountRepository, *MockAccountIdentityRepository, *jwt.JWTService) {
+	mockOAuthRepo := &MockOAuthRepository{}
+	mockAccountRepo := &MockAccountRepository{}
+	mockIdentityRepo := &MockAccountIdentityRepository{}
+
+	jwtService, err := jwt.NewJWTService(jwt.JWTConfig{
+		SecretKey:     "test-secret-key-for-testing-purposes",
+		TokenDuration: 24 * time.Hour,
+		Issuer:        "test-platform",
+	})
+	require.NoError(t, err)
+
+	service := newOAuthService(mockOAuthRepo, mockAccountRepo, mockIdentityRepo, jwtService)
+
+	return service, mockOAuthRepo, mockAccountRepo, mockIdentityRepo, jwtService
+}
+
+func createTestOAuthClient(overrides ...func(*OAuthClient)) *OAuthClient {
+	client := &OAuthClient{
+		ClientID:         "test-client",
+		ClientSecretHash: hashSecret(testOAuthClientSecret),
+		OwnerAccountID:   "507f1f77bcf86cd799439011",
+		Name:             "Test Client",
+		RedirectURIs:     []string{testOAuthRedirectURI},
+		GrantTypes:       []string{OAuthGrantAuthorizationCode, OAuthGrantRefreshToken, OAuthGrantClientCredentials},
+		Scopes:           []string{OAuthScopeProfile, OAuthScopeKnowledgeRead},
+		Confidential:     true,
+	}
+
+	for _, override := range overrides {
+		override(client)
+	}
+
+	return client
+}
+
+// authorizeTestClient approves an authorization request and returns the code
+// from the redirect together with the stored record.
+func authorizeTestClient(t *testing.T, service *oauthService, mockOAuthRepo *MockOAuthRepository, accountID string, req *OAuthAuthorizeRequest) (string, *OAuthAuthorizationCode) {
+	var stored OAuthAuthorizationCode
+	mockOAuthRepo.On("SaveConsent", mock.Anything, accountID, req.ClientID, mock.Anything).Return(nil).Once()
+	mockOAuthRepo.On("CreateAuthorizationCode", mock.Anything, mock.AnythingOfType("*account.OAuthAuthorizationCode")).
+		Run(func(args mock.Arguments) {
+			stored = *args.Get(1).(*OAuthAuthorizationCode)
+		}).
+		Return(&stored, nil).Once()
+
+	response, err := service.Authorize(context.Background(), accountID, &OAuthConsentRequest{
+		OAuthAuthorizeRequest: *req,
+		Approve:               true,
+	})
+	require.NoError(t, err)
+
+	redirect, err := url.Parse(response.RedirectTo)
+	require.NoError(t, err)
+	assert.Equal(t, req.State, redirect.Query().Get("state"))
+
+	code := redirect.Query().Get("code")
+	require.NotEmpty(t, code)
+	assert.Equal(t, hashSecret(code), stored.CodeHash)
+
+	return code, &stored
+}
+
+func TestOAuthService_AuthorizationCodeWithPKCE(t *testing.T) {
+	service, mockOAuthRepo, mockAccountRepo, mockIdentityRepo, jwtService := setupOAuthService(t)
+	account := CreateTestAccount()
+	client := createTestOAuthClient(func(c *OAuthClient) {
+		c.Confidential = false
+		c.ClientSecretHash = ""
+		c.GrantTypes = []string{OAuthGrantAuthorizationCode, OAuthGrantRefreshToken}
+	})
+	verifier := oauth2.GenerateVerifier()
+
+	mockOAuthRepo.On("GetClientByClientID", mock.Anything, client.ClientID).Return(client, nil)
+
+	code, stored := authorizeTestClient(t, service, mockOAuthRepo, account.ID.Hex(), &OAuthAuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            client.ClientID,
+		RedirectURI:         testOAuthRedirectURI,
+		Scope:               OAuthScopeProfile,
+		State:               "xyz",
+		CodeChallenge:       oauth2.S256ChallengeFromVerifier(verifier),
+		CodeChallengeMethod: "S256",
+	})
+
+	var session Session
+	var refreshToken OAuthRefreshToken
+	mockOAuthRepo.On("ConsumeAuthorizationCode", mock.Anything, hashSecret(code)).Return(stored, nil).Once()
+	mockAccountRepo.On("GetByID", mock.Anything, account.ID).Return(account, nil)
+	mockIdentityRepo.On("CreateSession", mock.Anything, mock.AnythingOfType("*account.Session")).
+		Run(func(args mock.Arguments) {
+			session = *args.Get(1).(*Session)
+		}).
+		Return(&session, nil)
+	mockOAuthRepo.On("CreateRefreshToken", mock.Anything, mock.AnythingOfType("*account.OAuthRefreshToken")).
+		Run(func(args mock.Arguments) {
+			refreshToken = *args.Get(1).(*OAuthRefreshToken)
+		}).
+		Return(&refreshToken, nil)
+
+	response, err := service.Token(context.Background(), &OAuthTokenRequest{
+		GrantType:    OAuthGrantAuthorizationCode,
+		Code:         code,
+		RedirectURI:  testOAuthRedirectURI,
+		CodeVerifier: verifier,
+		ClientID:     client.ClientID,
+	}, "test-agent", "127.0.0.1")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Bearer", response.TokenType)
+	assert.Equal(t, OAuthScopeProfile, response.Scope)
+	assert.NotEmpty(t, response.RefreshToken)
+	assert.Equal(t, hashSecret(response.RefreshToken), refreshToken.TokenHash)
+	assert.Equal(t, hashSecret(response.AccessToken), refreshToken.AccessTokenHash)
+
+	assert.Equal(t, hashSecret(response.AccessToken), session.TokenHash)
+	assert.Equal(t, client.ClientID, session.ClientID)
+	assert.Equal(t, []string{OAuthScopeProfile}, session.Scopes)
+	assert.True(t, session.IsActive)
+
+	claims, err := jwtService.Verify(response.AccessToken)
+	require.NoError(t, err)
+	accountClaims := NewAccountJWTClaimsFromCustom(claims.CustomClaims)
+	assert.Equal(t, account.ID.Hex(), accountClaims.AccountID)
+	assert.Equal(t, client.ClientID, accountClaims.ClientID)
+	assert.Equal(t, []string{OAuthScopeProfile}, accountClaims.Scopes)
+}
+
+func TestOAuthService_ExchangeAuthorizationCode_Rejected(t *testing.T) {
+	accountID := "507f1f77bcf86cd799439011"
+	verifier := oauth2.GenerateVerifier()
+
+	tests := []struct {
+		name         string
+		code         *OAuthAuthorizationCode
+		codeVerifier string
+		redirectURI  string
+	}{
+		{
+			name:         "unknown code",
+			codeVerifier: verifier,
+			redirectURI:  testOAuthRedirectURI,
+		},
+		{
+			name: "expired code",
+			code: &OAuthAuthorizationCode{
+				ClientID:      "test-client",
+				AccountID:     accountID,
+				RedirectURI:   testOAuthRedirectURI,
+				CodeChallenge: oauth2.S256ChallengeFromVerifier(verifier),
+				ExpiresAt:     time.Now().Add(-time.Minute),
+			},
+			codeVerifier: verifier,
+			redirectURI:  testOAuthRedirectURI,
+		},
+		{
+			name: "code issued to another client",
+			code: &OAuthAuthorizationCode{
+				ClientID:    "other-client",
+				AccountID:   accountID,
+				RedirectURI: testOAuthRedirectURI,
+				ExpiresAt:   time.Now().Add(time.Minute),
+			},
+			redirectURI: testOAuthRedirectURI,
+		},
+		{
+			name: "redirect URI mismatch",
+			code: &OAuthAuthorizationCode{
+				ClientID:    "test-client",
+				AccountID:   accountID,
+				RedirectURI: testOAuthRedirectURI,
+				ExpiresAt:   time.Now().Add(time.Minute),
+			},
+			redirectURI: "https://attacker.example.com/callback",
+		},
+		{
+			name: "wrong code verifier",
+			code: &OAuthAuthorizationCode{
+				ClientID:      "test-client",
+				AccountID:     accountID,
+				RedirectURI:   testOAuthRedirectURI,
+				CodeChallenge: oauth2.S256ChallengeFromVerifier(verifier),
+				ExpiresAt:     time.Now().Add(time.Minute),
+			},
+			codeVerifier: oauth2.GenerateVerifier(),
+			redirectURI:  testOAuthRedirectURI,
+		},
+		{
+			name: "missing code verifier",
+			code: &OAuthAuthorizationCode{
+				ClientID:      "test-client",
+				AccountID:     accountID,
+				RedirectURI:   testOAuthRedirectURI,
+				CodeChallenge: oauth2.S256ChallengeFromVerifier(verifier),
+				ExpiresAt:     time.Now().Add(time.Minute),
+			},
+			redirectURI: testOAuthRedirectURI,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockOAuthRepo, _, _, _ := setupOAuthService(t)
+			mockOAuthRepo.On("GetClientByClientID", mock.Anything, "test-client").Return(createTestOAuthClient(), nil)
+			if tt.code == nil {
+				mockOAuthRepo.On("ConsumeAuthorizationCode", mock.Anything, hashSecret("the-code")).Return(nil, nil)
+			} else {
+				mockOAuthRepo.On("ConsumeAuthorizationCode", mock.Anything, hashSecret("the-code")).Return(tt.code, nil)
+			}
+
+			_, err := service.Token(context.Background(), &OAuthTokenRequest{
+				GrantType:    OAuthGrantAuthorizationCode,
+				Code:         "the-code",
+				RedirectURI:  tt.redirectURI,
+				CodeVerifier: tt.codeVerifier,
+				ClientID:     "test-client",
+				ClientSecret: testOAuthClientSecret,
+			}, "", "")
+
+			var oauthErr *OAuthError
+			require.True(t, errors.As(err, &oauthErr))
+			assert.Equal(t, "invalid_grant", oauthErr.Code)
+			mockOAuthRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOAuthService_Authorize_Validation(t *testing.T) {
+	tests := []struct {
+		name          string
+		client        *OAuthClient
+		req           OAuthAuthorizeRequest
+		errorContains string
+	}{
+		{
+			name:   "unregistered redirect URI",
+			client: createTestOAuthClient(),
+			req: OAuthAuthorizeRequest{
+				ResponseType: "code",
+				ClientID:     "test-client",
+				RedirectURI:  "https://attacker.example.com/callback",
+			},
+			errorContains: "redirect_uri is not registered",
+		},
+		{
+			name:   "scope outside the client registration",
+			client: createTestOAuthClient(),
+			req: OAuthAuthorizeRequest{
+				ResponseType: "code",
+				ClientID:     "test-client",
+				Scope:        OAuthScopeDocumentsWrite,
+			},
+			errorContains: "invalid_scope",
+		},
+		{
+			name:   "public client without PKCE",
+			client: createTestOAuthClient(func(c *OAuthClient) { c.Confidential = false }),
+			req: OAuthAuthorizeRequest{
+				ResponseType: "code",
+				ClientID:     "test-client",
+			},
+			errorContains: "code_challenge is required",
+		},
+		{
+			name:   "plain PKCE method",
+			client: createTestOAuthClient(),
+			req: OAuthAuthorizeRequest{
+				ResponseType:        "code",
+				ClientID:            "test-client",
+				CodeChallenge:       "challenge",
+				CodeChallengeMethod: "plain",
+			},
+			errorContains: "must be S256",
+		},
+		{
+			name:   "unsupported response type",
+			client: createTestOAuthClient(),
+			req: OAuthAuthorizeRequest{
+				ResponseType: "token",
+				ClientID:     "test-client",
+			},
+			errorContains: "unsupported response_type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockOAuthRepo, _, _, _ := setupOAuthService(t)
+			mockOAuthRepo.On("GetClientByClientID", mock.Anything, "test-client").Return(tt.client, nil)
+
+			_, err := service.PrepareAuthorization(context.Background(), "507f1f77bcf86cd799439011", &tt.req)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorContains)
+		})
+	}
+}
+
+func TestOAuthService_PrepareAuthorization_ExistingConsent(t *testing.T) {
+	service, mockOAuthRepo, _, _, _ := setupOAuthService(t)
+	client := createTestOAuthClient()
+	accountID := "507f1f77bcf86cd799439011"
+
+	mockOAuthRepo.On("GetClientByClientID", mock.Anything, client.ClientID).Return(client, nil)
+	mockOAuthRepo.On("GetConsent", mock.Anything, accountID, client.ClientID).Return(&OAuthConsent{
+		Scopes: []string{OAuthScopeProfile, OAuthScopeKnowledgeRead},
+	}, nil)
+	mockOAuthRepo.On("CreateAuthorizationCode", mock.Anything, mock.AnythingOfType("*account.OAuthAuthorizationCode")).Return(&OAuthAuthorizationCode{}, nil)
+
+	response, err := service.PrepareAuthorization(context.Background(), accountID, &OAuthAuthorizeRequest{
+		ResponseType: "code",
+		ClientID:     client.ClientID,
+		Scope:        OAuthScopeKnowledgeRead,
+	})
+	require.NoError(t, err)
+
+	assert.False(t, response.ConsentRequired)
+	assert.Contains(t, response.RedirectTo, testOAuthRedirectURI+"?code=")
+}
+
+func TestOAuthService_Authorize_Denied(t *testing.T) {
+	service, mockOAuthRepo, _, _, _ := setupOAuthService(t)
+	client := createTestOAuthClient()
+
+	mockOAuthRepo.On("GetClientByClientID", mock.Anything, client.ClientID).Return(client, nil)
+
+	response, err := service.Authorize(context.Background(), "507f1f77bcf86cd799439011", &OAuthConsentRequest{
+		OAuthAuthorizeRequest: OAuthAuthorizeRequest{
+			ResponseType: "code",
+			ClientID:     client.ClientID,
+			State:        "xyz",
+		},
+		Approve: false,
+	})
+	require.NoError(t, err)
+
+	redirect, err := url.Parse(response.RedirectTo)
+	require.NoError(t, err)
+	assert.Equal(t, "access_denied", redirect.Query().Get("error"))
+	assert.Equal(t, "xyz", redirect.Query().Get("state"))
+	mockOAuthRepo.AssertNotCalled(t, "SaveConsent", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOAuthService_ClientCredentials(t *testing.T) {
+	tests := []struct {
+		name         string
+		clientSecret string
+		scope        string
+		expectedCode string
+	}{
+		{
+			name:         "valid credentials",
+			clientSecret: testOAuthClientSecret,
+			scope:        OAuthScopeKnowledgeRead,
+		},
+		{
+			name:         "wrong secret",
+			clientSecret: "wrong",
+			expectedCode: "invalid_client",
+		},
+		{
+			name:         "scope outside the registration",
+			clientSecret: testOAuthClientSecret,
+			scope:        OAuthScopeDocumentsWrite,
+			expectedCode: "invalid_scope",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockOAuthRepo, mockAccountRepo, mockIdentityRepo, _ := setupOAuthService(t)
+			client := createTestOAuthClient()
+			owner := CreateTestAccount()
+			client.OwnerAccountID = owner.ID.Hex()
+
+			mockOAuthRepo.On("GetClientByClientID", mock.Anything, client.ClientID).Return(client, nil)
+			mockAccountRepo.On("GetByID", mock.Anything, owner.ID).Return(owner, nil)
+			mockIdentityRepo.On("CreateSession", mock.Anything, mock.AnythingOfType("*account.Session")).Return(&Session{}, nil)
+
+			response, err := service.Token(context.Background(), &OAuthTokenRequest{
+				GrantType:    OAuthGrantClientCredentials,
+				Scope:        tt.scope,
+				ClientID:     client.ClientID,
+				ClientSecret: tt.clientSecret,
+			}, "", "")
+
+			if tt.expectedCode != "" {
+				var oauthErr *OAuthError
+				require.True(t, errors.As(err, &oauthErr))
+				assert.Equal(t, tt.expectedCode, oauthErr.Code)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.scope, response.Scope)
+			assert.Empty(t, response.RefreshToken)
+			mockOAuthRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestOAuthService_RefreshToken_Rotation(t *testing.T) {
+	service, mockOAuthRepo, mockAccountRepo, mockIdentityRepo, _ := setupOAuthService(t)
+	client := createTestOAuthClient()
+	account := CreateTestAccount()
+
+	current := &OAuthRefreshToken{
+		TokenHash: hashSecret("refresh-1"),
+		FamilyID:  "family-1",
+		ClientID:  client.ClientID,
+		AccountID: account.ID.Hex(),
+		Scopes:    []string{OAuthScopeProfile, OAuthScopeKnowledgeRead},
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	var rotated OAuthRefreshToken
+	mockOAuthRepo.On("GetClientByClientID", mock.Anything, client.ClientID).Return(client, nil)
+	mockOAuthRepo.On("GetRefreshToken", mock.Anything, hashSecret("refresh-1")).Return(current, nil)
+	mockOAuthRepo.On("ConsumeRefreshToken", mock.Anything, current.ID).Return(current, nil).Once()
+	mockAccountRepo.On("GetByID", mock.Anything, account.ID).Return(account, nil)
+	mockIdentityRepo.On("CreateSession", mock.Anything, mock.AnythingOfType("*account.Session")).Return(&Session{}, nil)
+	mockOAuthRepo.On("CreateRefreshToken", mock.Anything, mock.AnythingOfType("*account.OAuthRefreshToken")).
+		Run(func(args mock.Arguments) {
+			rotated = *args.Get(1).(*OAuthRefreshToken)
+		}).
+		Return(&rotated, nil)
+
+	response, err := service.Token(context.Background(), &OAuthTokenRequest{
+		GrantType:    OAuthGrantRefreshToken,
+		RefreshToken: "refresh-1",
+		Scope:        OAuthScopeProfile,
+		ClientID:     client.ClientID,
+		ClientSecret: testOAuthClientSecret,
+	}, "", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, OAuthScopeProfile, response.Scope)
+	assert.NotEqual(t, "refresh-1", response.RefreshToken)
+	assert.Equal(t, "family-1", rotated.FamilyID)
+	assert.Equal(t, []string{OAuthScopeProfile}, rotated.Scopes)
+	mockOAuthRepo.AssertExpectations(t)
+}
+
+func TestOAuthService_RefreshToken_ReuseRevokesFamily(t *testing.T) {
+	service, mockOAuthRepo, _, mockIdentityRepo, _ := setupOAuthService(t)
+	client := createTestOAuthClient()
+
+	replayed := &OAuthRefreshToken{
+		TokenHash:       hashSecret("refresh-1"),
+		FamilyID:        "family-1",
+		ClientID:        client.ClientID,
+		AccessTokenHash: "access-1",
+		Revoked:         true,
+		ExpiresAt:       time.Now().Add(time.Hour),
+	}
+	latest := &OAuthRefreshToken{
+		TokenHash:       hashSecret("refresh-2"),
+		FamilyID:        "family-1",
+		ClientID:        client.ClientID,
+		AccessTokenHash: "access-2",
+		ExpiresAt:       time.Now().Add(time.Hour),
+	}
+
+	mockOAuthRepo.On("GetClientByClientID", mock.Anything, client.ClientID).Return(client, nil)
+	mockOAuthRepo.On("GetRefreshToken", mock.Anything, hashSecret("refresh-1")).Return(replayed, nil)
+	mockOAuthRepo.On("GetRefreshTokenFamily", mock.Anything, "family-1").Return([]*OAuthRefreshToken{replayed, latest}, nil)
+	mockOAuthRepo.On("RevokeRefreshToken", mock.Anything, latest.ID).Return(nil).Once()
+	mockIdentityRepo.On("DeactivateSession", mock.Anything, "access-1").Return(nil)
+	mockIdentityRepo.On("DeactivateSession", mock.Anything, "access-2").Return(nil)
+
+	_, err := service.Token(context.Background(), &OAuthTokenRequest{
+		GrantType:    OAuthGrantRefreshToken,
+		RefreshToken: "refresh-1",
+		ClientID:     client.ClientID,
+		ClientSecret: testOAuthClientSecret,
+	}, "", "")
+
+	var oauthErr *OAuthError
+	require.True(t, errors.As(err, &oauthErr))
+	assert.Equal(t, "invalid_grant", oauthErr.Code)
+	mockOAuthRepo.AssertExpectations(t)
+	mockIdentityRepo.AssertExpectations(t)
+}
+
+func TestOAuthService_RefreshToken_ConcurrentUseRevokesFamily(t *testing.T) {
+	service, mockOAuthRepo, _, mockIdentityRepo, _ := setupOAuthService(t)
+	client := createTestOAuthClient()
+
+	current := &OAuthRefreshToken{
+		TokenHash:       hashSecret("refresh-1"),
+		FamilyID:        "family-1",
+		ClientID:        client.ClientID,
+		AccessTokenHash: "access-1",
+		ExpiresAt:       time.Now().Add(time.Hour),
+	}
+	rotated := &OAuthRefreshToken{
+		TokenHash:       hashSecret("refresh-2"),
+		FamilyID:        "family-1",
+		ClientID:        client.ClientID,
+		AccessTokenHash: "access-2",
+		ExpiresAt:       time.Now().Add(time.Hour),
+	}
+
+	// The token is read as unrevoked, but another request rotates it first.
+	mockOAuthRepo.On("GetClientByClientID", mock.Anything, client.ClientID).Return(client, nil)
+	mockOAuthRepo.On("GetRefreshToken", mock.Anything, hashSecret("refresh-1")).Return(current, nil)
+	mockOAuthRepo.On("ConsumeRefreshToken", mock.Anything, current.ID).Return(nil, nil)
+	mockOAuthRepo.On("GetRefreshTokenFamily", mock.Anything, "family-1").Return([]*OAuthRefreshToken{current, rotated}, nil)
+	mockOAuthRepo.On("RevokeRefreshToken", mock.Anything, current.ID).Return(nil)
+	mockIdentityRepo.On("DeactivateSession", mock.Anything, "access-1").Return(nil)
+	mockIdentityRepo.On("DeactivateSession", mock.Anything, "access-2").Return(nil)
+
+	_, err := service.Token(context.Background(), &OAuthTokenRequest{
+		GrantType:    OAuthGrantRefreshToken,
+		RefreshToken: "refresh-1",
+		ClientID:     client.ClientID,
+		ClientSecret: testOAuthClientSecret,
+	}, "", "")
+
+	var oauthErr *OAuthError
+	require.True(t, errors.As(err, &oauthErr))
+	assert.Equal(t, "invalid_grant", oauthErr.Code)
+	mockOAuthRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything, mock.Anything)
+	mockIdentityRepo.AssertExpectations(t)
+}
+
+func TestOAuthService_Introspect(t *testing.T) {
+	service, mockOAuthRepo, mockAccountRepo, mockIdentityRepo, jwtService := setupOAuthService(t)
+	client := createTestOAuthClient()
+	account := CreateTestAccount()
+
+	issue := func(clientID string) string {
+		claims := &AccountJWTClaims{
+			AccountID: account.ID.Hex(),
+			Username:  account.Username,
+			ClientID:  clientID,
+			Scopes:    []string{OAuthScopeProfile},
+		}
+		token, err := jwtService.GenerateWithDuration(claims.ToCustomClaims(), OAuthAccessTokenExpiry)
+		require.NoError(t, err)
+		return token
+	}
+
+	ownToken := issue(client.ClientID)
+	foreignToken := issue("other-client")
+
+	mockOAuthRepo.On("GetClientByClientID", mock.Anything, client.ClientID).Return(client, nil)
+	mockOAuthRepo.On("GetRefreshToken", mock.Anything, mock.Anything).Return(nil, nil)
+	mockIdentityRepo.On("GetSessionByToken", mock.Anything, hashSecret(ownToken)).Return(&Session{
+		IsActive:  true,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	mockAccountRepo.On("GetByID", mock.Anything, account.ID).Return(account, nil)
+
+	response, err := service.Introspect(context.Background(), &OAuthTokenActionRequest{
+		Token:        ownToken,
+		ClientID:     client.ClientID,
+		ClientSecret: testOAuthClientSecret,
+	})
+	require.NoError(t, err)
+	assert.True(t, response.Active)
+	assert.Equal(t, OAuthScopeProfile, response.Scope)
+	assert.Equal(t, account.ID.Hex(), response.Subject)
+	assert.Equal(t, client.ClientID, response.ClientID)
+
+	response, err = service.Introspect(context.Background(), &OAuthTokenActionRequest{
+		Token:        foreignToken,
+		ClientID:     client.ClientID,
+		ClientSecret: testOAuthClientSecret,
+	})
+	require.NoError(t, err)
+	assert.False(t, response.Active)
+	assert.Empty(t, response.Subject)
+}
+
+func TestOAuthService_RegisterClient(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           RegisterOAuthClientRequest
+		errorContains string
+	}{
+		{
+			name: "confidential client",
+			req: RegisterOAuthClientRequest{
+				Name:         "Reporting",
+				RedirectURIs: []string{testOAuthRedirectURI},
+				GrantTypes:   []string{OAuthGrantAuthorizationCode, OAuthGrantRefreshToken},
+				Scopes:       []string{OAuthScopeProfile},
+				Confidential: true,
+			},
+		},
+		{
+			name: "public client with client credentials",
+			req: RegisterOAuthClientRequest{
+				Name:       "Mobile",
+				GrantTypes: []string{OAuthGrantClientCredentials},
+				Scopes:     []string{OAuthScopeProfile},
+			},
+			errorContains: "requires a confidential client",
+		},
+		{
+			name: "missing redirect URI",
+			req: RegisterOAuthClientRequest{
+				Name:       "Web",
+				GrantTypes: []string{OAuthGrantAuthorizationCode},
+				Scopes:     []string{OAuthScopeProfile},
+			},
+			errorContains: "at least one redirect URI",
+		},
+		{
+			name: "relative redirect URI",
+			req: RegisterOAuthClientRequest{
+				Name:         "Web",
+				RedirectURIs: []string{"/callback"},
+				GrantTypes:   []string{OAuthGrantAuthorizationCode},
+				Scopes:       []string{OAuthScopeProfile},
+			},
+			errorContains: "must be an absolute URI",
+		},
+		{
+			name: "unsupported scope",
+			req: RegisterOAuthClientRequest{
+				Name:         "Web",
+				RedirectURIs: []string{testOAuthRedirectURI},
+				GrantTypes:   []string{OAuthGrantAuthorizationCode},
+				Scopes:       []string{"admin"},
+			},
+			errorContains: "unsupported scope",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockOAuthRepo, _, _, _ := setupOAuthService(t)
+
+			var stored OAuthClient
+			mockOAuthRepo.On("CreateClient", mock.Anything, mock.AnythingOfType("*account.OAuthClient")).
+				Run(func(args mock.Arguments) {
+					stored = *args.Get(1).(*OAuthClient)
+				}).
+				Return(&stored, nil)
+
+			response, err := service.RegisterClient(context.Background(), "507f1f77bcf86cd799439011", &tt.req)
+
+			if tt.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				mockOAuthRepo.AssertNotCalled(t, "CreateClient", mock.Anything, mock.Anything)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotEmpty(t, response.ClientID)
+			assert.NotEmpty(t, response.ClientSecret)
+			assert.Equal(t, hashSecret(response.ClientSecret), stored.ClientSecretHash)
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package account
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/binding"
+)
+
+type PasskeyHandler struct {
+	service PasskeyService
+}
+
+func NewPasskeyHandler(service PasskeyService) *PasskeyHandler {
+	return &PasskeyHandler{
+		service: service,
+	}
+}
+
+// BeginRegistration godoc
+// @Summary Begin passkey registration
+// @Description Start a WebAuthn registration ceremony for the authenticated account and return the credential creation options
+// @Tags passkeys
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Registration options generated"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Account not found"
+// @Router /accounts/passkeys/register/begin [post]
+func (h *PasskeyHandler) BeginRegistration(c *fiber.Ctx) error {
+	accountID, ok := c.Locals("account_id").(string)
+	if !ok || accountID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   "Unauthorized",
+			"message": "Account ID not found in context",
+		})
+	}
+
+	response, err := h.service.BeginRegistration(c.Context(), accountID)
+	if err != nil {
+		statusCode := fiber.StatusInternalServerError
+		if strings.Contains(err.Error(), "not found") {
+			statusCode = fiber.StatusNotFound
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"error":   "Failed to begin passkey registration",
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Registration options generated",
+		"data":    response,
+	})
+}
+
+// FinishRegistration godoc
+// @Summary Finish passkey registration
+// @Description Verify the authenticator attestation response and store the new passkey
+// @Tags passkeys
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body PasskeyRegistrationFinishRequest true "Ceremony ID and attestation response"
+// @Success 201 {object} map[string]interface{} "Passkey registered successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request - invalid or expired ceremony"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 409 {object} map[string]interface{} "Conflict - passkey already registered"
+// @Router /accounts/passkeys/register/finish [post]
+func (h *PasskeyHandler) FinishRegistration(c *fiber.Ctx) error {
+	var req PasskeyRegistrationFinishRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	accountID, ok := c.Locals("account_id").(string)
+	if !ok || accountID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   "Unauthorized",
+			"message": "Account ID not found in context",
+		})
+	}
+
+	response, err := h.service.FinishRegistration(c.Context(), accountID, &req)
+	if err != nil {
+		statusCode := fiber.StatusInternalServerError
+		if strings.Contains(err.Error(), "already exists") {
+			statusCode = fiber.StatusConflict
+		} else if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "expired") {
+			statusCode = fiber.StatusBadRequest
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"error":   "Passkey registration failed",
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Passkey registered successfully",
+		"data":    response,
+	})
+}
+
+// ListPasskeys godoc
+// @Summary List passkeys
+// @Description List the passkeys registered to the authenticated account
+// @Tags passkeys
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Passkeys retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /accounts/passkeys [get]
+func (h *PasskeyHandler) ListPasskeys(c *fiber.Ctx) error {
+	accountID, ok := c.Locals("account_id").(string)
+	if !ok || accountID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   "Unauthorized",
+			"message": "Account ID not found in context",
+		})
+	}
+
+	passkeys, err := h.service.ListPasskeys(c.Context(), accountID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to list passkeys",
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Passkeys retrieved successfully",
+		"data":    passkeys,
+	})
+}
+
+// DeletePasskey godoc
+// @Summary Delete passkey
+// @Description Remove a passkey from the authenticated account
+// @Tags passkeys
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param passkeyId path string true "Passkey ID"
+// @Success 200 {object} map[string]interface{} "Passkey deleted successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Passkey not found"
+// @Router /accounts/passkeys/{passkeyId} [delete]
+func (h *PasskeyHandler) DeletePasskey(c *fiber.Ctx) error {
+	accountID, ok := c.Locals("account_id").(string)
+	if !ok || accountID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   "Unauthorized",
+			"message": "Account ID not found in context",
+		})
+	}
+
+	err := h.service.DeletePasskey(c.Context(), accountID, c.Params("passkeyId"))
+	if err != nil {
+		statusCode := fiber.StatusInternalServerError
+		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "invalid") {
+			statusCode = fiber.StatusNotFound
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"error":   "Failed to delete passkey",
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Passkey deleted successfully",
+	})
+}
+
+// BeginLogin godoc
+// @Summary Begin passkey login
+// @Description Start a WebAuthn assertion ceremony. Omit the email to allow any discoverable passkey. An email that is unknown or has no passkeys gets the same discoverable challenge.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body PasskeyLoginBeginRequest false "Optional email to restrict allowed credentials"
+// @Success 200 {object} map[string]interface{} "Login options generated"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 429 {object} map[string]interface{} "Too many requests"
+// @Router /accounts/login/passkey/begin [post]
+func (h *PasskeyHandler) BeginLogin(c *fiber.Ctx) error {
+	var req PasskeyLoginBeginRequest
+	if err := binding.OptionalBody(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	response, err := h.service.BeginLogin(c.Context(), &req)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to begin passkey login",
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Login options generated",
+		"data":    response,
+	})
+}
+
+// FinishLogin godoc
+// @Summary Finish passkey login
+// @Description Verify the authenticator assertion response and create a session
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body PasskeyLoginFinishRequest true "Ceremony ID and assertion response"
+// @Success 200 {object} map[string]interface{} "Login successful"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized - invalid assertion"
+// @Failure 403 {object} map[string]interface{} "Forbidden - account inactive or passkey disabled"
+// @Failure 429 {object} map[string]interface{} "Too many requests"
+// @Router /accounts/login/passkey/finish [post]
+func (h *PasskeyHandler) FinishLogin(c *fiber.Ctx) error {
+	var req PasskeyLoginFinishRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	userAgent := c.Get("User-Agent")
+	ipAddress := c.IP()
+
+	response, err := h.service.FinishLogin(c.Context(), &req, userAgent, ipAddress)
+	if err != nil {
+		statusCode := fiber.StatusUnauthorized
+		if strings.Contains(err.Error(), "inactive") || strings.Contains(err.Error(), "cloned") {
+			statusCode = fiber.StatusForbidden
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"error":   "Login failed",
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Login successful",
+		"data":    response,
+	})
+}
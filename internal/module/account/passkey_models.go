@@ -0,0 +1,178 @@
+package account
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PasskeyCeremonyPurpose string
+
+const (
+	PasskeyCeremonyRegistration PasskeyCeremonyPurpose = "registration"
+	PasskeyCeremonyLogin        PasskeyCeremonyPurpose = "login"
+)
+
+const PasskeyCeremonyExpiry = 5 * time.Minute
+
+type PasskeyConfig struct {
+	RPID          string   `json:"rp_id"`
+	RPDisplayName string   `json:"rp_display_name"`
+	RPOrigins     []string `json:"rp_origins"`
+}
+
+type PasskeyCredential struct {
+	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	AccountID       string             `json:"account_id" bson:"account_id"`
+	Name            string             `json:"name" bson:"name"`
+	CredentialID    []byte             `json:"credential_id" bson:"credential_id"`
+	PublicKey       []byte             `json:"-" bson:"public_key"`
+	AttestationType string             `json:"attestation_type" bson:"attestation_type"`
+	Transports      []string           `json:"transports" bson:"transports"`
+	AAGUID          []byte             `json:"aaguid" bson:"aaguid"`
+	SignCount       uint32             `json:"sign_count" bson:"sign_count"`
+	UserVerified    bool               `json:"user_verified" bson:"user_verified"`
+	BackupEligible  bool               `json:"backup_eligible" bson:"backup_eligible"`
+	BackupState     bool               `json:"backup_state" bson:"backup_state"`
+	CloneWarning    bool               `json:"clone_warning" bson:"clone_warning"`
+	CreatedAt       time.Time          `json:"created_at" bson:"created_at"`
+	LastUsedAt      time.Time          `json:"last_used_at" bson:"last_used_at"`
+}
+
+type PasskeyCeremony struct {
+	ID          primitive.ObjectID     `json:"id" bson:"_id,omitempty"`
+	AccountID   string                 `json:"account_id" bson:"account_id"`
+	Purpose     PasskeyCeremonyPurpose `json:"purpose" bson:"purpose"`
+	SessionData string                 `json:"-" bson:"session_data"`
+	ExpiresAt   time.Time              `json:"expires_at" bson:"expires_at"`
+	CreatedAt   time.Time              `json:"created_at" bson:"created_at"`
+}
+
+type PasskeyRegistrationFinishRequest struct {
+	CeremonyID string          `json:"ceremony_id" validate:"required"`
+	Name       string          `json:"name" validate:"max=100"`
+	Credential json.RawMessage `json:"credential" validate:"required" swaggertype:"object"`
+}
+
+type PasskeyLoginBeginRequest struct {
+	Email string `json:"email,omitempty" validate:"omitempty,email"`
+}
+
+type PasskeyLoginFinishRequest struct {
+	CeremonyID string          `json:"ceremony_id" validate:"required"`
+	Credential json.RawMessage `json:"credential" validate:"required" swaggertype:"object"`
+}
+
+type PasskeyCeremonyResponse struct {
+	CeremonyID string `json:"ceremony_id"`
+	Options    any    `json:"options"`
+}
+
+type PasskeyResponse struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Transports   []string  `json:"transports"`
+	BackupState  bool      `json:"backup_state"`
+	CloneWarning bool      `json:"clone_warning"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastUsedAt   time.Time `json:"last_used_at"`
+}
+
+func (c *PasskeyCeremony) IsExpired() bool {
+	return time.Now().After(c.ExpiresAt)
+}
+
+func (p *PasskeyCredential) ToResponse() *PasskeyResponse {
+	return &PasskeyResponse{
+		ID:           p.ID.Hex(),
+		Name:         p.Name,
+		Transports:   p.Transports,
+		BackupState:  p.BackupState,
+		CloneWarning: p.CloneWarning,
+		CreatedAt:    p.CreatedAt,
+		LastUsedAt:   p.LastUsedAt,
+	}
+}
+
+func (p *PasskeyCredential) ToWebAuthnCredential() webauthn.Credential {
+	transports := make([]protocol.AuthenticatorTransport, len(p.Transports))
+	for i, transport := range p.Transports {
+		transports[i] = protocol.AuthenticatorTransport(transport)
+	}
+
+	return webauthn.Credential{
+		ID:              p.CredentialID,
+		PublicKey:       p.PublicKey,
+		AttestationType: p.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			UserPresent:    true,
+			UserVerified:   p.UserVerified,
+			BackupEligible: p.BackupEligible,
+			BackupState:    p.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:       p.AAGUID,
+			SignCount:    p.SignCount,
+			CloneWarning: p.CloneWarning,
+		},
+	}
+}
+
+func NewPasskeyCredential(accountID, name string, credential *webauthn.Credential) *PasskeyCredential {
+	transports := make([]string, len(credential.Transport))
+	for i, transport := range credential.Transport {
+		transports[i] = string(transport)
+	}
+
+	now := time.Now()
+
+	return &PasskeyCredential{
+		AccountID:       accountID,
+		Name:            name,
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transports:      transports,
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       credential.Authenticator.SignCount,
+		UserVerified:    credential.Flags.UserVerified,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+		CreatedAt:       now,
+		LastUsedAt:      now,
+	}
+}
+
+// passkeyUser adapts an Account and its stored credentials to webauthn.User.
+// The WebAuthn user handle is the raw 12-byte account ObjectID.
+type passkeyUser struct {
+	account     *Account
+	credentials []*PasskeyCredential
+}
+
+func (u *passkeyUser) WebAuthnID() []byte {
+	return u.account.ID[:]
+}
+
+func (u *passkeyUser) WebAuthnName() string {
+	return u.account.Email
+}
+
+func (u *passkeyUser) WebAuthnDisplayName() string {
+	if u.account.FirstName == "" && u.account.LastName == "" {
+		return u.account.Username
+	}
+	return u.account.FirstName + " " + u.account.LastName
+}
+
+func (u *passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, len(u.credentials))
+	for i, credential := range u.credentials {
+		credentials[i] = credential.ToWebAuthnCredential()
+	}
+	return credentials
+}
@@ -0,0 +1,159 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+const (
+	PasskeyCollectionName         = "passkeys"
+	PasskeyCeremonyCollectionName = "passkey_ceremonies"
+)
+
+type PasskeyRepository interface {
+	CreatePasskey(ctx context.Context, passkey *PasskeyCredential) (*PasskeyCredential, error)
+	GetPasskeyByCredentialID(ctx context.Context, credentialID []byte) (*PasskeyCredential, error)
+	GetPasskeysByAccountID(ctx context.Context, accountID string) ([]*PasskeyCredential, error)
+	UpdatePasskeyUsage(ctx context.Context, id primitive.ObjectID, signCount uint32, backupState bool) error
+	FlagPasskeyCloned(ctx context.Context, id primitive.ObjectID) error
+	DeletePasskey(ctx context.Context, accountID string, id primitive.ObjectID) error
+
+	CreateCeremony(ctx context.Context, ceremony *PasskeyCeremony) (*PasskeyCeremony, error)
+	ConsumeCeremony(ctx context.Context, id primitive.ObjectID) (*PasskeyCeremony, error)
+}
+
+type passkeyRepository struct {
+	passkeyRepo  mongo.Repository[PasskeyCredential]
+	ceremonyRepo mongo.Repository[PasskeyCeremony]
+}
+
+var _ PasskeyRepository = (*passkeyRepository)(nil)
+
+func NewPasskeyRepository(mongoService *mongo.MongoService) PasskeyRepository {
+	return &passkeyRepository{
+		passkeyRepo:  mongo.NewRepository[PasskeyCredential](mongoService, PasskeyCollectionName),
+		ceremonyRepo: mongo.NewRepository[PasskeyCeremony](mongoService, PasskeyCeremonyCollectionName),
+	}
+}
+
+func (r *passkeyRepository) CreatePasskey(ctx context.Context, passkey *PasskeyCredential) (*PasskeyCredential, error) {
+	passkey.ID = primitive.NewObjectID()
+
+	result, err := r.passkeyRepo.Create(ctx, *passkey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create passkey: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *passkeyRepository) GetPasskeyByCredentialID(ctx context.Context, credentialID []byte) (*PasskeyCredential, error) {
+	filter := bson.M{"credential_id": credentialID}
+
+	result, err := r.passkeyRepo.FindOne(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get passkey: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *passkeyRepository) GetPasskeysByAccountID(ctx context.Context, accountID string) ([]*PasskeyCredential, error) {
+	filter := bson.M{"account_id": accountID}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	results, err := r.passkeyRepo.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get passkeys: %w", err)
+	}
+
+	passkeys := make([]*PasskeyCredential, len(results))
+	for i := range results {
+		passkeys[i] = &results[i]
+	}
+
+	return passkeys, nil
+}
+
+func (r *passkeyRepository) UpdatePasskeyUsage(ctx context.Context, id primitive.ObjectID, signCount uint32, backupState bool) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{
+		"$set": bson.M{
+			"sign_count":   signCount,
+			"backup_state": backupState,
+			"last_used_at": time.Now(),
+		},
+	}
+
+	_, err := r.passkeyRepo.Update(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update passkey usage: %w", err)
+	}
+
+	return nil
+}
+
+func (r *passkeyRepository) FlagPasskeyCloned(ctx context.Context, id primitive.ObjectID) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{"clone_warning": true}}
+
+	_, err := r.passkeyRepo.Update(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to flag passkey: %w", err)
+	}
+
+	return nil
+}
+
+func (r *passkeyRepository) DeletePasskey(ctx context.Context, accountID string, id primitive.ObjectID) error {
+	filter := bson.M{
+		"_id":        id,
+		"account_id": accountID,
+	}
+
+	err := r.passkeyRepo.Delete(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to delete passkey: %w", err)
+	}
+
+	return nil
+}
+
+func (r *passkeyRepository) CreateCeremony(ctx context.Context, ceremony *PasskeyCeremony) (*PasskeyCeremony, error) {
+	ceremony.ID = primitive.NewObjectID()
+
+	result, err := r.ceremonyRepo.Create(ctx, *ceremony)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create passkey ceremony: %w", err)
+	}
+
+	return result, nil
+}
+
+// ConsumeCeremony loads and removes a ceremony so that each challenge can be
+// answered at most once.
+func (r *passkeyRepository) ConsumeCeremony(ctx context.Context, id primitive.ObjectID) (*PasskeyCeremony, error) {
+	filter := bson.M{"_id": id}
+
+	ceremony, err := r.ceremonyRepo.FindOne(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get passkey ceremony: %w", err)
+	}
+
+	if ceremony == nil {
+		return nil, nil
+	}
+
+	if err := r.ceremonyRepo.Delete(ctx, filter); err != nil {
+		return nil, fmt.Errorf("failed to consume passkey ceremony: %w", err)
+	}
+
+	return ceremony, nil
+}
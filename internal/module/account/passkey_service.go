@@ -0,0 +1,375 @@
+package account
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+type PasskeyService interface {
+	BeginRegistration(ctx context.Context, accountID string) (*PasskeyCeremonyResponse, error)
+	FinishRegistration(ctx context.Context, accountID string, req *PasskeyRegistrationFinishRequest) (*PasskeyResponse, error)
+	BeginLogin(ctx context.Context, req *PasskeyLoginBeginRequest) (*PasskeyCeremonyResponse, error)
+	FinishLogin(ctx context.Context, req *PasskeyLoginFinishRequest, userAgent, ipAddress string) (*LoginResponse, error)
+	ListPasskeys(ctx context.Context, accountID string) ([]*PasskeyResponse, error)
+	DeletePasskey(ctx context.Context, accountID, passkeyID string) error
+}
+
+type passkeyService struct {
+	webAuthn          *webauthn.WebAuthn
+	repository        PasskeyRepository
+	accountRepository AccountRepository
+	accountService    AccountService
+}
+
+func NewPasskeyService(
+	mongoService *mongo.MongoService,
+	accountService AccountService,
+	config PasskeyConfig,
+) (PasskeyService, error) {
+	return newPasskeyService(
+		NewPasskeyRepository(mongoService),
+		NewAccountRepository(mongoService),
+		accountService,
+		config,
+	)
+}
+
+func newPasskeyService(
+	repository PasskeyRepository,
+	accountRepository AccountRepository,
+	accountService AccountService,
+	config PasskeyConfig,
+) (*passkeyService, error) {
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          config.RPID,
+		RPDisplayName: config.RPDisplayName,
+		RPOrigins:     config.RPOrigins,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure webauthn: %w", err)
+	}
+
+	return &passkeyService{
+		webAuthn:          webAuthn,
+		repository:        repository,
+		accountRepository: accountRepository,
+		accountService:    accountService,
+	}, nil
+}
+
+func (s *passkeyService) BeginRegistration(ctx context.Context, accountID string) (*PasskeyCeremonyResponse, error) {
+	user, err := s.loadUser(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	exclusions := webauthn.Credentials(user.WebAuthnCredentials()).CredentialDescriptors()
+
+	creation, sessionData, err := s.webAuthn.BeginRegistration(
+		user,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin passkey registration: %w", err)
+	}
+
+	ceremony, err := s.storeCeremony(ctx, accountID, PasskeyCeremonyRegistration, sessionData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PasskeyCeremonyResponse{
+		CeremonyID: ceremony.ID.Hex(),
+		Options:    creation,
+	}, nil
+}
+
+func (s *passkeyService) FinishRegistration(ctx context.Context, accountID string, req *PasskeyRegistrationFinishRequest) (*PasskeyResponse, error) {
+	ceremony, sessionData, err := s.consumeCeremony(ctx, req.CeremonyID, PasskeyCeremonyRegistration)
+	if err != nil {
+		return nil, err
+	}
+
+	if ceremony.AccountID != accountID {
+		return nil, fmt.Errorf("invalid passkey ceremony")
+	}
+
+	user, err := s.loadUser(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(req.Credential)
+	if err != nil {
+		return nil, fmt.Errorf("invalid passkey credential: %w", err)
+	}
+
+	credential, err := s.webAuthn.CreateCredential(user, *sessionData, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid passkey credential: %w", err)
+	}
+
+	existing, err := s.repository.GetPasskeyByCredentialID(ctx, credential.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check passkey existence: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("passkey already exists")
+	}
+
+	name := req.Name
+	if name == "" {
+		name = "Passkey"
+	}
+
+	created, err := s.repository.CreatePasskey(ctx, NewPasskeyCredential(accountID, name, credential))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save passkey: %w", err)
+	}
+
+	return created.ToResponse(), nil
+}
+
+func (s *passkeyService) BeginLogin(ctx context.Context, req *PasskeyLoginBeginRequest) (*PasskeyCeremonyResponse, error) {
+	var (
+		assertion   *protocol.CredentialAssertion
+		sessionData *webauthn.SessionData
+		accountID   string
+		err         error
+	)
+
+	var user *passkeyUser
+	if req != nil && req.Email != "" {
+		user, err = s.loginUser(ctx, req.Email)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// An unknown email and one without passkeys get the same discoverable
+	// challenge as a request without an email, so the response does not tell
+	// which addresses have accounts.
+	if user != nil {
+		accountID = user.account.ID.Hex()
+		assertion, sessionData, err = s.webAuthn.BeginLogin(user)
+	} else {
+		assertion, sessionData, err = s.webAuthn.BeginDiscoverableLogin()
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin passkey login: %w", err)
+	}
+
+	ceremony, err := s.storeCeremony(ctx, accountID, PasskeyCeremonyLogin, sessionData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PasskeyCeremonyResponse{
+		CeremonyID: ceremony.ID.Hex(),
+		Options:    assertion,
+	}, nil
+}
+
+func (s *passkeyService) FinishLogin(ctx context.Context, req *PasskeyLoginFinishRequest, userAgent, ipAddress string) (*LoginResponse, error) {
+	ceremony, sessionData, err := s.consumeCeremony(ctx, req.CeremonyID, PasskeyCeremonyLogin)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(req.Credential)
+	if err != nil {
+		return nil, fmt.Errorf("invalid passkey assertion: %w", err)
+	}
+
+	passkey, err := s.repository.GetPasskeyByCredentialID(ctx, parsed.RawID)
+	if err != nil || passkey == nil {
+		return nil, fmt.Errorf("invalid passkey assertion")
+	}
+
+	if passkey.CloneWarning {
+		return nil, fmt.Errorf("passkey is disabled because a cloned authenticator was detected")
+	}
+
+	if ceremony.AccountID != "" && ceremony.AccountID != passkey.AccountID {
+		return nil, fmt.Errorf("invalid passkey assertion")
+	}
+
+	user, err := s.loadUser(ctx, passkey.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid passkey assertion")
+	}
+
+	if !user.account.IsActive {
+		return nil, user.account.inactiveError()
+	}
+
+	var credential *webauthn.Credential
+	if ceremony.AccountID != "" {
+		credential, err = s.webAuthn.ValidateLogin(user, *sessionData, parsed)
+	} else {
+		handler := func(rawID, userHandle []byte) (webauthn.User, error) {
+			if !bytes.Equal(userHandle, user.WebAuthnID()) {
+				return nil, fmt.Errorf("user handle does not match passkey owner")
+			}
+			return user, nil
+		}
+		_, credential, err = s.webAuthn.ValidatePasskeyLogin(handler, *sessionData, parsed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid passkey assertion: %w", err)
+	}
+
+	if credential.Authenticator.CloneWarning {
+		if flagErr := s.repository.FlagPasskeyCloned(ctx, passkey.ID); flagErr != nil {
+			fmt.Printf("Failed to flag cloned passkey: %v\n", flagErr)
+		}
+		return nil, fmt.Errorf("passkey is disabled because a cloned authenticator was detected")
+	}
+
+	err = s.repository.UpdatePasskeyUsage(ctx, passkey.ID, credential.Authenticator.SignCount, credential.Flags.BackupState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update passkey: %w", err)
+	}
+
+	return s.accountService.IssueSession(ctx, passkey.AccountID, userAgent, ipAddress)
+}
+
+func (s *passkeyService) ListPasskeys(ctx context.Context, accountID string) ([]*PasskeyResponse, error) {
+	passkeys, err := s.repository.GetPasskeysByAccountID(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list passkeys: %w", err)
+	}
+
+	responses := make([]*PasskeyResponse, len(passkeys))
+	for i, passkey := range passkeys {
+		responses[i] = passkey.ToResponse()
+	}
+
+	return responses, nil
+}
+
+func (s *passkeyService) DeletePasskey(ctx context.Context, accountID, passkeyID string) error {
+	objectID, err := primitive.ObjectIDFromHex(passkeyID)
+	if err != nil {
+		return fmt.Errorf("invalid passkey ID format: %w", err)
+	}
+
+	if err := s.repository.DeletePasskey(ctx, accountID, objectID); err != nil {
+		return fmt.Errorf("passkey not found: %w", err)
+	}
+
+	return nil
+}
+
+// loginUser returns the account registered under email with its passkeys,
+// or nil when there is no such account or it has no passkeys.
+func (s *passkeyService) loginUser(ctx context.Context, email string) (*passkeyUser, error) {
+	account, err := s.accountRepository.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+
+	if account == nil {
+		return nil, nil
+	}
+
+	credentials, err := s.repository.GetPasskeysByAccountID(ctx, account.ID.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get passkeys: %w", err)
+	}
+
+	if len(credentials) == 0 {
+		return nil, nil
+	}
+
+	return &passkeyUser{
+		account:     account,
+		credentials: credentials,
+	}, nil
+}
+
+func (s *passkeyService) loadUser(ctx context.Context, accountID string) (*passkeyUser, error) {
+	objectID, err := primitive.ObjectIDFromHex(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account ID format: %w", err)
+	}
+
+	account, err := s.accountRepository.GetByID(ctx, objectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+
+	if account == nil {
+		return nil, fmt.Errorf("account not found")
+	}
+
+	credentials, err := s.repository.GetPasskeysByAccountID(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get passkeys: %w", err)
+	}
+
+	return &passkeyUser{
+		account:     account,
+		credentials: credentials,
+	}, nil
+}
+
+func (s *passkeyService) storeCeremony(ctx context.Context, accountID string, purpose PasskeyCeremonyPurpose, sessionData *webauthn.SessionData) (*PasskeyCeremony, error) {
+	data, err := json.Marshal(sessionData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode passkey ceremony: %w", err)
+	}
+
+	ceremony := &PasskeyCeremony{
+		AccountID:   accountID,
+		Purpose:     purpose,
+		SessionData: string(data),
+		ExpiresAt:   time.Now().Add(PasskeyCeremonyExpiry),
+		CreatedAt:   time.Now(),
+	}
+
+	created, err := s.repository.CreateCeremony(ctx, ceremony)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store passkey ceremony: %w", err)
+	}
+
+	return created, nil
+}
+
+func (s *passkeyService) consumeCeremony(ctx context.Context, ceremonyID string, purpose PasskeyCeremonyPurpose) (*PasskeyCeremony, *webauthn.SessionData, error) {
+	objectID, err := primitive.ObjectIDFromHex(ceremonyID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid passkey ceremony")
+	}
+
+	ceremony, err := s.repository.ConsumeCeremony(ctx, objectID)
+	if err != nil || ceremony == nil {
+		return nil, nil, fmt.Errorf("invalid passkey ceremony")
+	}
+
+	if ceremony.Purpose != purpose {
+		return nil, nil, fmt.Errorf("invalid passkey ceremony")
+	}
+
+	if ceremony.IsExpired() {
+		return nil, nil, fmt.Errorf("passkey ceremony has expired")
+	}
+
+	var sessionData webauthn.SessionData
+	if err := json.Unmarshal([]byte(ceremony.SessionData), &sessionData); err != nil {
+		return nil, nil, fmt.Errorf("invalid passkey ceremony")
+	}
+
+	return ceremony, &sessionData, nil
+}
@@ -0,0 +1,418 @@
+package account
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	testPasskeyRPID   = "localhost"
+	testPasskeyOrigin = "http://localhost:3000"
+)
+
+// softAuthenticator is a minimal in-memory ES256 authenticator producing
+// "none" attestations, so the ceremonies can be exercised without hardware.
+type softAuthenticator struct {
+	privateKey   *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	counter      uint32
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	credentialID := make([]byte, 16)
+	_, err = rand.Read(credentialID)
+	require.NoError(t, err)
+
+	return &softAuthenticator{
+		privateKey:   privateKey,
+		credentialID: credentialID,
+	}
+}
+
+func (a *softAuthenticator) authenticatorData(flags byte, attested []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(testPasskeyRPID))
+
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.counter)
+	return append(data, attested...)
+}
+
+func (a *softAuthenticator) clientData(t *testing.T, ceremonyType, challenge string) []byte {
+	data, err := json.Marshal(map[string]string{
+		"type":      ceremonyType,
+		"challenge": challenge,
+		"origin":    testPasskeyOrigin,
+	})
+	require.NoError(t, err)
+	return data
+}
+
+func (a *softAuthenticator) create(t *testing.T, options *protocol.CredentialCreation) json.RawMessage {
+	encoder, err := cbor.CTAP2EncOptions().EncMode()
+	require.NoError(t, err)
+
+	publicKey, err := encoder.Marshal(map[int]any{
+		1:  2,
+		3:  -7,
+		-1: 1,
+		-2: a.privateKey.PublicKey.X.FillBytes(make([]byte, 32)),
+		-3: a.privateKey.PublicKey.Y.FillBytes(make([]byte, 32)),
+	})
+	require.NoError(t, err)
+
+	attested := make([]byte, 16)
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(a.credentialID)))
+	attested = append(attested, a.credentialID...)
+	attested = append(attested, publicKey...)
+
+	attestationObject, err := encoder.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": a.authenticatorData(0x45, attested),
+	})
+	require.NoError(t, err)
+
+	a.userHandle = []byte(options.Response.User.ID.(protocol.URLEncodedBase64))
+
+	clientData := a.clientData(t, "webauthn.create", options.Response.Challenge.String())
+
+	return a.marshal(t, map[string]string{
+		"clientDataJSON":    encodeBase64URL(clientData),
+		"attestationObject": encodeBase64URL(attestationObject),
+	})
+}
+
+func (a *softAuthenticator) assert(t *testing.T, options *protocol.CredentialAssertion) json.RawMessage {
+	a.counter++
+
+	authData := a.authenticatorData(0x05, nil)
+	clientData := a.clientData(t, "webauthn.get", options.Response.Challenge.String())
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+
+	signature, err := ecdsa.SignASN1(rand.Reader, a.privateKey, digest[:])
+	require.NoError(t, err)
+
+	return a.marshal(t, map[string]string{
+		"clientDataJSON":    encodeBase64URL(clientData),
+		"authenticatorData": encodeBase64URL(authData),
+		"signature":         encodeBase64URL(signature),
+		"userHandle":        encodeBase64URL(a.userHandle),
+	})
+}
+
+func (a *softAuthenticator) marshal(t *testing.T, response map[string]string) json.RawMessage {
+	data, err := json.Marshal(map[string]any{
+		"id":       encodeBase64URL(a.credentialID),
+		"rawId":    encodeBase64URL(a.credentialID),
+		"type":     "public-key",
+		"response": response,
+	})
+	require.NoError(t, err)
+	return data
+}
+
+func encodeBase64URL(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func setupPasskeyService(t *testing.T) (*passkeyService, *MockPasskeyRepository, *MockAccountRepository, *MockAccountService) {
+	mockPasskeyRepo := &MockPasskeyRepository{}
+	mockAccountRepo := &MockAccountRepository{}
+	mockAccountService := &MockAccountService{}
+
+	service, err := newPasskeyService(mockPasskeyRepo, mockAccountRepo, mockAccountService, PasskeyConfig{
+		RPID:          testPasskeyRPID,
+		RPDisplayName: "Test Platform",
+		RPOrigins:     []string{testPasskeyOrigin},
+	})
+	require.NoError(t, err)
+
+	return service, mockPasskeyRepo, mockAccountRepo, mockAccountService
+}
+
+// expectCeremony records the ceremony passed to CreateCeremony and serves it
+// back from ConsumeCeremony, mimicking the Mongo round trip.
+func expectCeremony(mockRepo *MockPasskeyRepository) *PasskeyCeremony {
+	stored := &PasskeyCeremony{}
+	id := primitive.NewObjectID()
+
+	mockRepo.On("CreateCeremony", mock.Anything, mock.AnythingOfType("*account.PasskeyCeremony")).
+		Run(func(args mock.Arguments) {
+			*stored = *args.Get(1).(*PasskeyCeremony)
+			stored.ID = id
+		}).
+		Return(stored, nil).Once()
+	mockRepo.On("ConsumeCeremony", mock.Anything, id).Return(stored, nil).Once()
+
+	return stored
+}
+
+func registerSoftPasskey(t *testing.T, service *passkeyService, mockPasskeyRepo *MockPasskeyRepository, account *Account, authenticator *softAuthenticator) *PasskeyCredential {
+	accountID := account.ID.Hex()
+
+	ceremony := expectCeremony(mockPasskeyRepo)
+	mockPasskeyRepo.On("GetPasskeysByAccountID", mock.Anything, accountID).Return([]*PasskeyCredential{}, nil).Twice()
+
+	begin, err := service.BeginRegistration(context.Background(), accountID)
+	require.NoError(t, err)
+	assert.Equal(t, ceremony.ID.Hex(), begin.CeremonyID)
+
+	var saved *PasskeyCredential
+	mockPasskeyRepo.On("GetPasskeyByCredentialID", mock.Anything, authenticator.credentialID).Return(nil, nil).Once()
+	mockPasskeyRepo.On("CreatePasskey", mock.Anything, mock.AnythingOfType("*account.PasskeyCredential")).
+		Run(func(args mock.Arguments) {
+			saved = args.Get(1).(*PasskeyCredential)
+			saved.ID = primitive.NewObjectID()
+		}).
+		Return(&PasskeyCredential{Name: "Laptop"}, nil).Once()
+
+	result, err := service.FinishRegistration(context.Background(), accountID, &PasskeyRegistrationFinishRequest{
+		CeremonyID: begin.CeremonyID,
+		Name:       "Laptop",
+		Credential: authenticator.create(t, begin.Options.(*protocol.CredentialCreation)),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Laptop", result.Name)
+	require.NotNil(t, saved)
+
+	return saved
+}
+
+func TestPasskeyService_RegisterAndLogin(t *testing.T) {
+	service, mockPasskeyRepo, mockAccountRepo, mockAccountService := setupPasskeyService(t)
+	account := CreateTestAccount()
+	accountID := account.ID.Hex()
+	authenticator := newSoftAuthenticator(t)
+
+	mockAccountRepo.On("GetByID", mock.Anything, account.ID).Return(account, nil)
+
+	saved := registerSoftPasskey(t, service, mockPasskeyRepo, account, authenticator)
+	assert.Equal(t, accountID, saved.AccountID)
+	assert.Equal(t, authenticator.credentialID, saved.CredentialID)
+	assert.Equal(t, "none", saved.AttestationType)
+	assert.Equal(t, uint32(0), saved.SignCount)
+
+	expectCeremony(mockPasskeyRepo)
+	begin, err := service.BeginLogin(context.Background(), &PasskeyLoginBeginRequest{})
+	require.NoError(t, err)
+
+	mockPasskeyRepo.On("GetPasskeyByCredentialID", mock.Anything, authenticator.credentialID).Return(saved, nil).Once()
+	mockPasskeyRepo.On("GetPasskeysByAccountID", mock.Anything, accountID).Return([]*PasskeyCredential{saved}, nil).Once()
+	mockPasskeyRepo.On("UpdatePasskeyUsage", mock.Anything, saved.ID, uint32(1), false).Return(nil).Once()
+	loginResponse := &LoginResponse{Token: "issued-token", Account: account.ToResponse()}
+	mockAccountService.On("IssueSession", mock.Anything, accountID, "Mozilla/5.0", "192.168.1.1").Return(loginResponse, nil).Once()
+
+	result, err := service.FinishLogin(context.Background(), &PasskeyLoginFinishRequest{
+		CeremonyID: begin.CeremonyID,
+		Credential: authenticator.assert(t, begin.Options.(*protocol.CredentialAssertion)),
+	}, "Mozilla/5.0", "192.168.1.1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "issued-token", result.Token)
+
+	mockPasskeyRepo.AssertExpectations(t)
+	mockAccountRepo.AssertExpectations(t)
+	mockAccountService.AssertExpectations(t)
+}
+
+func TestPasskeyService_BeginLogin(t *testing.T) {
+	account := CreateTestAccount()
+	accountID := account.ID.Hex()
+	enrolled := &PasskeyCredential{ID: primitive.NewObjectID(), AccountID: accountID, CredentialID: []byte("credential-1")}
+
+	tests := []struct {
+		name          string
+		setup         func(mockPasskeyRepo *MockPasskeyRepository, mockAccountRepo *MockAccountRepository)
+		wantAccountID string
+	}{
+		{
+			name: "unknown email gets a discoverable challenge",
+			setup: func(mockPasskeyRepo *MockPasskeyRepository, mockAccountRepo *MockAccountRepository) {
+				mockAccountRepo.On("GetByEmail", mock.Anything, "test@example.com").Return(nil, nil).Once()
+			},
+		},
+		{
+			name: "email without passkeys gets a discoverable challenge",
+			setup: func(mockPasskeyRepo *MockPasskeyRepository, mockAccountRepo *MockAccountRepository) {
+				mockAccountRepo.On("GetByEmail", mock.Anything, "test@example.com").Return(account, nil).Once()
+				mockPasskeyRepo.On("GetPasskeysByAccountID", mock.Anything, accountID).Return([]*PasskeyCredential{}, nil).Once()
+			},
+		},
+		{
+			name: "email with passkeys is restricted to them",
+			setup: func(mockPasskeyRepo *MockPasskeyRepository, mockAccountRepo *MockAccountRepository) {
+				mockAccountRepo.On("GetByEmail", mock.Anything, "test@example.com").Return(account, nil).Once()
+				mockPasskeyRepo.On("GetPasskeysByAccountID", mock.Anything, accountID).Return([]*PasskeyCredential{enrolled}, nil).Once()
+			},
+			wantAccountID: accountID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockPasskeyRepo, mockAccountRepo, _ := setupPasskeyService(t)
+			tt.setup(mockPasskeyRepo, mockAccountRepo)
+			ceremony := expectCeremony(mockPasskeyRepo)
+
+			begin, err := service.BeginLogin(context.Background(), &PasskeyLoginBeginRequest{Email: "test@example.com"})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAccountID, ceremony.AccountID)
+			allowed := begin.Options.(*protocol.CredentialAssertion).Response.AllowedCredentials
+			if tt.wantAccountID == "" {
+				assert.Empty(t, allowed)
+			} else {
+				require.Len(t, allowed, 1)
+				assert.Equal(t, protocol.URLEncodedBase64(enrolled.CredentialID), allowed[0].CredentialID)
+			}
+			mockAccountRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPasskeyService_FinishLogin(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     func(saved *PasskeyCredential, mockPasskeyRepo *MockPasskeyRepository)
+		account   func(a *Account)
+		errMsg    string
+		wantFlags bool
+	}{
+		{
+			name: "sign count regression flags cloned authenticator",
+			setup: func(saved *PasskeyCredential, mockPasskeyRepo *MockPasskeyRepository) {
+				saved.SignCount = 10
+				mockPasskeyRepo.On("FlagPasskeyCloned", mock.Anything, saved.ID).Return(nil).Once()
+			},
+			errMsg:    "cloned authenticator",
+			wantFlags: true,
+		},
+		{
+			name: "previously flagged passkey is rejected",
+			setup: func(saved *PasskeyCredential, mockPasskeyRepo *MockPasskeyRepository) {
+				saved.CloneWarning = true
+			},
+			errMsg: "cloned authenticator",
+		},
+		{
+			name:    "inactive account",
+			setup:   func(saved *PasskeyCredential, mockPasskeyRepo *MockPasskeyRepository) {},
+			account: func(a *Account) { a.IsActive = false },
+			errMsg:  "account is inactive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockPasskeyRepo, mockAccountRepo, mockAccountService := setupPasskeyService(t)
+			account := CreateTestAccount()
+			accountID := account.ID.Hex()
+			authenticator := newSoftAuthenticator(t)
+
+			mockAccountRepo.On("GetByID", mock.Anything, account.ID).Return(account, nil)
+
+			saved := registerSoftPasskey(t, service, mockPasskeyRepo, account, authenticator)
+			if tt.account != nil {
+				tt.account(account)
+			}
+			tt.setup(saved, mockPasskeyRepo)
+
+			expectCeremony(mockPasskeyRepo)
+			begin, err := service.BeginLogin(context.Background(), nil)
+			require.NoError(t, err)
+
+			mockPasskeyRepo.On("GetPasskeyByCredentialID", mock.Anything, authenticator.credentialID).Return(saved, nil).Once()
+			mockPasskeyRepo.On("GetPasskeysByAccountID", mock.Anything, accountID).Return([]*PasskeyCredential{saved}, nil).Maybe()
+
+			result, err := service.FinishLogin(context.Background(), &PasskeyLoginFinishRequest{
+				CeremonyID: begin.CeremonyID,
+				Credential: authenticator.assert(t, begin.Options.(*protocol.CredentialAssertion)),
+			}, "Mozilla/5.0", "192.168.1.1")
+
+			assert.Nil(t, result)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+
+			mockPasskeyRepo.AssertExpectations(t)
+			mockAccountService.AssertNotCalled(t, "IssueSession", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			if !tt.wantFlags {
+				mockPasskeyRepo.AssertNotCalled(t, "FlagPasskeyCloned", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestPasskeyService_ConsumeCeremony(t *testing.T) {
+	tests := []struct {
+		name     string
+		ceremony *PasskeyCeremony
+		errMsg   string
+	}{
+		{
+			name:   "unknown ceremony",
+			errMsg: "invalid passkey ceremony",
+		},
+		{
+			name: "wrong purpose",
+			ceremony: &PasskeyCeremony{
+				Purpose:     PasskeyCeremonyRegistration,
+				SessionData: "{}",
+				ExpiresAt:   time.Now().Add(time.Minute),
+			},
+			errMsg: "invalid passkey ceremony",
+		},
+		{
+			name: "expired ceremony",
+			ceremony: &PasskeyCeremony{
+				Purpose:     PasskeyCeremonyLogin,
+				SessionData: "{}",
+				ExpiresAt:   time.Now().Add(-time.Minute),
+			},
+			errMsg: "expired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockPasskeyRepo, _, _ := setupPasskeyService(t)
+			id := primitive.NewObjectID()
+
+			if tt.ceremony == nil {
+				mockPasskeyRepo.On("ConsumeCeremony", mock.Anything, id).Return(nil, nil)
+			} else {
+				mockPasskeyRepo.On("ConsumeCeremony", mock.Anything, id).Return(tt.ceremony, nil)
+			}
+
+			result, err := service.FinishLogin(context.Background(), &PasskeyLoginFinishRequest{
+				CeremonyID: id.Hex(),
+				Credential: json.RawMessage(`{}`),
+			}, "", "")
+
+			assert.Nil(t, result)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+			mockPasskeyRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package account
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// breachHashPrefixLength is the length of the SHA-1 prefix that selects a
+// range, as in the k-anonymity model of the Pwned Passwords range API.
+const breachHashPrefixLength = 5
+
+// BreachCorpus tells whether a password appears in a corpus of breached
+// passwords. Lookups never leave the machine.
+type BreachCorpus interface {
+	Contains(password string) (bool, error)
+}
+
+// LoadBreachCorpus opens a local breach corpus keyed by SHA-1. path is either
+// a directory of range files, one per five character hash prefix holding
+// "SUFFIX:COUNT" lines as served by the Pwned Passwords range API, or a single
+// file of "HASH:COUNT" lines, which is read into memory.
+func LoadBreachCorpus(path string) (BreachCorpus, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breach corpus: %w", err)
+	}
+
+	if info.IsDir() {
+		return &rangeDirectoryBreachCorpus{dir: path}, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breach corpus: %w", err)
+	}
+	defer file.Close()
+
+	return readBreachCorpus(file)
+}
+
+// breachHash splits the SHA-1 of a password into its range prefix and the
+// suffix looked up within the range.
+func breachHash(password string) (string, string) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	return hash[:breachHashPrefixLength], hash[breachHashPrefixLength:]
+}
+
+// memoryBreachCorpus groups suffixes by prefix, the same shape as the range
+// files, so both corpora look passwords up the same way.
+type memoryBreachCorpus struct {
+	ranges map[string]map[string]struct{}
+}
+
+func readBreachCorpus(reader io.Reader) (*memoryBreachCorpus, error) {
+	corpus := &memoryBreachCorpus{ranges: make(map[string]map[string]struct{})}
+
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		hash := breachLineHash(scanner.Text())
+		if hash == "" {
+			continue
+		}
+		if len(hash) != sha1.Size*2 {
+			return nil, fmt.Errorf("failed to read breach corpus: line %d is not a SHA-1 hash", line)
+		}
+
+		prefix, suffix := hash[:breachHashPrefixLength], hash[breachHashPrefixLength:]
+		if corpus.ranges[prefix] == nil {
+			corpus.ranges[prefix] = make(map[string]struct{})
+		}
+		corpus.ranges[prefix][suffix] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breach corpus: %w", err)
+	}
+
+	return corpus, nil
+}
+
+func (c *memoryBreachCorpus) Contains(password string) (bool, error) {
+	prefix, suffix := breachHash(password)
+	_, found := c.ranges[prefix][suffix]
+
+	return found, nil
+}
+
+type rangeDirectoryBreachCorpus struct {
+	dir string
+}
+
+func (c *rangeDirectoryBreachCorpus) Contains(password string) (bool, error) {
+	prefix, suffix := breachHash(password)
+
+	file, err := c.openRange(prefix)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to open breach range %s: %w", prefix, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if breachLineHash(scanner.Text()) == suffix {
+			return true, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("failed to read breach range %s: %w", prefix, err)
+	}
+
+	return false, nil
+}
+
+func (c *rangeDirectoryBreachCorpus) openRange(prefix string) (*os.File, error) {
+	file, err := os.Open(filepath.Join(c.dir, prefix))
+	if errors.Is(err, os.ErrNotExist) {
+		return os.Open(filepath.Join(c.dir, prefix+".txt"))
+	}
+
+	return file, err
+}
+
+// breachLineHash returns the hash part of a "HASH:COUNT" line, upper-cased,
+// or an empty string for blank lines.
+func breachLineHash(line string) string {
+	hash, _, _ := strings.Cut(strings.TrimSpace(line), ":")
+	return strings.ToUpper(hash)
+}
@@ -0,0 +1,93 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/argon2"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/vault"
+)
+
+// PasswordHashConfig sets the argon2 parameters of new password hashes and
+// where the pepper lives in Vault. Without a PepperPath passwords are hashed
+// without a pepper.
+type PasswordHashConfig struct {
+	Params     argon2.Params
+	PepperPath string
+}
+
+// loadPasswordPeppers reads every pepper version from the Vault secret at
+// path. Each key is a version number, optionally prefixed with "v", holding
+// the pepper itself; adding a higher version rotates the pepper.
+func loadPasswordPeppers(ctx context.Context, vaultService vault.VaultService, path string) ([]argon2.Pepper, error) {
+	secret, err := vaultService.GetSecret(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read password pepper: %w", err)
+	}
+
+	peppers := make([]argon2.Pepper, 0, len(secret))
+	for key, value := range secret {
+		version, err := strconv.Atoi(strings.TrimPrefix(key, "v"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid password pepper version %q", key)
+		}
+
+		pepper, ok := value.(string)
+		if !ok || pepper == "" {
+			return nil, fmt.Errorf("password pepper version %d must be a non-empty string", version)
+		}
+
+		peppers = append(peppers, argon2.Pepper{Version: version, Secret: []byte(pepper)})
+	}
+
+	if len(peppers) == 0 {
+		return nil, fmt.Errorf("no password pepper found at %s", path)
+	}
+
+	return peppers, nil
+}
+
+// newPasswordHasher builds the hasher for config, loading its peppers from
+// Vault when a path is set.
+func newPasswordHasher(ctx context.Context, config PasswordHashConfig, vaultService vault.VaultService) (*argon2.Hasher, error) {
+	var peppers []argon2.Pepper
+	if config.PepperPath != "" {
+		if vaultService == nil {
+			return nil, fmt.Errorf("password pepper requires vault")
+		}
+
+		loaded, err := loadPasswordPeppers(ctx, vaultService, config.PepperPath)
+		if err != nil {
+			return nil, err
+		}
+		peppers = loaded
+	}
+
+	return argon2.NewHasher(config.Params, peppers...)
+}
+
+// rehashPassword replaces a hash made with outdated argon2 parameters or an
+// old pepper, using the password the user just signed in with. Failures are
+// only logged: the old hash still works.
+func (s *accountService) rehashPassword(ctx context.Context, account *Account, password string) {
+	if !argon2.NeedsRehash(account.PasswordHash) {
+		return
+	}
+
+	hashedPassword, err := argon2.HashPassword(password)
+	if err != nil {
+		fmt.Printf("Failed to rehash password of account %s: %v\n", account.ID.Hex(), err)
+		return
+	}
+
+	if _, err := s.repository.Update(ctx, account.ID, bson.M{"password_hash": hashedPassword}); err != nil {
+		fmt.Printf("Failed to save rehashed password of account %s: %v\n", account.ID.Hex(), err)
+		return
+	}
+
+	account.PasswordHash = hashedPassword
+}
@@ -0,0 +1,110 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/argon2"
+)
+
+func useDefaultHasher(t *testing.T, hasher *argon2.Hasher) {
+	t.Helper()
+
+	argon2.SetDefault(hasher)
+	t.Cleanup(func() {
+		defaultHasher, _ := argon2.NewHasher(argon2.DefaultParams())
+		argon2.SetDefault(defaultHasher)
+	})
+}
+
+func TestNewPasswordHasher_LoadsPeppersFromVault(t *testing.T) {
+	mockVault := &MockVaultService{}
+	mockVault.On("GetSecret", mock.Anything, "secret/password-pepper").Return(map[string]interface{}{
+		"v1": "first-pepper",
+		"2":  "second-pepper",
+	}, nil)
+
+	hasher, err := newPasswordHasher(context.Background(), PasswordHashConfig{
+		Params:     argon2.DefaultParams(),
+		PepperPath: "secret/password-pepper",
+	}, mockVault)
+	require.NoError(t, err)
+
+	hash, err := hasher.Hash("password123")
+	require.NoError(t, err)
+	assert.Contains(t, hash, ",pv=2$", "new hashes use the newest pepper")
+}
+
+func TestNewPasswordHasher_Errors(t *testing.T) {
+	config := PasswordHashConfig{Params: argon2.DefaultParams(), PepperPath: "secret/password-pepper"}
+
+	tests := []struct {
+		name   string
+		secret map[string]interface{}
+		err    error
+	}{
+		{name: "vault error", err: errors.New("permission denied")},
+		{name: "empty secret", secret: map[string]interface{}{}},
+		{name: "bad version", secret: map[string]interface{}{"latest": "pepper"}},
+		{name: "empty pepper", secret: map[string]interface{}{"1": ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockVault := &MockVaultService{}
+			if tt.err != nil {
+				mockVault.On("GetSecret", mock.Anything, config.PepperPath).Return(nil, tt.err)
+			} else {
+				mockVault.On("GetSecret", mock.Anything, config.PepperPath).Return(tt.secret, nil)
+			}
+
+			_, err := newPasswordHasher(context.Background(), config, mockVault)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := newPasswordHasher(context.Background(), config, nil)
+	assert.ErrorContains(t, err, "requires vault")
+}
+
+func TestAccountService_LoginRehashesOutdatedPassword(t *testing.T) {
+	legacy, err := argon2.NewHasher(argon2.Params{Memory: 8 * 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	require.NoError(t, err)
+	legacyHash, err := legacy.Hash("password123")
+	require.NoError(t, err)
+
+	current, err := argon2.NewHasher(argon2.DefaultParams(), argon2.Pepper{Version: 1, Secret: []byte("pepper")})
+	require.NoError(t, err)
+	useDefaultHasher(t, current)
+
+	service, mockAccountRepo, mockIdentityRepo, _ := setupAccountService()
+	account := CreateTestAccount(func(a *Account) {
+		a.PasswordHash = legacyHash
+	})
+
+	var savedHash string
+	mockAccountRepo.On("GetByEmail", mock.Anything, account.Email).Return(account, nil)
+	mockAccountRepo.On("Update", mock.Anything, account.ID, mock.MatchedBy(func(update bson.M) bool {
+		hash, ok := update["password_hash"].(string)
+		savedHash = hash
+		return ok && len(update) == 1
+	})).Return(account, nil)
+	mockIdentityRepo.On("CreateSession", mock.Anything, mock.Anything).Return(CreateTestSession(), nil)
+
+	_, err = service.Login(context.Background(), &LoginRequest{Email: account.Email, Password: "password123"}, "agent", "203.0.113.42")
+	require.NoError(t, err)
+
+	mockAccountRepo.AssertExpectations(t)
+	assert.Contains(t, savedHash, ",pv=1$")
+	assert.False(t, current.NeedsRehash(savedHash))
+
+	valid, err := current.Verify("password123", savedHash)
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
@@ -0,0 +1,18 @@
+package account
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// passwordPolicyStatus returns 400 when err is a password policy violation,
+// and statusCode otherwise.
+func passwordPolicyStatus(err error, statusCode int) int {
+	var policyErr *PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		return fiber.StatusBadRequest
+	}
+
+	return statusCode
+}
@@ -0,0 +1,45 @@
+package account
+
+import (
+	"strings"
+	"time"
+)
+
+// PasswordPolicy decides which new passwords are accepted. It is applied when
+// registering, resetting and changing a password.
+type PasswordPolicy struct {
+	MinLength     int  `json:"min_length"`
+	MaxLength     int  `json:"max_length"`
+	RequireUpper  bool `json:"require_upper"`
+	RequireLower  bool `json:"require_lower"`
+	RequireDigit  bool `json:"require_digit"`
+	RequireSymbol bool `json:"require_symbol"`
+	// HistorySize rejects a password matching the current one or any of the
+	// previous HistorySize-1. Zero allows reuse.
+	HistorySize int `json:"history_size"`
+	// MaxAge makes password logins fail once the password is older, until it
+	// is reset. Zero disables expiry.
+	MaxAge time.Duration `json:"max_age"`
+	// Breaches, when set, rejects passwords found in a breach corpus.
+	Breaches BreachCorpus `json:"-"`
+}
+
+// DefaultPasswordPolicy follows current guidance: length over composition,
+// no reuse of recent passwords and no forced rotation.
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:   8,
+		MaxLength:   128,
+		HistorySize: 5,
+	}
+}
+
+// PasswordPolicyError lists every rule a password broke so that clients can
+// show them all at once.
+type PasswordPolicyError struct {
+	Violations []string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return "password does not meet the password policy: " + strings.Join(e.Violations, "; ")
+}
@@ -0,0 +1,155 @@
+package account
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/argon2"
+)
+
+// Check returns a *PasswordPolicyError listing every rule the password breaks.
+// previousHashes holds the current hash first, then older ones, as kept in
+// Account.PasswordHistory.
+func (p *PasswordPolicy) Check(password string, previousHashes []string) error {
+	var violations []string
+
+	length := utf8.RuneCountInString(password)
+	if p.MinLength > 0 && length < p.MinLength {
+		violations = append(violations, "must be at least "+strconv.Itoa(p.MinLength)+" characters")
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, "must be at most "+strconv.Itoa(p.MaxLength)+" characters")
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUpper && !hasUpper {
+		violations = append(violations, "must contain an uppercase letter")
+	}
+	if p.RequireLower && !hasLower {
+		violations = append(violations, "must contain a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, "must contain a digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, "must contain a symbol")
+	}
+
+	if p.reusesPassword(password, previousHashes) {
+		violations = append(violations, "must not match any of the last "+strconv.Itoa(p.HistorySize)+" passwords")
+	}
+
+	if p.Breaches != nil {
+		breached, err := p.Breaches.Contains(password)
+		if err != nil {
+			return fmt.Errorf("failed to check password against breach corpus: %w", err)
+		}
+		if breached {
+			violations = append(violations, "has appeared in a data breach and must not be used")
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+
+	return nil
+}
+
+func (p *PasswordPolicy) reusesPassword(password string, previousHashes []string) bool {
+	for i, hash := range previousHashes {
+		if i >= p.HistorySize {
+			break
+		}
+		if hash == "" {
+			continue
+		}
+
+		if matches, err := argon2.VerifyPassword(password, hash); err == nil && matches {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NextHistory returns the hashes to keep once account's password is replaced:
+// the outgoing hash followed by the newest older ones, enough for Check to
+// compare against HistorySize passwords including the new current one.
+func (p *PasswordPolicy) NextHistory(account *Account) []string {
+	keep := p.HistorySize - 1
+	if keep <= 0 || account.PasswordHash == "" {
+		return nil
+	}
+
+	history := append([]string{account.PasswordHash}, account.PasswordHistory...)
+	if len(history) > keep {
+		history = history[:keep]
+	}
+
+	return history
+}
+
+// IsExpired reports whether account's password is older than MaxAge. Accounts
+// that never changed their password count from their creation.
+func (p *PasswordPolicy) IsExpired(account *Account) bool {
+	if p.MaxAge <= 0 || account.PasswordHash == "" {
+		return false
+	}
+
+	changedAt := account.CreatedAt
+	if account.PasswordChangedAt != nil {
+		changedAt = *account.PasswordChangedAt
+	}
+
+	return time.Since(changedAt) > p.MaxAge
+}
+
+// previousPasswordHashes lists the account's current hash followed by its
+// history, the order Check expects.
+func previousPasswordHashes(account *Account) []string {
+	if account.PasswordHash == "" {
+		return account.PasswordHistory
+	}
+
+	return append([]string{account.PasswordHash}, account.PasswordHistory...)
+}
+
+// checkPasswordPolicy applies the configured policy to a new password for
+// account, which is nil when registering. Without a policy every password is
+// accepted.
+func (s *accountService) checkPasswordPolicy(password string, account *Account) error {
+	if s.passwordPolicy == nil {
+		return nil
+	}
+
+	var previousHashes []string
+	if account != nil {
+		previousHashes = previousPasswordHashes(account)
+	}
+
+	return s.passwordPolicy.Check(password, previousHashes)
+}
+
+func (s *accountService) nextPasswordHistory(account *Account) []string {
+	if s.passwordPolicy == nil {
+		return nil
+	}
+
+	return s.passwordPolicy.NextHistory(account)
+}
@@ -0,0 +1,200 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/argon2"
+)
+
+// breachedPassword's SHA-1 is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8.
+const breachedPassword = "password"
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	policy := PasswordPolicy{
+		MinLength:     10,
+		MaxLength:     20,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+	}
+
+	tests := []struct {
+		name       string
+		password   string
+		violations []string
+	}{
+		{name: "compliant", password: "Correct-Horse-9"},
+		{name: "too short", password: "Sh0rt!", violations: []string{"at least 10"}},
+		{name: "too long", password: "Much-Too-Long-Password-1", violations: []string{"at most 20"}},
+		{
+			name:       "missing classes",
+			password:   "alllowercaseletters",
+			violations: []string{"uppercase", "digit", "symbol"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Check(tt.password, nil)
+
+			if len(tt.violations) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var policyErr *PasswordPolicyError
+			require.True(t, errors.As(err, &policyErr))
+			assert.Len(t, policyErr.Violations, len(tt.violations))
+			for _, violation := range tt.violations {
+				assert.Contains(t, err.Error(), violation)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicy_History(t *testing.T) {
+	policy := PasswordPolicy{HistorySize: 3}
+
+	hashes := make([]string, 4)
+	for i := range hashes {
+		hash, err := argon2.HashPassword("password-" + string(rune('a'+i)))
+		require.NoError(t, err)
+		hashes[i] = hash
+	}
+
+	account := CreateTestAccount(func(a *Account) {
+		a.PasswordHash = hashes[0]
+		a.PasswordHistory = hashes[1:]
+	})
+	previous := previousPasswordHashes(account)
+
+	assert.Error(t, policy.Check("password-a", previous), "current password")
+	assert.Error(t, policy.Check("password-c", previous), "within history")
+	assert.NoError(t, policy.Check("password-d", previous), "older than the history size")
+
+	assert.Equal(t, hashes[:2], policy.NextHistory(account))
+	assert.Nil(t, (&PasswordPolicy{HistorySize: 1}).NextHistory(account))
+}
+
+func TestPasswordPolicy_IsExpired(t *testing.T) {
+	policy := PasswordPolicy{MaxAge: 90 * 24 * time.Hour}
+	longAgo := time.Now().Add(-100 * 24 * time.Hour)
+	recently := time.Now().Add(-24 * time.Hour)
+
+	assert.True(t, policy.IsExpired(CreateTestAccount(func(a *Account) { a.CreatedAt = longAgo })))
+	assert.False(t, policy.IsExpired(CreateTestAccount(func(a *Account) {
+		a.CreatedAt = longAgo
+		a.PasswordChangedAt = &recently
+	})))
+	assert.False(t, (&PasswordPolicy{}).IsExpired(CreateTestAccount(func(a *Account) { a.CreatedAt = longAgo })))
+}
+
+func TestLoadBreachCorpus(t *testing.T) {
+	dir := t.TempDir()
+
+	file := filepath.Join(dir, "corpus.txt")
+	require.NoError(t, os.WriteFile(file, []byte("5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8:9545824\n\n"), 0o600))
+
+	ranges := filepath.Join(dir, "ranges")
+	require.NoError(t, os.Mkdir(ranges, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(ranges, "5BAA6"), []byte("003D68EB55068C33ACE09247EE4C639306B:3\r\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824\r\n"), 0o600))
+
+	for _, path := range []string{file, ranges} {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			corpus, err := LoadBreachCorpus(path)
+			require.NoError(t, err)
+
+			found, err := corpus.Contains(breachedPassword)
+			require.NoError(t, err)
+			assert.True(t, found)
+
+			found, err = corpus.Contains("Correct-Horse-9")
+			require.NoError(t, err)
+			assert.False(t, found)
+		})
+	}
+
+	malformed := filepath.Join(dir, "malformed.txt")
+	require.NoError(t, os.WriteFile(malformed, []byte("not-a-hash\n"), 0o600))
+	_, err := LoadBreachCorpus(malformed)
+	assert.Error(t, err)
+
+	_, err = LoadBreachCorpus(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestAccountService_RegisterRejectsBreachedPassword(t *testing.T) {
+	service, mockAccountRepo, _, _ := setupAccountService()
+	corpus, err := readBreachCorpus(strings.NewReader("5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:1\n"))
+	require.NoError(t, err)
+
+	policy := DefaultPasswordPolicy()
+	policy.Breaches = corpus
+	service.passwordPolicy = &policy
+
+	_, err = service.Register(context.Background(), &RegisterRequest{
+		Email:     "new@example.com",
+		Username:  "newuser",
+		FirstName: "New",
+		LastName:  "User",
+		Password:  breachedPassword,
+	})
+
+	var policyErr *PasswordPolicyError
+	require.True(t, errors.As(err, &policyErr))
+	assert.Contains(t, err.Error(), "data breach")
+	mockAccountRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestAccountService_ChangePasswordAppliesPolicy(t *testing.T) {
+	service, mockAccountRepo, mockIdentityRepo, _ := setupAccountService()
+	policy := DefaultPasswordPolicy()
+	service.passwordPolicy = &policy
+
+	account := CreateTestAccount()
+	mockAccountRepo.On("GetByID", mock.Anything, account.ID).Return(account, nil)
+
+	err := service.ChangePassword(context.Background(), account.ID.Hex(), &ChangePasswordRequest{
+		OldPassword: "password123",
+		NewPassword: "password123",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "last 5 passwords")
+
+	mockAccountRepo.On("UpdatePasswordHash", mock.Anything, account.ID, mock.Anything, []string{account.PasswordHash}).Return(account, nil)
+	mockIdentityRepo.On("DeactivateAllUserSessions", mock.Anything, account.ID.Hex()).Return(nil)
+
+	err = service.ChangePassword(context.Background(), account.ID.Hex(), &ChangePasswordRequest{
+		OldPassword: "password123",
+		NewPassword: "a-brand-new-passphrase",
+	})
+	require.NoError(t, err)
+	mockAccountRepo.AssertExpectations(t)
+}
+
+func TestAccountService_LoginRejectsExpiredPassword(t *testing.T) {
+	service, mockAccountRepo, _, _ := setupAccountService()
+	service.passwordPolicy = &PasswordPolicy{MaxAge: 24 * time.Hour}
+
+	changedAt := time.Now().Add(-48 * time.Hour)
+	account := CreateTestAccount(func(a *Account) {
+		a.PasswordChangedAt = &changedAt
+	})
+	mockAccountRepo.On("GetByEmail", mock.Anything, account.Email).Return(account, nil)
+
+	_, err := service.Login(context.Background(), &LoginRequest{Email: account.Email, Password: "password123"}, "agent", "203.0.113.42")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "password has expired")
+}
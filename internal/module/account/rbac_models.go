@@ -0,0 +1,102 @@
+package account
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	PermissionAccountsRead        = "accounts:read"
+	PermissionAccountsWrite       = "accounts:write"
+	PermissionAccountsImpersonate = "accounts:impersonate"
+	PermissionAccountsExport      = "accounts:export"
+	PermissionRolesRead           = "roles:read"
+	PermissionRolesManage         = "roles:manage"
+	PermissionAuditRead           = "audit:read"
+
+	// PermissionAll grants every permission. A permission of the form
+	// "accounts:*" grants every action on that resource.
+	PermissionAll = "*"
+)
+
+const (
+	RoleAdmin   = "admin"
+	RoleSupport = "support"
+)
+
+// RolePermissionCacheTTL bounds how long role definitions are cached in
+// memory before changes in Mongo are picked up.
+const RolePermissionCacheTTL = time.Minute
+
+const ImpersonationTokenExpiry = time.Hour
+
+// SystemRoles are always available and cannot be modified or deleted.
+// Roles stored in Mongo with the same name are ignored.
+var SystemRoles = map[string]*Role{
+	RoleAdmin: {
+		Name:        RoleAdmin,
+		Description: "Full access to every administrative endpoint",
+		Permissions: []string{PermissionAll},
+		System:      true,
+	},
+	RoleSupport: {
+		Name:        RoleSupport,
+		Description: "Read-only access to accounts, roles and the audit log",
+		Permissions: []string{PermissionAccountsRead, PermissionRolesRead, PermissionAuditRead},
+		System:      true,
+	},
+}
+
+type Role struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name        string             `json:"name" bson:"name"`
+	Description string             `json:"description" bson:"description"`
+	Permissions []string           `json:"permissions" bson:"permissions"`
+	System      bool               `json:"system" bson:"-"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" validate:"required,min=2,max=50"`
+	Description string   `json:"description" validate:"max=200"`
+	Permissions []string `json:"permissions" validate:"required,min=1"`
+}
+
+type UpdateRoleRequest struct {
+	Description *string  `json:"description,omitempty" validate:"omitempty,max=200"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+type AssignRolesRequest struct {
+	Roles []string `json:"roles"`
+}
+
+type ImpersonationResponse struct {
+	Token          string           `json:"token"`
+	ExpiresAt      time.Time        `json:"expires_at"`
+	ImpersonatorID string           `json:"impersonator_id"`
+	Account        *AccountResponse `json:"account"`
+}
+
+// Grants reports whether the role includes the permission, either directly
+// or through a wildcard.
+func (r *Role) Grants(permission string) bool {
+	for _, granted := range r.Permissions {
+		if permissionMatches(granted, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+func permissionMatches(granted, permission string) bool {
+	if granted == PermissionAll || granted == permission {
+		return true
+	}
+
+	resource, found := strings.CutSuffix(granted, ":*")
+	return found && strings.HasPrefix(permission, resource+":")
+}
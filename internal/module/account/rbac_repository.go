@@ -0,0 +1,90 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+const RoleCollectionName = "roles"
+
+type RoleRepository interface {
+	CreateRole(ctx context.Context, role *Role) (*Role, error)
+	GetRoleByName(ctx context.Context, name string) (*Role, error)
+	ListRoles(ctx context.Context) ([]*Role, error)
+	UpdateRole(ctx context.Context, name string, updateData bson.M) (*Role, error)
+	DeleteRole(ctx context.Context, name string) error
+}
+
+type roleRepository struct {
+	repo mongo.Repository[Role]
+}
+
+var _ RoleRepository = (*roleRepository)(nil)
+
+func NewRoleRepository(mongoService *mongo.MongoService) RoleRepository {
+	return &roleRepository{
+		repo: mongo.NewRepository[Role](mongoService, RoleCollectionName),
+	}
+}
+
+func (r *roleRepository) CreateRole(ctx context.Context, role *Role) (*Role, error) {
+	role.ID = primitive.NewObjectID()
+
+	result, err := r.repo.Create(ctx, *role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create role: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *roleRepository) GetRoleByName(ctx context.Context, name string) (*Role, error) {
+	result, err := r.repo.FindOne(ctx, bson.M{"name": name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *roleRepository) ListRoles(ctx context.Context) ([]*Role, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+
+	results, err := r.repo.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	roles := make([]*Role, len(results))
+	for i := range results {
+		roles[i] = &results[i]
+	}
+
+	return roles, nil
+}
+
+func (r *roleRepository) UpdateRole(ctx context.Context, name string, updateData bson.M) (*Role, error) {
+	updateData["updated_at"] = time.Now()
+
+	result, err := r.repo.Update(ctx, bson.M{"name": name}, bson.M{"$set": updateData})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update role: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *roleRepository) DeleteRole(ctx context.Context, name string) error {
+	if err := r.repo.Delete(ctx, bson.M{"name": name}); err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+
+	return nil
+}
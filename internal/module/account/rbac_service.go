@@ -0,0 +1,309 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+type RBACService interface {
+	ListRoles(ctx context.Context) ([]*Role, error)
+	CreateRole(ctx context.Context, req *CreateRoleRequest) (*Role, error)
+	UpdateRole(ctx context.Context, name string, req *UpdateRoleRequest) (*Role, error)
+	DeleteRole(ctx context.Context, name string) error
+
+	AssignRoles(ctx context.Context, accountID string, req *AssignRolesRequest) (*AccountResponse, error)
+	GetPermissions(ctx context.Context, accountID string) ([]string, error)
+	HasPermission(ctx context.Context, accountID, permission string) (bool, error)
+}
+
+type rbacService struct {
+	repository        RoleRepository
+	accountRepository AccountRepository
+	bootstrapAdmins   []string
+
+	mu       sync.Mutex
+	cache    map[string]*Role
+	cachedAt time.Time
+}
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+func NewRBACService(mongoService *mongo.MongoService, bootstrapAdmins []string) RBACService {
+	return newRBACService(
+		NewRoleRepository(mongoService),
+		NewAccountRepository(mongoService),
+		bootstrapAdmins,
+	)
+}
+
+// newRBACService takes the emails of accounts that always hold the admin
+// role, so that a fresh deployment has someone able to assign roles.
+func newRBACService(repository RoleRepository, accountRepository AccountRepository, bootstrapAdmins []string) *rbacService {
+	normalized := make([]string, 0, len(bootstrapAdmins))
+	for _, email := range bootstrapAdmins {
+		if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+			normalized = append(normalized, email)
+		}
+	}
+
+	return &rbacService{
+		repository:        repository,
+		accountRepository: accountRepository,
+		bootstrapAdmins:   normalized,
+	}
+}
+
+func (s *rbacService) ListRoles(ctx context.Context) ([]*Role, error) {
+	roles, err := s.loadRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*Role, 0, len(roles))
+	for _, role := range roles {
+		list = append(list, role)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list, nil
+}
+
+func (s *rbacService) CreateRole(ctx context.Context, req *CreateRoleRequest) (*Role, error) {
+	if !roleNamePattern.MatchString(req.Name) {
+		return nil, fmt.Errorf("invalid role: name must be lowercase letters, digits, dashes or underscores")
+	}
+
+	if _, ok := SystemRoles[req.Name]; ok {
+		return nil, fmt.Errorf("role %s already exists", req.Name)
+	}
+
+	if err := validatePermissions(req.Permissions); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repository.GetRoleByName(ctx, req.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check role existence: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("role %s already exists", req.Name)
+	}
+
+	role, err := s.repository.CreateRole(ctx, &Role{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create role: %w", err)
+	}
+
+	s.invalidateCache()
+	return role, nil
+}
+
+func (s *rbacService) UpdateRole(ctx context.Context, name string, req *UpdateRoleRequest) (*Role, error) {
+	if _, ok := SystemRoles[name]; ok {
+		return nil, fmt.Errorf("system role %s cannot be modified", name)
+	}
+
+	updateData := bson.M{}
+	if req.Description != nil {
+		updateData["description"] = *req.Description
+	}
+	if req.Permissions != nil {
+		if err := validatePermissions(req.Permissions); err != nil {
+			return nil, err
+		}
+		updateData["permissions"] = req.Permissions
+	}
+
+	if len(updateData) == 0 {
+		return nil, fmt.Errorf("invalid role: no fields to update")
+	}
+
+	role, err := s.repository.UpdateRole(ctx, name, updateData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update role: %w", err)
+	}
+	if role == nil {
+		return nil, fmt.Errorf("role %s not found", name)
+	}
+
+	s.invalidateCache()
+	return role, nil
+}
+
+func (s *rbacService) DeleteRole(ctx context.Context, name string) error {
+	if _, ok := SystemRoles[name]; ok {
+		return fmt.Errorf("system role %s cannot be modified", name)
+	}
+
+	if err := s.repository.DeleteRole(ctx, name); err != nil {
+		return fmt.Errorf("role %s not found: %w", name, err)
+	}
+
+	s.invalidateCache()
+	return nil
+}
+
+func (s *rbacService) AssignRoles(ctx context.Context, accountID string, req *AssignRolesRequest) (*AccountResponse, error) {
+	objectID, err := primitive.ObjectIDFromHex(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account ID format: %w", err)
+	}
+
+	roles, err := s.loadRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	assigned := make([]string, 0, len(req.Roles))
+	for _, name := range req.Roles {
+		if _, ok := roles[name]; !ok {
+			return nil, fmt.Errorf("role %s not found", name)
+		}
+		if !slices.Contains(assigned, name) {
+			assigned = append(assigned, name)
+		}
+	}
+
+	account, err := s.accountRepository.Update(ctx, objectID, bson.M{
+		"roles":      assigned,
+		"updated_at": time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign roles: %w", err)
+	}
+	if account == nil {
+		return nil, fmt.Errorf("account not found")
+	}
+
+	return account.ToResponse(), nil
+}
+
+// GetPermissions resolves the account's roles on every call so that role
+// changes apply to existing sessions without waiting for tokens to expire.
+func (s *rbacService) GetPermissions(ctx context.Context, accountID string) ([]string, error) {
+	objectID, err := primitive.ObjectIDFromHex(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account ID format: %w", err)
+	}
+
+	account, err := s.accountRepository.GetByID(ctx, objectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	if account == nil {
+		return nil, fmt.Errorf("account not found")
+	}
+
+	roles, err := s.loadRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accountRoles := account.Roles
+	if slices.Contains(s.bootstrapAdmins, strings.ToLower(account.Email)) {
+		accountRoles = append(slices.Clone(accountRoles), RoleAdmin)
+	}
+
+	var permissions []string
+	for _, name := range accountRoles {
+		role, ok := roles[name]
+		if !ok {
+			continue
+		}
+		for _, permission := range role.Permissions {
+			if !slices.Contains(permissions, permission) {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+
+	return permissions, nil
+}
+
+func (s *rbacService) HasPermission(ctx context.Context, accountID, permission string) (bool, error) {
+	permissions, err := s.GetPermissions(ctx, accountID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, granted := range permissions {
+		if permissionMatches(granted, permission) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (s *rbacService) loadRoles(ctx context.Context) (map[string]*Role, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cache != nil && time.Since(s.cachedAt) < RolePermissionCacheTTL {
+		return s.cache, nil
+	}
+
+	stored, err := s.repository.ListRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load roles: %w", err)
+	}
+
+	roles := make(map[string]*Role, len(stored)+len(SystemRoles))
+	for _, role := range stored {
+		roles[role.Name] = role
+	}
+	for name, role := range SystemRoles {
+		roles[name] = role
+	}
+
+	s.cache = roles
+	s.cachedAt = time.Now()
+
+	return roles, nil
+}
+
+func (s *rbacService) invalidateCache() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cache = nil
+}
+
+func validatePermissions(permissions []string) error {
+	if len(permissions) == 0 {
+		return fmt.Errorf("invalid role: at least one permission is required")
+	}
+
+	for _, permission := range permissions {
+		if permission == PermissionAll {
+			continue
+		}
+
+		resource, action, found := strings.Cut(permission, ":")
+		if !found || resource == "" || action == "" {
+			return fmt.Errorf("invalid role: permission %q must have the form resource:action", permission)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package account
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestRole_Grants(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []string
+		permission  string
+		expected    bool
+	}{
+		{name: "exact match", permissions: []string{PermissionAccountsRead}, permission: PermissionAccountsRead, expected: true},
+		{name: "resource wildcard", permissions: []string{"accounts:*"}, permission: PermissionAccountsImpersonate, expected: true},
+		{name: "global wildcard", permissions: []string{PermissionAll}, permission: PermissionRolesManage, expected: true},
+		{name: "other resource wildcard", permissions: []string{"roles:*"}, permission: PermissionAccountsRead, expected: false},
+		{name: "resource prefix is not a wildcard", permissions: []string{"accounts:*"}, permission: "accountsx:read", expected: false},
+		{name: "no permissions", permissions: nil, permission: PermissionAccountsRead, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role := &Role{Permissions: tt.permissions}
+			assert.Equal(t, tt.expected, role.Grants(tt.permission))
+		})
+	}
+}
+
+func TestRBACService_HasPermission(t *testing.T) {
+	auditor := &Role{Name: "auditor", Permissions: []string{PermissionAccountsRead}}
+
+	tests := []struct {
+		name       string
+		account    *Account
+		permission string
+		expected   bool
+	}{
+		{
+			name:       "custom role from Mongo",
+			account:    CreateTestAccount(func(a *Account) { a.Roles = []string{"auditor"} }),
+			permission: PermissionAccountsRead,
+			expected:   true,
+		},
+		{
+			name:       "custom role without the permission",
+			account:    CreateTestAccount(func(a *Account) { a.Roles = []string{"auditor"} }),
+			permission: PermissionAccountsWrite,
+			expected:   false,
+		},
+		{
+			name:       "system admin role",
+			account:    CreateTestAccount(func(a *Account) { a.Roles = []string{RoleAdmin} }),
+			permission: PermissionAccountsImpersonate,
+			expected:   true,
+		},
+		{
+			name:       "bootstrap admin email",
+			account:    CreateTestAccount(func(a *Account) { a.Email = "Root@Example.com" }),
+			permission: PermissionRolesManage,
+			expected:   true,
+		},
+		{
+			name:       "unknown role is ignored",
+			account:    CreateTestAccount(func(a *Account) { a.Roles = []string{"deleted-role"} }),
+			permission: PermissionAccountsRead,
+			expected:   false,
+		},
+		{
+			name:       "no roles",
+			account:    CreateTestAccount(),
+			permission: PermissionAccountsRead,
+			expected:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRoleRepo := &MockRoleRepository{}
+			mockAccountRepo := &MockAccountRepository{}
+			service := newRBACService(mockRoleRepo, mockAccountRepo, []string{" root@example.com "})
+
+			mockRoleRepo.On("ListRoles", mock.Anything).Return([]*Role{auditor}, nil)
+			mockAccountRepo.On("GetByID", mock.Anything, tt.account.ID).Return(tt.account, nil)
+
+			allowed, err := service.HasPermission(context.Background(), tt.account.ID.Hex(), tt.permission)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, allowed)
+		})
+	}
+}
+
+func TestRBACService_RoleCache(t *testing.T) {
+	mockRoleRepo := &MockRoleRepository{}
+	service := newRBACService(mockRoleRepo, &MockAccountRepository{}, nil)
+
+	mockRoleRepo.On("ListRoles", mock.Anything).Return([]*Role{}, nil)
+	mockRoleRepo.On("GetRoleByName", mock.Anything, "auditor").Return(nil, nil)
+	mockRoleRepo.On("CreateRole", mock.Anything, mock.AnythingOfType("*account.Role")).Return(&Role{Name: "auditor"}, nil)
+
+	_, err := service.ListRoles(context.Background())
+	require.NoError(t, err)
+	_, err = service.ListRoles(context.Background())
+	require.NoError(t, err)
+	mockRoleRepo.AssertNumberOfCalls(t, "ListRoles", 1)
+
+	_, err = service.CreateRole(context.Background(), &CreateRoleRequest{
+		Name:        "auditor",
+		Permissions: []string{PermissionAccountsRead},
+	})
+	require.NoError(t, err)
+
+	roles, err := service.ListRoles(context.Background())
+	require.NoError(t, err)
+	mockRoleRepo.AssertNumberOfCalls(t, "ListRoles", 2)
+	assert.Len(t, roles, len(SystemRoles))
+}
+
+func TestRBACService_RoleValidation(t *testing.T) {
+	service := newRBACService(&MockRoleRepository{}, &MockAccountRepository{}, nil)
+	ctx := context.Background()
+
+	_, err := service.CreateRole(ctx, &CreateRoleRequest{Name: RoleAdmin, Permissions: []string{PermissionAccountsRead}})
+	assert.ErrorContains(t, err, "already exists")
+
+	_, err = service.CreateRole(ctx, &CreateRoleRequest{Name: "Auditors", Permissions: []string{PermissionAccountsRead}})
+	assert.ErrorContains(t, err, "invalid role")
+
+	_, err = service.CreateRole(ctx, &CreateRoleRequest{Name: "auditor", Permissions: []string{"read"}})
+	assert.ErrorContains(t, err, "resource:action")
+
+	description := "changed"
+	_, err = service.UpdateRole(ctx, RoleSupport, &UpdateRoleRequest{Description: &description})
+	assert.ErrorContains(t, err, "cannot be modified")
+
+	err = service.DeleteRole(ctx, RoleAdmin)
+	assert.ErrorContains(t, err, "cannot be modified")
+}
+
+func TestRBACService_AssignRoles(t *testing.T) {
+	mockRoleRepo := &MockRoleRepository{}
+	mockAccountRepo := &MockAccountRepository{}
+	service := newRBACService(mockRoleRepo, mockAccountRepo, nil)
+	account := CreateTestAccount()
+
+	mockRoleRepo.On("ListRoles", mock.Anything).Return([]*Role{{Name: "auditor"}}, nil)
+
+	_, err := service.AssignRoles(context.Background(), account.ID.Hex(), &AssignRolesRequest{Roles: []string{"ghost"}})
+	assert.ErrorContains(t, err, "role ghost not found")
+
+	mockAccountRepo.On("Update", mock.Anything, account.ID, mock.MatchedBy(func(update bson.M) bool {
+		roles, ok := update["roles"].([]string)
+		_, hasTimestamp := update["updated_at"].(time.Time)
+		return ok && hasTimestamp && assert.ObjectsAreEqual([]string{"auditor", RoleSupport}, roles)
+	})).Return(CreateTestAccount(func(a *Account) {
+		a.ID = account.ID
+		a.Roles = []string{"auditor", RoleSupport}
+	}), nil)
+
+	response, err := service.AssignRoles(context.Background(), account.ID.Hex(), &AssignRolesRequest{
+		Roles: []string{"auditor", RoleSupport, "auditor"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"auditor", RoleSupport}, response.Roles)
+}
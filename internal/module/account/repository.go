@@ -20,9 +20,12 @@ type AccountRepository interface {
 	GetByEmail(ctx context.Context, email string) (*Account, error)
 	GetByUsername(ctx context.Context, username string) (*Account, error)
 	Update(ctx context.Context, id primitive.ObjectID, updateData bson.M) (*Account, error)
-	UpdatePasswordHash(ctx context.Context, id primitive.ObjectID, passwordHash string) (*Account, error)
+	UpdatePasswordHash(ctx context.Context, id primitive.ObjectID, passwordHash string, history []string) (*Account, error)
+	ChangeEmail(ctx context.Context, id primitive.ObjectID, currentEmail, newEmail string) (*Account, error)
 	Delete(ctx context.Context, id primitive.ObjectID) error
 	List(ctx context.Context, filter bson.M, pagination mongo.PaginationOptions) (*mongo.PaginatedResult[Account], error)
+	Search(ctx context.Context, filter bson.M, sort bson.D, pagination mongo.PaginationOptions) (*mongo.PaginatedResult[Account], error)
+	FindBatch(ctx context.Context, filter bson.M, sort bson.D, limit int64) ([]Account, error)
 	Count(ctx context.Context, filter bson.M) (int64, error)
 	ExistsByEmail(ctx context.Context, email string) (bool, error)
 	ExistsByUsername(ctx context.Context, username string) (bool, error)
@@ -107,15 +110,47 @@ func (r *accountRepository) Update(ctx context.Context, id primitive.ObjectID, u
 	return result, nil
 }
 
-func (r *accountRepository) UpdatePasswordHash(ctx context.Context, id primitive.ObjectID, passwordHash string) (*Account, error) {
+func (r *accountRepository) UpdatePasswordHash(ctx context.Context, id primitive.ObjectID, passwordHash string, history []string) (*Account, error) {
 	updateData := bson.M{
-		"password_hash": passwordHash,
-		"updated_at":    time.Now(),
+		"password_hash":           passwordHash,
+		"password_history":        history,
+		"password_changed_at":     time.Now(),
+		"password_reset_required": false,
+		"updated_at":              time.Now(),
 	}
 	
 	return r.Update(ctx, id, updateData)
 }
 
+// ChangeEmail moves the account to newEmail only while it still has
+// currentEmail and no other account uses newEmail. It returns nil when either
+// condition no longer holds.
+func (r *accountRepository) ChangeEmail(ctx context.Context, id primitive.ObjectID, currentEmail, newEmail string) (*Account, error) {
+	exists, err := r.ExistsByEmail(ctx, newEmail)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, nil
+	}
+
+	filter := bson.M{"_id": id, "email": currentEmail}
+	update := bson.M{
+		"$set": bson.M{
+			"email":             newEmail,
+			"email_verified_at": time.Now(),
+			"updated_at":        time.Now(),
+		},
+	}
+
+	result, err := r.repo.Update(ctx, filter, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to change account email: %w", err)
+	}
+
+	return result, nil
+}
+
 func (r *accountRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
 	filter := bson.M{"_id": id}
 	
@@ -128,7 +163,11 @@ func (r *accountRepository) Delete(ctx context.Context, id primitive.ObjectID) e
 }
 
 func (r *accountRepository) List(ctx context.Context, filter bson.M, pagination mongo.PaginationOptions) (*mongo.PaginatedResult[Account], error) {
-	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	return r.Search(ctx, filter, bson.D{{Key: "created_at", Value: -1}}, pagination)
+}
+
+func (r *accountRepository) Search(ctx context.Context, filter bson.M, sort bson.D, pagination mongo.PaginationOptions) (*mongo.PaginatedResult[Account], error) {
+	opts := options.Find().SetSort(sort)
 	
 	result, err := r.repo.FindWithPagination(ctx, filter, pagination, opts)
 	if err != nil {
@@ -138,6 +177,19 @@ func (r *accountRepository) List(ctx context.Context, filter bson.M, pagination
 	return result, nil
 }
 
+// FindBatch returns up to limit accounts in sort order, for keyset
+// pagination where the filter already excludes the accounts seen so far.
+func (r *accountRepository) FindBatch(ctx context.Context, filter bson.M, sort bson.D, limit int64) ([]Account, error) {
+	opts := options.Find().SetSort(sort).SetLimit(limit)
+
+	accounts, err := r.repo.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+
+	return accounts, nil
+}
+
 func (r *accountRepository) Count(ctx context.Context, filter bson.M) (int64, error) {
 	count, err := r.repo.Count(ctx, filter)
 	if err != nil {
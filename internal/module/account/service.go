@@ -2,7 +2,9 @@ package account
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/argon2"
 	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/jwt"
 	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/ratelimit"
 	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/redis"
 	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/resend"
 )
@@ -35,14 +38,29 @@ type AccountService interface {
 	ValidateToken(ctx context.Context, token string) (*ValidateTokenResponse, error)
 	RefreshToken(ctx context.Context, token string, userAgent, ipAddress string) (*RefreshTokenResponse, error)
 	GetCurrentUser(ctx context.Context, token string) (*MeResponse, error)
+	IssueSession(ctx context.Context, accountID string, userAgent, ipAddress string) (*LoginResponse, error)
+
+	RequestAccountUnlock(ctx context.Context, req *RequestAccountUnlockRequest) error
+	UnlockAccount(ctx context.Context, req *UnlockAccountRequest) error
+
+	RequestEmailChange(ctx context.Context, accountID string, req *RequestEmailChangeRequest, userAgent, ipAddress string) error
+	ConfirmEmailChange(ctx context.Context, accountID, token string, req *ConfirmEmailChangeRequest, userAgent, ipAddress string) (*AccountResponse, error)
+
+	ReportLogin(ctx context.Context, token string) error
 }
 
 type accountService struct {
 	repository                AccountRepository
 	accountIdentityRepository AccountIdentityRepository
+	emailChangeRepository     EmailChangeRepository
+	knownDeviceRepository     KnownDeviceRepository
 	jwtService                *jwt.JWTService
 	resendService             resend.ResendService
 	fromEmail                 string
+	limiter                   ratelimit.Limiter
+	auditService              AuditRecorder
+	loginReportURL            string
+	passwordPolicy            *PasswordPolicy
 }
 
 func NewAccountService(
@@ -51,32 +69,43 @@ func NewAccountService(
 	jwtService *jwt.JWTService,
 	resendService resend.ResendService,
 	fromEmail string,
+	limiter ratelimit.Limiter,
+	auditService AuditRecorder,
+	loginReportURL string,
+	passwordPolicy *PasswordPolicy,
 ) AccountService {
-	repository := NewAccountRepository(mongoService)
+	return &accountService{
+		repository:                NewAccountRepository(mongoService),
+		accountIdentityRepository: newAccountIdentityRepository(mongoService, cacheService),
+		emailChangeRepository:     NewEmailChangeRepository(mongoService),
+		knownDeviceRepository:     NewKnownDeviceRepository(mongoService),
+		jwtService:                jwtService,
+		resendService:             resendService,
+		fromEmail:                 fromEmail,
+		limiter:                   limiter,
+		auditService:              auditService,
+		loginReportURL:            loginReportURL,
+		passwordPolicy:            passwordPolicy,
+	}
+}
 
-	var accountIdentityRepository AccountIdentityRepository
+// newAccountIdentityRepository picks the OTP and session store shared by every
+// service that issues or checks sessions, preferring the cache when available.
+func newAccountIdentityRepository(mongoService *mongo.MongoService, cacheService redis.RedisService) AccountIdentityRepository {
 	if cacheService != nil {
 		cacheConfig := HybridRepositoryConfig{
 			UseCacheForOTP:     true,
 			UseCacheForSession: true,
 			EnableFallback:     true,
 		}
-		accountIdentityRepository = NewHybridAccountIdentityRepository(
+		return NewHybridAccountIdentityRepository(
 			mongoService,
 			cacheService,
 			cacheConfig,
 		)
-	} else {
-		accountIdentityRepository = NewAccountIdentityRepository(mongoService)
 	}
 
-	return &accountService{
-		repository:                repository,
-		accountIdentityRepository: accountIdentityRepository,
-		jwtService:                jwtService,
-		resendService:             resendService,
-		fromEmail:                 fromEmail,
-	}
+	return NewAccountIdentityRepository(mongoService)
 }
 
 func (s *accountService) CreateAccount(ctx context.Context, req *CreateAccountRequest) (*AccountResponse, error) {
@@ -102,6 +131,7 @@ func (s *accountService) CreateAccount(ctx context.Context, req *CreateAccountRe
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
 		Avatar:    req.Avatar,
+		Status:    AccountStatusActive,
 		IsActive:  true,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -200,7 +230,21 @@ func (s *accountService) UpdateAccount(ctx context.Context, id string, req *Upda
 	}
 
 	if req.IsActive != nil {
-		updateData["is_active"] = *req.IsActive
+		account, err := s.repository.GetByID(ctx, objectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get account: %w", err)
+		}
+		if account == nil || account.CurrentStatus() == AccountStatusDeleted {
+			return nil, fmt.Errorf("account not found")
+		}
+
+		status := AccountStatusSuspended
+		if *req.IsActive {
+			status = AccountStatusActive
+		}
+		for key, value := range statusUpdate(status) {
+			updateData[key] = value
+		}
 	}
 
 	updatedAccount, err := s.repository.Update(ctx, objectID, updateData)
@@ -226,34 +270,114 @@ func (s *accountService) DeleteAccount(ctx context.Context, id string) error {
 		return fmt.Errorf("failed to get account: %w", err)
 	}
 
-	if account == nil {
+	if account == nil || account.CurrentStatus() == AccountStatusDeleted {
 		return fmt.Errorf("account not found")
 	}
 
-	err = s.repository.Delete(ctx, objectID)
+	// The account is only marked here; the purge worker removes it and its
+	// data once AccountDeletionGracePeriod has passed.
+	updateData := statusUpdate(AccountStatusDeleted)
+	updateData["deleted_at"] = time.Now()
+
+	_, err = s.repository.Update(ctx, objectID, updateData)
 	if err != nil {
 		return fmt.Errorf("failed to delete account: %w", err)
 	}
 
+	s.revokeSessions(ctx, id, "account deleted")
+
+	recordAudit(ctx, s.auditService, &AuditEvent{
+		Type:     AuditEventAccountDelete,
+		TargetID: id,
+	})
+
 	return nil
 }
 
 
 func (s *accountService) Login(ctx context.Context, req *LoginRequest, userAgent, ipAddress string) (*LoginResponse, error) {
+	if err := s.checkRateLimit(ctx, rateLimitKey("login", "email", req.Email), LoginEmailRateLimit, "too many login attempts for this email"); err != nil {
+		return nil, err
+	}
+
 	account, err := s.repository.GetByEmail(ctx, req.Email)
 	if err != nil || account == nil {
+		s.auditLoginFailure(ctx, req.Email, nil, "unknown email")
 		return nil, fmt.Errorf("invalid email or password")
 	}
 
+	if account.IsLocked() {
+		s.auditLoginFailure(ctx, req.Email, account, "account locked")
+		return nil, lockedError(account)
+	}
+
 	if !account.IsActive {
-		return nil, fmt.Errorf("account is inactive")
+		s.auditLoginFailure(ctx, req.Email, account, "account "+string(account.CurrentStatus()))
+		return nil, account.inactiveError()
 	}
 
 	isValid, err := argon2.VerifyPassword(req.Password, account.PasswordHash)
 	if err != nil || !isValid {
+		s.auditLoginFailure(ctx, req.Email, account, "invalid password")
+		s.recordLoginFailure(ctx, account)
 		return nil, fmt.Errorf("invalid email or password")
 	}
 
+	if s.passwordPolicy != nil && s.passwordPolicy.IsExpired(account) {
+		s.auditLoginFailure(ctx, req.Email, account, "password expired")
+		return nil, fmt.Errorf("account is inactive: password has expired, reset it to sign in")
+	}
+
+	if err := s.clearLoginFailures(ctx, account); err != nil {
+		fmt.Printf("Failed to clear login failures: %v\n", err)
+	}
+
+	s.rehashPassword(ctx, account, req.Password)
+
+	return s.issueSession(ctx, account, "password", userAgent, ipAddress)
+}
+
+func (s *accountService) auditLoginFailure(ctx context.Context, email string, account *Account, reason string) {
+	event := &AuditEvent{
+		Type:     AuditEventLogin,
+		Outcome:  AuditOutcomeFailure,
+		Reason:   reason,
+		Metadata: map[string]string{"email": email},
+	}
+	if account != nil {
+		event.TargetID = account.ID.Hex()
+	}
+
+	recordAudit(ctx, s.auditService, event)
+}
+
+// IssueSession signs a token and opens a session for an account that has
+// already been authenticated by some other means, such as a passkey.
+func (s *accountService) IssueSession(ctx context.Context, accountID string, userAgent, ipAddress string) (*LoginResponse, error) {
+	objectID, err := primitive.ObjectIDFromHex(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account ID format: %w", err)
+	}
+
+	account, err := s.repository.GetByID(ctx, objectID)
+	if err != nil || account == nil {
+		return nil, fmt.Errorf("account not found")
+	}
+
+	if !account.IsActive {
+		return nil, account.inactiveError()
+	}
+
+	return s.issueSession(ctx, account, "external", userAgent, ipAddress)
+}
+
+// issueSession records a successful login; method says how the account was
+// authenticated.
+func (s *accountService) issueSession(ctx context.Context, account *Account, method, userAgent, ipAddress string) (*LoginResponse, error) {
+	if account.PasswordResetRequired {
+		return nil, fmt.Errorf("account is inactive: password reset required, check your email for a reset code")
+	}
+
 	claims := &AccountJWTClaims{
 		AccountID: account.ID.Hex(),
 		Email:     account.Email,
@@ -278,6 +402,17 @@ func (s *accountService) Login(ctx context.Context, req *LoginRequest, userAgent
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
 
+	recordAudit(ctx, s.auditService, &AuditEvent{
+		Type:      AuditEventLogin,
+		ActorID:   account.ID.Hex(),
+		TargetID:  account.ID.Hex(),
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Metadata:  map[string]string{"method": method},
+	})
+
+	s.checkLoginDevice(ctx, account, userAgent, ipAddress)
+
 	return &LoginResponse{
 		Token:   token,
 		Account: account.ToResponse(),
@@ -292,10 +427,16 @@ func (s *accountService) Logout(ctx context.Context, token string) error {
 		return fmt.Errorf("failed to logout: %w", err)
 	}
 
+	recordAudit(ctx, s.auditService, &AuditEvent{Type: AuditEventLogout})
+
 	return nil
 }
 
 func (s *accountService) Register(ctx context.Context, req *RegisterRequest) (*RegisterResponse, error) {
+	if err := s.checkPasswordPolicy(req.Password, nil); err != nil {
+		return nil, err
+	}
+
 	hashedPassword, err := argon2.HashPassword(req.Password)
 	if err != nil {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
@@ -308,6 +449,7 @@ func (s *accountService) Register(ctx context.Context, req *RegisterRequest) (*R
 		LastName:     req.LastName,
 		Avatar:       req.Avatar,
 		PasswordHash: hashedPassword,
+		Status:       AccountStatusPending,
 		IsActive:     false,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
@@ -376,21 +518,38 @@ func (s *accountService) Register(ctx context.Context, req *RegisterRequest) (*R
 }
 
 func (s *accountService) VerifyEmail(ctx context.Context, req *VerifyEmailRequest) error {
+	if err := s.checkRateLimit(ctx, rateLimitKey("otp", "verify", req.Email), OTPVerifyRateLimit, "too many verification attempts for this email"); err != nil {
+		return err
+	}
+
 	_, err := s.accountIdentityRepository.ValidateOTP(ctx, req.Email, OTPPurposeEmailVerification, req.OTP)
 	if err != nil {
+		recordAudit(ctx, s.auditService, &AuditEvent{
+			Type:     AuditEventEmailVerification,
+			Outcome:  AuditOutcomeFailure,
+			Reason:   err.Error(),
+			Metadata: map[string]string{"email": req.Email},
+		})
 		return fmt.Errorf("email verification failed: %w", err)
 	}
 
-	accountResp, err := s.GetAccountByEmail(ctx, req.Email)
-	if err != nil {
-		return fmt.Errorf("account not found: %w", err)
+	account, err := s.repository.GetByEmail(ctx, req.Email)
+	if err != nil || account == nil || account.CurrentStatus() == AccountStatusDeleted {
+		return fmt.Errorf("account not found")
 	}
 
-	updateReq := &UpdateAccountRequest{
-		IsActive: &[]bool{true}[0],
+	// Verifying only activates pending accounts; a suspended account stays
+	// suspended but is recorded as verified.
+	updateData := bson.M{
+		"email_verified_at": time.Now(),
+		"updated_at":        time.Now(),
+	}
+	if account.CurrentStatus() == AccountStatusPending {
+		updateData = statusUpdate(AccountStatusActive)
+		updateData["email_verified_at"] = time.Now()
 	}
 
-	_, err = s.UpdateAccount(ctx, accountResp.ID, updateReq)
+	_, err = s.repository.Update(ctx, account.ID, updateData)
 	if err != nil {
 		return fmt.Errorf("failed to activate account: %w", err)
 	}
@@ -400,16 +559,27 @@ func (s *accountService) VerifyEmail(ctx context.Context, req *VerifyEmailReques
 		fmt.Printf("Failed to delete OTP: %v\n", err)
 	}
 
+	recordAudit(ctx, s.auditService, &AuditEvent{
+		Type:     AuditEventEmailVerification,
+		ActorID:  account.ID.Hex(),
+		TargetID: account.ID.Hex(),
+		Metadata: map[string]string{"email": req.Email},
+	})
+
 	return nil
 }
 
 func (s *accountService) ResendEmailVerification(ctx context.Context, req *ResendVerificationRequest) error {
-	accountResp, err := s.GetAccountByEmail(ctx, req.Email)
-	if err != nil {
+	if err := s.checkRateLimit(ctx, rateLimitKey("otp", "request", req.Email), OTPRequestRateLimit, "too many codes requested for this email"); err != nil {
+		return err
+	}
+
+	account, err := s.repository.GetByEmail(ctx, req.Email)
+	if err != nil || account == nil || account.CurrentStatus() == AccountStatusDeleted {
 		return fmt.Errorf("account not found")
 	}
 
-	if accountResp.IsActive {
+	if account.IsEmailVerified() {
 		return fmt.Errorf("email is already verified")
 	}
 
@@ -438,6 +608,10 @@ func (s *accountService) ResendEmailVerification(ctx context.Context, req *Resen
 }
 
 func (s *accountService) ForgotPassword(ctx context.Context, req *ForgotPasswordRequest) error {
+	if err := s.checkRateLimit(ctx, rateLimitKey("otp", "request", req.Email), OTPRequestRateLimit, "too many codes requested for this email"); err != nil {
+		return err
+	}
+
 	accountResp, err := s.GetAccountByEmail(ctx, req.Email)
 	if err != nil {
 		return nil
@@ -472,8 +646,18 @@ func (s *accountService) ForgotPassword(ctx context.Context, req *ForgotPassword
 }
 
 func (s *accountService) ResetPassword(ctx context.Context, req *ResetPasswordRequest) error {
+	if err := s.checkRateLimit(ctx, rateLimitKey("otp", "verify", req.Email), OTPVerifyRateLimit, "too many verification attempts for this email"); err != nil {
+		return err
+	}
+
 	_, err := s.accountIdentityRepository.ValidateOTP(ctx, req.Email, OTPPurposePasswordReset, req.OTP)
 	if err != nil {
+		recordAudit(ctx, s.auditService, &AuditEvent{
+			Type:     AuditEventPasswordReset,
+			Outcome:  AuditOutcomeFailure,
+			Reason:   err.Error(),
+			Metadata: map[string]string{"email": req.Email},
+		})
 		return fmt.Errorf("password reset failed: %w", err)
 	}
 
@@ -482,20 +666,27 @@ func (s *accountService) ResetPassword(ctx context.Context, req *ResetPasswordRe
 		return fmt.Errorf("account not found: %w", err)
 	}
 
+	if err := s.checkPasswordPolicy(req.NewPassword, account); err != nil {
+		return err
+	}
+
 	hashedPassword, err := argon2.HashPassword(req.NewPassword)
 	if err != nil {
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	_, err = s.repository.UpdatePasswordHash(ctx, account.ID, hashedPassword)
+	_, err = s.repository.UpdatePasswordHash(ctx, account.ID, hashedPassword, s.nextPasswordHistory(account))
 	if err != nil {
 		return fmt.Errorf("failed to update password: %w", err)
 	}
 
-	err = s.accountIdentityRepository.DeactivateAllUserSessions(ctx, account.ID.Hex())
-	if err != nil {
-		fmt.Printf("Failed to deactivate user sessions: %v\n", err)
-	}
+	recordAudit(ctx, s.auditService, &AuditEvent{
+		Type:     AuditEventPasswordReset,
+		ActorID:  account.ID.Hex(),
+		TargetID: account.ID.Hex(),
+	})
+
+	s.revokeSessions(ctx, account.ID.Hex(), "password reset")
 
 	err = s.accountIdentityRepository.DeleteOTP(ctx, req.Email, OTPPurposePasswordReset)
 	if err != nil {
@@ -538,23 +729,35 @@ func (s *accountService) ChangePassword(ctx context.Context, accountID string, r
 
 	isValid, err := argon2.VerifyPassword(req.OldPassword, account.PasswordHash)
 	if err != nil || !isValid {
+		recordAudit(ctx, s.auditService, &AuditEvent{
+			Type:     AuditEventPasswordChange,
+			Outcome:  AuditOutcomeFailure,
+			TargetID: accountID,
+			Reason:   "current password is incorrect",
+		})
 		return fmt.Errorf("current password is incorrect")
 	}
 
+	if err := s.checkPasswordPolicy(req.NewPassword, account); err != nil {
+		return err
+	}
+
 	hashedPassword, err := argon2.HashPassword(req.NewPassword)
 	if err != nil {
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	_, err = s.repository.UpdatePasswordHash(ctx, account.ID, hashedPassword)
+	_, err = s.repository.UpdatePasswordHash(ctx, account.ID, hashedPassword, s.nextPasswordHistory(account))
 	if err != nil {
 		return fmt.Errorf("failed to update password: %w", err)
 	}
 
-	err = s.accountIdentityRepository.DeactivateAllUserSessions(ctx, accountID)
-	if err != nil {
-		fmt.Printf("Failed to deactivate user sessions: %v\n", err)
-	}
+	recordAudit(ctx, s.auditService, &AuditEvent{
+		Type:     AuditEventPasswordChange,
+		TargetID: accountID,
+	})
+
+	s.revokeSessions(ctx, accountID, "password changed")
 
 	if s.resendService != nil {
 		template := GetPasswordChangeConfirmationTemplate()
@@ -617,6 +820,14 @@ func (s *accountService) RefreshToken(ctx context.Context, token string, userAge
 		return nil, fmt.Errorf("invalid token")
 	}
 
+	if validateResp.Claims.IsDelegated() {
+		return nil, fmt.Errorf("invalid token: OAuth access tokens must be refreshed at the token endpoint")
+	}
+
+	if validateResp.Claims.ImpersonatorID != "" {
+		return nil, fmt.Errorf("invalid token: impersonation tokens cannot be refreshed")
+	}
+
 	newToken, err := s.jwtService.Refresh(token)
 	if err != nil {
 		return nil, fmt.Errorf("failed to refresh token: %w", err)
@@ -664,7 +875,33 @@ func (s *accountService) GetCurrentUser(ctx context.Context, token string) (*MeR
 	}, nil
 }
 
+// revokeSessions signs the account out everywhere and records why.
+func (s *accountService) revokeSessions(ctx context.Context, accountID, reason string) {
+	if err := s.accountIdentityRepository.DeactivateAllUserSessions(ctx, accountID); err != nil {
+		fmt.Printf("Failed to deactivate sessions of account %s: %v\n", accountID, err)
+		return
+	}
+
+	recordAudit(ctx, s.auditService, &AuditEvent{
+		Type:     AuditEventSessionRevoke,
+		TargetID: accountID,
+		Reason:   reason,
+	})
+}
+
 func (s *accountService) hashToken(token string) string {
-	hash := sha256.Sum256([]byte(token))
+	return hashSecret(token)
+}
+
+func hashSecret(value string) string {
+	hash := sha256.Sum256([]byte(value))
 	return fmt.Sprintf("%x", hash)
 }
+
+func generateRandomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
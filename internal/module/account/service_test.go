@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 )
@@ -382,34 +383,53 @@ func TestAccountService_UpdateAccount(t *testing.T) {
 
 func TestAccountService_DeleteAccount(t *testing.T) {
 	validID := primitive.NewObjectID()
+	deletedAt := time.Now()
 
 	tests := []struct {
 		name          string
 		id            string
-		setupMock     func(*MockAccountRepository)
+		setupMock     func(*MockAccountRepository, *MockAccountIdentityRepository)
 		expectedError string
 		expectSuccess bool
 	}{
 		{
 			name: "successful delete",
 			id:   validID.Hex(),
-			setupMock: func(mockRepo *MockAccountRepository) {
-				mockRepo.On("Delete", mock.Anything, validID).Return(nil)
+			setupMock: func(mockRepo *MockAccountRepository, mockIdentityRepo *MockAccountIdentityRepository) {
+				mockRepo.On("GetByID", mock.Anything, validID).Return(CreateTestAccount(func(a *Account) { a.ID = validID }), nil)
+				mockRepo.On("Update", mock.Anything, validID, mock.MatchedBy(func(update bson.M) bool {
+					_, hasDeletedAt := update["deleted_at"].(time.Time)
+					return update["status"] == AccountStatusDeleted && update["is_active"] == false && hasDeletedAt
+				})).Return(CreateTestAccount(), nil)
+				mockIdentityRepo.On("DeactivateAllUserSessions", mock.Anything, validID.Hex()).Return(nil)
 			},
 			expectSuccess: true,
 		},
 		{
 			name:          "invalid ID",
 			id:            "invalid-id",
-			setupMock:     func(mockRepo *MockAccountRepository) {},
+			setupMock:     func(*MockAccountRepository, *MockAccountIdentityRepository) {},
 			expectedError: "invalid account ID format",
 			expectSuccess: false,
 		},
+		{
+			name: "already deleted",
+			id:   validID.Hex(),
+			setupMock: func(mockRepo *MockAccountRepository, _ *MockAccountIdentityRepository) {
+				mockRepo.On("GetByID", mock.Anything, validID).Return(CreateTestAccount(func(a *Account) {
+					a.Status = AccountStatusDeleted
+					a.DeletedAt = &deletedAt
+				}), nil)
+			},
+			expectedError: "account not found",
+			expectSuccess: false,
+		},
 		{
 			name: "delete fails",
 			id:   validID.Hex(),
-			setupMock: func(mockRepo *MockAccountRepository) {
-				mockRepo.On("Delete", mock.Anything, validID).Return(errors.New("delete failed"))
+			setupMock: func(mockRepo *MockAccountRepository, _ *MockAccountIdentityRepository) {
+				mockRepo.On("GetByID", mock.Anything, validID).Return(CreateTestAccount(), nil)
+				mockRepo.On("Update", mock.Anything, validID, mock.Anything).Return(nil, errors.New("update failed"))
 			},
 			expectedError: "failed to delete account",
 			expectSuccess: false,
@@ -419,9 +439,10 @@ func TestAccountService_DeleteAccount(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := &MockAccountRepository{}
-			tt.setupMock(mockRepo)
+			mockIdentityRepo := &MockAccountIdentityRepository{}
+			tt.setupMock(mockRepo, mockIdentityRepo)
 
-			service := &accountService{repository: mockRepo}
+			service := &accountService{repository: mockRepo, accountIdentityRepository: mockIdentityRepo}
 
 			err := service.DeleteAccount(context.Background(), tt.id)
 
@@ -435,6 +456,7 @@ func TestAccountService_DeleteAccount(t *testing.T) {
 			}
 
 			mockRepo.AssertExpectations(t)
+			mockIdentityRepo.AssertExpectations(t)
 		})
 	}
 }
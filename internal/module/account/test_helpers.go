@@ -9,6 +9,8 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/resend"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/vault"
 )
 
 type MockAccountRepository struct {
@@ -55,6 +57,14 @@ func (m *MockAccountRepository) Update(ctx context.Context, id primitive.ObjectI
 	return args.Get(0).(*Account), args.Error(1)
 }
 
+func (m *MockAccountRepository) ChangeEmail(ctx context.Context, id primitive.ObjectID, currentEmail, newEmail string) (*Account, error) {
+	args := m.Called(ctx, id, currentEmail, newEmail)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Account), args.Error(1)
+}
+
 func (m *MockAccountRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -68,6 +78,22 @@ func (m *MockAccountRepository) List(ctx context.Context, filter bson.M, paginat
 	return args.Get(0).(*mongo.PaginatedResult[Account]), args.Error(1)
 }
 
+func (m *MockAccountRepository) Search(ctx context.Context, filter bson.M, sort bson.D, pagination mongo.PaginationOptions) (*mongo.PaginatedResult[Account], error) {
+	args := m.Called(ctx, filter, sort, pagination)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*mongo.PaginatedResult[Account]), args.Error(1)
+}
+
+func (m *MockAccountRepository) FindBatch(ctx context.Context, filter bson.M, sort bson.D, limit int64) ([]Account, error) {
+	args := m.Called(ctx, filter, sort, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Account), args.Error(1)
+}
+
 func (m *MockAccountRepository) Count(ctx context.Context, filter bson.M) (int64, error) {
 	args := m.Called(ctx, filter)
 	return args.Get(0).(int64), args.Error(1)
@@ -83,8 +109,8 @@ func (m *MockAccountRepository) ExistsByUsername(ctx context.Context, username s
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockAccountRepository) UpdatePasswordHash(ctx context.Context, id primitive.ObjectID, passwordHash string) (*Account, error) {
-	args := m.Called(ctx, id, passwordHash)
+func (m *MockAccountRepository) UpdatePasswordHash(ctx context.Context, id primitive.ObjectID, passwordHash string, history []string) (*Account, error) {
+	args := m.Called(ctx, id, passwordHash, history)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -172,6 +198,34 @@ func (m *MockAccountService) ResendEmailVerification(ctx context.Context, req *R
 	return args.Error(0)
 }
 
+func (m *MockAccountService) RequestAccountUnlock(ctx context.Context, req *RequestAccountUnlockRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) UnlockAccount(ctx context.Context, req *UnlockAccountRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) RequestEmailChange(ctx context.Context, accountID string, req *RequestEmailChangeRequest, userAgent, ipAddress string) error {
+	args := m.Called(ctx, accountID, req, userAgent, ipAddress)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) ConfirmEmailChange(ctx context.Context, accountID, token string, req *ConfirmEmailChangeRequest, userAgent, ipAddress string) (*AccountResponse, error) {
+	args := m.Called(ctx, accountID, token, req, userAgent, ipAddress)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*AccountResponse), args.Error(1)
+}
+
+func (m *MockAccountService) ReportLogin(ctx context.Context, token string) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
 func (m *MockAccountService) ForgotPassword(ctx context.Context, req *ForgotPasswordRequest) error {
 	args := m.Called(ctx, req)
 	return args.Error(0)
@@ -211,6 +265,14 @@ func (m *MockAccountService) GetCurrentUser(ctx context.Context, token string) (
 	return args.Get(0).(*MeResponse), args.Error(1)
 }
 
+func (m *MockAccountService) IssueSession(ctx context.Context, accountID string, userAgent, ipAddress string) (*LoginResponse, error) {
+	args := m.Called(ctx, accountID, userAgent, ipAddress)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*LoginResponse), args.Error(1)
+}
+
 type MockAccountIdentityRepository struct {
 	mock.Mock
 }
@@ -534,3 +596,574 @@ func CreateTestValidateTokenResponse(overrides ...func(*ValidateTokenResponse))
 
 	return resp
 }
+
+type MockPasskeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasskeyRepository) CreatePasskey(ctx context.Context, passkey *PasskeyCredential) (*PasskeyCredential, error) {
+	args := m.Called(ctx, passkey)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*PasskeyCredential), args.Error(1)
+}
+
+func (m *MockPasskeyRepository) GetPasskeyByCredentialID(ctx context.Context, credentialID []byte) (*PasskeyCredential, error) {
+	args := m.Called(ctx, credentialID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*PasskeyCredential), args.Error(1)
+}
+
+func (m *MockPasskeyRepository) GetPasskeysByAccountID(ctx context.Context, accountID string) ([]*PasskeyCredential, error) {
+	args := m.Called(ctx, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*PasskeyCredential), args.Error(1)
+}
+
+func (m *MockPasskeyRepository) UpdatePasskeyUsage(ctx context.Context, id primitive.ObjectID, signCount uint32, backupState bool) error {
+	args := m.Called(ctx, id, signCount, backupState)
+	return args.Error(0)
+}
+
+func (m *MockPasskeyRepository) FlagPasskeyCloned(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPasskeyRepository) DeletePasskey(ctx context.Context, accountID string, id primitive.ObjectID) error {
+	args := m.Called(ctx, accountID, id)
+	return args.Error(0)
+}
+
+func (m *MockPasskeyRepository) CreateCeremony(ctx context.Context, ceremony *PasskeyCeremony) (*PasskeyCeremony, error) {
+	args := m.Called(ctx, ceremony)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*PasskeyCeremony), args.Error(1)
+}
+
+func (m *MockPasskeyRepository) ConsumeCeremony(ctx context.Context, id primitive.ObjectID) (*PasskeyCeremony, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*PasskeyCeremony), args.Error(1)
+}
+
+type MockFederationRepository struct {
+	mock.Mock
+}
+
+func (m *MockFederationRepository) CreateIdentity(ctx context.Context, identity *FederatedIdentity) (*FederatedIdentity, error) {
+	args := m.Called(ctx, identity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*FederatedIdentity), args.Error(1)
+}
+
+func (m *MockFederationRepository) GetIdentity(ctx context.Context, provider, subject string) (*FederatedIdentity, error) {
+	args := m.Called(ctx, provider, subject)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*FederatedIdentity), args.Error(1)
+}
+
+func (m *MockFederationRepository) GetIdentitiesByAccountID(ctx context.Context, accountID string) ([]*FederatedIdentity, error) {
+	args := m.Called(ctx, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*FederatedIdentity), args.Error(1)
+}
+
+func (m *MockFederationRepository) UpdateIdentityLastLogin(ctx context.Context, id primitive.ObjectID, email string, emailVerified bool) error {
+	args := m.Called(ctx, id, email, emailVerified)
+	return args.Error(0)
+}
+
+func (m *MockFederationRepository) CreateAuthState(ctx context.Context, state *OIDCAuthState) (*OIDCAuthState, error) {
+	args := m.Called(ctx, state)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*OIDCAuthState), args.Error(1)
+}
+
+func (m *MockFederationRepository) ConsumeAuthState(ctx context.Context, state string) (*OIDCAuthState, error) {
+	args := m.Called(ctx, state)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*OIDCAuthState), args.Error(1)
+}
+
+type MockOAuthRepository struct {
+	mock.Mock
+}
+
+func (m *MockOAuthRepository) CreateClient(ctx context.Context, client *OAuthClient) (*OAuthClient, error) {
+	args := m.Called(ctx, client)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*OAuthClient), args.Error(1)
+}
+
+func (m *MockOAuthRepository) GetClientByClientID(ctx context.Context, clientID string) (*OAuthClient, error) {
+	args := m.Called(ctx, clientID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*OAuthClient), args.Error(1)
+}
+
+func (m *MockOAuthRepository) GetClientsByOwner(ctx context.Context, ownerAccountID string) ([]*OAuthClient, error) {
+	args := m.Called(ctx, ownerAccountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*OAuthClient), args.Error(1)
+}
+
+func (m *MockOAuthRepository) DeleteClient(ctx context.Context, ownerAccountID, clientID string) error {
+	args := m.Called(ctx, ownerAccountID, clientID)
+	return args.Error(0)
+}
+
+func (m *MockOAuthRepository) CreateAuthorizationCode(ctx context.Context, code *OAuthAuthorizationCode) (*OAuthAuthorizationCode, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*OAuthAuthorizationCode), args.Error(1)
+}
+
+func (m *MockOAuthRepository) ConsumeAuthorizationCode(ctx context.Context, codeHash string) (*OAuthAuthorizationCode, error) {
+	args := m.Called(ctx, codeHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*OAuthAuthorizationCode), args.Error(1)
+}
+
+func (m *MockOAuthRepository) GetConsent(ctx context.Context, accountID, clientID string) (*OAuthConsent, error) {
+	args := m.Called(ctx, accountID, clientID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*OAuthConsent), args.Error(1)
+}
+
+func (m *MockOAuthRepository) SaveConsent(ctx context.Context, accountID, clientID string, scopes []string) error {
+	args := m.Called(ctx, accountID, clientID, scopes)
+	return args.Error(0)
+}
+
+func (m *MockOAuthRepository) CreateRefreshToken(ctx context.Context, token *OAuthRefreshToken) (*OAuthRefreshToken, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*OAuthRefreshToken), args.Error(1)
+}
+
+func (m *MockOAuthRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*OAuthRefreshToken, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*OAuthRefreshToken), args.Error(1)
+}
+
+func (m *MockOAuthRepository) ConsumeRefreshToken(ctx context.Context, id primitive.ObjectID) (*OAuthRefreshToken, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*OAuthRefreshToken), args.Error(1)
+}
+
+func (m *MockOAuthRepository) RevokeRefreshToken(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOAuthRepository) GetRefreshTokenFamily(ctx context.Context, familyID string) ([]*OAuthRefreshToken, error) {
+	args := m.Called(ctx, familyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*OAuthRefreshToken), args.Error(1)
+}
+
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) CreateAPIKey(ctx context.Context, key *APIKey) (*APIKey, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*APIKey, error) {
+	args := m.Called(ctx, keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetAPIKeysByAccountID(ctx context.Context, accountID string) ([]*APIKey, error) {
+	args := m.Called(ctx, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) UpdateAPIKeyLastUsed(ctx context.Context, id primitive.ObjectID, ipAddress string) error {
+	args := m.Called(ctx, id, ipAddress)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) DeleteAPIKey(ctx context.Context, accountID string, id primitive.ObjectID) error {
+	args := m.Called(ctx, accountID, id)
+	return args.Error(0)
+}
+
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) CreateAPIKey(ctx context.Context, accountID string, req *CreateAPIKeyRequest) (*APIKeyCreatedResponse, error) {
+	args := m.Called(ctx, accountID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*APIKeyCreatedResponse), args.Error(1)
+}
+
+func (m *MockAPIKeyService) ListAPIKeys(ctx context.Context, accountID string) ([]*APIKeyResponse, error) {
+	args := m.Called(ctx, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*APIKeyResponse), args.Error(1)
+}
+
+func (m *MockAPIKeyService) RevokeAPIKey(ctx context.Context, accountID, keyID string) error {
+	args := m.Called(ctx, accountID, keyID)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyService) Authenticate(ctx context.Context, key, ipAddress string) (*APIKey, *Account, error) {
+	args := m.Called(ctx, key, ipAddress)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*APIKey), args.Get(1).(*Account), args.Error(2)
+}
+
+type MockRoleRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoleRepository) CreateRole(ctx context.Context, role *Role) (*Role, error) {
+	args := m.Called(ctx, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) GetRoleByName(ctx context.Context, name string) (*Role, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) ListRoles(ctx context.Context) ([]*Role, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) UpdateRole(ctx context.Context, name string, updateData bson.M) (*Role, error) {
+	args := m.Called(ctx, name, updateData)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) DeleteRole(ctx context.Context, name string) error {
+	args := m.Called(ctx, name)
+	return args.Error(0)
+}
+
+type MockRBACService struct {
+	mock.Mock
+}
+
+func (m *MockRBACService) ListRoles(ctx context.Context) ([]*Role, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*Role), args.Error(1)
+}
+
+func (m *MockRBACService) CreateRole(ctx context.Context, req *CreateRoleRequest) (*Role, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Role), args.Error(1)
+}
+
+func (m *MockRBACService) UpdateRole(ctx context.Context, name string, req *UpdateRoleRequest) (*Role, error) {
+	args := m.Called(ctx, name, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Role), args.Error(1)
+}
+
+func (m *MockRBACService) DeleteRole(ctx context.Context, name string) error {
+	args := m.Called(ctx, name)
+	return args.Error(0)
+}
+
+func (m *MockRBACService) AssignRoles(ctx context.Context, accountID string, req *AssignRolesRequest) (*AccountResponse, error) {
+	args := m.Called(ctx, accountID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*AccountResponse), args.Error(1)
+}
+
+func (m *MockRBACService) GetPermissions(ctx context.Context, accountID string) ([]string, error) {
+	args := m.Called(ctx, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockRBACService) HasPermission(ctx context.Context, accountID, permission string) (bool, error) {
+	args := m.Called(ctx, accountID, permission)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockAccountDataRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccountDataRepository) DeleteAccountData(ctx context.Context, account *Account) (int64, error) {
+	args := m.Called(ctx, account)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockEmailChangeRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmailChangeRepository) CreateEmailChange(ctx context.Context, change *EmailChange) (*EmailChange, error) {
+	args := m.Called(ctx, change)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*EmailChange), args.Error(1)
+}
+
+func (m *MockEmailChangeRepository) GetPendingEmailChange(ctx context.Context, accountID string) (*EmailChange, error) {
+	args := m.Called(ctx, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*EmailChange), args.Error(1)
+}
+
+func (m *MockEmailChangeRepository) CompleteEmailChange(ctx context.Context, id primitive.ObjectID, ipAddress, userAgent string) error {
+	args := m.Called(ctx, id, ipAddress, userAgent)
+	return args.Error(0)
+}
+
+type MockMagicLinkRepository struct {
+	mock.Mock
+}
+
+func (m *MockMagicLinkRepository) CreateMagicLink(ctx context.Context, link *MagicLink) (*MagicLink, error) {
+	args := m.Called(ctx, link)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*MagicLink), args.Error(1)
+}
+
+func (m *MockMagicLinkRepository) ConsumeMagicLink(ctx context.Context, id primitive.ObjectID, deviceHash, ipAddress string) (*MagicLink, error) {
+	args := m.Called(ctx, id, deviceHash, ipAddress)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*MagicLink), args.Error(1)
+}
+
+func (m *MockMagicLinkRepository) ConsumeMagicLinkByEmail(ctx context.Context, email, deviceHash, ipAddress string) (*MagicLink, error) {
+	args := m.Called(ctx, email, deviceHash, ipAddress)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*MagicLink), args.Error(1)
+}
+
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) AppendAuditEvent(ctx context.Context, event *AuditEvent) (*AuditEvent, error) {
+	args := m.Called(ctx, event)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*AuditEvent), args.Error(1)
+}
+
+func (m *MockAuditRepository) ListAuditEvents(ctx context.Context, filter bson.M, pagination mongo.PaginationOptions) (*mongo.PaginatedResult[AuditEvent], error) {
+	args := m.Called(ctx, filter, pagination)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*mongo.PaginatedResult[AuditEvent]), args.Error(1)
+}
+
+func (m *MockAuditRepository) ListAuditEventsAfter(ctx context.Context, sequence int64, limit int64) ([]AuditEvent, error) {
+	args := m.Called(ctx, sequence, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]AuditEvent), args.Error(1)
+}
+
+func (m *MockAuditRepository) GetAuditChainHead(ctx context.Context) (*AuditChainHead, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*AuditChainHead), args.Error(1)
+}
+
+func (m *MockAuditRepository) DeleteAuditEventsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockKnownDeviceRepository struct {
+	mock.Mock
+}
+
+func (m *MockKnownDeviceRepository) ListKnownDevices(ctx context.Context, accountID string) ([]KnownDevice, error) {
+	args := m.Called(ctx, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]KnownDevice), args.Error(1)
+}
+
+func (m *MockKnownDeviceRepository) RecordKnownDevice(ctx context.Context, device *KnownDevice) (*KnownDevice, error) {
+	args := m.Called(ctx, device)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*KnownDevice), args.Error(1)
+}
+
+func (m *MockKnownDeviceRepository) DeleteKnownDevice(ctx context.Context, accountID string, id primitive.ObjectID) error {
+	args := m.Called(ctx, accountID, id)
+	return args.Error(0)
+}
+
+type MockResendService struct {
+	mock.Mock
+}
+
+func (m *MockResendService) HealthCheck(ctx context.Context) resend.HealthStatus {
+	args := m.Called(ctx)
+	return args.Get(0).(resend.HealthStatus)
+}
+
+func (m *MockResendService) SendEmail(ctx context.Context, request *resend.EmailRequest) (*resend.EmailResponse, error) {
+	args := m.Called(ctx, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*resend.EmailResponse), args.Error(1)
+}
+
+func (m *MockResendService) SendBulkEmails(ctx context.Context, requests []*resend.EmailRequest) ([]*resend.EmailResponse, error) {
+	args := m.Called(ctx, requests)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*resend.EmailResponse), args.Error(1)
+}
+
+func (m *MockResendService) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+type MockVaultService struct {
+	mock.Mock
+}
+
+func (m *MockVaultService) HealthCheck(ctx context.Context) vault.HealthStatus {
+	args := m.Called(ctx)
+	return args.Get(0).(vault.HealthStatus)
+}
+
+func (m *MockVaultService) GetSecret(ctx context.Context, path string) (map[string]interface{}, error) {
+	args := m.Called(ctx, path)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]interface{}), args.Error(1)
+}
+
+func (m *MockVaultService) PutSecret(ctx context.Context, path string, data map[string]interface{}) error {
+	args := m.Called(ctx, path, data)
+	return args.Error(0)
+}
+
+func (m *MockVaultService) ListSecrets(ctx context.Context, path string) ([]string, error) {
+	args := m.Called(ctx, path)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockVaultService) DeleteSecret(ctx context.Context, path string) error {
+	args := m.Called(ctx, path)
+	return args.Error(0)
+}
+
+func (m *MockVaultService) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
@@ -0,0 +1,89 @@
+package account
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/binding"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/ratelimit"
+)
+
+// RequestAccountUnlock godoc
+// @Summary Request an account unlock code
+// @Description Email a new unlock code if the account is locked after failed logins. Always succeeds so that it does not reveal whether the account exists.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body RequestAccountUnlockRequest true "Account email"
+// @Success 200 {object} map[string]interface{} "Unlock code sent if the account is locked"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 429 {object} map[string]interface{} "Too many requests"
+// @Router /accounts/unlock/request [post]
+func (h *AccountHandler) RequestAccountUnlock(c *fiber.Ctx) error {
+	var req RequestAccountUnlockRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	if err := h.service.RequestAccountUnlock(c.Context(), &req); err != nil {
+		return c.Status(throttledStatus(c, err, fiber.StatusInternalServerError)).JSON(fiber.Map{
+			"error":   "Failed to send unlock email",
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "If the account is locked, an unlock code has been sent",
+	})
+}
+
+// UnlockAccount godoc
+// @Summary Unlock account
+// @Description Unlock an account locked after failed logins using the code sent by email
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body UnlockAccountRequest true "Unlock details"
+// @Success 200 {object} map[string]interface{} "Account unlocked successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized - invalid or expired OTP"
+// @Failure 429 {object} map[string]interface{} "Too many requests"
+// @Router /accounts/unlock [post]
+func (h *AccountHandler) UnlockAccount(c *fiber.Ctx) error {
+	var req UnlockAccountRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	if err := h.service.UnlockAccount(c.Context(), &req); err != nil {
+		statusCode := fiber.StatusBadRequest
+		if strings.Contains(err.Error(), "expired") || strings.Contains(err.Error(), "invalid") {
+			statusCode = fiber.StatusUnauthorized
+		}
+		statusCode = throttledStatus(c, err, statusCode)
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"error":   "Account unlock failed",
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Account unlocked successfully",
+	})
+}
+
+// throttledStatus returns 429 and sets Retry-After when err comes from a rate
+// limit or lockout, and statusCode otherwise.
+func throttledStatus(c *fiber.Ctx, err error, statusCode int) int {
+	var exceeded *ratelimit.ExceededError
+	if !errors.As(err, &exceeded) {
+		return statusCode
+	}
+
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(ratelimit.RetryAfterSeconds(exceeded.RetryAfter)))
+	return fiber.StatusTooManyRequests
+}
@@ -0,0 +1,70 @@
+package account
+
+import (
+	"strings"
+	"time"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/ratelimit"
+)
+
+const (
+	// MaxLoginFailures wrong passwords within LoginFailureWindow lock the
+	// account. Each further lockout doubles the previous lockout duration.
+	MaxLoginFailures    = 5
+	LoginFailureWindow  = 15 * time.Minute
+	LockoutBaseDuration = 5 * time.Minute
+	LockoutMaxDuration  = 24 * time.Hour
+)
+
+var (
+	// Per-IP limits, applied as route middleware.
+	LoginIPRateLimit = ratelimit.Rule{Limit: 20, Window: 5 * time.Minute}
+	OTPIPRateLimit   = ratelimit.Rule{Limit: 30, Window: 15 * time.Minute}
+
+	// Per-email limits, applied in the service before the account is looked up
+	// so that they behave the same for unknown addresses.
+	LoginEmailRateLimit = ratelimit.Rule{Limit: 10, Window: 15 * time.Minute}
+	OTPRequestRateLimit = ratelimit.Rule{Limit: 3, Window: 15 * time.Minute}
+	OTPVerifyRateLimit  = ratelimit.Rule{Limit: 10, Window: 15 * time.Minute}
+
+	loginFailureRule = ratelimit.Rule{Limit: MaxLoginFailures, Window: LoginFailureWindow}
+)
+
+type RequestAccountUnlockRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type UnlockAccountRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	OTP   string `json:"otp" validate:"required,len=6"`
+}
+
+func (a *Account) IsLocked() bool {
+	return a.LockedUntil != nil && time.Now().Before(*a.LockedUntil)
+}
+
+// lockoutDuration returns how long the count-th consecutive lockout lasts.
+func lockoutDuration(count int) time.Duration {
+	duration := LockoutBaseDuration
+	for i := 1; i < count && duration < LockoutMaxDuration; i++ {
+		duration *= 2
+	}
+
+	if duration > LockoutMaxDuration {
+		return LockoutMaxDuration
+	}
+	return duration
+}
+
+func rateLimitKey(parts ...string) string {
+	return strings.ToLower(strings.Join(parts, ":"))
+}
+
+func GetAccountUnlockTemplate(otp string, lockedUntil time.Time) EmailTemplate {
+	until := lockedUntil.UTC().Format(time.RFC1123)
+	return EmailTemplate{
+		Subject:  "Your Account Has Been Locked",
+		HtmlBody: "<h1>Account Locked</h1><p>We locked your account until " + until + " after several failed sign-in attempts.</p><p>If this was you, unlock it now with the code: <strong>" + otp + "</strong></p><p>This code will expire in 5 minutes. If this was not you, consider changing your password.</p>",
+		TextBody: "Account Locked - We locked your account until " + until + " after several failed sign-in attempts. If this was you, unlock it now with the code: " + otp + ". This code will expire in 5 minutes. If this was not you, consider changing your password.",
+	}
+}
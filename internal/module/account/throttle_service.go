@@ -0,0 +1,201 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/ratelimit"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/redis"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/resend"
+)
+
+// NewRateLimiter prefers Redis so that limits hold across server instances,
+// and falls back to a per-process limiter otherwise.
+func NewRateLimiter(cacheService redis.RedisService) ratelimit.Limiter {
+	if cacheService != nil {
+		return ratelimit.NewRedisLimiter(cacheService.GetClient(), "ratelimit")
+	}
+
+	return ratelimit.NewMemoryLimiter()
+}
+
+func (s *accountService) RequestAccountUnlock(ctx context.Context, req *RequestAccountUnlockRequest) error {
+	if err := s.checkRateLimit(ctx, rateLimitKey("otp", "request", req.Email), OTPRequestRateLimit, "too many codes requested for this email"); err != nil {
+		return err
+	}
+
+	account, err := s.repository.GetByEmail(ctx, req.Email)
+	if err != nil || account == nil || !account.IsLocked() {
+		return nil
+	}
+
+	return s.sendUnlockCode(ctx, account)
+}
+
+func (s *accountService) UnlockAccount(ctx context.Context, req *UnlockAccountRequest) error {
+	if err := s.checkRateLimit(ctx, rateLimitKey("otp", "verify", req.Email), OTPVerifyRateLimit, "too many verification attempts for this email"); err != nil {
+		return err
+	}
+
+	_, err := s.accountIdentityRepository.ValidateOTP(ctx, req.Email, OTPPurposeAccountUnlock, req.OTP)
+	if err != nil {
+		return fmt.Errorf("account unlock failed: %w", err)
+	}
+
+	account, err := s.repository.GetByEmail(ctx, req.Email)
+	if err != nil || account == nil {
+		return fmt.Errorf("account not found")
+	}
+
+	if err := s.clearLoginFailures(ctx, account); err != nil {
+		return err
+	}
+
+	if err := s.accountIdentityRepository.DeleteOTP(ctx, req.Email, OTPPurposeAccountUnlock); err != nil {
+		fmt.Printf("Failed to delete OTP: %v\n", err)
+	}
+
+	recordAudit(ctx, s.auditService, &AuditEvent{
+		Type:     AuditEventAccountUnlock,
+		ActorID:  account.ID.Hex(),
+		TargetID: account.ID.Hex(),
+	})
+
+	return nil
+}
+
+func (s *accountService) checkRateLimit(ctx context.Context, key string, rule ratelimit.Rule, reason string) error {
+	return checkRateLimit(ctx, s.limiter, key, rule, reason)
+}
+
+// checkRateLimit turns a rejected attempt into a ratelimit.ExceededError. It
+// lets the attempt through when no limiter is configured or Redis fails.
+func checkRateLimit(ctx context.Context, limiter ratelimit.Limiter, key string, rule ratelimit.Rule, reason string) error {
+	if limiter == nil {
+		return nil
+	}
+
+	result, err := limiter.Allow(ctx, key, rule)
+	if err != nil {
+		fmt.Printf("Rate limiter unavailable: %v\n", err)
+		return nil
+	}
+
+	if !result.Allowed {
+		return &ratelimit.ExceededError{Reason: reason, RetryAfter: result.RetryAfter}
+	}
+
+	return nil
+}
+
+// recordLoginFailure locks the account once MaxLoginFailures wrong passwords
+// fall within LoginFailureWindow, and emails an unlock code to the owner.
+func (s *accountService) recordLoginFailure(ctx context.Context, account *Account) {
+	if s.limiter == nil {
+		return
+	}
+
+	key := rateLimitKey("login", "failures", account.ID.Hex())
+
+	result, err := s.limiter.Allow(ctx, key, loginFailureRule)
+	if err != nil {
+		fmt.Printf("Rate limiter unavailable: %v\n", err)
+		return
+	}
+	if result.Allowed && result.Remaining > 0 {
+		return
+	}
+
+	lockoutCount := account.LockoutCount + 1
+	lockedUntil := time.Now().Add(lockoutDuration(lockoutCount))
+
+	_, err = s.repository.Update(ctx, account.ID, bson.M{
+		"locked_until":  lockedUntil,
+		"lockout_count": lockoutCount,
+		"updated_at":    time.Now(),
+	})
+	if err != nil {
+		fmt.Printf("Failed to lock account %s: %v\n", account.ID.Hex(), err)
+		return
+	}
+	account.LockedUntil = &lockedUntil
+	account.LockoutCount = lockoutCount
+
+	recordAudit(ctx, s.auditService, &AuditEvent{
+		Type:     AuditEventAccountLock,
+		TargetID: account.ID.Hex(),
+		Reason:   "too many failed logins",
+		Metadata: map[string]string{
+			"locked_until":  lockedUntil.UTC().Format(time.RFC3339),
+			"lockout_count": strconv.Itoa(lockoutCount),
+		},
+	})
+
+	if err := s.limiter.Reset(ctx, key); err != nil {
+		fmt.Printf("Failed to reset login failures: %v\n", err)
+	}
+
+	if err := s.sendUnlockCode(ctx, account); err != nil {
+		fmt.Printf("Failed to send unlock email: %v\n", err)
+	}
+}
+
+func (s *accountService) clearLoginFailures(ctx context.Context, account *Account) error {
+	if s.limiter != nil {
+		if err := s.limiter.Reset(ctx, rateLimitKey("login", "failures", account.ID.Hex())); err != nil {
+			fmt.Printf("Failed to reset login failures: %v\n", err)
+		}
+	}
+
+	if account.LockedUntil == nil && account.LockoutCount == 0 {
+		return nil
+	}
+
+	_, err := s.repository.Update(ctx, account.ID, bson.M{
+		"locked_until":  nil,
+		"lockout_count": 0,
+		"updated_at":    time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unlock account: %w", err)
+	}
+
+	return nil
+}
+
+func (s *accountService) sendUnlockCode(ctx context.Context, account *Account) error {
+	otp, err := s.accountIdentityRepository.CreateOTP(ctx, account.Email, OTPPurposeAccountUnlock)
+	if err != nil {
+		return fmt.Errorf("failed to create unlock OTP: %w", err)
+	}
+
+	if s.resendService == nil {
+		return nil
+	}
+
+	template := GetAccountUnlockTemplate(otp.Code, *account.LockedUntil)
+	emailReq := &resend.EmailRequest{
+		From:    s.fromEmail,
+		To:      []string{account.Email},
+		Subject: template.Subject,
+		Html:    template.HtmlBody,
+		Text:    template.TextBody,
+	}
+
+	if _, err := s.resendService.SendEmail(ctx, emailReq); err != nil {
+		return fmt.Errorf("failed to send unlock email: %w", err)
+	}
+
+	return nil
+}
+
+func lockedError(account *Account) error {
+	return &ratelimit.ExceededError{
+		Reason:     "account is temporarily locked, check your email to unlock it",
+		RetryAfter: time.Until(*account.LockedUntil),
+	}
+}
@@ -0,0 +1,127 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/ratelimit"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	assert.Equal(t, LockoutBaseDuration, lockoutDuration(1))
+	assert.Equal(t, 2*LockoutBaseDuration, lockoutDuration(2))
+	assert.Equal(t, 8*LockoutBaseDuration, lockoutDuration(4))
+	assert.Equal(t, LockoutMaxDuration, lockoutDuration(20))
+}
+
+func TestAccountService_Login_LocksAfterRepeatedFailures(t *testing.T) {
+	service, mockAccountRepo, mockIdentityRepo, _ := setupAccountService()
+	service.limiter = ratelimit.NewMemoryLimiter()
+
+	account := CreateTestAccount(func(a *Account) { a.LockoutCount = 1 })
+	request := CreateTestLoginRequest(func(r *LoginRequest) { r.Password = "wrong-password" })
+
+	mockAccountRepo.On("GetByEmail", mock.Anything, request.Email).Return(account, nil)
+	mockAccountRepo.On("Update", mock.Anything, account.ID, mock.MatchedBy(func(update bson.M) bool {
+		lockedUntil, ok := update["locked_until"].(time.Time)
+		return ok && update["lockout_count"] == 2 &&
+			lockedUntil.Sub(time.Now()) > lockoutDuration(2)-time.Minute
+	})).Return(account, nil).Once()
+	mockIdentityRepo.On("CreateOTP", mock.Anything, account.Email, OTPPurposeAccountUnlock).
+		Return(CreateTestOTP(func(o *OTP) { o.Purpose = OTPPurposeAccountUnlock }), nil).Once()
+
+	for i := 0; i < MaxLoginFailures; i++ {
+		_, err := service.Login(context.Background(), request, "agent", "127.0.0.1")
+		assert.ErrorContains(t, err, "invalid email or password")
+	}
+
+	mockAccountRepo.AssertExpectations(t)
+	mockIdentityRepo.AssertExpectations(t)
+
+	_, err := service.Login(context.Background(), CreateTestLoginRequest(), "agent", "127.0.0.1")
+
+	var exceeded *ratelimit.ExceededError
+	require.True(t, errors.As(err, &exceeded), "a locked account rejects even the right password")
+	assert.Contains(t, err.Error(), "temporarily locked")
+	assert.Greater(t, exceeded.RetryAfter, lockoutDuration(2)-time.Minute)
+}
+
+func TestAccountService_Login_SuccessClearsLockout(t *testing.T) {
+	service, mockAccountRepo, mockIdentityRepo, _ := setupAccountService()
+	service.limiter = ratelimit.NewMemoryLimiter()
+
+	expired := time.Now().Add(-time.Minute)
+	account := CreateTestAccount(func(a *Account) {
+		a.LockedUntil = &expired
+		a.LockoutCount = 3
+	})
+
+	mockAccountRepo.On("GetByEmail", mock.Anything, account.Email).Return(account, nil)
+	mockAccountRepo.On("Update", mock.Anything, account.ID, mock.MatchedBy(func(update bson.M) bool {
+		return update["locked_until"] == nil && update["lockout_count"] == 0
+	})).Return(account, nil).Once()
+	mockIdentityRepo.On("CreateSession", mock.Anything, mock.AnythingOfType("*account.Session")).Return(CreateTestSession(), nil)
+
+	response, err := service.Login(context.Background(), CreateTestLoginRequest(), "agent", "127.0.0.1")
+	require.NoError(t, err)
+	assert.NotEmpty(t, response.Token)
+	mockAccountRepo.AssertExpectations(t)
+}
+
+func TestAccountService_OTPRequestRateLimit(t *testing.T) {
+	service, mockAccountRepo, _, _ := setupAccountService()
+	service.limiter = ratelimit.NewMemoryLimiter()
+
+	mockAccountRepo.On("GetByEmail", mock.Anything, "someone@example.com").Return(nil, nil)
+
+	request := &ForgotPasswordRequest{Email: "someone@example.com"}
+	for i := 0; i < OTPRequestRateLimit.Limit; i++ {
+		require.NoError(t, service.ForgotPassword(context.Background(), request))
+	}
+
+	err := service.ForgotPassword(context.Background(), request)
+	var exceeded *ratelimit.ExceededError
+	require.True(t, errors.As(err, &exceeded))
+
+	err = service.ResendEmailVerification(context.Background(), &ResendVerificationRequest{Email: "Someone@Example.com"})
+	assert.True(t, errors.As(err, &exceeded), "every code sent to an address shares one budget")
+}
+
+func TestAccountService_UnlockAccount(t *testing.T) {
+	service, mockAccountRepo, mockIdentityRepo, _ := setupAccountService()
+
+	lockedUntil := time.Now().Add(time.Hour)
+	account := CreateTestAccount(func(a *Account) {
+		a.LockedUntil = &lockedUntil
+		a.LockoutCount = 1
+	})
+	request := &UnlockAccountRequest{Email: account.Email, OTP: "123456"}
+
+	mockIdentityRepo.On("ValidateOTP", mock.Anything, account.Email, OTPPurposeAccountUnlock, "123456").Return(CreateTestOTP(), nil)
+	mockIdentityRepo.On("DeleteOTP", mock.Anything, account.Email, OTPPurposeAccountUnlock).Return(nil)
+	mockAccountRepo.On("GetByEmail", mock.Anything, account.Email).Return(account, nil)
+	mockAccountRepo.On("Update", mock.Anything, account.ID, mock.MatchedBy(func(update bson.M) bool {
+		return update["locked_until"] == nil
+	})).Return(account, nil).Once()
+
+	require.NoError(t, service.UnlockAccount(context.Background(), request))
+	mockAccountRepo.AssertExpectations(t)
+	mockIdentityRepo.AssertExpectations(t)
+}
+
+func TestAccountService_RequestAccountUnlock_IgnoresUnlockedAccounts(t *testing.T) {
+	service, mockAccountRepo, mockIdentityRepo, _ := setupAccountService()
+	account := CreateTestAccount()
+
+	mockAccountRepo.On("GetByEmail", mock.Anything, account.Email).Return(account, nil)
+
+	require.NoError(t, service.RequestAccountUnlock(context.Background(), &RequestAccountUnlockRequest{Email: account.Email}))
+	mockIdentityRepo.AssertNotCalled(t, "CreateOTP", mock.Anything, mock.Anything, mock.Anything)
+}
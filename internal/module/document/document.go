@@ -0,0 +1,136 @@
+package document
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/container"
+	appmiddleware "github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/middleware"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/module/account"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/module/workspace"
+)
+
+type DocumentModule struct {
+	container.BaseModule
+	maxSize int64
+}
+
+func NewDocumentModule() *DocumentModule {
+	return &DocumentModule{
+		BaseModule: container.NewBaseModule(
+			"document",
+			"1.0.0",
+			"Workspace documents stored in MinIO with versioning, presigned transfers and text extraction",
+			[]string{"account", "workspace"},
+		),
+		maxSize: DefaultMaxDocumentSize,
+	}
+}
+
+// WithMaxSize sets the largest file accepted, in bytes. Uploads through the
+// API are also bounded by the upload size of the container.
+func (m *DocumentModule) WithMaxSize(maxSize int64) *DocumentModule {
+	m.maxSize = maxSize
+	return m
+}
+
+func (m *DocumentModule) RegisterServices(registry *container.ServiceRegistry) error {
+	mongoService := registry.GetMongo()
+	if mongoService == nil {
+		return container.ServiceNotFoundError{ServiceName: "mongo"}
+	}
+
+	minioService := registry.GetMinIO()
+	if minioService == nil {
+		return container.ServiceNotFoundError{ServiceName: "minio"}
+	}
+
+	repository := NewDocumentRepository(mongoService)
+	storage := NewObjectStorage(minioService)
+
+	documentService := newDocumentService(repository, storage, m.maxSize)
+	if err := registry.RegisterService("document", documentService); err != nil {
+		return err
+	}
+
+	extractionService := newExtractionService(NewExtractionRepository(mongoService), repository, storage, m.maxSize)
+	if err := registry.RegisterService("document_extraction", extractionService); err != nil {
+		return err
+	}
+
+	documentService.OnVersion(func(ctx context.Context, document *Document) error {
+		_, err := extractionService.Enqueue(ctx, document)
+		return err
+	})
+	documentService.OnDelete(extractionService.DeleteDocument)
+
+	workspaceServiceInterface, err := registry.GetService("workspace")
+	if err != nil {
+		return err
+	}
+	workspaceService := workspaceServiceInterface.(workspace.WorkspaceService)
+	workspaceService.OnDelete(extractionService.DeleteWorkspace)
+	workspaceService.OnDelete(documentService.DeleteWorkspace)
+
+	extractionService.Start()
+
+	return nil
+}
+
+func (m *DocumentModule) RegisterMiddleware(registry *container.ServiceRegistry) error {
+	return nil
+}
+
+func (m *DocumentModule) RegisterRoutes(router fiber.Router, registry *container.ServiceRegistry) error {
+	documentServiceInterface, err := registry.GetService("document")
+	if err != nil {
+		return err
+	}
+
+	extractionServiceInterface, err := registry.GetService("document_extraction")
+	if err != nil {
+		return err
+	}
+
+	handler := NewDocumentHandler(documentServiceInterface.(DocumentService))
+	extractionHandler := NewExtractionHandler(extractionServiceInterface.(ExtractionService))
+
+	middlewareInterface, err := registry.GetService("account_middleware")
+	if err != nil {
+		return err
+	}
+
+	workspaceMiddlewareInterface, err := registry.GetService("workspace_middleware")
+	if err != nil {
+		return err
+	}
+
+	middleware := middlewareInterface.(*account.AccountMiddleware)
+	members := workspaceMiddlewareInterface.(*workspace.WorkspaceMiddleware)
+	read := middleware.RequireScope(account.OAuthScopeDocumentsRead)
+	write := middleware.RequireScope(account.OAuthScopeDocumentsWrite)
+	viewer := members.RequireRole(workspace.RoleViewer)
+	editor := members.RequireRole(workspace.RoleEditor)
+	upload := appmiddleware.NewUploadLimitMiddleware(m.maxSize)
+
+	documents := router.Group("/workspaces/:workspaceId/documents", middleware.RequireAuth())
+
+	documents.Get("/", read, viewer, handler.ListDocuments)
+	documents.Post("/", write, editor, upload, handler.UploadDocument)
+	documents.Post("/uploads", write, editor, handler.CreateUpload)
+
+	documents.Get("/:id", read, viewer, handler.GetDocument)
+	documents.Patch("/:id", write, editor, handler.UpdateDocument)
+	documents.Delete("/:id", write, editor, handler.DeleteDocument)
+	documents.Get("/:id/download", read, viewer, handler.GetDownloadURL)
+	documents.Put("/:id/content", write, editor, upload, handler.ReplaceContent)
+	documents.Post("/:id/uploads", write, editor, handler.CreateVersionUpload)
+	documents.Post("/:id/uploads/complete", write, editor, handler.CompleteUpload)
+
+	documents.Get("/:id/extraction", read, viewer, extractionHandler.GetExtraction)
+	documents.Get("/:id/extraction/result", read, viewer, extractionHandler.GetExtractionResult)
+	documents.Post("/:id/extraction/retry", write, editor, extractionHandler.RetryExtraction)
+
+	return nil
+}
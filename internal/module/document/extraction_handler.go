@@ -0,0 +1,111 @@
+package document
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/binding"
+)
+
+type ExtractionHandler struct {
+	service ExtractionService
+}
+
+func NewExtractionHandler(service ExtractionService) *ExtractionHandler {
+	return &ExtractionHandler{
+		service: service,
+	}
+}
+
+// GetExtraction godoc
+// @Summary Get the text extraction of a document
+// @Description Return the status of the text extraction of the current version or of an earlier one, including the error of a failed attempt.
+// @Tags documents
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Document ID"
+// @Param version query int false "Version, defaults to the latest extracted one"
+// @Success 200 {object} map[string]interface{} "Extraction retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Extraction not found"
+// @Router /workspaces/{workspaceId}/documents/{id}/extraction [get]
+func (h *ExtractionHandler) GetExtraction(c *fiber.Ctx) error {
+	var query ExtractionQuery
+	if err := binding.Query(c, &query); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	extraction, err := h.service.GetExtraction(c.Context(), workspaceIDFrom(c), c.Params("id"), query.Version)
+	if err != nil {
+		return documentError(c, "Failed to get extraction", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Extraction retrieved successfully",
+		"data":    extraction,
+	})
+}
+
+// GetExtractionResult godoc
+// @Summary Get the extracted text of a document
+// @Description Return the plain text and the headings, paragraphs, list items and tables extracted from a document version.
+// @Tags documents
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Document ID"
+// @Param version query int false "Version, defaults to the latest extracted one"
+// @Success 200 {object} map[string]interface{} "Extraction result retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Extraction result not found"
+// @Router /workspaces/{workspaceId}/documents/{id}/extraction/result [get]
+func (h *ExtractionHandler) GetExtractionResult(c *fiber.Ctx) error {
+	var query ExtractionQuery
+	if err := binding.Query(c, &query); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	result, err := h.service.GetResult(c.Context(), workspaceIDFrom(c), c.Params("id"), query.Version)
+	if err != nil {
+		return documentError(c, "Failed to get extraction result", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Extraction result retrieved successfully",
+		"data":    result,
+	})
+}
+
+// RetryExtraction godoc
+// @Summary Retry the text extraction of a document
+// @Description Queue a finished extraction again with a fresh set of attempts. Versions uploaded before extraction was available are queued for the first time.
+// @Tags documents
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Document ID"
+// @Param version query int false "Version, defaults to the latest extracted one"
+// @Success 202 {object} map[string]interface{} "Extraction queued successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Document not found"
+// @Failure 409 {object} map[string]interface{} "Extraction in progress"
+// @Router /workspaces/{workspaceId}/documents/{id}/extraction/retry [post]
+func (h *ExtractionHandler) RetryExtraction(c *fiber.Ctx) error {
+	var query ExtractionQuery
+	if err := binding.Query(c, &query); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	extraction, err := h.service.RetryExtraction(c.Context(), workspaceIDFrom(c), c.Params("id"), query.Version)
+	if err != nil {
+		return documentError(c, "Failed to retry extraction", err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "Extraction queued successfully",
+		"data":    extraction,
+	})
+}
@@ -0,0 +1,74 @@
+package document
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/textract"
+)
+
+const ExtractionCollectionName = "document_extractions"
+
+const (
+	// ExtractionMaxAttempts is how often a failing extraction is tried before
+	// it is marked failed. Files that cannot be parsed fail at once.
+	ExtractionMaxAttempts = 3
+
+	// ExtractionLease is how long a worker owns a claimed extraction. A worker
+	// that stops without finishing leaves it to be claimed again afterwards.
+	ExtractionLease = 5 * time.Minute
+
+	// ExtractionRetryDelay is the wait before the second attempt; it doubles
+	// for every later one.
+	ExtractionRetryDelay = 30 * time.Second
+
+	ExtractionPollInterval = 5 * time.Second
+)
+
+type ExtractionStatus string
+
+const (
+	ExtractionStatusQueued     ExtractionStatus = "queued"
+	ExtractionStatusProcessing ExtractionStatus = "processing"
+	ExtractionStatusSucceeded  ExtractionStatus = "succeeded"
+	ExtractionStatusFailed     ExtractionStatus = "failed"
+)
+
+// Extraction tracks the text extraction of one version of a document. The
+// plain text and the block structure are stored next to the file as
+// derived artifacts.
+type Extraction struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	WorkspaceID    string             `json:"workspace_id" bson:"workspace_id"`
+	DocumentID     string             `json:"document_id" bson:"document_id"`
+	Version        int                `json:"version" bson:"version"`
+	Status         ExtractionStatus   `json:"status" bson:"status"`
+	Format         textract.Format    `json:"format,omitempty" bson:"format,omitempty"`
+	Attempts       int                `json:"attempts" bson:"attempts"`
+	Error          string             `json:"error,omitempty" bson:"error,omitempty"`
+	TextKey        string             `json:"-" bson:"text_key,omitempty"`
+	StructureKey   string             `json:"-" bson:"structure_key,omitempty"`
+	CharacterCount int                `json:"character_count" bson:"character_count"`
+	BlockCount     int                `json:"block_count" bson:"block_count"`
+	NextAttemptAt  time.Time          `json:"next_attempt_at" bson:"next_attempt_at"`
+	LockedUntil    time.Time          `json:"-" bson:"locked_until,omitempty"`
+	StartedAt      *time.Time         `json:"started_at,omitempty" bson:"started_at,omitempty"`
+	FinishedAt     *time.Time         `json:"finished_at,omitempty" bson:"finished_at,omitempty"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// ExtractionResult is the extracted content of a document version. Text is
+// the blocks joined by blank lines.
+type ExtractionResult struct {
+	DocumentID string           `json:"document_id"`
+	Version    int              `json:"version"`
+	Format     textract.Format  `json:"format"`
+	Text       string           `json:"text"`
+	Blocks     []textract.Block `json:"blocks"`
+}
+
+type ExtractionQuery struct {
+	Version int `query:"version" validate:"min=0"`
+}
@@ -0,0 +1,138 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+type ExtractionRepository interface {
+	Create(ctx context.Context, extraction *Extraction) (*Extraction, error)
+	GetLatest(ctx context.Context, workspaceID, documentID string) (*Extraction, error)
+	GetVersion(ctx context.Context, workspaceID, documentID string, version int) (*Extraction, error)
+	Claim(ctx context.Context, now time.Time, lease time.Duration) (*Extraction, error)
+	Update(ctx context.Context, id primitive.ObjectID, update bson.M) (*Extraction, error)
+	UpdateAttempt(ctx context.Context, id primitive.ObjectID, attempts int, update bson.M) (*Extraction, error)
+	DeleteByDocument(ctx context.Context, workspaceID, documentID string) error
+	DeleteByWorkspace(ctx context.Context, workspaceID string) error
+}
+
+type extractionRepository struct {
+	repo mongo.Repository[Extraction]
+}
+
+var _ ExtractionRepository = (*extractionRepository)(nil)
+
+func NewExtractionRepository(mongoService *mongo.MongoService) ExtractionRepository {
+	return &extractionRepository{
+		repo: mongo.NewRepository[Extraction](mongoService, ExtractionCollectionName),
+	}
+}
+
+func (r *extractionRepository) Create(ctx context.Context, extraction *Extraction) (*Extraction, error) {
+	extraction.ID = primitive.NewObjectID()
+
+	result, err := r.repo.Create(ctx, *extraction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create extraction: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *extractionRepository) GetLatest(ctx context.Context, workspaceID, documentID string) (*Extraction, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}})
+
+	result, err := r.repo.FindOne(ctx, bson.M{"workspace_id": workspaceID, "document_id": documentID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get extraction: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *extractionRepository) GetVersion(ctx context.Context, workspaceID, documentID string, version int) (*Extraction, error) {
+	result, err := r.repo.FindOne(ctx, bson.M{"workspace_id": workspaceID, "document_id": documentID, "version": version})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get extraction: %w", err)
+	}
+
+	return result, nil
+}
+
+// Claim hands one due extraction to the caller and counts the attempt. Queued
+// extractions are due once their next attempt time has passed; processing
+// ones once their lease has run out. It returns nil when nothing is due.
+func (r *extractionRepository) Claim(ctx context.Context, now time.Time, lease time.Duration) (*Extraction, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": ExtractionStatusQueued, "next_attempt_at": bson.M{"$lte": now}},
+		bson.M{"status": ExtractionStatusProcessing, "locked_until": bson.M{"$lte": now}},
+	}}
+	update := bson.M{
+		"$set": bson.M{
+			"status":       ExtractionStatusProcessing,
+			"locked_until": now.Add(lease),
+			"started_at":   now,
+			"updated_at":   now,
+		},
+		"$inc": bson.M{"attempts": 1},
+	}
+
+	result, err := r.repo.Update(ctx, filter, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim extraction: %w", err)
+	}
+
+	return result, nil
+}
+
+// Update applies a full update document and returns nil when the extraction
+// does not exist.
+func (r *extractionRepository) Update(ctx context.Context, id primitive.ObjectID, update bson.M) (*Extraction, error) {
+	return r.update(ctx, bson.M{"_id": id}, update)
+}
+
+// UpdateAttempt only applies the update while the extraction is still on the
+// given attempt, so a worker whose lease ran out cannot overwrite the result
+// of the worker that took over. It returns nil in that case.
+func (r *extractionRepository) UpdateAttempt(ctx context.Context, id primitive.ObjectID, attempts int, update bson.M) (*Extraction, error) {
+	return r.update(ctx, bson.M{"_id": id, "attempts": attempts}, update)
+}
+
+func (r *extractionRepository) update(ctx context.Context, filter bson.M, update bson.M) (*Extraction, error) {
+	set, _ := update["$set"].(bson.M)
+	if set == nil {
+		set = bson.M{}
+		update["$set"] = set
+	}
+	set["updated_at"] = time.Now()
+
+	result, err := r.repo.Update(ctx, filter, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update extraction: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *extractionRepository) DeleteByDocument(ctx context.Context, workspaceID, documentID string) error {
+	if _, err := r.repo.DeleteMany(ctx, bson.M{"workspace_id": workspaceID, "document_id": documentID}); err != nil {
+		return fmt.Errorf("failed to delete extractions: %w", err)
+	}
+
+	return nil
+}
+
+func (r *extractionRepository) DeleteByWorkspace(ctx context.Context, workspaceID string) error {
+	if _, err := r.repo.DeleteMany(ctx, bson.M{"workspace_id": workspaceID}); err != nil {
+		return fmt.Errorf("failed to delete extractions: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,434 @@
+package document
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/minio"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/textract"
+)
+
+// ExtractionService turns document versions into plain text and block
+// structure. Every new version is queued and processed by a background
+// worker; the results are stored under the document's prefix so they are
+// removed together with it.
+type ExtractionService interface {
+	Enqueue(ctx context.Context, document *Document) (*Extraction, error)
+	GetExtraction(ctx context.Context, workspaceID, documentID string, version int) (*Extraction, error)
+	GetResult(ctx context.Context, workspaceID, documentID string, version int) (*ExtractionResult, error)
+	RetryExtraction(ctx context.Context, workspaceID, documentID string, version int) (*Extraction, error)
+	ProcessNext(ctx context.Context) (bool, error)
+	DeleteDocument(ctx context.Context, workspaceID, documentID string) error
+	DeleteWorkspace(ctx context.Context, workspaceID string) error
+	Start()
+	Shutdown() error
+}
+
+type extractionService struct {
+	repository ExtractionRepository
+	documents  DocumentRepository
+	storage    ObjectStorage
+	maxSize    int64
+
+	interval time.Duration
+	wake     chan struct{}
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewExtractionService(mongoService *mongo.MongoService, minioService minio.MinIOService, maxSize int64) ExtractionService {
+	return newExtractionService(
+		NewExtractionRepository(mongoService),
+		NewDocumentRepository(mongoService),
+		NewObjectStorage(minioService),
+		maxSize,
+	)
+}
+
+func newExtractionService(repository ExtractionRepository, documents DocumentRepository, storage ObjectStorage, maxSize int64) *extractionService {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxDocumentSize
+	}
+
+	return &extractionService{
+		repository: repository,
+		documents:  documents,
+		storage:    storage,
+		maxSize:    maxSize,
+		interval:   ExtractionPollInterval,
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// permanentError marks a failure that another attempt cannot fix, such as a
+// file that cannot be parsed.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// Enqueue queues the current version of document for extraction.
+func (s *extractionService) Enqueue(ctx context.Context, document *Document) (*Extraction, error) {
+	now := time.Now()
+	extraction, err := s.repository.Create(ctx, &Extraction{
+		WorkspaceID:   document.WorkspaceID,
+		DocumentID:    document.ID.Hex(),
+		Version:       document.Version,
+		Status:        ExtractionStatusQueued,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.notify()
+
+	return extraction, nil
+}
+
+// GetExtraction returns the extraction of a version, or of the latest
+// version when version is zero.
+func (s *extractionService) GetExtraction(ctx context.Context, workspaceID, documentID string, version int) (*Extraction, error) {
+	extraction, err := s.find(ctx, workspaceID, documentID, version)
+	if err != nil {
+		return nil, err
+	}
+	if extraction == nil {
+		return nil, fmt.Errorf("extraction not found")
+	}
+
+	return extraction, nil
+}
+
+func (s *extractionService) GetResult(ctx context.Context, workspaceID, documentID string, version int) (*ExtractionResult, error) {
+	extraction, err := s.GetExtraction(ctx, workspaceID, documentID, version)
+	if err != nil {
+		return nil, err
+	}
+	if extraction.Status != ExtractionStatusSucceeded {
+		return nil, fmt.Errorf("extraction result not found: extraction is %s", extraction.Status)
+	}
+
+	reader, err := s.storage.Open(ctx, extraction.StructureKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read extraction result: %w", err)
+	}
+	defer reader.Close()
+
+	var document textract.Document
+	if err := json.NewDecoder(reader).Decode(&document); err != nil {
+		return nil, fmt.Errorf("failed to read extraction result: %w", err)
+	}
+
+	return &ExtractionResult{
+		DocumentID: extraction.DocumentID,
+		Version:    extraction.Version,
+		Format:     document.Format,
+		Text:       document.Text(),
+		Blocks:     document.Blocks,
+	}, nil
+}
+
+// RetryExtraction queues a finished extraction again with a fresh set of
+// attempts. Versions stored before extraction existed are queued for the
+// first time.
+func (s *extractionService) RetryExtraction(ctx context.Context, workspaceID, documentID string, version int) (*Extraction, error) {
+	extraction, err := s.find(ctx, workspaceID, documentID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if extraction == nil {
+		document, err := s.getDocument(ctx, workspaceID, documentID)
+		if err != nil {
+			return nil, err
+		}
+		if version != 0 && version != document.Version {
+			if document.FindVersion(version) == nil {
+				return nil, fmt.Errorf("document version not found")
+			}
+			document.Version = version
+		}
+		return s.Enqueue(ctx, document)
+	}
+
+	if extraction.Status == ExtractionStatusQueued || extraction.Status == ExtractionStatusProcessing {
+		return nil, fmt.Errorf("extraction is already in progress")
+	}
+
+	updated, err := s.repository.UpdateAttempt(ctx, extraction.ID, extraction.Attempts, bson.M{
+		"$set": bson.M{
+			"status":          ExtractionStatusQueued,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		},
+		"$unset": bson.M{"error": "", "started_at": "", "finished_at": ""},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("extraction is already in progress")
+	}
+	s.notify()
+
+	return updated, nil
+}
+
+// ProcessNext claims one due extraction and runs it. It reports whether an
+// extraction was found; failures of the extraction itself are recorded on it
+// rather than returned.
+func (s *extractionService) ProcessNext(ctx context.Context) (bool, error) {
+	extraction, err := s.repository.Claim(ctx, time.Now(), ExtractionLease)
+	if err != nil {
+		return false, err
+	}
+	if extraction == nil {
+		return false, nil
+	}
+
+	if err := s.extract(ctx, extraction); err != nil {
+		if err := s.fail(ctx, extraction, err); err != nil {
+			return true, err
+		}
+	}
+
+	return true, nil
+}
+
+func (s *extractionService) DeleteDocument(ctx context.Context, workspaceID, documentID string) error {
+	return s.repository.DeleteByDocument(ctx, workspaceID, documentID)
+}
+
+// DeleteWorkspace only removes the extraction records; the artifacts are
+// deleted with the workspace's documents.
+func (s *extractionService) DeleteWorkspace(ctx context.Context, workspaceID string) error {
+	return s.repository.DeleteByWorkspace(ctx, workspaceID)
+}
+
+// extract runs one attempt. A panic, such as one from a parser tripping over
+// a malformed file, fails the extraction instead of the server.
+func (s *extractionService) extract(ctx context.Context, extraction *Extraction) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = permanent(fmt.Errorf("failed to extract text: %v", r))
+		}
+	}()
+
+	if extraction.Attempts > ExtractionMaxAttempts {
+		return permanent(fmt.Errorf("gave up after %d attempts", ExtractionMaxAttempts))
+	}
+
+	document, err := s.getDocument(ctx, extraction.WorkspaceID, extraction.DocumentID)
+	if err != nil {
+		if err.Error() == "document not found" {
+			return permanent(err)
+		}
+		return err
+	}
+
+	version := document.FindVersion(extraction.Version)
+	if version == nil {
+		return permanent(fmt.Errorf("document version not found"))
+	}
+
+	data, err := s.read(ctx, version.ObjectKey)
+	if err != nil {
+		return err
+	}
+
+	format := textract.Detect(version.ContentType, document.Name, data)
+	if format == "" {
+		return permanent(textract.ErrUnsupportedFormat)
+	}
+
+	result, err := textract.Extract(format, data)
+	if err != nil {
+		return permanent(err)
+	}
+
+	structure, err := json.Marshal(result)
+	if err != nil {
+		return permanent(fmt.Errorf("failed to encode extraction result: %w", err))
+	}
+	text := result.Text()
+
+	prefix := extractionPrefix(document, extraction.Version)
+	textKey, structureKey := prefix+"text.txt", prefix+"structure.json"
+	if err := s.storage.Put(ctx, textKey, bytes.NewReader([]byte(text)), int64(len(text)), "text/plain; charset=utf-8"); err != nil {
+		return fmt.Errorf("failed to store extracted text: %w", err)
+	}
+	if err := s.storage.Put(ctx, structureKey, bytes.NewReader(structure), int64(len(structure)), "application/json"); err != nil {
+		return fmt.Errorf("failed to store extraction result: %w", err)
+	}
+
+	_, err = s.repository.UpdateAttempt(ctx, extraction.ID, extraction.Attempts, bson.M{
+		"$set": bson.M{
+			"status":          ExtractionStatusSucceeded,
+			"format":          format,
+			"text_key":        textKey,
+			"structure_key":   structureKey,
+			"character_count": utf8.RuneCountInString(text),
+			"block_count":     len(result.Blocks),
+			"finished_at":     time.Now(),
+		},
+		"$unset": bson.M{"error": "", "locked_until": ""},
+	})
+	return err
+}
+
+// fail records a failed attempt. Permanent failures and the last attempt
+// mark the extraction failed; anything else is queued again after a delay
+// that doubles with every attempt.
+func (s *extractionService) fail(ctx context.Context, extraction *Extraction, cause error) error {
+	now := time.Now()
+	set := bson.M{"error": cause.Error()}
+
+	var permanentErr *permanentError
+	if errors.As(cause, &permanentErr) || extraction.Attempts >= ExtractionMaxAttempts {
+		set["status"] = ExtractionStatusFailed
+		set["finished_at"] = now
+	} else {
+		set["status"] = ExtractionStatusQueued
+		set["next_attempt_at"] = now.Add(ExtractionRetryDelay << (extraction.Attempts - 1))
+	}
+
+	_, err := s.repository.UpdateAttempt(ctx, extraction.ID, extraction.Attempts, bson.M{
+		"$set":   set,
+		"$unset": bson.M{"locked_until": ""},
+	})
+	return err
+}
+
+func (s *extractionService) find(ctx context.Context, workspaceID, documentID string, version int) (*Extraction, error) {
+	if version == 0 {
+		return s.repository.GetLatest(ctx, workspaceID, documentID)
+	}
+	return s.repository.GetVersion(ctx, workspaceID, documentID, version)
+}
+
+func (s *extractionService) read(ctx context.Context, key string) ([]byte, error) {
+	reader, err := s.storage.Open(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open document: %w", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, s.maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document: %w", err)
+	}
+	if int64(len(data)) > s.maxSize {
+		return nil, permanent(fmt.Errorf("document is larger than %d bytes", s.maxSize))
+	}
+
+	return data, nil
+}
+
+func (s *extractionService) getDocument(ctx context.Context, workspaceID, documentID string) (*Document, error) {
+	objectID, err := primitive.ObjectIDFromHex(documentID)
+	if err != nil {
+		return nil, fmt.Errorf("document not found")
+	}
+
+	document, err := s.documents.GetByID(ctx, workspaceID, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, fmt.Errorf("document not found")
+	}
+
+	return document, nil
+}
+
+// notify wakes the worker without waiting for it.
+func (s *extractionService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *extractionService) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go s.run(ctx, s.done)
+}
+
+// run drains every due extraction, then sleeps until the next tick or until
+// a new extraction is queued.
+func (s *extractionService) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			processed, err := s.ProcessNext(ctx)
+			if err != nil {
+				fmt.Printf("Document extraction failed: %v\n", err)
+				break
+			}
+			if !processed {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+func (s *extractionService) Shutdown() error {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	<-done
+	return nil
+}
+
+func extractionPrefix(document *Document, version int) string {
+	return fmt.Sprintf("%sextractions/v%d/", documentPrefix(document.WorkspaceID, document.ID), version)
+}
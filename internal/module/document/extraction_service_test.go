@@ -0,0 +1,257 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/textract"
+)
+
+func setupExtractionService() (*extractionService, *MockExtractionRepository, *MockDocumentRepository, *MockObjectStorage) {
+	mockRepo := &MockExtractionRepository{}
+	mockDocuments := &MockDocumentRepository{}
+	mockStorage := &MockObjectStorage{}
+	return newExtractionService(mockRepo, mockDocuments, mockStorage, 1024), mockRepo, mockDocuments, mockStorage
+}
+
+func markdownDocument() *Document {
+	document := CreateTestDocument()
+	document.Name = "notes.md"
+	document.ContentType = "text/markdown"
+	return document
+}
+
+func TestExtractionService_Enqueue(t *testing.T) {
+	service, mockRepo, _, _ := setupExtractionService()
+	document := CreateTestDocument()
+
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(extraction *Extraction) bool {
+		return extraction.DocumentID == document.ID.Hex() &&
+			extraction.WorkspaceID == testWorkspaceID &&
+			extraction.Version == 1 &&
+			extraction.Status == ExtractionStatusQueued
+	})).Return(CreateTestExtraction(document), nil)
+
+	_, err := service.Enqueue(context.Background(), document)
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	assert.Len(t, service.wake, 1)
+}
+
+func TestExtractionService_ProcessNext(t *testing.T) {
+	t.Run("stores the text and the structure", func(t *testing.T) {
+		service, mockRepo, mockDocuments, mockStorage := setupExtractionService()
+		document := markdownDocument()
+		extraction := CreateTestExtraction(document)
+		prefix := extractionPrefix(document, 1)
+
+		mockRepo.On("Claim", mock.Anything, mock.Anything, ExtractionLease).Return(extraction, nil)
+		mockDocuments.On("GetByID", mock.Anything, testWorkspaceID, document.ID).Return(document, nil)
+		mockStorage.On("Open", mock.Anything, document.ObjectKey).Return(io.NopCloser(strings.NewReader("# Notes\n\nAlice met Bob.\n")), nil)
+		mockStorage.On("Put", mock.Anything, prefix+"text.txt", "Notes\n\nAlice met Bob.", int64(21), mock.Anything).Return(nil)
+		mockStorage.On("Put", mock.Anything, prefix+"structure.json", mock.MatchedBy(func(data string) bool {
+			return strings.Contains(data, `"format":"markdown"`) && strings.Contains(data, `"text":"Alice met Bob."`)
+		}), mock.Anything, "application/json").Return(nil)
+		mockRepo.On("UpdateAttempt", mock.Anything, extraction.ID, 1, mock.MatchedBy(func(update bson.M) bool {
+			set := update["$set"].(bson.M)
+			return set["status"] == ExtractionStatusSucceeded &&
+				set["format"] == textract.FormatMarkdown &&
+				set["character_count"] == 21 &&
+				set["block_count"] == 2
+		})).Return(extraction, nil)
+
+		processed, err := service.ProcessNext(context.Background())
+
+		require.NoError(t, err)
+		assert.True(t, processed)
+		mockStorage.AssertExpectations(t)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("reports when nothing is due", func(t *testing.T) {
+		service, mockRepo, _, _ := setupExtractionService()
+
+		mockRepo.On("Claim", mock.Anything, mock.Anything, ExtractionLease).Return(nil, nil)
+
+		processed, err := service.ProcessNext(context.Background())
+
+		require.NoError(t, err)
+		assert.False(t, processed)
+	})
+
+	t.Run("fails unsupported files without retrying", func(t *testing.T) {
+		service, mockRepo, mockDocuments, mockStorage := setupExtractionService()
+		document := CreateTestDocument()
+		document.Name = "image.png"
+		document.ContentType = "image/png"
+		extraction := CreateTestExtraction(document)
+
+		mockRepo.On("Claim", mock.Anything, mock.Anything, ExtractionLease).Return(extraction, nil)
+		mockDocuments.On("GetByID", mock.Anything, testWorkspaceID, document.ID).Return(document, nil)
+		mockStorage.On("Open", mock.Anything, document.ObjectKey).Return(io.NopCloser(strings.NewReader("\x89PNG\x00\x01")), nil)
+		mockRepo.On("UpdateAttempt", mock.Anything, extraction.ID, 1, mock.MatchedBy(func(update bson.M) bool {
+			set := update["$set"].(bson.M)
+			return set["status"] == ExtractionStatusFailed && set["error"] == textract.ErrUnsupportedFormat.Error()
+		})).Return(extraction, nil)
+
+		processed, err := service.ProcessNext(context.Background())
+
+		require.NoError(t, err)
+		assert.True(t, processed)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("fails when extraction panics", func(t *testing.T) {
+		service, mockRepo, mockDocuments, mockStorage := setupExtractionService()
+		document := markdownDocument()
+		extraction := CreateTestExtraction(document)
+
+		mockRepo.On("Claim", mock.Anything, mock.Anything, ExtractionLease).Return(extraction, nil)
+		mockDocuments.On("GetByID", mock.Anything, testWorkspaceID, document.ID).Return(document, nil)
+		mockStorage.On("Open", mock.Anything, document.ObjectKey).Run(func(mock.Arguments) {
+			panic("slice bounds out of range")
+		}).Return(nil, nil)
+		mockRepo.On("UpdateAttempt", mock.Anything, extraction.ID, 1, mock.MatchedBy(func(update bson.M) bool {
+			set := update["$set"].(bson.M)
+			return set["status"] == ExtractionStatusFailed && set["error"] == "failed to extract text: slice bounds out of range"
+		})).Return(extraction, nil)
+
+		processed, err := service.ProcessNext(context.Background())
+
+		require.NoError(t, err)
+		assert.True(t, processed)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("retries storage failures with backoff", func(t *testing.T) {
+		service, mockRepo, mockDocuments, mockStorage := setupExtractionService()
+		document := markdownDocument()
+		extraction := CreateTestExtraction(document)
+		extraction.Attempts = 2
+
+		mockRepo.On("Claim", mock.Anything, mock.Anything, ExtractionLease).Return(extraction, nil)
+		mockDocuments.On("GetByID", mock.Anything, testWorkspaceID, document.ID).Return(document, nil)
+		mockStorage.On("Open", mock.Anything, document.ObjectKey).Return(nil, errors.New("storage unavailable"))
+		mockRepo.On("UpdateAttempt", mock.Anything, extraction.ID, 2, mock.MatchedBy(func(update bson.M) bool {
+			set := update["$set"].(bson.M)
+			next, _ := set["next_attempt_at"].(time.Time)
+			return set["status"] == ExtractionStatusQueued &&
+				time.Until(next) > ExtractionRetryDelay &&
+				time.Until(next) <= 2*ExtractionRetryDelay
+		})).Return(extraction, nil)
+
+		_, err := service.ProcessNext(context.Background())
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("fails after the last attempt", func(t *testing.T) {
+		service, mockRepo, mockDocuments, mockStorage := setupExtractionService()
+		document := markdownDocument()
+		extraction := CreateTestExtraction(document)
+		extraction.Attempts = ExtractionMaxAttempts
+
+		mockRepo.On("Claim", mock.Anything, mock.Anything, ExtractionLease).Return(extraction, nil)
+		mockDocuments.On("GetByID", mock.Anything, testWorkspaceID, document.ID).Return(document, nil)
+		mockStorage.On("Open", mock.Anything, document.ObjectKey).Return(nil, errors.New("storage unavailable"))
+		mockRepo.On("UpdateAttempt", mock.Anything, extraction.ID, ExtractionMaxAttempts, mock.MatchedBy(func(update bson.M) bool {
+			return update["$set"].(bson.M)["status"] == ExtractionStatusFailed
+		})).Return(extraction, nil)
+
+		_, err := service.ProcessNext(context.Background())
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestExtractionService_RetryExtraction(t *testing.T) {
+	t.Run("queues a failed extraction again", func(t *testing.T) {
+		service, mockRepo, _, _ := setupExtractionService()
+		extraction := CreateTestExtraction(CreateTestDocument())
+		extraction.Status = ExtractionStatusFailed
+		extraction.Attempts = ExtractionMaxAttempts
+
+		mockRepo.On("GetVersion", mock.Anything, testWorkspaceID, extraction.DocumentID, 1).Return(extraction, nil)
+		mockRepo.On("UpdateAttempt", mock.Anything, extraction.ID, ExtractionMaxAttempts, mock.MatchedBy(func(update bson.M) bool {
+			set := update["$set"].(bson.M)
+			return set["status"] == ExtractionStatusQueued && set["attempts"] == 0
+		})).Return(extraction, nil)
+
+		_, err := service.RetryExtraction(context.Background(), testWorkspaceID, extraction.DocumentID, 1)
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects extractions in progress", func(t *testing.T) {
+		service, mockRepo, _, _ := setupExtractionService()
+		extraction := CreateTestExtraction(CreateTestDocument())
+
+		mockRepo.On("GetLatest", mock.Anything, testWorkspaceID, extraction.DocumentID).Return(extraction, nil)
+
+		_, err := service.RetryExtraction(context.Background(), testWorkspaceID, extraction.DocumentID, 0)
+
+		assert.EqualError(t, err, "extraction is already in progress")
+		mockRepo.AssertNotCalled(t, "UpdateAttempt", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("queues versions that were never extracted", func(t *testing.T) {
+		service, mockRepo, mockDocuments, _ := setupExtractionService()
+		document := CreateTestDocument()
+
+		mockRepo.On("GetLatest", mock.Anything, testWorkspaceID, document.ID.Hex()).Return(nil, nil)
+		mockDocuments.On("GetByID", mock.Anything, testWorkspaceID, document.ID).Return(document, nil)
+		mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(extraction *Extraction) bool {
+			return extraction.Version == 1 && extraction.Status == ExtractionStatusQueued
+		})).Return(CreateTestExtraction(document), nil)
+
+		_, err := service.RetryExtraction(context.Background(), testWorkspaceID, document.ID.Hex(), 0)
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestExtractionService_GetResult(t *testing.T) {
+	t.Run("reads the stored structure", func(t *testing.T) {
+		service, mockRepo, _, mockStorage := setupExtractionService()
+		extraction := CreateTestExtraction(CreateTestDocument())
+		extraction.Status = ExtractionStatusSucceeded
+		extraction.StructureKey = "structure.json"
+
+		mockRepo.On("GetLatest", mock.Anything, testWorkspaceID, extraction.DocumentID).Return(extraction, nil)
+		mockStorage.On("Open", mock.Anything, "structure.json").Return(io.NopCloser(strings.NewReader(
+			`{"format":"text","blocks":[{"type":"heading","level":1,"text":"Notes"},{"type":"paragraph","text":"Body"}]}`,
+		)), nil)
+
+		result, err := service.GetResult(context.Background(), testWorkspaceID, extraction.DocumentID, 0)
+
+		require.NoError(t, err)
+		assert.Equal(t, textract.FormatText, result.Format)
+		assert.Equal(t, "Notes\n\nBody", result.Text)
+		assert.Len(t, result.Blocks, 2)
+	})
+
+	t.Run("requires a finished extraction", func(t *testing.T) {
+		service, mockRepo, _, _ := setupExtractionService()
+		extraction := CreateTestExtraction(CreateTestDocument())
+
+		mockRepo.On("GetLatest", mock.Anything, testWorkspaceID, extraction.DocumentID).Return(extraction, nil)
+
+		_, err := service.GetResult(context.Background(), testWorkspaceID, extraction.DocumentID, 0)
+
+		assert.ErrorContains(t, err, "not found")
+	})
+}
@@ -0,0 +1,402 @@
+package document
+
+import (
+	"fmt"
+	"mime/multipart"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/binding"
+)
+
+type DocumentHandler struct {
+	service DocumentService
+}
+
+func NewDocumentHandler(service DocumentService) *DocumentHandler {
+	return &DocumentHandler{
+		service: service,
+	}
+}
+
+// UploadDocument godoc
+// @Summary Upload a document
+// @Description Upload a file through the API as multipart form data. Large files are better sent straight to storage with a presigned upload.
+// @Tags documents
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param file formData file true "File to upload"
+// @Param name formData string false "Document name, defaults to the file name"
+// @Param checksum formData string false "Expected SHA-256 of the file, hex encoded"
+// @Success 201 {object} map[string]interface{} "Document uploaded successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 413 {object} map[string]interface{} "File too large"
+// @Router /workspaces/{workspaceId}/documents [post]
+func (h *DocumentHandler) UploadDocument(c *fiber.Ctx) error {
+	input, err := uploadInput(c)
+	if err != nil {
+		return documentError(c, "Failed to upload document", err)
+	}
+
+	file, err := input.open()
+	if err != nil {
+		return documentError(c, "Failed to upload document", err)
+	}
+	defer file.Close()
+
+	document, err := h.service.UploadDocument(c.Context(), workspaceIDFrom(c), accountIDFrom(c), &input.UploadInput, file)
+	if err != nil {
+		return documentError(c, "Failed to upload document", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Document uploaded successfully",
+		"data":    document,
+	})
+}
+
+// CreateUpload godoc
+// @Summary Start a presigned upload
+// @Description Create a pending document and return a URL the client puts the file to directly. Complete the upload once the file is stored.
+// @Tags documents
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param request body CreateUploadRequest true "File name, content type, size and optional checksum"
+// @Success 201 {object} map[string]interface{} "Upload created successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /workspaces/{workspaceId}/documents/uploads [post]
+func (h *DocumentHandler) CreateUpload(c *fiber.Ctx) error {
+	var req CreateUploadRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	upload, err := h.service.CreateUpload(c.Context(), workspaceIDFrom(c), accountIDFrom(c), &req)
+	if err != nil {
+		return documentError(c, "Failed to create upload", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Upload created successfully",
+		"data":    upload,
+	})
+}
+
+// ListDocuments godoc
+// @Summary List documents
+// @Description Page through the documents of the workspace, newest first.
+// @Tags documents
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param q query string false "Case-insensitive match on the name"
+// @Param status query string false "Filter by status" Enums(pending, ready)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(20)
+// @Success 200 {object} map[string]interface{} "Documents retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /workspaces/{workspaceId}/documents [get]
+func (h *DocumentHandler) ListDocuments(c *fiber.Ctx) error {
+	var query DocumentListQuery
+	if err := binding.Query(c, &query); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	documents, err := h.service.ListDocuments(c.Context(), workspaceIDFrom(c), &query)
+	if err != nil {
+		return documentError(c, "Failed to list documents", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Documents retrieved successfully",
+		"data":    documents,
+	})
+}
+
+// GetDocument godoc
+// @Summary Get a document
+// @Description Get the metadata of a document and its earlier versions.
+// @Tags documents
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Document ID"
+// @Success 200 {object} map[string]interface{} "Document retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Document not found"
+// @Router /workspaces/{workspaceId}/documents/{id} [get]
+func (h *DocumentHandler) GetDocument(c *fiber.Ctx) error {
+	document, err := h.service.GetDocument(c.Context(), workspaceIDFrom(c), c.Params("id"))
+	if err != nil {
+		return documentError(c, "Failed to get document", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Document retrieved successfully",
+		"data":    document,
+	})
+}
+
+// UpdateDocument godoc
+// @Summary Rename a document
+// @Description Change the name of a document.
+// @Tags documents
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Document ID"
+// @Param request body UpdateDocumentRequest true "New name"
+// @Success 200 {object} map[string]interface{} "Document updated successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Document not found"
+// @Router /workspaces/{workspaceId}/documents/{id} [patch]
+func (h *DocumentHandler) UpdateDocument(c *fiber.Ctx) error {
+	var req UpdateDocumentRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	document, err := h.service.UpdateDocument(c.Context(), workspaceIDFrom(c), c.Params("id"), &req)
+	if err != nil {
+		return documentError(c, "Failed to update document", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Document updated successfully",
+		"data":    document,
+	})
+}
+
+// ReplaceContent godoc
+// @Summary Replace the file of a document
+// @Description Upload a new version of the file as multipart form data. The replaced file stays available as an earlier version.
+// @Tags documents
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Document ID"
+// @Param file formData file true "File to upload"
+// @Param checksum formData string false "Expected SHA-256 of the file, hex encoded"
+// @Success 200 {object} map[string]interface{} "Document content replaced successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Document not found"
+// @Failure 409 {object} map[string]interface{} "Document changed by another upload"
+// @Failure 413 {object} map[string]interface{} "File too large"
+// @Router /workspaces/{workspaceId}/documents/{id}/content [put]
+func (h *DocumentHandler) ReplaceContent(c *fiber.Ctx) error {
+	input, err := uploadInput(c)
+	if err != nil {
+		return documentError(c, "Failed to replace document content", err)
+	}
+
+	file, err := input.open()
+	if err != nil {
+		return documentError(c, "Failed to replace document content", err)
+	}
+	defer file.Close()
+
+	document, err := h.service.ReplaceContent(c.Context(), workspaceIDFrom(c), accountIDFrom(c), c.Params("id"), &input.UploadInput, file)
+	if err != nil {
+		return documentError(c, "Failed to replace document content", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Document content replaced successfully",
+		"data":    document,
+	})
+}
+
+// CreateVersionUpload godoc
+// @Summary Start a presigned upload of a new version
+// @Description Return a URL the client puts the new file to directly. Complete the upload once the file is stored.
+// @Tags documents
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Document ID"
+// @Param request body CreateVersionUploadRequest true "Content type, size and optional checksum"
+// @Success 201 {object} map[string]interface{} "Upload created successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Document not found"
+// @Router /workspaces/{workspaceId}/documents/{id}/uploads [post]
+func (h *DocumentHandler) CreateVersionUpload(c *fiber.Ctx) error {
+	var req CreateVersionUploadRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	upload, err := h.service.CreateVersionUpload(c.Context(), workspaceIDFrom(c), accountIDFrom(c), c.Params("id"), &req)
+	if err != nil {
+		return documentError(c, "Failed to create upload", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Upload created successfully",
+		"data":    upload,
+	})
+}
+
+// CompleteUpload godoc
+// @Summary Complete a presigned upload
+// @Description Verify the size and checksum of the uploaded file and make it the current version of the document.
+// @Tags documents
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Document ID"
+// @Success 200 {object} map[string]interface{} "Upload completed successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Document not found"
+// @Failure 409 {object} map[string]interface{} "Document changed by another upload"
+// @Router /workspaces/{workspaceId}/documents/{id}/uploads/complete [post]
+func (h *DocumentHandler) CompleteUpload(c *fiber.Ctx) error {
+	document, err := h.service.CompleteUpload(c.Context(), workspaceIDFrom(c), c.Params("id"))
+	if err != nil {
+		return documentError(c, "Failed to complete upload", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Upload completed successfully",
+		"data":    document,
+	})
+}
+
+// GetDownloadURL godoc
+// @Summary Download a document
+// @Description Return a short-lived URL to download the current file or an earlier version straight from storage.
+// @Tags documents
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Document ID"
+// @Param version query int false "Version to download, defaults to the current one"
+// @Success 200 {object} map[string]interface{} "Download URL created successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Document not found"
+// @Router /workspaces/{workspaceId}/documents/{id}/download [get]
+func (h *DocumentHandler) GetDownloadURL(c *fiber.Ctx) error {
+	var query DownloadQuery
+	if err := binding.Query(c, &query); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	download, err := h.service.GetDownloadURL(c.Context(), workspaceIDFrom(c), c.Params("id"), query.Version)
+	if err != nil {
+		return documentError(c, "Failed to download document", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Download URL created successfully",
+		"data":    download,
+	})
+}
+
+// DeleteDocument godoc
+// @Summary Delete a document
+// @Description Delete a document with every stored version of its file.
+// @Tags documents
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Document ID"
+// @Success 200 {object} map[string]interface{} "Document deleted successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Document not found"
+// @Router /workspaces/{workspaceId}/documents/{id} [delete]
+func (h *DocumentHandler) DeleteDocument(c *fiber.Ctx) error {
+	if err := h.service.DeleteDocument(c.Context(), workspaceIDFrom(c), c.Params("id")); err != nil {
+		return documentError(c, "Failed to delete document", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Document deleted successfully",
+	})
+}
+
+// multipartUpload is the file part of a multipart request with the form
+// fields describing it.
+type multipartUpload struct {
+	UploadInput
+	header *multipart.FileHeader
+}
+
+func uploadInput(c *fiber.Ctx) (*multipartUpload, error) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, fmt.Errorf("invalid file: the file field is required")
+	}
+
+	name := c.FormValue("name")
+	if name == "" {
+		name = header.Filename
+	}
+
+	return &multipartUpload{
+		UploadInput: UploadInput{
+			Name:        name,
+			ContentType: header.Header.Get("Content-Type"),
+			Size:        header.Size,
+			Checksum:    c.FormValue("checksum"),
+		},
+		header: header,
+	}, nil
+}
+
+func (u *multipartUpload) open() (multipart.File, error) {
+	file, err := u.header.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return file, nil
+}
+
+func accountIDFrom(c *fiber.Ctx) string {
+	accountID, _ := c.Locals("account_id").(string)
+	return accountID
+}
+
+// workspaceIDFrom returns the workspace resolved by
+// workspace.WorkspaceMiddleware.
+func workspaceIDFrom(c *fiber.Ctx) string {
+	workspaceID, _ := c.Locals("workspace_id").(string)
+	return workspaceID
+}
+
+func documentError(c *fiber.Ctx, title string, err error) error {
+	statusCode := fiber.StatusInternalServerError
+	if strings.Contains(err.Error(), "not found") {
+		statusCode = fiber.StatusNotFound
+	} else if strings.Contains(err.Error(), "larger than") {
+		statusCode = fiber.StatusRequestEntityTooLarge
+	} else if strings.Contains(err.Error(), "already") {
+		statusCode = fiber.StatusConflict
+	} else if strings.Contains(err.Error(), "invalid") {
+		statusCode = fiber.StatusBadRequest
+	}
+
+	return c.Status(statusCode).JSON(fiber.Map{
+		"error":   title,
+		"message": err.Error(),
+	})
+}
@@ -0,0 +1,145 @@
+package document
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const DocumentCollectionName = "documents"
+
+const (
+	// DefaultMaxDocumentSize is the largest file accepted when the module is
+	// not configured otherwise.
+	DefaultMaxDocumentSize int64 = 50 << 20
+
+	PresignedUploadExpiry   = 15 * time.Minute
+	PresignedDownloadExpiry = 15 * time.Minute
+
+	DocumentListDefaultLimit = 20
+	DocumentListMaxLimit     = 100
+
+	defaultContentType = "application/octet-stream"
+)
+
+// DocumentStatus is pending until the first file of a document has been
+// uploaded and verified.
+type DocumentStatus string
+
+const (
+	DocumentStatusPending DocumentStatus = "pending"
+	DocumentStatusReady   DocumentStatus = "ready"
+)
+
+// DocumentVersion is one stored file of a document. Version numbers start at
+// 1 and grow by one each time the file is replaced.
+type DocumentVersion struct {
+	Version     int       `json:"version" bson:"version"`
+	ObjectKey   string    `json:"-" bson:"object_key"`
+	ContentType string    `json:"content_type" bson:"content_type"`
+	Size        int64     `json:"size" bson:"size"`
+	Checksum    string    `json:"checksum" bson:"checksum"`
+	UploadedBy  string    `json:"uploaded_by" bson:"uploaded_by"`
+	UploadedAt  time.Time `json:"uploaded_at" bson:"uploaded_at"`
+}
+
+// PendingUpload is a presigned upload that has not been completed yet.
+// Checksum is the SHA-256 the client announced, if any.
+type PendingUpload struct {
+	ObjectKey   string    `json:"-" bson:"object_key"`
+	ContentType string    `json:"content_type" bson:"content_type"`
+	Size        int64     `json:"size" bson:"size"`
+	Checksum    string    `json:"checksum,omitempty" bson:"checksum,omitempty"`
+	UploadedBy  string    `json:"uploaded_by" bson:"uploaded_by"`
+	ExpiresAt   time.Time `json:"expires_at" bson:"expires_at"`
+}
+
+func (u *PendingUpload) IsExpired() bool {
+	return time.Now().After(u.ExpiresAt)
+}
+
+// Document is the metadata of a stored file. The embedded version is the
+// current file; Versions holds the files it replaced, oldest first.
+type Document struct {
+	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	WorkspaceID     string             `json:"workspace_id" bson:"workspace_id"`
+	OwnerID         string             `json:"owner_id" bson:"owner_id"`
+	Name            string             `json:"name" bson:"name"`
+	Status          DocumentStatus     `json:"status" bson:"status"`
+	DocumentVersion `bson:",inline"`
+	Versions        []DocumentVersion `json:"versions" bson:"versions"`
+	Upload          *PendingUpload    `json:"upload,omitempty" bson:"upload,omitempty"`
+	CreatedAt       time.Time         `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at" bson:"updated_at"`
+}
+
+// FindVersion returns the current file for version 0 and nil when the
+// version does not exist.
+func (d *Document) FindVersion(version int) *DocumentVersion {
+	if d.Status != DocumentStatusReady {
+		return nil
+	}
+	if version == 0 || version == d.Version {
+		return &d.DocumentVersion
+	}
+
+	for i := range d.Versions {
+		if d.Versions[i].Version == version {
+			return &d.Versions[i]
+		}
+	}
+
+	return nil
+}
+
+// UploadInput is a file streamed through the API.
+type UploadInput struct {
+	Name        string
+	ContentType string
+	Size        int64
+	Checksum    string
+}
+
+type CreateUploadRequest struct {
+	Name        string `json:"name" validate:"required,max=255"`
+	ContentType string `json:"content_type" validate:"max=255"`
+	Size        int64  `json:"size" validate:"required,min=1"`
+	Checksum    string `json:"checksum" validate:"omitempty,len=64,hexadecimal"`
+}
+
+type CreateVersionUploadRequest struct {
+	ContentType string `json:"content_type" validate:"max=255"`
+	Size        int64  `json:"size" validate:"required,min=1"`
+	Checksum    string `json:"checksum" validate:"omitempty,len=64,hexadecimal"`
+}
+
+type UpdateDocumentRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+type UploadResponse struct {
+	Document  *Document `json:"document"`
+	UploadURL string    `json:"upload_url"`
+	Method    string    `json:"method"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type DownloadResponse struct {
+	URL         string    `json:"url"`
+	Version     int       `json:"version"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Checksum    string    `json:"checksum"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type DocumentListQuery struct {
+	Q      string         `query:"q"`
+	Status DocumentStatus `query:"status" validate:"omitempty,oneof=pending ready"`
+	Page   int64          `query:"page"`
+	Limit  int64          `query:"limit"`
+}
+
+type DownloadQuery struct {
+	Version int `query:"version" validate:"min=0"`
+}
@@ -0,0 +1,113 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+type DocumentRepository interface {
+	Create(ctx context.Context, document *Document) (*Document, error)
+	GetByID(ctx context.Context, workspaceID string, id primitive.ObjectID) (*Document, error)
+	List(ctx context.Context, filter bson.M, pagination mongo.PaginationOptions) (*mongo.PaginatedResult[Document], error)
+	Update(ctx context.Context, workspaceID string, id primitive.ObjectID, update bson.M) (*Document, error)
+	UpdateAtVersion(ctx context.Context, workspaceID string, id primitive.ObjectID, version int, update bson.M) (*Document, error)
+	Delete(ctx context.Context, workspaceID string, id primitive.ObjectID) error
+	DeleteByWorkspace(ctx context.Context, workspaceID string) error
+}
+
+type documentRepository struct {
+	repo mongo.Repository[Document]
+}
+
+var _ DocumentRepository = (*documentRepository)(nil)
+
+func NewDocumentRepository(mongoService *mongo.MongoService) DocumentRepository {
+	return &documentRepository{
+		repo: mongo.NewRepository[Document](mongoService, DocumentCollectionName),
+	}
+}
+
+func (r *documentRepository) Create(ctx context.Context, document *Document) (*Document, error) {
+	if document.ID.IsZero() {
+		document.ID = primitive.NewObjectID()
+	}
+
+	result, err := r.repo.Create(ctx, *document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create document: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *documentRepository) GetByID(ctx context.Context, workspaceID string, id primitive.ObjectID) (*Document, error) {
+	result, err := r.repo.FindOne(ctx, bson.M{"_id": id, "workspace_id": workspaceID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *documentRepository) List(ctx context.Context, filter bson.M, pagination mongo.PaginationOptions) (*mongo.PaginatedResult[Document], error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	result, err := r.repo.FindWithPagination(ctx, filter, pagination, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	return result, nil
+}
+
+// Update applies a full update document, so callers may combine $set with
+// $push or $unset. It returns nil when the document does not exist.
+func (r *documentRepository) Update(ctx context.Context, workspaceID string, id primitive.ObjectID, update bson.M) (*Document, error) {
+	return r.update(ctx, bson.M{"_id": id, "workspace_id": workspaceID}, update)
+}
+
+// UpdateAtVersion only applies the update while the current version is still
+// version, so two uploads finishing at once cannot both become the next one.
+// It returns nil when the document does not exist or has moved on.
+func (r *documentRepository) UpdateAtVersion(ctx context.Context, workspaceID string, id primitive.ObjectID, version int, update bson.M) (*Document, error) {
+	return r.update(ctx, bson.M{"_id": id, "workspace_id": workspaceID, "version": version}, update)
+}
+
+func (r *documentRepository) update(ctx context.Context, filter bson.M, update bson.M) (*Document, error) {
+	set, _ := update["$set"].(bson.M)
+	if set == nil {
+		set = bson.M{}
+		update["$set"] = set
+	}
+	set["updated_at"] = time.Now()
+
+	result, err := r.repo.Update(ctx, filter, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update document: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *documentRepository) Delete(ctx context.Context, workspaceID string, id primitive.ObjectID) error {
+	if err := r.repo.Delete(ctx, bson.M{"_id": id, "workspace_id": workspaceID}); err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+
+	return nil
+}
+
+func (r *documentRepository) DeleteByWorkspace(ctx context.Context, workspaceID string) error {
+	if _, err := r.repo.DeleteMany(ctx, bson.M{"workspace_id": workspaceID}); err != nil {
+		return fmt.Errorf("failed to delete documents: %w", err)
+	}
+
+	return nil
+}
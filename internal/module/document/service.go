@@ -0,0 +1,576 @@
+package document
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/minio"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+// DocumentVersionHook is told about every file that becomes the current
+// version of a document. It runs after the change is stored and cannot undo
+// it, so errors are only logged.
+type DocumentVersionHook func(ctx context.Context, document *Document) error
+
+// DocumentDeleteHook removes data that other modules keep for a document.
+// Hooks run before the document is deleted, so a failing hook leaves the
+// document in place and the deletion can be retried.
+type DocumentDeleteHook func(ctx context.Context, workspaceID, documentID string) error
+
+type DocumentService interface {
+	UploadDocument(ctx context.Context, workspaceID, accountID string, input *UploadInput, body io.Reader) (*Document, error)
+	ReplaceContent(ctx context.Context, workspaceID, accountID, id string, input *UploadInput, body io.Reader) (*Document, error)
+	CreateUpload(ctx context.Context, workspaceID, accountID string, req *CreateUploadRequest) (*UploadResponse, error)
+	CreateVersionUpload(ctx context.Context, workspaceID, accountID, id string, req *CreateVersionUploadRequest) (*UploadResponse, error)
+	CompleteUpload(ctx context.Context, workspaceID, id string) (*Document, error)
+	ListDocuments(ctx context.Context, workspaceID string, query *DocumentListQuery) (*mongo.PaginatedResult[Document], error)
+	GetDocument(ctx context.Context, workspaceID, id string) (*Document, error)
+	UpdateDocument(ctx context.Context, workspaceID, id string, req *UpdateDocumentRequest) (*Document, error)
+	GetDownloadURL(ctx context.Context, workspaceID, id string, version int) (*DownloadResponse, error)
+	OpenVersion(ctx context.Context, workspaceID, id string, version int) (io.ReadCloser, *DocumentVersion, error)
+	DeleteDocument(ctx context.Context, workspaceID, id string) error
+	DeleteWorkspace(ctx context.Context, workspaceID string) error
+	OnVersion(hook DocumentVersionHook)
+	OnDelete(hook DocumentDeleteHook)
+}
+
+type documentService struct {
+	repository DocumentRepository
+	storage    ObjectStorage
+	maxSize    int64
+
+	mu           sync.RWMutex
+	versionHooks []DocumentVersionHook
+	deleteHooks  []DocumentDeleteHook
+}
+
+func NewDocumentService(mongoService *mongo.MongoService, minioService minio.MinIOService, maxSize int64) DocumentService {
+	return newDocumentService(NewDocumentRepository(mongoService), NewObjectStorage(minioService), maxSize)
+}
+
+func newDocumentService(repository DocumentRepository, storage ObjectStorage, maxSize int64) *documentService {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxDocumentSize
+	}
+
+	return &documentService{
+		repository: repository,
+		storage:    storage,
+		maxSize:    maxSize,
+	}
+}
+
+func (s *documentService) OnVersion(hook DocumentVersionHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.versionHooks = append(s.versionHooks, hook)
+}
+
+func (s *documentService) OnDelete(hook DocumentDeleteHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteHooks = append(s.deleteHooks, hook)
+}
+
+func (s *documentService) UploadDocument(ctx context.Context, workspaceID, accountID string, input *UploadInput, body io.Reader) (*Document, error) {
+	name, err := documentName(input.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	id := primitive.NewObjectID()
+	version, err := s.store(ctx, workspaceID, id, accountID, input, body)
+	if err != nil {
+		return nil, err
+	}
+	version.Version = 1
+
+	now := time.Now()
+	document, err := s.repository.Create(ctx, &Document{
+		ID:              id,
+		WorkspaceID:     workspaceID,
+		OwnerID:         accountID,
+		Name:            name,
+		Status:          DocumentStatusReady,
+		DocumentVersion: *version,
+		Versions:        []DocumentVersion{},
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	})
+	if err != nil {
+		s.storage.Delete(ctx, version.ObjectKey)
+		return nil, err
+	}
+	s.notifyVersion(ctx, document)
+
+	return document, nil
+}
+
+func (s *documentService) ReplaceContent(ctx context.Context, workspaceID, accountID, id string, input *UploadInput, body io.Reader) (*Document, error) {
+	document, err := s.getDocument(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := s.store(ctx, workspaceID, document.ID, accountID, input, body)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.promote(ctx, document, version, false)
+	if err != nil {
+		s.storage.Delete(ctx, version.ObjectKey)
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (s *documentService) CreateUpload(ctx context.Context, workspaceID, accountID string, req *CreateUploadRequest) (*UploadResponse, error) {
+	name, err := documentName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	id := primitive.NewObjectID()
+	upload, url, err := s.presignUpload(ctx, workspaceID, id, accountID, req.ContentType, req.Size, req.Checksum)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	document, err := s.repository.Create(ctx, &Document{
+		ID:          id,
+		WorkspaceID: workspaceID,
+		OwnerID:     accountID,
+		Name:        name,
+		Status:      DocumentStatusPending,
+		Versions:    []DocumentVersion{},
+		Upload:      upload,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &UploadResponse{
+		Document:  document,
+		UploadURL: url,
+		Method:    http.MethodPut,
+		ExpiresAt: upload.ExpiresAt,
+	}, nil
+}
+
+// CreateVersionUpload replaces any upload already in progress; its object
+// is removed when the document is deleted.
+func (s *documentService) CreateVersionUpload(ctx context.Context, workspaceID, accountID, id string, req *CreateVersionUploadRequest) (*UploadResponse, error) {
+	document, err := s.getDocument(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	upload, url, err := s.presignUpload(ctx, workspaceID, document.ID, accountID, req.ContentType, req.Size, req.Checksum)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repository.Update(ctx, workspaceID, document.ID, bson.M{"$set": bson.M{"upload": upload}})
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("document not found")
+	}
+
+	return &UploadResponse{
+		Document:  updated,
+		UploadURL: url,
+		Method:    http.MethodPut,
+		ExpiresAt: upload.ExpiresAt,
+	}, nil
+}
+
+// CompleteUpload checks the object a client put through a presigned URL and
+// makes it the current version. An object that does not match the announced
+// size or checksum is deleted and the upload has to be started again.
+func (s *documentService) CompleteUpload(ctx context.Context, workspaceID, id string) (*Document, error) {
+	document, err := s.getDocument(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	upload := document.Upload
+	if upload == nil {
+		return nil, fmt.Errorf("invalid upload: no upload in progress")
+	}
+	if upload.IsExpired() {
+		s.discardUpload(ctx, document)
+		return nil, fmt.Errorf("invalid upload: upload has expired")
+	}
+
+	size, err := s.storage.Stat(ctx, upload.ObjectKey)
+	if errors.Is(err, errObjectNotFound) {
+		return nil, fmt.Errorf("invalid upload: file has not been uploaded")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if size != upload.Size {
+		s.discardUpload(ctx, document)
+		return nil, fmt.Errorf("invalid upload: uploaded %d bytes, expected %d", size, upload.Size)
+	}
+
+	checksum, err := s.checksum(ctx, upload.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Checksum != "" && checksum != upload.Checksum {
+		s.discardUpload(ctx, document)
+		return nil, fmt.Errorf("invalid upload: checksum does not match")
+	}
+
+	return s.promote(ctx, document, &DocumentVersion{
+		ObjectKey:   upload.ObjectKey,
+		ContentType: upload.ContentType,
+		Size:        size,
+		Checksum:    checksum,
+		UploadedBy:  upload.UploadedBy,
+		UploadedAt:  time.Now(),
+	}, true)
+}
+
+func (s *documentService) ListDocuments(ctx context.Context, workspaceID string, query *DocumentListQuery) (*mongo.PaginatedResult[Document], error) {
+	filter := bson.M{"workspace_id": workspaceID}
+	if query.Status != "" {
+		filter["status"] = query.Status
+	}
+	if q := strings.TrimSpace(query.Q); q != "" {
+		filter["name"] = bson.M{"$regex": regexp.QuoteMeta(q), "$options": "i"}
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DocumentListDefaultLimit
+	}
+	if limit > DocumentListMaxLimit {
+		limit = DocumentListMaxLimit
+	}
+
+	return s.repository.List(ctx, filter, mongo.PaginationOptions{
+		Page:  query.Page,
+		Limit: limit,
+	})
+}
+
+func (s *documentService) GetDocument(ctx context.Context, workspaceID, id string) (*Document, error) {
+	return s.getDocument(ctx, workspaceID, id)
+}
+
+func (s *documentService) UpdateDocument(ctx context.Context, workspaceID, id string, req *UpdateDocumentRequest) (*Document, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("document not found")
+	}
+
+	name, err := documentName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := s.repository.Update(ctx, workspaceID, objectID, bson.M{"$set": bson.M{"name": name}})
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, fmt.Errorf("document not found")
+	}
+
+	return document, nil
+}
+
+// GetDownloadURL presigns a GET for the current file when version is 0.
+func (s *documentService) GetDownloadURL(ctx context.Context, workspaceID, id string, version int) (*DownloadResponse, error) {
+	document, err := s.getDocument(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	file := document.FindVersion(version)
+	if file == nil {
+		return nil, fmt.Errorf("document version not found")
+	}
+
+	url, err := s.storage.PresignGet(ctx, file.ObjectKey, PresignedDownloadExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign download: %w", err)
+	}
+
+	return &DownloadResponse{
+		URL:         url,
+		Version:     file.Version,
+		ContentType: file.ContentType,
+		Size:        file.Size,
+		Checksum:    file.Checksum,
+		ExpiresAt:   time.Now().Add(PresignedDownloadExpiry),
+	}, nil
+}
+
+// OpenVersion streams a stored file for other modules. Version 0 is the
+// current file.
+func (s *documentService) OpenVersion(ctx context.Context, workspaceID, id string, version int) (io.ReadCloser, *DocumentVersion, error) {
+	document, err := s.getDocument(ctx, workspaceID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file := document.FindVersion(version)
+	if file == nil {
+		return nil, nil, fmt.Errorf("document version not found")
+	}
+
+	reader, err := s.storage.Open(ctx, file.ObjectKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open document: %w", err)
+	}
+
+	return reader, file, nil
+}
+
+// DeleteDocument removes every object stored for the document before the
+// record, so a failed deletion can be retried without leaking objects.
+func (s *documentService) DeleteDocument(ctx context.Context, workspaceID, id string) error {
+	document, err := s.getDocument(ctx, workspaceID, id)
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	hooks := append([]DocumentDeleteHook(nil), s.deleteHooks...)
+	s.mu.RUnlock()
+
+	for _, hook := range hooks {
+		if err := hook(ctx, workspaceID, document.ID.Hex()); err != nil {
+			return err
+		}
+	}
+
+	if err := s.deletePrefix(ctx, documentPrefix(workspaceID, document.ID)); err != nil {
+		return err
+	}
+
+	return s.repository.Delete(ctx, workspaceID, document.ID)
+}
+
+func (s *documentService) DeleteWorkspace(ctx context.Context, workspaceID string) error {
+	if err := s.deletePrefix(ctx, workspacePrefix(workspaceID)); err != nil {
+		return err
+	}
+
+	return s.repository.DeleteByWorkspace(ctx, workspaceID)
+}
+
+func (s *documentService) getDocument(ctx context.Context, workspaceID, id string) (*Document, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("document not found")
+	}
+
+	document, err := s.repository.GetByID(ctx, workspaceID, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, fmt.Errorf("document not found")
+	}
+
+	return document, nil
+}
+
+// store streams body to a new object while hashing it. The returned version
+// has no number yet; promote assigns it.
+func (s *documentService) store(ctx context.Context, workspaceID string, id primitive.ObjectID, accountID string, input *UploadInput, body io.Reader) (*DocumentVersion, error) {
+	if input.Size <= 0 {
+		return nil, fmt.Errorf("invalid file: file is empty")
+	}
+	if input.Size > s.maxSize {
+		return nil, fmt.Errorf("invalid file: file is larger than %d bytes", s.maxSize)
+	}
+
+	key := objectKey(workspaceID, id)
+	hash := sha256.New()
+	if err := s.storage.Put(ctx, key, io.TeeReader(body, hash), input.Size, contentType(input.ContentType)); err != nil {
+		return nil, fmt.Errorf("failed to store document: %w", err)
+	}
+
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	if input.Checksum != "" && !strings.EqualFold(input.Checksum, checksum) {
+		s.storage.Delete(ctx, key)
+		return nil, fmt.Errorf("invalid file: checksum does not match")
+	}
+
+	return &DocumentVersion{
+		ObjectKey:   key,
+		ContentType: contentType(input.ContentType),
+		Size:        input.Size,
+		Checksum:    checksum,
+		UploadedBy:  accountID,
+		UploadedAt:  time.Now(),
+	}, nil
+}
+
+// promote makes version the current file. The file it replaces, if any, is
+// kept in Versions.
+func (s *documentService) promote(ctx context.Context, document *Document, version *DocumentVersion, completesUpload bool) (*Document, error) {
+	version.Version = document.Version + 1
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":       DocumentStatusReady,
+			"version":      version.Version,
+			"object_key":   version.ObjectKey,
+			"content_type": version.ContentType,
+			"size":         version.Size,
+			"checksum":     version.Checksum,
+			"uploaded_by":  version.UploadedBy,
+			"uploaded_at":  version.UploadedAt,
+		},
+	}
+	if document.Status == DocumentStatusReady {
+		update["$push"] = bson.M{"versions": document.DocumentVersion}
+	}
+	if completesUpload {
+		update["$unset"] = bson.M{"upload": ""}
+	}
+
+	updated, err := s.repository.UpdateAtVersion(ctx, document.WorkspaceID, document.ID, document.Version, update)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("document was already changed by another upload")
+	}
+	s.notifyVersion(ctx, updated)
+
+	return updated, nil
+}
+
+func (s *documentService) notifyVersion(ctx context.Context, document *Document) {
+	s.mu.RLock()
+	hooks := append([]DocumentVersionHook(nil), s.versionHooks...)
+	s.mu.RUnlock()
+
+	for _, hook := range hooks {
+		if err := hook(ctx, document); err != nil {
+			fmt.Printf("Document version hook failed for %s: %v\n", document.ID.Hex(), err)
+		}
+	}
+}
+
+func (s *documentService) presignUpload(ctx context.Context, workspaceID string, id primitive.ObjectID, accountID, mediaType string, size int64, checksum string) (*PendingUpload, string, error) {
+	if size > s.maxSize {
+		return nil, "", fmt.Errorf("invalid file: file is larger than %d bytes", s.maxSize)
+	}
+
+	upload := &PendingUpload{
+		ObjectKey:   objectKey(workspaceID, id),
+		ContentType: contentType(mediaType),
+		Size:        size,
+		Checksum:    strings.ToLower(checksum),
+		UploadedBy:  accountID,
+		ExpiresAt:   time.Now().Add(PresignedUploadExpiry),
+	}
+
+	url, err := s.storage.PresignPut(ctx, upload.ObjectKey, PresignedUploadExpiry)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to presign upload: %w", err)
+	}
+
+	return upload, url, nil
+}
+
+func (s *documentService) discardUpload(ctx context.Context, document *Document) {
+	s.storage.Delete(ctx, document.Upload.ObjectKey)
+	s.repository.Update(ctx, document.WorkspaceID, document.ID, bson.M{"$unset": bson.M{"upload": ""}})
+}
+
+func (s *documentService) checksum(ctx context.Context, key string) (string, error) {
+	reader, err := s.storage.Open(ctx, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read upload: %w", err)
+	}
+	defer reader.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, reader); err != nil {
+		return "", fmt.Errorf("failed to read upload: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (s *documentService) deletePrefix(ctx context.Context, prefix string) error {
+	keys, err := s.storage.List(ctx, prefix)
+	if err != nil {
+		return fmt.Errorf("failed to list document objects: %w", err)
+	}
+
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			return fmt.Errorf("failed to delete document object: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func workspacePrefix(workspaceID string) string {
+	return "workspaces/" + workspaceID + "/documents/"
+}
+
+func documentPrefix(workspaceID string, id primitive.ObjectID) string {
+	return workspacePrefix(workspaceID) + id.Hex() + "/"
+}
+
+// objectKey names every stored file uniquely, so a version upload that is
+// abandoned never overwrites a file still in use.
+func objectKey(workspaceID string, id primitive.ObjectID) string {
+	return documentPrefix(workspaceID, id) + primitive.NewObjectID().Hex()
+}
+
+func documentName(name string) (string, error) {
+	name = path.Base(strings.ReplaceAll(strings.TrimSpace(name), "\\", "/"))
+	if name == "" || name == "." || name == "/" {
+		return "", fmt.Errorf("invalid file: name is required")
+	}
+	if len(name) > 255 {
+		return "", fmt.Errorf("invalid file: name is longer than 255 bytes")
+	}
+
+	return name, nil
+}
+
+func contentType(mediaType string) string {
+	if mediaType = strings.TrimSpace(mediaType); mediaType == "" {
+		return defaultContentType
+	}
+
+	return mediaType
+}
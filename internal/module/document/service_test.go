@@ -0,0 +1,359 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+const helloChecksum = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+func setupDocumentService() (*documentService, *MockDocumentRepository, *MockObjectStorage) {
+	mockRepo := &MockDocumentRepository{}
+	mockStorage := &MockObjectStorage{}
+	return newDocumentService(mockRepo, mockStorage, 1024), mockRepo, mockStorage
+}
+
+func TestDocumentService_UploadDocument(t *testing.T) {
+	t.Run("stores the file and records its checksum", func(t *testing.T) {
+		service, mockRepo, mockStorage := setupDocumentService()
+
+		mockStorage.On("Put", mock.Anything, mock.MatchedBy(func(key string) bool {
+			return strings.HasPrefix(key, workspacePrefix(testWorkspaceID))
+		}), "hello", int64(5), "text/plain").Return(nil)
+		mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(document *Document) bool {
+			return document.Name == "hello.txt" &&
+				document.Status == DocumentStatusReady &&
+				document.Version == 1 &&
+				document.Checksum == helloChecksum &&
+				document.OwnerID == testAccountID
+		})).Return(CreateTestDocument(), nil)
+
+		_, err := service.UploadDocument(context.Background(), testWorkspaceID, testAccountID, &UploadInput{
+			Name:        "../notes/hello.txt",
+			ContentType: "text/plain",
+			Size:        5,
+		}, strings.NewReader("hello"))
+
+		require.NoError(t, err)
+		mockStorage.AssertExpectations(t)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects files over the size limit", func(t *testing.T) {
+		service, _, mockStorage := setupDocumentService()
+
+		_, err := service.UploadDocument(context.Background(), testWorkspaceID, testAccountID, &UploadInput{
+			Name: "large.bin",
+			Size: 2048,
+		}, strings.NewReader("ignored"))
+
+		assert.EqualError(t, err, "invalid file: file is larger than 1024 bytes")
+		mockStorage.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("deletes the object when the checksum does not match", func(t *testing.T) {
+		service, mockRepo, mockStorage := setupDocumentService()
+
+		mockStorage.On("Put", mock.Anything, mock.Anything, "hello", int64(5), defaultContentType).Return(nil)
+		mockStorage.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+		_, err := service.UploadDocument(context.Background(), testWorkspaceID, testAccountID, &UploadInput{
+			Name:     "hello.txt",
+			Size:     5,
+			Checksum: strings.Repeat("0", 64),
+		}, strings.NewReader("hello"))
+
+		assert.EqualError(t, err, "invalid file: checksum does not match")
+		mockStorage.AssertCalled(t, "Delete", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestDocumentService_ReplaceContent(t *testing.T) {
+	t.Run("keeps the replaced file as an earlier version", func(t *testing.T) {
+		service, mockRepo, mockStorage := setupDocumentService()
+		document := CreateTestDocument()
+
+		mockRepo.On("GetByID", mock.Anything, testWorkspaceID, document.ID).Return(document, nil)
+		mockStorage.On("Put", mock.Anything, mock.Anything, "hello", int64(5), "application/pdf").Return(nil)
+		mockRepo.On("UpdateAtVersion", mock.Anything, testWorkspaceID, document.ID, 1, mock.MatchedBy(func(update bson.M) bool {
+			set := update["$set"].(bson.M)
+			push := update["$push"].(bson.M)
+			return set["version"] == 2 && push["versions"].(DocumentVersion).Version == 1
+		})).Return(document, nil)
+
+		_, err := service.ReplaceContent(context.Background(), testWorkspaceID, testAccountID, document.ID.Hex(), &UploadInput{
+			Name:        "report.pdf",
+			ContentType: "application/pdf",
+			Size:        5,
+		}, strings.NewReader("hello"))
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("reports a concurrent replacement", func(t *testing.T) {
+		service, mockRepo, mockStorage := setupDocumentService()
+		document := CreateTestDocument()
+
+		mockRepo.On("GetByID", mock.Anything, testWorkspaceID, document.ID).Return(document, nil)
+		mockStorage.On("Put", mock.Anything, mock.Anything, "hello", int64(5), defaultContentType).Return(nil)
+		mockRepo.On("UpdateAtVersion", mock.Anything, testWorkspaceID, document.ID, 1, mock.Anything).Return(nil, nil)
+		mockStorage.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+		_, err := service.ReplaceContent(context.Background(), testWorkspaceID, testAccountID, document.ID.Hex(), &UploadInput{
+			Size: 5,
+		}, strings.NewReader("hello"))
+
+		assert.EqualError(t, err, "document was already changed by another upload")
+		mockStorage.AssertCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
+
+func TestDocumentService_CompleteUpload(t *testing.T) {
+	pendingDocument := func(checksum string) *Document {
+		document := CreateTestDocument()
+		document.Status = DocumentStatusPending
+		document.DocumentVersion = DocumentVersion{}
+		document.Upload = &PendingUpload{
+			ObjectKey:   documentPrefix(testWorkspaceID, document.ID) + "upload",
+			ContentType: "text/plain",
+			Size:        5,
+			Checksum:    checksum,
+			UploadedBy:  testAccountID,
+			ExpiresAt:   time.Now().Add(time.Minute),
+		}
+		return document
+	}
+
+	t.Run("promotes a verified upload to the first version", func(t *testing.T) {
+		service, mockRepo, mockStorage := setupDocumentService()
+		document := pendingDocument(helloChecksum)
+
+		mockRepo.On("GetByID", mock.Anything, testWorkspaceID, document.ID).Return(document, nil)
+		mockStorage.On("Stat", mock.Anything, document.Upload.ObjectKey).Return(int64(5), nil)
+		mockStorage.On("Open", mock.Anything, document.Upload.ObjectKey).Return(io.NopCloser(strings.NewReader("hello")), nil)
+		mockRepo.On("UpdateAtVersion", mock.Anything, testWorkspaceID, document.ID, 0, mock.MatchedBy(func(update bson.M) bool {
+			set := update["$set"].(bson.M)
+			_, pushes := update["$push"]
+			_, unsets := update["$unset"]
+			return set["version"] == 1 && set["checksum"] == helloChecksum && !pushes && unsets
+		})).Return(document, nil)
+
+		_, err := service.CompleteUpload(context.Background(), testWorkspaceID, document.ID.Hex())
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("discards an upload of the wrong size", func(t *testing.T) {
+		service, mockRepo, mockStorage := setupDocumentService()
+		document := pendingDocument("")
+
+		mockRepo.On("GetByID", mock.Anything, testWorkspaceID, document.ID).Return(document, nil)
+		mockStorage.On("Stat", mock.Anything, document.Upload.ObjectKey).Return(int64(7), nil)
+		mockStorage.On("Delete", mock.Anything, document.Upload.ObjectKey).Return(nil)
+		mockRepo.On("Update", mock.Anything, testWorkspaceID, document.ID, bson.M{"$unset": bson.M{"upload": ""}}).Return(document, nil)
+
+		_, err := service.CompleteUpload(context.Background(), testWorkspaceID, document.ID.Hex())
+
+		assert.EqualError(t, err, "invalid upload: uploaded 7 bytes, expected 5")
+		mockStorage.AssertExpectations(t)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("discards an upload with another checksum", func(t *testing.T) {
+		service, mockRepo, mockStorage := setupDocumentService()
+		document := pendingDocument(strings.Repeat("0", 64))
+
+		mockRepo.On("GetByID", mock.Anything, testWorkspaceID, document.ID).Return(document, nil)
+		mockStorage.On("Stat", mock.Anything, document.Upload.ObjectKey).Return(int64(5), nil)
+		mockStorage.On("Open", mock.Anything, document.Upload.ObjectKey).Return(io.NopCloser(strings.NewReader("hello")), nil)
+		mockStorage.On("Delete", mock.Anything, document.Upload.ObjectKey).Return(nil)
+		mockRepo.On("Update", mock.Anything, testWorkspaceID, document.ID, mock.Anything).Return(document, nil)
+
+		_, err := service.CompleteUpload(context.Background(), testWorkspaceID, document.ID.Hex())
+
+		assert.EqualError(t, err, "invalid upload: checksum does not match")
+		mockRepo.AssertNotCalled(t, "UpdateAtVersion", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("waits for the file to be uploaded", func(t *testing.T) {
+		service, mockRepo, mockStorage := setupDocumentService()
+		document := pendingDocument("")
+
+		mockRepo.On("GetByID", mock.Anything, testWorkspaceID, document.ID).Return(document, nil)
+		mockStorage.On("Stat", mock.Anything, document.Upload.ObjectKey).Return(int64(0), errObjectNotFound)
+
+		_, err := service.CompleteUpload(context.Background(), testWorkspaceID, document.ID.Hex())
+
+		assert.EqualError(t, err, "invalid upload: file has not been uploaded")
+		mockStorage.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("requires an upload in progress", func(t *testing.T) {
+		service, mockRepo, _ := setupDocumentService()
+		document := CreateTestDocument()
+
+		mockRepo.On("GetByID", mock.Anything, testWorkspaceID, document.ID).Return(document, nil)
+
+		_, err := service.CompleteUpload(context.Background(), testWorkspaceID, document.ID.Hex())
+
+		assert.EqualError(t, err, "invalid upload: no upload in progress")
+	})
+}
+
+func TestDocumentService_CreateUpload(t *testing.T) {
+	service, mockRepo, mockStorage := setupDocumentService()
+
+	mockStorage.On("PresignPut", mock.Anything, mock.Anything, PresignedUploadExpiry).Return("https://storage.example.com/upload", nil)
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(document *Document) bool {
+		return document.Status == DocumentStatusPending &&
+			document.Upload.Checksum == helloChecksum &&
+			document.Upload.ContentType == defaultContentType
+	})).Return(CreateTestDocument(), nil)
+
+	upload, err := service.CreateUpload(context.Background(), testWorkspaceID, testAccountID, &CreateUploadRequest{
+		Name:     "hello.txt",
+		Size:     5,
+		Checksum: strings.ToUpper(helloChecksum),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://storage.example.com/upload", upload.UploadURL)
+	assert.Equal(t, "PUT", upload.Method)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDocumentService_GetDownloadURL(t *testing.T) {
+	document := CreateTestDocument()
+	previous := document.DocumentVersion
+	document.Versions = []DocumentVersion{previous}
+	document.Version = 2
+	document.ObjectKey = documentPrefix(testWorkspaceID, document.ID) + "v2"
+
+	t.Run("presigns an earlier version", func(t *testing.T) {
+		service, mockRepo, mockStorage := setupDocumentService()
+
+		mockRepo.On("GetByID", mock.Anything, testWorkspaceID, document.ID).Return(document, nil)
+		mockStorage.On("PresignGet", mock.Anything, previous.ObjectKey, PresignedDownloadExpiry).Return("https://storage.example.com/v1", nil)
+
+		download, err := service.GetDownloadURL(context.Background(), testWorkspaceID, document.ID.Hex(), 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, download.Version)
+		assert.Equal(t, "https://storage.example.com/v1", download.URL)
+	})
+
+	t.Run("rejects unknown versions", func(t *testing.T) {
+		service, mockRepo, _ := setupDocumentService()
+
+		mockRepo.On("GetByID", mock.Anything, testWorkspaceID, document.ID).Return(document, nil)
+
+		_, err := service.GetDownloadURL(context.Background(), testWorkspaceID, document.ID.Hex(), 3)
+
+		assert.EqualError(t, err, "document version not found")
+	})
+}
+
+func TestDocumentService_DeleteDocument(t *testing.T) {
+	t.Run("deletes every object before the record", func(t *testing.T) {
+		service, mockRepo, mockStorage := setupDocumentService()
+		document := CreateTestDocument()
+		prefix := documentPrefix(testWorkspaceID, document.ID)
+
+		mockRepo.On("GetByID", mock.Anything, testWorkspaceID, document.ID).Return(document, nil)
+		mockStorage.On("List", mock.Anything, prefix).Return([]string{prefix + "v1", prefix + "v2"}, nil)
+		mockStorage.On("Delete", mock.Anything, prefix+"v1").Return(nil)
+		mockStorage.On("Delete", mock.Anything, prefix+"v2").Return(nil)
+		mockRepo.On("Delete", mock.Anything, testWorkspaceID, document.ID).Return(nil)
+
+		err := service.DeleteDocument(context.Background(), testWorkspaceID, document.ID.Hex())
+
+		require.NoError(t, err)
+		mockStorage.AssertExpectations(t)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("keeps the record when an object cannot be deleted", func(t *testing.T) {
+		service, mockRepo, mockStorage := setupDocumentService()
+		document := CreateTestDocument()
+		prefix := documentPrefix(testWorkspaceID, document.ID)
+
+		mockRepo.On("GetByID", mock.Anything, testWorkspaceID, document.ID).Return(document, nil)
+		mockStorage.On("List", mock.Anything, prefix).Return([]string{prefix + "v1"}, nil)
+		mockStorage.On("Delete", mock.Anything, prefix+"v1").Return(errors.New("storage unavailable"))
+
+		err := service.DeleteDocument(context.Background(), testWorkspaceID, document.ID.Hex())
+
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("keeps the document when a hook fails", func(t *testing.T) {
+		service, mockRepo, mockStorage := setupDocumentService()
+		document := CreateTestDocument()
+
+		var deleted string
+		service.OnDelete(func(ctx context.Context, workspaceID, documentID string) error {
+			deleted = documentID
+			return errors.New("extractions unavailable")
+		})
+		mockRepo.On("GetByID", mock.Anything, testWorkspaceID, document.ID).Return(document, nil)
+
+		err := service.DeleteDocument(context.Background(), testWorkspaceID, document.ID.Hex())
+
+		assert.Error(t, err)
+		assert.Equal(t, document.ID.Hex(), deleted)
+		mockStorage.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestDocumentService_OnVersion(t *testing.T) {
+	service, mockRepo, mockStorage := setupDocumentService()
+	document := CreateTestDocument()
+	replaced := *document
+	replaced.Version = 2
+
+	var versions []int
+	service.OnVersion(func(ctx context.Context, document *Document) error {
+		versions = append(versions, document.Version)
+		return errors.New("queue unavailable")
+	})
+
+	mockRepo.On("GetByID", mock.Anything, testWorkspaceID, document.ID).Return(document, nil)
+	mockStorage.On("Put", mock.Anything, mock.Anything, "hello", int64(5), "text/plain").Return(nil)
+	mockRepo.On("UpdateAtVersion", mock.Anything, testWorkspaceID, document.ID, 1, mock.Anything).Return(&replaced, nil)
+
+	_, err := service.ReplaceContent(context.Background(), testWorkspaceID, testAccountID, document.ID.Hex(), &UploadInput{
+		ContentType: "text/plain",
+		Size:        5,
+	}, strings.NewReader("hello"))
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{2}, versions)
+}
+
+func TestDocumentService_ListDocuments_CapsLimit(t *testing.T) {
+	service, mockRepo, _ := setupDocumentService()
+
+	mockRepo.On("List", mock.Anything, bson.M{"workspace_id": testWorkspaceID}, mongo.PaginationOptions{Page: 2, Limit: DocumentListMaxLimit}).
+		Return(&mongo.PaginatedResult[Document]{}, nil)
+
+	_, err := service.ListDocuments(context.Background(), testWorkspaceID, &DocumentListQuery{Page: 2, Limit: 500})
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,113 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v7"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/minio"
+)
+
+var errObjectNotFound = errors.New("object not found")
+
+// ObjectStorage is the part of the bucket the document module uses. Keys
+// are relative to the configured bucket.
+type ObjectStorage interface {
+	Put(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Stat(ctx context.Context, key string) (int64, error)
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]string, error)
+	PresignGet(ctx context.Context, key string, expires time.Duration) (string, error)
+	PresignPut(ctx context.Context, key string, expires time.Duration) (string, error)
+}
+
+type minioStorage struct {
+	minio minio.MinIOService
+}
+
+var _ ObjectStorage = (*minioStorage)(nil)
+
+func NewObjectStorage(minioService minio.MinIOService) ObjectStorage {
+	return &minioStorage{
+		minio: minioService,
+	}
+}
+
+func (s *minioStorage) Put(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error {
+	return s.minio.PutObject(ctx, key, reader, size, miniogo.PutObjectOptions{ContentType: contentType})
+}
+
+func (s *minioStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	object, err := s.minio.GetObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// GetObject is lazy; stat it so a missing key fails here rather than on
+	// the first read.
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		return nil, storageError(err)
+	}
+
+	return object, nil
+}
+
+func (s *minioStorage) Stat(ctx context.Context, key string) (int64, error) {
+	info, err := s.minio.GetObjectInfo(ctx, key)
+	if err != nil {
+		return 0, storageError(err)
+	}
+
+	return info.Size, nil
+}
+
+func (s *minioStorage) Delete(ctx context.Context, key string) error {
+	return s.minio.DeleteObject(ctx, key)
+}
+
+func (s *minioStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	objects, err := s.minio.ListObjects(ctx, prefix, true)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(objects))
+	for i, object := range objects {
+		keys[i] = object.Key
+	}
+
+	return keys, nil
+}
+
+func (s *minioStorage) PresignGet(ctx context.Context, key string, expires time.Duration) (string, error) {
+	u, err := s.minio.GeneratePresignedURL(ctx, key, expires, http.MethodGet)
+	if err != nil {
+		return "", err
+	}
+
+	return u.String(), nil
+}
+
+func (s *minioStorage) PresignPut(ctx context.Context, key string, expires time.Duration) (string, error) {
+	u, err := s.minio.GeneratePresignedURL(ctx, key, expires, http.MethodPut)
+	if err != nil {
+		return "", err
+	}
+
+	return u.String(), nil
+}
+
+func storageError(err error) error {
+	if miniogo.ToErrorResponse(err).StatusCode == http.StatusNotFound {
+		return errObjectNotFound
+	}
+
+	return fmt.Errorf("failed to stat object: %w", err)
+}
@@ -0,0 +1,232 @@
+package document
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+const (
+	testWorkspaceID = "64b7f0c2e4b0a1a2b3c4d500"
+	testAccountID   = "64b7f0c2e4b0a1a2b3c4d501"
+)
+
+type MockDocumentRepository struct {
+	mock.Mock
+}
+
+func (m *MockDocumentRepository) Create(ctx context.Context, document *Document) (*Document, error) {
+	args := m.Called(ctx, document)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Document), args.Error(1)
+}
+
+func (m *MockDocumentRepository) GetByID(ctx context.Context, workspaceID string, id primitive.ObjectID) (*Document, error) {
+	args := m.Called(ctx, workspaceID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Document), args.Error(1)
+}
+
+func (m *MockDocumentRepository) List(ctx context.Context, filter bson.M, pagination mongo.PaginationOptions) (*mongo.PaginatedResult[Document], error) {
+	args := m.Called(ctx, filter, pagination)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*mongo.PaginatedResult[Document]), args.Error(1)
+}
+
+func (m *MockDocumentRepository) Update(ctx context.Context, workspaceID string, id primitive.ObjectID, update bson.M) (*Document, error) {
+	args := m.Called(ctx, workspaceID, id, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Document), args.Error(1)
+}
+
+func (m *MockDocumentRepository) UpdateAtVersion(ctx context.Context, workspaceID string, id primitive.ObjectID, version int, update bson.M) (*Document, error) {
+	args := m.Called(ctx, workspaceID, id, version, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Document), args.Error(1)
+}
+
+func (m *MockDocumentRepository) Delete(ctx context.Context, workspaceID string, id primitive.ObjectID) error {
+	args := m.Called(ctx, workspaceID, id)
+	return args.Error(0)
+}
+
+func (m *MockDocumentRepository) DeleteByWorkspace(ctx context.Context, workspaceID string) error {
+	args := m.Called(ctx, workspaceID)
+	return args.Error(0)
+}
+
+type MockExtractionRepository struct {
+	mock.Mock
+}
+
+func (m *MockExtractionRepository) Create(ctx context.Context, extraction *Extraction) (*Extraction, error) {
+	args := m.Called(ctx, extraction)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Extraction), args.Error(1)
+}
+
+func (m *MockExtractionRepository) GetLatest(ctx context.Context, workspaceID, documentID string) (*Extraction, error) {
+	args := m.Called(ctx, workspaceID, documentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Extraction), args.Error(1)
+}
+
+func (m *MockExtractionRepository) GetVersion(ctx context.Context, workspaceID, documentID string, version int) (*Extraction, error) {
+	args := m.Called(ctx, workspaceID, documentID, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Extraction), args.Error(1)
+}
+
+func (m *MockExtractionRepository) Claim(ctx context.Context, now time.Time, lease time.Duration) (*Extraction, error) {
+	args := m.Called(ctx, now, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Extraction), args.Error(1)
+}
+
+func (m *MockExtractionRepository) Update(ctx context.Context, id primitive.ObjectID, update bson.M) (*Extraction, error) {
+	args := m.Called(ctx, id, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Extraction), args.Error(1)
+}
+
+func (m *MockExtractionRepository) UpdateAttempt(ctx context.Context, id primitive.ObjectID, attempts int, update bson.M) (*Extraction, error) {
+	args := m.Called(ctx, id, attempts, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Extraction), args.Error(1)
+}
+
+func (m *MockExtractionRepository) DeleteByDocument(ctx context.Context, workspaceID, documentID string) error {
+	args := m.Called(ctx, workspaceID, documentID)
+	return args.Error(0)
+}
+
+func (m *MockExtractionRepository) DeleteByWorkspace(ctx context.Context, workspaceID string) error {
+	args := m.Called(ctx, workspaceID)
+	return args.Error(0)
+}
+
+// MockObjectStorage reads the whole body passed to Put, so expectations match
+// on the stored content as a string.
+type MockObjectStorage struct {
+	mock.Mock
+}
+
+func (m *MockObjectStorage) Put(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	args := m.Called(ctx, key, string(data), size, contentType)
+	return args.Error(0)
+}
+
+func (m *MockObjectStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockObjectStorage) Stat(ctx context.Context, key string) (int64, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockObjectStorage) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockObjectStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	args := m.Called(ctx, prefix)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockObjectStorage) PresignGet(ctx context.Context, key string, expires time.Duration) (string, error) {
+	args := m.Called(ctx, key, expires)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockObjectStorage) PresignPut(ctx context.Context, key string, expires time.Duration) (string, error) {
+	args := m.Called(ctx, key, expires)
+	return args.String(0), args.Error(1)
+}
+
+// CreateTestDocument returns a ready document at version 1 in the test
+// workspace.
+func CreateTestDocument() *Document {
+	id := primitive.NewObjectID()
+	now := time.Now()
+
+	return &Document{
+		ID:          id,
+		WorkspaceID: testWorkspaceID,
+		OwnerID:     testAccountID,
+		Name:        "report.pdf",
+		Status:      DocumentStatusReady,
+		DocumentVersion: DocumentVersion{
+			Version:     1,
+			ObjectKey:   documentPrefix(testWorkspaceID, id) + "v1",
+			ContentType: "application/pdf",
+			Size:        5,
+			Checksum:    "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+			UploadedBy:  testAccountID,
+			UploadedAt:  now,
+		},
+		Versions:  []DocumentVersion{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// CreateTestExtraction returns an extraction of version 1 of document that a
+// worker has just claimed for the first time.
+func CreateTestExtraction(document *Document) *Extraction {
+	now := time.Now()
+
+	return &Extraction{
+		ID:            primitive.NewObjectID(),
+		WorkspaceID:   document.WorkspaceID,
+		DocumentID:    document.ID.Hex(),
+		Version:       1,
+		Status:        ExtractionStatusProcessing,
+		Attempts:      1,
+		NextAttemptAt: now,
+		LockedUntil:   now.Add(ExtractionLease),
+		StartedAt:     &now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
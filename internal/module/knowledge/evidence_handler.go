@@ -0,0 +1,65 @@
+package knowledge
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+type EvidenceHandler struct {
+	service EvidenceService
+}
+
+func NewEvidenceHandler(service EvidenceService) *EvidenceHandler {
+	return &EvidenceHandler{
+		service: service,
+	}
+}
+
+// GetEntityEvidence godoc
+// @Summary Show the evidence for an entity
+// @Description List the documents and users that support an entity, newest first, with their combined confidence.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Entity ID"
+// @Success 200 {object} map[string]interface{} "Evidence retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Entity not found"
+// @Router /workspaces/{workspaceId}/knowledge/entities/{id}/evidence [get]
+func (h *EvidenceHandler) GetEntityEvidence(c *fiber.Ctx) error {
+	evidence, err := h.service.GetEntityEvidence(c.Context(), workspaceIDFrom(c), c.Params("id"))
+	if err != nil {
+		return knowledgeError(c, "Failed to get evidence", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Evidence retrieved successfully",
+		"data":    evidence,
+	})
+}
+
+// GetRelationEvidence godoc
+// @Summary Show the evidence for a relation
+// @Description List the documents and users that support a relation, newest first, with their combined confidence.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Relation ID"
+// @Success 200 {object} map[string]interface{} "Evidence retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Relation not found"
+// @Router /workspaces/{workspaceId}/knowledge/relations/{id}/evidence [get]
+func (h *EvidenceHandler) GetRelationEvidence(c *fiber.Ctx) error {
+	evidence, err := h.service.GetRelationEvidence(c.Context(), workspaceIDFrom(c), c.Params("id"))
+	if err != nil {
+		return knowledgeError(c, "Failed to get evidence", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Evidence retrieved successfully",
+		"data":    evidence,
+	})
+}
@@ -0,0 +1,81 @@
+package knowledge
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const EvidenceCollectionName = "knowledge_evidence"
+
+const (
+	// MaxEvidencePerFact caps the evidence returned for one fact, newest
+	// first.
+	MaxEvidencePerFact = 1000
+
+	// userEvidenceConfidence is the confidence of facts stated by a user.
+	userEvidenceConfidence = 1.0
+)
+
+type FactKind string
+
+const (
+	FactKindEntity   FactKind = "entity"
+	FactKindRelation FactKind = "relation"
+)
+
+type EvidenceSource string
+
+const (
+	EvidenceSourceDocument EvidenceSource = "document"
+	EvidenceSourceUser     EvidenceSource = "user"
+)
+
+// Evidence is one reason to believe an entity or relation: a span of a
+// document found by an extractor and accepted by AccountID, or a user who
+// stated the fact. A fact found in several documents, or stated and also
+// extracted, has one evidence record per source.
+//
+// Relation evidence keeps the IDs of both entities, so that it can be
+// removed with the relation when either entity is deleted.
+type Evidence struct {
+	ID               primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	WorkspaceID      string             `json:"workspace_id" bson:"workspace_id"`
+	FactKind         FactKind           `json:"fact_kind" bson:"fact_kind"`
+	FactID           string             `json:"fact_id" bson:"fact_id"`
+	SourceEntityID   string             `json:"-" bson:"source_entity_id,omitempty"`
+	TargetEntityID   string             `json:"-" bson:"target_entity_id,omitempty"`
+	Source           EvidenceSource     `json:"source" bson:"source"`
+	DocumentID       string             `json:"document_id,omitempty" bson:"document_id,omitempty"`
+	DocumentVersion  int                `json:"document_version,omitempty" bson:"document_version,omitempty"`
+	Spans            []Span             `json:"spans,omitempty" bson:"spans,omitempty"`
+	Snippet          string             `json:"snippet,omitempty" bson:"snippet,omitempty"`
+	Extractor        string             `json:"extractor,omitempty" bson:"extractor,omitempty"`
+	ExtractorVersion string             `json:"extractor_version,omitempty" bson:"extractor_version,omitempty"`
+	CandidateID      string             `json:"candidate_id,omitempty" bson:"candidate_id,omitempty"`
+	AccountID        string             `json:"account_id" bson:"account_id"`
+	Confidence       float64            `json:"confidence" bson:"confidence"`
+	CreatedAt        time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// FactEvidence answers why a fact is believed. Confidence combines the
+// evidence as independent sources: the fact is wrong only if every source is
+// wrong, so each source adds to the confidence of the others.
+type FactEvidence struct {
+	FactKind   FactKind    `json:"fact_kind"`
+	FactID     string      `json:"fact_id"`
+	Confidence float64     `json:"confidence"`
+	Documents  int         `json:"documents"`
+	Users      int         `json:"users"`
+	Evidence   []*Evidence `json:"evidence"`
+}
+
+// Retraction reports what deleting a document removed from the graph. Facts
+// with evidence left keep it; those whose graph provenance pointed at the
+// document point at their oldest remaining document instead.
+type Retraction struct {
+	DocumentID         string   `json:"document_id"`
+	EvidenceRemoved    int      `json:"evidence_removed"`
+	RetractedEntities  []string `json:"retracted_entities"`
+	RetractedRelations []string `json:"retracted_relations"`
+}
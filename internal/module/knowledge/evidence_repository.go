@@ -0,0 +1,171 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+type EvidenceRepository interface {
+	Create(ctx context.Context, evidence *Evidence) (*Evidence, error)
+	ListByFact(ctx context.Context, workspaceID string, kind FactKind, factID string) ([]*Evidence, error)
+	ListByDocument(ctx context.Context, workspaceID, documentID string) ([]*Evidence, error)
+	IDsByFact(ctx context.Context, workspaceID string, kind FactKind, factID string) ([]primitive.ObjectID, error)
+	Reassign(ctx context.Context, workspaceID string, ids []primitive.ObjectID, kind FactKind, factID, sourceEntityID, targetEntityID string) error
+	DeleteByDocument(ctx context.Context, workspaceID, documentID string) (int64, error)
+	DeleteByFact(ctx context.Context, workspaceID string, kind FactKind, factID string) error
+	DeleteByEntity(ctx context.Context, workspaceID, entityID string) error
+	DeleteByWorkspace(ctx context.Context, workspaceID string) error
+}
+
+type evidenceRepository struct {
+	repo mongo.Repository[Evidence]
+}
+
+var _ EvidenceRepository = (*evidenceRepository)(nil)
+
+func NewEvidenceRepository(mongoService *mongo.MongoService) EvidenceRepository {
+	return &evidenceRepository{
+		repo: mongo.NewRepository[Evidence](mongoService, EvidenceCollectionName),
+	}
+}
+
+func (r *evidenceRepository) Create(ctx context.Context, evidence *Evidence) (*Evidence, error) {
+	result, err := r.repo.Create(ctx, *evidence)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create evidence: %w", err)
+	}
+
+	return result, nil
+}
+
+// ListByFact returns up to MaxEvidencePerFact records, newest first.
+func (r *evidenceRepository) ListByFact(ctx context.Context, workspaceID string, kind FactKind, factID string) ([]*Evidence, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(MaxEvidencePerFact)
+
+	results, err := r.repo.Find(ctx, bson.M{
+		"workspace_id": workspaceID,
+		"fact_kind":    kind,
+		"fact_id":      factID,
+	}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list evidence: %w", err)
+	}
+
+	return evidencePointers(results), nil
+}
+
+func (r *evidenceRepository) ListByDocument(ctx context.Context, workspaceID, documentID string) ([]*Evidence, error) {
+	results, err := r.repo.Find(ctx, bson.M{"workspace_id": workspaceID, "document_id": documentID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list evidence: %w", err)
+	}
+
+	return evidencePointers(results), nil
+}
+
+// IDsByFact returns the IDs of every evidence record of a fact.
+func (r *evidenceRepository) IDsByFact(ctx context.Context, workspaceID string, kind FactKind, factID string) ([]primitive.ObjectID, error) {
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+
+	results, err := r.repo.Find(ctx, bson.M{
+		"workspace_id": workspaceID,
+		"fact_kind":    kind,
+		"fact_id":      factID,
+	}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list evidence: %w", err)
+	}
+
+	ids := make([]primitive.ObjectID, len(results))
+	for i := range results {
+		ids[i] = results[i].ID
+	}
+	return ids, nil
+}
+
+// Reassign points evidence records at another fact, when entities are
+// merged or a merge is undone.
+func (r *evidenceRepository) Reassign(ctx context.Context, workspaceID string, ids []primitive.ObjectID, kind FactKind, factID, sourceEntityID, targetEntityID string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := r.repo.UpdateMany(ctx,
+		bson.M{"workspace_id": workspaceID, "_id": bson.M{"$in": ids}},
+		bson.M{"$set": bson.M{
+			"fact_kind":        kind,
+			"fact_id":          factID,
+			"source_entity_id": sourceEntityID,
+			"target_entity_id": targetEntityID,
+		}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to move evidence: %w", err)
+	}
+
+	return nil
+}
+
+func (r *evidenceRepository) DeleteByDocument(ctx context.Context, workspaceID, documentID string) (int64, error) {
+	deleted, err := r.repo.DeleteMany(ctx, bson.M{"workspace_id": workspaceID, "document_id": documentID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete evidence: %w", err)
+	}
+
+	return deleted, nil
+}
+
+func (r *evidenceRepository) DeleteByFact(ctx context.Context, workspaceID string, kind FactKind, factID string) error {
+	_, err := r.repo.DeleteMany(ctx, bson.M{
+		"workspace_id": workspaceID,
+		"fact_kind":    kind,
+		"fact_id":      factID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete evidence: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteByEntity removes the evidence of an entity and of the relations
+// attached to it, which are deleted with it.
+func (r *evidenceRepository) DeleteByEntity(ctx context.Context, workspaceID, entityID string) error {
+	_, err := r.repo.DeleteMany(ctx, bson.M{
+		"workspace_id": workspaceID,
+		"$or": bson.A{
+			bson.M{"fact_kind": FactKindEntity, "fact_id": entityID},
+			bson.M{"source_entity_id": entityID},
+			bson.M{"target_entity_id": entityID},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete evidence: %w", err)
+	}
+
+	return nil
+}
+
+func (r *evidenceRepository) DeleteByWorkspace(ctx context.Context, workspaceID string) error {
+	if _, err := r.repo.DeleteMany(ctx, bson.M{"workspace_id": workspaceID}); err != nil {
+		return fmt.Errorf("failed to delete evidence: %w", err)
+	}
+
+	return nil
+}
+
+func evidencePointers(results []Evidence) []*Evidence {
+	evidence := make([]*Evidence, len(results))
+	for i := range results {
+		evidence[i] = &results[i]
+	}
+	return evidence
+}
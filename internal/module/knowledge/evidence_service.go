@@ -0,0 +1,219 @@
+package knowledge
+
+import (
+	"context"
+	"sort"
+)
+
+// EvidenceService answers why the graph holds a fact, and retracts the facts
+// that lose all of their evidence when a document is deleted.
+type EvidenceService interface {
+	GetEntityEvidence(ctx context.Context, workspaceID, id string) (*FactEvidence, error)
+	GetRelationEvidence(ctx context.Context, workspaceID, id string) (*FactEvidence, error)
+	RetractDocument(ctx context.Context, workspaceID, documentID string) (*Retraction, error)
+	DeleteWorkspace(ctx context.Context, workspaceID string) error
+}
+
+type evidenceService struct {
+	evidence  EvidenceRepository
+	knowledge *knowledgeService
+}
+
+func newEvidenceService(evidence EvidenceRepository, knowledge *knowledgeService) *evidenceService {
+	return &evidenceService{
+		evidence:  evidence,
+		knowledge: knowledge,
+	}
+}
+
+func (s *evidenceService) GetEntityEvidence(ctx context.Context, workspaceID, id string) (*FactEvidence, error) {
+	if _, err := s.knowledge.GetEntity(ctx, workspaceID, id); err != nil {
+		return nil, err
+	}
+
+	return s.factEvidence(ctx, workspaceID, FactKindEntity, id)
+}
+
+func (s *evidenceService) GetRelationEvidence(ctx context.Context, workspaceID, id string) (*FactEvidence, error) {
+	if _, err := s.knowledge.GetRelation(ctx, workspaceID, id); err != nil {
+		return nil, err
+	}
+
+	return s.factEvidence(ctx, workspaceID, FactKindRelation, id)
+}
+
+// RetractDocument removes the evidence of a deleted document. Relations left
+// without evidence are deleted first, then entities left without evidence
+// and without relations; an entity still related to other facts stays, as
+// those facts vouch for it. Facts that keep evidence but whose provenance
+// pointed at the document are pointed at their oldest remaining document.
+//
+// The evidence of the document is deleted last, so a retraction that fails
+// part way can be run again.
+func (s *evidenceService) RetractDocument(ctx context.Context, workspaceID, documentID string) (*Retraction, error) {
+	records, err := s.evidence.ListByDocument(ctx, workspaceID, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	retraction := &Retraction{
+		DocumentID:         documentID,
+		RetractedEntities:  []string{},
+		RetractedRelations: []string{},
+	}
+
+	facts := map[FactKind][]string{}
+	seen := map[string]bool{}
+	for _, record := range records {
+		key := string(record.FactKind) + ":" + record.FactID
+		if !seen[key] {
+			seen[key] = true
+			facts[record.FactKind] = append(facts[record.FactKind], record.FactID)
+		}
+	}
+
+	for _, kind := range []FactKind{FactKindRelation, FactKindEntity} {
+		ids := facts[kind]
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			retracted, err := s.retractFact(ctx, workspaceID, documentID, kind, id)
+			if err != nil {
+				return nil, err
+			}
+			if !retracted {
+				continue
+			}
+			if kind == FactKindRelation {
+				retraction.RetractedRelations = append(retraction.RetractedRelations, id)
+			} else {
+				retraction.RetractedEntities = append(retraction.RetractedEntities, id)
+			}
+		}
+	}
+
+	removed, err := s.evidence.DeleteByDocument(ctx, workspaceID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	retraction.EvidenceRemoved = int(removed)
+
+	return retraction, nil
+}
+
+func (s *evidenceService) DeleteWorkspace(ctx context.Context, workspaceID string) error {
+	return s.evidence.DeleteByWorkspace(ctx, workspaceID)
+}
+
+// retractFact deletes a fact with no evidence besides the document, and
+// reports whether it did.
+func (s *evidenceService) retractFact(ctx context.Context, workspaceID, documentID string, kind FactKind, id string) (bool, error) {
+	records, err := s.evidence.ListByFact(ctx, workspaceID, kind, id)
+	if err != nil {
+		return false, err
+	}
+
+	var remaining []*Evidence
+	for _, record := range records {
+		if record.DocumentID != documentID {
+			remaining = append(remaining, record)
+		}
+	}
+
+	if len(remaining) == 0 {
+		if kind == FactKindRelation {
+			return s.knowledge.repository.DeleteRelation(ctx, workspaceID, id)
+		}
+
+		degree, err := s.knowledge.repository.Degree(ctx, workspaceID, id)
+		if err != nil {
+			return false, err
+		}
+		if degree == 0 {
+			return s.knowledge.repository.DeleteEntity(ctx, workspaceID, id)
+		}
+	}
+
+	return false, s.repointProvenance(ctx, workspaceID, documentID, kind, id, remaining)
+}
+
+// repointProvenance moves the provenance of a fact off the deleted document,
+// to the oldest document that still supports it, or removes it.
+func (s *evidenceService) repointProvenance(ctx context.Context, workspaceID, documentID string, kind FactKind, id string, remaining []*Evidence) error {
+	var provenance *Provenance
+	if kind == FactKindRelation {
+		relation, err := s.knowledge.repository.GetRelation(ctx, workspaceID, id)
+		if err != nil || relation == nil {
+			return err
+		}
+		provenance = relation.Provenance
+	} else {
+		entity, err := s.knowledge.repository.GetEntity(ctx, workspaceID, id)
+		if err != nil || entity == nil {
+			return err
+		}
+		provenance = entity.Provenance
+	}
+	if provenance == nil || provenance.DocumentID != documentID {
+		return nil
+	}
+
+	// remaining is newest first.
+	var replacement *Provenance
+	for i := len(remaining) - 1; i >= 0; i-- {
+		if remaining[i].Source == EvidenceSourceDocument {
+			replacement = evidenceProvenance(remaining[i])
+			break
+		}
+	}
+
+	return s.knowledge.repository.SetProvenance(ctx, workspaceID, kind, id, replacement)
+}
+
+func (s *evidenceService) factEvidence(ctx context.Context, workspaceID string, kind FactKind, id string) (*FactEvidence, error) {
+	records, err := s.evidence.ListByFact(ctx, workspaceID, kind, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FactEvidence{
+		FactKind: kind,
+		FactID:   id,
+		Evidence: records,
+	}
+
+	doubt := 1.0
+	documents := map[string]bool{}
+	users := map[string]bool{}
+	for _, record := range records {
+		doubt *= 1 - record.Confidence
+		if record.Source == EvidenceSourceDocument {
+			documents[record.DocumentID] = true
+		} else {
+			users[record.AccountID] = true
+		}
+	}
+	if len(records) > 0 {
+		result.Confidence = 1 - doubt
+	}
+	result.Documents = len(documents)
+	result.Users = len(users)
+
+	return result, nil
+}
+
+// evidenceProvenance points at the first span of document evidence.
+func evidenceProvenance(evidence *Evidence) *Provenance {
+	provenance := &Provenance{
+		DocumentID:       evidence.DocumentID,
+		DocumentVersion:  int64(evidence.DocumentVersion),
+		Extractor:        evidence.Extractor,
+		ExtractorVersion: evidence.ExtractorVersion,
+	}
+	if len(evidence.Spans) > 0 {
+		provenance.Start = int64(evidence.Spans[0].Start)
+		provenance.End = int64(evidence.Spans[0].End)
+	}
+
+	return provenance
+}
@@ -0,0 +1,121 @@
+package knowledge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const otherDocumentID = "64b7f0c2e4b0a1a2b3c4d5e9"
+
+func setupEvidenceService() (*evidenceService, *MockEvidenceRepository, *MockKnowledgeRepository) {
+	knowledge, mockRepo := setupKnowledgeService()
+	mockEvidence := &MockEvidenceRepository{}
+	knowledge.evidence = mockEvidence
+	return newEvidenceService(mockEvidence, knowledge), mockEvidence, mockRepo
+}
+
+func TestEvidenceService_GetRelationEvidence(t *testing.T) {
+	service, mockEvidence, mockRepo := setupEvidenceService()
+
+	mockRepo.On("GetRelation", mock.Anything, testWorkspaceID, "5:test:1").Return(&Relation{ID: "5:test:1", Type: "WORKS_AT"}, nil)
+	mockEvidence.On("ListByFact", mock.Anything, testWorkspaceID, FactKindRelation, "5:test:1").Return([]*Evidence{
+		CreateTestEvidence(),
+		CreateTestEvidence(func(e *Evidence) { e.DocumentID = otherDocumentID; e.Confidence = 0.5 }),
+		CreateTestEvidence(func(e *Evidence) { e.DocumentID = otherDocumentID; e.Confidence = 0.5 }),
+	}, nil)
+
+	evidence, err := service.GetRelationEvidence(context.Background(), testWorkspaceID, "5:test:1")
+	require.NoError(t, err)
+	assert.Len(t, evidence.Evidence, 3)
+	assert.Equal(t, 2, evidence.Documents)
+	assert.Equal(t, 0, evidence.Users)
+	assert.InDelta(t, 0.9, evidence.Confidence, 1e-9)
+}
+
+func TestEvidenceService_GetEntityEvidence(t *testing.T) {
+	t.Run("stated by a user", func(t *testing.T) {
+		service, mockEvidence, mockRepo := setupEvidenceService()
+
+		mockRepo.On("GetEntity", mock.Anything, testWorkspaceID, "4:test:1").Return(CreateTestEntity(), nil)
+		mockEvidence.On("ListByFact", mock.Anything, testWorkspaceID, FactKindEntity, "4:test:1").Return([]*Evidence{
+			CreateTestEvidence(func(e *Evidence) { e.FactKind = FactKindEntity; e.FactID = "4:test:1" }),
+			{FactKind: FactKindEntity, FactID: "4:test:1", Source: EvidenceSourceUser, AccountID: testAccountID, Confidence: userEvidenceConfidence},
+		}, nil)
+
+		evidence, err := service.GetEntityEvidence(context.Background(), testWorkspaceID, "4:test:1")
+		require.NoError(t, err)
+		assert.Equal(t, 1, evidence.Documents)
+		assert.Equal(t, 1, evidence.Users)
+		assert.Equal(t, 1.0, evidence.Confidence)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		service, mockEvidence, mockRepo := setupEvidenceService()
+
+		mockRepo.On("GetEntity", mock.Anything, testWorkspaceID, "4:test:404").Return(nil, nil)
+
+		_, err := service.GetEntityEvidence(context.Background(), testWorkspaceID, "4:test:404")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+		mockEvidence.AssertNotCalled(t, "ListByFact", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestEvidenceService_RetractDocument(t *testing.T) {
+	service, mockEvidence, mockRepo := setupEvidenceService()
+
+	fromDocument := func(kind FactKind, id string) *Evidence {
+		return CreateTestEvidence(func(e *Evidence) { e.FactKind = kind; e.FactID = id })
+	}
+	provenance := &Provenance{DocumentID: testDocumentID, DocumentVersion: 1}
+
+	// Only this document supports 5:test:1, 4:test:1 and 4:test:2; 5:test:2 is
+	// also found in another document. 4:test:2 is still related to 4:test:3.
+	mockEvidence.On("ListByDocument", mock.Anything, testWorkspaceID, testDocumentID).Return([]*Evidence{
+		fromDocument(FactKindEntity, "4:test:1"),
+		fromDocument(FactKindEntity, "4:test:2"),
+		fromDocument(FactKindRelation, "5:test:1"),
+		fromDocument(FactKindRelation, "5:test:2"),
+		fromDocument(FactKindRelation, "5:test:2"),
+	}, nil)
+
+	mockEvidence.On("ListByFact", mock.Anything, testWorkspaceID, FactKindRelation, "5:test:1").
+		Return([]*Evidence{fromDocument(FactKindRelation, "5:test:1")}, nil)
+	mockRepo.On("DeleteRelation", mock.Anything, testWorkspaceID, "5:test:1").Return(true, nil)
+
+	other := CreateTestEvidence(func(e *Evidence) { e.FactID = "5:test:2"; e.DocumentID = otherDocumentID; e.DocumentVersion = 4 })
+	mockEvidence.On("ListByFact", mock.Anything, testWorkspaceID, FactKindRelation, "5:test:2").
+		Return([]*Evidence{fromDocument(FactKindRelation, "5:test:2"), other}, nil)
+	mockRepo.On("GetRelation", mock.Anything, testWorkspaceID, "5:test:2").
+		Return(&Relation{ID: "5:test:2", Provenance: provenance}, nil)
+	mockRepo.On("SetProvenance", mock.Anything, testWorkspaceID, FactKindRelation, "5:test:2", mock.MatchedBy(func(p *Provenance) bool {
+		return p != nil && p.DocumentID == otherDocumentID && p.DocumentVersion == 4 && p.End == 26
+	})).Return(nil)
+
+	mockEvidence.On("ListByFact", mock.Anything, testWorkspaceID, FactKindEntity, "4:test:1").
+		Return([]*Evidence{fromDocument(FactKindEntity, "4:test:1")}, nil)
+	mockRepo.On("Degree", mock.Anything, testWorkspaceID, "4:test:1").Return(int64(0), nil)
+	mockRepo.On("DeleteEntity", mock.Anything, testWorkspaceID, "4:test:1").Return(true, nil)
+
+	mockEvidence.On("ListByFact", mock.Anything, testWorkspaceID, FactKindEntity, "4:test:2").
+		Return([]*Evidence{fromDocument(FactKindEntity, "4:test:2")}, nil)
+	mockRepo.On("Degree", mock.Anything, testWorkspaceID, "4:test:2").Return(int64(1), nil)
+	mockRepo.On("GetEntity", mock.Anything, testWorkspaceID, "4:test:2").
+		Return(CreateTestEntity(func(e *Entity) { e.ID = "4:test:2"; e.Provenance = provenance }), nil)
+	mockRepo.On("SetProvenance", mock.Anything, testWorkspaceID, FactKindEntity, "4:test:2", (*Provenance)(nil)).Return(nil)
+
+	mockEvidence.On("DeleteByDocument", mock.Anything, testWorkspaceID, testDocumentID).Return(int64(5), nil)
+
+	retraction, err := service.RetractDocument(context.Background(), testWorkspaceID, testDocumentID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"5:test:1"}, retraction.RetractedRelations)
+	assert.Equal(t, []string{"4:test:1"}, retraction.RetractedEntities)
+	assert.Equal(t, 5, retraction.EvidenceRemoved)
+	mockRepo.AssertExpectations(t)
+	mockEvidence.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "DeleteRelation", mock.Anything, testWorkspaceID, "5:test:2")
+}
@@ -0,0 +1,288 @@
+package knowledge
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/binding"
+)
+
+type ExtractionHandler struct {
+	service ExtractionService
+}
+
+func NewExtractionHandler(service ExtractionService) *ExtractionHandler {
+	return &ExtractionHandler{
+		service: service,
+	}
+}
+
+// GetRules godoc
+// @Summary Get the extraction rules
+// @Description Get the gazetteers, patterns and co-occurrence rules used to extract entities and relations from documents.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Success 200 {object} map[string]interface{} "Extraction rules retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Extraction rules not found"
+// @Router /workspaces/{workspaceId}/knowledge/extraction/rules [get]
+func (h *ExtractionHandler) GetRules(c *fiber.Ctx) error {
+	rules, err := h.service.GetRules(c.Context(), workspaceIDFrom(c))
+	if err != nil {
+		return knowledgeError(c, "Failed to get extraction rules", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Extraction rules retrieved successfully",
+		"data":    rules,
+	})
+}
+
+// UpdateRules godoc
+// @Summary Publish new extraction rules
+// @Description Store a new version of the extraction rules. Patterns use RE2 syntax. Requires the admin role.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param request body UpdateRulesRequest true "Gazetteers, patterns and co-occurrence rules"
+// @Success 201 {object} map[string]interface{} "Extraction rules updated successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Router /workspaces/{workspaceId}/knowledge/extraction/rules [put]
+func (h *ExtractionHandler) UpdateRules(c *fiber.Ctx) error {
+	var req UpdateRulesRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	rules, err := h.service.UpdateRules(c.Context(), workspaceIDFrom(c), accountIDFrom(c), &req)
+	if err != nil {
+		return knowledgeError(c, "Failed to update extraction rules", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Extraction rules updated successfully",
+		"data":    rules,
+	})
+}
+
+// ListExtractors godoc
+// @Summary List the extractors
+// @Description List the built-in rules and the external extraction services a workspace can choose from.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Success 200 {object} map[string]interface{} "Extractors retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /workspaces/{workspaceId}/knowledge/extraction/extractors [get]
+func (h *ExtractionHandler) ListExtractors(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"message": "Extractors retrieved successfully",
+		"data":    h.service.ListExtractors(),
+	})
+}
+
+// GetSettings godoc
+// @Summary Get the extraction settings
+// @Description Get the extractor the workspace uses when a run does not name one.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Success 200 {object} map[string]interface{} "Extraction settings retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /workspaces/{workspaceId}/knowledge/extraction/settings [get]
+func (h *ExtractionHandler) GetSettings(c *fiber.Ctx) error {
+	settings, err := h.service.GetSettings(c.Context(), workspaceIDFrom(c))
+	if err != nil {
+		return knowledgeError(c, "Failed to get extraction settings", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Extraction settings retrieved successfully",
+		"data":    settings,
+	})
+}
+
+// UpdateSettings godoc
+// @Summary Choose the extractor
+// @Description Set the extractor the workspace uses when a run does not name one. Requires the admin role.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param request body UpdateExtractionSettingsRequest true "Extractor name"
+// @Success 200 {object} map[string]interface{} "Extraction settings updated successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Router /workspaces/{workspaceId}/knowledge/extraction/settings [put]
+func (h *ExtractionHandler) UpdateSettings(c *fiber.Ctx) error {
+	var req UpdateExtractionSettingsRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	settings, err := h.service.UpdateSettings(c.Context(), workspaceIDFrom(c), accountIDFrom(c), &req)
+	if err != nil {
+		return knowledgeError(c, "Failed to update extraction settings", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Extraction settings updated successfully",
+		"data":    settings,
+	})
+}
+
+// ExtractDocument godoc
+// @Summary Extract facts from a document
+// @Description Run an extractor over the extracted text of a document and queue the entities and relations found for review. Without an extractor in the request, the one chosen in the workspace settings is used. Pending candidates of earlier runs on the document are replaced.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param request body ExtractDocumentRequest true "Document, version and extractor"
+// @Success 201 {object} map[string]interface{} "Document extracted successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Extraction rules or document text not found"
+// @Failure 502 {object} map[string]interface{} "Extractor unavailable"
+// @Router /workspaces/{workspaceId}/knowledge/extraction/runs [post]
+func (h *ExtractionHandler) ExtractDocument(c *fiber.Ctx) error {
+	var req ExtractDocumentRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	run, err := h.service.ExtractDocument(c.Context(), workspaceIDFrom(c), &req)
+	if err != nil {
+		return knowledgeError(c, "Failed to extract document", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Document extracted successfully",
+		"data":    run,
+	})
+}
+
+// ListCandidates godoc
+// @Summary List candidate facts
+// @Description Page through the review queue of extracted entities and relations, most confident first.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param status query string false "Filter by status" Enums(pending, accepted, rejected)
+// @Param kind query string false "Filter by kind" Enums(entity, relation)
+// @Param document_id query string false "Filter by document"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(20)
+// @Success 200 {object} map[string]interface{} "Candidates retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /workspaces/{workspaceId}/knowledge/candidates [get]
+func (h *ExtractionHandler) ListCandidates(c *fiber.Ctx) error {
+	var query CandidateListQuery
+	if err := binding.Query(c, &query); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	candidates, err := h.service.ListCandidates(c.Context(), workspaceIDFrom(c), &query)
+	if err != nil {
+		return knowledgeError(c, "Failed to list candidates", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Candidates retrieved successfully",
+		"data":    candidates,
+	})
+}
+
+// GetCandidate godoc
+// @Summary Get a candidate fact
+// @Description Get an extracted entity or relation with its mentions in the document.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Candidate ID"
+// @Success 200 {object} map[string]interface{} "Candidate retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Candidate not found"
+// @Router /workspaces/{workspaceId}/knowledge/candidates/{id} [get]
+func (h *ExtractionHandler) GetCandidate(c *fiber.Ctx) error {
+	candidate, err := h.service.GetCandidate(c.Context(), workspaceIDFrom(c), c.Params("id"))
+	if err != nil {
+		return knowledgeError(c, "Failed to get candidate", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Candidate retrieved successfully",
+		"data":    candidate,
+	})
+}
+
+// AcceptCandidate godoc
+// @Summary Accept a candidate fact
+// @Description Write the candidate to the graph with its provenance, reusing an existing entity of the same type and name. Accepting a relation also accepts its pending entities.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Candidate ID"
+// @Success 200 {object} map[string]interface{} "Candidate accepted successfully"
+// @Failure 400 {object} map[string]interface{} "Candidate violates the schema"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Candidate not found"
+// @Failure 409 {object} map[string]interface{} "Candidate already reviewed"
+// @Router /workspaces/{workspaceId}/knowledge/candidates/{id}/accept [post]
+func (h *ExtractionHandler) AcceptCandidate(c *fiber.Ctx) error {
+	candidate, err := h.service.AcceptCandidate(c.Context(), workspaceIDFrom(c), accountIDFrom(c), c.Params("id"))
+	if err != nil {
+		return knowledgeError(c, "Failed to accept candidate", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Candidate accepted successfully",
+		"data":    candidate,
+	})
+}
+
+// RejectCandidate godoc
+// @Summary Reject a candidate fact
+// @Description Keep the candidate out of the graph. Rejecting an entity also rejects the pending relations involving it, and later runs do not propose them again.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Candidate ID"
+// @Success 200 {object} map[string]interface{} "Candidate rejected successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Candidate not found"
+// @Failure 409 {object} map[string]interface{} "Candidate already reviewed"
+// @Router /workspaces/{workspaceId}/knowledge/candidates/{id}/reject [post]
+func (h *ExtractionHandler) RejectCandidate(c *fiber.Ctx) error {
+	candidate, err := h.service.RejectCandidate(c.Context(), workspaceIDFrom(c), accountIDFrom(c), c.Params("id"))
+	if err != nil {
+		return knowledgeError(c, "Failed to reject candidate", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Candidate rejected successfully",
+		"data":    candidate,
+	})
+}
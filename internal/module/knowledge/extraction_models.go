@@ -0,0 +1,211 @@
+package knowledge
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	RuleSetCollectionName            = "knowledge_extraction_rules"
+	CandidateCollectionName          = "knowledge_candidates"
+	ExtractionSettingsCollectionName = "knowledge_extraction_settings"
+)
+
+const (
+	// RuleExtractor names the built-in extractor in provenance, and
+	// RuleExtractorVersion changes whenever its matching changes.
+	RuleExtractor        = "rules"
+	RuleExtractorVersion = "1.0.0"
+
+	MaxGazetteers         = 50
+	MaxGazetteerEntries   = 10000
+	MaxPatternRules       = 100
+	MaxPatternLength      = 1000
+	MaxCooccurrenceRules  = 100
+	MaxCandidatesPerRun   = 5000
+	CandidateSnippetRunes = 60
+
+	CandidateListDefaultLimit = 20
+	CandidateListMaxLimit     = 100
+
+	defaultGazetteerConfidence    = 1.0
+	defaultPatternConfidence      = 0.8
+	defaultCooccurrenceConfidence = 0.5
+)
+
+const (
+	ScopeSentence  = "sentence"
+	ScopeParagraph = "paragraph"
+)
+
+// GazetteerEntry is one known entity. A mention of the name or of any alias
+// becomes a candidate entity with Name as its name.
+type GazetteerEntry struct {
+	Name    string   `json:"name" bson:"name" validate:"required,max=200"`
+	Aliases []string `json:"aliases,omitempty" bson:"aliases,omitempty" validate:"dive,max=200"`
+}
+
+// Gazetteer lists known entities of one type. Matches are case-insensitive
+// unless CaseSensitive is set and never start or end inside a word.
+type Gazetteer struct {
+	EntityType    string           `json:"entity_type" bson:"entity_type" validate:"required,max=64"`
+	CaseSensitive bool             `json:"case_sensitive" bson:"case_sensitive"`
+	Confidence    float64          `json:"confidence" bson:"confidence" validate:"min=0,max=1"`
+	Entries       []GazetteerEntry `json:"entries" bson:"entries" validate:"required,dive"`
+}
+
+// PatternRule finds entities with a regular expression in RE2 syntax. Group
+// selects the capture group holding the name; 0 is the whole match.
+type PatternRule struct {
+	Name       string  `json:"name" bson:"name" validate:"required,max=64"`
+	EntityType string  `json:"entity_type" bson:"entity_type" validate:"required,max=64"`
+	Expression string  `json:"expression" bson:"expression" validate:"required"`
+	Group      int     `json:"group" bson:"group" validate:"min=0"`
+	Confidence float64 `json:"confidence" bson:"confidence" validate:"min=0,max=1"`
+}
+
+// CooccurrenceRule proposes a relation between two entities of the given
+// types found in the same sentence or paragraph. With Keywords set, one of
+// them must appear between the two mentions.
+type CooccurrenceRule struct {
+	RelationType string   `json:"relation_type" bson:"relation_type" validate:"required,max=64"`
+	SourceType   string   `json:"source_type" bson:"source_type" validate:"required,max=64"`
+	TargetType   string   `json:"target_type" bson:"target_type" validate:"required,max=64"`
+	Scope        string   `json:"scope" bson:"scope" validate:"omitempty,oneof=sentence paragraph"`
+	Keywords     []string `json:"keywords,omitempty" bson:"keywords,omitempty" validate:"dive,max=100"`
+	Confidence   float64  `json:"confidence" bson:"confidence" validate:"min=0,max=1"`
+}
+
+// RuleSet is one version of the extraction rules of a workspace. Like
+// schemas, versions are never changed once stored and the highest one is
+// used.
+type RuleSet struct {
+	ID            primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	WorkspaceID   string             `json:"workspace_id" bson:"workspace_id"`
+	Version       int                `json:"version" bson:"version"`
+	Gazetteers    []Gazetteer        `json:"gazetteers" bson:"gazetteers"`
+	Patterns      []PatternRule      `json:"patterns" bson:"patterns"`
+	Cooccurrences []CooccurrenceRule `json:"cooccurrences" bson:"cooccurrences"`
+	CreatedBy     string             `json:"created_by" bson:"created_by"`
+	CreatedAt     time.Time          `json:"created_at" bson:"created_at"`
+}
+
+type UpdateRulesRequest struct {
+	Gazetteers    []Gazetteer        `json:"gazetteers" validate:"dive"`
+	Patterns      []PatternRule      `json:"patterns" validate:"dive"`
+	Cooccurrences []CooccurrenceRule `json:"cooccurrences" validate:"dive"`
+}
+
+type CandidateKind string
+
+const (
+	CandidateKindEntity   CandidateKind = "entity"
+	CandidateKindRelation CandidateKind = "relation"
+)
+
+type CandidateStatus string
+
+const (
+	CandidateStatusPending  CandidateStatus = "pending"
+	CandidateStatusAccepted CandidateStatus = "accepted"
+	CandidateStatusRejected CandidateStatus = "rejected"
+)
+
+// Span is a range of characters in the extracted text of a document. End is
+// exclusive.
+type Span struct {
+	Start int `json:"start" bson:"start"`
+	End   int `json:"end" bson:"end"`
+}
+
+// Candidate is an entity or relation found in a document and waiting for an
+// editor to accept or reject it. Accepting writes it to the graph; FactID is
+// then the ID of the entity or relation it became. Relation candidates refer
+// to the entity candidates at both ends.
+type Candidate struct {
+	ID                primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	WorkspaceID       string             `json:"workspace_id" bson:"workspace_id"`
+	DocumentID        string             `json:"document_id" bson:"document_id"`
+	DocumentVersion   int                `json:"document_version" bson:"document_version"`
+	Kind              CandidateKind      `json:"kind" bson:"kind"`
+	Status            CandidateStatus    `json:"status" bson:"status"`
+	Key               string             `json:"-" bson:"key"`
+	Type              string             `json:"type" bson:"type"`
+	Name              string             `json:"name,omitempty" bson:"name,omitempty"`
+	SourceCandidateID string             `json:"source_candidate_id,omitempty" bson:"source_candidate_id,omitempty"`
+	TargetCandidateID string             `json:"target_candidate_id,omitempty" bson:"target_candidate_id,omitempty"`
+	SourceName        string             `json:"source_name,omitempty" bson:"source_name,omitempty"`
+	TargetName        string             `json:"target_name,omitempty" bson:"target_name,omitempty"`
+	Mentions          []Span             `json:"mentions" bson:"mentions"`
+	Snippet           string             `json:"snippet" bson:"snippet"`
+	Rule              string             `json:"rule" bson:"rule"`
+	Confidence        float64            `json:"confidence" bson:"confidence"`
+	Extractor         string             `json:"extractor" bson:"extractor"`
+	ExtractorVersion  string             `json:"extractor_version" bson:"extractor_version"`
+	RulesVersion      int                `json:"rules_version,omitempty" bson:"rules_version,omitempty"`
+	FactID            string             `json:"fact_id,omitempty" bson:"fact_id,omitempty"`
+	ReviewedBy        string             `json:"reviewed_by,omitempty" bson:"reviewed_by,omitempty"`
+	ReviewedAt        *time.Time         `json:"reviewed_at,omitempty" bson:"reviewed_at,omitempty"`
+	CreatedAt         time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt         time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// Provenance records where an extracted entity or relation came from. It is
+// stored on the graph element when a candidate is accepted.
+type Provenance struct {
+	DocumentID       string `json:"document_id"`
+	DocumentVersion  int64  `json:"document_version"`
+	Start            int64  `json:"start"`
+	End              int64  `json:"end"`
+	Extractor        string `json:"extractor"`
+	ExtractorVersion string `json:"extractor_version"`
+}
+
+// ExtractDocumentRequest runs an extractor over a document version. Version
+// 0 is the latest version with extracted text, and an empty Extractor is the
+// one chosen in the workspace settings.
+type ExtractDocumentRequest struct {
+	DocumentID string `json:"document_id" validate:"required"`
+	Version    int    `json:"version" validate:"min=0"`
+	Extractor  string `json:"extractor,omitempty" validate:"omitempty,max=64"`
+}
+
+// ExtractionRun summarises the candidates a run added to the review queue.
+// Facts that were already accepted or rejected for the document are not
+// proposed again. Discarded counts the extractor results that could not be
+// used, such as mentions outside the text.
+type ExtractionRun struct {
+	DocumentID       string `json:"document_id"`
+	DocumentVersion  int    `json:"document_version"`
+	Extractor        string `json:"extractor"`
+	ExtractorVersion string `json:"extractor_version"`
+	RulesVersion     int    `json:"rules_version,omitempty"`
+	Entities         int    `json:"entities"`
+	Relations        int    `json:"relations"`
+	Skipped          int    `json:"skipped"`
+	Discarded        int    `json:"discarded"`
+	Truncated        bool   `json:"truncated"`
+}
+
+// ExtractionSettings holds the extractor a workspace uses when a run does not
+// name one. Workspaces without settings use the built-in rules.
+type ExtractionSettings struct {
+	ID          primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	WorkspaceID string             `json:"workspace_id" bson:"workspace_id"`
+	Extractor   string             `json:"extractor" bson:"extractor"`
+	UpdatedBy   string             `json:"updated_by,omitempty" bson:"updated_by"`
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+type UpdateExtractionSettingsRequest struct {
+	Extractor string `json:"extractor" validate:"required,max=64"`
+}
+
+type CandidateListQuery struct {
+	Status     string `query:"status" validate:"omitempty,oneof=pending accepted rejected"`
+	Kind       string `query:"kind" validate:"omitempty,oneof=entity relation"`
+	DocumentID string `query:"document_id"`
+	Page       int64  `query:"page"`
+	Limit      int64  `query:"limit"`
+}
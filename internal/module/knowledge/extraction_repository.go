@@ -0,0 +1,280 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+type RuleSetRepository interface {
+	Create(ctx context.Context, rules *RuleSet) (*RuleSet, error)
+	GetLatest(ctx context.Context, workspaceID string) (*RuleSet, error)
+	DeleteByWorkspace(ctx context.Context, workspaceID string) error
+}
+
+type ruleSetRepository struct {
+	repo mongo.Repository[RuleSet]
+}
+
+var _ RuleSetRepository = (*ruleSetRepository)(nil)
+
+func NewRuleSetRepository(mongoService *mongo.MongoService) RuleSetRepository {
+	return &ruleSetRepository{
+		repo: mongo.NewRepository[RuleSet](mongoService, RuleSetCollectionName),
+	}
+}
+
+func (r *ruleSetRepository) Create(ctx context.Context, rules *RuleSet) (*RuleSet, error) {
+	result, err := r.repo.Create(ctx, *rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create extraction rules: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *ruleSetRepository) GetLatest(ctx context.Context, workspaceID string) (*RuleSet, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}})
+
+	result, err := r.repo.FindOne(ctx, bson.M{"workspace_id": workspaceID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get extraction rules: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *ruleSetRepository) DeleteByWorkspace(ctx context.Context, workspaceID string) error {
+	if _, err := r.repo.DeleteMany(ctx, bson.M{"workspace_id": workspaceID}); err != nil {
+		return fmt.Errorf("failed to delete extraction rules: %w", err)
+	}
+
+	return nil
+}
+
+type CandidateRepository interface {
+	Create(ctx context.Context, candidate *Candidate) (*Candidate, error)
+	GetByID(ctx context.Context, workspaceID string, id primitive.ObjectID) (*Candidate, error)
+	List(ctx context.Context, filter bson.M, pagination mongo.PaginationOptions) (*mongo.PaginatedResult[Candidate], error)
+	ListReviewed(ctx context.Context, workspaceID, documentID string) ([]*Candidate, error)
+	ListPendingRelations(ctx context.Context, workspaceID, entityCandidateID string) ([]*Candidate, error)
+	Review(ctx context.Context, workspaceID string, id primitive.ObjectID, status CandidateStatus, factID, reviewedBy string) (*Candidate, error)
+	DeletePending(ctx context.Context, workspaceID, documentID string) error
+	DeleteByDocument(ctx context.Context, workspaceID, documentID string) error
+	DeleteByWorkspace(ctx context.Context, workspaceID string) error
+}
+
+type candidateRepository struct {
+	repo mongo.Repository[Candidate]
+}
+
+var _ CandidateRepository = (*candidateRepository)(nil)
+
+func NewCandidateRepository(mongoService *mongo.MongoService) CandidateRepository {
+	return &candidateRepository{
+		repo: mongo.NewRepository[Candidate](mongoService, CandidateCollectionName),
+	}
+}
+
+// Create stores candidate under its ID, or a new one when it has none, so
+// that relation candidates can refer to entity candidates stored in the same
+// run.
+func (r *candidateRepository) Create(ctx context.Context, candidate *Candidate) (*Candidate, error) {
+	if candidate.ID.IsZero() {
+		candidate.ID = primitive.NewObjectID()
+	}
+
+	result, err := r.repo.Create(ctx, *candidate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create candidate: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *candidateRepository) GetByID(ctx context.Context, workspaceID string, id primitive.ObjectID) (*Candidate, error) {
+	result, err := r.repo.FindOne(ctx, bson.M{"_id": id, "workspace_id": workspaceID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get candidate: %w", err)
+	}
+
+	return result, nil
+}
+
+// List sorts candidates by confidence, most confident first.
+func (r *candidateRepository) List(ctx context.Context, filter bson.M, pagination mongo.PaginationOptions) (*mongo.PaginatedResult[Candidate], error) {
+	opts := options.Find().SetSort(bson.D{{Key: "confidence", Value: -1}, {Key: "created_at", Value: -1}})
+
+	result, err := r.repo.FindWithPagination(ctx, filter, pagination, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list candidates: %w", err)
+	}
+
+	return result, nil
+}
+
+// ListReviewed returns the accepted and rejected candidates of a document.
+func (r *candidateRepository) ListReviewed(ctx context.Context, workspaceID, documentID string) ([]*Candidate, error) {
+	results, err := r.repo.Find(ctx, bson.M{
+		"workspace_id": workspaceID,
+		"document_id":  documentID,
+		"status":       bson.M{"$in": bson.A{CandidateStatusAccepted, CandidateStatusRejected}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list candidates: %w", err)
+	}
+
+	candidates := make([]*Candidate, len(results))
+	for i := range results {
+		candidates[i] = &results[i]
+	}
+
+	return candidates, nil
+}
+
+// ListPendingRelations returns the pending relation candidates with the
+// entity candidate at either end.
+func (r *candidateRepository) ListPendingRelations(ctx context.Context, workspaceID, entityCandidateID string) ([]*Candidate, error) {
+	results, err := r.repo.Find(ctx, bson.M{
+		"workspace_id": workspaceID,
+		"kind":         CandidateKindRelation,
+		"status":       CandidateStatusPending,
+		"$or": bson.A{
+			bson.M{"source_candidate_id": entityCandidateID},
+			bson.M{"target_candidate_id": entityCandidateID},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list candidates: %w", err)
+	}
+
+	candidates := make([]*Candidate, len(results))
+	for i := range results {
+		candidates[i] = &results[i]
+	}
+
+	return candidates, nil
+}
+
+// Review records the decision on a pending candidate. It returns nil when the
+// candidate does not exist or was already reviewed.
+func (r *candidateRepository) Review(ctx context.Context, workspaceID string, id primitive.ObjectID, status CandidateStatus, factID, reviewedBy string) (*Candidate, error) {
+	now := time.Now()
+	set := bson.M{
+		"status":      status,
+		"reviewed_by": reviewedBy,
+		"reviewed_at": now,
+		"updated_at":  now,
+	}
+	if factID != "" {
+		set["fact_id"] = factID
+	}
+
+	result, err := r.repo.Update(ctx,
+		bson.M{"_id": id, "workspace_id": workspaceID, "status": CandidateStatusPending},
+		bson.M{"$set": set},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to review candidate: %w", err)
+	}
+
+	return result, nil
+}
+
+// DeletePending removes the candidates of a document that are still waiting
+// for review, before the document is extracted again.
+func (r *candidateRepository) DeletePending(ctx context.Context, workspaceID, documentID string) error {
+	_, err := r.repo.DeleteMany(ctx, bson.M{
+		"workspace_id": workspaceID,
+		"document_id":  documentID,
+		"status":       CandidateStatusPending,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete candidates: %w", err)
+	}
+
+	return nil
+}
+
+func (r *candidateRepository) DeleteByDocument(ctx context.Context, workspaceID, documentID string) error {
+	_, err := r.repo.DeleteMany(ctx, bson.M{"workspace_id": workspaceID, "document_id": documentID})
+	if err != nil {
+		return fmt.Errorf("failed to delete candidates: %w", err)
+	}
+
+	return nil
+}
+
+func (r *candidateRepository) DeleteByWorkspace(ctx context.Context, workspaceID string) error {
+	if _, err := r.repo.DeleteMany(ctx, bson.M{"workspace_id": workspaceID}); err != nil {
+		return fmt.Errorf("failed to delete candidates: %w", err)
+	}
+
+	return nil
+}
+
+type ExtractionSettingsRepository interface {
+	Get(ctx context.Context, workspaceID string) (*ExtractionSettings, error)
+	Set(ctx context.Context, settings *ExtractionSettings) (*ExtractionSettings, error)
+	DeleteByWorkspace(ctx context.Context, workspaceID string) error
+}
+
+type extractionSettingsRepository struct {
+	repo mongo.Repository[ExtractionSettings]
+}
+
+var _ ExtractionSettingsRepository = (*extractionSettingsRepository)(nil)
+
+func NewExtractionSettingsRepository(mongoService *mongo.MongoService) ExtractionSettingsRepository {
+	return &extractionSettingsRepository{
+		repo: mongo.NewRepository[ExtractionSettings](mongoService, ExtractionSettingsCollectionName),
+	}
+}
+
+func (r *extractionSettingsRepository) Get(ctx context.Context, workspaceID string) (*ExtractionSettings, error) {
+	result, err := r.repo.FindOne(ctx, bson.M{"workspace_id": workspaceID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get extraction settings: %w", err)
+	}
+
+	return result, nil
+}
+
+// Set replaces the settings of the workspace, creating them the first time.
+func (r *extractionSettingsRepository) Set(ctx context.Context, settings *ExtractionSettings) (*ExtractionSettings, error) {
+	result, err := r.repo.Update(ctx,
+		bson.M{"workspace_id": settings.WorkspaceID},
+		bson.M{"$set": bson.M{
+			"extractor":  settings.Extractor,
+			"updated_by": settings.UpdatedBy,
+			"updated_at": settings.UpdatedAt,
+		}},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update extraction settings: %w", err)
+	}
+	if result != nil {
+		return result, nil
+	}
+
+	result, err = r.repo.Create(ctx, *settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create extraction settings: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *extractionSettingsRepository) DeleteByWorkspace(ctx context.Context, workspaceID string) error {
+	if _, err := r.repo.DeleteMany(ctx, bson.M{"workspace_id": workspaceID}); err != nil {
+		return fmt.Errorf("failed to delete extraction settings: %w", err)
+	}
+
+	return nil
+}
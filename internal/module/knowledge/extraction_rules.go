@@ -0,0 +1,373 @@
+package knowledge
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// mention is an entity found in a text. Offsets are in bytes; the service
+// converts them to characters.
+type mention struct {
+	entityType string
+	name       string
+	start, end int
+	rule       string
+	confidence float64
+	priority   int
+}
+
+// relationMention is a relation between two mentions of the same text.
+type relationMention struct {
+	relationType   string
+	source, target *mention
+	start, end     int
+	rule           string
+	confidence     float64
+}
+
+type compiledGazetteer struct {
+	entityType    string
+	caseSensitive bool
+	confidence    float64
+	expr          *regexp.Regexp
+	names         map[string]string
+}
+
+type compiledPattern struct {
+	name       string
+	entityType string
+	expr       *regexp.Regexp
+	group      int
+	confidence float64
+}
+
+type compiledCooccurrence struct {
+	CooccurrenceRule
+	keywords []string
+}
+
+// ruleMatcher applies a rule set to text. It has no state between calls and
+// can be shared.
+type ruleMatcher struct {
+	gazetteers    []compiledGazetteer
+	patterns      []compiledPattern
+	cooccurrences []compiledCooccurrence
+}
+
+// compileRules prepares a normalized rule set for matching.
+func compileRules(rules *RuleSet) (*ruleMatcher, error) {
+	matcher := &ruleMatcher{}
+
+	for _, gazetteer := range rules.Gazetteers {
+		compiled := compiledGazetteer{
+			entityType:    gazetteer.EntityType,
+			caseSensitive: gazetteer.CaseSensitive,
+			confidence:    gazetteer.Confidence,
+			names:         make(map[string]string),
+		}
+
+		var terms []string
+		for _, entry := range gazetteer.Entries {
+			for _, term := range append([]string{entry.Name}, entry.Aliases...) {
+				key := compiled.key(term)
+				if _, exists := compiled.names[key]; exists || key == "" {
+					continue
+				}
+				compiled.names[key] = entry.Name
+				terms = append(terms, term)
+			}
+		}
+		if len(terms) == 0 {
+			continue
+		}
+
+		// Longer terms first, so that the leftmost match is also the longest.
+		// Words may be separated by any whitespace, line breaks included.
+		sort.SliceStable(terms, func(i, j int) bool { return len(terms[i]) > len(terms[j]) })
+		for i, term := range terms {
+			words := strings.Fields(term)
+			for j, word := range words {
+				words[j] = regexp.QuoteMeta(word)
+			}
+			terms[i] = strings.Join(words, `\s+`)
+		}
+
+		expression := strings.Join(terms, "|")
+		if !gazetteer.CaseSensitive {
+			expression = "(?i)" + expression
+		}
+
+		expr, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gazetteer for %s: %w", gazetteer.EntityType, err)
+		}
+		compiled.expr = expr
+		matcher.gazetteers = append(matcher.gazetteers, compiled)
+	}
+
+	for _, pattern := range rules.Patterns {
+		expr, err := regexp.Compile(pattern.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %w", pattern.Name, err)
+		}
+		if pattern.Group > expr.NumSubexp() {
+			return nil, fmt.Errorf("invalid pattern %s: the expression has no group %d", pattern.Name, pattern.Group)
+		}
+
+		matcher.patterns = append(matcher.patterns, compiledPattern{
+			name:       pattern.Name,
+			entityType: pattern.EntityType,
+			expr:       expr,
+			group:      pattern.Group,
+			confidence: pattern.Confidence,
+		})
+	}
+
+	for _, rule := range rules.Cooccurrences {
+		compiled := compiledCooccurrence{CooccurrenceRule: rule}
+		for _, keyword := range rule.Keywords {
+			compiled.keywords = append(compiled.keywords, strings.ToLower(keyword))
+		}
+		matcher.cooccurrences = append(matcher.cooccurrences, compiled)
+	}
+
+	return matcher, nil
+}
+
+func (g *compiledGazetteer) key(term string) string {
+	term = strings.Join(strings.Fields(term), " ")
+	if g.caseSensitive {
+		return term
+	}
+	return strings.ToLower(term)
+}
+
+// extract finds the entities named in text and the relations the
+// co-occurrence rules propose between them. Overlapping entity mentions are
+// resolved in favour of the earliest, then the longest, then the one from
+// the earlier rule, with gazetteers before patterns.
+func (m *ruleMatcher) extract(text string) ([]*mention, []*relationMention) {
+	var mentions []*mention
+	priority := 0
+
+	for i := range m.gazetteers {
+		gazetteer := &m.gazetteers[i]
+		for _, match := range gazetteer.expr.FindAllStringIndex(text, -1) {
+			start, end := match[0], match[1]
+			if !atWordBoundary(text, start, end) {
+				continue
+			}
+
+			name, ok := gazetteer.names[gazetteer.key(text[start:end])]
+			if !ok {
+				name = text[start:end]
+			}
+			mentions = append(mentions, &mention{
+				entityType: gazetteer.entityType,
+				name:       name,
+				start:      start,
+				end:        end,
+				rule:       "gazetteer:" + gazetteer.entityType,
+				confidence: gazetteer.confidence,
+				priority:   priority,
+			})
+		}
+		priority++
+	}
+
+	for _, pattern := range m.patterns {
+		for _, match := range pattern.expr.FindAllStringSubmatchIndex(text, -1) {
+			start, end := match[2*pattern.group], match[2*pattern.group+1]
+			if start < 0 {
+				continue
+			}
+
+			name := strings.Join(strings.Fields(text[start:end]), " ")
+			if name == "" {
+				continue
+			}
+			mentions = append(mentions, &mention{
+				entityType: pattern.entityType,
+				name:       name,
+				start:      start,
+				end:        end,
+				rule:       "pattern:" + pattern.name,
+				confidence: pattern.confidence,
+				priority:   priority,
+			})
+		}
+		priority++
+	}
+
+	mentions = resolveOverlaps(mentions)
+
+	var relations []*relationMention
+	for _, rule := range m.cooccurrences {
+		for _, segment := range segments(text, rule.Scope) {
+			relations = append(relations, rule.relations(text, segment, mentions)...)
+		}
+	}
+
+	return mentions, relations
+}
+
+func resolveOverlaps(mentions []*mention) []*mention {
+	sort.SliceStable(mentions, func(i, j int) bool {
+		a, b := mentions[i], mentions[j]
+		if a.start != b.start {
+			return a.start < b.start
+		}
+		if a.end-a.start != b.end-b.start {
+			return a.end-a.start > b.end-b.start
+		}
+		return a.priority < b.priority
+	})
+
+	kept := mentions[:0]
+	end := -1
+	for _, mention := range mentions {
+		if mention.start < end {
+			continue
+		}
+		kept = append(kept, mention)
+		end = mention.end
+	}
+
+	return kept
+}
+
+// relations pairs the mentions inside segment. Either mention may be the
+// source, whichever comes first in the text.
+func (r *compiledCooccurrence) relations(text string, segment [2]int, mentions []*mention) []*relationMention {
+	var inside []*mention
+	for _, mention := range mentions {
+		if mention.start >= segment[0] && mention.end <= segment[1] {
+			inside = append(inside, mention)
+		}
+	}
+
+	var relations []*relationMention
+	for i, first := range inside {
+		for _, second := range inside[i+1:] {
+			if strings.EqualFold(first.name, second.name) && first.entityType == second.entityType {
+				continue
+			}
+			if len(r.keywords) > 0 && !containsAny(strings.ToLower(text[first.end:second.start]), r.keywords) {
+				continue
+			}
+
+			for _, pair := range [][2]*mention{{first, second}, {second, first}} {
+				source, target := pair[0], pair[1]
+				if source.entityType != r.SourceType || target.entityType != r.TargetType {
+					continue
+				}
+				relations = append(relations, &relationMention{
+					relationType: r.RelationType,
+					source:       source,
+					target:       target,
+					start:        first.start,
+					end:          second.end,
+					rule:         "cooccurrence:" + r.RelationType,
+					confidence:   r.Confidence,
+				})
+			}
+		}
+	}
+
+	return relations
+}
+
+func containsAny(s string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if strings.Contains(s, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// segments splits text into paragraphs at blank lines, or into sentences at
+// line breaks and at sentence punctuation followed by a space. Segments are
+// byte ranges.
+func segments(text, scope string) [][2]int {
+	var result [][2]int
+	start := 0
+
+	add := func(end int) {
+		if strings.TrimSpace(text[start:end]) != "" {
+			result = append(result, [2]int{start, end})
+		}
+	}
+
+	for i := 0; i < len(text); i++ {
+		switch {
+		case scope == ScopeParagraph && strings.HasPrefix(text[i:], "\n\n"):
+			add(i)
+			start = i + 2
+			i++
+		case scope != ScopeParagraph && text[i] == '\n':
+			add(i)
+			start = i + 1
+		case scope != ScopeParagraph && strings.ContainsRune(".!?", rune(text[i])) &&
+			(i+1 == len(text) || text[i+1] == ' ' || text[i+1] == '\t'):
+			add(i + 1)
+			start = i + 1
+		}
+	}
+	add(len(text))
+
+	return result
+}
+
+// atWordBoundary reports whether text[start:end] neither starts nor ends in
+// the middle of a word.
+func atWordBoundary(text string, start, end int) bool {
+	if start > 0 {
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		first, _ := utf8.DecodeRuneInString(text[start:])
+		if isWordRune(before) && isWordRune(first) {
+			return false
+		}
+	}
+	if end < len(text) {
+		last, _ := utf8.DecodeLastRuneInString(text[:end])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if isWordRune(last) && isWordRune(after) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// runeOffsets converts byte offsets into character offsets. Offsets must be
+// asked for in increasing order to stay linear; smaller ones start over.
+type runeOffsets struct {
+	text       string
+	byteOffset int
+	runeOffset int
+}
+
+func (o *runeOffsets) at(offset int) int {
+	if offset < o.byteOffset {
+		o.byteOffset, o.runeOffset = 0, 0
+	}
+	o.runeOffset += utf8.RuneCountInString(o.text[o.byteOffset:offset])
+	o.byteOffset = offset
+	return o.runeOffset
+}
+
+// snippet returns the text around the characters text[start:end].
+func snippet(text []rune, start, end int) string {
+	from := max(start-CandidateSnippetRunes, 0)
+	to := min(end+CandidateSnippetRunes, len(text))
+
+	return strings.Join(strings.Fields(string(text[from:to])), " ")
+}
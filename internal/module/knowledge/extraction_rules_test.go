@@ -0,0 +1,175 @@
+package knowledge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRuleMatcher(t *testing.T, req *UpdateRulesRequest) *ruleMatcher {
+	rules, err := normalizeRules(req)
+	require.NoError(t, err)
+
+	matcher, err := compileRules(rules)
+	require.NoError(t, err)
+
+	return matcher
+}
+
+func mentionNames(mentions []*mention) []string {
+	names := make([]string, len(mentions))
+	for i, mention := range mentions {
+		names[i] = mention.entityType + ":" + mention.name
+	}
+	return names
+}
+
+func TestRuleMatcher_Gazetteer(t *testing.T) {
+	matcher := testRuleMatcher(t, &UpdateRulesRequest{
+		Gazetteers: []Gazetteer{
+			{
+				EntityType: "Person",
+				Entries: []GazetteerEntry{
+					{Name: "Ada Lovelace", Aliases: []string{"Countess of Lovelace", "Ada"}},
+					{Name: "Babbage"},
+				},
+			},
+			{
+				EntityType:    "Organization",
+				CaseSensitive: true,
+				Entries:       []GazetteerEntry{{Name: "IBM"}},
+			},
+		},
+	})
+
+	mentions, _ := matcher.extract("ADA LOVELACE met Babbage. The countess of  Lovelace, not Adam, worked before ibm or IBM.")
+
+	assert.Equal(t, []string{
+		"Person:Ada Lovelace",
+		"Person:Babbage",
+		"Person:Ada Lovelace",
+		"Organization:IBM",
+	}, mentionNames(mentions))
+	assert.Equal(t, "gazetteer:Person", mentions[0].rule)
+	assert.Equal(t, defaultGazetteerConfidence, mentions[0].confidence)
+}
+
+func TestRuleMatcher_PatternsAndOverlaps(t *testing.T) {
+	matcher := testRuleMatcher(t, &UpdateRulesRequest{
+		Gazetteers: []Gazetteer{
+			{EntityType: "Organization", Entries: []GazetteerEntry{{Name: "Analytical Society"}}},
+		},
+		Patterns: []PatternRule{
+			{Name: "society", EntityType: "Organization", Expression: `([A-Z]\w+\s+Society)`, Group: 1},
+			{Name: "year", EntityType: "Year", Expression: `\b(1[89]\d\d)\b`},
+		},
+	})
+
+	mentions, _ := matcher.extract("The Analytical Society was founded in 1812; the Royal\nSociety is older.")
+
+	require.Len(t, mentions, 3)
+	assert.Equal(t, "gazetteer:Organization", mentions[0].rule)
+	assert.Equal(t, "Analytical Society", mentions[0].name)
+	assert.Equal(t, "pattern:year", mentions[1].rule)
+	assert.Equal(t, "1812", mentions[1].name)
+	assert.Equal(t, "Royal Society", mentions[2].name)
+	assert.Equal(t, defaultPatternConfidence, mentions[2].confidence)
+}
+
+func TestRuleMatcher_Cooccurrence(t *testing.T) {
+	req := &UpdateRulesRequest{
+		Gazetteers: []Gazetteer{
+			{EntityType: "Person", Entries: []GazetteerEntry{{Name: "Ada"}, {Name: "Charles"}}},
+			{EntityType: "Organization", Entries: []GazetteerEntry{{Name: "Acme"}, {Name: "Initech"}}},
+		},
+		Cooccurrences: []CooccurrenceRule{
+			{RelationType: "works_at", SourceType: "Person", TargetType: "Organization", Keywords: []string{"works"}},
+		},
+	}
+
+	t.Run("sentence scope with keywords", func(t *testing.T) {
+		matcher := testRuleMatcher(t, req)
+
+		_, relations := matcher.extract("Ada works at Acme. Charles visited Initech.\nAcme, where Charles works.")
+
+		require.Len(t, relations, 1)
+		assert.Equal(t, "WORKS_AT", relations[0].relationType)
+		assert.Equal(t, "Ada", relations[0].source.name)
+		assert.Equal(t, "Acme", relations[0].target.name)
+		assert.Equal(t, "cooccurrence:WORKS_AT", relations[0].rule)
+	})
+
+	t.Run("paragraph scope in either order", func(t *testing.T) {
+		paragraph := *req
+		paragraph.Cooccurrences = []CooccurrenceRule{
+			{RelationType: "WORKS_AT", SourceType: "Person", TargetType: "Organization", Scope: ScopeParagraph},
+		}
+		matcher := testRuleMatcher(t, &paragraph)
+
+		_, relations := matcher.extract("Acme hired someone. It was Ada.\n\nCharles")
+
+		require.Len(t, relations, 1)
+		assert.Equal(t, "Ada", relations[0].source.name)
+		assert.Equal(t, "Acme", relations[0].target.name)
+		assert.Equal(t, defaultCooccurrenceConfidence, relations[0].confidence)
+	})
+}
+
+func TestNormalizeRules_Invalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           *UpdateRulesRequest
+		errorContains string
+	}{
+		{
+			name:          "bad expression",
+			req:           &UpdateRulesRequest{Patterns: []PatternRule{{Name: "broken", EntityType: "Person", Expression: `(unclosed`}}},
+			errorContains: "invalid pattern broken",
+		},
+		{
+			name:          "missing group",
+			req:           &UpdateRulesRequest{Patterns: []PatternRule{{Name: "year", EntityType: "Year", Expression: `\d{4}`, Group: 1}}},
+			errorContains: "has no group 1",
+		},
+		{
+			name: "duplicate pattern",
+			req: &UpdateRulesRequest{Patterns: []PatternRule{
+				{Name: "year", EntityType: "Year", Expression: `\d{4}`},
+				{Name: "year", EntityType: "Year", Expression: `\d{2}`},
+			}},
+			errorContains: "defined twice",
+		},
+		{
+			name:          "invalid entity type",
+			req:           &UpdateRulesRequest{Gazetteers: []Gazetteer{{EntityType: "Person`)", Entries: []GazetteerEntry{{Name: "Ada"}}}}},
+			errorContains: "invalid entity type",
+		},
+		{
+			name:          "entry without a name",
+			req:           &UpdateRulesRequest{Gazetteers: []Gazetteer{{EntityType: "Person", Entries: []GazetteerEntry{{Name: "  "}}}}},
+			errorContains: "without a name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := normalizeRules(tt.req)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorContains)
+		})
+	}
+}
+
+func TestRuneOffsetsAndSnippet(t *testing.T) {
+	text := "Zoë Ångström works at Café Ü."
+	offsets := &runeOffsets{text: text}
+
+	start := len("Zoë ")
+	end := start + len("Ångström")
+	assert.Equal(t, 4, offsets.at(start))
+	assert.Equal(t, 12, offsets.at(end))
+	assert.Equal(t, 0, offsets.at(0))
+
+	assert.Equal(t, text, snippet([]rune(text), 4, 12))
+}
@@ -0,0 +1,691 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/module/document"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+// DocumentTextSource returns the text extracted from a document version.
+// Version 0 is the latest version with extracted text.
+type DocumentTextSource interface {
+	GetResult(ctx context.Context, workspaceID, documentID string, version int) (*document.ExtractionResult, error)
+}
+
+// ExtractionService turns document text into candidate entities and
+// relations with the extractor chosen for the workspace, and writes the
+// candidates editors accept to the graph.
+type ExtractionService interface {
+	GetRules(ctx context.Context, workspaceID string) (*RuleSet, error)
+	UpdateRules(ctx context.Context, workspaceID, accountID string, req *UpdateRulesRequest) (*RuleSet, error)
+	ListExtractors() []*ExtractorInfo
+	GetSettings(ctx context.Context, workspaceID string) (*ExtractionSettings, error)
+	UpdateSettings(ctx context.Context, workspaceID, accountID string, req *UpdateExtractionSettingsRequest) (*ExtractionSettings, error)
+	ExtractDocument(ctx context.Context, workspaceID string, req *ExtractDocumentRequest) (*ExtractionRun, error)
+
+	ListCandidates(ctx context.Context, workspaceID string, query *CandidateListQuery) (*mongo.PaginatedResult[Candidate], error)
+	GetCandidate(ctx context.Context, workspaceID, id string) (*Candidate, error)
+	AcceptCandidate(ctx context.Context, workspaceID, accountID, id string) (*Candidate, error)
+	RejectCandidate(ctx context.Context, workspaceID, accountID, id string) (*Candidate, error)
+
+	DeleteDocument(ctx context.Context, workspaceID, documentID string) error
+	DeleteWorkspace(ctx context.Context, workspaceID string) error
+}
+
+type extractionService struct {
+	rules      RuleSetRepository
+	candidates CandidateRepository
+	settings   ExtractionSettingsRepository
+	extractors *ExtractorRegistry
+	knowledge  *knowledgeService
+	documents  DocumentTextSource
+}
+
+func newExtractionService(
+	rules RuleSetRepository,
+	candidates CandidateRepository,
+	settings ExtractionSettingsRepository,
+	extractors *ExtractorRegistry,
+	knowledge *knowledgeService,
+	documents DocumentTextSource,
+) *extractionService {
+	return &extractionService{
+		rules:      rules,
+		candidates: candidates,
+		settings:   settings,
+		extractors: extractors,
+		knowledge:  knowledge,
+		documents:  documents,
+	}
+}
+
+func (s *extractionService) GetRules(ctx context.Context, workspaceID string) (*RuleSet, error) {
+	rules, err := s.rules.GetLatest(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		return nil, fmt.Errorf("extraction rules not found")
+	}
+
+	return rules, nil
+}
+
+// UpdateRules stores req as the next version of the rules. Candidates that
+// are already queued keep the rules version that produced them.
+func (s *extractionService) UpdateRules(ctx context.Context, workspaceID, accountID string, req *UpdateRulesRequest) (*RuleSet, error) {
+	rules, err := normalizeRules(req)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := s.rules.GetLatest(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules.WorkspaceID = workspaceID
+	rules.Version = 1
+	if latest != nil {
+		rules.Version = latest.Version + 1
+	}
+	rules.CreatedBy = accountID
+	rules.CreatedAt = time.Now()
+
+	return s.rules.Create(ctx, rules)
+}
+
+func (s *extractionService) ListExtractors() []*ExtractorInfo {
+	return s.extractors.List()
+}
+
+// GetSettings returns the settings of the workspace, or the defaults when
+// none were stored.
+func (s *extractionService) GetSettings(ctx context.Context, workspaceID string) (*ExtractionSettings, error) {
+	settings, err := s.settings.Get(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		return &ExtractionSettings{WorkspaceID: workspaceID, Extractor: RuleExtractor}, nil
+	}
+
+	return settings, nil
+}
+
+func (s *extractionService) UpdateSettings(ctx context.Context, workspaceID, accountID string, req *UpdateExtractionSettingsRequest) (*ExtractionSettings, error) {
+	if _, ok := s.extractors.Get(req.Extractor); !ok {
+		return nil, fmt.Errorf("invalid extractor %q", req.Extractor)
+	}
+
+	return s.settings.Set(ctx, &ExtractionSettings{
+		WorkspaceID: workspaceID,
+		Extractor:   req.Extractor,
+		UpdatedBy:   accountID,
+		UpdatedAt:   time.Now(),
+	})
+}
+
+// ExtractDocument replaces the pending candidates of a document with those
+// the extractor finds in the text of the requested version. Facts already
+// accepted or rejected for the document are skipped, whichever extractor
+// proposed them, and relations are only proposed between entities that were
+// not rejected.
+func (s *extractionService) ExtractDocument(ctx context.Context, workspaceID string, req *ExtractDocumentRequest) (*ExtractionRun, error) {
+	name := req.Extractor
+	if name == "" {
+		settings, err := s.GetSettings(ctx, workspaceID)
+		if err != nil {
+			return nil, err
+		}
+		name = settings.Extractor
+	}
+
+	extractor, ok := s.extractors.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("invalid extractor %q", name)
+	}
+
+	result, err := s.documents.GetResult(ctx, workspaceID, req.DocumentID, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &ExtractorInput{
+		WorkspaceID:     workspaceID,
+		DocumentID:      req.DocumentID,
+		DocumentVersion: result.Version,
+		Text:            result.Text,
+	}
+	schema, err := s.knowledge.schemas.GetLatest(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if schema != nil {
+		for _, definition := range schema.EntityTypes {
+			input.EntityTypes = append(input.EntityTypes, definition.Name)
+		}
+		for _, definition := range schema.RelationTypes {
+			input.RelationTypes = append(input.RelationTypes, definition.Name)
+		}
+	}
+
+	output, err := extractor.Extract(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewed, err := s.candidates.ListReviewed(ctx, workspaceID, req.DocumentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.candidates.DeletePending(ctx, workspaceID, req.DocumentID); err != nil {
+		return nil, err
+	}
+
+	decided := make(map[string]*Candidate, len(reviewed))
+	for _, candidate := range reviewed {
+		decided[candidate.Key] = candidate
+	}
+
+	run := &ExtractionRun{
+		DocumentID:       req.DocumentID,
+		DocumentVersion:  result.Version,
+		Extractor:        extractor.Name(),
+		ExtractorVersion: extractor.Version(),
+		RulesVersion:     output.RulesVersion,
+		Discarded:        output.Discarded,
+	}
+	if output.Version != "" {
+		run.ExtractorVersion = output.Version
+	}
+
+	candidates := newCandidateBuilder(workspaceID, run, result.Text)
+	for _, entity := range output.Entities {
+		candidates.addEntity(entity)
+	}
+	for _, relation := range output.Relations {
+		candidates.addRelation(relation, output.Entities)
+	}
+
+	for _, candidate := range candidates.list {
+		if previous, ok := decided[candidate.Key]; ok {
+			candidates.ids[candidate.Key] = previous.ID.Hex()
+			if previous.Status == CandidateStatusRejected {
+				candidates.rejected[candidate.Key] = true
+			}
+			run.Skipped++
+			continue
+		}
+
+		if candidate.Kind == CandidateKindRelation {
+			if candidates.rejected[candidates.endKeys[candidate.Key][0]] || candidates.rejected[candidates.endKeys[candidate.Key][1]] {
+				run.Skipped++
+				continue
+			}
+			candidate.SourceCandidateID = candidates.ids[candidates.endKeys[candidate.Key][0]]
+			candidate.TargetCandidateID = candidates.ids[candidates.endKeys[candidate.Key][1]]
+		}
+
+		if run.Entities+run.Relations >= MaxCandidatesPerRun {
+			run.Truncated = true
+			break
+		}
+
+		if _, err := s.candidates.Create(ctx, candidate); err != nil {
+			return nil, err
+		}
+		if candidate.Kind == CandidateKindEntity {
+			run.Entities++
+		} else {
+			run.Relations++
+		}
+	}
+
+	return run, nil
+}
+
+func (s *extractionService) ListCandidates(ctx context.Context, workspaceID string, query *CandidateListQuery) (*mongo.PaginatedResult[Candidate], error) {
+	filter := bson.M{"workspace_id": workspaceID}
+	if query.Status != "" {
+		filter["status"] = query.Status
+	}
+	if query.Kind != "" {
+		filter["kind"] = query.Kind
+	}
+	if query.DocumentID != "" {
+		filter["document_id"] = query.DocumentID
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = CandidateListDefaultLimit
+	}
+	if limit > CandidateListMaxLimit {
+		limit = CandidateListMaxLimit
+	}
+
+	return s.candidates.List(ctx, filter, mongo.PaginationOptions{
+		Page:  query.Page,
+		Limit: limit,
+	})
+}
+
+func (s *extractionService) GetCandidate(ctx context.Context, workspaceID, id string) (*Candidate, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("candidate not found")
+	}
+
+	candidate, err := s.candidates.GetByID(ctx, workspaceID, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if candidate == nil {
+		return nil, fmt.Errorf("candidate not found")
+	}
+
+	return candidate, nil
+}
+
+// AcceptCandidate writes the candidate to the graph with its provenance. An
+// entity that already exists with the same type and name is reused rather
+// than duplicated, and so is an existing relation; either way the candidate
+// is recorded as evidence for the fact. Accepting a relation also
+// accepts the entities at its ends that are still pending.
+func (s *extractionService) AcceptCandidate(ctx context.Context, workspaceID, accountID, id string) (*Candidate, error) {
+	candidate, err := s.GetCandidate(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.accept(ctx, candidate, accountID)
+}
+
+// RejectCandidate keeps the candidate out of the graph. Rejecting an entity
+// also rejects the pending relations that involve it.
+func (s *extractionService) RejectCandidate(ctx context.Context, workspaceID, accountID, id string) (*Candidate, error) {
+	candidate, err := s.GetCandidate(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rejected, err := s.candidates.Review(ctx, workspaceID, candidate.ID, CandidateStatusRejected, "", accountID)
+	if err != nil {
+		return nil, err
+	}
+	if rejected == nil {
+		return nil, fmt.Errorf("candidate was already reviewed")
+	}
+
+	if candidate.Kind == CandidateKindEntity {
+		relations, err := s.candidates.ListPendingRelations(ctx, workspaceID, candidate.ID.Hex())
+		if err != nil {
+			return nil, err
+		}
+		for _, relation := range relations {
+			if _, err := s.candidates.Review(ctx, workspaceID, relation.ID, CandidateStatusRejected, "", accountID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return rejected, nil
+}
+
+// DeleteDocument removes the candidates found in a deleted document.
+func (s *extractionService) DeleteDocument(ctx context.Context, workspaceID, documentID string) error {
+	return s.candidates.DeleteByDocument(ctx, workspaceID, documentID)
+}
+
+func (s *extractionService) DeleteWorkspace(ctx context.Context, workspaceID string) error {
+	if err := s.candidates.DeleteByWorkspace(ctx, workspaceID); err != nil {
+		return err
+	}
+	if err := s.settings.DeleteByWorkspace(ctx, workspaceID); err != nil {
+		return err
+	}
+
+	return s.rules.DeleteByWorkspace(ctx, workspaceID)
+}
+
+func (s *extractionService) accept(ctx context.Context, candidate *Candidate, accountID string) (*Candidate, error) {
+	switch candidate.Status {
+	case CandidateStatusAccepted:
+		return candidate, nil
+	case CandidateStatusRejected:
+		return nil, fmt.Errorf("candidate was already rejected")
+	}
+
+	var (
+		factID string
+		err    error
+	)
+	if candidate.Kind == CandidateKindEntity {
+		factID, err = s.acceptEntity(ctx, candidate, accountID)
+	} else {
+		factID, err = s.acceptRelation(ctx, candidate, accountID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	accepted, err := s.candidates.Review(ctx, candidate.WorkspaceID, candidate.ID, CandidateStatusAccepted, factID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if accepted == nil {
+		return nil, fmt.Errorf("candidate was already reviewed")
+	}
+
+	return accepted, nil
+}
+
+func (s *extractionService) acceptEntity(ctx context.Context, candidate *Candidate, accountID string) (string, error) {
+	existing, err := s.knowledge.repository.FindEntityByName(ctx, candidate.WorkspaceID, candidate.Type, candidate.Name)
+	if err != nil {
+		return "", err
+	}
+	entityID := ""
+	if existing != nil {
+		entityID = existing.ID
+	} else {
+		entity, err := s.knowledge.createEntity(ctx, candidate.WorkspaceID, accountID, candidate.Type,
+			map[string]interface{}{"name": candidate.Name}, candidateProvenance(candidate))
+		if err != nil {
+			return "", err
+		}
+		entityID = entity.ID
+	}
+
+	evidence := candidateEvidence(candidate, accountID)
+	evidence.FactKind = FactKindEntity
+	evidence.FactID = entityID
+	if _, err := s.knowledge.evidence.Create(ctx, evidence); err != nil {
+		return "", err
+	}
+
+	return entityID, nil
+}
+
+func (s *extractionService) acceptRelation(ctx context.Context, candidate *Candidate, accountID string) (string, error) {
+	sourceID, err := s.acceptEnd(ctx, candidate.WorkspaceID, candidate.SourceCandidateID, accountID)
+	if err != nil {
+		return "", err
+	}
+	targetID, err := s.acceptEnd(ctx, candidate.WorkspaceID, candidate.TargetCandidateID, accountID)
+	if err != nil {
+		return "", err
+	}
+
+	existing, err := s.knowledge.repository.FindRelation(ctx, candidate.WorkspaceID, sourceID, targetID, candidate.Type)
+	if err != nil {
+		return "", err
+	}
+	relationID := ""
+	if existing != nil {
+		relationID = existing.ID
+	} else {
+		relation, err := s.knowledge.createRelation(ctx, candidate.WorkspaceID, accountID, candidate.Type, sourceID, targetID,
+			map[string]interface{}{}, candidateProvenance(candidate))
+		if err != nil {
+			return "", err
+		}
+		relationID = relation.ID
+	}
+
+	evidence := candidateEvidence(candidate, accountID)
+	evidence.FactKind = FactKindRelation
+	evidence.FactID = relationID
+	evidence.SourceEntityID = sourceID
+	evidence.TargetEntityID = targetID
+	if _, err := s.knowledge.evidence.Create(ctx, evidence); err != nil {
+		return "", err
+	}
+
+	return relationID, nil
+}
+
+// acceptEnd accepts the entity candidate at one end of a relation and
+// returns the ID of its entity.
+func (s *extractionService) acceptEnd(ctx context.Context, workspaceID, candidateID, accountID string) (string, error) {
+	end, err := s.GetCandidate(ctx, workspaceID, candidateID)
+	if err != nil {
+		return "", err
+	}
+	if end.Status == CandidateStatusRejected {
+		return "", fmt.Errorf("invalid candidate: the entity %q was rejected", end.Name)
+	}
+
+	accepted, err := s.accept(ctx, end, accountID)
+	if err != nil {
+		return "", err
+	}
+
+	return accepted.FactID, nil
+}
+
+// candidateEvidence records the document spans behind an accepted candidate,
+// and who accepted it.
+func candidateEvidence(candidate *Candidate, accountID string) *Evidence {
+	return &Evidence{
+		WorkspaceID:      candidate.WorkspaceID,
+		Source:           EvidenceSourceDocument,
+		DocumentID:       candidate.DocumentID,
+		DocumentVersion:  candidate.DocumentVersion,
+		Spans:            candidate.Mentions,
+		Snippet:          candidate.Snippet,
+		Extractor:        candidate.Extractor,
+		ExtractorVersion: candidate.ExtractorVersion,
+		CandidateID:      candidate.ID.Hex(),
+		AccountID:        accountID,
+		Confidence:       candidate.Confidence,
+		CreatedAt:        time.Now(),
+	}
+}
+
+// candidateProvenance points at the first mention of the candidate.
+func candidateProvenance(candidate *Candidate) *Provenance {
+	provenance := &Provenance{
+		DocumentID:       candidate.DocumentID,
+		DocumentVersion:  int64(candidate.DocumentVersion),
+		Extractor:        candidate.Extractor,
+		ExtractorVersion: candidate.ExtractorVersion,
+	}
+	if len(candidate.Mentions) > 0 {
+		provenance.Start = int64(candidate.Mentions[0].Start)
+		provenance.End = int64(candidate.Mentions[0].End)
+	}
+
+	return provenance
+}
+
+// candidateBuilder merges the mentions of one run into candidates: one per
+// entity type and name, and one per relation between two such entities.
+type candidateBuilder struct {
+	workspaceID string
+	run         *ExtractionRun
+	text        []rune
+	now         time.Time
+
+	list     []*Candidate
+	byKey    map[string]*Candidate
+	ids      map[string]string
+	endKeys  map[string][2]string
+	rejected map[string]bool
+}
+
+func newCandidateBuilder(workspaceID string, run *ExtractionRun, text string) *candidateBuilder {
+	return &candidateBuilder{
+		workspaceID: workspaceID,
+		run:         run,
+		text:        []rune(text),
+		now:         time.Now(),
+		byKey:       make(map[string]*Candidate),
+		ids:         make(map[string]string),
+		endKeys:     make(map[string][2]string),
+		rejected:    make(map[string]bool),
+	}
+}
+
+func entityKey(entityType, name string) string {
+	return "entity:" + entityType + ":" + strings.ToLower(name)
+}
+
+func (b *candidateBuilder) addEntity(entity ExtractedEntity) {
+	key := entityKey(entity.Type, entity.Name)
+	b.add(key, entity.Start, entity.End, entity.Confidence, func() *Candidate {
+		return &Candidate{Kind: CandidateKindEntity, Type: entity.Type, Name: entity.Name, Rule: entity.Rule}
+	})
+}
+
+func (b *candidateBuilder) addRelation(relation ExtractedRelation, entities []ExtractedEntity) {
+	source, target := entities[relation.Source], entities[relation.Target]
+	sourceKey := entityKey(source.Type, source.Name)
+	targetKey := entityKey(target.Type, target.Name)
+	key := "relation:" + relation.Type + ":" + sourceKey + ":" + targetKey
+
+	b.endKeys[key] = [2]string{sourceKey, targetKey}
+	b.add(key, relation.Start, relation.End, relation.Confidence, func() *Candidate {
+		return &Candidate{
+			Kind:       CandidateKindRelation,
+			Type:       relation.Type,
+			SourceName: source.Name,
+			TargetName: target.Name,
+			Rule:       relation.Rule,
+		}
+	})
+}
+
+// add records a mention of the candidate with key, creating the candidate on
+// its first mention. Confidence is the highest of any mention.
+func (b *candidateBuilder) add(key string, start, end int, confidence float64, create func() *Candidate) {
+	span := Span{Start: start, End: end}
+
+	if candidate, ok := b.byKey[key]; ok {
+		candidate.Mentions = append(candidate.Mentions, span)
+		candidate.Confidence = max(candidate.Confidence, confidence)
+		return
+	}
+
+	candidate := create()
+	candidate.ID = primitive.NewObjectID()
+	candidate.WorkspaceID = b.workspaceID
+	candidate.DocumentID = b.run.DocumentID
+	candidate.DocumentVersion = b.run.DocumentVersion
+	candidate.Status = CandidateStatusPending
+	candidate.Key = key
+	candidate.Mentions = []Span{span}
+	candidate.Snippet = snippet(b.text, start, end)
+	candidate.Confidence = confidence
+	candidate.Extractor = b.run.Extractor
+	candidate.ExtractorVersion = b.run.ExtractorVersion
+	candidate.RulesVersion = b.run.RulesVersion
+	candidate.CreatedAt = b.now
+	candidate.UpdatedAt = b.now
+
+	b.list = append(b.list, candidate)
+	b.byKey[key] = candidate
+	b.ids[key] = candidate.ID.Hex()
+}
+
+// normalizeRules checks the rule types and expressions and fills in default
+// confidences and scopes.
+func normalizeRules(req *UpdateRulesRequest) (*RuleSet, error) {
+	if len(req.Gazetteers) > MaxGazetteers {
+		return nil, fmt.Errorf("invalid rules: at most %d gazetteers", MaxGazetteers)
+	}
+	if len(req.Patterns) > MaxPatternRules {
+		return nil, fmt.Errorf("invalid rules: at most %d patterns", MaxPatternRules)
+	}
+	if len(req.Cooccurrences) > MaxCooccurrenceRules {
+		return nil, fmt.Errorf("invalid rules: at most %d co-occurrence rules", MaxCooccurrenceRules)
+	}
+
+	rules := &RuleSet{
+		Gazetteers:    make([]Gazetteer, len(req.Gazetteers)),
+		Patterns:      make([]PatternRule, len(req.Patterns)),
+		Cooccurrences: make([]CooccurrenceRule, len(req.Cooccurrences)),
+	}
+
+	entries := 0
+	for i, gazetteer := range req.Gazetteers {
+		entityType, err := normalizeEntityType(gazetteer.EntityType)
+		if err != nil {
+			return nil, err
+		}
+		entries += len(gazetteer.Entries)
+		if entries > MaxGazetteerEntries {
+			return nil, fmt.Errorf("invalid rules: at most %d gazetteer entries", MaxGazetteerEntries)
+		}
+		for _, entry := range gazetteer.Entries {
+			if strings.TrimSpace(entry.Name) == "" {
+				return nil, fmt.Errorf("invalid rules: gazetteer %s has an entry without a name", entityType)
+			}
+		}
+
+		gazetteer.EntityType = entityType
+		gazetteer.Confidence = confidenceOrDefault(gazetteer.Confidence, defaultGazetteerConfidence)
+		rules.Gazetteers[i] = gazetteer
+	}
+
+	names := make(map[string]bool, len(req.Patterns))
+	for i, pattern := range req.Patterns {
+		entityType, err := normalizeEntityType(pattern.EntityType)
+		if err != nil {
+			return nil, err
+		}
+		if names[pattern.Name] {
+			return nil, fmt.Errorf("invalid rules: pattern %q is defined twice", pattern.Name)
+		}
+		names[pattern.Name] = true
+		if len(pattern.Expression) > MaxPatternLength {
+			return nil, fmt.Errorf("invalid rules: pattern %q is longer than %d characters", pattern.Name, MaxPatternLength)
+		}
+
+		pattern.EntityType = entityType
+		pattern.Confidence = confidenceOrDefault(pattern.Confidence, defaultPatternConfidence)
+		rules.Patterns[i] = pattern
+	}
+
+	for i, rule := range req.Cooccurrences {
+		relationType, err := normalizeRelationType(rule.RelationType)
+		if err != nil {
+			return nil, err
+		}
+		if rule.SourceType, err = normalizeEntityType(rule.SourceType); err != nil {
+			return nil, err
+		}
+		if rule.TargetType, err = normalizeEntityType(rule.TargetType); err != nil {
+			return nil, err
+		}
+		if rule.Scope == "" {
+			rule.Scope = ScopeSentence
+		}
+
+		rule.RelationType = relationType
+		rule.Confidence = confidenceOrDefault(rule.Confidence, defaultCooccurrenceConfidence)
+		rules.Cooccurrences[i] = rule
+	}
+
+	// Compiling checks the expressions and their groups.
+	if _, err := compileRules(rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func confidenceOrDefault(confidence, fallback float64) float64 {
+	if confidence <= 0 {
+		return fallback
+	}
+	return confidence
+}
@@ -0,0 +1,361 @@
+package knowledge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/module/document"
+)
+
+const testDocumentID = "64b7f0c2e4b0a1a2b3c4d5e8"
+
+type extractionMocks struct {
+	repo       *MockKnowledgeRepository
+	rules      *MockRuleSetRepository
+	candidates *MockCandidateRepository
+	settings   *MockExtractionSettingsRepository
+	documents  *MockDocumentTextSource
+	evidence   *MockEvidenceRepository
+}
+
+// setupExtractionService returns a service with the rules extractor and the
+// given extractors, for a workspace without a schema or settings.
+func setupExtractionService(extractors ...Extractor) (*extractionService, *extractionMocks) {
+	knowledge, mockRepo := setupKnowledgeService()
+	mocks := &extractionMocks{
+		repo:       mockRepo,
+		rules:      &MockRuleSetRepository{},
+		candidates: &MockCandidateRepository{},
+		settings:   &MockExtractionSettingsRepository{},
+		documents:  &MockDocumentTextSource{},
+		evidence:   knowledge.evidence.(*MockEvidenceRepository),
+	}
+	mocks.settings.On("Get", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+
+	registry := NewExtractorRegistry()
+	_ = registry.Register(newRuleExtractor(mocks.rules))
+	for _, extractor := range extractors {
+		_ = registry.Register(extractor)
+	}
+
+	return newExtractionService(mocks.rules, mocks.candidates, mocks.settings, registry, knowledge, mocks.documents), mocks
+}
+
+func testRuleSet(t *testing.T) *RuleSet {
+	rules, err := normalizeRules(&UpdateRulesRequest{
+		Gazetteers: []Gazetteer{
+			{EntityType: "Person", Entries: []GazetteerEntry{{Name: "Ada"}, {Name: "Charles"}}},
+			{EntityType: "Organization", Entries: []GazetteerEntry{{Name: "Acme"}}},
+		},
+		Cooccurrences: []CooccurrenceRule{
+			{RelationType: "WORKS_AT", SourceType: "Person", TargetType: "Organization"},
+		},
+	})
+	require.NoError(t, err)
+
+	rules.WorkspaceID = testWorkspaceID
+	rules.Version = 2
+	return rules
+}
+
+func TestExtractionService_UpdateRules(t *testing.T) {
+	service, mocks := setupExtractionService()
+
+	mocks.rules.On("GetLatest", mock.Anything, testWorkspaceID).Return(testRuleSet(t), nil)
+	mocks.rules.On("Create", mock.Anything, mock.MatchedBy(func(rules *RuleSet) bool {
+		return rules.Version == 3 && rules.CreatedBy == testAccountID && rules.Patterns[0].Confidence == defaultPatternConfidence
+	})).Return(testRuleSet(t), nil)
+
+	_, err := service.UpdateRules(context.Background(), testWorkspaceID, testAccountID, &UpdateRulesRequest{
+		Patterns: []PatternRule{{Name: "year", EntityType: "Year", Expression: `\d{4}`}},
+	})
+	require.NoError(t, err)
+	mocks.rules.AssertExpectations(t)
+}
+
+func TestExtractionService_ExtractDocument(t *testing.T) {
+	service, mocks := setupExtractionService()
+
+	rejected := CreateTestCandidate(func(c *Candidate) {
+		c.Key = entityKey("Person", "Charles")
+		c.Name = "Charles"
+		c.Status = CandidateStatusRejected
+	})
+
+	mocks.rules.On("GetLatest", mock.Anything, testWorkspaceID).Return(testRuleSet(t), nil)
+	mocks.documents.On("GetResult", mock.Anything, testWorkspaceID, testDocumentID, 0).Return(&document.ExtractionResult{
+		DocumentID: testDocumentID,
+		Version:    4,
+		Text:       "Ada works at Acme. Charles works at Acme too.",
+	}, nil)
+	mocks.candidates.On("ListReviewed", mock.Anything, testWorkspaceID, testDocumentID).Return([]*Candidate{rejected}, nil)
+	mocks.candidates.On("DeletePending", mock.Anything, testWorkspaceID, testDocumentID).Return(nil)
+
+	var created []*Candidate
+	mocks.candidates.On("Create", mock.Anything, mock.MatchedBy(func(c *Candidate) bool {
+		created = append(created, c)
+		return true
+	})).Return(CreateTestCandidate(), nil)
+
+	run, err := service.ExtractDocument(context.Background(), testWorkspaceID, &ExtractDocumentRequest{DocumentID: testDocumentID})
+	require.NoError(t, err)
+
+	assert.Equal(t, &ExtractionRun{
+		DocumentID:       testDocumentID,
+		DocumentVersion:  4,
+		Extractor:        RuleExtractor,
+		ExtractorVersion: RuleExtractorVersion,
+		RulesVersion:     2,
+		Entities:         2,
+		Relations:        1,
+		Skipped:          2,
+	}, run)
+
+	require.Len(t, created, 3)
+	ada, acme, worksAt := created[0], created[1], created[2]
+	assert.Equal(t, "Ada", ada.Name)
+	assert.Equal(t, []Span{{Start: 0, End: 3}}, ada.Mentions)
+	assert.Equal(t, "Acme", acme.Name)
+	assert.Equal(t, []Span{{Start: 13, End: 17}, {Start: 36, End: 40}}, acme.Mentions)
+	assert.Equal(t, CandidateKindRelation, worksAt.Kind)
+	assert.Equal(t, ada.ID.Hex(), worksAt.SourceCandidateID)
+	assert.Equal(t, acme.ID.Hex(), worksAt.TargetCandidateID)
+	assert.Equal(t, CandidateStatusPending, worksAt.Status)
+	assert.Equal(t, 4, worksAt.DocumentVersion)
+	assert.Equal(t, RuleExtractor, worksAt.Extractor)
+}
+
+func TestExtractionService_ExtractDocument_NoRules(t *testing.T) {
+	service, mocks := setupExtractionService()
+
+	mocks.rules.On("GetLatest", mock.Anything, testWorkspaceID).Return(nil, nil)
+	mocks.documents.On("GetResult", mock.Anything, testWorkspaceID, testDocumentID, 0).Return(&document.ExtractionResult{Version: 1, Text: "Ada"}, nil)
+
+	_, err := service.ExtractDocument(context.Background(), testWorkspaceID, &ExtractDocumentRequest{DocumentID: testDocumentID})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestExtractionService_AcceptCandidate_Entity(t *testing.T) {
+	t.Run("creates the entity with provenance", func(t *testing.T) {
+		service, mocks := setupExtractionService()
+		candidate := CreateTestCandidate()
+
+		mocks.candidates.On("GetByID", mock.Anything, testWorkspaceID, candidate.ID).Return(candidate, nil)
+		mocks.repo.On("FindEntityByName", mock.Anything, testWorkspaceID, "Person", "Ada Lovelace").Return(nil, nil)
+		mocks.repo.On("CreateEntity", mock.Anything, testWorkspaceID, testAccountID, "Person", map[string]interface{}{
+			"name":                        "Ada Lovelace",
+			propertySourceDocumentID:      testDocumentID,
+			propertySourceDocumentVersion: int64(1),
+			propertySourceStart:           int64(0),
+			propertySourceEnd:             int64(12),
+			propertyExtractor:             RuleExtractor,
+			propertyExtractorVersion:      RuleExtractorVersion,
+		}).Return(CreateTestEntity(), nil)
+		mocks.candidates.On("Review", mock.Anything, testWorkspaceID, candidate.ID, CandidateStatusAccepted, "4:test:1", testAccountID).
+			Return(CreateTestCandidate(func(c *Candidate) { c.Status = CandidateStatusAccepted; c.FactID = "4:test:1" }), nil)
+
+		accepted, err := service.AcceptCandidate(context.Background(), testWorkspaceID, testAccountID, candidate.ID.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, "4:test:1", accepted.FactID)
+		mocks.repo.AssertExpectations(t)
+	})
+
+	t.Run("adds evidence to an existing entity", func(t *testing.T) {
+		service, mocks := setupExtractionService()
+		candidate := CreateTestCandidate()
+
+		mocks.candidates.On("GetByID", mock.Anything, testWorkspaceID, candidate.ID).Return(candidate, nil)
+		mocks.repo.On("FindEntityByName", mock.Anything, testWorkspaceID, "Person", "Ada Lovelace").
+			Return(CreateTestEntity(func(e *Entity) { e.ID = "4:test:9" }), nil)
+		mocks.candidates.On("Review", mock.Anything, testWorkspaceID, candidate.ID, CandidateStatusAccepted, "4:test:9", testAccountID).
+			Return(CreateTestCandidate(func(c *Candidate) { c.Status = CandidateStatusAccepted; c.FactID = "4:test:9" }), nil)
+
+		accepted, err := service.AcceptCandidate(context.Background(), testWorkspaceID, testAccountID, candidate.ID.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, "4:test:9", accepted.FactID)
+		mocks.repo.AssertNotCalled(t, "CreateEntity", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mocks.evidence.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(evidence *Evidence) bool {
+			return evidence.FactKind == FactKindEntity && evidence.FactID == "4:test:9" &&
+				evidence.Source == EvidenceSourceDocument && evidence.DocumentID == testDocumentID
+		}))
+	})
+
+	t.Run("already rejected", func(t *testing.T) {
+		service, mocks := setupExtractionService()
+		candidate := CreateTestCandidate(func(c *Candidate) { c.Status = CandidateStatusRejected })
+
+		mocks.candidates.On("GetByID", mock.Anything, testWorkspaceID, candidate.ID).Return(candidate, nil)
+
+		_, err := service.AcceptCandidate(context.Background(), testWorkspaceID, testAccountID, candidate.ID.Hex())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already rejected")
+	})
+}
+
+func TestExtractionService_AcceptCandidate_Relation(t *testing.T) {
+	service, mocks := setupExtractionService()
+
+	source := CreateTestCandidate()
+	target := CreateTestCandidate(func(c *Candidate) {
+		c.Type = "Organization"
+		c.Name = "Acme"
+		c.Status = CandidateStatusAccepted
+		c.FactID = "4:test:2"
+	})
+	relation := CreateTestCandidate(func(c *Candidate) {
+		c.Kind = CandidateKindRelation
+		c.Type = "WORKS_AT"
+		c.Name = ""
+		c.SourceCandidateID = source.ID.Hex()
+		c.TargetCandidateID = target.ID.Hex()
+	})
+
+	mocks.candidates.On("GetByID", mock.Anything, testWorkspaceID, relation.ID).Return(relation, nil)
+	mocks.candidates.On("GetByID", mock.Anything, testWorkspaceID, source.ID).Return(source, nil)
+	mocks.candidates.On("GetByID", mock.Anything, testWorkspaceID, target.ID).Return(target, nil)
+	mocks.repo.On("FindEntityByName", mock.Anything, testWorkspaceID, "Person", "Ada Lovelace").Return(nil, nil)
+	mocks.repo.On("CreateEntity", mock.Anything, testWorkspaceID, testAccountID, "Person", mock.Anything).Return(CreateTestEntity(), nil)
+	mocks.candidates.On("Review", mock.Anything, testWorkspaceID, source.ID, CandidateStatusAccepted, "4:test:1", testAccountID).
+		Return(CreateTestCandidate(func(c *Candidate) { c.Status = CandidateStatusAccepted; c.FactID = "4:test:1" }), nil)
+	mocks.repo.On("FindRelation", mock.Anything, testWorkspaceID, "4:test:1", "4:test:2", "WORKS_AT").Return(nil, nil)
+	mocks.repo.On("CreateRelation", mock.Anything, testWorkspaceID, testAccountID, "4:test:1", "4:test:2", "WORKS_AT", mock.MatchedBy(func(properties map[string]interface{}) bool {
+		return properties[propertyExtractor] == RuleExtractor
+	})).Return(&Relation{ID: "5:test:1", Type: "WORKS_AT"}, nil)
+	mocks.candidates.On("Review", mock.Anything, testWorkspaceID, relation.ID, CandidateStatusAccepted, "5:test:1", testAccountID).
+		Return(CreateTestCandidate(func(c *Candidate) { c.Status = CandidateStatusAccepted; c.FactID = "5:test:1" }), nil)
+
+	accepted, err := service.AcceptCandidate(context.Background(), testWorkspaceID, testAccountID, relation.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, "5:test:1", accepted.FactID)
+	mocks.repo.AssertExpectations(t)
+	mocks.candidates.AssertExpectations(t)
+	mocks.evidence.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(evidence *Evidence) bool {
+		return evidence.FactKind == FactKindRelation && evidence.FactID == "5:test:1" &&
+			evidence.SourceEntityID == "4:test:1" && evidence.TargetEntityID == "4:test:2" &&
+			evidence.CandidateID == relation.ID.Hex() && evidence.AccountID == testAccountID
+	}))
+}
+
+func TestExtractionService_RejectCandidate(t *testing.T) {
+	t.Run("cascades to pending relations", func(t *testing.T) {
+		service, mocks := setupExtractionService()
+		candidate := CreateTestCandidate()
+		relation := CreateTestCandidate(func(c *Candidate) { c.Kind = CandidateKindRelation })
+
+		mocks.candidates.On("GetByID", mock.Anything, testWorkspaceID, candidate.ID).Return(candidate, nil)
+		mocks.candidates.On("Review", mock.Anything, testWorkspaceID, candidate.ID, CandidateStatusRejected, "", testAccountID).
+			Return(CreateTestCandidate(func(c *Candidate) { c.Status = CandidateStatusRejected }), nil)
+		mocks.candidates.On("ListPendingRelations", mock.Anything, testWorkspaceID, candidate.ID.Hex()).Return([]*Candidate{relation}, nil)
+		mocks.candidates.On("Review", mock.Anything, testWorkspaceID, relation.ID, CandidateStatusRejected, "", testAccountID).Return(relation, nil)
+
+		rejected, err := service.RejectCandidate(context.Background(), testWorkspaceID, testAccountID, candidate.ID.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, CandidateStatusRejected, rejected.Status)
+		mocks.candidates.AssertExpectations(t)
+	})
+
+	t.Run("already reviewed", func(t *testing.T) {
+		service, mocks := setupExtractionService()
+		candidate := CreateTestCandidate()
+
+		mocks.candidates.On("GetByID", mock.Anything, testWorkspaceID, candidate.ID).Return(candidate, nil)
+		mocks.candidates.On("Review", mock.Anything, testWorkspaceID, candidate.ID, CandidateStatusRejected, "", testAccountID).Return(nil, nil)
+
+		_, err := service.RejectCandidate(context.Background(), testWorkspaceID, testAccountID, candidate.ID.Hex())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already reviewed")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		service, mocks := setupExtractionService()
+		id := primitive.NewObjectID()
+
+		mocks.candidates.On("GetByID", mock.Anything, testWorkspaceID, id).Return(nil, nil)
+
+		_, err := service.RejectCandidate(context.Background(), testWorkspaceID, testAccountID, id.Hex())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+}
+
+// staticExtractor returns the same output for every document.
+type staticExtractor struct {
+	output *ExtractorOutput
+	input  *ExtractorInput
+}
+
+func (e *staticExtractor) Name() string {
+	return "ner"
+}
+
+func (e *staticExtractor) Version() string {
+	return "1.2.0"
+}
+
+func (e *staticExtractor) Extract(ctx context.Context, input *ExtractorInput) (*ExtractorOutput, error) {
+	e.input = input
+	return e.output, nil
+}
+
+func TestExtractionService_ExtractDocument_WorkspaceExtractor(t *testing.T) {
+	extractor := &staticExtractor{output: &ExtractorOutput{
+		Version: "1.2.1",
+		Entities: []ExtractedEntity{
+			{Type: "Person", Name: "Ada", Start: 0, End: 3, Confidence: 0.7, Rule: "extractor:Person"},
+			{Type: "Person", Name: "ada", Start: 10, End: 13, Confidence: 0.9, Rule: "extractor:Person"},
+		},
+		Discarded: 1,
+	}}
+	service, mocks := setupExtractionService(extractor)
+
+	mocks.settings.ExpectedCalls = nil
+	mocks.settings.On("Get", mock.Anything, testWorkspaceID).Return(&ExtractionSettings{WorkspaceID: testWorkspaceID, Extractor: "ner"}, nil)
+	mocks.documents.On("GetResult", mock.Anything, testWorkspaceID, testDocumentID, 0).Return(&document.ExtractionResult{Version: 2, Text: "Ada, then ada."}, nil)
+	mocks.candidates.On("ListReviewed", mock.Anything, testWorkspaceID, testDocumentID).Return([]*Candidate{}, nil)
+	mocks.candidates.On("DeletePending", mock.Anything, testWorkspaceID, testDocumentID).Return(nil)
+	mocks.candidates.On("Create", mock.Anything, mock.MatchedBy(func(c *Candidate) bool {
+		return c.Extractor == "ner" && c.ExtractorVersion == "1.2.1" && c.RulesVersion == 0 &&
+			c.Confidence == 0.9 && len(c.Mentions) == 2
+	})).Return(CreateTestCandidate(), nil).Once()
+
+	run, err := service.ExtractDocument(context.Background(), testWorkspaceID, &ExtractDocumentRequest{DocumentID: testDocumentID})
+	require.NoError(t, err)
+	assert.Equal(t, "ner", run.Extractor)
+	assert.Equal(t, "1.2.1", run.ExtractorVersion)
+	assert.Equal(t, 1, run.Entities)
+	assert.Equal(t, 1, run.Discarded)
+	assert.Equal(t, 2, extractor.input.DocumentVersion)
+	mocks.candidates.AssertExpectations(t)
+	mocks.rules.AssertNotCalled(t, "GetLatest", mock.Anything, mock.Anything)
+}
+
+func TestExtractionService_Settings(t *testing.T) {
+	service, mocks := setupExtractionService(&staticExtractor{})
+
+	settings, err := service.GetSettings(context.Background(), testWorkspaceID)
+	require.NoError(t, err)
+	assert.Equal(t, RuleExtractor, settings.Extractor)
+
+	_, err = service.UpdateSettings(context.Background(), testWorkspaceID, testAccountID, &UpdateExtractionSettingsRequest{Extractor: "llm"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid extractor")
+
+	mocks.settings.On("Set", mock.Anything, mock.MatchedBy(func(s *ExtractionSettings) bool {
+		return s.Extractor == "ner" && s.UpdatedBy == testAccountID
+	})).Return(&ExtractionSettings{Extractor: "ner"}, nil)
+
+	settings, err = service.UpdateSettings(context.Background(), testWorkspaceID, testAccountID, &UpdateExtractionSettingsRequest{Extractor: "ner"})
+	require.NoError(t, err)
+	assert.Equal(t, "ner", settings.Extractor)
+
+	assert.Equal(t, []*ExtractorInfo{
+		{Name: "ner", Version: "1.2.0"},
+		{Name: RuleExtractor, Version: RuleExtractorVersion, Builtin: true},
+	}, service.ListExtractors())
+}
@@ -0,0 +1,189 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Extractor finds entities and relations in the text of a document. The
+// built-in rules are one; services reached over HTTP are others. Offsets in
+// the output are characters of ExtractorInput.Text, end exclusive.
+type Extractor interface {
+	Name() string
+	Version() string
+	Extract(ctx context.Context, input *ExtractorInput) (*ExtractorOutput, error)
+}
+
+// ExtractorInput is one document version to extract from. EntityTypes and
+// RelationTypes come from the workspace schema, when it has one, so that
+// extractors can restrict themselves to the types the graph accepts.
+type ExtractorInput struct {
+	WorkspaceID     string
+	DocumentID      string
+	DocumentVersion int
+	Text            string
+	EntityTypes     []string
+	RelationTypes   []string
+}
+
+// ExtractedEntity is one mention of an entity.
+type ExtractedEntity struct {
+	Type       string
+	Name       string
+	Start      int
+	End        int
+	Confidence float64
+	Rule       string
+}
+
+// ExtractedRelation is one mention of a relation. Source and Target are
+// indexes into ExtractorOutput.Entities.
+type ExtractedRelation struct {
+	Type       string
+	Source     int
+	Target     int
+	Start      int
+	End        int
+	Confidence float64
+	Rule       string
+}
+
+// ExtractorOutput is what an extractor found. Version, when set, replaces the
+// version the extractor reported before the run, and RulesVersion is only
+// set by the built-in rules.
+type ExtractorOutput struct {
+	Version      string
+	RulesVersion int
+	Entities     []ExtractedEntity
+	Relations    []ExtractedRelation
+	Discarded    int
+}
+
+type ExtractorInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Builtin bool   `json:"builtin"`
+}
+
+// ExtractorRegistry holds the extractors workspaces can choose from.
+type ExtractorRegistry struct {
+	mu         sync.RWMutex
+	extractors map[string]Extractor
+}
+
+func NewExtractorRegistry() *ExtractorRegistry {
+	return &ExtractorRegistry{
+		extractors: make(map[string]Extractor),
+	}
+}
+
+func (r *ExtractorRegistry) Register(extractor Extractor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.extractors[extractor.Name()]; exists {
+		return fmt.Errorf("extractor %s is already registered", extractor.Name())
+	}
+	r.extractors[extractor.Name()] = extractor
+
+	return nil
+}
+
+func (r *ExtractorRegistry) Get(name string) (Extractor, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	extractor, ok := r.extractors[name]
+	return extractor, ok
+}
+
+// List returns the registered extractors sorted by name.
+func (r *ExtractorRegistry) List() []*ExtractorInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	infos := make([]*ExtractorInfo, 0, len(r.extractors))
+	for name, extractor := range r.extractors {
+		_, builtin := extractor.(*ruleExtractor)
+		infos = append(infos, &ExtractorInfo{
+			Name:    name,
+			Version: extractor.Version(),
+			Builtin: builtin,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+	return infos
+}
+
+// ruleExtractor applies the latest rule set of the workspace.
+type ruleExtractor struct {
+	rules RuleSetRepository
+}
+
+var _ Extractor = (*ruleExtractor)(nil)
+
+func newRuleExtractor(rules RuleSetRepository) *ruleExtractor {
+	return &ruleExtractor{rules: rules}
+}
+
+func (e *ruleExtractor) Name() string {
+	return RuleExtractor
+}
+
+func (e *ruleExtractor) Version() string {
+	return RuleExtractorVersion
+}
+
+func (e *ruleExtractor) Extract(ctx context.Context, input *ExtractorInput) (*ExtractorOutput, error) {
+	rules, err := e.rules.GetLatest(ctx, input.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		return nil, fmt.Errorf("extraction rules not found")
+	}
+
+	matcher, err := compileRules(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	mentions, relations := matcher.extract(input.Text)
+	offsets := &runeOffsets{text: input.Text}
+
+	output := &ExtractorOutput{
+		RulesVersion: rules.Version,
+		Entities:     make([]ExtractedEntity, len(mentions)),
+		Relations:    make([]ExtractedRelation, len(relations)),
+	}
+
+	indexes := make(map[*mention]int, len(mentions))
+	for i, mention := range mentions {
+		indexes[mention] = i
+		output.Entities[i] = ExtractedEntity{
+			Type:       mention.entityType,
+			Name:       mention.name,
+			Start:      offsets.at(mention.start),
+			End:        offsets.at(mention.end),
+			Confidence: mention.confidence,
+			Rule:       mention.rule,
+		}
+	}
+
+	for i, relation := range relations {
+		output.Relations[i] = ExtractedRelation{
+			Type:       relation.relationType,
+			Source:     indexes[relation.source],
+			Target:     indexes[relation.target],
+			Start:      offsets.at(relation.start),
+			End:        offsets.at(relation.end),
+			Confidence: relation.confidence,
+			Rule:       relation.rule,
+		}
+	}
+
+	return output, nil
+}
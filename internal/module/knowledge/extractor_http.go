@@ -0,0 +1,394 @@
+package knowledge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	DefaultExtractorTimeout     = 30 * time.Second
+	DefaultExtractorMaxAttempts = 3
+	DefaultExtractorRetryDelay  = 500 * time.Millisecond
+	DefaultExtractorBatchSize   = 8
+	DefaultExtractorSegmentSize = 2000
+
+	// defaultExternalConfidence is used for results without a confidence.
+	defaultExternalConfidence = 0.5
+	maxExtractorResponseSize  = 32 << 20
+)
+
+var extractorNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// HTTPExtractorConfig configures an extraction service reached over HTTP.
+// Text is sent in segments of at most SegmentSize characters, cut at
+// paragraph breaks where possible, with up to BatchSize segments per
+// request. Each request gets Timeout and is retried with a doubling delay
+// after network errors, 429 and 5xx responses, up to MaxAttempts in total.
+type HTTPExtractorConfig struct {
+	Name        string        `json:"name"`
+	URL         string        `json:"url"`
+	Token       string        `json:"-"`
+	Version     string        `json:"version"`
+	Timeout     time.Duration `json:"timeout"`
+	MaxAttempts int           `json:"max_attempts"`
+	RetryDelay  time.Duration `json:"retry_delay"`
+	BatchSize   int           `json:"batch_size"`
+	SegmentSize int           `json:"segment_size"`
+}
+
+// The HTTP extractor contract. The service receives a POST with a JSON
+// ExtractorRequest and the bearer token, when one is configured, and answers
+// 200 with a JSON ExtractorResponse holding one result per segment.
+//
+// Offsets in a result are characters of the segment text, end exclusive, and
+// relations refer to entities by their ID within the same result. Entities
+// without a name take the text they cover, and results without a confidence
+// get 0.5. Results with unknown segments, offsets outside the segment,
+// invalid types or missing entities are discarded.
+type ExtractorRequest struct {
+	Extractor       string             `json:"extractor"`
+	WorkspaceID     string             `json:"workspace_id"`
+	DocumentID      string             `json:"document_id"`
+	DocumentVersion int                `json:"document_version"`
+	EntityTypes     []string           `json:"entity_types,omitempty"`
+	RelationTypes   []string           `json:"relation_types,omitempty"`
+	Segments        []ExtractorSegment `json:"segments"`
+}
+
+// ExtractorSegment is a piece of the document text starting at Offset
+// characters into it.
+type ExtractorSegment struct {
+	ID     int    `json:"id"`
+	Offset int    `json:"offset"`
+	Text   string `json:"text"`
+}
+
+type ExtractorResponse struct {
+	Version string                   `json:"version,omitempty"`
+	Results []ExtractorSegmentResult `json:"results"`
+}
+
+type ExtractorSegmentResult struct {
+	SegmentID int                `json:"segment_id"`
+	Entities  []ExternalEntity   `json:"entities"`
+	Relations []ExternalRelation `json:"relations"`
+}
+
+type ExternalEntity struct {
+	ID         string   `json:"id"`
+	Type       string   `json:"type"`
+	Name       string   `json:"name,omitempty"`
+	Start      int      `json:"start"`
+	End        int      `json:"end"`
+	Confidence *float64 `json:"confidence,omitempty"`
+}
+
+type ExternalRelation struct {
+	Type       string   `json:"type"`
+	Source     string   `json:"source"`
+	Target     string   `json:"target"`
+	Start      *int     `json:"start,omitempty"`
+	End        *int     `json:"end,omitempty"`
+	Confidence *float64 `json:"confidence,omitempty"`
+}
+
+type httpExtractor struct {
+	config HTTPExtractorConfig
+	client *http.Client
+}
+
+var _ Extractor = (*httpExtractor)(nil)
+
+// NewHTTPExtractor checks config and fills in its defaults.
+func NewHTTPExtractor(config HTTPExtractorConfig) (Extractor, error) {
+	if !extractorNamePattern.MatchString(config.Name) || config.Name == RuleExtractor {
+		return nil, fmt.Errorf("invalid extractor name %q", config.Name)
+	}
+
+	endpoint, err := url.Parse(config.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid URL for extractor %s", config.Name)
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultExtractorTimeout
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultExtractorMaxAttempts
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = DefaultExtractorRetryDelay
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultExtractorBatchSize
+	}
+	if config.SegmentSize <= 0 {
+		config.SegmentSize = DefaultExtractorSegmentSize
+	}
+
+	return &httpExtractor{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+func (e *httpExtractor) Name() string {
+	return e.config.Name
+}
+
+func (e *httpExtractor) Version() string {
+	return e.config.Version
+}
+
+func (e *httpExtractor) Extract(ctx context.Context, input *ExtractorInput) (*ExtractorOutput, error) {
+	text := []rune(input.Text)
+	segments := splitSegments(text, e.config.SegmentSize)
+	output := &ExtractorOutput{}
+
+	for start := 0; start < len(segments); start += e.config.BatchSize {
+		batch := segments[start:min(start+e.config.BatchSize, len(segments))]
+
+		response, err := e.send(ctx, &ExtractorRequest{
+			Extractor:       e.config.Name,
+			WorkspaceID:     input.WorkspaceID,
+			DocumentID:      input.DocumentID,
+			DocumentVersion: input.DocumentVersion,
+			EntityTypes:     input.EntityTypes,
+			RelationTypes:   input.RelationTypes,
+			Segments:        batch,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if response.Version != "" {
+			output.Version = response.Version
+		}
+		output.normalize(text, batch, response.Results)
+	}
+
+	return output, nil
+}
+
+// send posts one batch, retrying failures that may be temporary.
+func (e *httpExtractor) send(ctx context.Context, request *ExtractorRequest) (*ExtractorResponse, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode extractor request: %w", err)
+	}
+
+	delay := e.config.RetryDelay
+	for attempt := 1; ; attempt++ {
+		response, err := e.post(ctx, body)
+		if err == nil {
+			return response, nil
+		}
+
+		var temporary *temporaryError
+		if !errors.As(err, &temporary) || attempt >= e.config.MaxAttempts {
+			return nil, fmt.Errorf("extractor %s unavailable: %w", e.config.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// temporaryError marks failures worth retrying.
+type temporaryError struct {
+	err error
+}
+
+func (e *temporaryError) Error() string {
+	return e.err.Error()
+}
+
+func (e *temporaryError) Unwrap() error {
+	return e.err
+}
+
+func (e *httpExtractor) post(ctx context.Context, body []byte) (*ExtractorResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create extractor request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if e.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+e.config.Token)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &temporaryError{err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxExtractorResponseSize))
+		err := fmt.Errorf("unexpected status %d", resp.StatusCode)
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return nil, &temporaryError{err: err}
+		}
+		return nil, err
+	}
+
+	var response ExtractorResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxExtractorResponseSize)).Decode(&response); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// normalize adds the results for a batch of segments to the output, with
+// offsets moved from the segments to the whole text.
+func (o *ExtractorOutput) normalize(text []rune, batch []ExtractorSegment, results []ExtractorSegmentResult) {
+	byID := make(map[int]ExtractorSegment, len(batch))
+	for _, segment := range batch {
+		byID[segment.ID] = segment
+	}
+
+	for _, result := range results {
+		segment, ok := byID[result.SegmentID]
+		if !ok {
+			o.Discarded += len(result.Entities) + len(result.Relations)
+			continue
+		}
+		length := len([]rune(segment.Text))
+
+		indexes := make(map[string]int, len(result.Entities))
+		for _, entity := range result.Entities {
+			entityType, err := normalizeEntityType(entity.Type)
+			if err != nil || entity.Start < 0 || entity.End <= entity.Start || entity.End > length {
+				o.Discarded++
+				continue
+			}
+
+			start, end := segment.Offset+entity.Start, segment.Offset+entity.End
+			name := strings.Join(strings.Fields(entity.Name), " ")
+			if name == "" {
+				name = strings.Join(strings.Fields(string(text[start:end])), " ")
+			}
+			confidence, ok := externalConfidence(entity.Confidence)
+			if name == "" || !ok {
+				o.Discarded++
+				continue
+			}
+
+			if entity.ID != "" {
+				indexes[entity.ID] = len(o.Entities)
+			}
+			o.Entities = append(o.Entities, ExtractedEntity{
+				Type:       entityType,
+				Name:       name,
+				Start:      start,
+				End:        end,
+				Confidence: confidence,
+				Rule:       "extractor:" + entityType,
+			})
+		}
+
+		for _, relation := range result.Relations {
+			relationType, err := normalizeRelationType(relation.Type)
+			source, sourceOK := indexes[relation.Source]
+			target, targetOK := indexes[relation.Target]
+			confidence, confidenceOK := externalConfidence(relation.Confidence)
+			if err != nil || !sourceOK || !targetOK || source == target || !confidenceOK {
+				o.Discarded++
+				continue
+			}
+
+			// Without offsets of its own, a relation spans its two entities.
+			start := min(o.Entities[source].Start, o.Entities[target].Start)
+			end := max(o.Entities[source].End, o.Entities[target].End)
+			if relation.Start != nil || relation.End != nil {
+				if relation.Start == nil || relation.End == nil || *relation.Start < 0 || *relation.End <= *relation.Start || *relation.End > length {
+					o.Discarded++
+					continue
+				}
+				start, end = segment.Offset+*relation.Start, segment.Offset+*relation.End
+			}
+
+			o.Relations = append(o.Relations, ExtractedRelation{
+				Type:       relationType,
+				Source:     source,
+				Target:     target,
+				Start:      start,
+				End:        end,
+				Confidence: confidence,
+				Rule:       "extractor:" + relationType,
+			})
+		}
+	}
+}
+
+func externalConfidence(confidence *float64) (float64, bool) {
+	if confidence == nil {
+		return defaultExternalConfidence, true
+	}
+	if *confidence < 0 || *confidence > 1 {
+		return 0, false
+	}
+	return *confidence, true
+}
+
+// splitSegments cuts text into segments of at most size characters, after
+// the last paragraph break of each segment or else its last whitespace.
+// Segments holding only whitespace are left out.
+func splitSegments(text []rune, size int) []ExtractorSegment {
+	var segments []ExtractorSegment
+
+	for start := 0; start < len(text); {
+		end := min(start+size, len(text))
+		if end < len(text) {
+			if cut := lastBreak(text[start:end]); cut > 0 {
+				end = start + cut
+			}
+		}
+
+		if strings.TrimSpace(string(text[start:end])) != "" {
+			segments = append(segments, ExtractorSegment{
+				ID:     len(segments),
+				Offset: start,
+				Text:   string(text[start:end]),
+			})
+		}
+		start = end
+	}
+
+	return segments
+}
+
+// lastBreak returns the length of text up to and including its last
+// paragraph break, or else its last whitespace, or 0 without either.
+func lastBreak(text []rune) int {
+	for i := len(text) - 1; i > 0; i-- {
+		if text[i] == '\n' && text[i-1] == '\n' {
+			return i + 1
+		}
+	}
+	for i := len(text) - 1; i > 0; i-- {
+		if unicode.IsSpace(text[i]) {
+			return i + 1
+		}
+	}
+	return 0
+}
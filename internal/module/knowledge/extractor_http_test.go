@@ -0,0 +1,265 @@
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubExtractorServer answers extractor requests with respond, after failing
+// the first failures requests with failStatus.
+type stubExtractorServer struct {
+	server *httptest.Server
+
+	mu         sync.Mutex
+	requests   []ExtractorRequest
+	headers    []http.Header
+	failures   int
+	failStatus int
+	delay      time.Duration
+	respond    func(*ExtractorRequest) *ExtractorResponse
+}
+
+func newStubExtractorServer(t *testing.T, respond func(*ExtractorRequest) *ExtractorResponse) *stubExtractorServer {
+	stub := &stubExtractorServer{respond: respond}
+	stub.server = httptest.NewServer(http.HandlerFunc(stub.handle))
+	t.Cleanup(stub.server.Close)
+	return stub
+}
+
+func (s *stubExtractorServer) handle(w http.ResponseWriter, r *http.Request) {
+	var request ExtractorRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, request)
+	s.headers = append(s.headers, r.Header.Clone())
+	fail := len(s.requests) <= s.failures
+	s.mu.Unlock()
+
+	if s.delay > 0 {
+		time.Sleep(s.delay)
+	}
+	if fail {
+		w.WriteHeader(s.failStatus)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.respond(&request))
+}
+
+func (s *stubExtractorServer) attempts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+func confidence(value float64) *float64 {
+	return &value
+}
+
+// findNames reports every occurrence of Ada and Acme in each segment, and
+// Ada WORKS_AT Acme where both occur.
+func findNames(request *ExtractorRequest) *ExtractorResponse {
+	response := &ExtractorResponse{Version: "ner-2.1"}
+
+	for _, segment := range request.Segments {
+		result := ExtractorSegmentResult{SegmentID: segment.ID}
+		text := []rune(segment.Text)
+		for _, name := range []string{"Ada", "Acme"} {
+			entityType := "Person"
+			if name == "Acme" {
+				entityType = "Organization"
+			}
+			if i := strings.Index(segment.Text, name); i >= 0 {
+				start := len([]rune(segment.Text[:i]))
+				result.Entities = append(result.Entities, ExternalEntity{
+					ID:         name,
+					Type:       entityType,
+					Start:      start,
+					End:        start + len([]rune(name)),
+					Confidence: confidence(0.9),
+				})
+			}
+		}
+		if len(result.Entities) == 2 {
+			result.Relations = append(result.Relations, ExternalRelation{Type: "works_at", Source: "Ada", Target: "Acme"})
+		}
+
+		// Results the adapter must discard.
+		result.Entities = append(result.Entities, ExternalEntity{ID: "out", Type: "Person", Start: 0, End: len(text) + 1})
+		result.Relations = append(result.Relations, ExternalRelation{Type: "KNOWS", Source: "Ada", Target: "nobody"})
+		response.Results = append(response.Results, result)
+	}
+
+	return response
+}
+
+func TestHTTPExtractor_Extract(t *testing.T) {
+	stub := newStubExtractorServer(t, findNames)
+
+	extractor, err := NewHTTPExtractor(HTTPExtractorConfig{
+		Name:        "ner",
+		URL:         stub.server.URL,
+		Token:       "secret",
+		Version:     "ner-2.0",
+		SegmentSize: 24,
+		BatchSize:   2,
+	})
+	require.NoError(t, err)
+
+	text := "Ünïcode Ada at Acme.\n\nNothing here.\n\nAcme hired Ada."
+	output, err := extractor.Extract(context.Background(), &ExtractorInput{
+		WorkspaceID:     testWorkspaceID,
+		DocumentID:      testDocumentID,
+		DocumentVersion: 3,
+		Text:            text,
+		EntityTypes:     []string{"Person", "Organization"},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, 2, stub.attempts())
+	assert.Len(t, stub.requests[0].Segments, 2)
+	assert.Len(t, stub.requests[1].Segments, 1)
+	assert.Equal(t, 37, stub.requests[1].Segments[0].Offset)
+	assert.Equal(t, "ner", stub.requests[0].Extractor)
+	assert.Equal(t, 3, stub.requests[0].DocumentVersion)
+	assert.Equal(t, []string{"Person", "Organization"}, stub.requests[0].EntityTypes)
+	assert.Equal(t, "Bearer secret", stub.headers[0].Get("Authorization"))
+
+	assert.Equal(t, "ner-2.1", output.Version)
+	require.Len(t, output.Entities, 4)
+	for _, entity := range output.Entities {
+		assert.Equal(t, entity.Name, string([]rune(text)[entity.Start:entity.End]))
+		assert.Equal(t, 0.9, entity.Confidence)
+	}
+	assert.Equal(t, "Person", output.Entities[2].Type)
+	assert.Equal(t, 48, output.Entities[2].Start)
+
+	require.Len(t, output.Relations, 2)
+	assert.Equal(t, "WORKS_AT", output.Relations[0].Type)
+	assert.Equal(t, "Ada", output.Entities[output.Relations[0].Source].Name)
+	assert.Equal(t, "Acme", output.Entities[output.Relations[0].Target].Name)
+	assert.Equal(t, defaultExternalConfidence, output.Relations[0].Confidence)
+	assert.Equal(t, 8, output.Relations[0].Start)
+	assert.Equal(t, 19, output.Relations[0].End)
+
+	// One out-of-range entity and one dangling relation per segment.
+	assert.Equal(t, 6, output.Discarded)
+}
+
+func TestHTTPExtractor_Retries(t *testing.T) {
+	config := func(url string) HTTPExtractorConfig {
+		return HTTPExtractorConfig{Name: "ner", URL: url, MaxAttempts: 3, RetryDelay: time.Millisecond}
+	}
+
+	t.Run("temporary failures", func(t *testing.T) {
+		stub := newStubExtractorServer(t, findNames)
+		stub.failures, stub.failStatus = 2, http.StatusServiceUnavailable
+
+		extractor, err := NewHTTPExtractor(config(stub.server.URL))
+		require.NoError(t, err)
+
+		output, err := extractor.Extract(context.Background(), &ExtractorInput{Text: "Ada at Acme."})
+		require.NoError(t, err)
+		assert.Len(t, output.Entities, 2)
+		assert.Equal(t, 3, stub.attempts())
+	})
+
+	t.Run("gives up after the last attempt", func(t *testing.T) {
+		stub := newStubExtractorServer(t, findNames)
+		stub.failures, stub.failStatus = 5, http.StatusTooManyRequests
+
+		extractor, err := NewHTTPExtractor(config(stub.server.URL))
+		require.NoError(t, err)
+
+		_, err = extractor.Extract(context.Background(), &ExtractorInput{Text: "Ada at Acme."})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "extractor ner unavailable")
+		assert.Equal(t, 3, stub.attempts())
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		stub := newStubExtractorServer(t, findNames)
+		stub.failures, stub.failStatus = 1, http.StatusUnprocessableEntity
+
+		extractor, err := NewHTTPExtractor(config(stub.server.URL))
+		require.NoError(t, err)
+
+		_, err = extractor.Extract(context.Background(), &ExtractorInput{Text: "Ada at Acme."})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unexpected status 422")
+		assert.Equal(t, 1, stub.attempts())
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		stub := newStubExtractorServer(t, findNames)
+		stub.delay = 200 * time.Millisecond
+
+		timeout := config(stub.server.URL)
+		timeout.Timeout = 20 * time.Millisecond
+		timeout.MaxAttempts = 2
+		extractor, err := NewHTTPExtractor(timeout)
+		require.NoError(t, err)
+
+		_, err = extractor.Extract(context.Background(), &ExtractorInput{Text: "Ada at Acme."})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unavailable")
+		assert.Equal(t, 2, stub.attempts())
+	})
+}
+
+func TestNewHTTPExtractor_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config HTTPExtractorConfig
+	}{
+		{name: "built-in name", config: HTTPExtractorConfig{Name: RuleExtractor, URL: "http://localhost:8080"}},
+		{name: "upper case name", config: HTTPExtractorConfig{Name: "NER", URL: "http://localhost:8080"}},
+		{name: "missing URL", config: HTTPExtractorConfig{Name: "ner"}},
+		{name: "unsupported scheme", config: HTTPExtractorConfig{Name: "ner", URL: "file:///etc/passwd"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewHTTPExtractor(tt.config)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid")
+		})
+	}
+}
+
+func TestSplitSegments(t *testing.T) {
+	text := []rune("First paragraph.\n\nSecond one is longer than the limit\n\n   \n\nEnd")
+
+	segments := splitSegments(text, 20)
+
+	var joined strings.Builder
+	for i, segment := range segments {
+		assert.Equal(t, i, segment.ID)
+		assert.LessOrEqual(t, len([]rune(segment.Text)), 20)
+		assert.Equal(t, segment.Text, string(text[segment.Offset:segment.Offset+len([]rune(segment.Text))]))
+		joined.WriteString(segment.Text)
+	}
+	assert.Equal(t, string(text), joined.String())
+	assert.Equal(t, "First paragraph.\n\n", segments[0].Text)
+	assert.Equal(t, "Second one is ", segments[1].Text)
+
+	blank := splitSegments([]rune("Ada\n\n          \n\nAcme"), 6)
+	require.Len(t, blank, 2)
+	assert.Equal(t, "Ada\n\n", blank[0].Text)
+	assert.Equal(t, 1, blank[1].ID)
+	assert.Equal(t, "Acme", blank[1].Text)
+}
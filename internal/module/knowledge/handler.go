@@ -0,0 +1,371 @@
+package knowledge
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/binding"
+)
+
+type KnowledgeHandler struct {
+	service KnowledgeService
+}
+
+func NewKnowledgeHandler(service KnowledgeService) *KnowledgeHandler {
+	return &KnowledgeHandler{
+		service: service,
+	}
+}
+
+// CreateEntity godoc
+// @Summary Create an entity
+// @Description Create a typed entity with properties. Property values must be strings, numbers, booleans or lists of one of those.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param request body CreateEntityRequest true "Entity type and properties"
+// @Success 201 {object} map[string]interface{} "Entity created successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /workspaces/{workspaceId}/knowledge/entities [post]
+func (h *KnowledgeHandler) CreateEntity(c *fiber.Ctx) error {
+	var req CreateEntityRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	entity, err := h.service.CreateEntity(c.Context(), workspaceIDFrom(c), accountIDFrom(c), &req)
+	if err != nil {
+		return knowledgeError(c, "Failed to create entity", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Entity created successfully",
+		"data":    entity,
+	})
+}
+
+// ListEntities godoc
+// @Summary List entities
+// @Description Page through the entities of the workspace, newest first.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param type query string false "Filter by entity type"
+// @Param q query string false "Case-insensitive match on the name property"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(20)
+// @Success 200 {object} map[string]interface{} "Entities retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /workspaces/{workspaceId}/knowledge/entities [get]
+func (h *KnowledgeHandler) ListEntities(c *fiber.Ctx) error {
+	var query EntityListQuery
+	if err := binding.Query(c, &query); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	entities, err := h.service.ListEntities(c.Context(), workspaceIDFrom(c), &query)
+	if err != nil {
+		return knowledgeError(c, "Failed to list entities", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Entities retrieved successfully",
+		"data":    entities,
+	})
+}
+
+// GetEntity godoc
+// @Summary Get an entity
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Entity ID"
+// @Success 200 {object} map[string]interface{} "Entity retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Entity not found"
+// @Router /workspaces/{workspaceId}/knowledge/entities/{id} [get]
+func (h *KnowledgeHandler) GetEntity(c *fiber.Ctx) error {
+	entity, err := h.service.GetEntity(c.Context(), workspaceIDFrom(c), c.Params("id"))
+	if err != nil {
+		return knowledgeError(c, "Failed to get entity", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Entity retrieved successfully",
+		"data":    entity,
+	})
+}
+
+// UpdateEntity godoc
+// @Summary Update an entity
+// @Description Merge properties into an entity. Set a property to null to remove it.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Entity ID"
+// @Param request body UpdateEntityRequest true "Properties to merge"
+// @Success 200 {object} map[string]interface{} "Entity updated successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Entity not found"
+// @Router /workspaces/{workspaceId}/knowledge/entities/{id} [patch]
+func (h *KnowledgeHandler) UpdateEntity(c *fiber.Ctx) error {
+	var req UpdateEntityRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	entity, err := h.service.UpdateEntity(c.Context(), workspaceIDFrom(c), c.Params("id"), &req)
+	if err != nil {
+		return knowledgeError(c, "Failed to update entity", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Entity updated successfully",
+		"data":    entity,
+	})
+}
+
+// DeleteEntity godoc
+// @Summary Delete an entity
+// @Description Delete an entity and every relation attached to it.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Entity ID"
+// @Success 200 {object} map[string]interface{} "Entity deleted successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Entity not found"
+// @Router /workspaces/{workspaceId}/knowledge/entities/{id} [delete]
+func (h *KnowledgeHandler) DeleteEntity(c *fiber.Ctx) error {
+	if err := h.service.DeleteEntity(c.Context(), workspaceIDFrom(c), c.Params("id")); err != nil {
+		return knowledgeError(c, "Failed to delete entity", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Entity deleted successfully",
+	})
+}
+
+// GetNeighbourhood godoc
+// @Summary Expand an entity
+// @Description Get the entities and relations reachable from an entity within a number of hops.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Entity ID"
+// @Param depth query int false "Number of hops (1-3)" default(1)
+// @Param direction query string false "Follow outgoing, incoming or both directions (out, in, both)" default(both)
+// @Param relation_types query string false "Comma-separated relation types to follow"
+// @Param limit query int false "Maximum number of paths to expand (1-500)" default(100)
+// @Success 200 {object} map[string]interface{} "Neighbourhood retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Entity not found"
+// @Router /workspaces/{workspaceId}/knowledge/entities/{id}/neighbourhood [get]
+func (h *KnowledgeHandler) GetNeighbourhood(c *fiber.Ctx) error {
+	var query NeighbourhoodQuery
+	if err := binding.Query(c, &query); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	graph, err := h.service.GetNeighbourhood(c.Context(), workspaceIDFrom(c), c.Params("id"), &query)
+	if err != nil {
+		return knowledgeError(c, "Failed to expand entity", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Neighbourhood retrieved successfully",
+		"data":    graph,
+	})
+}
+
+// CreateRelation godoc
+// @Summary Create a relation
+// @Description Create a typed, directed relation between two entities of the workspace. Relation types are upper-cased.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param request body CreateRelationRequest true "Source, target, type and properties"
+// @Success 201 {object} map[string]interface{} "Relation created successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Source or target entity not found"
+// @Router /workspaces/{workspaceId}/knowledge/relations [post]
+func (h *KnowledgeHandler) CreateRelation(c *fiber.Ctx) error {
+	var req CreateRelationRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	relation, err := h.service.CreateRelation(c.Context(), workspaceIDFrom(c), accountIDFrom(c), &req)
+	if err != nil {
+		return knowledgeError(c, "Failed to create relation", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Relation created successfully",
+		"data":    relation,
+	})
+}
+
+// GetRelation godoc
+// @Summary Get a relation
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Relation ID"
+// @Success 200 {object} map[string]interface{} "Relation retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Relation not found"
+// @Router /workspaces/{workspaceId}/knowledge/relations/{id} [get]
+func (h *KnowledgeHandler) GetRelation(c *fiber.Ctx) error {
+	relation, err := h.service.GetRelation(c.Context(), workspaceIDFrom(c), c.Params("id"))
+	if err != nil {
+		return knowledgeError(c, "Failed to get relation", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Relation retrieved successfully",
+		"data":    relation,
+	})
+}
+
+// UpdateRelation godoc
+// @Summary Update a relation
+// @Description Merge properties into a relation. Set a property to null to remove it.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Relation ID"
+// @Param request body UpdateRelationRequest true "Properties to merge"
+// @Success 200 {object} map[string]interface{} "Relation updated successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Relation not found"
+// @Router /workspaces/{workspaceId}/knowledge/relations/{id} [patch]
+func (h *KnowledgeHandler) UpdateRelation(c *fiber.Ctx) error {
+	var req UpdateRelationRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	relation, err := h.service.UpdateRelation(c.Context(), workspaceIDFrom(c), c.Params("id"), &req)
+	if err != nil {
+		return knowledgeError(c, "Failed to update relation", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Relation updated successfully",
+		"data":    relation,
+	})
+}
+
+// DeleteRelation godoc
+// @Summary Delete a relation
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Relation ID"
+// @Success 200 {object} map[string]interface{} "Relation deleted successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Relation not found"
+// @Router /workspaces/{workspaceId}/knowledge/relations/{id} [delete]
+func (h *KnowledgeHandler) DeleteRelation(c *fiber.Ctx) error {
+	if err := h.service.DeleteRelation(c.Context(), workspaceIDFrom(c), c.Params("id")); err != nil {
+		return knowledgeError(c, "Failed to delete relation", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Relation deleted successfully",
+	})
+}
+
+// FindPaths godoc
+// @Summary Find paths between entities
+// @Description Find the shortest path between two entities, ignoring direction. With all=true every shortest path is returned. An empty list means no path within max_depth hops.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param from query string true "Start entity ID"
+// @Param to query string true "End entity ID"
+// @Param max_depth query int false "Maximum path length (1-6)" default(4)
+// @Param all query bool false "Return every shortest path" default(false)
+// @Success 200 {object} map[string]interface{} "Paths retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Entity not found"
+// @Router /workspaces/{workspaceId}/knowledge/paths [get]
+func (h *KnowledgeHandler) FindPaths(c *fiber.Ctx) error {
+	var query PathQuery
+	if err := binding.Query(c, &query); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	paths, err := h.service.FindPaths(c.Context(), workspaceIDFrom(c), &query)
+	if err != nil {
+		return knowledgeError(c, "Failed to find paths", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Paths retrieved successfully",
+		"data":    paths,
+	})
+}
+
+func accountIDFrom(c *fiber.Ctx) string {
+	accountID, _ := c.Locals("account_id").(string)
+	return accountID
+}
+
+// workspaceIDFrom returns the workspace resolved by
+// workspace.WorkspaceMiddleware.
+func workspaceIDFrom(c *fiber.Ctx) string {
+	workspaceID, _ := c.Locals("workspace_id").(string)
+	return workspaceID
+}
+
+func knowledgeError(c *fiber.Ctx, title string, err error) error {
+	statusCode := fiber.StatusInternalServerError
+	if strings.Contains(err.Error(), "unavailable") {
+		statusCode = fiber.StatusBadGateway
+	} else if strings.Contains(err.Error(), "not found") {
+		statusCode = fiber.StatusNotFound
+	} else if strings.Contains(err.Error(), "larger than") {
+		statusCode = fiber.StatusRequestEntityTooLarge
+	} else if strings.Contains(err.Error(), "already") || strings.Contains(err.Error(), "conflict") {
+		statusCode = fiber.StatusConflict
+	} else if strings.Contains(err.Error(), "invalid") {
+		statusCode = fiber.StatusBadRequest
+	}
+
+	return c.Status(statusCode).JSON(fiber.Map{
+		"error":   title,
+		"message": err.Error(),
+	})
+}
@@ -0,0 +1,596 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// importer turns the records of a file into batched writes. A record is
+// either imported whole or skipped with row errors: everything it maps to is
+// checked before anything is queued. Entities are written before the
+// relations that may need them, and a dry run checks everything without
+// writing.
+type importer struct {
+	knowledge *knowledgeService
+	job       *ImportJob
+	schema    *Schema
+
+	// save stores the progress after every batch.
+	save func(ctx context.Context) error
+
+	progress ImportProgress
+	errors   []ImportRowError
+
+	entities      map[string]*ImportEntityBatch
+	entityOrder   []string
+	relations     map[string]*ImportRelationBatch
+	relationOrder []string
+
+	// seen holds the keys of the entities of a dry run, so that its
+	// relations can refer to them.
+	seen map[string]bool
+
+	// nodes maps the GraphML IDs of the nodes imported so far to the entities
+	// they became.
+	nodes map[string]ImportEndpoint
+	keys  map[string]interface{}
+}
+
+type entityWrite struct {
+	batch ImportEntityBatch
+	row   ImportEntityRow
+}
+
+type relationWrite struct {
+	batch ImportRelationBatch
+	row   ImportRelationRow
+}
+
+func newImporter(knowledge *knowledgeService, job *ImportJob, schema *Schema) *importer {
+	return &importer{
+		knowledge: knowledge,
+		job:       job,
+		schema:    schema,
+		save:      func(context.Context) error { return nil },
+		entities:  map[string]*ImportEntityBatch{},
+		relations: map[string]*ImportRelationBatch{},
+		seen:      map[string]bool{},
+		nodes:     map[string]ImportEndpoint{},
+		keys:      map[string]interface{}{},
+	}
+}
+
+// importRecords imports every record of a CSV or JSON file.
+func (im *importer) importRecords(ctx context.Context, records recordReader) error {
+	for {
+		record, err := records.Next()
+		if err == io.EOF {
+			return im.flush(ctx)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := im.addRecord(ctx, record); err != nil {
+			return err
+		}
+	}
+}
+
+// importGraph imports every node and edge of a GraphML file. Edges can only
+// refer to nodes that come before them.
+func (im *importer) importGraph(ctx context.Context, graph *graphMLReader) error {
+	for {
+		item, err := graph.Next()
+		if err == io.EOF {
+			return im.flush(ctx)
+		}
+		if err != nil {
+			return err
+		}
+
+		if item.Node {
+			err = im.addNode(ctx, item)
+		} else {
+			err = im.addEdge(ctx, item)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (im *importer) addRecord(ctx context.Context, record *importRecord) error {
+	im.progress.Rows++
+	if record.Err != "" {
+		im.fail(record.Row, "", record.Err)
+		return nil
+	}
+
+	mapping := im.job.Mapping
+	var rowErrors []ImportRowError
+	var entities []entityWrite
+	var relations []relationWrite
+
+	for _, entityMapping := range mapping.Entities {
+		target := "entity " + entityMapping.Type
+
+		properties := map[string]interface{}{}
+		for name, field := range entityMapping.Properties {
+			if value, ok := record.Field(field); ok {
+				properties[name] = value
+			}
+		}
+
+		write, problem, err := im.entity(ctx, record.Row, entityMapping.Type, entityMapping.Key, properties)
+		if err != nil {
+			return err
+		}
+		if problem != "" {
+			rowErrors = append(rowErrors, ImportRowError{Row: record.Row, Target: target, Message: problem})
+			continue
+		}
+		entities = append(entities, write)
+	}
+
+	for _, relationMapping := range mapping.Relations {
+		target := "relation " + relationMapping.Type
+
+		source, sourceOK := record.Field(relationMapping.Source.Field)
+		if !sourceOK {
+			rowErrors = append(rowErrors, ImportRowError{Row: record.Row, Target: target, Message: fmt.Sprintf("source field %q is missing", relationMapping.Source.Field)})
+			continue
+		}
+		targetValue, targetOK := record.Field(relationMapping.Target.Field)
+		if !targetOK {
+			rowErrors = append(rowErrors, ImportRowError{Row: record.Row, Target: target, Message: fmt.Sprintf("target field %q is missing", relationMapping.Target.Field)})
+			continue
+		}
+
+		properties := map[string]interface{}{}
+		for name, field := range relationMapping.Properties {
+			if value, ok := record.Field(field); ok {
+				properties[name] = value
+			}
+		}
+
+		write, problem, err := im.relation(ctx, record.Row, relationMapping.Type, relationMapping.Source, relationMapping.Target, source, targetValue, properties)
+		if err != nil {
+			return err
+		}
+		if problem != "" {
+			rowErrors = append(rowErrors, ImportRowError{Row: record.Row, Target: target, Message: problem})
+			continue
+		}
+		relations = append(relations, write)
+	}
+
+	if len(rowErrors) > 0 {
+		for _, rowError := range rowErrors {
+			im.fail(rowError.Row, rowError.Target, rowError.Message)
+		}
+		return nil
+	}
+
+	return im.queue(ctx, entities, relations)
+}
+
+// addNode imports a GraphML node as an entity. Its type is the first of the
+// labels in its type attribute, such as :Person:Author, or else the mapping's
+// node type.
+func (im *importer) addNode(ctx context.Context, item *graphItem) error {
+	im.progress.Rows++
+	target := "node " + item.ID
+	if item.Err != "" {
+		im.fail(item.Row, target, item.Err)
+		return nil
+	}
+
+	mapping := im.job.Mapping.GraphML
+	entityType := mapping.NodeType
+	if labels, ok := item.Data[mapping.NodeTypeAttribute].(string); ok {
+		for _, label := range strings.Split(labels, ":") {
+			if label = strings.TrimSpace(label); label != "" {
+				entityType = label
+				break
+			}
+		}
+	}
+	delete(item.Data, mapping.NodeTypeAttribute)
+
+	if entityType == "" {
+		im.fail(item.Row, target, "has no type")
+		return nil
+	}
+	if _, err := normalizeEntityType(entityType); err != nil {
+		im.fail(item.Row, target, err.Error())
+		return nil
+	}
+	if _, ok := item.Data[mapping.Key]; !ok {
+		item.Data[mapping.Key] = item.ID
+	}
+
+	write, problem, err := im.entity(ctx, item.Row, entityType, mapping.Key, item.Data)
+	if err != nil {
+		return err
+	}
+	if problem != "" {
+		im.fail(item.Row, target, problem)
+		return nil
+	}
+
+	im.nodes[item.ID] = ImportEndpoint{Type: entityType, Key: mapping.Key}
+	im.keys[item.ID] = write.row.Key
+
+	return im.queue(ctx, []entityWrite{write}, nil)
+}
+
+// addEdge imports a GraphML edge as a relation between the entities its
+// nodes became.
+func (im *importer) addEdge(ctx context.Context, item *graphItem) error {
+	im.progress.Rows++
+	target := "edge " + item.ID
+	if item.ID == "" {
+		target = fmt.Sprintf("edge %s-%s", item.Source, item.Target)
+	}
+	if item.Err != "" {
+		im.fail(item.Row, target, item.Err)
+		return nil
+	}
+
+	mapping := im.job.Mapping.GraphML
+	relationType := mapping.EdgeType
+	if label, ok := item.Data[mapping.EdgeTypeAttribute].(string); ok && strings.TrimSpace(label) != "" {
+		relationType = strings.TrimSpace(label)
+	}
+	delete(item.Data, mapping.EdgeTypeAttribute)
+
+	if relationType == "" {
+		im.fail(item.Row, target, "has no type")
+		return nil
+	}
+	normalized, err := normalizeRelationType(relationType)
+	if err != nil {
+		im.fail(item.Row, target, err.Error())
+		return nil
+	}
+
+	source, ok := im.nodes[item.Source]
+	if !ok {
+		im.fail(item.Row, target, fmt.Sprintf("source node %q was not imported", item.Source))
+		return nil
+	}
+	targetNode, ok := im.nodes[item.Target]
+	if !ok {
+		im.fail(item.Row, target, fmt.Sprintf("target node %q was not imported", item.Target))
+		return nil
+	}
+
+	write, problem, err := im.relation(ctx, item.Row, normalized, source, targetNode, im.keys[item.Source], im.keys[item.Target], item.Data)
+	if err != nil {
+		return err
+	}
+	if problem != "" {
+		im.fail(item.Row, target, problem)
+		return nil
+	}
+
+	return im.queue(ctx, nil, []relationWrite{write})
+}
+
+// entity checks an entity and its key. It returns a problem for entities
+// that cannot be imported, and an error only when the check itself failed.
+func (im *importer) entity(ctx context.Context, row int, entityType, key string, properties map[string]interface{}) (entityWrite, string, error) {
+	var definitions []PropertyDefinition
+	if im.schema != nil {
+		if definition := im.schema.EntityType(entityType); definition != nil {
+			definitions = definition.Properties
+		}
+	}
+	coerceNumbers(definitions, properties)
+
+	normalized, err := normalizeProperties(properties, false)
+	if err != nil {
+		return entityWrite{}, err.Error(), nil
+	}
+
+	keyValue, ok := normalized[key]
+	if !ok {
+		return entityWrite{}, fmt.Sprintf("key property %q is missing", key), nil
+	}
+	if !isImportKey(keyValue) {
+		return entityWrite{}, fmt.Sprintf("key property %q must be a string or a number", key), nil
+	}
+
+	if im.schema != nil {
+		types, err := im.referencedTypes(ctx, definitions, normalized)
+		if err != nil {
+			return entityWrite{}, "", err
+		}
+		if problems := entityProblems(im.schema, entityType, normalized, types); len(problems) > 0 {
+			return entityWrite{}, strings.Join(problems, "; "), nil
+		}
+	}
+
+	return entityWrite{
+		batch: ImportEntityBatch{Type: entityType, Key: key},
+		row:   ImportEntityRow{Row: row, Key: keyValue, Properties: normalized},
+	}, "", nil
+}
+
+// relation checks a relation and the keys of its ends. Whether the ends
+// exist is only known once they are written, so it is checked by batch.
+func (im *importer) relation(ctx context.Context, row int, relationType string, source, target ImportEndpoint, sourceValue, targetValue interface{}, properties map[string]interface{}) (relationWrite, string, error) {
+	sourceKey, problem := im.endpointKey("source", source, sourceValue)
+	if problem != "" {
+		return relationWrite{}, problem, nil
+	}
+	targetKey, problem := im.endpointKey("target", target, targetValue)
+	if problem != "" {
+		return relationWrite{}, problem, nil
+	}
+
+	var definitions []PropertyDefinition
+	if im.schema != nil {
+		if definition := im.schema.RelationType(relationType); definition != nil {
+			definitions = definition.Properties
+		}
+	}
+	coerceNumbers(definitions, properties)
+
+	normalized, err := normalizeProperties(properties, false)
+	if err != nil {
+		return relationWrite{}, err.Error(), nil
+	}
+
+	if im.schema != nil {
+		types, err := im.referencedTypes(ctx, definitions, normalized)
+		if err != nil {
+			return relationWrite{}, "", err
+		}
+		if problems := relationProblems(im.schema, relationType, source.Type, target.Type, normalized, types); len(problems) > 0 {
+			return relationWrite{}, strings.Join(problems, "; "), nil
+		}
+	}
+
+	return relationWrite{
+		batch: ImportRelationBatch{
+			Type:   relationType,
+			Source: ImportEndpoint{Type: source.Type, Key: source.Key},
+			Target: ImportEndpoint{Type: target.Type, Key: target.Key},
+		},
+		row: ImportRelationRow{Row: row, Source: sourceKey, Target: targetKey, Properties: normalized},
+	}, "", nil
+}
+
+// endpointKey converts the value identifying an end of a relation the way
+// the key property of its entities is converted.
+func (im *importer) endpointKey(end string, endpoint ImportEndpoint, value interface{}) (interface{}, string) {
+	if im.schema != nil {
+		if definition := im.schema.EntityType(endpoint.Type); definition != nil {
+			properties := map[string]interface{}{endpoint.Key: value}
+			coerceNumbers(definition.Properties, properties)
+			value = properties[endpoint.Key]
+		}
+	}
+
+	normalized, err := normalizeValue(value)
+	if err != nil || !isImportKey(normalized) {
+		return nil, fmt.Sprintf("%s key must be a string or a number", end)
+	}
+
+	return normalized, ""
+}
+
+func (im *importer) referencedTypes(ctx context.Context, definitions []PropertyDefinition, properties map[string]interface{}) (map[string]string, error) {
+	ids := referencedIDs(definitions, properties)
+	if len(ids) == 0 {
+		return map[string]string{}, nil
+	}
+
+	return im.knowledge.repository.EntityTypes(ctx, im.job.WorkspaceID, ids)
+}
+
+// queue adds the writes of a record to their batches and writes the batches
+// that are full.
+func (im *importer) queue(ctx context.Context, entities []entityWrite, relations []relationWrite) error {
+	for _, write := range entities {
+		id := write.batch.Type + "\x00" + write.batch.Key
+		batch, ok := im.entities[id]
+		if !ok {
+			batch = &ImportEntityBatch{Type: write.batch.Type, Key: write.batch.Key}
+			im.entities[id] = batch
+			im.entityOrder = append(im.entityOrder, id)
+		}
+		batch.Rows = append(batch.Rows, write.row)
+
+		if len(batch.Rows) >= ImportBatchSize {
+			if err := im.flushEntities(ctx, batch); err != nil {
+				return err
+			}
+			if err := im.save(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, write := range relations {
+		id := strings.Join([]string{write.batch.Type, write.batch.Source.Type, write.batch.Source.Key, write.batch.Target.Type, write.batch.Target.Key}, "\x00")
+		batch, ok := im.relations[id]
+		if !ok {
+			batch = &ImportRelationBatch{Type: write.batch.Type, Source: write.batch.Source, Target: write.batch.Target}
+			im.relations[id] = batch
+			im.relationOrder = append(im.relationOrder, id)
+		}
+		batch.Rows = append(batch.Rows, write.row)
+
+		if len(batch.Rows) >= ImportBatchSize {
+			if err := im.flushAllEntities(ctx); err != nil {
+				return err
+			}
+			if err := im.flushRelations(ctx, batch); err != nil {
+				return err
+			}
+			if err := im.save(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// flush writes what is left once the file has been read.
+func (im *importer) flush(ctx context.Context) error {
+	if err := im.flushAllEntities(ctx); err != nil {
+		return err
+	}
+
+	for _, id := range im.relationOrder {
+		if err := im.flushRelations(ctx, im.relations[id]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (im *importer) flushAllEntities(ctx context.Context) error {
+	for _, id := range im.entityOrder {
+		if err := im.flushEntities(ctx, im.entities[id]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (im *importer) flushEntities(ctx context.Context, batch *ImportEntityBatch) error {
+	if len(batch.Rows) == 0 {
+		return nil
+	}
+
+	if im.job.DryRun {
+		for _, row := range batch.Rows {
+			im.seen[importKey(batch.Type, batch.Key, row.Key)] = true
+		}
+	} else {
+		created, err := im.knowledge.repository.ImportEntities(ctx, im.job.WorkspaceID, im.job.CreatedBy, batch)
+		if err != nil {
+			return err
+		}
+		im.progress.EntitiesCreated += int(created)
+	}
+
+	im.progress.Entities += len(batch.Rows)
+	batch.Rows = batch.Rows[:0]
+	return nil
+}
+
+// flushRelations writes the relations of a batch whose ends exist and
+// reports the others. A dry run also accepts ends among the entities it
+// would have written.
+func (im *importer) flushRelations(ctx context.Context, batch *ImportRelationBatch) error {
+	if len(batch.Rows) == 0 {
+		return nil
+	}
+
+	matches, err := im.knowledge.repository.MatchImportEndpoints(ctx, im.job.WorkspaceID, batch)
+	if err != nil {
+		return err
+	}
+	found := make(map[int]*ImportEndpointMatch, len(matches))
+	for _, match := range matches {
+		found[match.Row] = match
+	}
+
+	rows := make([]ImportRelationRow, 0, len(batch.Rows))
+	for _, row := range batch.Rows {
+		sourceFound, targetFound := false, false
+		if match := found[row.Row]; match != nil {
+			sourceFound, targetFound = match.Source, match.Target
+		}
+		if im.job.DryRun {
+			sourceFound = sourceFound || im.seen[importKey(batch.Source.Type, batch.Source.Key, row.Source)]
+			targetFound = targetFound || im.seen[importKey(batch.Target.Type, batch.Target.Key, row.Target)]
+		}
+
+		target := "relation " + batch.Type
+		if !sourceFound {
+			im.fail(row.Row, target, fmt.Sprintf("source entity %s with %s %s not found", batch.Source.Type, batch.Source.Key, formatKey(row.Source)))
+			continue
+		}
+		if !targetFound {
+			im.fail(row.Row, target, fmt.Sprintf("target entity %s with %s %s not found", batch.Target.Type, batch.Target.Key, formatKey(row.Target)))
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) > 0 && !im.job.DryRun {
+		created, err := im.knowledge.repository.ImportRelations(ctx, im.job.WorkspaceID, im.job.CreatedBy, &ImportRelationBatch{
+			Type:   batch.Type,
+			Source: batch.Source,
+			Target: batch.Target,
+			Rows:   rows,
+		})
+		if err != nil {
+			return err
+		}
+		im.progress.RelationsCreated += int(created)
+	}
+
+	im.progress.Relations += len(rows)
+	batch.Rows = batch.Rows[:0]
+	return nil
+}
+
+// fail records a row error. All of them are counted, the first
+// MaxImportErrors are kept.
+func (im *importer) fail(row int, target, message string) {
+	im.progress.Errors++
+	if len(im.errors) < MaxImportErrors {
+		im.errors = append(im.errors, ImportRowError{Row: row, Target: target, Message: message})
+	}
+}
+
+// coerceNumbers converts the strings of a CSV file, or of GraphML data
+// without a type, to numbers where the schema expects them. Values that are
+// not numbers are left for the schema check to report.
+func coerceNumbers(definitions []PropertyDefinition, properties map[string]interface{}) {
+	for _, definition := range definitions {
+		if definition.Type != PropertyTypeNumber {
+			continue
+		}
+		str, ok := properties[definition.Name].(string)
+		if !ok {
+			continue
+		}
+		if number, err := strconv.ParseFloat(str, 64); err == nil && !math.IsNaN(number) && !math.IsInf(number, 0) {
+			properties[definition.Name] = number
+		}
+	}
+}
+
+func isImportKey(value interface{}) bool {
+	switch value.(type) {
+	case string, int64, float64:
+		return true
+	}
+	return false
+}
+
+func importKey(entityType, key string, value interface{}) string {
+	return fmt.Sprintf("%s\x00%s\x00%T\x00%v", entityType, key, value, value)
+}
+
+func formatKey(value interface{}) string {
+	if str, ok := value.(string); ok {
+		return strconv.Quote(str)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,162 @@
+package knowledge
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/binding"
+)
+
+type ImportHandler struct {
+	service ImportService
+}
+
+func NewImportHandler(service ImportService) *ImportHandler {
+	return &ImportHandler{
+		service: service,
+	}
+}
+
+// CreateImport godoc
+// @Summary Import a file into the knowledge graph
+// @Description Upload a CSV, JSON or GraphML file as multipart form data and queue its import. The mapping, a JSON ImportMapping, turns CSV columns or JSON paths into entities and relations; GraphML nodes and edges are imported as they are. Entities and relations are merged on their keys, so importing a file again updates them. A dry run checks the file against the workspace schema without writing.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param file formData file true "File to import"
+// @Param format formData string false "File format, detected from the file name by default" Enums(csv, json, graphml)
+// @Param mapping formData string false "ImportMapping as JSON, required for CSV and JSON files"
+// @Param dry_run formData bool false "Only check the file"
+// @Success 202 {object} map[string]interface{} "Import queued successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 413 {object} map[string]interface{} "File too large"
+// @Router /workspaces/{workspaceId}/knowledge/imports [post]
+func (h *ImportHandler) CreateImport(c *fiber.Ctx) error {
+	header, err := c.FormFile("file")
+	if err != nil {
+		return knowledgeError(c, "Failed to create import", fmt.Errorf("invalid file: the file field is required"))
+	}
+
+	input := &ImportInput{
+		Name:        header.Filename,
+		ContentType: header.Header.Get("Content-Type"),
+		Size:        header.Size,
+		Format:      ImportFormat(c.FormValue("format")),
+	}
+
+	if value := c.FormValue("dry_run"); value != "" {
+		if input.DryRun, err = strconv.ParseBool(value); err != nil {
+			return knowledgeError(c, "Failed to create import", fmt.Errorf("invalid dry_run %q", value))
+		}
+	}
+
+	if value := c.FormValue("mapping"); value != "" {
+		if err := json.Unmarshal([]byte(value), &input.Mapping); err != nil {
+			return knowledgeError(c, "Failed to create import", fmt.Errorf("invalid mapping: %w", err))
+		}
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return knowledgeError(c, "Failed to create import", fmt.Errorf("failed to read file: %w", err))
+	}
+	defer file.Close()
+
+	job, err := h.service.CreateImport(c.Context(), workspaceIDFrom(c), accountIDFrom(c), input, file)
+	if err != nil {
+		return knowledgeError(c, "Failed to create import", err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "Import queued successfully",
+		"data":    job,
+	})
+}
+
+// ListImports godoc
+// @Summary List imports
+// @Description Page through the imports of the workspace, newest first.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param status query string false "Filter by status" Enums(queued, processing, succeeded, failed)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(20)
+// @Success 200 {object} map[string]interface{} "Imports retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /workspaces/{workspaceId}/knowledge/imports [get]
+func (h *ImportHandler) ListImports(c *fiber.Ctx) error {
+	var query ImportListQuery
+	if err := binding.Query(c, &query); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	jobs, err := h.service.ListImports(c.Context(), workspaceIDFrom(c), &query)
+	if err != nil {
+		return knowledgeError(c, "Failed to list imports", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Imports retrieved successfully",
+		"data":    jobs,
+	})
+}
+
+// GetImport godoc
+// @Summary Get an import
+// @Description Get the status and progress of an import: the bytes and rows read, the entities and relations written or checked, and how many rows failed.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Import ID"
+// @Success 200 {object} map[string]interface{} "Import retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Import not found"
+// @Router /workspaces/{workspaceId}/knowledge/imports/{id} [get]
+func (h *ImportHandler) GetImport(c *fiber.Ctx) error {
+	job, err := h.service.GetImport(c.Context(), workspaceIDFrom(c), c.Params("id"))
+	if err != nil {
+		return knowledgeError(c, "Failed to get import", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Import retrieved successfully",
+		"data":    job,
+	})
+}
+
+// GetImportErrors godoc
+// @Summary Get the row errors of an import
+// @Description List why rows, or GraphML nodes and edges, were not imported. Only the first 1000 are kept; count has them all.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Import ID"
+// @Success 200 {object} map[string]interface{} "Import errors retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Import not found"
+// @Router /workspaces/{workspaceId}/knowledge/imports/{id}/errors [get]
+func (h *ImportHandler) GetImportErrors(c *fiber.Ctx) error {
+	importErrors, err := h.service.GetImportErrors(c.Context(), workspaceIDFrom(c), c.Params("id"))
+	if err != nil {
+		return knowledgeError(c, "Failed to get import errors", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Import errors retrieved successfully",
+		"data":    importErrors,
+	})
+}
@@ -0,0 +1,222 @@
+package knowledge
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const ImportCollectionName = "knowledge_imports"
+
+const (
+	// ImportBatchSize is how many rows one UNWIND query writes.
+	ImportBatchSize = 500
+
+	// MaxImportErrors caps the row errors kept on an import. The progress
+	// still counts all of them.
+	MaxImportErrors = 1000
+
+	// DefaultMaxImportSize is the largest file imported, in bytes.
+	DefaultMaxImportSize int64 = 100 << 20
+
+	// ImportMaxAttempts is how often an import that failed for a reason other
+	// than its file is tried. Entities and relations are merged on their keys,
+	// so a second attempt updates what the first one wrote.
+	ImportMaxAttempts = 3
+
+	// ImportLease is how long a worker owns a claimed import without saving
+	// progress. Saving progress renews it.
+	ImportLease = 10 * time.Minute
+
+	// ImportRetryDelay is the wait before the second attempt; it doubles for
+	// every later one.
+	ImportRetryDelay = 30 * time.Second
+
+	ImportPollInterval = 5 * time.Second
+
+	ImportListDefaultLimit = 20
+	ImportListMaxLimit     = 100
+
+	maxImportMappings = 20
+)
+
+type ImportFormat string
+
+const (
+	ImportFormatCSV     ImportFormat = "csv"
+	ImportFormatJSON    ImportFormat = "json"
+	ImportFormatGraphML ImportFormat = "graphml"
+)
+
+type ImportStatus string
+
+const (
+	ImportStatusQueued     ImportStatus = "queued"
+	ImportStatusProcessing ImportStatus = "processing"
+	ImportStatusSucceeded  ImportStatus = "succeeded"
+	ImportStatusFailed     ImportStatus = "failed"
+)
+
+// ImportMapping turns the records of a CSV or JSON file into entities and
+// relations. Fields name CSV columns, or JSON paths of keys and list indexes
+// separated by dots, such as company.name or authors.0.
+//
+// Records names the JSON path of the list of records. Without it the file
+// holds a list of records, or one record per line. Delimiter separates CSV
+// columns, a comma by default.
+type ImportMapping struct {
+	Delimiter string            `json:"delimiter,omitempty" bson:"delimiter,omitempty"`
+	Records   string            `json:"records,omitempty" bson:"records,omitempty"`
+	Entities  []EntityMapping   `json:"entities" bson:"entities"`
+	Relations []RelationMapping `json:"relations" bson:"relations"`
+	GraphML   *GraphMLMapping   `json:"graphml,omitempty" bson:"graphml,omitempty"`
+}
+
+// EntityMapping creates an entity of Type from every record, with the values
+// of the fields as properties. Key is the property that identifies the
+// entity: an entity of the type with the same key is updated instead.
+type EntityMapping struct {
+	Type       string            `json:"type" bson:"type"`
+	Key        string            `json:"key" bson:"key"`
+	Properties map[string]string `json:"properties" bson:"properties"`
+}
+
+// RelationMapping creates a relation of Type from every record, between the
+// entities its ends identify. A relation of the type between the same
+// entities is updated instead.
+type RelationMapping struct {
+	Type       string            `json:"type" bson:"type"`
+	Source     ImportEndpoint    `json:"source" bson:"source"`
+	Target     ImportEndpoint    `json:"target" bson:"target"`
+	Properties map[string]string `json:"properties" bson:"properties"`
+}
+
+// ImportEndpoint identifies the entity of Type whose Key property has the
+// value of Field.
+type ImportEndpoint struct {
+	Type  string `json:"type" bson:"type"`
+	Key   string `json:"key" bson:"key"`
+	Field string `json:"field,omitempty" bson:"field,omitempty"`
+}
+
+// GraphMLMapping turns GraphML nodes into entities and edges into relations.
+// Their data become properties, except the attributes giving their types.
+// Nodes without a type attribute are of NodeType, and edges of EdgeType.
+//
+// Nodes are identified by their Key property, name by default, whose value
+// is their data of the same name or else their GraphML ID.
+type GraphMLMapping struct {
+	NodeType          string `json:"node_type,omitempty" bson:"node_type,omitempty"`
+	NodeTypeAttribute string `json:"node_type_attribute,omitempty" bson:"node_type_attribute,omitempty"`
+	EdgeType          string `json:"edge_type,omitempty" bson:"edge_type,omitempty"`
+	EdgeTypeAttribute string `json:"edge_type_attribute,omitempty" bson:"edge_type_attribute,omitempty"`
+	Key               string `json:"key,omitempty" bson:"key,omitempty"`
+}
+
+// ImportInput describes an uploaded file and what to do with it. Format is
+// detected from the file name when empty.
+type ImportInput struct {
+	Name        string
+	ContentType string
+	Size        int64
+	Format      ImportFormat
+	DryRun      bool
+	Mapping     *ImportMapping
+}
+
+// ImportProgress counts what an import has done so far. Entities and
+// Relations count the rows written, or that would be written by a dry run;
+// the created counts leave out those that updated existing ones.
+type ImportProgress struct {
+	BytesRead        int64 `json:"bytes_read" bson:"bytes_read"`
+	Rows             int   `json:"rows" bson:"rows"`
+	Entities         int   `json:"entities" bson:"entities"`
+	EntitiesCreated  int   `json:"entities_created" bson:"entities_created"`
+	Relations        int   `json:"relations" bson:"relations"`
+	RelationsCreated int   `json:"relations_created" bson:"relations_created"`
+	Errors           int   `json:"errors" bson:"errors"`
+}
+
+// ImportRowError explains why a row, or a node or edge of a GraphML file,
+// was not imported. Rows count from 1, leaving out the CSV header.
+type ImportRowError struct {
+	Row     int    `json:"row" bson:"row"`
+	Target  string `json:"target,omitempty" bson:"target,omitempty"`
+	Message string `json:"message" bson:"message"`
+}
+
+// ImportJob tracks the import of one file. The file is kept in storage until
+// the import finishes. A dry run reads and checks the file against the
+// workspace schema without writing to the graph.
+type ImportJob struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	WorkspaceID   string             `json:"workspace_id" bson:"workspace_id"`
+	Name          string             `json:"name" bson:"name"`
+	Format        ImportFormat       `json:"format" bson:"format"`
+	Size          int64              `json:"size" bson:"size"`
+	DryRun        bool               `json:"dry_run" bson:"dry_run"`
+	Mapping       *ImportMapping     `json:"mapping" bson:"mapping"`
+	Status        ImportStatus       `json:"status" bson:"status"`
+	Attempts      int                `json:"attempts" bson:"attempts"`
+	Error         string             `json:"error,omitempty" bson:"error,omitempty"`
+	Progress      ImportProgress     `json:"progress" bson:"progress"`
+	Errors        []ImportRowError   `json:"-" bson:"errors"`
+	ObjectKey     string             `json:"-" bson:"object_key"`
+	CreatedBy     string             `json:"created_by" bson:"created_by"`
+	NextAttemptAt time.Time          `json:"next_attempt_at" bson:"next_attempt_at"`
+	LockedUntil   time.Time          `json:"-" bson:"locked_until,omitempty"`
+	StartedAt     *time.Time         `json:"started_at,omitempty" bson:"started_at,omitempty"`
+	FinishedAt    *time.Time         `json:"finished_at,omitempty" bson:"finished_at,omitempty"`
+	CreatedAt     time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// ImportErrors lists the row errors of an import. Truncated is set when
+// there were more than MaxImportErrors.
+type ImportErrors struct {
+	Errors    []ImportRowError `json:"errors"`
+	Count     int              `json:"count"`
+	Truncated bool             `json:"truncated"`
+}
+
+type ImportListQuery struct {
+	Status string `query:"status" validate:"omitempty,oneof=queued processing succeeded failed"`
+	Page   int64  `query:"page"`
+	Limit  int64  `query:"limit"`
+}
+
+// ImportEntityBatch merges entities of one type on their Key property.
+type ImportEntityBatch struct {
+	Type string
+	Key  string
+	Rows []ImportEntityRow
+}
+
+type ImportEntityRow struct {
+	Row        int
+	Key        interface{}
+	Properties map[string]interface{}
+}
+
+// ImportRelationBatch merges relations of one type between entities
+// identified the same way.
+type ImportRelationBatch struct {
+	Type   string
+	Source ImportEndpoint
+	Target ImportEndpoint
+	Rows   []ImportRelationRow
+}
+
+type ImportRelationRow struct {
+	Row        int
+	Source     interface{}
+	Target     interface{}
+	Properties map[string]interface{}
+}
+
+// ImportEndpointMatch reports whether the ends of a relation row exist.
+type ImportEndpointMatch struct {
+	Row    int
+	Source bool
+	Target bool
+}
@@ -0,0 +1,471 @@
+package knowledge
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// countingReader counts the bytes read from a file, for the progress of its
+// import. It keeps the error reading failed with, which the parsers would
+// otherwise report as a broken file.
+type countingReader struct {
+	reader io.Reader
+	count  int64
+	err    error
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
+
+// importRecord is a row of a CSV file or a record of a JSON file. Err is set
+// when the record cannot be read; it is then skipped.
+type importRecord struct {
+	Row     int
+	Err     string
+	columns map[string]string
+	value   interface{}
+}
+
+// Field returns the value of a CSV column, or of a JSON path. Empty columns
+// and JSON nulls count as absent.
+func (r *importRecord) Field(name string) (interface{}, bool) {
+	if r.columns != nil {
+		value, ok := r.columns[name]
+		if !ok || value == "" {
+			return nil, false
+		}
+		return value, true
+	}
+
+	value, ok := lookupPath(r.value, name)
+	if !ok || value == nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// recordReader reads the records of a file one at a time. Next returns
+// io.EOF after the last one; any other error means the file cannot be read.
+type recordReader interface {
+	Next() (*importRecord, error)
+}
+
+type csvRecordReader struct {
+	reader *csv.Reader
+	header []string
+	row    int
+}
+
+// newCSVReader reads the header of a CSV file, whose columns name the fields
+// of its rows.
+func newCSVReader(reader io.Reader, delimiter string) (*csvRecordReader, error) {
+	r := csv.NewReader(reader)
+	if delimiter != "" {
+		r.Comma, _ = utf8.DecodeRuneInString(delimiter)
+	}
+
+	header, err := r.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, permanent(fmt.Errorf("invalid CSV file: the header row is missing"))
+		}
+		return nil, permanent(fmt.Errorf("invalid CSV file: %w", err))
+	}
+
+	seen := make(map[string]bool, len(header))
+	for i, column := range header {
+		if i == 0 {
+			column = strings.TrimPrefix(column, "\ufeff")
+		}
+		column = strings.TrimSpace(column)
+		if column != "" && seen[column] {
+			return nil, permanent(fmt.Errorf("invalid CSV file: column %q appears twice", column))
+		}
+		seen[column] = true
+		header[i] = column
+	}
+
+	return &csvRecordReader{reader: r, header: header}, nil
+}
+
+func (r *csvRecordReader) Next() (*importRecord, error) {
+	values, err := r.reader.Read()
+	if err == io.EOF {
+		return nil, err
+	}
+	r.row++
+
+	if err != nil {
+		if errors.Is(err, csv.ErrFieldCount) {
+			return &importRecord{
+				Row: r.row,
+				Err: fmt.Sprintf("has %d columns, the header has %d", len(values), len(r.header)),
+			}, nil
+		}
+		return nil, permanent(fmt.Errorf("invalid CSV file: %w", err))
+	}
+
+	columns := make(map[string]string, len(r.header))
+	for i, column := range r.header {
+		if column != "" {
+			columns[column] = strings.TrimSpace(values[i])
+		}
+	}
+
+	return &importRecord{Row: r.row, columns: columns}, nil
+}
+
+type jsonRecordReader struct {
+	decoder *json.Decoder
+	lines   bool
+	row     int
+}
+
+// newJSONReader finds the list of records at path, a JSON path of keys and
+// list indexes separated by dots. Without a path the file holds a list of
+// records, or one record per line.
+func newJSONReader(reader io.Reader, path string) (*jsonRecordReader, error) {
+	buffered := bufio.NewReader(reader)
+	decoder := json.NewDecoder(buffered)
+
+	if path == "" {
+		first, err := firstNonSpace(buffered)
+		if err != nil {
+			return nil, err
+		}
+		if first == '{' {
+			return &jsonRecordReader{decoder: decoder, lines: true}, nil
+		}
+		if err := expectDelim(decoder, '['); err != nil {
+			return nil, err
+		}
+		return &jsonRecordReader{decoder: decoder}, nil
+	}
+
+	for _, segment := range strings.Split(path, ".") {
+		if err := descend(decoder, segment); err != nil {
+			return nil, err
+		}
+	}
+	if err := expectDelim(decoder, '['); err != nil {
+		return nil, permanent(fmt.Errorf("invalid JSON file: %q is not a list", path))
+	}
+
+	return &jsonRecordReader{decoder: decoder}, nil
+}
+
+func (r *jsonRecordReader) Next() (*importRecord, error) {
+	if !r.lines && !r.decoder.More() {
+		return nil, io.EOF
+	}
+
+	var value interface{}
+	if err := r.decoder.Decode(&value); err != nil {
+		if err == io.EOF && r.lines {
+			return nil, err
+		}
+		return nil, permanent(fmt.Errorf("invalid JSON file: %w", err))
+	}
+	r.row++
+
+	if _, ok := value.(map[string]interface{}); !ok {
+		return &importRecord{Row: r.row, Err: "is not an object"}, nil
+	}
+
+	return &importRecord{Row: r.row, value: value}, nil
+}
+
+func firstNonSpace(reader *bufio.Reader) (byte, error) {
+	for {
+		b, err := reader.Peek(1)
+		if err != nil {
+			if err == io.EOF {
+				return 0, permanent(fmt.Errorf("invalid JSON file: the file is empty"))
+			}
+			return 0, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			reader.ReadByte()
+		default:
+			return b[0], nil
+		}
+	}
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return permanent(fmt.Errorf("invalid JSON file: %w", err))
+	}
+	if token != delim {
+		return permanent(fmt.Errorf("invalid JSON file: expected %s", delim))
+	}
+
+	return nil
+}
+
+// descend moves the decoder into the value of a key of the next object, or
+// of an index of the next list, skipping the values before it.
+func descend(decoder *json.Decoder, segment string) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return permanent(fmt.Errorf("invalid JSON file: %w", err))
+	}
+
+	switch token {
+	case json.Delim('{'):
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return permanent(fmt.Errorf("invalid JSON file: %w", err))
+			}
+			if key == segment {
+				return nil
+			}
+			if err := skipValue(decoder); err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		index, err := strconv.Atoi(segment)
+		if err != nil || index < 0 {
+			break
+		}
+		for i := 0; decoder.More(); i++ {
+			if i == index {
+				return nil
+			}
+			if err := skipValue(decoder); err != nil {
+				return err
+			}
+		}
+	}
+
+	return permanent(fmt.Errorf("invalid JSON file: %q not found", segment))
+}
+
+func skipValue(decoder *json.Decoder) error {
+	var raw json.RawMessage
+	if err := decoder.Decode(&raw); err != nil {
+		return permanent(fmt.Errorf("invalid JSON file: %w", err))
+	}
+
+	return nil
+}
+
+// lookupPath follows a JSON path of keys and list indexes separated by dots.
+func lookupPath(value interface{}, path string) (interface{}, bool) {
+	for _, segment := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			next, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			value = v[index]
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// graphItem is a node or an edge of a GraphML file, with its data by
+// attribute name. Err is set when the item cannot be imported; it is then
+// skipped.
+type graphItem struct {
+	Row    int
+	Node   bool
+	ID     string
+	Source string
+	Target string
+	Data   map[string]interface{}
+	Err    string
+}
+
+type graphKey struct {
+	ID      string  `xml:"id,attr"`
+	For     string  `xml:"for,attr"`
+	Name    string  `xml:"attr.name,attr"`
+	Type    string  `xml:"attr.type,attr"`
+	Default *string `xml:"default"`
+}
+
+type graphData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphElement struct {
+	ID     string      `xml:"id,attr"`
+	Source string      `xml:"source,attr"`
+	Target string      `xml:"target,attr"`
+	Data   []graphData `xml:"data"`
+	Graphs []struct{}  `xml:"graph"`
+}
+
+// graphMLReader reads the nodes and edges of a GraphML file in document
+// order. Keys are declared before the graph, so their names and types are
+// known by the time data refers to them.
+type graphMLReader struct {
+	decoder *xml.Decoder
+	keys    map[string]graphKey
+	row     int
+}
+
+func newGraphMLReader(reader io.Reader) *graphMLReader {
+	return &graphMLReader{
+		decoder: xml.NewDecoder(reader),
+		keys:    map[string]graphKey{},
+	}
+}
+
+func (r *graphMLReader) Next() (*graphItem, error) {
+	for {
+		token, err := r.decoder.Token()
+		if err == io.EOF {
+			return nil, err
+		}
+		if err != nil {
+			return nil, permanent(fmt.Errorf("invalid GraphML file: %w", err))
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "key":
+			var key graphKey
+			if err := r.decoder.DecodeElement(&key, &start); err != nil {
+				return nil, permanent(fmt.Errorf("invalid GraphML file: %w", err))
+			}
+			r.keys[key.ID] = key
+		case "node", "edge":
+			var element graphElement
+			if err := r.decoder.DecodeElement(&element, &start); err != nil {
+				return nil, permanent(fmt.Errorf("invalid GraphML file: %w", err))
+			}
+			r.row++
+			return r.item(start.Name.Local == "node", &element), nil
+		case "hyperedge":
+			if err := r.decoder.Skip(); err != nil {
+				return nil, permanent(fmt.Errorf("invalid GraphML file: %w", err))
+			}
+			r.row++
+			return &graphItem{Row: r.row, Err: "hyperedges are not supported"}, nil
+		}
+	}
+}
+
+func (r *graphMLReader) item(node bool, element *graphElement) *graphItem {
+	item := &graphItem{
+		Row:    r.row,
+		Node:   node,
+		ID:     element.ID,
+		Source: element.Source,
+		Target: element.Target,
+		Data:   map[string]interface{}{},
+	}
+
+	kind := "edge"
+	if node {
+		kind = "node"
+	}
+
+	if node && len(element.Graphs) > 0 {
+		item.Err = "nested graphs are not supported"
+		return item
+	}
+
+	for _, key := range r.keys {
+		if key.Default == nil || (key.For != kind && key.For != "all") {
+			continue
+		}
+		if value, err := graphValue(key, *key.Default); err == nil && value != nil {
+			item.Data[keyName(key)] = value
+		}
+	}
+
+	for _, data := range element.Data {
+		key, ok := r.keys[data.Key]
+		if !ok {
+			key = graphKey{ID: data.Key}
+		}
+
+		value, err := graphValue(key, data.Value)
+		if err != nil {
+			item.Err = fmt.Sprintf("data %q %s", keyName(key), err)
+			return item
+		}
+		if value == nil {
+			delete(item.Data, keyName(key))
+		} else {
+			item.Data[keyName(key)] = value
+		}
+	}
+
+	return item
+}
+
+func keyName(key graphKey) string {
+	if key.Name != "" {
+		return key.Name
+	}
+	return key.ID
+}
+
+// graphValue converts data to the type its key declares. Blank data counts
+// as absent.
+func graphValue(key graphKey, data string) (interface{}, error) {
+	data = strings.TrimSpace(data)
+	if data == "" {
+		return nil, nil
+	}
+
+	switch key.Type {
+	case "boolean":
+		value, err := strconv.ParseBool(data)
+		if err != nil {
+			return nil, fmt.Errorf("must be a boolean")
+		}
+		return value, nil
+	case "int", "long":
+		value, err := strconv.ParseInt(data, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be an integer")
+		}
+		return value, nil
+	case "float", "double":
+		value, err := strconv.ParseFloat(data, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return value, nil
+	default:
+		return data, nil
+	}
+}
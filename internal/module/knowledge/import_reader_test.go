@@ -0,0 +1,170 @@
+package knowledge
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readRecords(t *testing.T, records recordReader) []*importRecord {
+	t.Helper()
+
+	var result []*importRecord
+	for {
+		record, err := records.Next()
+		if err == io.EOF {
+			return result
+		}
+		require.NoError(t, err)
+		result = append(result, record)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	file := "\ufeffname; employer ;born\nAda Lovelace; Analytical Engines ;\nCharles Babbage\n\"Lovelace; Ada\";;1815-12-10\n"
+
+	reader, err := newCSVReader(strings.NewReader(file), ";")
+	require.NoError(t, err)
+	records := readRecords(t, reader)
+	require.Len(t, records, 3)
+
+	name, ok := records[0].Field("name")
+	assert.True(t, ok)
+	assert.Equal(t, "Ada Lovelace", name)
+	employer, _ := records[0].Field("employer")
+	assert.Equal(t, "Analytical Engines", employer)
+	_, ok = records[0].Field("born")
+	assert.False(t, ok, "empty cells are absent")
+
+	assert.Equal(t, 2, records[1].Row)
+	assert.Equal(t, "has 1 columns, the header has 3", records[1].Err)
+
+	name, _ = records[2].Field("name")
+	assert.Equal(t, "Lovelace; Ada", name)
+
+	_, err = newCSVReader(strings.NewReader("name,name\n"), "")
+	assert.EqualError(t, err, `invalid CSV file: column "name" appears twice`)
+
+	_, err = newCSVReader(strings.NewReader(""), "")
+	assert.EqualError(t, err, "invalid CSV file: the header row is missing")
+
+	reader, err = newCSVReader(strings.NewReader("name\n\"Ada\n"), "")
+	require.NoError(t, err)
+	_, err = reader.Next()
+	var permanentErr *permanentError
+	assert.True(t, errors.As(err, &permanentErr))
+}
+
+func TestJSONReader(t *testing.T) {
+	t.Run("records path", func(t *testing.T) {
+		file := `{"meta": {"skip": [1, 2]}, "data": {"people": [
+			{"name": "Ada Lovelace", "company": {"name": "Analytical Engines"}, "tags": ["math", "poetry"]},
+			"not a record",
+			{"name": null}
+		]}}`
+
+		reader, err := newJSONReader(strings.NewReader(file), "data.people")
+		require.NoError(t, err)
+		records := readRecords(t, reader)
+		require.Len(t, records, 3)
+
+		company, ok := records[0].Field("company.name")
+		assert.True(t, ok)
+		assert.Equal(t, "Analytical Engines", company)
+		tag, _ := records[0].Field("tags.1")
+		assert.Equal(t, "poetry", tag)
+		_, ok = records[0].Field("tags.2")
+		assert.False(t, ok)
+
+		assert.Equal(t, "is not an object", records[1].Err)
+
+		_, ok = records[2].Field("name")
+		assert.False(t, ok, "nulls are absent")
+	})
+
+	t.Run("list index in records path", func(t *testing.T) {
+		reader, err := newJSONReader(strings.NewReader(`[[{"a": 1}], [{"a": 2}, {"a": 3}]]`), "1")
+		require.NoError(t, err)
+		records := readRecords(t, reader)
+		require.Len(t, records, 2)
+		value, _ := records[1].Field("a")
+		assert.Equal(t, float64(3), value)
+	})
+
+	t.Run("top-level list", func(t *testing.T) {
+		reader, err := newJSONReader(strings.NewReader(` [{"a": 1}, {"a": 2}]`), "")
+		require.NoError(t, err)
+		assert.Len(t, readRecords(t, reader), 2)
+	})
+
+	t.Run("JSON lines", func(t *testing.T) {
+		reader, err := newJSONReader(strings.NewReader("{\"a\": 1}\n{\"a\": 2}\n{\"a\": 3}\n"), "")
+		require.NoError(t, err)
+		records := readRecords(t, reader)
+		require.Len(t, records, 3)
+		assert.Equal(t, 3, records[2].Row)
+	})
+
+	t.Run("missing records path", func(t *testing.T) {
+		_, err := newJSONReader(strings.NewReader(`{"data": {}}`), "data.people")
+		assert.EqualError(t, err, `invalid JSON file: "people" not found`)
+
+		_, err = newJSONReader(strings.NewReader(`{"data": {"people": {}}}`), "data.people")
+		assert.EqualError(t, err, `invalid JSON file: "data.people" is not a list`)
+	})
+}
+
+func TestGraphMLReader(t *testing.T) {
+	file := `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="d0" for="node" attr.name="labels" attr.type="string"/>
+  <key id="d1" for="node" attr.name="name" attr.type="string"/>
+  <key id="d2" for="node" attr.name="born" attr.type="int"/>
+  <key id="d3" for="node" attr.name="active" attr.type="boolean"><default>true</default></key>
+  <key id="d4" for="edge" attr.name="label" attr.type="string"/>
+  <key id="d5" for="edge" attr.name="weight" attr.type="double"/>
+  <graph id="G" edgedefault="directed">
+    <node id="n0"><data key="d0">:Person</data><data key="d1">Ada Lovelace</data><data key="d2">1815</data></node>
+    <node id="n1"><data key="d1">Charles Babbage</data><data key="d3">false</data></node>
+    <node id="n2"><data key="d2">eighteen</data></node>
+    <edge id="e0" source="n0" target="n1"><data key="d4">KNOWS</data><data key="d5">0.5</data></edge>
+    <hyperedge><endpoint node="n0"/><endpoint node="n1"/><endpoint node="n2"/></hyperedge>
+  </graph>
+</graphml>`
+
+	reader := newGraphMLReader(strings.NewReader(file))
+	var items []*graphItem
+	for {
+		item, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		items = append(items, item)
+	}
+	require.Len(t, items, 5)
+
+	assert.True(t, items[0].Node)
+	assert.Equal(t, "n0", items[0].ID)
+	assert.Equal(t, map[string]interface{}{"labels": ":Person", "name": "Ada Lovelace", "born": int64(1815), "active": true}, items[0].Data)
+
+	assert.Equal(t, false, items[1].Data["active"])
+
+	assert.Equal(t, 3, items[2].Row)
+	assert.Equal(t, `data "born" must be an integer`, items[2].Err)
+
+	assert.False(t, items[3].Node)
+	assert.Equal(t, "n0", items[3].Source)
+	assert.Equal(t, "n1", items[3].Target)
+	assert.Equal(t, map[string]interface{}{"label": "KNOWS", "weight": 0.5}, items[3].Data)
+
+	assert.Equal(t, "hyperedges are not supported", items[4].Err)
+
+	_, err := newGraphMLReader(strings.NewReader("<graphml><graph><node id=\"n0\">")).Next()
+	var permanentErr *permanentError
+	assert.True(t, errors.As(err, &permanentErr))
+}
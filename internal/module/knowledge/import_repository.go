@@ -0,0 +1,188 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v7"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/minio"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+type ImportJobRepository interface {
+	Create(ctx context.Context, job *ImportJob) (*ImportJob, error)
+	GetByID(ctx context.Context, workspaceID string, id primitive.ObjectID) (*ImportJob, error)
+	List(ctx context.Context, filter bson.M, pagination mongo.PaginationOptions) (*mongo.PaginatedResult[ImportJob], error)
+	Claim(ctx context.Context, now time.Time, lease time.Duration) (*ImportJob, error)
+	UpdateAttempt(ctx context.Context, id primitive.ObjectID, attempts int, update bson.M) (*ImportJob, error)
+	DeleteByWorkspace(ctx context.Context, workspaceID string) error
+}
+
+type importJobRepository struct {
+	repo mongo.Repository[ImportJob]
+}
+
+var _ ImportJobRepository = (*importJobRepository)(nil)
+
+func NewImportJobRepository(mongoService *mongo.MongoService) ImportJobRepository {
+	return &importJobRepository{
+		repo: mongo.NewRepository[ImportJob](mongoService, ImportCollectionName),
+	}
+}
+
+func (r *importJobRepository) Create(ctx context.Context, job *ImportJob) (*ImportJob, error) {
+	if job.ID.IsZero() {
+		job.ID = primitive.NewObjectID()
+	}
+
+	result, err := r.repo.Create(ctx, *job)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create import: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *importJobRepository) GetByID(ctx context.Context, workspaceID string, id primitive.ObjectID) (*ImportJob, error) {
+	result, err := r.repo.FindOne(ctx, bson.M{"_id": id, "workspace_id": workspaceID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get import: %w", err)
+	}
+
+	return result, nil
+}
+
+// List sorts imports newest first and leaves out their row errors.
+func (r *importJobRepository) List(ctx context.Context, filter bson.M, pagination mongo.PaginationOptions) (*mongo.PaginatedResult[ImportJob], error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetProjection(bson.M{"errors": 0})
+
+	result, err := r.repo.FindWithPagination(ctx, filter, pagination, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list imports: %w", err)
+	}
+
+	return result, nil
+}
+
+// Claim hands one due import to the caller, counts the attempt and clears
+// the progress of earlier ones. Queued imports are due once their next
+// attempt time has passed; processing ones once their lease has run out. It
+// returns nil when nothing is due.
+func (r *importJobRepository) Claim(ctx context.Context, now time.Time, lease time.Duration) (*ImportJob, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": ImportStatusQueued, "next_attempt_at": bson.M{"$lte": now}},
+		bson.M{"status": ImportStatusProcessing, "locked_until": bson.M{"$lte": now}},
+	}}
+	update := bson.M{
+		"$set": bson.M{
+			"status":       ImportStatusProcessing,
+			"progress":     ImportProgress{},
+			"errors":       []ImportRowError{},
+			"locked_until": now.Add(lease),
+			"started_at":   now,
+			"updated_at":   now,
+		},
+		"$inc": bson.M{"attempts": 1},
+	}
+
+	result, err := r.repo.Update(ctx, filter, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim import: %w", err)
+	}
+
+	return result, nil
+}
+
+// UpdateAttempt only applies the update while the import is still on the
+// given attempt, so a worker whose lease ran out cannot overwrite the
+// progress of the worker that took over. It returns nil in that case.
+func (r *importJobRepository) UpdateAttempt(ctx context.Context, id primitive.ObjectID, attempts int, update bson.M) (*ImportJob, error) {
+	set, _ := update["$set"].(bson.M)
+	if set == nil {
+		set = bson.M{}
+		update["$set"] = set
+	}
+	set["updated_at"] = time.Now()
+
+	result, err := r.repo.Update(ctx, bson.M{"_id": id, "attempts": attempts}, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update import: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *importJobRepository) DeleteByWorkspace(ctx context.Context, workspaceID string) error {
+	if _, err := r.repo.DeleteMany(ctx, bson.M{"workspace_id": workspaceID}); err != nil {
+		return fmt.Errorf("failed to delete imports: %w", err)
+	}
+
+	return nil
+}
+
+// ImportStorage keeps uploaded files until their import finishes.
+type ImportStorage interface {
+	Put(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+type minioImportStorage struct {
+	minio minio.MinIOService
+}
+
+var _ ImportStorage = (*minioImportStorage)(nil)
+
+func NewImportStorage(minioService minio.MinIOService) ImportStorage {
+	return &minioImportStorage{
+		minio: minioService,
+	}
+}
+
+func (s *minioImportStorage) Put(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error {
+	return s.minio.PutObject(ctx, key, reader, size, miniogo.PutObjectOptions{ContentType: contentType})
+}
+
+func (s *minioImportStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	object, err := s.minio.GetObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// GetObject is lazy; stat it so a missing key fails here rather than on
+	// the first read.
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		return nil, err
+	}
+
+	return object, nil
+}
+
+func (s *minioImportStorage) Delete(ctx context.Context, key string) error {
+	return s.minio.DeleteObject(ctx, key)
+}
+
+func (s *minioImportStorage) DeletePrefix(ctx context.Context, prefix string) error {
+	objects, err := s.minio.ListObjects(ctx, prefix, true)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range objects {
+		if err := s.minio.DeleteObject(ctx, object.Key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,617 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+// ImportService imports CSV, JSON and GraphML files into the knowledge
+// graph. Files are stored and imported by a background worker, which writes
+// them in batches and reports its progress and row errors on the import.
+type ImportService interface {
+	CreateImport(ctx context.Context, workspaceID, accountID string, input *ImportInput, body io.Reader) (*ImportJob, error)
+	ListImports(ctx context.Context, workspaceID string, query *ImportListQuery) (*mongo.PaginatedResult[ImportJob], error)
+	GetImport(ctx context.Context, workspaceID, id string) (*ImportJob, error)
+	GetImportErrors(ctx context.Context, workspaceID, id string) (*ImportErrors, error)
+	ProcessNext(ctx context.Context) (bool, error)
+	DeleteWorkspace(ctx context.Context, workspaceID string) error
+	Start()
+	Shutdown() error
+}
+
+type importService struct {
+	jobs      ImportJobRepository
+	storage   ImportStorage
+	knowledge *knowledgeService
+	maxSize   int64
+
+	interval time.Duration
+	wake     chan struct{}
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newImportService(jobs ImportJobRepository, storage ImportStorage, knowledge *knowledgeService, maxSize int64) *importService {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxImportSize
+	}
+
+	return &importService{
+		jobs:      jobs,
+		storage:   storage,
+		knowledge: knowledge,
+		maxSize:   maxSize,
+		interval:  ImportPollInterval,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+var errImportTakenOver = errors.New("import was taken over by another worker")
+
+// permanentError marks a failure that another attempt cannot fix, such as a
+// file that cannot be parsed.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// CreateImport stores the file and queues its import. The format is taken
+// from the file name unless given, and the mapping is checked up front so
+// that a mistake in it fails the request rather than every row.
+func (s *importService) CreateImport(ctx context.Context, workspaceID, accountID string, input *ImportInput, body io.Reader) (*ImportJob, error) {
+	format, delimiter, err := importFormat(input.Format, input.Name)
+	if err != nil {
+		return nil, err
+	}
+	if input.Size > s.maxSize {
+		return nil, fmt.Errorf("file is larger than %d bytes", s.maxSize)
+	}
+
+	mapping, err := normalizeImportMapping(format, input.Mapping)
+	if err != nil {
+		return nil, err
+	}
+	if mapping.Delimiter == "" {
+		mapping.Delimiter = delimiter
+	}
+
+	contentType := input.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	id := primitive.NewObjectID()
+	key := importPrefix(workspaceID) + id.Hex()
+	if err := s.storage.Put(ctx, key, body, input.Size, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store import file: %w", err)
+	}
+
+	now := time.Now()
+	job, err := s.jobs.Create(ctx, &ImportJob{
+		ID:            id,
+		WorkspaceID:   workspaceID,
+		Name:          input.Name,
+		Format:        format,
+		Size:          input.Size,
+		DryRun:        input.DryRun,
+		Mapping:       mapping,
+		Status:        ImportStatusQueued,
+		Errors:        []ImportRowError{},
+		ObjectKey:     key,
+		CreatedBy:     accountID,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	if err != nil {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			fmt.Printf("Failed to delete import file %s: %v\n", key, err)
+		}
+		return nil, err
+	}
+	s.notify()
+
+	return job, nil
+}
+
+func (s *importService) ListImports(ctx context.Context, workspaceID string, query *ImportListQuery) (*mongo.PaginatedResult[ImportJob], error) {
+	filter := bson.M{"workspace_id": workspaceID}
+	if query.Status != "" {
+		filter["status"] = query.Status
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = ImportListDefaultLimit
+	}
+	if limit > ImportListMaxLimit {
+		limit = ImportListMaxLimit
+	}
+
+	return s.jobs.List(ctx, filter, mongo.PaginationOptions{
+		Page:  query.Page,
+		Limit: limit,
+	})
+}
+
+func (s *importService) GetImport(ctx context.Context, workspaceID, id string) (*ImportJob, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("import not found")
+	}
+
+	job, err := s.jobs.GetByID(ctx, workspaceID, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, fmt.Errorf("import not found")
+	}
+
+	return job, nil
+}
+
+func (s *importService) GetImportErrors(ctx context.Context, workspaceID, id string) (*ImportErrors, error) {
+	job, err := s.GetImport(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rowErrors := job.Errors
+	if rowErrors == nil {
+		rowErrors = []ImportRowError{}
+	}
+
+	return &ImportErrors{
+		Errors:    rowErrors,
+		Count:     job.Progress.Errors,
+		Truncated: job.Progress.Errors > len(rowErrors),
+	}, nil
+}
+
+// ProcessNext claims one due import and runs it. It reports whether an
+// import was found; failures of the import itself are recorded on it rather
+// than returned.
+func (s *importService) ProcessNext(ctx context.Context) (bool, error) {
+	job, err := s.jobs.Claim(ctx, time.Now(), ImportLease)
+	if err != nil {
+		return false, err
+	}
+	if job == nil {
+		return false, nil
+	}
+
+	if err := s.run(ctx, job); err != nil {
+		if errors.Is(err, errImportTakenOver) {
+			return true, nil
+		}
+		if err := s.fail(ctx, job, err); err != nil {
+			return true, err
+		}
+	}
+
+	return true, nil
+}
+
+// DeleteWorkspace removes the imports of a workspace and the files of those
+// that have not finished.
+func (s *importService) DeleteWorkspace(ctx context.Context, workspaceID string) error {
+	if err := s.storage.DeletePrefix(ctx, importPrefix(workspaceID)); err != nil {
+		return fmt.Errorf("failed to delete import files: %w", err)
+	}
+
+	return s.jobs.DeleteByWorkspace(ctx, workspaceID)
+}
+
+func (s *importService) run(ctx context.Context, job *ImportJob) error {
+	if job.Attempts > ImportMaxAttempts {
+		return permanent(fmt.Errorf("gave up after %d attempts", ImportMaxAttempts))
+	}
+
+	schema, err := s.knowledge.schemas.GetLatest(ctx, job.WorkspaceID)
+	if err != nil {
+		return err
+	}
+
+	reader, err := s.storage.Open(ctx, job.ObjectKey)
+	if err != nil {
+		return fmt.Errorf("failed to open import file: %w", err)
+	}
+	defer reader.Close()
+
+	file := &countingReader{reader: reader}
+	im := newImporter(s.knowledge, job, schema)
+	im.save = func(ctx context.Context) error {
+		return s.save(ctx, job, im, file, bson.M{"locked_until": time.Now().Add(ImportLease)}, nil)
+	}
+
+	if err := s.read(ctx, job, im, file); err != nil {
+		// Keep the progress made before the failure; another attempt starts
+		// over and merges onto what was written.
+		if saveErr := im.save(ctx); errors.Is(saveErr, errImportTakenOver) {
+			return saveErr
+		}
+		return err
+	}
+
+	if err := s.save(ctx, job, im, file, bson.M{
+		"status":      ImportStatusSucceeded,
+		"finished_at": time.Now(),
+	}, bson.M{"error": "", "locked_until": ""}); err != nil {
+		return err
+	}
+	s.deleteFile(ctx, job)
+
+	return nil
+}
+
+// read imports the file. Files that cannot be parsed fail for good, but
+// failing to read them, or to write to the graph, is worth another attempt.
+func (s *importService) read(ctx context.Context, job *ImportJob, im *importer, file *countingReader) error {
+	var err error
+	switch job.Format {
+	case ImportFormatCSV:
+		var records *csvRecordReader
+		if records, err = newCSVReader(file, job.Mapping.Delimiter); err == nil {
+			err = im.importRecords(ctx, records)
+		}
+	case ImportFormatJSON:
+		var records *jsonRecordReader
+		if records, err = newJSONReader(file, job.Mapping.Records); err == nil {
+			err = im.importRecords(ctx, records)
+		}
+	case ImportFormatGraphML:
+		err = im.importGraph(ctx, newGraphMLReader(file))
+	default:
+		err = permanent(fmt.Errorf("unsupported format %q", job.Format))
+	}
+
+	if err != nil && file.err != nil {
+		return fmt.Errorf("failed to read import file: %w", file.err)
+	}
+	return err
+}
+
+func (s *importService) save(ctx context.Context, job *ImportJob, im *importer, file *countingReader, set, unset bson.M) error {
+	im.progress.BytesRead = file.count
+	set["progress"] = im.progress
+	set["errors"] = im.errors
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	updated, err := s.jobs.UpdateAttempt(ctx, job.ID, job.Attempts, update)
+	if err != nil {
+		return err
+	}
+	if updated == nil {
+		return errImportTakenOver
+	}
+
+	return nil
+}
+
+// fail records a failed attempt. Permanent failures and the last attempt
+// mark the import failed; anything else is queued again after a delay that
+// doubles with every attempt.
+func (s *importService) fail(ctx context.Context, job *ImportJob, cause error) error {
+	now := time.Now()
+	set := bson.M{"error": cause.Error()}
+
+	var permanentErr *permanentError
+	failed := errors.As(cause, &permanentErr) || job.Attempts >= ImportMaxAttempts
+	if failed {
+		set["status"] = ImportStatusFailed
+		set["finished_at"] = now
+	} else {
+		set["status"] = ImportStatusQueued
+		set["next_attempt_at"] = now.Add(ImportRetryDelay << (job.Attempts - 1))
+	}
+
+	updated, err := s.jobs.UpdateAttempt(ctx, job.ID, job.Attempts, bson.M{
+		"$set":   set,
+		"$unset": bson.M{"locked_until": ""},
+	})
+	if err != nil {
+		return err
+	}
+	if updated != nil && failed {
+		s.deleteFile(ctx, job)
+	}
+
+	return nil
+}
+
+// deleteFile removes the file of a finished import. A file left behind is
+// removed with the workspace.
+func (s *importService) deleteFile(ctx context.Context, job *ImportJob) {
+	if err := s.storage.Delete(ctx, job.ObjectKey); err != nil {
+		fmt.Printf("Failed to delete import file %s: %v\n", job.ObjectKey, err)
+	}
+}
+
+// notify wakes the worker without waiting for it.
+func (s *importService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *importService) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go s.loop(ctx, s.done)
+}
+
+// loop drains every due import, then sleeps until the next tick or until a
+// new import is queued.
+func (s *importService) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			processed, err := s.ProcessNext(ctx)
+			if err != nil {
+				fmt.Printf("Knowledge import failed: %v\n", err)
+				break
+			}
+			if !processed {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+func (s *importService) Shutdown() error {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	<-done
+	return nil
+}
+
+// importFormat returns the format of a file, detected from its name when not
+// given, and the default CSV delimiter for it.
+func importFormat(format ImportFormat, name string) (ImportFormat, string, error) {
+	if format != "" {
+		switch format {
+		case ImportFormatCSV, ImportFormatJSON, ImportFormatGraphML:
+			return format, "", nil
+		}
+		return "", "", fmt.Errorf("invalid format %q: use csv, json or graphml", format)
+	}
+
+	switch strings.ToLower(path.Ext(name)) {
+	case ".csv":
+		return ImportFormatCSV, "", nil
+	case ".tsv":
+		return ImportFormatCSV, "\t", nil
+	case ".json", ".jsonl", ".ndjson":
+		return ImportFormatJSON, "", nil
+	case ".graphml":
+		return ImportFormatGraphML, "", nil
+	}
+
+	return "", "", fmt.Errorf("invalid format: cannot tell the format of %q, give csv, json or graphml", name)
+}
+
+// normalizeImportMapping checks a mapping and normalizes its types the way
+// entities and relations created through the API are. GraphML files need no
+// mapping; its defaults are filled in.
+func normalizeImportMapping(format ImportFormat, mapping *ImportMapping) (*ImportMapping, error) {
+	if format == ImportFormatGraphML {
+		if mapping == nil {
+			mapping = &ImportMapping{}
+		}
+		if len(mapping.Entities) > 0 || len(mapping.Relations) > 0 {
+			return nil, fmt.Errorf("invalid mapping: GraphML files are mapped with graphml, not entities and relations")
+		}
+		if mapping.GraphML == nil {
+			mapping.GraphML = &GraphMLMapping{}
+		}
+		if err := normalizeGraphMLMapping(mapping.GraphML); err != nil {
+			return nil, fmt.Errorf("invalid mapping: %w", err)
+		}
+		mapping.Entities, mapping.Relations = []EntityMapping{}, []RelationMapping{}
+		return mapping, nil
+	}
+
+	if mapping == nil || len(mapping.Entities)+len(mapping.Relations) == 0 {
+		return nil, fmt.Errorf("invalid mapping: map the records to at least one entity or relation")
+	}
+	if len(mapping.Entities)+len(mapping.Relations) > maxImportMappings {
+		return nil, fmt.Errorf("invalid mapping: at most %d entities and relations", maxImportMappings)
+	}
+	if mapping.GraphML != nil {
+		return nil, fmt.Errorf("invalid mapping: graphml only applies to GraphML files")
+	}
+
+	if format == ImportFormatCSV && mapping.Delimiter != "" {
+		delimiter, size := utf8.DecodeRuneInString(mapping.Delimiter)
+		if size != len(mapping.Delimiter) || delimiter == utf8.RuneError || strings.ContainsRune("\"\r\n", delimiter) {
+			return nil, fmt.Errorf("invalid mapping: the delimiter must be one character other than a quote or line break")
+		}
+	}
+	if format != ImportFormatCSV && mapping.Delimiter != "" {
+		return nil, fmt.Errorf("invalid mapping: delimiter only applies to CSV files")
+	}
+	if format != ImportFormatJSON && mapping.Records != "" {
+		return nil, fmt.Errorf("invalid mapping: records only applies to JSON files")
+	}
+
+	for i := range mapping.Entities {
+		if err := normalizeEntityMapping(&mapping.Entities[i]); err != nil {
+			return nil, fmt.Errorf("invalid mapping: entity %d: %w", i+1, err)
+		}
+	}
+	for i := range mapping.Relations {
+		if err := normalizeRelationMapping(&mapping.Relations[i]); err != nil {
+			return nil, fmt.Errorf("invalid mapping: relation %d: %w", i+1, err)
+		}
+	}
+	if mapping.Entities == nil {
+		mapping.Entities = []EntityMapping{}
+	}
+	if mapping.Relations == nil {
+		mapping.Relations = []RelationMapping{}
+	}
+
+	return mapping, nil
+}
+
+func normalizeEntityMapping(mapping *EntityMapping) error {
+	entityType, err := normalizeEntityType(mapping.Type)
+	if err != nil {
+		return err
+	}
+	mapping.Type = entityType
+
+	if err := validateKeyProperty(mapping.Key); err != nil {
+		return err
+	}
+	if err := validatePropertyFields(mapping.Properties); err != nil {
+		return err
+	}
+	if _, ok := mapping.Properties[mapping.Key]; !ok {
+		return fmt.Errorf("key property %q is not mapped", mapping.Key)
+	}
+
+	return nil
+}
+
+func normalizeRelationMapping(mapping *RelationMapping) error {
+	relationType, err := normalizeRelationType(mapping.Type)
+	if err != nil {
+		return err
+	}
+	mapping.Type = relationType
+
+	for _, endpoint := range []*ImportEndpoint{&mapping.Source, &mapping.Target} {
+		entityType, err := normalizeEntityType(endpoint.Type)
+		if err != nil {
+			return err
+		}
+		endpoint.Type = entityType
+
+		if err := validateKeyProperty(endpoint.Key); err != nil {
+			return err
+		}
+		if endpoint.Field == "" {
+			return fmt.Errorf("the field identifying the %s entity is missing", endpointName(endpoint == &mapping.Source))
+		}
+	}
+
+	return validatePropertyFields(mapping.Properties)
+}
+
+func normalizeGraphMLMapping(mapping *GraphMLMapping) error {
+	if mapping.NodeType != "" {
+		if _, err := normalizeEntityType(mapping.NodeType); err != nil {
+			return err
+		}
+	}
+	if mapping.EdgeType != "" {
+		edgeType, err := normalizeRelationType(mapping.EdgeType)
+		if err != nil {
+			return err
+		}
+		mapping.EdgeType = edgeType
+	}
+
+	if mapping.NodeTypeAttribute == "" {
+		mapping.NodeTypeAttribute = "labels"
+	}
+	if mapping.EdgeTypeAttribute == "" {
+		mapping.EdgeTypeAttribute = "label"
+	}
+	if mapping.Key == "" {
+		mapping.Key = "name"
+	}
+
+	return validateKeyProperty(mapping.Key)
+}
+
+func validateKeyProperty(name string) error {
+	if !propertyPattern.MatchString(name) || reservedProperties[name] {
+		return fmt.Errorf("invalid key property %q", name)
+	}
+
+	return nil
+}
+
+func validatePropertyFields(properties map[string]string) error {
+	if len(properties) > maxProperties {
+		return fmt.Errorf("at most %d properties", maxProperties)
+	}
+
+	for name, field := range properties {
+		if !propertyPattern.MatchString(name) || reservedProperties[name] {
+			return fmt.Errorf("invalid property name %q", name)
+		}
+		if field == "" {
+			return fmt.Errorf("property %q has no field", name)
+		}
+	}
+
+	return nil
+}
+
+func endpointName(source bool) string {
+	if source {
+		return "source"
+	}
+	return "target"
+}
+
+func importPrefix(workspaceID string) string {
+	return "workspaces/" + workspaceID + "/imports/"
+}
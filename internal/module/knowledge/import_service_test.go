@@ -0,0 +1,375 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type importMocks struct {
+	repo    *MockKnowledgeRepository
+	jobs    *MockImportJobRepository
+	storage *MockImportStorage
+}
+
+// setupImportService returns a service for a workspace with the given
+// schema, or without one when schema is nil.
+func setupImportService(schema *Schema) (*importService, *importMocks) {
+	mocks := &importMocks{
+		repo:    &MockKnowledgeRepository{},
+		jobs:    &MockImportJobRepository{},
+		storage: &MockImportStorage{},
+	}
+
+	mockSchemas := &MockSchemaRepository{}
+	mockSchemas.On("GetLatest", mock.Anything, mock.Anything).Return(schema, nil).Maybe()
+	knowledge := newKnowledgeService(mocks.repo, mockSchemas, setupEvidenceRepository())
+
+	return newImportService(mocks.jobs, mocks.storage, knowledge, 1<<20), mocks
+}
+
+func createTestImportJob(format ImportFormat, mapping *ImportMapping, overrides ...func(*ImportJob)) *ImportJob {
+	mapping, err := normalizeImportMapping(format, mapping)
+	if err != nil {
+		panic(err)
+	}
+
+	job := &ImportJob{
+		ID:          primitive.NewObjectID(),
+		WorkspaceID: testWorkspaceID,
+		Format:      format,
+		Mapping:     mapping,
+		Status:      ImportStatusProcessing,
+		Attempts:    1,
+		ObjectKey:   importPrefix(testWorkspaceID) + "file",
+		CreatedBy:   testAccountID,
+	}
+	for _, override := range overrides {
+		override(job)
+	}
+
+	return job
+}
+
+// processImport claims job, runs it over file and returns the last update
+// stored on it.
+func processImport(t *testing.T, service *importService, mocks *importMocks, job *ImportJob, file string) bson.M {
+	t.Helper()
+
+	var last bson.M
+	mocks.jobs.On("Claim", mock.Anything, mock.Anything, ImportLease).Return(job, nil).Once()
+	mocks.storage.On("Open", mock.Anything, job.ObjectKey).Return(io.NopCloser(strings.NewReader(file)), nil)
+	mocks.storage.On("Delete", mock.Anything, job.ObjectKey).Return(nil).Maybe()
+	mocks.jobs.On("UpdateAttempt", mock.Anything, job.ID, job.Attempts, mock.Anything).
+		Run(func(args mock.Arguments) { last = args.Get(3).(bson.M) }).
+		Return(job, nil)
+
+	processed, err := service.ProcessNext(context.Background())
+	require.NoError(t, err)
+	require.True(t, processed)
+	require.NotNil(t, last)
+
+	return last
+}
+
+func TestImportService_CreateImport(t *testing.T) {
+	people := &ImportMapping{
+		Entities: []EntityMapping{
+			{Type: "Person", Key: "name", Properties: map[string]string{"name": "Name"}},
+		},
+		Relations: []RelationMapping{
+			{Type: "works_at", Source: ImportEndpoint{Type: "Person", Key: "name", Field: "Name"}, Target: ImportEndpoint{Type: "Organization", Key: "name", Field: "Company"}},
+		},
+	}
+
+	t.Run("stores the file and queues the import", func(t *testing.T) {
+		service, mocks := setupImportService(nil)
+		mocks.storage.On("Put", mock.Anything, mock.MatchedBy(func(key string) bool {
+			return strings.HasPrefix(key, "workspaces/"+testWorkspaceID+"/imports/")
+		}), mock.Anything, int64(42), "text/csv").Return(nil)
+		var job *ImportJob
+		mocks.jobs.On("Create", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) { job = args.Get(1).(*ImportJob) }).
+			Return(&ImportJob{}, nil)
+
+		_, err := service.CreateImport(context.Background(), testWorkspaceID, testAccountID, &ImportInput{
+			Name:        "people.tsv",
+			ContentType: "text/csv",
+			Size:        42,
+			Mapping:     people,
+		}, strings.NewReader("Name\tCompany\n"))
+		require.NoError(t, err)
+
+		assert.Equal(t, ImportFormatCSV, job.Format)
+		assert.Equal(t, ImportStatusQueued, job.Status)
+		assert.Equal(t, "\t", job.Mapping.Delimiter)
+		assert.Equal(t, "WORKS_AT", job.Mapping.Relations[0].Type)
+		assert.Equal(t, importPrefix(testWorkspaceID)+job.ID.Hex(), job.ObjectKey)
+		assert.Equal(t, testAccountID, job.CreatedBy)
+	})
+
+	t.Run("GraphML defaults", func(t *testing.T) {
+		service, mocks := setupImportService(nil)
+		mocks.storage.On("Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		var job *ImportJob
+		mocks.jobs.On("Create", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) { job = args.Get(1).(*ImportJob) }).
+			Return(&ImportJob{}, nil)
+
+		_, err := service.CreateImport(context.Background(), testWorkspaceID, testAccountID, &ImportInput{Name: "graph.graphml", Size: 10}, strings.NewReader("<graphml/>"))
+		require.NoError(t, err)
+		assert.Equal(t, &GraphMLMapping{NodeTypeAttribute: "labels", EdgeTypeAttribute: "label", Key: "name"}, job.Mapping.GraphML)
+	})
+
+	tests := []struct {
+		name  string
+		input ImportInput
+		err   string
+	}{
+		{"unknown format", ImportInput{Name: "people.xlsx", Mapping: people}, `invalid format: cannot tell the format of "people.xlsx", give csv, json or graphml`},
+		{"too large", ImportInput{Name: "people.csv", Size: 2 << 20, Mapping: people}, "file is larger than 1048576 bytes"},
+		{"no mapping", ImportInput{Name: "people.csv"}, "invalid mapping: map the records to at least one entity or relation"},
+		{"key not mapped", ImportInput{Name: "people.json", Mapping: &ImportMapping{
+			Entities: []EntityMapping{{Type: "Person", Key: "id", Properties: map[string]string{"name": "name"}}},
+		}}, `invalid mapping: entity 1: key property "id" is not mapped`},
+		{"reserved property", ImportInput{Name: "people.json", Mapping: &ImportMapping{
+			Entities: []EntityMapping{{Type: "Person", Key: "name", Properties: map[string]string{"name": "name", "workspace_id": "ws"}}},
+		}}, `invalid mapping: entity 1: invalid property name "workspace_id"`},
+		{"delimiter for JSON", ImportInput{Name: "people.json", Mapping: &ImportMapping{Delimiter: ";", Entities: people.Entities}}, "invalid mapping: delimiter only applies to CSV files"},
+		{"entities for GraphML", ImportInput{Name: "graph.graphml", Mapping: people}, "invalid mapping: GraphML files are mapped with graphml, not entities and relations"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service, mocks := setupImportService(nil)
+
+			_, err := service.CreateImport(context.Background(), testWorkspaceID, testAccountID, &test.input, strings.NewReader(""))
+			assert.EqualError(t, err, test.err)
+			mocks.storage.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestImportService_ProcessCSV(t *testing.T) {
+	service, mocks := setupImportService(CreateTestSchema())
+	job := createTestImportJob(ImportFormatCSV, &ImportMapping{
+		Entities: []EntityMapping{
+			{Type: "Person", Key: "name", Properties: map[string]string{"name": "name", "born": "born"}},
+			{Type: "Organization", Key: "name", Properties: map[string]string{"name": "company", "employees": "employees"}},
+		},
+		Relations: []RelationMapping{{
+			Type:       "WORKS_AT",
+			Source:     ImportEndpoint{Type: "Person", Key: "name", Field: "name"},
+			Target:     ImportEndpoint{Type: "Organization", Key: "name", Field: "company"},
+			Properties: map[string]string{"since": "since"},
+		}},
+	})
+	file := "name,born,company,employees,since\n" +
+		"Ada Lovelace,1815-12-10,Analytical Engines,12,1842\n" +
+		"Charles Babbage,not a date,Difference Engines,3,1822\n" +
+		",1900-01-01,Acme,5,2000\n" +
+		"Mary Somerville,,Acme,,1830\n"
+
+	var entityBatches []*ImportEntityBatch
+	mocks.repo.On("ImportEntities", mock.Anything, testWorkspaceID, testAccountID, mock.Anything).
+		Run(func(args mock.Arguments) {
+			batch := *args.Get(3).(*ImportEntityBatch)
+			batch.Rows = append([]ImportEntityRow(nil), batch.Rows...)
+			entityBatches = append(entityBatches, &batch)
+		}).
+		Return(int64(1), nil)
+	mocks.repo.On("MatchImportEndpoints", mock.Anything, testWorkspaceID, mock.Anything).Return([]*ImportEndpointMatch{
+		{Row: 1, Source: true, Target: true},
+		{Row: 4, Source: true, Target: false},
+	}, nil)
+	mocks.repo.On("ImportRelations", mock.Anything, testWorkspaceID, testAccountID, mock.MatchedBy(func(batch *ImportRelationBatch) bool {
+		return len(batch.Rows) == 1 && batch.Rows[0].Row == 1 &&
+			batch.Rows[0].Source == "Ada Lovelace" && batch.Rows[0].Target == "Analytical Engines" &&
+			batch.Rows[0].Properties["since"] == int64(1842)
+	})).Return(int64(1), nil)
+
+	update := processImport(t, service, mocks, job, file)
+
+	set := update["$set"].(bson.M)
+	assert.Equal(t, ImportStatusSucceeded, set["status"])
+	assert.Equal(t, ImportProgress{
+		BytesRead:        int64(len(file)),
+		Rows:             4,
+		Entities:         4,
+		EntitiesCreated:  2,
+		Relations:        1,
+		RelationsCreated: 1,
+		Errors:           4,
+	}, set["progress"])
+	assert.Equal(t, []ImportRowError{
+		{Row: 2, Target: "entity Person", Message: `property "born" must be a date in the YYYY-MM-DD or RFC 3339 format`},
+		{Row: 3, Target: "entity Person", Message: `key property "name" is missing`},
+		{Row: 3, Target: "relation WORKS_AT", Message: `source field "name" is missing`},
+		{Row: 4, Target: "relation WORKS_AT", Message: `target entity Organization with name "Acme" not found`},
+	}, set["errors"])
+
+	require.Len(t, entityBatches, 2)
+	assert.Equal(t, "Person", entityBatches[0].Type)
+	assert.Equal(t, []ImportEntityRow{
+		{Row: 1, Key: "Ada Lovelace", Properties: map[string]interface{}{"name": "Ada Lovelace", "born": "1815-12-10"}},
+		{Row: 4, Key: "Mary Somerville", Properties: map[string]interface{}{"name": "Mary Somerville"}},
+	}, entityBatches[0].Rows)
+	assert.Equal(t, "Organization", entityBatches[1].Type)
+	assert.Equal(t, int64(12), entityBatches[1].Rows[0].Properties["employees"], "numbers in the schema are converted")
+
+	mocks.storage.AssertCalled(t, "Delete", mock.Anything, job.ObjectKey)
+}
+
+func TestImportService_DryRun(t *testing.T) {
+	service, mocks := setupImportService(nil)
+	job := createTestImportJob(ImportFormatJSON, &ImportMapping{
+		Records: "people",
+		Entities: []EntityMapping{
+			{Type: "Person", Key: "name", Properties: map[string]string{"name": "name", "tags": "tags"}},
+		},
+		Relations: []RelationMapping{{
+			Type:   "KNOWS",
+			Source: ImportEndpoint{Type: "Person", Key: "name", Field: "name"},
+			Target: ImportEndpoint{Type: "Person", Key: "name", Field: "friend"},
+		}},
+	}, func(j *ImportJob) { j.DryRun = true })
+	file := `{"people": [
+		{"name": "Ada Lovelace", "friend": "Charles Babbage", "tags": ["math"]},
+		{"name": "Charles Babbage", "friend": "Nobody"},
+		{"name": "Mary Somerville", "friend": "Ada Lovelace", "tags": {"nested": true}}
+	]}`
+
+	mocks.repo.On("MatchImportEndpoints", mock.Anything, testWorkspaceID, mock.Anything).Return([]*ImportEndpointMatch{}, nil)
+
+	update := processImport(t, service, mocks, job, file)
+
+	set := update["$set"].(bson.M)
+	assert.Equal(t, ImportStatusSucceeded, set["status"])
+	progress := set["progress"].(ImportProgress)
+	assert.Equal(t, 2, progress.Entities)
+	assert.Equal(t, 1, progress.Relations)
+	assert.Equal(t, 0, progress.EntitiesCreated)
+	assert.Equal(t, []ImportRowError{
+		{Row: 3, Target: "entity Person", Message: `invalid property "tags": nested objects are not supported`},
+		{Row: 2, Target: "relation KNOWS", Message: `target entity Person with name "Nobody" not found`},
+	}, set["errors"])
+
+	mocks.repo.AssertNotCalled(t, "ImportEntities", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mocks.repo.AssertNotCalled(t, "ImportRelations", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestImportService_ProcessGraphML(t *testing.T) {
+	service, mocks := setupImportService(nil)
+	job := createTestImportJob(ImportFormatGraphML, &ImportMapping{GraphML: &GraphMLMapping{EdgeType: "related_to"}})
+	file := `<graphml>
+  <key id="labels" for="node" attr.name="labels"/>
+  <key id="name" for="node" attr.name="name"/>
+  <key id="label" for="edge" attr.name="label"/>
+  <graph edgedefault="directed">
+    <node id="n0"><data key="labels">:Person:Mathematician</data><data key="name">Ada Lovelace</data></node>
+    <node id="n1"><data key="labels">:Person</data></node>
+    <node id="n2"></node>
+    <edge source="n0" target="n1"><data key="label">KNOWS</data></edge>
+    <edge source="n1" target="n0"/>
+    <edge source="n0" target="n9"/>
+  </graph>
+</graphml>`
+
+	mocks.repo.On("ImportEntities", mock.Anything, testWorkspaceID, testAccountID, mock.MatchedBy(func(batch *ImportEntityBatch) bool {
+		return batch.Type == "Person" && batch.Key == "name" && len(batch.Rows) == 2 &&
+			batch.Rows[0].Key == "Ada Lovelace" && batch.Rows[1].Key == "n1"
+	})).Return(int64(2), nil)
+	mocks.repo.On("MatchImportEndpoints", mock.Anything, testWorkspaceID, mock.Anything).Return([]*ImportEndpointMatch{
+		{Row: 4, Source: true, Target: true},
+		{Row: 5, Source: true, Target: true},
+	}, nil)
+	mocks.repo.On("ImportRelations", mock.Anything, testWorkspaceID, testAccountID, mock.MatchedBy(func(batch *ImportRelationBatch) bool {
+		return batch.Type == "KNOWS" && batch.Rows[0].Source == "Ada Lovelace" && batch.Rows[0].Target == "n1"
+	})).Return(int64(1), nil)
+	mocks.repo.On("ImportRelations", mock.Anything, testWorkspaceID, testAccountID, mock.MatchedBy(func(batch *ImportRelationBatch) bool {
+		return batch.Type == "RELATED_TO" && batch.Rows[0].Source == "n1" && batch.Rows[0].Target == "Ada Lovelace"
+	})).Return(int64(1), nil)
+
+	update := processImport(t, service, mocks, job, file)
+
+	set := update["$set"].(bson.M)
+	assert.Equal(t, ImportStatusSucceeded, set["status"])
+	progress := set["progress"].(ImportProgress)
+	assert.Equal(t, 6, progress.Rows)
+	assert.Equal(t, 2, progress.Entities)
+	assert.Equal(t, 2, progress.Relations)
+	assert.Equal(t, []ImportRowError{
+		{Row: 3, Target: "node n2", Message: "has no type"},
+		{Row: 6, Target: "edge n0-n9", Message: `target node "n9" was not imported`},
+	}, set["errors"])
+}
+
+func TestImportService_ProcessFailures(t *testing.T) {
+	mapping := &ImportMapping{
+		Entities: []EntityMapping{{Type: "Person", Key: "name", Properties: map[string]string{"name": "name"}}},
+	}
+
+	t.Run("broken file fails for good", func(t *testing.T) {
+		service, mocks := setupImportService(nil)
+		job := createTestImportJob(ImportFormatCSV, mapping)
+
+		update := processImport(t, service, mocks, job, "name\n\"Ada Lovelace\n")
+
+		set := update["$set"].(bson.M)
+		assert.Equal(t, ImportStatusFailed, set["status"])
+		assert.Contains(t, set["error"], "invalid CSV file")
+		mocks.storage.AssertCalled(t, "Delete", mock.Anything, job.ObjectKey)
+	})
+
+	t.Run("graph errors are retried", func(t *testing.T) {
+		service, mocks := setupImportService(nil)
+		job := createTestImportJob(ImportFormatCSV, mapping)
+		mocks.repo.On("ImportEntities", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(int64(0), fmt.Errorf("neo4j unavailable"))
+
+		before := time.Now()
+		update := processImport(t, service, mocks, job, "name\nAda Lovelace\n")
+
+		set := update["$set"].(bson.M)
+		assert.Equal(t, ImportStatusQueued, set["status"])
+		assert.Equal(t, "neo4j unavailable", set["error"])
+		assert.True(t, set["next_attempt_at"].(time.Time).After(before.Add(ImportRetryDelay-time.Second)))
+		mocks.storage.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("last attempt fails for good", func(t *testing.T) {
+		service, mocks := setupImportService(nil)
+		job := createTestImportJob(ImportFormatCSV, mapping, func(j *ImportJob) { j.Attempts = ImportMaxAttempts })
+		mocks.repo.On("ImportEntities", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(int64(0), fmt.Errorf("neo4j unavailable"))
+
+		update := processImport(t, service, mocks, job, "name\nAda Lovelace\n")
+
+		assert.Equal(t, ImportStatusFailed, update["$set"].(bson.M)["status"])
+	})
+}
+
+func TestImportService_GetImportErrors(t *testing.T) {
+	service, mocks := setupImportService(nil)
+	job := createTestImportJob(ImportFormatGraphML, nil, func(j *ImportJob) {
+		j.Errors = []ImportRowError{{Row: 1, Message: "hyperedges are not supported"}}
+		j.Progress.Errors = 3
+	})
+	mocks.jobs.On("GetByID", mock.Anything, testWorkspaceID, job.ID).Return(job, nil)
+
+	result, err := service.GetImportErrors(context.Background(), testWorkspaceID, job.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Count)
+	assert.True(t, result.Truncated)
+	assert.Len(t, result.Errors, 1)
+
+	_, err = service.GetImportErrors(context.Background(), testWorkspaceID, "invalid")
+	assert.EqualError(t, err, "import not found")
+}
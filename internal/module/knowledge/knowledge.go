@@ -0,0 +1,277 @@
+package knowledge
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/container"
+	appmiddleware "github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/middleware"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/module/account"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/module/document"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/module/workspace"
+)
+
+type KnowledgeModule struct {
+	container.BaseModule
+	extractors    []HTTPExtractorConfig
+	maxImportSize int64
+}
+
+func NewKnowledgeModule() *KnowledgeModule {
+	return &KnowledgeModule{
+		BaseModule: container.NewBaseModule(
+			"knowledge",
+			"1.0.0",
+			"Knowledge graph entities, relations, traversal, schemas, pluggable extraction, evidence, entity resolution and imports on Neo4j",
+			[]string{"account", "workspace", "document"},
+		),
+	}
+}
+
+// WithExtractors adds external extraction services that workspaces can
+// choose instead of the built-in rules.
+func (m *KnowledgeModule) WithExtractors(extractors ...HTTPExtractorConfig) *KnowledgeModule {
+	m.extractors = append(m.extractors, extractors...)
+	return m
+}
+
+// WithMaxImportSize sets the largest file imported, in bytes. Uploads are
+// also bounded by the upload size of the container.
+func (m *KnowledgeModule) WithMaxImportSize(maxSize int64) *KnowledgeModule {
+	m.maxImportSize = maxSize
+	return m
+}
+
+func (m *KnowledgeModule) RegisterServices(registry *container.ServiceRegistry) error {
+	neo4jService := registry.GetNeo4j()
+	if neo4jService == nil {
+		return container.ServiceNotFoundError{ServiceName: "neo4j"}
+	}
+
+	mongoService := registry.GetMongo()
+	if mongoService == nil {
+		return container.ServiceNotFoundError{ServiceName: "mongo"}
+	}
+
+	minioService := registry.GetMinIO()
+	if minioService == nil {
+		return container.ServiceNotFoundError{ServiceName: "minio"}
+	}
+
+	repository := NewKnowledgeRepository(neo4jService)
+	schemaRepository := NewSchemaRepository(mongoService)
+	evidenceRepository := NewEvidenceRepository(mongoService)
+
+	knowledgeService := newKnowledgeService(repository, schemaRepository, evidenceRepository)
+	if err := registry.RegisterService("knowledge", knowledgeService); err != nil {
+		return err
+	}
+
+	evidenceService := newEvidenceService(evidenceRepository, knowledgeService)
+	if err := registry.RegisterService("knowledge_evidence", evidenceService); err != nil {
+		return err
+	}
+
+	resolutionService := newResolutionService(
+		NewResolutionSettingsRepository(mongoService),
+		NewMergeRepository(mongoService),
+		knowledgeService,
+	)
+	if err := registry.RegisterService("knowledge_resolution", resolutionService); err != nil {
+		return err
+	}
+
+	importService := newImportService(
+		NewImportJobRepository(mongoService),
+		NewImportStorage(minioService),
+		knowledgeService,
+		m.maxImportSize,
+	)
+	if err := registry.RegisterService("knowledge_import", importService); err != nil {
+		return err
+	}
+
+	schemaService := newSchemaService(schemaRepository, repository)
+	if err := registry.RegisterService("knowledge_schema", schemaService); err != nil {
+		return err
+	}
+
+	documentExtractionInterface, err := registry.GetService("document_extraction")
+	if err != nil {
+		return err
+	}
+
+	ruleSetRepository := NewRuleSetRepository(mongoService)
+
+	extractors := NewExtractorRegistry()
+	if err := extractors.Register(newRuleExtractor(ruleSetRepository)); err != nil {
+		return err
+	}
+	for _, config := range m.extractors {
+		extractor, err := NewHTTPExtractor(config)
+		if err != nil {
+			return err
+		}
+		if err := extractors.Register(extractor); err != nil {
+			return err
+		}
+	}
+
+	extractionService := newExtractionService(
+		ruleSetRepository,
+		NewCandidateRepository(mongoService),
+		NewExtractionSettingsRepository(mongoService),
+		extractors,
+		knowledgeService,
+		documentExtractionInterface.(document.ExtractionService),
+	)
+	if err := registry.RegisterService("knowledge_extraction", extractionService); err != nil {
+		return err
+	}
+
+	documentServiceInterface, err := registry.GetService("document")
+	if err != nil {
+		return err
+	}
+	documentService := documentServiceInterface.(document.DocumentService)
+	documentService.OnDelete(func(ctx context.Context, workspaceID, documentID string) error {
+		_, err := evidenceService.RetractDocument(ctx, workspaceID, documentID)
+		return err
+	})
+	documentService.OnDelete(extractionService.DeleteDocument)
+
+	workspaceServiceInterface, err := registry.GetService("workspace")
+	if err != nil {
+		return err
+	}
+	workspaceService := workspaceServiceInterface.(workspace.WorkspaceService)
+	workspaceService.OnDelete(repository.DeleteWorkspace)
+	workspaceService.OnDelete(schemaRepository.DeleteByWorkspace)
+	workspaceService.OnDelete(extractionService.DeleteWorkspace)
+	workspaceService.OnDelete(evidenceService.DeleteWorkspace)
+	workspaceService.OnDelete(resolutionService.DeleteWorkspace)
+	workspaceService.OnDelete(importService.DeleteWorkspace)
+
+	importService.Start()
+
+	return nil
+}
+
+func (m *KnowledgeModule) RegisterMiddleware(registry *container.ServiceRegistry) error {
+	return nil
+}
+
+func (m *KnowledgeModule) RegisterRoutes(router fiber.Router, registry *container.ServiceRegistry) error {
+	knowledgeServiceInterface, err := registry.GetService("knowledge")
+	if err != nil {
+		return err
+	}
+
+	schemaServiceInterface, err := registry.GetService("knowledge_schema")
+	if err != nil {
+		return err
+	}
+
+	extractionServiceInterface, err := registry.GetService("knowledge_extraction")
+	if err != nil {
+		return err
+	}
+
+	evidenceServiceInterface, err := registry.GetService("knowledge_evidence")
+	if err != nil {
+		return err
+	}
+
+	resolutionServiceInterface, err := registry.GetService("knowledge_resolution")
+	if err != nil {
+		return err
+	}
+
+	importServiceInterface, err := registry.GetService("knowledge_import")
+	if err != nil {
+		return err
+	}
+
+	handler := NewKnowledgeHandler(knowledgeServiceInterface.(KnowledgeService))
+	schemaHandler := NewSchemaHandler(schemaServiceInterface.(SchemaService))
+	extractionHandler := NewExtractionHandler(extractionServiceInterface.(ExtractionService))
+	evidenceHandler := NewEvidenceHandler(evidenceServiceInterface.(EvidenceService))
+	resolutionHandler := NewResolutionHandler(resolutionServiceInterface.(ResolutionService))
+	importHandler := NewImportHandler(importServiceInterface.(ImportService))
+
+	middlewareInterface, err := registry.GetService("account_middleware")
+	if err != nil {
+		return err
+	}
+
+	workspaceMiddlewareInterface, err := registry.GetService("workspace_middleware")
+	if err != nil {
+		return err
+	}
+
+	middleware := middlewareInterface.(*account.AccountMiddleware)
+	members := workspaceMiddlewareInterface.(*workspace.WorkspaceMiddleware)
+	read := middleware.RequireScope(account.OAuthScopeKnowledgeRead)
+	write := middleware.RequireScope(account.OAuthScopeKnowledgeWrite)
+	viewer := members.RequireRole(workspace.RoleViewer)
+	editor := members.RequireRole(workspace.RoleEditor)
+	admin := members.RequireRole(workspace.RoleAdmin)
+
+	knowledge := router.Group("/workspaces/:workspaceId/knowledge", middleware.RequireAuth())
+
+	knowledge.Get("/entities", read, viewer, handler.ListEntities)
+	knowledge.Post("/entities", write, editor, handler.CreateEntity)
+	knowledge.Get("/entities/:id", read, viewer, handler.GetEntity)
+	knowledge.Patch("/entities/:id", write, editor, handler.UpdateEntity)
+	knowledge.Delete("/entities/:id", write, editor, handler.DeleteEntity)
+	knowledge.Get("/entities/:id/neighbourhood", read, viewer, handler.GetNeighbourhood)
+	knowledge.Get("/entities/:id/evidence", read, viewer, evidenceHandler.GetEntityEvidence)
+
+	knowledge.Post("/relations", write, editor, handler.CreateRelation)
+	knowledge.Get("/relations/:id", read, viewer, handler.GetRelation)
+	knowledge.Patch("/relations/:id", write, editor, handler.UpdateRelation)
+	knowledge.Delete("/relations/:id", write, editor, handler.DeleteRelation)
+	knowledge.Get("/relations/:id/evidence", read, viewer, evidenceHandler.GetRelationEvidence)
+
+	knowledge.Get("/paths", read, viewer, handler.FindPaths)
+
+	knowledge.Get("/schema", read, viewer, schemaHandler.GetSchema)
+	knowledge.Put("/schema", write, admin, schemaHandler.UpdateSchema)
+	knowledge.Post("/schema/preview", write, admin, schemaHandler.PreviewSchema)
+	knowledge.Get("/schema/report", read, viewer, schemaHandler.GetMigrationReport)
+	knowledge.Get("/schema/versions", read, viewer, schemaHandler.ListSchemaVersions)
+	knowledge.Get("/schema/versions/:version", read, viewer, schemaHandler.GetSchemaVersion)
+
+	knowledge.Get("/extraction/rules", read, viewer, extractionHandler.GetRules)
+	knowledge.Put("/extraction/rules", write, admin, extractionHandler.UpdateRules)
+	knowledge.Get("/extraction/extractors", read, viewer, extractionHandler.ListExtractors)
+	knowledge.Get("/extraction/settings", read, viewer, extractionHandler.GetSettings)
+	knowledge.Put("/extraction/settings", write, admin, extractionHandler.UpdateSettings)
+	knowledge.Post("/extraction/runs", write, editor, extractionHandler.ExtractDocument)
+
+	knowledge.Get("/candidates", read, viewer, extractionHandler.ListCandidates)
+	knowledge.Get("/candidates/:id", read, viewer, extractionHandler.GetCandidate)
+	knowledge.Post("/candidates/:id/accept", write, editor, extractionHandler.AcceptCandidate)
+	knowledge.Post("/candidates/:id/reject", write, editor, extractionHandler.RejectCandidate)
+
+	knowledge.Get("/resolution/settings", read, viewer, resolutionHandler.GetSettings)
+	knowledge.Put("/resolution/settings", write, admin, resolutionHandler.UpdateSettings)
+	knowledge.Get("/resolution/candidates", read, viewer, resolutionHandler.FindCandidates)
+	knowledge.Post("/resolution/merges", write, editor, resolutionHandler.MergeEntities)
+	knowledge.Get("/resolution/merges", read, viewer, resolutionHandler.ListMerges)
+	knowledge.Get("/resolution/merges/:id", read, viewer, resolutionHandler.GetMerge)
+	knowledge.Post("/resolution/merges/:id/undo", write, editor, resolutionHandler.UndoMerge)
+
+	maxImportSize := m.maxImportSize
+	if maxImportSize <= 0 {
+		maxImportSize = DefaultMaxImportSize
+	}
+
+	knowledge.Post("/imports", write, editor, appmiddleware.NewUploadLimitMiddleware(maxImportSize), importHandler.CreateImport)
+	knowledge.Get("/imports", read, viewer, importHandler.ListImports)
+	knowledge.Get("/imports/:id", read, viewer, importHandler.GetImport)
+	knowledge.Get("/imports/:id/errors", read, viewer, importHandler.GetImportErrors)
+
+	return nil
+}
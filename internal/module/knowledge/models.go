@@ -0,0 +1,166 @@
+package knowledge
+
+import (
+	"time"
+)
+
+// EntityLabel is carried by every entity node next to the label of its type,
+// so that queries can tell entities apart from other nodes in the graph.
+const EntityLabel = "Entity"
+
+const (
+	EntityListDefaultLimit = 20
+	EntityListMaxLimit     = 100
+
+	NeighbourhoodDefaultDepth = 1
+	NeighbourhoodMaxDepth     = 3
+	NeighbourhoodDefaultLimit = 100
+	NeighbourhoodMaxLimit     = 500
+
+	PathDefaultMaxDepth = 4
+	PathMaxDepth        = 6
+	PathMaxResults      = 25
+)
+
+const (
+	DirectionOutgoing = "out"
+	DirectionIncoming = "in"
+	DirectionBoth     = "both"
+)
+
+// Properties the module maintains itself. Clients cannot set them.
+const (
+	propertyWorkspaceID = "workspace_id"
+	propertyCreatedBy   = "created_by"
+	propertyCreatedAt   = "created_at"
+	propertyUpdatedAt   = "updated_at"
+)
+
+// Provenance of extracted entities and relations, stored next to the system
+// properties and returned as Provenance rather than with the properties.
+const (
+	propertySourceDocumentID      = "source_document_id"
+	propertySourceDocumentVersion = "source_document_version"
+	propertySourceStart           = "source_start"
+	propertySourceEnd             = "source_end"
+	propertyExtractor             = "extractor"
+	propertyExtractorVersion      = "extractor_version"
+)
+
+// propertyAliases lists the names of the entities merged into an entity. It
+// is returned as Aliases rather than with the properties.
+const propertyAliases = "merged_aliases"
+
+var reservedProperties = map[string]bool{
+	propertyWorkspaceID:           true,
+	propertyCreatedBy:             true,
+	propertyCreatedAt:             true,
+	propertyUpdatedAt:             true,
+	propertySourceDocumentID:      true,
+	propertySourceDocumentVersion: true,
+	propertySourceStart:           true,
+	propertySourceEnd:             true,
+	propertyExtractor:             true,
+	propertyExtractorVersion:      true,
+	propertyAliases:               true,
+}
+
+// Entity is a typed node of the knowledge graph. Type is its label, such as
+// Person or Organization. Provenance is set on entities that were extracted
+// from a document, and Aliases on entities that others were merged into.
+type Entity struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	Properties map[string]interface{} `json:"properties"`
+	Aliases    []string               `json:"aliases,omitempty"`
+	Provenance *Provenance            `json:"provenance,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+}
+
+// Relation is a typed, directed edge from SourceID to TargetID.
+type Relation struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	SourceID   string                 `json:"source_id"`
+	TargetID   string                 `json:"target_id"`
+	Properties map[string]interface{} `json:"properties"`
+	Provenance *Provenance            `json:"provenance,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+}
+
+type CreateEntityRequest struct {
+	Type       string                 `json:"type" validate:"required,max=64"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// UpdateEntityRequest merges Properties into the entity. A property set to
+// null is removed. The type of an entity cannot be changed.
+type UpdateEntityRequest struct {
+	Properties map[string]interface{} `json:"properties" validate:"required"`
+}
+
+type CreateRelationRequest struct {
+	SourceID   string                 `json:"source_id" validate:"required"`
+	TargetID   string                 `json:"target_id" validate:"required"`
+	Type       string                 `json:"type" validate:"required,max=64"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// UpdateRelationRequest merges Properties into the relation. A property set
+// to null is removed.
+type UpdateRelationRequest struct {
+	Properties map[string]interface{} `json:"properties" validate:"required"`
+}
+
+// EntityListQuery filters entities by type and by a case-insensitive match
+// on their name property.
+type EntityListQuery struct {
+	Type  string `query:"type"`
+	Query string `query:"q"`
+	Page  int64  `query:"page"`
+	Limit int64  `query:"limit"`
+}
+
+type EntityPage struct {
+	Data       []*Entity `json:"data"`
+	Total      int64     `json:"total"`
+	Page       int64     `json:"page"`
+	Limit      int64     `json:"limit"`
+	TotalPages int64     `json:"total_pages"`
+	HasNext    bool      `json:"has_next"`
+	HasPrev    bool      `json:"has_prev"`
+}
+
+// NeighbourhoodQuery expands an entity up to Depth hops. RelationTypes is a
+// comma-separated list; when set, only relations of those types are followed.
+type NeighbourhoodQuery struct {
+	Depth         int    `query:"depth" validate:"omitempty,min=1,max=3"`
+	Direction     string `query:"direction" validate:"omitempty,oneof=out in both"`
+	RelationTypes string `query:"relation_types"`
+	Limit         int    `query:"limit" validate:"omitempty,min=1,max=500"`
+}
+
+// PathQuery looks for the shortest paths between two entities. With All set,
+// every shortest path is returned rather than one.
+type PathQuery struct {
+	From     string `query:"from" validate:"required"`
+	To       string `query:"to" validate:"required"`
+	MaxDepth int    `query:"max_depth" validate:"omitempty,min=1,max=6"`
+	All      bool   `query:"all"`
+}
+
+// Subgraph is a set of entities and the relations between them.
+type Subgraph struct {
+	Entities  []*Entity   `json:"entities"`
+	Relations []*Relation `json:"relations"`
+}
+
+// Path lists the entities from the start to the end of a path and the
+// relations joining them, in order.
+type Path struct {
+	Length    int         `json:"length"`
+	Entities  []*Entity   `json:"entities"`
+	Relations []*Relation `json:"relations"`
+}
@@ -0,0 +1,1016 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	neo4jDriver "github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/neo4j"
+)
+
+// KnowledgeRepository stores entities and relations in Neo4j. Every method is
+// scoped to one workspace: nodes and relations of other workspaces are never
+// read or changed. Entity and relation types must have been validated, as
+// they are written into the Cypher statements.
+type KnowledgeRepository interface {
+	CreateEntity(ctx context.Context, workspaceID, createdBy, entityType string, properties map[string]interface{}) (*Entity, error)
+	GetEntity(ctx context.Context, workspaceID, id string) (*Entity, error)
+	ListEntities(ctx context.Context, workspaceID string, query *EntityListQuery) ([]*Entity, int64, error)
+	UpdateEntity(ctx context.Context, workspaceID, id string, properties map[string]interface{}) (*Entity, error)
+	DeleteEntity(ctx context.Context, workspaceID, id string) (bool, error)
+
+	CreateRelation(ctx context.Context, workspaceID, createdBy, sourceID, targetID, relationType string, properties map[string]interface{}) (*Relation, error)
+	GetRelation(ctx context.Context, workspaceID, id string) (*Relation, error)
+	UpdateRelation(ctx context.Context, workspaceID, id string, properties map[string]interface{}) (*Relation, error)
+	DeleteRelation(ctx context.Context, workspaceID, id string) (bool, error)
+
+	Neighbourhood(ctx context.Context, workspaceID, id string, depth int, direction string, relationTypes []string, limit int) (*Subgraph, error)
+	ShortestPaths(ctx context.Context, workspaceID, fromID, toID string, maxDepth int, all bool) ([]*Path, error)
+
+	FindEntityByName(ctx context.Context, workspaceID, entityType, name string) (*Entity, error)
+	FindRelation(ctx context.Context, workspaceID, sourceID, targetID, relationType string) (*Relation, error)
+	EntityTypes(ctx context.Context, workspaceID string, ids []string) (map[string]string, error)
+	CountRelations(ctx context.Context, workspaceID, entityID, relationType string, outgoing bool) (int64, error)
+	Degree(ctx context.Context, workspaceID, entityID string) (int64, error)
+	SnapshotEntity(ctx context.Context, workspaceID, id string) (*StoredEntity, []*StoredRelation, error)
+	RestoreEntity(ctx context.Context, workspaceID string, entity *StoredEntity) (string, error)
+	RestoreRelation(ctx context.Context, workspaceID string, relation *StoredRelation) (string, error)
+	ReplaceEntityProperties(ctx context.Context, workspaceID, id string, properties map[string]interface{}) error
+	SetProvenance(ctx context.Context, workspaceID string, kind FactKind, id string, provenance *Provenance) error
+	ScanEntities(ctx context.Context, workspaceID, after string, limit int) ([]*Entity, error)
+	ScanRelations(ctx context.Context, workspaceID, after string, limit int) ([]*ScannedRelation, error)
+	RelationFanOut(ctx context.Context, workspaceID, relationType string, outgoing bool, limit int) ([]*RelationCount, error)
+
+	ImportEntities(ctx context.Context, workspaceID, createdBy string, batch *ImportEntityBatch) (int64, error)
+	ImportRelations(ctx context.Context, workspaceID, createdBy string, batch *ImportRelationBatch) (int64, error)
+	MatchImportEndpoints(ctx context.Context, workspaceID string, batch *ImportRelationBatch) ([]*ImportEndpointMatch, error)
+
+	DeleteWorkspace(ctx context.Context, workspaceID string) error
+}
+
+type knowledgeRepository struct {
+	neo4j neo4j.Neo4jService
+}
+
+func NewKnowledgeRepository(neo4jService neo4j.Neo4jService) KnowledgeRepository {
+	return &knowledgeRepository{neo4j: neo4jService}
+}
+
+func (r *knowledgeRepository) CreateEntity(ctx context.Context, workspaceID, createdBy, entityType string, properties map[string]interface{}) (*Entity, error) {
+	now := time.Now()
+	props := withSystemProperties(properties, workspaceID, createdBy, now)
+
+	cypher := fmt.Sprintf("CREATE (n:%s:%s) SET n = $props RETURN n", EntityLabel, quoteIdentifier(entityType))
+
+	records, err := r.neo4j.ExecuteWrite(ctx, cypher, map[string]interface{}{"props": props})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create entity: %w", err)
+	}
+
+	return singleEntity(records, "n")
+}
+
+func (r *knowledgeRepository) GetEntity(ctx context.Context, workspaceID, id string) (*Entity, error) {
+	records, err := r.neo4j.ExecuteRead(ctx,
+		"MATCH (n:Entity) WHERE elementId(n) = $id AND n.workspace_id = $workspaceId RETURN n",
+		map[string]interface{}{"id": id, "workspaceId": workspaceID},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity: %w", err)
+	}
+
+	return singleEntity(records, "n")
+}
+
+func (r *knowledgeRepository) ListEntities(ctx context.Context, workspaceID string, query *EntityListQuery) ([]*Entity, int64, error) {
+	cypher := `MATCH (n:Entity)
+		WHERE n.workspace_id = $workspaceId
+			AND ($type = '' OR $type IN labels(n))
+			AND ($query = '' OR toLower(toString(coalesce(n.name, ''))) CONTAINS toLower($query))
+		WITH n ORDER BY n.created_at DESC, elementId(n)
+		WITH collect(n) AS nodes
+		RETURN size(nodes) AS total, nodes[$skip..$skip + $limit] AS page`
+
+	records, err := r.neo4j.ExecuteRead(ctx, cypher, map[string]interface{}{
+		"workspaceId": workspaceID,
+		"type":        query.Type,
+		"query":       query.Query,
+		"skip":        (query.Page - 1) * query.Limit,
+		"limit":       query.Limit,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list entities: %w", err)
+	}
+
+	if len(records) == 0 {
+		return []*Entity{}, 0, nil
+	}
+
+	total, _ := records[0].Get("total")
+	page, _ := records[0].Get("page")
+
+	nodes, _ := page.([]interface{})
+	entities := make([]*Entity, 0, len(nodes))
+	for _, value := range nodes {
+		if node, ok := value.(neo4jDriver.Node); ok {
+			entities = append(entities, nodeToEntity(node))
+		}
+	}
+
+	count, _ := total.(int64)
+	return entities, count, nil
+}
+
+func (r *knowledgeRepository) UpdateEntity(ctx context.Context, workspaceID, id string, properties map[string]interface{}) (*Entity, error) {
+	records, err := r.neo4j.ExecuteWrite(ctx,
+		`MATCH (n:Entity) WHERE elementId(n) = $id AND n.workspace_id = $workspaceId
+		SET n += $props, n.updated_at = $now
+		RETURN n`,
+		map[string]interface{}{
+			"id":          id,
+			"workspaceId": workspaceID,
+			"props":       properties,
+			"now":         time.Now(),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update entity: %w", err)
+	}
+
+	return singleEntity(records, "n")
+}
+
+func (r *knowledgeRepository) DeleteEntity(ctx context.Context, workspaceID, id string) (bool, error) {
+	records, err := r.neo4j.ExecuteWrite(ctx,
+		`MATCH (n:Entity) WHERE elementId(n) = $id AND n.workspace_id = $workspaceId
+		DETACH DELETE n
+		RETURN count(*) AS deleted`,
+		map[string]interface{}{"id": id, "workspaceId": workspaceID},
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete entity: %w", err)
+	}
+
+	return countOf(records, "deleted") > 0, nil
+}
+
+func (r *knowledgeRepository) CreateRelation(ctx context.Context, workspaceID, createdBy, sourceID, targetID, relationType string, properties map[string]interface{}) (*Relation, error) {
+	now := time.Now()
+	props := withSystemProperties(properties, workspaceID, createdBy, now)
+
+	cypher := fmt.Sprintf(`MATCH (a:Entity), (b:Entity)
+		WHERE elementId(a) = $sourceId AND elementId(b) = $targetId
+			AND a.workspace_id = $workspaceId AND b.workspace_id = $workspaceId
+		CREATE (a)-[r:%s]->(b) SET r = $props
+		RETURN r`, quoteIdentifier(relationType))
+
+	records, err := r.neo4j.ExecuteWrite(ctx, cypher, map[string]interface{}{
+		"sourceId":    sourceID,
+		"targetId":    targetID,
+		"workspaceId": workspaceID,
+		"props":       props,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create relation: %w", err)
+	}
+
+	return singleRelation(records, "r")
+}
+
+func (r *knowledgeRepository) GetRelation(ctx context.Context, workspaceID, id string) (*Relation, error) {
+	records, err := r.neo4j.ExecuteRead(ctx,
+		"MATCH (:Entity)-[r]->(:Entity) WHERE elementId(r) = $id AND r.workspace_id = $workspaceId RETURN r",
+		map[string]interface{}{"id": id, "workspaceId": workspaceID},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relation: %w", err)
+	}
+
+	return singleRelation(records, "r")
+}
+
+func (r *knowledgeRepository) UpdateRelation(ctx context.Context, workspaceID, id string, properties map[string]interface{}) (*Relation, error) {
+	records, err := r.neo4j.ExecuteWrite(ctx,
+		`MATCH (:Entity)-[r]->(:Entity) WHERE elementId(r) = $id AND r.workspace_id = $workspaceId
+		SET r += $props, r.updated_at = $now
+		RETURN r`,
+		map[string]interface{}{
+			"id":          id,
+			"workspaceId": workspaceID,
+			"props":       properties,
+			"now":         time.Now(),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update relation: %w", err)
+	}
+
+	return singleRelation(records, "r")
+}
+
+func (r *knowledgeRepository) DeleteRelation(ctx context.Context, workspaceID, id string) (bool, error) {
+	records, err := r.neo4j.ExecuteWrite(ctx,
+		`MATCH (:Entity)-[r]->(:Entity) WHERE elementId(r) = $id AND r.workspace_id = $workspaceId
+		DELETE r
+		RETURN count(*) AS deleted`,
+		map[string]interface{}{"id": id, "workspaceId": workspaceID},
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete relation: %w", err)
+	}
+
+	return countOf(records, "deleted") > 0, nil
+}
+
+func (r *knowledgeRepository) Neighbourhood(ctx context.Context, workspaceID, id string, depth int, direction string, relationTypes []string, limit int) (*Subgraph, error) {
+	pattern := fmt.Sprintf("-[rels*1..%d]-", depth)
+	switch direction {
+	case DirectionOutgoing:
+		pattern = fmt.Sprintf("-[rels*1..%d]->", depth)
+	case DirectionIncoming:
+		pattern = fmt.Sprintf("<-[rels*1..%d]-", depth)
+	}
+
+	cypher := fmt.Sprintf(`MATCH (start:Entity) WHERE elementId(start) = $id AND start.workspace_id = $workspaceId
+		OPTIONAL MATCH p = (start)%s(:Entity)
+		WHERE all(r IN rels WHERE r.workspace_id = $workspaceId AND (size($types) = 0 OR type(r) IN $types))
+			AND all(x IN nodes(p) WHERE x.workspace_id = $workspaceId)
+		WITH start, p LIMIT $limit
+		RETURN start, p`, pattern)
+
+	records, err := r.neo4j.ExecuteRead(ctx, cypher, map[string]interface{}{
+		"id":          id,
+		"workspaceId": workspaceID,
+		"types":       relationTypes,
+		"limit":       limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand entity: %w", err)
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	graph := newSubgraphBuilder()
+	for _, record := range records {
+		if start, ok := recordValue(record, "start").(neo4jDriver.Node); ok {
+			graph.addNode(start)
+		}
+		if path, ok := recordValue(record, "p").(neo4jDriver.Path); ok {
+			graph.addPath(path)
+		}
+	}
+
+	return graph.subgraph(), nil
+}
+
+func (r *knowledgeRepository) ShortestPaths(ctx context.Context, workspaceID, fromID, toID string, maxDepth int, all bool) ([]*Path, error) {
+	function := "shortestPath"
+	if all {
+		function = "allShortestPaths"
+	}
+
+	cypher := fmt.Sprintf(`MATCH (a:Entity), (b:Entity)
+		WHERE elementId(a) = $fromId AND elementId(b) = $toId
+			AND a.workspace_id = $workspaceId AND b.workspace_id = $workspaceId
+		MATCH p = %s((a)-[*1..%d]-(b))
+		WHERE all(r IN relationships(p) WHERE r.workspace_id = $workspaceId)
+			AND all(x IN nodes(p) WHERE x:Entity AND x.workspace_id = $workspaceId)
+		RETURN p LIMIT $limit`, function, maxDepth)
+
+	records, err := r.neo4j.ExecuteRead(ctx, cypher, map[string]interface{}{
+		"fromId":      fromID,
+		"toId":        toID,
+		"workspaceId": workspaceID,
+		"limit":       PathMaxResults,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find path: %w", err)
+	}
+
+	paths := make([]*Path, 0, len(records))
+	for _, record := range records {
+		if path, ok := recordValue(record, "p").(neo4jDriver.Path); ok {
+			paths = append(paths, toPath(path))
+		}
+	}
+
+	return paths, nil
+}
+
+// FindEntityByName returns the oldest entity of entityType whose name matches
+// name regardless of case, or nil when there is none. An entity that has the
+// name as an alias of a merged entity matches too, after those named so.
+func (r *knowledgeRepository) FindEntityByName(ctx context.Context, workspaceID, entityType, name string) (*Entity, error) {
+	cypher := fmt.Sprintf(`MATCH (n:%s:%s)
+		WHERE n.workspace_id = $workspaceId
+			AND (toLower(toString(n.name)) = toLower($name)
+				OR any(alias IN coalesce(n.%s, []) WHERE toLower(alias) = toLower($name)))
+		RETURN n ORDER BY toLower(toString(n.name)) = toLower($name) DESC, n.created_at, elementId(n) LIMIT 1`,
+		EntityLabel, quoteIdentifier(entityType), propertyAliases)
+
+	records, err := r.neo4j.ExecuteRead(ctx, cypher, map[string]interface{}{
+		"workspaceId": workspaceID,
+		"name":        name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find entity: %w", err)
+	}
+
+	return singleEntity(records, "n")
+}
+
+// FindRelation returns a relation of relationType from the source to the
+// target entity, or nil when there is none.
+func (r *knowledgeRepository) FindRelation(ctx context.Context, workspaceID, sourceID, targetID, relationType string) (*Relation, error) {
+	cypher := fmt.Sprintf(`MATCH (a:Entity)-[r:%s]->(b:Entity)
+		WHERE elementId(a) = $sourceId AND elementId(b) = $targetId AND r.workspace_id = $workspaceId
+		RETURN r ORDER BY r.created_at, elementId(r) LIMIT 1`, quoteIdentifier(relationType))
+
+	records, err := r.neo4j.ExecuteRead(ctx, cypher, map[string]interface{}{
+		"sourceId":    sourceID,
+		"targetId":    targetID,
+		"workspaceId": workspaceID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find relation: %w", err)
+	}
+
+	return singleRelation(records, "r")
+}
+
+// EntityTypes maps the IDs of the given entities to their types. IDs of
+// entities that do not exist in the workspace are left out.
+func (r *knowledgeRepository) EntityTypes(ctx context.Context, workspaceID string, ids []string) (map[string]string, error) {
+	types := make(map[string]string, len(ids))
+	if len(ids) == 0 {
+		return types, nil
+	}
+
+	records, err := r.neo4j.ExecuteRead(ctx,
+		"MATCH (n:Entity) WHERE elementId(n) IN $ids AND n.workspace_id = $workspaceId RETURN n",
+		map[string]interface{}{"ids": ids, "workspaceId": workspaceID},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up entities: %w", err)
+	}
+
+	for _, record := range records {
+		if node, ok := recordValue(record, "n").(neo4jDriver.Node); ok {
+			types[node.ElementId] = nodeToEntity(node).Type
+		}
+	}
+
+	return types, nil
+}
+
+// CountRelations counts the relations of relationType leaving the entity, or
+// entering it when outgoing is false.
+func (r *knowledgeRepository) CountRelations(ctx context.Context, workspaceID, entityID, relationType string, outgoing bool) (int64, error) {
+	pattern := "(n:Entity)-[r:%s]->(:Entity)"
+	if !outgoing {
+		pattern = "(n:Entity)<-[r:%s]-(:Entity)"
+	}
+
+	cypher := fmt.Sprintf(`MATCH `+pattern+`
+		WHERE elementId(n) = $id AND r.workspace_id = $workspaceId
+		RETURN count(r) AS total`, quoteIdentifier(relationType))
+
+	records, err := r.neo4j.ExecuteRead(ctx, cypher, map[string]interface{}{
+		"id":          entityID,
+		"workspaceId": workspaceID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count relations: %w", err)
+	}
+
+	return countOf(records, "total"), nil
+}
+
+// Degree counts the relations of an entity in either direction.
+func (r *knowledgeRepository) Degree(ctx context.Context, workspaceID, entityID string) (int64, error) {
+	records, err := r.neo4j.ExecuteRead(ctx,
+		`MATCH (n:Entity)-[r]-(:Entity)
+		WHERE elementId(n) = $id AND r.workspace_id = $workspaceId
+		RETURN count(r) AS total`,
+		map[string]interface{}{"id": entityID, "workspaceId": workspaceID},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count relations: %w", err)
+	}
+
+	return countOf(records, "total"), nil
+}
+
+// SetProvenance replaces the provenance of an entity or relation, or removes
+// it when provenance is nil. It does nothing when the fact does not exist.
+func (r *knowledgeRepository) SetProvenance(ctx context.Context, workspaceID string, kind FactKind, id string, provenance *Provenance) error {
+	props := (&Provenance{}).properties()
+	for key := range props {
+		props[key] = nil
+	}
+	if provenance != nil {
+		props = provenance.properties()
+	}
+
+	cypher := `MATCH (f:Entity) WHERE elementId(f) = $id AND f.workspace_id = $workspaceId SET f += $props`
+	if kind == FactKindRelation {
+		cypher = `MATCH (:Entity)-[f]->(:Entity) WHERE elementId(f) = $id AND f.workspace_id = $workspaceId SET f += $props`
+	}
+
+	_, err := r.neo4j.ExecuteWrite(ctx, cypher, map[string]interface{}{
+		"id":          id,
+		"workspaceId": workspaceID,
+		"props":       props,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update provenance: %w", err)
+	}
+
+	return nil
+}
+
+// SnapshotEntity returns an entity with every stored property, system ones
+// included, and the relations attached to it in either direction. It
+// returns nil when the entity does not exist.
+func (r *knowledgeRepository) SnapshotEntity(ctx context.Context, workspaceID, id string) (*StoredEntity, []*StoredRelation, error) {
+	records, err := r.neo4j.ExecuteRead(ctx,
+		`MATCH (n:Entity) WHERE elementId(n) = $id AND n.workspace_id = $workspaceId
+		OPTIONAL MATCH (n)-[r]-(:Entity) WHERE r.workspace_id = $workspaceId
+		RETURN n, collect(DISTINCT r) AS relations`,
+		map[string]interface{}{"id": id, "workspaceId": workspaceID},
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read entity: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil, nil
+	}
+
+	node, ok := recordValue(records[0], "n").(neo4jDriver.Node)
+	if !ok {
+		return nil, nil, fmt.Errorf("unexpected result: n is not a node")
+	}
+	entity := &StoredEntity{
+		ID:         node.ElementId,
+		Type:       nodeToEntity(node).Type,
+		Properties: node.Props,
+	}
+
+	values, _ := recordValue(records[0], "relations").([]interface{})
+	relations := make([]*StoredRelation, 0, len(values))
+	for _, value := range values {
+		if relationship, ok := value.(neo4jDriver.Relationship); ok {
+			relations = append(relations, &StoredRelation{
+				ID:         relationship.ElementId,
+				Type:       relationship.Type,
+				SourceID:   relationship.StartElementId,
+				TargetID:   relationship.EndElementId,
+				Properties: relationship.Props,
+			})
+		}
+	}
+
+	return entity, relations, nil
+}
+
+// RestoreEntity creates an entity from a snapshot, with its stored
+// properties as they were, and returns its new ID.
+func (r *knowledgeRepository) RestoreEntity(ctx context.Context, workspaceID string, entity *StoredEntity) (string, error) {
+	props := storedProperties(entity.Properties)
+	props[propertyWorkspaceID] = workspaceID
+
+	cypher := fmt.Sprintf("CREATE (n:%s:%s) SET n = $props RETURN elementId(n) AS id", EntityLabel, quoteIdentifier(entity.Type))
+
+	records, err := r.neo4j.ExecuteWrite(ctx, cypher, map[string]interface{}{"props": props})
+	if err != nil {
+		return "", fmt.Errorf("failed to restore entity: %w", err)
+	}
+
+	return idOf(records), nil
+}
+
+// RestoreRelation creates a relation from a snapshot between the entities
+// it names and returns its new ID, or an empty ID when either entity no
+// longer exists.
+func (r *knowledgeRepository) RestoreRelation(ctx context.Context, workspaceID string, relation *StoredRelation) (string, error) {
+	props := storedProperties(relation.Properties)
+	props[propertyWorkspaceID] = workspaceID
+
+	cypher := fmt.Sprintf(`MATCH (a:Entity), (b:Entity)
+		WHERE elementId(a) = $sourceId AND elementId(b) = $targetId
+			AND a.workspace_id = $workspaceId AND b.workspace_id = $workspaceId
+		CREATE (a)-[r:%s]->(b) SET r = $props
+		RETURN elementId(r) AS id`, quoteIdentifier(relation.Type))
+
+	records, err := r.neo4j.ExecuteWrite(ctx, cypher, map[string]interface{}{
+		"sourceId":    relation.SourceID,
+		"targetId":    relation.TargetID,
+		"workspaceId": workspaceID,
+		"props":       props,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to restore relation: %w", err)
+	}
+
+	return idOf(records), nil
+}
+
+// ReplaceEntityProperties sets every stored property of an entity, system
+// ones included, to those of a snapshot.
+func (r *knowledgeRepository) ReplaceEntityProperties(ctx context.Context, workspaceID, id string, properties map[string]interface{}) error {
+	props := storedProperties(properties)
+	props[propertyWorkspaceID] = workspaceID
+
+	_, err := r.neo4j.ExecuteWrite(ctx,
+		`MATCH (n:Entity) WHERE elementId(n) = $id AND n.workspace_id = $workspaceId SET n = $props`,
+		map[string]interface{}{"id": id, "workspaceId": workspaceID, "props": props},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to restore entity: %w", err)
+	}
+
+	return nil
+}
+
+// ScanEntities returns up to limit entities whose IDs sort after after, in ID
+// order, so that a whole workspace can be read in batches.
+func (r *knowledgeRepository) ScanEntities(ctx context.Context, workspaceID, after string, limit int) ([]*Entity, error) {
+	records, err := r.neo4j.ExecuteRead(ctx,
+		`MATCH (n:Entity) WHERE n.workspace_id = $workspaceId AND elementId(n) > $after
+		RETURN n ORDER BY elementId(n) LIMIT $limit`,
+		map[string]interface{}{"workspaceId": workspaceID, "after": after, "limit": limit},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan entities: %w", err)
+	}
+
+	entities := make([]*Entity, 0, len(records))
+	for _, record := range records {
+		if node, ok := recordValue(record, "n").(neo4jDriver.Node); ok {
+			entities = append(entities, nodeToEntity(node))
+		}
+	}
+
+	return entities, nil
+}
+
+// ScanRelations is ScanEntities for relations.
+func (r *knowledgeRepository) ScanRelations(ctx context.Context, workspaceID, after string, limit int) ([]*ScannedRelation, error) {
+	records, err := r.neo4j.ExecuteRead(ctx,
+		`MATCH (a:Entity)-[r]->(b:Entity) WHERE r.workspace_id = $workspaceId AND elementId(r) > $after
+		RETURN r, a, b ORDER BY elementId(r) LIMIT $limit`,
+		map[string]interface{}{"workspaceId": workspaceID, "after": after, "limit": limit},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan relations: %w", err)
+	}
+
+	relations := make([]*ScannedRelation, 0, len(records))
+	for _, record := range records {
+		relationship, ok := recordValue(record, "r").(neo4jDriver.Relationship)
+		if !ok {
+			continue
+		}
+
+		scanned := &ScannedRelation{Relation: relationshipToRelation(relationship)}
+		if source, ok := recordValue(record, "a").(neo4jDriver.Node); ok {
+			scanned.SourceType = nodeToEntity(source).Type
+		}
+		if target, ok := recordValue(record, "b").(neo4jDriver.Node); ok {
+			scanned.TargetType = nodeToEntity(target).Type
+		}
+		relations = append(relations, scanned)
+	}
+
+	return relations, nil
+}
+
+// RelationFanOut returns up to limit entities with more than one relation of
+// relationType leaving them, or entering them when outgoing is false.
+func (r *knowledgeRepository) RelationFanOut(ctx context.Context, workspaceID, relationType string, outgoing bool, limit int) ([]*RelationCount, error) {
+	pattern := "(n:Entity)-[r:%s]->(:Entity)"
+	if !outgoing {
+		pattern = "(n:Entity)<-[r:%s]-(:Entity)"
+	}
+
+	cypher := fmt.Sprintf(`MATCH `+pattern+`
+		WHERE n.workspace_id = $workspaceId AND r.workspace_id = $workspaceId
+		WITH n, count(r) AS total WHERE total > 1
+		RETURN n, total ORDER BY elementId(n) LIMIT $limit`, quoteIdentifier(relationType))
+
+	records, err := r.neo4j.ExecuteRead(ctx, cypher, map[string]interface{}{
+		"workspaceId": workspaceID,
+		"limit":       limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count relations: %w", err)
+	}
+
+	counts := make([]*RelationCount, 0, len(records))
+	for _, record := range records {
+		node, ok := recordValue(record, "n").(neo4jDriver.Node)
+		if !ok {
+			continue
+		}
+
+		total, _ := recordValue(record, "total").(int64)
+		counts = append(counts, &RelationCount{
+			EntityID:   node.ElementId,
+			EntityType: nodeToEntity(node).Type,
+			Count:      total,
+		})
+	}
+
+	return counts, nil
+}
+
+// ImportEntities merges a batch of entities in one UNWIND query, updating
+// those of the type whose key property has the same value. It returns how
+// many entities were created.
+func (r *knowledgeRepository) ImportEntities(ctx context.Context, workspaceID, createdBy string, batch *ImportEntityBatch) (int64, error) {
+	rows := make([]map[string]interface{}, len(batch.Rows))
+	for i, row := range batch.Rows {
+		rows[i] = map[string]interface{}{"key": row.Key, "props": row.Properties}
+	}
+
+	cypher := fmt.Sprintf(`UNWIND $rows AS row
+		MERGE (n:%s:%s {%s: $workspaceId, %s: row.key})
+		ON CREATE SET n.%s = $createdBy, n.%s = $now
+		SET n += row.props, n.%s = $now`,
+		EntityLabel, quoteIdentifier(batch.Type), propertyWorkspaceID, quoteIdentifier(batch.Key),
+		propertyCreatedBy, propertyCreatedAt, propertyUpdatedAt)
+
+	counters, err := r.runImport(ctx, cypher, map[string]interface{}{
+		"rows":        rows,
+		"workspaceId": workspaceID,
+		"createdBy":   createdBy,
+		"now":         time.Now(),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to import entities: %w", err)
+	}
+
+	return int64(counters.NodesCreated()), nil
+}
+
+// ImportRelations merges a batch of relations in one UNWIND query. Rows
+// whose ends do not exist are skipped; MatchImportEndpoints finds them
+// beforehand. It returns how many relations were created.
+func (r *knowledgeRepository) ImportRelations(ctx context.Context, workspaceID, createdBy string, batch *ImportRelationBatch) (int64, error) {
+	rows := make([]map[string]interface{}, len(batch.Rows))
+	for i, row := range batch.Rows {
+		rows[i] = map[string]interface{}{"source": row.Source, "target": row.Target, "props": row.Properties}
+	}
+
+	cypher := fmt.Sprintf(`UNWIND $rows AS row
+		MATCH (a:%s:%s {%s: $workspaceId, %s: row.source})
+		MATCH (b:%s:%s {%s: $workspaceId, %s: row.target})
+		MERGE (a)-[r:%s]->(b)
+		ON CREATE SET r.%s = $workspaceId, r.%s = $createdBy, r.%s = $now
+		SET r += row.props, r.%s = $now`,
+		EntityLabel, quoteIdentifier(batch.Source.Type), propertyWorkspaceID, quoteIdentifier(batch.Source.Key),
+		EntityLabel, quoteIdentifier(batch.Target.Type), propertyWorkspaceID, quoteIdentifier(batch.Target.Key),
+		quoteIdentifier(batch.Type),
+		propertyWorkspaceID, propertyCreatedBy, propertyCreatedAt, propertyUpdatedAt)
+
+	counters, err := r.runImport(ctx, cypher, map[string]interface{}{
+		"rows":        rows,
+		"workspaceId": workspaceID,
+		"createdBy":   createdBy,
+		"now":         time.Now(),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to import relations: %w", err)
+	}
+
+	return int64(counters.RelationshipsCreated()), nil
+}
+
+// MatchImportEndpoints reports, for every row of a batch, whether its source
+// and target entities exist.
+func (r *knowledgeRepository) MatchImportEndpoints(ctx context.Context, workspaceID string, batch *ImportRelationBatch) ([]*ImportEndpointMatch, error) {
+	rows := make([]map[string]interface{}, len(batch.Rows))
+	for i, row := range batch.Rows {
+		rows[i] = map[string]interface{}{"row": row.Row, "source": row.Source, "target": row.Target}
+	}
+
+	cypher := fmt.Sprintf(`UNWIND $rows AS row
+		OPTIONAL MATCH (a:%s:%s {%s: $workspaceId, %s: row.source})
+		WITH row, count(a) > 0 AS source
+		OPTIONAL MATCH (b:%s:%s {%s: $workspaceId, %s: row.target})
+		RETURN row.row AS row, source, count(b) > 0 AS target`,
+		EntityLabel, quoteIdentifier(batch.Source.Type), propertyWorkspaceID, quoteIdentifier(batch.Source.Key),
+		EntityLabel, quoteIdentifier(batch.Target.Type), propertyWorkspaceID, quoteIdentifier(batch.Target.Key))
+
+	records, err := r.neo4j.ExecuteRead(ctx, cypher, map[string]interface{}{
+		"rows":        rows,
+		"workspaceId": workspaceID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to match relation ends: %w", err)
+	}
+
+	matches := make([]*ImportEndpointMatch, 0, len(records))
+	for _, record := range records {
+		row, _ := recordValue(record, "row").(int64)
+		source, _ := recordValue(record, "source").(bool)
+		target, _ := recordValue(record, "target").(bool)
+		matches = append(matches, &ImportEndpointMatch{Row: int(row), Source: source, Target: target})
+	}
+
+	return matches, nil
+}
+
+// runImport writes with RunWrite, which closes its session before returning.
+// Closing the session consumes the result, and the driver keeps the summary,
+// or the error the query failed with, for Consume.
+func (r *knowledgeRepository) runImport(ctx context.Context, cypher string, params map[string]interface{}) (neo4jDriver.Counters, error) {
+	result, err := r.neo4j.RunWrite(ctx, cypher, params)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := result.Consume(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return summary.Counters(), nil
+}
+
+// DeleteWorkspace removes every entity of the workspace together with its
+// relations.
+func (r *knowledgeRepository) DeleteWorkspace(ctx context.Context, workspaceID string) error {
+	_, err := r.neo4j.RunWrite(ctx,
+		"MATCH (n:Entity) WHERE n.workspace_id = $workspaceId DETACH DELETE n",
+		map[string]interface{}{"workspaceId": workspaceID},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete workspace graph: %w", err)
+	}
+
+	return nil
+}
+
+// withSystemProperties copies properties and adds the workspace, the author
+// and timestamps.
+func withSystemProperties(properties map[string]interface{}, workspaceID, createdBy string, now time.Time) map[string]interface{} {
+	props := make(map[string]interface{}, len(properties)+4)
+	for key, value := range properties {
+		props[key] = value
+	}
+	props[propertyWorkspaceID] = workspaceID
+	props[propertyCreatedBy] = createdBy
+	props[propertyCreatedAt] = now
+	props[propertyUpdatedAt] = now
+
+	return props
+}
+
+// quoteIdentifier backtick-quotes a label or relationship type for Cypher.
+func quoteIdentifier(identifier string) string {
+	return "`" + identifier + "`"
+}
+
+func recordValue(record *neo4jDriver.Record, key string) interface{} {
+	value, _ := record.Get(key)
+	return value
+}
+
+func countOf(records []*neo4jDriver.Record, key string) int64 {
+	if len(records) == 0 {
+		return 0
+	}
+
+	count, _ := recordValue(records[0], key).(int64)
+	return count
+}
+
+func idOf(records []*neo4jDriver.Record) string {
+	if len(records) == 0 {
+		return ""
+	}
+
+	id, _ := recordValue(records[0], "id").(string)
+	return id
+}
+
+// storedProperties converts properties read back from MongoDB into values
+// Neo4j accepts.
+func storedProperties(properties map[string]interface{}) map[string]interface{} {
+	props := make(map[string]interface{}, len(properties)+1)
+	for key, value := range properties {
+		props[key] = storedValue(value)
+	}
+
+	return props
+}
+
+func storedValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case primitive.DateTime:
+		return v.Time()
+	case primitive.A:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = storedValue(item)
+		}
+		return items
+	case int32:
+		return int64(v)
+	default:
+		return v
+	}
+}
+
+func singleEntity(records []*neo4jDriver.Record, key string) (*Entity, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	node, ok := recordValue(records[0], key).(neo4jDriver.Node)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result: %s is not a node", key)
+	}
+
+	return nodeToEntity(node), nil
+}
+
+func singleRelation(records []*neo4jDriver.Record, key string) (*Relation, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	relationship, ok := recordValue(records[0], key).(neo4jDriver.Relationship)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result: %s is not a relationship", key)
+	}
+
+	return relationshipToRelation(relationship), nil
+}
+
+func nodeToEntity(node neo4jDriver.Node) *Entity {
+	entity := &Entity{
+		ID:         node.ElementId,
+		Properties: userProperties(node.Props),
+		Aliases:    stringsOf(node.Props[propertyAliases]),
+		Provenance: provenanceOf(node.Props),
+		CreatedAt:  timeProperty(node.Props, propertyCreatedAt),
+		UpdatedAt:  timeProperty(node.Props, propertyUpdatedAt),
+	}
+
+	for _, label := range node.Labels {
+		if label != EntityLabel {
+			entity.Type = label
+			break
+		}
+	}
+
+	return entity
+}
+
+func relationshipToRelation(relationship neo4jDriver.Relationship) *Relation {
+	return &Relation{
+		ID:         relationship.ElementId,
+		Type:       relationship.Type,
+		SourceID:   relationship.StartElementId,
+		TargetID:   relationship.EndElementId,
+		Properties: userProperties(relationship.Props),
+		Provenance: provenanceOf(relationship.Props),
+		CreatedAt:  timeProperty(relationship.Props, propertyCreatedAt),
+		UpdatedAt:  timeProperty(relationship.Props, propertyUpdatedAt),
+	}
+}
+
+func userProperties(props map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{}, len(props))
+	for key, value := range props {
+		if !reservedProperties[key] {
+			properties[key] = value
+		}
+	}
+
+	return properties
+}
+
+// stringsOf reads a list of strings stored on a node.
+func stringsOf(value interface{}) []string {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if text, ok := item.(string); ok {
+			values = append(values, text)
+		}
+	}
+
+	return values
+}
+
+// provenanceOf reads the provenance properties, or returns nil for entities
+// and relations that were not extracted.
+func provenanceOf(props map[string]interface{}) *Provenance {
+	documentID, ok := props[propertySourceDocumentID].(string)
+	if !ok {
+		return nil
+	}
+
+	provenance := &Provenance{DocumentID: documentID}
+	provenance.DocumentVersion, _ = props[propertySourceDocumentVersion].(int64)
+	provenance.Start, _ = props[propertySourceStart].(int64)
+	provenance.End, _ = props[propertySourceEnd].(int64)
+	provenance.Extractor, _ = props[propertyExtractor].(string)
+	provenance.ExtractorVersion, _ = props[propertyExtractorVersion].(string)
+
+	return provenance
+}
+
+// properties returns the provenance as graph properties.
+func (p *Provenance) properties() map[string]interface{} {
+	return map[string]interface{}{
+		propertySourceDocumentID:      p.DocumentID,
+		propertySourceDocumentVersion: p.DocumentVersion,
+		propertySourceStart:           p.Start,
+		propertySourceEnd:             p.End,
+		propertyExtractor:             p.Extractor,
+		propertyExtractorVersion:      p.ExtractorVersion,
+	}
+}
+
+func timeProperty(props map[string]interface{}, key string) time.Time {
+	switch value := props[key].(type) {
+	case time.Time:
+		return value
+	case neo4jDriver.LocalDateTime:
+		return value.Time()
+	default:
+		return time.Time{}
+	}
+}
+
+func toPath(path neo4jDriver.Path) *Path {
+	result := &Path{
+		Length:    len(path.Relationships),
+		Entities:  make([]*Entity, len(path.Nodes)),
+		Relations: make([]*Relation, len(path.Relationships)),
+	}
+
+	for i, node := range path.Nodes {
+		result.Entities[i] = nodeToEntity(node)
+	}
+	for i, relationship := range path.Relationships {
+		result.Relations[i] = relationshipToRelation(relationship)
+	}
+
+	return result
+}
+
+// subgraphBuilder collects the distinct entities and relations of several
+// paths, keeping the order in which they were first seen.
+type subgraphBuilder struct {
+	entities  []*Entity
+	relations []*Relation
+	seen      map[string]bool
+}
+
+func newSubgraphBuilder() *subgraphBuilder {
+	return &subgraphBuilder{
+		entities:  []*Entity{},
+		relations: []*Relation{},
+		seen:      make(map[string]bool),
+	}
+}
+
+func (b *subgraphBuilder) addNode(node neo4jDriver.Node) {
+	if b.seen["n:"+node.ElementId] {
+		return
+	}
+	b.seen["n:"+node.ElementId] = true
+	b.entities = append(b.entities, nodeToEntity(node))
+}
+
+func (b *subgraphBuilder) addPath(path neo4jDriver.Path) {
+	for _, node := range path.Nodes {
+		b.addNode(node)
+	}
+
+	for _, relationship := range path.Relationships {
+		if b.seen["r:"+relationship.ElementId] {
+			continue
+		}
+		b.seen["r:"+relationship.ElementId] = true
+		b.relations = append(b.relations, relationshipToRelation(relationship))
+	}
+}
+
+func (b *subgraphBuilder) subgraph() *Subgraph {
+	return &Subgraph{Entities: b.entities, Relations: b.relations}
+}
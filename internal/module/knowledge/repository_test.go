@@ -0,0 +1,71 @@
+package knowledge
+
+import (
+	"testing"
+	"time"
+
+	neo4jDriver "github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeToEntity(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	entity := nodeToEntity(neo4jDriver.Node{
+		ElementId: "4:db:1",
+		Labels:    []string{EntityLabel, "Organization"},
+		Props: map[string]interface{}{
+			"name":              "IBM",
+			propertyWorkspaceID: "account",
+			propertyCreatedAt:   createdAt,
+			propertyUpdatedAt:   createdAt,
+		},
+	})
+
+	assert.Equal(t, "4:db:1", entity.ID)
+	assert.Equal(t, "Organization", entity.Type)
+	assert.Equal(t, map[string]interface{}{"name": "IBM"}, entity.Properties)
+	assert.Equal(t, createdAt, entity.CreatedAt)
+}
+
+func TestNodeToEntity_Provenance(t *testing.T) {
+	provenance := &Provenance{
+		DocumentID:       "64b7f0c2e4b0a1a2b3c4d510",
+		DocumentVersion:  2,
+		Start:            10,
+		End:              15,
+		Extractor:        RuleExtractor,
+		ExtractorVersion: RuleExtractorVersion,
+	}
+
+	props := provenance.properties()
+	props["name"] = "Alice"
+
+	entity := nodeToEntity(neo4jDriver.Node{
+		ElementId: "4:db:1",
+		Labels:    []string{EntityLabel, "Person"},
+		Props:     props,
+	})
+
+	assert.Equal(t, map[string]interface{}{"name": "Alice"}, entity.Properties)
+	assert.Equal(t, provenance, entity.Provenance)
+}
+
+func TestSubgraphBuilder_DeduplicatesPaths(t *testing.T) {
+	a := neo4jDriver.Node{ElementId: "4:db:1", Labels: []string{EntityLabel, "Person"}}
+	b := neo4jDriver.Node{ElementId: "4:db:2", Labels: []string{EntityLabel, "Person"}}
+	c := neo4jDriver.Node{ElementId: "4:db:3", Labels: []string{EntityLabel, "Organization"}}
+	ab := neo4jDriver.Relationship{ElementId: "5:db:1", Type: "KNOWS", StartElementId: a.ElementId, EndElementId: b.ElementId}
+	bc := neo4jDriver.Relationship{ElementId: "5:db:2", Type: "WORKS_AT", StartElementId: b.ElementId, EndElementId: c.ElementId}
+
+	builder := newSubgraphBuilder()
+	builder.addNode(a)
+	builder.addPath(neo4jDriver.Path{Nodes: []neo4jDriver.Node{a, b}, Relationships: []neo4jDriver.Relationship{ab}})
+	builder.addPath(neo4jDriver.Path{Nodes: []neo4jDriver.Node{a, b, c}, Relationships: []neo4jDriver.Relationship{ab, bc}})
+
+	graph := builder.subgraph()
+	assert.Len(t, graph.Entities, 3)
+	assert.Len(t, graph.Relations, 2)
+	assert.Equal(t, "WORKS_AT", graph.Relations[1].Type)
+	assert.Equal(t, b.ElementId, graph.Relations[1].SourceID)
+}
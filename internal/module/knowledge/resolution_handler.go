@@ -0,0 +1,220 @@
+package knowledge
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/binding"
+)
+
+type ResolutionHandler struct {
+	service ResolutionService
+}
+
+func NewResolutionHandler(service ResolutionService) *ResolutionHandler {
+	return &ResolutionHandler{
+		service: service,
+	}
+}
+
+// GetSettings godoc
+// @Summary Get the entity resolution settings
+// @Description Get the similarity threshold, the properties compared and the conflict policies used to find and merge duplicate entities. Defaults apply until the settings are first saved.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Success 200 {object} map[string]interface{} "Resolution settings retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /workspaces/{workspaceId}/knowledge/resolution/settings [get]
+func (h *ResolutionHandler) GetSettings(c *fiber.Ctx) error {
+	settings, err := h.service.GetSettings(c.Context(), workspaceIDFrom(c))
+	if err != nil {
+		return knowledgeError(c, "Failed to get resolution settings", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Resolution settings retrieved successfully",
+		"data":    settings,
+	})
+}
+
+// UpdateSettings godoc
+// @Summary Update the entity resolution settings
+// @Description Replace the similarity threshold, the properties compared and the conflict policies of the workspace. Requires the admin role.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param request body UpdateResolutionSettingsRequest true "Resolution settings"
+// @Success 200 {object} map[string]interface{} "Resolution settings updated successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Router /workspaces/{workspaceId}/knowledge/resolution/settings [put]
+func (h *ResolutionHandler) UpdateSettings(c *fiber.Ctx) error {
+	var req UpdateResolutionSettingsRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	settings, err := h.service.UpdateSettings(c.Context(), workspaceIDFrom(c), accountIDFrom(c), &req)
+	if err != nil {
+		return knowledgeError(c, "Failed to update resolution settings", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Resolution settings updated successfully",
+		"data":    settings,
+	})
+}
+
+// FindCandidates godoc
+// @Summary Find duplicate entities
+// @Description Propose pairs of named entities of the same type that may be duplicates, best first. Names and aliases are compared with Jaro-Winkler, token set and acronym similarity, and the properties listed in the settings are compared when both entities have them.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param type query string false "Only compare entities of this type"
+// @Param threshold query number false "Minimum score, overriding the settings"
+// @Param limit query int false "Maximum number of pairs" default(50)
+// @Success 200 {object} map[string]interface{} "Merge candidates retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 502 {object} map[string]interface{} "Graph database unavailable"
+// @Router /workspaces/{workspaceId}/knowledge/resolution/candidates [get]
+func (h *ResolutionHandler) FindCandidates(c *fiber.Ctx) error {
+	var query MergeCandidateQuery
+	if err := binding.Query(c, &query); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	candidates, err := h.service.FindCandidates(c.Context(), workspaceIDFrom(c), &query)
+	if err != nil {
+		return knowledgeError(c, "Failed to find merge candidates", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Merge candidates retrieved successfully",
+		"data":    candidates,
+	})
+}
+
+// MergeEntities godoc
+// @Summary Merge entities
+// @Description Merge the source entities into the target. Their relations move to the target, their names become its aliases and their evidence supports it; they are then deleted. Properties the target and a source disagree on are resolved by the conflict policy, and the reject policy refuses the merge.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param request body MergeEntitiesRequest true "Target, sources and conflict policies"
+// @Success 201 {object} map[string]interface{} "Entities merged successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Entity not found"
+// @Failure 409 {object} map[string]interface{} "Conflicting property values"
+// @Router /workspaces/{workspaceId}/knowledge/resolution/merges [post]
+func (h *ResolutionHandler) MergeEntities(c *fiber.Ctx) error {
+	var req MergeEntitiesRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	merge, err := h.service.MergeEntities(c.Context(), workspaceIDFrom(c), accountIDFrom(c), &req)
+	if err != nil {
+		return knowledgeError(c, "Failed to merge entities", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Entities merged successfully",
+		"data":    merge,
+	})
+}
+
+// ListMerges godoc
+// @Summary List merges
+// @Description Page through the merges of the workspace, newest first.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param status query string false "Filter by status" Enums(pending, completed, failed, undone)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(20)
+// @Success 200 {object} map[string]interface{} "Merges retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /workspaces/{workspaceId}/knowledge/resolution/merges [get]
+func (h *ResolutionHandler) ListMerges(c *fiber.Ctx) error {
+	var query MergeListQuery
+	if err := binding.Query(c, &query); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	merges, err := h.service.ListMerges(c.Context(), workspaceIDFrom(c), &query)
+	if err != nil {
+		return knowledgeError(c, "Failed to list merges", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Merges retrieved successfully",
+		"data":    merges,
+	})
+}
+
+// GetMerge godoc
+// @Summary Get a merge
+// @Description Get a merge with the aliases it added, the property conflicts it resolved and the relations it moved.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Merge ID"
+// @Success 200 {object} map[string]interface{} "Merge retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Merge not found"
+// @Router /workspaces/{workspaceId}/knowledge/resolution/merges/{id} [get]
+func (h *ResolutionHandler) GetMerge(c *fiber.Ctx) error {
+	merge, err := h.service.GetMerge(c.Context(), workspaceIDFrom(c), c.Params("id"))
+	if err != nil {
+		return knowledgeError(c, "Failed to get merge", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Merge retrieved successfully",
+		"data":    merge,
+	})
+}
+
+// UndoMerge godoc
+// @Summary Undo a merge
+// @Description Create the merged entities again, with new IDs, together with their relations and evidence, and give the target back its properties from before the merge. A failed merge is rolled back the same way, restoring only the entities it deleted. Relations to entities deleted since cannot be restored.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param id path string true "Merge ID"
+// @Success 200 {object} map[string]interface{} "Merge undone successfully"
+// @Failure 400 {object} map[string]interface{} "Merge cannot be undone"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Merge or target entity not found"
+// @Failure 409 {object} map[string]interface{} "Merge already undone"
+// @Router /workspaces/{workspaceId}/knowledge/resolution/merges/{id}/undo [post]
+func (h *ResolutionHandler) UndoMerge(c *fiber.Ctx) error {
+	merge, err := h.service.UndoMerge(c.Context(), workspaceIDFrom(c), accountIDFrom(c), c.Params("id"))
+	if err != nil {
+		return knowledgeError(c, "Failed to undo merge", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Merge undone successfully",
+		"data":    merge,
+	})
+}
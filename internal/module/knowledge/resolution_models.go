@@ -0,0 +1,211 @@
+package knowledge
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ResolutionSettingsCollectionName = "knowledge_resolution_settings"
+	MergeCollectionName              = "knowledge_merges"
+)
+
+const (
+	// MaxResolutionEntities caps the entities compared in one search. Larger
+	// workspaces are searched one entity type at a time.
+	MaxResolutionEntities = 50000
+
+	// MaxBlockSize caps the entities sharing a blocking key. Larger blocks
+	// hold keys too common to tell entities apart, and are skipped.
+	MaxBlockSize = 200
+
+	MergeCandidateDefaultLimit = 50
+	MergeCandidateMaxLimit     = 500
+
+	MergeListDefaultLimit = 20
+	MergeListMaxLimit     = 100
+
+	DefaultResolutionThreshold = 0.88
+	DefaultPropertyWeight      = 0.3
+
+	// acronymSimilarity is the name similarity of an acronym and the name
+	// it abbreviates, such as IBM and International Business Machines.
+	acronymSimilarity = 0.9
+
+	resolutionScanBatch = 1000
+)
+
+// ConflictPolicy decides the value of a property that the target and a
+// source of a merge both have, with different values.
+type ConflictPolicy string
+
+const (
+	// ConflictKeepTarget keeps the value of the entity merged into.
+	ConflictKeepTarget ConflictPolicy = "keep_target"
+	// ConflictKeepSource takes the value of the source, the last source
+	// winning when there are several.
+	ConflictKeepSource ConflictPolicy = "keep_source"
+	// ConflictUnion keeps every distinct value in a list. Values that cannot
+	// share a list, such as booleans, keep the target value.
+	ConflictUnion ConflictPolicy = "union"
+	// ConflictReject refuses the merge.
+	ConflictReject ConflictPolicy = "reject"
+)
+
+const (
+	MatchJaroWinkler = "jaro_winkler"
+	MatchTokenSet    = "token_set"
+	MatchAcronym     = "acronym"
+)
+
+type MergeStatus string
+
+const (
+	MergeStatusPending   MergeStatus = "pending"
+	MergeStatusCompleted MergeStatus = "completed"
+	MergeStatusFailed    MergeStatus = "failed"
+	MergeStatusUndone    MergeStatus = "undone"
+)
+
+// ResolutionSettings tunes how a workspace looks for duplicate entities.
+// Pairs scoring at least Threshold are proposed. MatchProperties are compared
+// when both entities have them, and their agreement makes up PropertyWeight
+// of the score, the name similarity making up the rest. ConflictPolicy is the
+// default for merges, and PropertyPolicies override it per property.
+type ResolutionSettings struct {
+	ID               primitive.ObjectID        `json:"-" bson:"_id,omitempty"`
+	WorkspaceID      string                    `json:"workspace_id" bson:"workspace_id"`
+	Threshold        float64                   `json:"threshold" bson:"threshold"`
+	PropertyWeight   float64                   `json:"property_weight" bson:"property_weight"`
+	MatchProperties  []string                  `json:"match_properties" bson:"match_properties"`
+	ConflictPolicy   ConflictPolicy            `json:"conflict_policy" bson:"conflict_policy"`
+	PropertyPolicies map[string]ConflictPolicy `json:"property_policies" bson:"property_policies"`
+	UpdatedBy        string                    `json:"updated_by,omitempty" bson:"updated_by"`
+	UpdatedAt        time.Time                 `json:"updated_at" bson:"updated_at"`
+}
+
+type UpdateResolutionSettingsRequest struct {
+	Threshold        float64                   `json:"threshold" validate:"required,gt=0,lte=1"`
+	PropertyWeight   float64                   `json:"property_weight" validate:"min=0,lt=1"`
+	MatchProperties  []string                  `json:"match_properties" validate:"max=20,dive,required,max=64"`
+	ConflictPolicy   ConflictPolicy            `json:"conflict_policy" validate:"omitempty,oneof=keep_target keep_source union reject"`
+	PropertyPolicies map[string]ConflictPolicy `json:"property_policies" validate:"max=50,dive,keys,required,max=64,endkeys,oneof=keep_target keep_source union reject"`
+}
+
+type MergeCandidateQuery struct {
+	Type      string  `query:"type" validate:"omitempty,max=64"`
+	Threshold float64 `query:"threshold" validate:"omitempty,gt=0,lte=1"`
+	Limit     int     `query:"limit"`
+}
+
+// MergeCandidate is a pair of entities of one type that may be duplicates.
+// NameScore is the best similarity of their names and aliases, found by
+// Method. Properties listed in the settings that both entities have are
+// Matched or Mismatched.
+type MergeCandidate struct {
+	EntityType string   `json:"entity_type"`
+	Left       *Entity  `json:"left"`
+	Right      *Entity  `json:"right"`
+	Score      float64  `json:"score"`
+	NameScore  float64  `json:"name_score"`
+	Method     string   `json:"method"`
+	Matched    []string `json:"matched_properties"`
+	Mismatched []string `json:"mismatched_properties"`
+}
+
+// MergeCandidates lists proposals, best first. Truncated is set when the
+// workspace had more entities than one search compares.
+type MergeCandidates struct {
+	Threshold  float64           `json:"threshold"`
+	Compared   int               `json:"compared"`
+	Truncated  bool              `json:"truncated"`
+	Candidates []*MergeCandidate `json:"candidates"`
+}
+
+// MergeEntitiesRequest merges the sources into the target. The policies
+// override those of the workspace settings for this merge.
+type MergeEntitiesRequest struct {
+	TargetID         string                    `json:"target_id" validate:"required"`
+	SourceIDs        []string                  `json:"source_ids" validate:"required,min=1,max=20,dive,required"`
+	ConflictPolicy   ConflictPolicy            `json:"conflict_policy" validate:"omitempty,oneof=keep_target keep_source union reject"`
+	PropertyPolicies map[string]ConflictPolicy `json:"property_policies" validate:"max=50,dive,keys,required,max=64,endkeys,oneof=keep_target keep_source union reject"`
+}
+
+type MergeListQuery struct {
+	Status string `query:"status" validate:"omitempty,oneof=pending completed failed undone"`
+	Page   int64  `query:"page"`
+	Limit  int64  `query:"limit"`
+}
+
+// PropertyConflict records a property the target and a source disagreed on,
+// and the value the merge kept.
+type PropertyConflict struct {
+	Property    string         `json:"property" bson:"property"`
+	SourceID    string         `json:"source_id" bson:"source_id"`
+	TargetValue interface{}    `json:"target_value" bson:"target_value"`
+	SourceValue interface{}    `json:"source_value" bson:"source_value"`
+	Policy      ConflictPolicy `json:"policy" bson:"policy"`
+	Result      interface{}    `json:"result" bson:"result"`
+}
+
+// StoredEntity is an entity with every stored property, system ones
+// included, kept so that a merge can be undone.
+type StoredEntity struct {
+	ID         string                 `json:"id" bson:"id"`
+	Type       string                 `json:"type" bson:"type"`
+	Properties map[string]interface{} `json:"properties" bson:"properties"`
+}
+
+// StoredRelation is StoredEntity for relations.
+type StoredRelation struct {
+	ID         string                 `json:"id" bson:"id"`
+	Type       string                 `json:"type" bson:"type"`
+	SourceID   string                 `json:"source_id" bson:"source_id"`
+	TargetID   string                 `json:"target_id" bson:"target_id"`
+	Properties map[string]interface{} `json:"properties" bson:"properties"`
+}
+
+// EvidenceMove records the evidence a merge moved off a fact, and the fact
+// and entities it pointed at before.
+type EvidenceMove struct {
+	IDs            []primitive.ObjectID `bson:"ids"`
+	FactKind       FactKind             `bson:"fact_kind"`
+	FactID         string               `bson:"fact_id"`
+	SourceEntityID string               `bson:"source_entity_id,omitempty"`
+	TargetEntityID string               `bson:"target_entity_id,omitempty"`
+}
+
+// MergeRecord describes a merge and keeps what undoing it needs: the target
+// and sources as they were, the relations deleted with the sources, the
+// relations created on the target and the evidence moved to it.
+//
+// Relations of the sources are moved to the target. One the target already
+// has, with the same type and other end, is merged into it instead, and one
+// between the merged entities is dropped. Undoing a merge creates the sources
+// again with new IDs, listed in Restored by their old IDs.
+type MergeRecord struct {
+	ID               primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	WorkspaceID      string             `json:"workspace_id" bson:"workspace_id"`
+	EntityType       string             `json:"entity_type" bson:"entity_type"`
+	TargetID         string             `json:"target_id" bson:"target_id"`
+	SourceIDs        []string           `json:"source_ids" bson:"source_ids"`
+	Status           MergeStatus        `json:"status" bson:"status"`
+	Error            string             `json:"error,omitempty" bson:"error,omitempty"`
+	Aliases          []string           `json:"aliases" bson:"aliases"`
+	Conflicts        []PropertyConflict `json:"conflicts" bson:"conflicts"`
+	RelationsMoved   int                `json:"relations_moved" bson:"relations_moved"`
+	RelationsMerged  int                `json:"relations_merged" bson:"relations_merged"`
+	RelationsDropped int                `json:"relations_dropped" bson:"relations_dropped"`
+	Restored         map[string]string  `json:"restored,omitempty" bson:"restored,omitempty"`
+	MergedBy         string             `json:"merged_by" bson:"merged_by"`
+	UndoneBy         string             `json:"undone_by,omitempty" bson:"undone_by,omitempty"`
+	CreatedAt        time.Time          `json:"created_at" bson:"created_at"`
+	UndoneAt         *time.Time         `json:"undone_at,omitempty" bson:"undone_at,omitempty"`
+
+	Target           *StoredEntity     `json:"-" bson:"target"`
+	Sources          []*StoredEntity   `json:"-" bson:"sources"`
+	Relations        []*StoredRelation `json:"-" bson:"relations"`
+	CreatedRelations []string          `json:"-" bson:"created_relations"`
+	EvidenceMoves    []EvidenceMove    `json:"-" bson:"evidence_moves"`
+}
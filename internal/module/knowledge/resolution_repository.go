@@ -0,0 +1,178 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+type ResolutionSettingsRepository interface {
+	Get(ctx context.Context, workspaceID string) (*ResolutionSettings, error)
+	Set(ctx context.Context, settings *ResolutionSettings) (*ResolutionSettings, error)
+	DeleteByWorkspace(ctx context.Context, workspaceID string) error
+}
+
+type resolutionSettingsRepository struct {
+	repo mongo.Repository[ResolutionSettings]
+}
+
+var _ ResolutionSettingsRepository = (*resolutionSettingsRepository)(nil)
+
+func NewResolutionSettingsRepository(mongoService *mongo.MongoService) ResolutionSettingsRepository {
+	return &resolutionSettingsRepository{
+		repo: mongo.NewRepository[ResolutionSettings](mongoService, ResolutionSettingsCollectionName),
+	}
+}
+
+func (r *resolutionSettingsRepository) Get(ctx context.Context, workspaceID string) (*ResolutionSettings, error) {
+	result, err := r.repo.FindOne(ctx, bson.M{"workspace_id": workspaceID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resolution settings: %w", err)
+	}
+
+	return result, nil
+}
+
+// Set replaces the settings of the workspace, creating them the first time.
+func (r *resolutionSettingsRepository) Set(ctx context.Context, settings *ResolutionSettings) (*ResolutionSettings, error) {
+	result, err := r.repo.Update(ctx,
+		bson.M{"workspace_id": settings.WorkspaceID},
+		bson.M{"$set": bson.M{
+			"threshold":         settings.Threshold,
+			"property_weight":   settings.PropertyWeight,
+			"match_properties":  settings.MatchProperties,
+			"conflict_policy":   settings.ConflictPolicy,
+			"property_policies": settings.PropertyPolicies,
+			"updated_by":        settings.UpdatedBy,
+			"updated_at":        settings.UpdatedAt,
+		}},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update resolution settings: %w", err)
+	}
+	if result != nil {
+		return result, nil
+	}
+
+	result, err = r.repo.Create(ctx, *settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resolution settings: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *resolutionSettingsRepository) DeleteByWorkspace(ctx context.Context, workspaceID string) error {
+	if _, err := r.repo.DeleteMany(ctx, bson.M{"workspace_id": workspaceID}); err != nil {
+		return fmt.Errorf("failed to delete resolution settings: %w", err)
+	}
+
+	return nil
+}
+
+type MergeRepository interface {
+	Create(ctx context.Context, record *MergeRecord) (*MergeRecord, error)
+	GetByID(ctx context.Context, workspaceID string, id primitive.ObjectID) (*MergeRecord, error)
+	List(ctx context.Context, filter bson.M, pagination mongo.PaginationOptions) (*mongo.PaginatedResult[MergeRecord], error)
+	Save(ctx context.Context, record *MergeRecord) (*MergeRecord, error)
+	MarkUndone(ctx context.Context, record *MergeRecord) (*MergeRecord, error)
+	DeleteByWorkspace(ctx context.Context, workspaceID string) error
+}
+
+type mergeRepository struct {
+	repo mongo.Repository[MergeRecord]
+}
+
+var _ MergeRepository = (*mergeRepository)(nil)
+
+func NewMergeRepository(mongoService *mongo.MongoService) MergeRepository {
+	return &mergeRepository{
+		repo: mongo.NewRepository[MergeRecord](mongoService, MergeCollectionName),
+	}
+}
+
+func (r *mergeRepository) Create(ctx context.Context, record *MergeRecord) (*MergeRecord, error) {
+	result, err := r.repo.Create(ctx, *record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create merge: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *mergeRepository) GetByID(ctx context.Context, workspaceID string, id primitive.ObjectID) (*MergeRecord, error) {
+	result, err := r.repo.FindOne(ctx, bson.M{"_id": id, "workspace_id": workspaceID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merge: %w", err)
+	}
+
+	return result, nil
+}
+
+// List sorts merges newest first.
+func (r *mergeRepository) List(ctx context.Context, filter bson.M, pagination mongo.PaginationOptions) (*mongo.PaginatedResult[MergeRecord], error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	result, err := r.repo.FindWithPagination(ctx, filter, pagination, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merges: %w", err)
+	}
+
+	return result, nil
+}
+
+// Save stores the outcome of a merge or of undoing it.
+func (r *mergeRepository) Save(ctx context.Context, record *MergeRecord) (*MergeRecord, error) {
+	result, err := r.repo.Update(ctx,
+		bson.M{"_id": record.ID, "workspace_id": record.WorkspaceID},
+		bson.M{"$set": bson.M{
+			"status":            record.Status,
+			"error":             record.Error,
+			"relations_moved":   record.RelationsMoved,
+			"relations_merged":  record.RelationsMerged,
+			"relations_dropped": record.RelationsDropped,
+			"restored":          record.Restored,
+			"relations":         record.Relations,
+			"created_relations": record.CreatedRelations,
+			"evidence_moves":    record.EvidenceMoves,
+		}},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update merge: %w", err)
+	}
+
+	return result, nil
+}
+
+// MarkUndone records that a completed or failed merge was undone. It returns
+// nil when the merge has changed since it was read, so that it is only undone
+// once.
+func (r *mergeRepository) MarkUndone(ctx context.Context, record *MergeRecord) (*MergeRecord, error) {
+	result, err := r.repo.Update(ctx,
+		bson.M{"_id": record.ID, "workspace_id": record.WorkspaceID, "status": record.Status, "undone_at": nil},
+		bson.M{"$set": bson.M{
+			"status":    MergeStatusUndone,
+			"restored":  record.Restored,
+			"undone_by": record.UndoneBy,
+			"undone_at": record.UndoneAt,
+		}},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update merge: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *mergeRepository) DeleteByWorkspace(ctx context.Context, workspaceID string) error {
+	if _, err := r.repo.DeleteMany(ctx, bson.M{"workspace_id": workspaceID}); err != nil {
+		return fmt.Errorf("failed to delete merges: %w", err)
+	}
+
+	return nil
+}
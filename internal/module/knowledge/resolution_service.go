@@ -0,0 +1,926 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+// ResolutionService finds entities that are likely duplicates and merges
+// them, keeping what undoing the merge needs.
+type ResolutionService interface {
+	GetSettings(ctx context.Context, workspaceID string) (*ResolutionSettings, error)
+	UpdateSettings(ctx context.Context, workspaceID, accountID string, req *UpdateResolutionSettingsRequest) (*ResolutionSettings, error)
+	FindCandidates(ctx context.Context, workspaceID string, query *MergeCandidateQuery) (*MergeCandidates, error)
+
+	MergeEntities(ctx context.Context, workspaceID, accountID string, req *MergeEntitiesRequest) (*MergeRecord, error)
+	ListMerges(ctx context.Context, workspaceID string, query *MergeListQuery) (*mongo.PaginatedResult[MergeRecord], error)
+	GetMerge(ctx context.Context, workspaceID, id string) (*MergeRecord, error)
+	UndoMerge(ctx context.Context, workspaceID, accountID, id string) (*MergeRecord, error)
+
+	DeleteWorkspace(ctx context.Context, workspaceID string) error
+}
+
+type resolutionService struct {
+	settings  ResolutionSettingsRepository
+	merges    MergeRepository
+	knowledge *knowledgeService
+}
+
+func newResolutionService(settings ResolutionSettingsRepository, merges MergeRepository, knowledge *knowledgeService) *resolutionService {
+	return &resolutionService{
+		settings:  settings,
+		merges:    merges,
+		knowledge: knowledge,
+	}
+}
+
+func (s *resolutionService) GetSettings(ctx context.Context, workspaceID string) (*ResolutionSettings, error) {
+	settings, err := s.settings.Get(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		return &ResolutionSettings{
+			WorkspaceID:      workspaceID,
+			Threshold:        DefaultResolutionThreshold,
+			PropertyWeight:   DefaultPropertyWeight,
+			MatchProperties:  []string{},
+			ConflictPolicy:   ConflictKeepTarget,
+			PropertyPolicies: map[string]ConflictPolicy{},
+		}, nil
+	}
+
+	return settings, nil
+}
+
+func (s *resolutionService) UpdateSettings(ctx context.Context, workspaceID, accountID string, req *UpdateResolutionSettingsRequest) (*ResolutionSettings, error) {
+	matchProperties := make([]string, 0, len(req.MatchProperties))
+	for _, name := range req.MatchProperties {
+		if err := validatePropertyName(name); err != nil {
+			return nil, err
+		}
+		if !containsString(matchProperties, name) {
+			matchProperties = append(matchProperties, name)
+		}
+	}
+
+	policies := make(map[string]ConflictPolicy, len(req.PropertyPolicies))
+	for name, policy := range req.PropertyPolicies {
+		if err := validatePropertyName(name); err != nil {
+			return nil, err
+		}
+		policies[name] = policy
+	}
+
+	policy := req.ConflictPolicy
+	if policy == "" {
+		policy = ConflictKeepTarget
+	}
+
+	return s.settings.Set(ctx, &ResolutionSettings{
+		WorkspaceID:      workspaceID,
+		Threshold:        req.Threshold,
+		PropertyWeight:   req.PropertyWeight,
+		MatchProperties:  matchProperties,
+		ConflictPolicy:   policy,
+		PropertyPolicies: policies,
+		UpdatedBy:        accountID,
+		UpdatedAt:        time.Now(),
+	})
+}
+
+// FindCandidates proposes pairs of entities of the same type whose score
+// reaches the threshold, best first. Only entities with a name take part,
+// and only entities sharing a blocking key are compared.
+func (s *resolutionService) FindCandidates(ctx context.Context, workspaceID string, query *MergeCandidateQuery) (*MergeCandidates, error) {
+	settings, err := s.GetSettings(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	entityType := ""
+	if query.Type != "" {
+		entityType, err = normalizeEntityType(query.Type)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	threshold := settings.Threshold
+	if query.Threshold > 0 {
+		threshold = query.Threshold
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = MergeCandidateDefaultLimit
+	}
+	if limit > MergeCandidateMaxLimit {
+		limit = MergeCandidateMaxLimit
+	}
+
+	entities, truncated, err := s.scanNamedEntities(ctx, workspaceID, entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := map[string][]int{}
+	for i, entity := range entities {
+		for _, key := range blockingKeys(entityNames(entity)) {
+			block := entity.Type + "\x00" + key
+			blocks[block] = append(blocks[block], i)
+		}
+	}
+
+	compared := map[[2]int]bool{}
+	candidates := []*MergeCandidate{}
+	for _, members := range blocks {
+		if len(members) < 2 || len(members) > MaxBlockSize {
+			continue
+		}
+
+		for i := 0; i < len(members); i++ {
+			for j := i + 1; j < len(members); j++ {
+				pair := [2]int{members[i], members[j]}
+				if compared[pair] {
+					continue
+				}
+				compared[pair] = true
+
+				candidate := scoreCandidate(entities[pair[0]], entities[pair[1]], settings)
+				if candidate.Score >= threshold {
+					candidates = append(candidates, candidate)
+				}
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		if candidates[i].Left.ID != candidates[j].Left.ID {
+			return candidates[i].Left.ID < candidates[j].Left.ID
+		}
+		return candidates[i].Right.ID < candidates[j].Right.ID
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	return &MergeCandidates{
+		Threshold:  threshold,
+		Compared:   len(entities),
+		Truncated:  truncated,
+		Candidates: candidates,
+	}, nil
+}
+
+// MergeEntities merges the sources into the target: their relations move to
+// the target, their properties fill in those the target lacks, their names
+// become aliases of the target and their evidence supports it. The sources
+// are then deleted.
+//
+// The merge is recorded before the graph changes. A merge that fails part
+// way is marked failed, with the snapshot of the entities as they were.
+func (s *resolutionService) MergeEntities(ctx context.Context, workspaceID, accountID string, req *MergeEntitiesRequest) (*MergeRecord, error) {
+	settings, err := s.GetSettings(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := settings.ConflictPolicy
+	if req.ConflictPolicy != "" {
+		policy = req.ConflictPolicy
+	}
+	policies := make(map[string]ConflictPolicy, len(settings.PropertyPolicies)+len(req.PropertyPolicies))
+	for name, value := range settings.PropertyPolicies {
+		policies[name] = value
+	}
+	for name, value := range req.PropertyPolicies {
+		policies[name] = value
+	}
+
+	target, targetRelations, err := s.knowledge.repository.SnapshotEntity(ctx, workspaceID, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, fmt.Errorf("entity not found")
+	}
+
+	var (
+		sources         []*StoredEntity
+		sourceIDs       []string
+		sourceRelations []*StoredRelation
+	)
+	for _, id := range req.SourceIDs {
+		if id == target.ID {
+			return nil, fmt.Errorf("invalid merge: an entity cannot be merged into itself")
+		}
+		if containsString(sourceIDs, id) {
+			continue
+		}
+
+		source, relations, err := s.knowledge.repository.SnapshotEntity(ctx, workspaceID, id)
+		if err != nil {
+			return nil, err
+		}
+		if source == nil {
+			return nil, fmt.Errorf("entity %s not found", id)
+		}
+		if source.Type != target.Type {
+			return nil, fmt.Errorf("invalid merge: entity %s is a %s, not a %s", id, source.Type, target.Type)
+		}
+
+		sources = append(sources, source)
+		sourceIDs = append(sourceIDs, id)
+		sourceRelations = append(sourceRelations, relations...)
+	}
+
+	updates, conflicts, err := resolveProperties(target, sources, policy, policies)
+	if err != nil {
+		return nil, err
+	}
+	aliases := mergeAliases(target, sources)
+	updates[propertyAliases] = aliases
+
+	if err := s.checkMerge(ctx, workspaceID, target, sourceIDs, targetRelations, sourceRelations, updates); err != nil {
+		return nil, err
+	}
+
+	record := &MergeRecord{
+		ID:          primitive.NewObjectID(),
+		WorkspaceID: workspaceID,
+		EntityType:  target.Type,
+		TargetID:    target.ID,
+		SourceIDs:   sourceIDs,
+		Status:      MergeStatusPending,
+		Aliases:     aliases,
+		Conflicts:   conflicts,
+		MergedBy:    accountID,
+		CreatedAt:   time.Now(),
+		Target:      target,
+		Sources:     sources,
+	}
+	if _, err := s.merges.Create(ctx, record); err != nil {
+		return nil, err
+	}
+
+	if err := s.merge(ctx, record, targetRelations, sourceRelations, updates); err != nil {
+		record.Status = MergeStatusFailed
+		record.Error = err.Error()
+		if _, saveErr := s.merges.Save(ctx, record); saveErr != nil {
+			return nil, fmt.Errorf("%w (and failed to record it: %v)", err, saveErr)
+		}
+		return nil, err
+	}
+
+	record.Status = MergeStatusCompleted
+	if _, err := s.merges.Save(ctx, record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+func (s *resolutionService) ListMerges(ctx context.Context, workspaceID string, query *MergeListQuery) (*mongo.PaginatedResult[MergeRecord], error) {
+	filter := bson.M{"workspace_id": workspaceID}
+	if query.Status != "" {
+		filter["status"] = query.Status
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = MergeListDefaultLimit
+	}
+	if limit > MergeListMaxLimit {
+		limit = MergeListMaxLimit
+	}
+
+	return s.merges.List(ctx, filter, mongo.PaginationOptions{
+		Page:  query.Page,
+		Limit: limit,
+	})
+}
+
+func (s *resolutionService) GetMerge(ctx context.Context, workspaceID, id string) (*MergeRecord, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("merge not found")
+	}
+
+	record, err := s.merges.GetByID(ctx, workspaceID, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, fmt.Errorf("merge not found")
+	}
+
+	return record, nil
+}
+
+// UndoMerge creates the sources of a completed merge again, with new IDs, and
+// their relations and evidence. The target gets back the properties it had
+// before the merge, losing changes made to it since. Relations to entities
+// deleted since the merge cannot be restored and are left out.
+//
+// A merge that failed part way is rolled back the same way: the sources it
+// had not deleted yet are kept, together with their relations.
+func (s *resolutionService) UndoMerge(ctx context.Context, workspaceID, accountID, id string) (*MergeRecord, error) {
+	record, err := s.GetMerge(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case record.Status == MergeStatusUndone:
+		return nil, fmt.Errorf("merge was already undone")
+	case record.Status == MergeStatusCompleted:
+	case record.Status == MergeStatusFailed && record.UndoneAt == nil:
+	default:
+		return nil, fmt.Errorf("invalid merge: only completed and failed merges can be undone")
+	}
+
+	if _, err := s.knowledge.GetEntity(ctx, workspaceID, record.TargetID); err != nil {
+		return nil, err
+	}
+
+	// A completed merge deleted every source; the IDs of deleted nodes may
+	// since have been reused, so they are only looked up after a failure.
+	var remaining map[string]string
+	if record.Status == MergeStatusFailed {
+		if remaining, err = s.knowledge.repository.EntityTypes(ctx, workspaceID, record.SourceIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	record.UndoneBy = accountID
+	record.UndoneAt = &now
+	claimed, err := s.merges.MarkUndone(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+	if claimed == nil {
+		return nil, fmt.Errorf("merge was already undone")
+	}
+
+	record.Status = MergeStatusUndone
+	record.Error = ""
+	if err := s.undo(ctx, record, remaining); err != nil {
+		record.Status = MergeStatusFailed
+		record.Error = err.Error()
+		if _, saveErr := s.merges.Save(ctx, record); saveErr != nil {
+			return nil, fmt.Errorf("%w (and failed to record it: %v)", err, saveErr)
+		}
+		return nil, err
+	}
+
+	if _, err := s.merges.Save(ctx, record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+func (s *resolutionService) DeleteWorkspace(ctx context.Context, workspaceID string) error {
+	if err := s.merges.DeleteByWorkspace(ctx, workspaceID); err != nil {
+		return err
+	}
+
+	return s.settings.DeleteByWorkspace(ctx, workspaceID)
+}
+
+// merge rewires the relations of the sources to the target, moves their
+// evidence, updates the target and deletes the sources, recording what it
+// changes on the record as it goes.
+func (s *resolutionService) merge(ctx context.Context, record *MergeRecord, targetRelations, sourceRelations []*StoredRelation, updates map[string]interface{}) error {
+	workspaceID, targetID := record.WorkspaceID, record.TargetID
+
+	kept, moves := planRelations(targetID, record.SourceIDs, targetRelations, sourceRelations)
+	existing := make(map[string]string, len(kept))
+	for _, relation := range kept {
+		existing[relationKey(relation.Type, relation.SourceID, relation.TargetID)] = relation.ID
+	}
+
+	for _, move := range moves {
+		relation := move.relation
+		record.Relations = append(record.Relations, relation)
+
+		// A dropped relation keeps its evidence, parked on the target so that
+		// it is cleaned up with it, until the merge is undone.
+		factID := relation.ID
+		switch move.action {
+		case relationDropped:
+			record.RelationsDropped++
+		case relationMerged:
+			factID = existing[move.key]
+			record.RelationsMerged++
+		case relationMoved:
+			created, err := s.knowledge.repository.RestoreRelation(ctx, workspaceID, &StoredRelation{
+				Type:       relation.Type,
+				SourceID:   move.sourceID,
+				TargetID:   move.targetID,
+				Properties: relation.Properties,
+			})
+			if err != nil {
+				return err
+			}
+			if created == "" {
+				return fmt.Errorf("entity not found: a relation of the merged entities lost its other end")
+			}
+			factID = created
+			existing[move.key] = created
+			record.CreatedRelations = append(record.CreatedRelations, created)
+			record.RelationsMoved++
+		}
+
+		if err := s.moveEvidence(ctx, record, FactKindRelation, relation.ID, relation.SourceID, relation.TargetID,
+			factID, move.sourceID, move.targetID); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range record.SourceIDs {
+		if err := s.moveEvidence(ctx, record, FactKindEntity, id, "", "", targetID, "", ""); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.knowledge.repository.UpdateEntity(ctx, workspaceID, targetID, updates); err != nil {
+		return err
+	}
+
+	for _, id := range record.SourceIDs {
+		if _, err := s.knowledge.repository.DeleteEntity(ctx, workspaceID, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkMerge rejects a merge that would leave the target in a state the
+// workspace schema does not allow, such as a list in a string property or a
+// second relation of a type it may only have one of.
+func (s *resolutionService) checkMerge(ctx context.Context, workspaceID string, target *StoredEntity, sourceIDs []string, targetRelations, sourceRelations []*StoredRelation, updates map[string]interface{}) error {
+	schema, err := s.knowledge.schemas.GetLatest(ctx, workspaceID)
+	if err != nil || schema == nil {
+		return err
+	}
+
+	properties := userProperties(mergeProperties(target.Properties, updates))
+	if err := s.knowledge.checkEntity(ctx, schema, workspaceID, target.Type, properties); err != nil {
+		return invalidMerge(err, "invalid entity: ")
+	}
+
+	kept, moves := planRelations(target.ID, sourceIDs, targetRelations, sourceRelations)
+	relations := append([]*StoredRelation(nil), kept...)
+	for _, move := range moves {
+		if move.action != relationMoved {
+			continue
+		}
+		relation := move.relation
+		if err := s.knowledge.checkRelation(ctx, schema, workspaceID, relation.Type, move.sourceID, move.targetID, userProperties(relation.Properties)); err != nil {
+			return invalidMerge(err, "invalid relation: ")
+		}
+		relations = append(relations, &StoredRelation{Type: relation.Type, SourceID: move.sourceID, TargetID: move.targetID})
+	}
+
+	outgoing, incoming := map[string]int{}, map[string]int{}
+	for _, relation := range relations {
+		if relation.SourceID == target.ID {
+			outgoing[relation.Type]++
+		}
+		if relation.TargetID == target.ID {
+			incoming[relation.Type]++
+		}
+	}
+	for _, definition := range schema.RelationTypes {
+		if definition.limitsSources() && outgoing[definition.Name] > 1 ||
+			definition.limitsTargets() && incoming[definition.Name] > 1 {
+			return fmt.Errorf("invalid merge: the entity would have more than one %s relation and %s allows one", definition.Name, definition.Cardinality)
+		}
+	}
+
+	return nil
+}
+
+// invalidMerge reports the schema problems of err, found by a check whose
+// messages start with prefix, as problems of the merge.
+func invalidMerge(err error, prefix string) error {
+	if problems, ok := strings.CutPrefix(err.Error(), prefix); ok {
+		return fmt.Errorf("invalid merge: %s", problems)
+	}
+	return err
+}
+
+type relationAction int
+
+const (
+	// relationDropped is a relation between merged entities, which would
+	// become a loop on the target.
+	relationDropped relationAction = iota
+	// relationMerged duplicates a relation the target already has.
+	relationMerged
+	// relationMoved is recreated on the target.
+	relationMoved
+)
+
+// relationMove is what a merge does with a relation of a source. sourceID
+// and targetID are its ends after the merge.
+type relationMove struct {
+	relation           *StoredRelation
+	sourceID, targetID string
+	key                string
+	action             relationAction
+}
+
+// planRelations works out how a merge rewires relations. kept are the
+// relations of the target that survive it; those to a source disappear with
+// the source. Every distinct relation of the sources gets a move.
+func planRelations(targetID string, sourceIDs []string, targetRelations, sourceRelations []*StoredRelation) ([]*StoredRelation, []relationMove) {
+	merged := map[string]bool{targetID: true}
+	for _, id := range sourceIDs {
+		merged[id] = true
+	}
+	endpoint := func(id string) string {
+		if merged[id] {
+			return targetID
+		}
+		return id
+	}
+
+	var kept []*StoredRelation
+	existing := map[string]bool{}
+	for _, relation := range targetRelations {
+		if merged[relation.SourceID] && merged[relation.TargetID] && relation.SourceID != relation.TargetID {
+			continue
+		}
+		kept = append(kept, relation)
+		existing[relationKey(relation.Type, relation.SourceID, relation.TargetID)] = true
+	}
+
+	var moves []relationMove
+	seen := map[string]bool{}
+	for _, relation := range sourceRelations {
+		if seen[relation.ID] {
+			continue
+		}
+		seen[relation.ID] = true
+
+		move := relationMove{relation: relation, sourceID: endpoint(relation.SourceID), targetID: endpoint(relation.TargetID)}
+		move.key = relationKey(relation.Type, move.sourceID, move.targetID)
+		switch {
+		case move.sourceID == move.targetID:
+			move.action = relationDropped
+		case existing[move.key]:
+			move.action = relationMerged
+		default:
+			move.action = relationMoved
+			existing[move.key] = true
+		}
+		moves = append(moves, move)
+	}
+
+	return kept, moves
+}
+
+// moveEvidence points the evidence of a fact at another fact and records the
+// move.
+func (s *resolutionService) moveEvidence(ctx context.Context, record *MergeRecord, kind FactKind, factID, sourceEntityID, targetEntityID, toFactID, toSourceEntityID, toTargetEntityID string) error {
+	ids, err := s.knowledge.evidence.IDsByFact(ctx, record.WorkspaceID, kind, factID)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := s.knowledge.evidence.Reassign(ctx, record.WorkspaceID, ids, kind, toFactID, toSourceEntityID, toTargetEntityID); err != nil {
+		return err
+	}
+	record.EvidenceMoves = append(record.EvidenceMoves, EvidenceMove{
+		IDs:            ids,
+		FactKind:       kind,
+		FactID:         factID,
+		SourceEntityID: sourceEntityID,
+		TargetEntityID: targetEntityID,
+	})
+
+	return nil
+}
+
+// undo reverses merge, recording the new IDs of the sources on the record.
+// Sources listed in remaining were never deleted and are left as they are.
+func (s *resolutionService) undo(ctx context.Context, record *MergeRecord, remaining map[string]string) error {
+	workspaceID := record.WorkspaceID
+
+	record.Restored = make(map[string]string, len(record.Sources))
+	for _, source := range record.Sources {
+		if _, ok := remaining[source.ID]; ok {
+			continue
+		}
+		id, err := s.knowledge.repository.RestoreEntity(ctx, workspaceID, source)
+		if err != nil {
+			return err
+		}
+		record.Restored[source.ID] = id
+	}
+	entityID := func(id string) string {
+		if restored, ok := record.Restored[id]; ok {
+			return restored
+		}
+		return id
+	}
+
+	for _, id := range record.CreatedRelations {
+		if _, err := s.knowledge.repository.DeleteRelation(ctx, workspaceID, id); err != nil {
+			return err
+		}
+	}
+
+	// Relations between entities that were never deleted still exist.
+	relations := make(map[string]string, len(record.Relations))
+	for _, relation := range record.Relations {
+		_, sourceRestored := record.Restored[relation.SourceID]
+		_, targetRestored := record.Restored[relation.TargetID]
+		if !sourceRestored && !targetRestored {
+			relations[relation.ID] = relation.ID
+			continue
+		}
+
+		id, err := s.knowledge.repository.RestoreRelation(ctx, workspaceID, &StoredRelation{
+			Type:       relation.Type,
+			SourceID:   entityID(relation.SourceID),
+			TargetID:   entityID(relation.TargetID),
+			Properties: relation.Properties,
+		})
+		if err != nil {
+			return err
+		}
+		if id != "" {
+			relations[relation.ID] = id
+		}
+	}
+
+	for _, move := range record.EvidenceMoves {
+		factID, ok := entityID(move.FactID), true
+		if move.FactKind == FactKindRelation {
+			factID, ok = relations[move.FactID]
+		}
+		if !ok {
+			continue
+		}
+
+		if err := s.knowledge.evidence.Reassign(ctx, workspaceID, move.IDs, move.FactKind, factID,
+			entityID(move.SourceEntityID), entityID(move.TargetEntityID)); err != nil {
+			return err
+		}
+	}
+
+	return s.knowledge.repository.ReplaceEntityProperties(ctx, workspaceID, record.TargetID, record.Target.Properties)
+}
+
+// scanNamedEntities reads the entities of the workspace that have a name, of
+// entityType when it is set, up to MaxResolutionEntities.
+func (s *resolutionService) scanNamedEntities(ctx context.Context, workspaceID, entityType string) ([]*Entity, bool, error) {
+	var (
+		entities []*Entity
+		after    string
+	)
+	for {
+		batch, err := s.knowledge.repository.ScanEntities(ctx, workspaceID, after, resolutionScanBatch)
+		if err != nil {
+			return nil, false, err
+		}
+
+		for _, entity := range batch {
+			if entityType != "" && entity.Type != entityType {
+				continue
+			}
+			if name, _ := entity.Properties["name"].(string); strings.TrimSpace(name) == "" {
+				continue
+			}
+			if len(entities) == MaxResolutionEntities {
+				return entities, true, nil
+			}
+			entities = append(entities, entity)
+		}
+
+		if len(batch) < resolutionScanBatch {
+			return entities, false, nil
+		}
+		after = batch[len(batch)-1].ID
+	}
+}
+
+// scoreCandidate scores a pair by the similarity of their names, weighed
+// with the agreement of the match properties both have.
+func scoreCandidate(left, right *Entity, settings *ResolutionSettings) *MergeCandidate {
+	nameScore, method := nameSimilarity(entityNames(left), entityNames(right))
+
+	candidate := &MergeCandidate{
+		EntityType: left.Type,
+		Left:       left,
+		Right:      right,
+		NameScore:  roundScore(nameScore),
+		Method:     method,
+		Matched:    []string{},
+		Mismatched: []string{},
+	}
+
+	for _, name := range settings.MatchProperties {
+		a, okA := left.Properties[name]
+		b, okB := right.Properties[name]
+		if !okA || !okB || a == nil || b == nil {
+			continue
+		}
+		if propertyText(a) == propertyText(b) {
+			candidate.Matched = append(candidate.Matched, name)
+		} else {
+			candidate.Mismatched = append(candidate.Mismatched, name)
+		}
+	}
+
+	score := nameScore
+	if compared := len(candidate.Matched) + len(candidate.Mismatched); compared > 0 {
+		agreement := float64(len(candidate.Matched)) / float64(compared)
+		score = (1-settings.PropertyWeight)*nameScore + settings.PropertyWeight*agreement
+	}
+	candidate.Score = roundScore(score)
+
+	return candidate
+}
+
+func roundScore(score float64) float64 {
+	return math.Round(score*10000) / 10000
+}
+
+func entityNames(entity *Entity) []string {
+	names := make([]string, 0, len(entity.Aliases)+1)
+	if name, ok := entity.Properties["name"].(string); ok && name != "" {
+		names = append(names, name)
+	}
+	return append(names, entity.Aliases...)
+}
+
+func relationKey(relationType, sourceID, targetID string) string {
+	return relationType + "\x00" + sourceID + "\x00" + targetID
+}
+
+// resolveProperties returns the properties of the target to change: those
+// only sources have, and those the conflict policy resolves for a source. The
+// target keeps its name, the names of the sources becoming its aliases.
+func resolveProperties(target *StoredEntity, sources []*StoredEntity, policy ConflictPolicy, policies map[string]ConflictPolicy) (map[string]interface{}, []PropertyConflict, error) {
+	current := userProperties(target.Properties)
+	updates := map[string]interface{}{}
+	conflicts := []PropertyConflict{}
+
+	for _, source := range sources {
+		properties := userProperties(source.Properties)
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			value := properties[name]
+			existing, ok := current[name]
+			if name == "name" && ok {
+				continue
+			}
+			if !ok || existing == nil {
+				current[name] = value
+				updates[name] = value
+				continue
+			}
+			if propertyText(existing) == propertyText(value) {
+				continue
+			}
+
+			resolution := policy
+			if override, ok := policies[name]; ok {
+				resolution = override
+			}
+
+			result := existing
+			switch resolution {
+			case ConflictReject:
+				return nil, nil, fmt.Errorf("conflicting values for property %q in entity %s", name, source.ID)
+			case ConflictKeepSource:
+				result = value
+			case ConflictUnion:
+				if union := unionValues(existing, value); union != nil {
+					result = union
+				}
+			}
+
+			conflicts = append(conflicts, PropertyConflict{
+				Property:    name,
+				SourceID:    source.ID,
+				TargetValue: existing,
+				SourceValue: value,
+				Policy:      resolution,
+				Result:      result,
+			})
+			if propertyText(result) != propertyText(existing) {
+				current[name] = result
+				updates[name] = result
+			}
+		}
+	}
+
+	return updates, conflicts, nil
+}
+
+// mergeAliases adds the names and aliases of the sources to the aliases of
+// the target, leaving out the name of the target and repeats.
+func mergeAliases(target *StoredEntity, sources []*StoredEntity) []string {
+	seen := map[string]bool{}
+	if name, ok := target.Properties["name"].(string); ok {
+		seen[strings.ToLower(name)] = true
+	}
+
+	aliases := []string{}
+	add := func(names ...string) {
+		for _, name := range names {
+			if name != "" && !seen[strings.ToLower(name)] {
+				seen[strings.ToLower(name)] = true
+				aliases = append(aliases, name)
+			}
+		}
+	}
+
+	add(stringsOf(target.Properties[propertyAliases])...)
+	for _, source := range sources {
+		if name, ok := source.Properties["name"].(string); ok {
+			add(name)
+		}
+		add(stringsOf(source.Properties[propertyAliases])...)
+	}
+
+	return aliases
+}
+
+// unionValues lists the distinct values of a and b, either of which may be a
+// list. It returns nil when they cannot share a list.
+func unionValues(a, b interface{}) interface{} {
+	var items []interface{}
+	for _, value := range []interface{}{a, b} {
+		if list, ok := value.([]interface{}); ok {
+			items = append(items, list...)
+		} else {
+			items = append(items, value)
+		}
+	}
+
+	seen := map[string]bool{}
+	distinct := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		if key := propertyText(item); !seen[key] {
+			seen[key] = true
+			if i, ok := item.(int64); ok {
+				item = float64(i)
+			}
+			distinct = append(distinct, item)
+		}
+	}
+
+	list, err := normalizeList(distinct)
+	if err != nil {
+		return nil
+	}
+	if _, ok := list.([]bool); ok {
+		return nil
+	}
+
+	return list
+}
+
+func validatePropertyName(name string) error {
+	if !propertyPattern.MatchString(name) || reservedProperties[name] {
+		return fmt.Errorf("invalid property name %q", name)
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
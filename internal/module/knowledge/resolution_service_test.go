@@ -0,0 +1,436 @@
+package knowledge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type resolutionMocks struct {
+	repo     *MockKnowledgeRepository
+	settings *MockResolutionSettingsRepository
+	merges   *MockMergeRepository
+	evidence *MockEvidenceRepository
+}
+
+// setupResolutionService returns a service for a workspace with the default
+// resolution settings.
+func setupResolutionService() (*resolutionService, *resolutionMocks) {
+	knowledge, mockRepo := setupKnowledgeService()
+	mocks := &resolutionMocks{
+		repo:     mockRepo,
+		settings: &MockResolutionSettingsRepository{},
+		merges:   &MockMergeRepository{},
+		evidence: knowledge.evidence.(*MockEvidenceRepository),
+	}
+	mocks.settings.On("Get", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+
+	return newResolutionService(mocks.settings, mocks.merges, knowledge), mocks
+}
+
+func namedEntity(id, entityType, name string, properties map[string]interface{}) *Entity {
+	return CreateTestEntity(func(e *Entity) {
+		e.ID = id
+		e.Type = entityType
+		e.Properties = map[string]interface{}{"name": name}
+		for key, value := range properties {
+			e.Properties[key] = value
+		}
+	})
+}
+
+func TestResolutionService_FindCandidates(t *testing.T) {
+	entities := []*Entity{
+		namedEntity("4:test:1", "Organization", "The Acme Corporation", map[string]interface{}{"country": "UK"}),
+		namedEntity("4:test:2", "Organization", "ACME", map[string]interface{}{"country": "uk"}),
+		namedEntity("4:test:3", "Organization", "IBM", map[string]interface{}{"country": "US"}),
+		namedEntity("4:test:4", "Organization", "International Business Machines", map[string]interface{}{"country": "GB"}),
+		namedEntity("4:test:5", "Person", "Acme", nil),
+		CreateTestEntity(func(e *Entity) { e.ID = "4:test:6"; e.Type = "Organization"; e.Properties = map[string]interface{}{} }),
+	}
+
+	t.Run("by name", func(t *testing.T) {
+		service, mocks := setupResolutionService()
+		mocks.repo.On("ScanEntities", mock.Anything, testWorkspaceID, "", resolutionScanBatch).Return(entities, nil)
+
+		result, err := service.FindCandidates(context.Background(), testWorkspaceID, &MergeCandidateQuery{})
+		require.NoError(t, err)
+
+		assert.Equal(t, 5, result.Compared)
+		assert.False(t, result.Truncated)
+		require.Len(t, result.Candidates, 2)
+		assert.Equal(t, "4:test:1", result.Candidates[0].Left.ID)
+		assert.Equal(t, "4:test:2", result.Candidates[0].Right.ID)
+		assert.Equal(t, 1.0, result.Candidates[0].Score)
+		assert.Equal(t, "4:test:3", result.Candidates[1].Left.ID)
+		assert.Equal(t, MatchAcronym, result.Candidates[1].Method)
+		assert.Equal(t, acronymSimilarity, result.Candidates[1].Score)
+	})
+
+	t.Run("with properties", func(t *testing.T) {
+		knowledge, mockRepo := setupKnowledgeService()
+		mockSettings := &MockResolutionSettingsRepository{}
+		mockSettings.On("Get", mock.Anything, testWorkspaceID).Return(&ResolutionSettings{
+			Threshold:       0.85,
+			PropertyWeight:  0.5,
+			MatchProperties: []string{"country"},
+		}, nil)
+		mockRepo.On("ScanEntities", mock.Anything, testWorkspaceID, "", resolutionScanBatch).Return(entities, nil)
+		service := newResolutionService(mockSettings, &MockMergeRepository{}, knowledge)
+
+		result, err := service.FindCandidates(context.Background(), testWorkspaceID, &MergeCandidateQuery{Type: "Organization"})
+		require.NoError(t, err)
+
+		assert.Equal(t, 4, result.Compared)
+		require.Len(t, result.Candidates, 1)
+		assert.Equal(t, []string{"country"}, result.Candidates[0].Matched)
+		assert.Empty(t, result.Candidates[0].Mismatched)
+
+		result, err = service.FindCandidates(context.Background(), testWorkspaceID, &MergeCandidateQuery{Type: "Organization", Threshold: 0.4})
+		require.NoError(t, err)
+		require.Len(t, result.Candidates, 2)
+		assert.Equal(t, []string{"country"}, result.Candidates[1].Mismatched)
+		assert.InDelta(t, 0.45, result.Candidates[1].Score, 1e-9)
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		service, _ := setupResolutionService()
+
+		_, err := service.FindCandidates(context.Background(), testWorkspaceID, &MergeCandidateQuery{Type: "no spaces"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid")
+	})
+}
+
+// mergeSnapshots sets up Acme (4:test:1) and ACME Inc (4:test:2). Ada
+// (4:test:5) works at both, ACME Inc is located in London (4:test:6) and Acme
+// owns ACME Inc.
+func mergeSnapshots(mocks *resolutionMocks) {
+	works := &StoredRelation{ID: "5:test:1", Type: "WORKS_AT", SourceID: "4:test:5", TargetID: "4:test:1", Properties: map[string]interface{}{}}
+	owns := &StoredRelation{ID: "5:test:4", Type: "OWNS", SourceID: "4:test:1", TargetID: "4:test:2", Properties: map[string]interface{}{}}
+
+	mocks.repo.On("SnapshotEntity", mock.Anything, testWorkspaceID, "4:test:1").Return(&StoredEntity{
+		ID:         "4:test:1",
+		Type:       "Organization",
+		Properties: map[string]interface{}{"name": "Acme", "country": "UK", propertyCreatedBy: testAccountID},
+	}, []*StoredRelation{works, owns}, nil)
+
+	mocks.repo.On("SnapshotEntity", mock.Anything, testWorkspaceID, "4:test:2").Return(&StoredEntity{
+		ID:         "4:test:2",
+		Type:       "Organization",
+		Properties: map[string]interface{}{"name": "ACME Inc", "country": "GB", "founded": int64(1990), propertyAliases: []interface{}{"Acme"}},
+	}, []*StoredRelation{
+		{ID: "5:test:2", Type: "WORKS_AT", SourceID: "4:test:5", TargetID: "4:test:2", Properties: map[string]interface{}{}},
+		{ID: "5:test:3", Type: "LOCATED_IN", SourceID: "4:test:2", TargetID: "4:test:6", Properties: map[string]interface{}{"since": int64(2001)}},
+		owns,
+	}, nil)
+}
+
+func TestResolutionService_MergeEntities(t *testing.T) {
+	service, mocks := setupResolutionService()
+	mergeSnapshots(mocks)
+
+	worksEvidence := []primitive.ObjectID{primitive.NewObjectID()}
+	ownsEvidence := []primitive.ObjectID{primitive.NewObjectID()}
+	entityEvidence := []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID()}
+
+	mocks.merges.On("Create", mock.Anything, mock.MatchedBy(func(r *MergeRecord) bool {
+		return r.Status == MergeStatusPending && r.TargetID == "4:test:1"
+	})).Return(&MergeRecord{}, nil)
+
+	mocks.repo.On("RestoreRelation", mock.Anything, testWorkspaceID, mock.MatchedBy(func(r *StoredRelation) bool {
+		return r.Type == "LOCATED_IN" && r.SourceID == "4:test:1" && r.TargetID == "4:test:6" && r.Properties["since"] == int64(2001)
+	})).Return("5:test:9", nil)
+
+	mocks.evidence.On("IDsByFact", mock.Anything, testWorkspaceID, FactKindRelation, "5:test:2").Return(worksEvidence, nil)
+	mocks.evidence.On("IDsByFact", mock.Anything, testWorkspaceID, FactKindRelation, "5:test:3").Return([]primitive.ObjectID{}, nil)
+	mocks.evidence.On("IDsByFact", mock.Anything, testWorkspaceID, FactKindRelation, "5:test:4").Return(ownsEvidence, nil)
+	mocks.evidence.On("IDsByFact", mock.Anything, testWorkspaceID, FactKindEntity, "4:test:2").Return(entityEvidence, nil)
+	mocks.evidence.On("Reassign", mock.Anything, testWorkspaceID, worksEvidence, FactKindRelation, "5:test:1", "4:test:5", "4:test:1").Return(nil)
+	mocks.evidence.On("Reassign", mock.Anything, testWorkspaceID, ownsEvidence, FactKindRelation, "5:test:4", "4:test:1", "4:test:1").Return(nil)
+	mocks.evidence.On("Reassign", mock.Anything, testWorkspaceID, entityEvidence, FactKindEntity, "4:test:1", "", "").Return(nil)
+
+	mocks.repo.On("UpdateEntity", mock.Anything, testWorkspaceID, "4:test:1", map[string]interface{}{
+		"country":       []string{"UK", "GB"},
+		"founded":       int64(1990),
+		propertyAliases: []string{"ACME Inc"},
+	}).Return(CreateTestEntity(), nil)
+	mocks.repo.On("DeleteEntity", mock.Anything, testWorkspaceID, "4:test:2").Return(true, nil)
+
+	mocks.merges.On("Save", mock.Anything, mock.MatchedBy(func(r *MergeRecord) bool {
+		return r.Status == MergeStatusCompleted
+	})).Return(&MergeRecord{}, nil)
+
+	merge, err := service.MergeEntities(context.Background(), testWorkspaceID, testAccountID, &MergeEntitiesRequest{
+		TargetID:         "4:test:1",
+		SourceIDs:        []string{"4:test:2", "4:test:2"},
+		PropertyPolicies: map[string]ConflictPolicy{"country": ConflictUnion},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, MergeStatusCompleted, merge.Status)
+	assert.Equal(t, []string{"4:test:2"}, merge.SourceIDs)
+	assert.Equal(t, []string{"ACME Inc"}, merge.Aliases)
+	assert.Equal(t, 1, merge.RelationsMoved)
+	assert.Equal(t, 1, merge.RelationsMerged)
+	assert.Equal(t, 1, merge.RelationsDropped)
+	assert.Equal(t, []string{"5:test:9"}, merge.CreatedRelations)
+	assert.Len(t, merge.Relations, 3)
+	assert.Len(t, merge.EvidenceMoves, 3)
+	assert.Equal(t, []PropertyConflict{{
+		Property:    "country",
+		SourceID:    "4:test:2",
+		TargetValue: "UK",
+		SourceValue: "GB",
+		Policy:      ConflictUnion,
+		Result:      []string{"UK", "GB"},
+	}}, merge.Conflicts)
+	mocks.repo.AssertExpectations(t)
+	mocks.evidence.AssertExpectations(t)
+	mocks.merges.AssertExpectations(t)
+}
+
+func TestResolutionService_MergeEntities_Refused(t *testing.T) {
+	t.Run("conflicting property", func(t *testing.T) {
+		service, mocks := setupResolutionService()
+		mergeSnapshots(mocks)
+
+		_, err := service.MergeEntities(context.Background(), testWorkspaceID, testAccountID, &MergeEntitiesRequest{
+			TargetID:       "4:test:1",
+			SourceIDs:      []string{"4:test:2"},
+			ConflictPolicy: ConflictReject,
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "conflict")
+		mocks.merges.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("union breaks the schema", func(t *testing.T) {
+		service, mocks := setupResolutionService()
+		mergeSnapshots(mocks)
+		schemas := &MockSchemaRepository{}
+		schemas.On("GetLatest", mock.Anything, testWorkspaceID).Return(CreateTestSchema(func(schema *Schema) {
+			schema.EntityTypes[1].Properties = append(schema.EntityTypes[1].Properties,
+				PropertyDefinition{Name: "country", Type: PropertyTypeString},
+				PropertyDefinition{Name: "founded", Type: PropertyTypeNumber},
+			)
+		}), nil)
+		service.knowledge.schemas = schemas
+
+		_, err := service.MergeEntities(context.Background(), testWorkspaceID, testAccountID, &MergeEntitiesRequest{
+			TargetID:       "4:test:1",
+			SourceIDs:      []string{"4:test:2"},
+			ConflictPolicy: ConflictUnion,
+		})
+		assert.EqualError(t, err, `invalid merge: property "country" must be a string`)
+		mocks.merges.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		mocks.repo.AssertNotCalled(t, "UpdateEntity", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("relations break the cardinality", func(t *testing.T) {
+		service, mocks := setupResolutionService()
+		schemas := &MockSchemaRepository{}
+		schemas.On("GetLatest", mock.Anything, testWorkspaceID).Return(CreateTestSchema(), nil)
+		service.knowledge.schemas = schemas
+
+		mocks.repo.On("SnapshotEntity", mock.Anything, testWorkspaceID, "4:test:1").Return(&StoredEntity{
+			ID: "4:test:1", Type: "Person", Properties: map[string]interface{}{"name": "Ada Lovelace"},
+		}, []*StoredRelation{
+			{ID: "5:test:1", Type: "WORKS_AT", SourceID: "4:test:1", TargetID: "4:test:5", Properties: map[string]interface{}{}},
+		}, nil)
+		mocks.repo.On("SnapshotEntity", mock.Anything, testWorkspaceID, "4:test:2").Return(&StoredEntity{
+			ID: "4:test:2", Type: "Person", Properties: map[string]interface{}{"name": "Ada King"},
+		}, []*StoredRelation{
+			{ID: "5:test:2", Type: "WORKS_AT", SourceID: "4:test:2", TargetID: "4:test:6", Properties: map[string]interface{}{}},
+		}, nil)
+		mocks.repo.On("EntityTypes", mock.Anything, testWorkspaceID, []string{"4:test:1", "4:test:6"}).
+			Return(map[string]string{"4:test:1": "Person", "4:test:6": "Organization"}, nil)
+
+		_, err := service.MergeEntities(context.Background(), testWorkspaceID, testAccountID, &MergeEntitiesRequest{
+			TargetID:  "4:test:1",
+			SourceIDs: []string{"4:test:2"},
+		})
+		assert.EqualError(t, err, "invalid merge: the entity would have more than one WORKS_AT relation and many_to_one allows one")
+		mocks.merges.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		mocks.repo.AssertNotCalled(t, "RestoreRelation", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("into itself", func(t *testing.T) {
+		service, mocks := setupResolutionService()
+		mergeSnapshots(mocks)
+
+		_, err := service.MergeEntities(context.Background(), testWorkspaceID, testAccountID, &MergeEntitiesRequest{
+			TargetID:  "4:test:1",
+			SourceIDs: []string{"4:test:2", "4:test:1"},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid")
+	})
+
+	t.Run("different types", func(t *testing.T) {
+		service, mocks := setupResolutionService()
+		mergeSnapshots(mocks)
+		mocks.repo.On("SnapshotEntity", mock.Anything, testWorkspaceID, "4:test:5").
+			Return(&StoredEntity{ID: "4:test:5", Type: "Person"}, []*StoredRelation{}, nil)
+
+		_, err := service.MergeEntities(context.Background(), testWorkspaceID, testAccountID, &MergeEntitiesRequest{
+			TargetID:  "4:test:1",
+			SourceIDs: []string{"4:test:5"},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid")
+	})
+}
+
+func TestResolutionService_UndoMerge(t *testing.T) {
+	service, mocks := setupResolutionService()
+
+	id := primitive.NewObjectID()
+	worksEvidence := []primitive.ObjectID{primitive.NewObjectID()}
+	locatedEvidence := []primitive.ObjectID{primitive.NewObjectID()}
+	entityEvidence := []primitive.ObjectID{primitive.NewObjectID()}
+	target := &StoredEntity{ID: "4:test:1", Type: "Organization", Properties: map[string]interface{}{"name": "Acme"}}
+	source := &StoredEntity{ID: "4:test:2", Type: "Organization", Properties: map[string]interface{}{"name": "ACME Inc"}}
+
+	merge := &MergeRecord{
+		ID:          id,
+		WorkspaceID: testWorkspaceID,
+		TargetID:    "4:test:1",
+		SourceIDs:   []string{"4:test:2"},
+		Status:      MergeStatusCompleted,
+		Target:      target,
+		Sources:     []*StoredEntity{source},
+		Relations: []*StoredRelation{
+			{ID: "5:test:2", Type: "WORKS_AT", SourceID: "4:test:5", TargetID: "4:test:2"},
+			{ID: "5:test:3", Type: "LOCATED_IN", SourceID: "4:test:2", TargetID: "4:test:6"},
+		},
+		CreatedRelations: []string{"5:test:9"},
+		EvidenceMoves: []EvidenceMove{
+			{IDs: worksEvidence, FactKind: FactKindRelation, FactID: "5:test:2", SourceEntityID: "4:test:5", TargetEntityID: "4:test:2"},
+			{IDs: locatedEvidence, FactKind: FactKindRelation, FactID: "5:test:3", SourceEntityID: "4:test:2", TargetEntityID: "4:test:6"},
+			{IDs: entityEvidence, FactKind: FactKindEntity, FactID: "4:test:2"},
+		},
+	}
+
+	mocks.merges.On("GetByID", mock.Anything, testWorkspaceID, id).Return(merge, nil)
+	mocks.repo.On("GetEntity", mock.Anything, testWorkspaceID, "4:test:1").Return(CreateTestEntity(), nil)
+	mocks.merges.On("MarkUndone", mock.Anything, merge).Return(merge, nil)
+
+	mocks.repo.On("RestoreEntity", mock.Anything, testWorkspaceID, source).Return("4:test:20", nil)
+	mocks.repo.On("DeleteRelation", mock.Anything, testWorkspaceID, "5:test:9").Return(true, nil)
+	mocks.repo.On("RestoreRelation", mock.Anything, testWorkspaceID, &StoredRelation{Type: "WORKS_AT", SourceID: "4:test:5", TargetID: "4:test:20"}).
+		Return("5:test:21", nil)
+	// London was deleted after the merge.
+	mocks.repo.On("RestoreRelation", mock.Anything, testWorkspaceID, &StoredRelation{Type: "LOCATED_IN", SourceID: "4:test:20", TargetID: "4:test:6"}).
+		Return("", nil)
+
+	mocks.evidence.On("Reassign", mock.Anything, testWorkspaceID, worksEvidence, FactKindRelation, "5:test:21", "4:test:5", "4:test:20").Return(nil)
+	mocks.evidence.On("Reassign", mock.Anything, testWorkspaceID, entityEvidence, FactKindEntity, "4:test:20", "", "").Return(nil)
+	mocks.repo.On("ReplaceEntityProperties", mock.Anything, testWorkspaceID, "4:test:1", target.Properties).Return(nil)
+	mocks.merges.On("Save", mock.Anything, merge).Return(merge, nil)
+
+	undone, err := service.UndoMerge(context.Background(), testWorkspaceID, testAccountID, id.Hex())
+	require.NoError(t, err)
+
+	assert.Equal(t, MergeStatusUndone, undone.Status)
+	assert.Equal(t, map[string]string{"4:test:2": "4:test:20"}, undone.Restored)
+	assert.Equal(t, testAccountID, undone.UndoneBy)
+	assert.NotNil(t, undone.UndoneAt)
+	mocks.repo.AssertExpectations(t)
+	mocks.evidence.AssertExpectations(t)
+	mocks.evidence.AssertNotCalled(t, "Reassign", mock.Anything, testWorkspaceID, locatedEvidence,
+		mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestResolutionService_UndoMerge_Failed(t *testing.T) {
+	service, mocks := setupResolutionService()
+
+	id := primitive.NewObjectID()
+	worksEvidence := []primitive.ObjectID{primitive.NewObjectID()}
+	locatedEvidence := []primitive.ObjectID{primitive.NewObjectID()}
+	target := &StoredEntity{ID: "4:test:1", Type: "Organization", Properties: map[string]interface{}{"name": "Acme"}}
+	deleted := &StoredEntity{ID: "4:test:2", Type: "Organization", Properties: map[string]interface{}{"name": "ACME Inc"}}
+	remaining := &StoredEntity{ID: "4:test:3", Type: "Organization", Properties: map[string]interface{}{"name": "Acme Ltd"}}
+
+	// The merge failed after deleting the first source but not the second.
+	merge := &MergeRecord{
+		ID:          id,
+		WorkspaceID: testWorkspaceID,
+		TargetID:    "4:test:1",
+		SourceIDs:   []string{"4:test:2", "4:test:3"},
+		Status:      MergeStatusFailed,
+		Error:       "graph database unavailable",
+		Target:      target,
+		Sources:     []*StoredEntity{deleted, remaining},
+		Relations: []*StoredRelation{
+			{ID: "5:test:2", Type: "WORKS_AT", SourceID: "4:test:5", TargetID: "4:test:2"},
+			{ID: "5:test:3", Type: "LOCATED_IN", SourceID: "4:test:3", TargetID: "4:test:6"},
+		},
+		CreatedRelations: []string{"5:test:8", "5:test:9"},
+		EvidenceMoves: []EvidenceMove{
+			{IDs: worksEvidence, FactKind: FactKindRelation, FactID: "5:test:2", SourceEntityID: "4:test:5", TargetEntityID: "4:test:2"},
+			{IDs: locatedEvidence, FactKind: FactKindRelation, FactID: "5:test:3", SourceEntityID: "4:test:3", TargetEntityID: "4:test:6"},
+		},
+	}
+
+	mocks.merges.On("GetByID", mock.Anything, testWorkspaceID, id).Return(merge, nil)
+	mocks.repo.On("GetEntity", mock.Anything, testWorkspaceID, "4:test:1").Return(CreateTestEntity(), nil)
+	mocks.repo.On("EntityTypes", mock.Anything, testWorkspaceID, []string{"4:test:2", "4:test:3"}).
+		Return(map[string]string{"4:test:3": "Organization"}, nil)
+	mocks.merges.On("MarkUndone", mock.Anything, merge).Return(merge, nil)
+
+	mocks.repo.On("RestoreEntity", mock.Anything, testWorkspaceID, deleted).Return("4:test:20", nil)
+	mocks.repo.On("DeleteRelation", mock.Anything, testWorkspaceID, "5:test:8").Return(true, nil)
+	mocks.repo.On("DeleteRelation", mock.Anything, testWorkspaceID, "5:test:9").Return(true, nil)
+	mocks.repo.On("RestoreRelation", mock.Anything, testWorkspaceID, &StoredRelation{Type: "WORKS_AT", SourceID: "4:test:5", TargetID: "4:test:20"}).
+		Return("5:test:21", nil)
+
+	mocks.evidence.On("Reassign", mock.Anything, testWorkspaceID, worksEvidence, FactKindRelation, "5:test:21", "4:test:5", "4:test:20").Return(nil)
+	mocks.evidence.On("Reassign", mock.Anything, testWorkspaceID, locatedEvidence, FactKindRelation, "5:test:3", "4:test:3", "4:test:6").Return(nil)
+	mocks.repo.On("ReplaceEntityProperties", mock.Anything, testWorkspaceID, "4:test:1", target.Properties).Return(nil)
+	mocks.merges.On("Save", mock.Anything, merge).Return(merge, nil)
+
+	undone, err := service.UndoMerge(context.Background(), testWorkspaceID, testAccountID, id.Hex())
+	require.NoError(t, err)
+
+	assert.Equal(t, MergeStatusUndone, undone.Status)
+	assert.Empty(t, undone.Error)
+	assert.Equal(t, map[string]string{"4:test:2": "4:test:20"}, undone.Restored)
+	mocks.repo.AssertExpectations(t)
+	mocks.evidence.AssertExpectations(t)
+	mocks.repo.AssertNotCalled(t, "RestoreEntity", mock.Anything, testWorkspaceID, remaining)
+}
+
+func TestResolutionService_UndoMerge_NotCompleted(t *testing.T) {
+	undoneAt := time.Now()
+
+	tests := []struct {
+		name   string
+		record MergeRecord
+		err    string
+	}{
+		{"undone", MergeRecord{Status: MergeStatusUndone}, "already"},
+		{"pending", MergeRecord{Status: MergeStatusPending}, "invalid"},
+		{"failed undo", MergeRecord{Status: MergeStatusFailed, UndoneAt: &undoneAt}, "invalid"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service, mocks := setupResolutionService()
+			id := primitive.NewObjectID()
+			record := test.record
+			record.ID, record.WorkspaceID = id, testWorkspaceID
+			mocks.merges.On("GetByID", mock.Anything, testWorkspaceID, id).Return(&record, nil)
+
+			_, err := service.UndoMerge(context.Background(), testWorkspaceID, testAccountID, id.Hex())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.err)
+			mocks.merges.AssertNotCalled(t, "MarkUndone", mock.Anything, mock.Anything)
+		})
+	}
+}
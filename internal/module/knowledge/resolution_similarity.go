@@ -0,0 +1,306 @@
+package knowledge
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// nameStopwords carry no identity: leaving them out of tokens lets "The Acme
+// Corporation" match "Acme" and abbreviate to the same acronym.
+var nameStopwords = map[string]bool{
+	"the": true, "of": true, "and": true, "for": true, "a": true, "an": true,
+	"inc": true, "incorporated": true, "corp": true, "corporation": true,
+	"co": true, "company": true, "ltd": true, "limited": true, "llc": true,
+	"plc": true, "gmbh": true, "ag": true, "sa": true,
+}
+
+// normalizeName lowercases a name and turns every run of characters other
+// than letters and digits into one space.
+func normalizeName(name string) string {
+	var builder strings.Builder
+	space := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && builder.Len() > 0 {
+				builder.WriteByte(' ')
+			}
+			builder.WriteRune(r)
+			space = false
+		} else {
+			space = true
+		}
+	}
+
+	return builder.String()
+}
+
+// nameTokens returns the words of a normalized name without stopwords, or
+// every word when the name has nothing else.
+func nameTokens(normalized string) []string {
+	words := strings.Fields(normalized)
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		if !nameStopwords[word] {
+			tokens = append(tokens, word)
+		}
+	}
+	if len(tokens) == 0 {
+		return words
+	}
+
+	return tokens
+}
+
+// acronymOf returns the initials of a name of several words.
+func acronymOf(tokens []string) string {
+	if len(tokens) < 2 {
+		return ""
+	}
+
+	var builder strings.Builder
+	for _, token := range tokens {
+		builder.WriteRune([]rune(token)[0])
+	}
+	return builder.String()
+}
+
+// compactOf returns a name that may be an acronym: one word, or single
+// letters such as "I B M".
+func compactOf(tokens []string) string {
+	if len(tokens) == 1 {
+		return tokens[0]
+	}
+	for _, token := range tokens {
+		if len([]rune(token)) != 1 {
+			return ""
+		}
+	}
+	return strings.Join(tokens, "")
+}
+
+// blockingKeys returns the keys under which an entity is compared with
+// others: the first four letters of each word of its names, and the acronym
+// each name has or may be. Entities sharing no key are never compared.
+func blockingKeys(names []string) []string {
+	seen := map[string]bool{}
+	var keys []string
+	add := func(key string) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	for _, name := range names {
+		tokens := nameTokens(normalizeName(name))
+		for _, token := range tokens {
+			runes := []rune(token)
+			if len(runes) > 4 {
+				runes = runes[:4]
+			}
+			add("word:" + string(runes))
+		}
+		if acronym := acronymOf(tokens); acronym != "" {
+			add("acronym:" + acronym)
+		}
+		if compact := compactOf(tokens); len([]rune(compact)) >= 2 && len([]rune(compact)) <= 6 {
+			add("acronym:" + compact)
+		}
+	}
+
+	return keys
+}
+
+// nameSimilarity returns the best similarity between any name of one entity
+// and any name of the other, and the method that found it.
+func nameSimilarity(left, right []string) (float64, string) {
+	best, method := 0.0, ""
+	for _, a := range left {
+		for _, b := range right {
+			score, found := namePairSimilarity(a, b)
+			if score > best {
+				best, method = score, found
+			}
+		}
+	}
+
+	return best, method
+}
+
+func namePairSimilarity(a, b string) (float64, string) {
+	normalizedA, normalizedB := normalizeName(a), normalizeName(b)
+	if normalizedA == "" || normalizedB == "" {
+		return 0, ""
+	}
+	tokensA, tokensB := nameTokens(normalizedA), nameTokens(normalizedB)
+
+	best, method := jaroWinkler(strings.Join(tokensA, " "), strings.Join(tokensB, " ")), MatchJaroWinkler
+	if score := tokenSetRatio(tokensA, tokensB); score > best {
+		best, method = score, MatchTokenSet
+	}
+	if isAcronym(tokensA, tokensB) || isAcronym(tokensB, tokensA) {
+		if acronymSimilarity > best {
+			best, method = acronymSimilarity, MatchAcronym
+		}
+	}
+
+	return best, method
+}
+
+// isAcronym reports whether short abbreviates long.
+func isAcronym(short, long []string) bool {
+	compact := compactOf(short)
+	return len([]rune(compact)) >= 2 && compact == acronymOf(long)
+}
+
+// jaroWinkler returns the Jaro similarity of two strings, raised for a
+// common prefix of up to four characters.
+func jaroWinkler(a, b string) float64 {
+	s, t := []rune(a), []rune(b)
+	if len(s) == 0 && len(t) == 0 {
+		return 1
+	}
+	if len(s) == 0 || len(t) == 0 {
+		return 0
+	}
+
+	window := max(len(s), len(t))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	matchedS := make([]bool, len(s))
+	matchedT := make([]bool, len(t))
+	matches := 0
+	for i := range s {
+		low, high := max(0, i-window), min(len(t), i+window+1)
+		for j := low; j < high; j++ {
+			if !matchedT[j] && s[i] == t[j] {
+				matchedS[i], matchedT[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions, j := 0, 0
+	for i := range s {
+		if !matchedS[i] {
+			continue
+		}
+		for !matchedT[j] {
+			j++
+		}
+		if s[i] != t[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(s)) + m/float64(len(t)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(s), len(t)) && s[prefix] == t[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+// tokenSetRatio compares names as sets of words, so that word order and
+// words only one name has weigh little: the words both names share are
+// compared with each name in full, and the best similarity wins.
+func tokenSetRatio(a, b []string) float64 {
+	setA, setB := tokenSet(a), tokenSet(b)
+	if len(setA) == 0 || len(setB) == 0 {
+		return 0
+	}
+
+	var shared, onlyA, onlyB []string
+	for token := range setA {
+		if setB[token] {
+			shared = append(shared, token)
+		} else {
+			onlyA = append(onlyA, token)
+		}
+	}
+	for token := range setB {
+		if !setA[token] {
+			onlyB = append(onlyB, token)
+		}
+	}
+	sort.Strings(shared)
+	sort.Strings(onlyA)
+	sort.Strings(onlyB)
+
+	common := strings.Join(shared, " ")
+	fullA := strings.TrimSpace(common + " " + strings.Join(onlyA, " "))
+	fullB := strings.TrimSpace(common + " " + strings.Join(onlyB, " "))
+
+	best := levenshteinRatio(fullA, fullB)
+	if common != "" {
+		best = max(best, levenshteinRatio(common, fullA), levenshteinRatio(common, fullB))
+	}
+
+	return best
+}
+
+func tokenSet(tokens []string) map[string]bool {
+	set := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		set[token] = true
+	}
+	return set
+}
+
+// levenshteinRatio is one minus the edit distance of two strings over the
+// length of the longer one.
+func levenshteinRatio(a, b string) float64 {
+	s, t := []rune(a), []rune(b)
+	if len(s) == 0 && len(t) == 0 {
+		return 1
+	}
+
+	previous := make([]int, len(t)+1)
+	current := make([]int, len(t)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		current[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return 1 - float64(previous[len(t)])/float64(max(len(s), len(t)))
+}
+
+// propertyText renders a property value for comparison, ignoring case and
+// the order of list items.
+func propertyText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(strings.ToLower(v))
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = propertyText(item)
+		}
+		sort.Strings(items)
+		return strings.Join(items, "\x00")
+	default:
+		return strings.ToLower(fmt.Sprint(v))
+	}
+}
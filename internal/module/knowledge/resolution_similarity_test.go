@@ -0,0 +1,64 @@
+package knowledge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJaroWinkler(t *testing.T) {
+	assert.InDelta(t, 0.9611, jaroWinkler("martha", "marhta"), 1e-4)
+	assert.InDelta(t, 0.84, jaroWinkler("dwayne", "duane"), 1e-4)
+	assert.InDelta(t, 0.8133, jaroWinkler("dixon", "dicksonx"), 1e-4)
+	assert.Equal(t, 1.0, jaroWinkler("acme", "acme"))
+	assert.Equal(t, 0.0, jaroWinkler("abc", "xyz"))
+	assert.Equal(t, 0.0, jaroWinkler("", "acme"))
+}
+
+func TestTokenSetRatio(t *testing.T) {
+	assert.Equal(t, 1.0, tokenSetRatio([]string{"lovelace", "ada"}, []string{"ada", "lovelace"}))
+	assert.Equal(t, 1.0, tokenSetRatio([]string{"ada", "lovelace"}, []string{"ada", "king", "lovelace"}))
+	assert.Less(t, tokenSetRatio([]string{"ada", "lovelace"}, []string{"charles", "babbage"}), 0.5)
+}
+
+func TestNameSimilarity(t *testing.T) {
+	tests := []struct {
+		name   string
+		left   []string
+		right  []string
+		score  float64
+		method string
+	}{
+		{"word order", []string{"Lovelace, Ada"}, []string{"Ada Lovelace"}, 1, MatchTokenSet},
+		{"corporate suffix", []string{"The Acme Corporation"}, []string{"ACME"}, 1, MatchJaroWinkler},
+		{"acronym", []string{"IBM"}, []string{"International Business Machines"}, acronymSimilarity, MatchAcronym},
+		{"spelled acronym", []string{"I.B.M."}, []string{"International Business Machines Corp."}, acronymSimilarity, MatchAcronym},
+		{"alias", []string{"Ada King", "Ada Lovelace"}, []string{"Ada Lovelace"}, 1, MatchJaroWinkler},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			score, method := nameSimilarity(test.left, test.right)
+			assert.InDelta(t, test.score, score, 1e-9)
+			assert.Equal(t, test.method, method)
+		})
+	}
+
+	score, _ := nameSimilarity([]string{"Ada Lovelace"}, []string{"Charles Babbage"})
+	assert.Less(t, score, 0.7)
+}
+
+func TestBlockingKeys(t *testing.T) {
+	assert.Equal(t, []string{"word:inte", "word:busi", "word:mach", "acronym:ibm"},
+		blockingKeys([]string{"International Business Machines Corp."}))
+	assert.Equal(t, []string{"word:ibm", "acronym:ibm"}, blockingKeys([]string{"IBM"}))
+	assert.Equal(t, []string{"word:ada", "word:love", "acronym:al", "word:king", "acronym:ak"},
+		blockingKeys([]string{"Ada Lovelace", "Ada King"}))
+}
+
+func TestPropertyText(t *testing.T) {
+	assert.Equal(t, propertyText(" London "), propertyText("london"))
+	assert.Equal(t, propertyText([]interface{}{"b", "A"}), propertyText([]interface{}{"a", "B"}))
+	assert.Equal(t, propertyText(int64(1815)), propertyText(int64(1815)))
+	assert.NotEqual(t, propertyText(int64(1815)), propertyText(int64(1816)))
+}
@@ -0,0 +1,185 @@
+package knowledge
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/binding"
+)
+
+type SchemaHandler struct {
+	service SchemaService
+}
+
+func NewSchemaHandler(service SchemaService) *SchemaHandler {
+	return &SchemaHandler{
+		service: service,
+	}
+}
+
+// GetSchema godoc
+// @Summary Get the workspace schema
+// @Description Get the schema version currently enforced on knowledge writes. Workspaces without a schema accept any entity and relation type.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Success 200 {object} map[string]interface{} "Schema retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Schema not found"
+// @Router /workspaces/{workspaceId}/knowledge/schema [get]
+func (h *SchemaHandler) GetSchema(c *fiber.Ctx) error {
+	schema, err := h.service.GetSchema(c.Context(), workspaceIDFrom(c))
+	if err != nil {
+		return knowledgeError(c, "Failed to get schema", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Schema retrieved successfully",
+		"data":    schema,
+	})
+}
+
+// UpdateSchema godoc
+// @Summary Publish a new schema version
+// @Description Store a new schema version and enforce it on later writes. The response lists the existing entities and relations that violate it. Requires the admin role.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param request body UpdateSchemaRequest true "Entity and relation type definitions"
+// @Success 201 {object} map[string]interface{} "Schema updated successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Router /workspaces/{workspaceId}/knowledge/schema [put]
+func (h *SchemaHandler) UpdateSchema(c *fiber.Ctx) error {
+	var req UpdateSchemaRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	response, err := h.service.UpdateSchema(c.Context(), workspaceIDFrom(c), accountIDFrom(c), &req)
+	if err != nil {
+		return knowledgeError(c, "Failed to update schema", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Schema updated successfully",
+		"data":    response,
+	})
+}
+
+// PreviewSchema godoc
+// @Summary Preview a schema change
+// @Description Report the existing entities and relations that would violate a schema without storing it. Requires the admin role.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param request body UpdateSchemaRequest true "Entity and relation type definitions"
+// @Success 200 {object} map[string]interface{} "Migration report generated successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Router /workspaces/{workspaceId}/knowledge/schema/preview [post]
+func (h *SchemaHandler) PreviewSchema(c *fiber.Ctx) error {
+	var req UpdateSchemaRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	report, err := h.service.PreviewSchema(c.Context(), workspaceIDFrom(c), &req)
+	if err != nil {
+		return knowledgeError(c, "Failed to preview schema", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Migration report generated successfully",
+		"data":    report,
+	})
+}
+
+// GetMigrationReport godoc
+// @Summary Check the graph against the schema
+// @Description List the entities and relations that violate the current schema version.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Success 200 {object} map[string]interface{} "Migration report generated successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Schema not found"
+// @Router /workspaces/{workspaceId}/knowledge/schema/report [get]
+func (h *SchemaHandler) GetMigrationReport(c *fiber.Ctx) error {
+	report, err := h.service.GetMigrationReport(c.Context(), workspaceIDFrom(c))
+	if err != nil {
+		return knowledgeError(c, "Failed to generate migration report", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Migration report generated successfully",
+		"data":    report,
+	})
+}
+
+// ListSchemaVersions godoc
+// @Summary List schema versions
+// @Description List every schema version of the workspace, newest first.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Success 200 {object} map[string]interface{} "Schema versions retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /workspaces/{workspaceId}/knowledge/schema/versions [get]
+func (h *SchemaHandler) ListSchemaVersions(c *fiber.Ctx) error {
+	versions, err := h.service.ListSchemaVersions(c.Context(), workspaceIDFrom(c))
+	if err != nil {
+		return knowledgeError(c, "Failed to list schema versions", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Schema versions retrieved successfully",
+		"data":    versions,
+	})
+}
+
+// GetSchemaVersion godoc
+// @Summary Get a schema version
+// @Description Get one stored schema version.
+// @Tags knowledge
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param version path int true "Schema version"
+// @Success 200 {object} map[string]interface{} "Schema retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Schema version not found"
+// @Router /workspaces/{workspaceId}/knowledge/schema/versions/{version} [get]
+func (h *SchemaHandler) GetSchemaVersion(c *fiber.Ctx) error {
+	version, err := strconv.Atoi(c.Params("version"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Failed to get schema",
+			"message": "schema version not found",
+		})
+	}
+
+	schema, err := h.service.GetSchemaVersion(c.Context(), workspaceIDFrom(c), version)
+	if err != nil {
+		return knowledgeError(c, "Failed to get schema", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Schema retrieved successfully",
+		"data":    schema,
+	})
+}
@@ -0,0 +1,181 @@
+package knowledge
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const SchemaCollectionName = "knowledge_schemas"
+
+const (
+	// MigrationReportMaxViolations caps the violations listed in a report.
+	// ViolationCount still counts all of them.
+	MigrationReportMaxViolations = 1000
+
+	schemaScanBatchSize = 500
+)
+
+// PropertyType is the kind of value a property holds. Dates are strings in
+// the YYYY-MM-DD or RFC 3339 format and references are entity IDs.
+type PropertyType string
+
+const (
+	PropertyTypeString    PropertyType = "string"
+	PropertyTypeNumber    PropertyType = "number"
+	PropertyTypeDate      PropertyType = "date"
+	PropertyTypeEnum      PropertyType = "enum"
+	PropertyTypeReference PropertyType = "reference"
+)
+
+// Cardinality limits how many relations of a type an entity may have. The
+// "one" side of the name is the side that may have at most one: with
+// many_to_one every source has at most one such relation.
+type Cardinality string
+
+const (
+	CardinalityOneToOne   Cardinality = "one_to_one"
+	CardinalityOneToMany  Cardinality = "one_to_many"
+	CardinalityManyToOne  Cardinality = "many_to_one"
+	CardinalityManyToMany Cardinality = "many_to_many"
+)
+
+// PropertyDefinition describes one property. Values lists the allowed values
+// of an enum. Target optionally restricts a reference to entities of one
+// type.
+type PropertyDefinition struct {
+	Name     string       `json:"name" bson:"name" validate:"required,max=64"`
+	Type     PropertyType `json:"type" bson:"type" validate:"required,oneof=string number date enum reference"`
+	Required bool         `json:"required" bson:"required"`
+	Values   []string     `json:"values,omitempty" bson:"values,omitempty"`
+	Target   string       `json:"target,omitempty" bson:"target,omitempty" validate:"max=64"`
+}
+
+// EntityTypeDefinition describes an entity type. Entities of the type may
+// only have the properties listed.
+type EntityTypeDefinition struct {
+	Name        string               `json:"name" bson:"name" validate:"required,max=64"`
+	Description string               `json:"description,omitempty" bson:"description,omitempty" validate:"max=500"`
+	Properties  []PropertyDefinition `json:"properties" bson:"properties" validate:"dive"`
+}
+
+// RelationTypeDefinition describes a relation type. Empty SourceTypes or
+// TargetTypes allow any entity type on that end.
+type RelationTypeDefinition struct {
+	Name        string               `json:"name" bson:"name" validate:"required,max=64"`
+	Description string               `json:"description,omitempty" bson:"description,omitempty" validate:"max=500"`
+	SourceTypes []string             `json:"source_types" bson:"source_types"`
+	TargetTypes []string             `json:"target_types" bson:"target_types"`
+	Cardinality Cardinality          `json:"cardinality" bson:"cardinality" validate:"omitempty,oneof=one_to_one one_to_many many_to_one many_to_many"`
+	Properties  []PropertyDefinition `json:"properties" bson:"properties" validate:"dive"`
+}
+
+// Schema is one version of the ontology of a workspace. Versions are never
+// changed once stored; the highest version is the one enforced. Workspaces
+// without a schema accept any entity and relation type.
+type Schema struct {
+	ID            primitive.ObjectID       `json:"-" bson:"_id,omitempty"`
+	WorkspaceID   string                   `json:"workspace_id" bson:"workspace_id"`
+	Version       int                      `json:"version" bson:"version"`
+	EntityTypes   []EntityTypeDefinition   `json:"entity_types" bson:"entity_types"`
+	RelationTypes []RelationTypeDefinition `json:"relation_types" bson:"relation_types"`
+	CreatedBy     string                   `json:"created_by" bson:"created_by"`
+	CreatedAt     time.Time                `json:"created_at" bson:"created_at"`
+}
+
+func (s *Schema) EntityType(name string) *EntityTypeDefinition {
+	for i := range s.EntityTypes {
+		if s.EntityTypes[i].Name == name {
+			return &s.EntityTypes[i]
+		}
+	}
+
+	return nil
+}
+
+func (s *Schema) RelationType(name string) *RelationTypeDefinition {
+	for i := range s.RelationTypes {
+		if s.RelationTypes[i].Name == name {
+			return &s.RelationTypes[i]
+		}
+	}
+
+	return nil
+}
+
+// limitsSources reports whether a source may have at most one relation of
+// the type, and limitsTargets whether a target may.
+func (d *RelationTypeDefinition) limitsSources() bool {
+	return d.Cardinality == CardinalityManyToOne || d.Cardinality == CardinalityOneToOne
+}
+
+func (d *RelationTypeDefinition) limitsTargets() bool {
+	return d.Cardinality == CardinalityOneToMany || d.Cardinality == CardinalityOneToOne
+}
+
+type UpdateSchemaRequest struct {
+	EntityTypes   []EntityTypeDefinition   `json:"entity_types" validate:"required,dive"`
+	RelationTypes []RelationTypeDefinition `json:"relation_types" validate:"dive"`
+}
+
+type SchemaVersionSummary struct {
+	Version           int       `json:"version"`
+	EntityTypeCount   int       `json:"entity_type_count"`
+	RelationTypeCount int       `json:"relation_type_count"`
+	CreatedBy         string    `json:"created_by"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+const (
+	ViolationKindEntity   = "entity"
+	ViolationKindRelation = "relation"
+)
+
+// Violation lists why an existing entity or relation does not conform to a
+// schema version.
+type Violation struct {
+	Kind     string   `json:"kind"`
+	ID       string   `json:"id"`
+	Type     string   `json:"type"`
+	Problems []string `json:"problems"`
+}
+
+// MigrationReport lists the entities and relations of a workspace that
+// violate a schema version.
+type MigrationReport struct {
+	Version          int          `json:"version"`
+	EntitiesChecked  int64        `json:"entities_checked"`
+	RelationsChecked int64        `json:"relations_checked"`
+	ViolationCount   int64        `json:"violation_count"`
+	Violations       []*Violation `json:"violations"`
+	Truncated        bool         `json:"truncated"`
+}
+
+func (r *MigrationReport) add(violation *Violation) {
+	r.ViolationCount++
+	if len(r.Violations) < MigrationReportMaxViolations {
+		r.Violations = append(r.Violations, violation)
+	} else {
+		r.Truncated = true
+	}
+}
+
+type SchemaUpdateResponse struct {
+	Schema *Schema          `json:"schema"`
+	Report *MigrationReport `json:"report"`
+}
+
+// ScannedRelation is a relation with the types of the entities it joins.
+type ScannedRelation struct {
+	*Relation
+	SourceType string
+	TargetType string
+}
+
+// RelationCount is how many relations of one type an entity has in one
+// direction.
+type RelationCount struct {
+	EntityID   string
+	EntityType string
+	Count      int64
+}
@@ -0,0 +1,84 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+type SchemaRepository interface {
+	Create(ctx context.Context, schema *Schema) (*Schema, error)
+	GetLatest(ctx context.Context, workspaceID string) (*Schema, error)
+	GetVersion(ctx context.Context, workspaceID string, version int) (*Schema, error)
+	ListVersions(ctx context.Context, workspaceID string) ([]*Schema, error)
+	DeleteByWorkspace(ctx context.Context, workspaceID string) error
+}
+
+type schemaRepository struct {
+	repo mongo.Repository[Schema]
+}
+
+var _ SchemaRepository = (*schemaRepository)(nil)
+
+func NewSchemaRepository(mongoService *mongo.MongoService) SchemaRepository {
+	return &schemaRepository{
+		repo: mongo.NewRepository[Schema](mongoService, SchemaCollectionName),
+	}
+}
+
+func (r *schemaRepository) Create(ctx context.Context, schema *Schema) (*Schema, error) {
+	result, err := r.repo.Create(ctx, *schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *schemaRepository) GetLatest(ctx context.Context, workspaceID string) (*Schema, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}})
+
+	result, err := r.repo.FindOne(ctx, bson.M{"workspace_id": workspaceID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *schemaRepository) GetVersion(ctx context.Context, workspaceID string, version int) (*Schema, error) {
+	result, err := r.repo.FindOne(ctx, bson.M{"workspace_id": workspaceID, "version": version})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *schemaRepository) ListVersions(ctx context.Context, workspaceID string) ([]*Schema, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "version", Value: -1}})
+
+	results, err := r.repo.Find(ctx, bson.M{"workspace_id": workspaceID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schema versions: %w", err)
+	}
+
+	schemas := make([]*Schema, len(results))
+	for i := range results {
+		schemas[i] = &results[i]
+	}
+
+	return schemas, nil
+}
+
+func (r *schemaRepository) DeleteByWorkspace(ctx context.Context, workspaceID string) error {
+	if _, err := r.repo.DeleteMany(ctx, bson.M{"workspace_id": workspaceID}); err != nil {
+		return fmt.Errorf("failed to delete schemas: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,489 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/neo4j"
+)
+
+type SchemaService interface {
+	GetSchema(ctx context.Context, workspaceID string) (*Schema, error)
+	GetSchemaVersion(ctx context.Context, workspaceID string, version int) (*Schema, error)
+	ListSchemaVersions(ctx context.Context, workspaceID string) ([]*SchemaVersionSummary, error)
+	UpdateSchema(ctx context.Context, workspaceID, accountID string, req *UpdateSchemaRequest) (*SchemaUpdateResponse, error)
+	PreviewSchema(ctx context.Context, workspaceID string, req *UpdateSchemaRequest) (*MigrationReport, error)
+	GetMigrationReport(ctx context.Context, workspaceID string) (*MigrationReport, error)
+}
+
+type schemaService struct {
+	repository          SchemaRepository
+	knowledgeRepository KnowledgeRepository
+}
+
+func NewSchemaService(mongoService *mongo.MongoService, neo4jService neo4j.Neo4jService) SchemaService {
+	return newSchemaService(NewSchemaRepository(mongoService), NewKnowledgeRepository(neo4jService))
+}
+
+func newSchemaService(repository SchemaRepository, knowledgeRepository KnowledgeRepository) *schemaService {
+	return &schemaService{
+		repository:          repository,
+		knowledgeRepository: knowledgeRepository,
+	}
+}
+
+func (s *schemaService) GetSchema(ctx context.Context, workspaceID string) (*Schema, error) {
+	schema, err := s.repository.GetLatest(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if schema == nil {
+		return nil, fmt.Errorf("schema not found")
+	}
+
+	return schema, nil
+}
+
+func (s *schemaService) GetSchemaVersion(ctx context.Context, workspaceID string, version int) (*Schema, error) {
+	schema, err := s.repository.GetVersion(ctx, workspaceID, version)
+	if err != nil {
+		return nil, err
+	}
+	if schema == nil {
+		return nil, fmt.Errorf("schema version not found")
+	}
+
+	return schema, nil
+}
+
+func (s *schemaService) ListSchemaVersions(ctx context.Context, workspaceID string) ([]*SchemaVersionSummary, error) {
+	schemas, err := s.repository.ListVersions(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]*SchemaVersionSummary, len(schemas))
+	for i, schema := range schemas {
+		summaries[i] = &SchemaVersionSummary{
+			Version:           schema.Version,
+			EntityTypeCount:   len(schema.EntityTypes),
+			RelationTypeCount: len(schema.RelationTypes),
+			CreatedBy:         schema.CreatedBy,
+			CreatedAt:         schema.CreatedAt,
+		}
+	}
+
+	return summaries, nil
+}
+
+// UpdateSchema stores req as the next schema version and reports the existing
+// entities and relations that violate it. Violations do not block the update:
+// they are left for the workspace to fix, and later writes to those entities
+// must conform.
+func (s *schemaService) UpdateSchema(ctx context.Context, workspaceID, accountID string, req *UpdateSchemaRequest) (*SchemaUpdateResponse, error) {
+	schema, err := s.nextSchema(ctx, workspaceID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	schema.CreatedBy = accountID
+	schema.CreatedAt = time.Now()
+
+	created, err := s.repository.Create(ctx, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.migrationReport(ctx, created)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SchemaUpdateResponse{Schema: created, Report: report}, nil
+}
+
+// PreviewSchema reports what UpdateSchema would report without storing req.
+func (s *schemaService) PreviewSchema(ctx context.Context, workspaceID string, req *UpdateSchemaRequest) (*MigrationReport, error) {
+	schema, err := s.nextSchema(ctx, workspaceID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.migrationReport(ctx, schema)
+}
+
+// GetMigrationReport checks the workspace against its current schema.
+func (s *schemaService) GetMigrationReport(ctx context.Context, workspaceID string) (*MigrationReport, error) {
+	schema, err := s.GetSchema(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.migrationReport(ctx, schema)
+}
+
+func (s *schemaService) nextSchema(ctx context.Context, workspaceID string, req *UpdateSchemaRequest) (*Schema, error) {
+	schema, err := normalizeSchema(req)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := s.repository.GetLatest(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	schema.WorkspaceID = workspaceID
+	schema.Version = 1
+	if latest != nil {
+		schema.Version = latest.Version + 1
+	}
+
+	return schema, nil
+}
+
+// migrationReport reads the whole workspace in batches and checks every
+// entity and relation against schema.
+func (s *schemaService) migrationReport(ctx context.Context, schema *Schema) (*MigrationReport, error) {
+	report := &MigrationReport{
+		Version:    schema.Version,
+		Violations: []*Violation{},
+	}
+	workspaceID := schema.WorkspaceID
+
+	after := ""
+	for {
+		entities, err := s.knowledgeRepository.ScanEntities(ctx, workspaceID, after, schemaScanBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		var ids []string
+		for _, entity := range entities {
+			if definition := schema.EntityType(entity.Type); definition != nil {
+				ids = append(ids, referencedIDs(definition.Properties, entity.Properties)...)
+			}
+		}
+		types, err := s.knowledgeRepository.EntityTypes(ctx, workspaceID, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entity := range entities {
+			report.EntitiesChecked++
+			if problems := entityProblems(schema, entity.Type, entity.Properties, types); len(problems) > 0 {
+				report.add(&Violation{Kind: ViolationKindEntity, ID: entity.ID, Type: entity.Type, Problems: problems})
+			}
+		}
+
+		if len(entities) < schemaScanBatchSize {
+			break
+		}
+		after = entities[len(entities)-1].ID
+	}
+
+	after = ""
+	for {
+		relations, err := s.knowledgeRepository.ScanRelations(ctx, workspaceID, after, schemaScanBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		var ids []string
+		for _, relation := range relations {
+			if definition := schema.RelationType(relation.Type); definition != nil {
+				ids = append(ids, referencedIDs(definition.Properties, relation.Properties)...)
+			}
+		}
+		types, err := s.knowledgeRepository.EntityTypes(ctx, workspaceID, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, relation := range relations {
+			report.RelationsChecked++
+			if problems := relationProblems(schema, relation.Type, relation.SourceType, relation.TargetType, relation.Properties, types); len(problems) > 0 {
+				report.add(&Violation{Kind: ViolationKindRelation, ID: relation.ID, Type: relation.Type, Problems: problems})
+			}
+		}
+
+		if len(relations) < schemaScanBatchSize {
+			break
+		}
+		after = relations[len(relations)-1].ID
+	}
+
+	for _, definition := range schema.RelationTypes {
+		for _, outgoing := range []bool{true, false} {
+			if (outgoing && !definition.limitsSources()) || (!outgoing && !definition.limitsTargets()) {
+				continue
+			}
+
+			counts, err := s.knowledgeRepository.RelationFanOut(ctx, workspaceID, definition.Name, outgoing, MigrationReportMaxViolations)
+			if err != nil {
+				return nil, err
+			}
+
+			direction := "incoming"
+			if outgoing {
+				direction = "outgoing"
+			}
+			for _, count := range counts {
+				report.add(&Violation{
+					Kind:     ViolationKindEntity,
+					ID:       count.EntityID,
+					Type:     count.EntityType,
+					Problems: []string{fmt.Sprintf("has %d %s %s relations but %s allows one", count.Count, direction, definition.Name, definition.Cardinality)},
+				})
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// normalizeSchema checks that the definitions in req are consistent and
+// normalizes type names the same way entity and relation writes do.
+func normalizeSchema(req *UpdateSchemaRequest) (*Schema, error) {
+	schema := &Schema{
+		EntityTypes:   make([]EntityTypeDefinition, len(req.EntityTypes)),
+		RelationTypes: make([]RelationTypeDefinition, len(req.RelationTypes)),
+	}
+
+	entityTypes := make(map[string]bool, len(req.EntityTypes))
+	for i, definition := range req.EntityTypes {
+		name, err := normalizeEntityType(definition.Name)
+		if err != nil {
+			return nil, err
+		}
+		if entityTypes[name] {
+			return nil, fmt.Errorf("invalid schema: entity type %q is defined twice", name)
+		}
+		entityTypes[name] = true
+
+		definition.Name = name
+		schema.EntityTypes[i] = definition
+	}
+
+	relationTypes := make(map[string]bool, len(req.RelationTypes))
+	for i, definition := range req.RelationTypes {
+		name, err := normalizeRelationType(definition.Name)
+		if err != nil {
+			return nil, err
+		}
+		if relationTypes[name] {
+			return nil, fmt.Errorf("invalid schema: relation type %q is defined twice", name)
+		}
+		relationTypes[name] = true
+
+		for _, entityType := range append(slices.Clone(definition.SourceTypes), definition.TargetTypes...) {
+			if !entityTypes[entityType] {
+				return nil, fmt.Errorf("invalid schema: relation type %q refers to undefined entity type %q", name, entityType)
+			}
+		}
+
+		if definition.Cardinality == "" {
+			definition.Cardinality = CardinalityManyToMany
+		}
+		if definition.SourceTypes == nil {
+			definition.SourceTypes = []string{}
+		}
+		if definition.TargetTypes == nil {
+			definition.TargetTypes = []string{}
+		}
+
+		definition.Name = name
+		schema.RelationTypes[i] = definition
+	}
+
+	for i := range schema.EntityTypes {
+		properties, err := normalizePropertyDefinitions(schema.EntityTypes[i].Properties, entityTypes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema: entity type %q: %w", schema.EntityTypes[i].Name, err)
+		}
+		schema.EntityTypes[i].Properties = properties
+	}
+	for i := range schema.RelationTypes {
+		properties, err := normalizePropertyDefinitions(schema.RelationTypes[i].Properties, entityTypes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema: relation type %q: %w", schema.RelationTypes[i].Name, err)
+		}
+		schema.RelationTypes[i].Properties = properties
+	}
+
+	return schema, nil
+}
+
+func normalizePropertyDefinitions(definitions []PropertyDefinition, entityTypes map[string]bool) ([]PropertyDefinition, error) {
+	if len(definitions) > maxProperties {
+		return nil, fmt.Errorf("at most %d properties", maxProperties)
+	}
+
+	names := make(map[string]bool, len(definitions))
+	normalized := make([]PropertyDefinition, len(definitions))
+	for i, definition := range definitions {
+		if !propertyPattern.MatchString(definition.Name) || reservedProperties[definition.Name] {
+			return nil, fmt.Errorf("invalid property name %q", definition.Name)
+		}
+		if names[definition.Name] {
+			return nil, fmt.Errorf("property %q is defined twice", definition.Name)
+		}
+		names[definition.Name] = true
+
+		switch definition.Type {
+		case PropertyTypeString, PropertyTypeNumber, PropertyTypeDate:
+		case PropertyTypeEnum:
+			if len(definition.Values) == 0 {
+				return nil, fmt.Errorf("enum property %q needs at least one value", definition.Name)
+			}
+		case PropertyTypeReference:
+			if definition.Target != "" && !entityTypes[definition.Target] {
+				return nil, fmt.Errorf("reference property %q targets undefined entity type %q", definition.Name, definition.Target)
+			}
+		default:
+			return nil, fmt.Errorf("property %q has unknown type %q", definition.Name, definition.Type)
+		}
+
+		if definition.Type != PropertyTypeEnum {
+			definition.Values = nil
+		}
+		if definition.Type != PropertyTypeReference {
+			definition.Target = ""
+		}
+		normalized[i] = definition
+	}
+
+	return normalized, nil
+}
+
+// entityProblems describes how an entity violates schema. types maps the IDs
+// of referenced entities to their types, as returned by
+// KnowledgeRepository.EntityTypes.
+func entityProblems(schema *Schema, entityType string, properties map[string]interface{}, types map[string]string) []string {
+	definition := schema.EntityType(entityType)
+	if definition == nil {
+		return []string{fmt.Sprintf("entity type %q is not defined in the schema", entityType)}
+	}
+
+	return propertyProblems(definition.Properties, properties, types)
+}
+
+// relationProblems is entityProblems for relations. Cardinality is checked
+// separately, as it depends on the other relations of the entities.
+func relationProblems(schema *Schema, relationType, sourceType, targetType string, properties map[string]interface{}, types map[string]string) []string {
+	definition := schema.RelationType(relationType)
+	if definition == nil {
+		return []string{fmt.Sprintf("relation type %q is not defined in the schema", relationType)}
+	}
+
+	var problems []string
+	if len(definition.SourceTypes) > 0 && !slices.Contains(definition.SourceTypes, sourceType) {
+		problems = append(problems, fmt.Sprintf("source must be one of %s, not %s", strings.Join(definition.SourceTypes, ", "), sourceType))
+	}
+	if len(definition.TargetTypes) > 0 && !slices.Contains(definition.TargetTypes, targetType) {
+		problems = append(problems, fmt.Sprintf("target must be one of %s, not %s", strings.Join(definition.TargetTypes, ", "), targetType))
+	}
+
+	return append(problems, propertyProblems(definition.Properties, properties, types)...)
+}
+
+func propertyProblems(definitions []PropertyDefinition, properties map[string]interface{}, types map[string]string) []string {
+	var problems []string
+
+	defined := make(map[string]bool, len(definitions))
+	for _, definition := range definitions {
+		defined[definition.Name] = true
+
+		value, ok := properties[definition.Name]
+		if !ok || value == nil {
+			if definition.Required {
+				problems = append(problems, fmt.Sprintf("property %q is required", definition.Name))
+			}
+			continue
+		}
+
+		if problem := valueProblem(definition, value, types); problem != "" {
+			problems = append(problems, fmt.Sprintf("property %q %s", definition.Name, problem))
+		}
+	}
+
+	var unknown []string
+	for name := range properties {
+		if !defined[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	slices.Sort(unknown)
+	for _, name := range unknown {
+		problems = append(problems, fmt.Sprintf("property %q is not defined in the schema", name))
+	}
+
+	return problems
+}
+
+func valueProblem(definition PropertyDefinition, value interface{}, types map[string]string) string {
+	switch definition.Type {
+	case PropertyTypeNumber:
+		switch value.(type) {
+		case int64, float64:
+			return ""
+		}
+		return "must be a number"
+	case PropertyTypeString:
+		if _, ok := value.(string); !ok {
+			return "must be a string"
+		}
+	case PropertyTypeDate:
+		str, ok := value.(string)
+		if !ok || !isDate(str) {
+			return "must be a date in the YYYY-MM-DD or RFC 3339 format"
+		}
+	case PropertyTypeEnum:
+		str, ok := value.(string)
+		if !ok || !slices.Contains(definition.Values, str) {
+			return "must be one of " + strings.Join(definition.Values, ", ")
+		}
+	case PropertyTypeReference:
+		id, ok := value.(string)
+		if !ok {
+			return "must be an entity ID"
+		}
+
+		entityType, exists := types[id]
+		if !exists {
+			return "refers to an entity that does not exist"
+		}
+		if definition.Target != "" && entityType != definition.Target {
+			return "must refer to an entity of type " + definition.Target
+		}
+	}
+
+	return ""
+}
+
+// referencedIDs returns the entity IDs held by the reference properties.
+func referencedIDs(definitions []PropertyDefinition, properties map[string]interface{}) []string {
+	var ids []string
+	for _, definition := range definitions {
+		if definition.Type != PropertyTypeReference {
+			continue
+		}
+		if id, ok := properties[definition.Name].(string); ok {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+func isDate(value string) bool {
+	if _, err := time.Parse(time.DateOnly, value); err == nil {
+		return true
+	}
+	_, err := time.Parse(time.RFC3339, value)
+	return err == nil
+}
@@ -0,0 +1,256 @@
+package knowledge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSchemaEnforcedService(schema *Schema) (*knowledgeService, *MockKnowledgeRepository) {
+	mockRepo := &MockKnowledgeRepository{}
+	mockSchemas := &MockSchemaRepository{}
+	mockSchemas.On("GetLatest", mock.Anything, testWorkspaceID).Return(schema, nil)
+	return newKnowledgeService(mockRepo, mockSchemas, setupEvidenceRepository()), mockRepo
+}
+
+func TestNormalizeSchema(t *testing.T) {
+	valid := &UpdateSchemaRequest{
+		EntityTypes: []EntityTypeDefinition{
+			{Name: "Person", Properties: []PropertyDefinition{{Name: "name", Type: PropertyTypeString, Values: []string{"ignored"}}}},
+			{Name: "Organization"},
+		},
+		RelationTypes: []RelationTypeDefinition{
+			{Name: "works_at", SourceTypes: []string{"Person"}, TargetTypes: []string{"Organization"}},
+		},
+	}
+
+	schema, err := normalizeSchema(valid)
+	require.NoError(t, err)
+	assert.Equal(t, "WORKS_AT", schema.RelationTypes[0].Name)
+	assert.Equal(t, CardinalityManyToMany, schema.RelationTypes[0].Cardinality)
+	assert.Nil(t, schema.EntityTypes[0].Properties[0].Values)
+
+	tests := []struct {
+		name          string
+		req           *UpdateSchemaRequest
+		errorContains string
+	}{
+		{
+			name:          "duplicate entity type",
+			req:           &UpdateSchemaRequest{EntityTypes: []EntityTypeDefinition{{Name: "Person"}, {Name: "Person"}}},
+			errorContains: "defined twice",
+		},
+		{
+			name: "undefined endpoint type",
+			req: &UpdateSchemaRequest{
+				EntityTypes:   []EntityTypeDefinition{{Name: "Person"}},
+				RelationTypes: []RelationTypeDefinition{{Name: "WORKS_AT", TargetTypes: []string{"Organization"}}},
+			},
+			errorContains: "undefined entity type \"Organization\"",
+		},
+		{
+			name:          "enum without values",
+			req:           &UpdateSchemaRequest{EntityTypes: []EntityTypeDefinition{{Name: "Person", Properties: []PropertyDefinition{{Name: "status", Type: PropertyTypeEnum}}}}},
+			errorContains: "needs at least one value",
+		},
+		{
+			name:          "reserved property",
+			req:           &UpdateSchemaRequest{EntityTypes: []EntityTypeDefinition{{Name: "Person", Properties: []PropertyDefinition{{Name: "workspace_id", Type: PropertyTypeString}}}}},
+			errorContains: "invalid property name",
+		},
+		{
+			name:          "reference to undefined type",
+			req:           &UpdateSchemaRequest{EntityTypes: []EntityTypeDefinition{{Name: "Person", Properties: []PropertyDefinition{{Name: "employer", Type: PropertyTypeReference, Target: "Company"}}}}},
+			errorContains: "targets undefined entity type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := normalizeSchema(tt.req)
+			assert.ErrorContains(t, err, "invalid")
+			assert.ErrorContains(t, err, tt.errorContains)
+		})
+	}
+}
+
+func TestKnowledgeService_CreateEntity_Schema(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           *CreateEntityRequest
+		types         map[string]string
+		errorContains string
+	}{
+		{
+			name: "conforming entity",
+			req: &CreateEntityRequest{Type: "Person", Properties: map[string]interface{}{
+				"name": "Ada", "born": "1815-12-10", "status": "retired", "employer": "4:test:9",
+			}},
+			types: map[string]string{"4:test:9": "Organization"},
+		},
+		{
+			name:          "undefined type",
+			req:           &CreateEntityRequest{Type: "Robot", Properties: map[string]interface{}{"name": "R2"}},
+			errorContains: "entity type \"Robot\" is not defined",
+		},
+		{
+			name:          "missing required property",
+			req:           &CreateEntityRequest{Type: "Person", Properties: map[string]interface{}{"born": "1815-12-10"}},
+			errorContains: "property \"name\" is required",
+		},
+		{
+			name:          "undeclared property",
+			req:           &CreateEntityRequest{Type: "Person", Properties: map[string]interface{}{"name": "Ada", "nickname": "Countess"}},
+			errorContains: "property \"nickname\" is not defined",
+		},
+		{
+			name:          "wrong date and enum",
+			req:           &CreateEntityRequest{Type: "Person", Properties: map[string]interface{}{"name": "Ada", "born": "10/12/1815", "status": "asleep"}},
+			errorContains: "must be one of active, retired",
+		},
+		{
+			name:          "reference to the wrong type",
+			req:           &CreateEntityRequest{Type: "Person", Properties: map[string]interface{}{"name": "Ada", "employer": "4:test:2"}},
+			types:         map[string]string{"4:test:2": "Person"},
+			errorContains: "must refer to an entity of type Organization",
+		},
+		{
+			name:          "dangling reference",
+			req:           &CreateEntityRequest{Type: "Person", Properties: map[string]interface{}{"name": "Ada", "employer": "4:test:404"}},
+			types:         map[string]string{},
+			errorContains: "refers to an entity that does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockRepo := setupSchemaEnforcedService(CreateTestSchema())
+			if tt.types != nil {
+				mockRepo.On("EntityTypes", mock.Anything, testWorkspaceID, mock.Anything).Return(tt.types, nil)
+			}
+			if tt.errorContains == "" {
+				mockRepo.On("CreateEntity", mock.Anything, testWorkspaceID, testAccountID, tt.req.Type, mock.Anything).Return(CreateTestEntity(), nil)
+			}
+
+			_, err := service.CreateEntity(context.Background(), testWorkspaceID, testAccountID, tt.req)
+
+			if tt.errorContains != "" {
+				assert.ErrorContains(t, err, "invalid entity")
+				assert.ErrorContains(t, err, tt.errorContains)
+				mockRepo.AssertNotCalled(t, "CreateEntity", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestKnowledgeService_UpdateEntity_SchemaChecksMergedProperties(t *testing.T) {
+	service, mockRepo := setupSchemaEnforcedService(CreateTestSchema())
+	current := CreateTestEntity(func(e *Entity) {
+		e.Properties = map[string]interface{}{"name": "Ada", "status": "active"}
+	})
+
+	mockRepo.On("GetEntity", mock.Anything, testWorkspaceID, current.ID).Return(current, nil)
+
+	_, err := service.UpdateEntity(context.Background(), testWorkspaceID, current.ID, &UpdateEntityRequest{
+		Properties: map[string]interface{}{"name": nil},
+	})
+	assert.ErrorContains(t, err, "property \"name\" is required")
+
+	mockRepo.On("UpdateEntity", mock.Anything, testWorkspaceID, current.ID, map[string]interface{}{"status": "retired"}).Return(current, nil)
+
+	_, err = service.UpdateEntity(context.Background(), testWorkspaceID, current.ID, &UpdateEntityRequest{
+		Properties: map[string]interface{}{"status": "retired"},
+	})
+	assert.NoError(t, err)
+}
+
+func TestKnowledgeService_CreateRelation_Schema(t *testing.T) {
+	types := map[string]string{"4:test:1": "Person", "4:test:2": "Organization", "4:test:3": "Person"}
+
+	t.Run("wrong endpoint types", func(t *testing.T) {
+		service, mockRepo := setupSchemaEnforcedService(CreateTestSchema())
+		mockRepo.On("EntityTypes", mock.Anything, testWorkspaceID, []string{"4:test:1", "4:test:3"}).Return(types, nil)
+
+		_, err := service.CreateRelation(context.Background(), testWorkspaceID, testAccountID, &CreateRelationRequest{
+			SourceID: "4:test:1", TargetID: "4:test:3", Type: "works_at",
+		})
+
+		assert.ErrorContains(t, err, "target must be one of Organization, not Person")
+	})
+
+	t.Run("cardinality", func(t *testing.T) {
+		service, mockRepo := setupSchemaEnforcedService(CreateTestSchema())
+		mockRepo.On("EntityTypes", mock.Anything, testWorkspaceID, []string{"4:test:1", "4:test:2"}).Return(types, nil)
+		mockRepo.On("CountRelations", mock.Anything, testWorkspaceID, "4:test:1", "WORKS_AT", true).Return(int64(1), nil)
+
+		_, err := service.CreateRelation(context.Background(), testWorkspaceID, testAccountID, &CreateRelationRequest{
+			SourceID: "4:test:1", TargetID: "4:test:2", Type: "WORKS_AT",
+		})
+
+		assert.ErrorContains(t, err, "the source already has a WORKS_AT relation")
+		mockRepo.AssertNotCalled(t, "CountRelations", mock.Anything, mock.Anything, mock.Anything, mock.Anything, false)
+	})
+
+	t.Run("missing endpoint", func(t *testing.T) {
+		service, mockRepo := setupSchemaEnforcedService(CreateTestSchema())
+		mockRepo.On("EntityTypes", mock.Anything, testWorkspaceID, mock.Anything).Return(map[string]string{"4:test:1": "Person"}, nil)
+
+		_, err := service.CreateRelation(context.Background(), testWorkspaceID, testAccountID, &CreateRelationRequest{
+			SourceID: "4:test:1", TargetID: "4:other:9", Type: "WORKS_AT",
+		})
+
+		assert.EqualError(t, err, "source or target entity not found")
+	})
+}
+
+func TestSchemaService_UpdateSchema(t *testing.T) {
+	mockSchemas := &MockSchemaRepository{}
+	mockRepo := &MockKnowledgeRepository{}
+	service := newSchemaService(mockSchemas, mockRepo)
+
+	mockSchemas.On("GetLatest", mock.Anything, testWorkspaceID).Return(CreateTestSchema(), nil)
+	mockSchemas.On("Create", mock.Anything, mock.MatchedBy(func(s *Schema) bool {
+		return s.Version == 2 && s.WorkspaceID == testWorkspaceID && s.CreatedBy == testAccountID
+	})).Return(CreateTestSchema(func(s *Schema) {
+		s.Version = 2
+		s.RelationTypes[0].Cardinality = CardinalityOneToOne
+	}), nil)
+
+	mockRepo.On("ScanEntities", mock.Anything, testWorkspaceID, "", schemaScanBatchSize).Return([]*Entity{
+		{ID: "4:test:1", Type: "Person", Properties: map[string]interface{}{"name": "Ada"}},
+		{ID: "4:test:2", Type: "Person", Properties: map[string]interface{}{}},
+		{ID: "4:test:3", Type: "Robot", Properties: map[string]interface{}{}},
+	}, nil)
+	mockRepo.On("ScanRelations", mock.Anything, testWorkspaceID, "", schemaScanBatchSize).Return([]*ScannedRelation{
+		{Relation: &Relation{ID: "5:test:1", Type: "WORKS_AT", Properties: map[string]interface{}{"since": "2020"}}, SourceType: "Person", TargetType: "Organization"},
+	}, nil)
+	mockRepo.On("EntityTypes", mock.Anything, testWorkspaceID, []string(nil)).Return(map[string]string{}, nil)
+	mockRepo.On("RelationFanOut", mock.Anything, testWorkspaceID, "WORKS_AT", true, MigrationReportMaxViolations).Return([]*RelationCount{}, nil)
+	mockRepo.On("RelationFanOut", mock.Anything, testWorkspaceID, "WORKS_AT", false, MigrationReportMaxViolations).Return([]*RelationCount{
+		{EntityID: "4:test:9", EntityType: "Organization", Count: 3},
+	}, nil)
+
+	response, err := service.UpdateSchema(context.Background(), testWorkspaceID, testAccountID, &UpdateSchemaRequest{
+		EntityTypes:   CreateTestSchema().EntityTypes,
+		RelationTypes: CreateTestSchema().RelationTypes,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, response.Schema.Version)
+
+	report := response.Report
+	assert.Equal(t, int64(3), report.EntitiesChecked)
+	assert.Equal(t, int64(1), report.RelationsChecked)
+	assert.Equal(t, int64(4), report.ViolationCount)
+	assert.Equal(t, "4:test:2", report.Violations[0].ID)
+	assert.Equal(t, []string{"entity type \"Robot\" is not defined in the schema"}, report.Violations[1].Problems)
+	assert.Equal(t, ViolationKindRelation, report.Violations[2].Kind)
+	assert.Contains(t, report.Violations[3].Problems[0], "has 3 incoming WORKS_AT relations")
+	mockRepo.AssertExpectations(t)
+}
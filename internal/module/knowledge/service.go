@@ -0,0 +1,627 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/neo4j"
+)
+
+const maxProperties = 100
+
+var (
+	typePattern     = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,63}$`)
+	propertyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,63}$`)
+)
+
+type KnowledgeService interface {
+	CreateEntity(ctx context.Context, workspaceID, accountID string, req *CreateEntityRequest) (*Entity, error)
+	GetEntity(ctx context.Context, workspaceID, id string) (*Entity, error)
+	ListEntities(ctx context.Context, workspaceID string, query *EntityListQuery) (*EntityPage, error)
+	UpdateEntity(ctx context.Context, workspaceID, id string, req *UpdateEntityRequest) (*Entity, error)
+	DeleteEntity(ctx context.Context, workspaceID, id string) error
+
+	CreateRelation(ctx context.Context, workspaceID, accountID string, req *CreateRelationRequest) (*Relation, error)
+	GetRelation(ctx context.Context, workspaceID, id string) (*Relation, error)
+	UpdateRelation(ctx context.Context, workspaceID, id string, req *UpdateRelationRequest) (*Relation, error)
+	DeleteRelation(ctx context.Context, workspaceID, id string) error
+
+	GetNeighbourhood(ctx context.Context, workspaceID, id string, query *NeighbourhoodQuery) (*Subgraph, error)
+	FindPaths(ctx context.Context, workspaceID string, query *PathQuery) ([]*Path, error)
+}
+
+// knowledgeService validates writes against the latest schema of the
+// workspace, when it has one, and records who stated the facts it creates.
+type knowledgeService struct {
+	repository KnowledgeRepository
+	schemas    SchemaRepository
+	evidence   EvidenceRepository
+}
+
+func NewKnowledgeService(neo4jService neo4j.Neo4jService, mongoService *mongo.MongoService) KnowledgeService {
+	return newKnowledgeService(NewKnowledgeRepository(neo4jService), NewSchemaRepository(mongoService), NewEvidenceRepository(mongoService))
+}
+
+func newKnowledgeService(repository KnowledgeRepository, schemas SchemaRepository, evidence EvidenceRepository) *knowledgeService {
+	return &knowledgeService{
+		repository: repository,
+		schemas:    schemas,
+		evidence:   evidence,
+	}
+}
+
+func (s *knowledgeService) CreateEntity(ctx context.Context, workspaceID, accountID string, req *CreateEntityRequest) (*Entity, error) {
+	entityType, err := normalizeEntityType(req.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	properties, err := normalizeProperties(req.Properties, false)
+	if err != nil {
+		return nil, err
+	}
+
+	entity, err := s.createEntity(ctx, workspaceID, accountID, entityType, properties, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.evidence.Create(ctx, &Evidence{
+		WorkspaceID: workspaceID,
+		FactKind:    FactKindEntity,
+		FactID:      entity.ID,
+		Source:      EvidenceSourceUser,
+		AccountID:   accountID,
+		Confidence:  userEvidenceConfidence,
+		CreatedAt:   time.Now(),
+	}); err != nil {
+		return nil, err
+	}
+
+	return entity, nil
+}
+
+// createEntity checks normalized properties against the schema and stores
+// the entity, with its provenance when it was extracted.
+func (s *knowledgeService) createEntity(ctx context.Context, workspaceID, accountID, entityType string, properties map[string]interface{}, provenance *Provenance) (*Entity, error) {
+	schema, err := s.schemas.GetLatest(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if schema != nil {
+		if err := s.checkEntity(ctx, schema, workspaceID, entityType, properties); err != nil {
+			return nil, err
+		}
+	}
+
+	entity, err := s.repository.CreateEntity(ctx, workspaceID, accountID, entityType, withProvenance(properties, provenance))
+	if err != nil {
+		return nil, err
+	}
+	if entity == nil {
+		return nil, fmt.Errorf("failed to create entity")
+	}
+
+	return entity, nil
+}
+
+func (s *knowledgeService) GetEntity(ctx context.Context, workspaceID, id string) (*Entity, error) {
+	entity, err := s.repository.GetEntity(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+	if entity == nil {
+		return nil, fmt.Errorf("entity not found")
+	}
+
+	return entity, nil
+}
+
+func (s *knowledgeService) ListEntities(ctx context.Context, workspaceID string, query *EntityListQuery) (*EntityPage, error) {
+	if query.Type != "" {
+		entityType, err := normalizeEntityType(query.Type)
+		if err != nil {
+			return nil, err
+		}
+		query.Type = entityType
+	}
+
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = EntityListDefaultLimit
+	}
+	if query.Limit > EntityListMaxLimit {
+		query.Limit = EntityListMaxLimit
+	}
+
+	entities, total, err := s.repository.ListEntities(ctx, workspaceID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := (total + query.Limit - 1) / query.Limit
+
+	return &EntityPage{
+		Data:       entities,
+		Total:      total,
+		Page:       query.Page,
+		Limit:      query.Limit,
+		TotalPages: totalPages,
+		HasNext:    query.Page < totalPages,
+		HasPrev:    query.Page > 1,
+	}, nil
+}
+
+func (s *knowledgeService) UpdateEntity(ctx context.Context, workspaceID, id string, req *UpdateEntityRequest) (*Entity, error) {
+	properties, err := normalizeProperties(req.Properties, true)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := s.schemas.GetLatest(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if schema != nil {
+		current, err := s.GetEntity(ctx, workspaceID, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkEntity(ctx, schema, workspaceID, current.Type, mergeProperties(current.Properties, properties)); err != nil {
+			return nil, err
+		}
+	}
+
+	entity, err := s.repository.UpdateEntity(ctx, workspaceID, id, properties)
+	if err != nil {
+		return nil, err
+	}
+	if entity == nil {
+		return nil, fmt.Errorf("entity not found")
+	}
+
+	return entity, nil
+}
+
+// DeleteEntity deletes the entity together with all of its relations.
+func (s *knowledgeService) DeleteEntity(ctx context.Context, workspaceID, id string) error {
+	deleted, err := s.repository.DeleteEntity(ctx, workspaceID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("entity not found")
+	}
+
+	return s.evidence.DeleteByEntity(ctx, workspaceID, id)
+}
+
+func (s *knowledgeService) CreateRelation(ctx context.Context, workspaceID, accountID string, req *CreateRelationRequest) (*Relation, error) {
+	relationType, err := normalizeRelationType(req.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	properties, err := normalizeProperties(req.Properties, false)
+	if err != nil {
+		return nil, err
+	}
+
+	relation, err := s.createRelation(ctx, workspaceID, accountID, relationType, req.SourceID, req.TargetID, properties, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.evidence.Create(ctx, &Evidence{
+		WorkspaceID:    workspaceID,
+		FactKind:       FactKindRelation,
+		FactID:         relation.ID,
+		SourceEntityID: relation.SourceID,
+		TargetEntityID: relation.TargetID,
+		Source:         EvidenceSourceUser,
+		AccountID:      accountID,
+		Confidence:     userEvidenceConfidence,
+		CreatedAt:      time.Now(),
+	}); err != nil {
+		return nil, err
+	}
+
+	return relation, nil
+}
+
+// createRelation is createEntity for relations.
+func (s *knowledgeService) createRelation(ctx context.Context, workspaceID, accountID, relationType, sourceID, targetID string, properties map[string]interface{}, provenance *Provenance) (*Relation, error) {
+	schema, err := s.schemas.GetLatest(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if schema != nil {
+		if err := s.checkRelation(ctx, schema, workspaceID, relationType, sourceID, targetID, properties); err != nil {
+			return nil, err
+		}
+		if err := s.checkCardinality(ctx, schema, workspaceID, relationType, sourceID, targetID); err != nil {
+			return nil, err
+		}
+	}
+
+	relation, err := s.repository.CreateRelation(ctx, workspaceID, accountID, sourceID, targetID, relationType, withProvenance(properties, provenance))
+	if err != nil {
+		return nil, err
+	}
+	if relation == nil {
+		return nil, fmt.Errorf("source or target entity not found")
+	}
+
+	return relation, nil
+}
+
+func (s *knowledgeService) GetRelation(ctx context.Context, workspaceID, id string) (*Relation, error) {
+	relation, err := s.repository.GetRelation(ctx, workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+	if relation == nil {
+		return nil, fmt.Errorf("relation not found")
+	}
+
+	return relation, nil
+}
+
+func (s *knowledgeService) UpdateRelation(ctx context.Context, workspaceID, id string, req *UpdateRelationRequest) (*Relation, error) {
+	properties, err := normalizeProperties(req.Properties, true)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := s.schemas.GetLatest(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if schema != nil {
+		current, err := s.GetRelation(ctx, workspaceID, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkRelation(ctx, schema, workspaceID, current.Type, current.SourceID, current.TargetID, mergeProperties(current.Properties, properties)); err != nil {
+			return nil, err
+		}
+	}
+
+	relation, err := s.repository.UpdateRelation(ctx, workspaceID, id, properties)
+	if err != nil {
+		return nil, err
+	}
+	if relation == nil {
+		return nil, fmt.Errorf("relation not found")
+	}
+
+	return relation, nil
+}
+
+func (s *knowledgeService) DeleteRelation(ctx context.Context, workspaceID, id string) error {
+	deleted, err := s.repository.DeleteRelation(ctx, workspaceID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("relation not found")
+	}
+
+	return s.evidence.DeleteByFact(ctx, workspaceID, FactKindRelation, id)
+}
+
+// GetNeighbourhood returns the entity and everything reachable from it within
+// the requested number of hops.
+func (s *knowledgeService) GetNeighbourhood(ctx context.Context, workspaceID, id string, query *NeighbourhoodQuery) (*Subgraph, error) {
+	depth := query.Depth
+	if depth <= 0 {
+		depth = NeighbourhoodDefaultDepth
+	}
+	if depth > NeighbourhoodMaxDepth {
+		return nil, fmt.Errorf("invalid depth: at most %d hops", NeighbourhoodMaxDepth)
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = NeighbourhoodDefaultLimit
+	}
+	if limit > NeighbourhoodMaxLimit {
+		limit = NeighbourhoodMaxLimit
+	}
+
+	direction := query.Direction
+	if direction == "" {
+		direction = DirectionBoth
+	}
+
+	relationTypes := []string{}
+	for _, relationType := range strings.Split(query.RelationTypes, ",") {
+		relationType = strings.TrimSpace(relationType)
+		if relationType == "" {
+			continue
+		}
+
+		normalized, err := normalizeRelationType(relationType)
+		if err != nil {
+			return nil, err
+		}
+		relationTypes = append(relationTypes, normalized)
+	}
+
+	graph, err := s.repository.Neighbourhood(ctx, workspaceID, id, depth, direction, relationTypes, limit)
+	if err != nil {
+		return nil, err
+	}
+	if graph == nil {
+		return nil, fmt.Errorf("entity not found")
+	}
+
+	return graph, nil
+}
+
+// FindPaths returns the shortest path between two entities, or every shortest
+// path when query.All is set. No path within MaxDepth hops is not an error.
+func (s *knowledgeService) FindPaths(ctx context.Context, workspaceID string, query *PathQuery) ([]*Path, error) {
+	maxDepth := query.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = PathDefaultMaxDepth
+	}
+	if maxDepth > PathMaxDepth {
+		return nil, fmt.Errorf("invalid max_depth: at most %d hops", PathMaxDepth)
+	}
+
+	from, err := s.GetEntity(ctx, workspaceID, query.From)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.From == query.To {
+		return []*Path{{Entities: []*Entity{from}, Relations: []*Relation{}}}, nil
+	}
+
+	if _, err := s.GetEntity(ctx, workspaceID, query.To); err != nil {
+		return nil, err
+	}
+
+	return s.repository.ShortestPaths(ctx, workspaceID, query.From, query.To, maxDepth, query.All)
+}
+
+func (s *knowledgeService) checkEntity(ctx context.Context, schema *Schema, workspaceID, entityType string, properties map[string]interface{}) error {
+	var ids []string
+	if definition := schema.EntityType(entityType); definition != nil {
+		ids = referencedIDs(definition.Properties, properties)
+	}
+
+	types := map[string]string{}
+	if len(ids) > 0 {
+		var err error
+		if types, err = s.repository.EntityTypes(ctx, workspaceID, ids); err != nil {
+			return err
+		}
+	}
+
+	if problems := entityProblems(schema, entityType, properties, types); len(problems) > 0 {
+		return fmt.Errorf("invalid entity: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+func (s *knowledgeService) checkRelation(ctx context.Context, schema *Schema, workspaceID, relationType, sourceID, targetID string, properties map[string]interface{}) error {
+	ids := []string{sourceID, targetID}
+	if definition := schema.RelationType(relationType); definition != nil {
+		ids = append(ids, referencedIDs(definition.Properties, properties)...)
+	}
+
+	types, err := s.repository.EntityTypes(ctx, workspaceID, ids)
+	if err != nil {
+		return err
+	}
+
+	sourceType, sourceExists := types[sourceID]
+	targetType, targetExists := types[targetID]
+	if !sourceExists || !targetExists {
+		return fmt.Errorf("source or target entity not found")
+	}
+
+	if problems := relationProblems(schema, relationType, sourceType, targetType, properties, types); len(problems) > 0 {
+		return fmt.Errorf("invalid relation: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// checkCardinality rejects a new relation that would give its source or
+// target more relations of the type than the schema allows.
+func (s *knowledgeService) checkCardinality(ctx context.Context, schema *Schema, workspaceID, relationType, sourceID, targetID string) error {
+	definition := schema.RelationType(relationType)
+	if definition == nil {
+		return nil
+	}
+
+	if definition.limitsSources() {
+		count, err := s.repository.CountRelations(ctx, workspaceID, sourceID, relationType, true)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("invalid relation: the source already has a %s relation and %s allows one", relationType, definition.Cardinality)
+		}
+	}
+
+	if definition.limitsTargets() {
+		count, err := s.repository.CountRelations(ctx, workspaceID, targetID, relationType, false)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("invalid relation: the target already has a %s relation and %s allows one", relationType, definition.Cardinality)
+		}
+	}
+
+	return nil
+}
+
+func withProvenance(properties map[string]interface{}, provenance *Provenance) map[string]interface{} {
+	if provenance == nil {
+		return properties
+	}
+
+	merged := provenance.properties()
+	for key, value := range properties {
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// mergeProperties applies an update to current the way Neo4j does: values
+// replace existing ones and nulls remove them.
+func mergeProperties(current, update map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(current)+len(update))
+	for key, value := range current {
+		merged[key] = value
+	}
+	for key, value := range update {
+		if value == nil {
+			delete(merged, key)
+		} else {
+			merged[key] = value
+		}
+	}
+
+	return merged
+}
+
+func normalizeEntityType(entityType string) (string, error) {
+	if !typePattern.MatchString(entityType) || entityType == EntityLabel {
+		return "", fmt.Errorf("invalid entity type %q: use letters, digits and underscores, starting with a letter", entityType)
+	}
+
+	return entityType, nil
+}
+
+// normalizeRelationType upper-cases relation types, following the Neo4j
+// convention, so that works_at and WORKS_AT are the same type.
+func normalizeRelationType(relationType string) (string, error) {
+	if !typePattern.MatchString(relationType) {
+		return "", fmt.Errorf("invalid relation type %q: use letters, digits and underscores, starting with a letter", relationType)
+	}
+
+	return strings.ToUpper(relationType), nil
+}
+
+// normalizeProperties checks that properties can be stored in Neo4j: scalar
+// values or lists of scalars of one kind. Whole numbers become integers. Null
+// values are dropped, or kept to remove the property when removable is set.
+func normalizeProperties(properties map[string]interface{}, removable bool) (map[string]interface{}, error) {
+	if len(properties) > maxProperties {
+		return nil, fmt.Errorf("invalid properties: at most %d properties", maxProperties)
+	}
+
+	normalized := make(map[string]interface{}, len(properties))
+	for key, value := range properties {
+		if !propertyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid property name %q", key)
+		}
+		if reservedProperties[key] {
+			return nil, fmt.Errorf("invalid property name %q: the name is reserved", key)
+		}
+
+		if value == nil {
+			if removable {
+				normalized[key] = nil
+			}
+			continue
+		}
+
+		converted, err := normalizeValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid property %q: %w", key, err)
+		}
+		normalized[key] = converted
+	}
+
+	return normalized, nil
+}
+
+func normalizeValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string, bool, int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v), nil
+		}
+		return v, nil
+	case []interface{}:
+		return normalizeList(v)
+	case map[string]interface{}:
+		return nil, fmt.Errorf("nested objects are not supported")
+	default:
+		return nil, fmt.Errorf("unsupported value of type %T", value)
+	}
+}
+
+// normalizeList accepts lists whose items are all strings, all booleans or
+// all numbers. A list of numbers stays floating point if any item has a
+// fraction.
+func normalizeList(list []interface{}) (interface{}, error) {
+	if len(list) == 0 {
+		return []string{}, nil
+	}
+
+	switch list[0].(type) {
+	case string:
+		items := make([]string, len(list))
+		for i, item := range list {
+			str, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("list items must all have the same type")
+			}
+			items[i] = str
+		}
+		return items, nil
+	case bool:
+		items := make([]bool, len(list))
+		for i, item := range list {
+			b, ok := item.(bool)
+			if !ok {
+				return nil, fmt.Errorf("list items must all have the same type")
+			}
+			items[i] = b
+		}
+		return items, nil
+	case float64:
+		floats := make([]float64, len(list))
+		whole := true
+		for i, item := range list {
+			f, ok := item.(float64)
+			if !ok {
+				return nil, fmt.Errorf("list items must all have the same type")
+			}
+			floats[i] = f
+			whole = whole && f == math.Trunc(f) && math.Abs(f) < 1<<53
+		}
+		if !whole {
+			return floats, nil
+		}
+
+		ints := make([]int64, len(floats))
+		for i, f := range floats {
+			ints[i] = int64(f)
+		}
+		return ints, nil
+	default:
+		return nil, fmt.Errorf("lists may only hold strings, numbers or booleans")
+	}
+}
@@ -0,0 +1,243 @@
+package knowledge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testWorkspaceID = "64b7f0c2e4b0a1a2b3c4d5e6"
+	testAccountID   = "64b7f0c2e4b0a1a2b3c4d5e7"
+)
+
+// setupKnowledgeService returns a service for a workspace without a schema.
+func setupKnowledgeService() (*knowledgeService, *MockKnowledgeRepository) {
+	mockRepo := &MockKnowledgeRepository{}
+	mockSchemas := &MockSchemaRepository{}
+	mockSchemas.On("GetLatest", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	return newKnowledgeService(mockRepo, mockSchemas, setupEvidenceRepository()), mockRepo
+}
+
+// setupEvidenceRepository returns an evidence repository that accepts every
+// write; tests assert on its calls.
+func setupEvidenceRepository() *MockEvidenceRepository {
+	mockEvidence := &MockEvidenceRepository{}
+	mockEvidence.On("Create", mock.Anything, mock.Anything).Return(&Evidence{}, nil).Maybe()
+	mockEvidence.On("DeleteByFact", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	mockEvidence.On("DeleteByEntity", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	return mockEvidence
+}
+
+func TestKnowledgeService_CreateEntity(t *testing.T) {
+	service, mockRepo := setupKnowledgeService()
+
+	mockRepo.On("CreateEntity", mock.Anything, testWorkspaceID, testAccountID, "Person", map[string]interface{}{
+		"name":    "Ada Lovelace",
+		"born":    int64(1815),
+		"height":  1.65,
+		"aliases": []string{"Ada", "Countess of Lovelace"},
+		"years":   []int64{1833, 1843},
+	}).Return(CreateTestEntity(), nil)
+
+	entity, err := service.CreateEntity(context.Background(), testWorkspaceID, testAccountID, &CreateEntityRequest{
+		Type: "Person",
+		Properties: map[string]interface{}{
+			"name":    "Ada Lovelace",
+			"born":    float64(1815),
+			"height":  1.65,
+			"aliases": []interface{}{"Ada", "Countess of Lovelace"},
+			"years":   []interface{}{float64(1833), float64(1843)},
+			"unknown": nil,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Person", entity.Type)
+	mockRepo.AssertExpectations(t)
+	service.evidence.(*MockEvidenceRepository).AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(evidence *Evidence) bool {
+		return evidence.FactKind == FactKindEntity && evidence.FactID == "4:test:1" &&
+			evidence.Source == EvidenceSourceUser && evidence.AccountID == testAccountID && evidence.Confidence == 1
+	}))
+}
+
+func TestKnowledgeService_CreateEntity_Invalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           *CreateEntityRequest
+		errorContains string
+	}{
+		{
+			name:          "type with cypher",
+			req:           &CreateEntityRequest{Type: "Person`) DETACH DELETE (n"},
+			errorContains: "invalid entity type",
+		},
+		{
+			name:          "entity label",
+			req:           &CreateEntityRequest{Type: EntityLabel},
+			errorContains: "invalid entity type",
+		},
+		{
+			name:          "reserved property",
+			req:           &CreateEntityRequest{Type: "Person", Properties: map[string]interface{}{"workspace_id": "someone"}},
+			errorContains: "reserved",
+		},
+		{
+			name:          "nested object",
+			req:           &CreateEntityRequest{Type: "Person", Properties: map[string]interface{}{"address": map[string]interface{}{"city": "London"}}},
+			errorContains: "nested objects",
+		},
+		{
+			name:          "mixed list",
+			req:           &CreateEntityRequest{Type: "Person", Properties: map[string]interface{}{"tags": []interface{}{"a", float64(1)}}},
+			errorContains: "same type",
+		},
+		{
+			name:          "bad property name",
+			req:           &CreateEntityRequest{Type: "Person", Properties: map[string]interface{}{"first name": "Ada"}},
+			errorContains: "invalid property name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockRepo := setupKnowledgeService()
+
+			_, err := service.CreateEntity(context.Background(), testWorkspaceID, testAccountID, tt.req)
+			assert.ErrorContains(t, err, tt.errorContains)
+			mockRepo.AssertNotCalled(t, "CreateEntity", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestKnowledgeService_UpdateEntity_RemovesNullProperties(t *testing.T) {
+	service, mockRepo := setupKnowledgeService()
+
+	mockRepo.On("UpdateEntity", mock.Anything, testWorkspaceID, "4:test:1", map[string]interface{}{
+		"name":  "Ada King",
+		"title": nil,
+	}).Return(CreateTestEntity(), nil).Once()
+	mockRepo.On("UpdateEntity", mock.Anything, testWorkspaceID, "4:test:2", mock.Anything).Return(nil, nil).Once()
+
+	_, err := service.UpdateEntity(context.Background(), testWorkspaceID, "4:test:1", &UpdateEntityRequest{
+		Properties: map[string]interface{}{"name": "Ada King", "title": nil},
+	})
+	require.NoError(t, err)
+
+	_, err = service.UpdateEntity(context.Background(), testWorkspaceID, "4:test:2", &UpdateEntityRequest{
+		Properties: map[string]interface{}{"name": "Someone"},
+	})
+	assert.ErrorContains(t, err, "entity not found")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestKnowledgeService_ListEntities(t *testing.T) {
+	service, mockRepo := setupKnowledgeService()
+
+	mockRepo.On("ListEntities", mock.Anything, testWorkspaceID, &EntityListQuery{
+		Type:  "Person",
+		Page:  2,
+		Limit: EntityListMaxLimit,
+	}).Return([]*Entity{CreateTestEntity()}, int64(101), nil)
+
+	page, err := service.ListEntities(context.Background(), testWorkspaceID, &EntityListQuery{Type: "Person", Page: 2, Limit: 1000})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(2), page.TotalPages)
+	assert.False(t, page.HasNext)
+	assert.True(t, page.HasPrev)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestKnowledgeService_CreateRelation(t *testing.T) {
+	service, mockRepo := setupKnowledgeService()
+
+	mockRepo.On("CreateRelation", mock.Anything, testWorkspaceID, testAccountID, "4:test:1", "4:test:2", "WORKS_AT", map[string]interface{}{"since": int64(2020)}).
+		Return(&Relation{ID: "5:test:1", Type: "WORKS_AT", SourceID: "4:test:1", TargetID: "4:test:2"}, nil).Once()
+	mockRepo.On("CreateRelation", mock.Anything, testWorkspaceID, testAccountID, "4:test:1", "4:other:9", "KNOWS", map[string]interface{}{}).
+		Return(nil, nil).Once()
+
+	relation, err := service.CreateRelation(context.Background(), testWorkspaceID, testAccountID, &CreateRelationRequest{
+		SourceID:   "4:test:1",
+		TargetID:   "4:test:2",
+		Type:       "works_at",
+		Properties: map[string]interface{}{"since": float64(2020)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "WORKS_AT", relation.Type)
+	service.evidence.(*MockEvidenceRepository).AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(evidence *Evidence) bool {
+		return evidence.FactKind == FactKindRelation && evidence.FactID == "5:test:1" &&
+			evidence.SourceEntityID == "4:test:1" && evidence.TargetEntityID == "4:test:2"
+	}))
+
+	_, err = service.CreateRelation(context.Background(), testWorkspaceID, testAccountID, &CreateRelationRequest{
+		SourceID: "4:test:1",
+		TargetID: "4:other:9",
+		Type:     "KNOWS",
+	})
+	assert.ErrorContains(t, err, "not found")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestKnowledgeService_GetNeighbourhood(t *testing.T) {
+	service, mockRepo := setupKnowledgeService()
+
+	mockRepo.On("Neighbourhood", mock.Anything, testWorkspaceID, "4:test:1", 2, DirectionOutgoing, []string{"WORKS_AT", "KNOWS"}, NeighbourhoodDefaultLimit).
+		Return(&Subgraph{Entities: []*Entity{CreateTestEntity()}}, nil).Once()
+	mockRepo.On("Neighbourhood", mock.Anything, testWorkspaceID, "4:missing:1", NeighbourhoodDefaultDepth, DirectionBoth, []string{}, NeighbourhoodDefaultLimit).
+		Return(nil, nil).Once()
+
+	graph, err := service.GetNeighbourhood(context.Background(), testWorkspaceID, "4:test:1", &NeighbourhoodQuery{
+		Depth:         2,
+		Direction:     DirectionOutgoing,
+		RelationTypes: "works_at, KNOWS",
+	})
+	require.NoError(t, err)
+	assert.Len(t, graph.Entities, 1)
+
+	_, err = service.GetNeighbourhood(context.Background(), testWorkspaceID, "4:missing:1", &NeighbourhoodQuery{})
+	assert.ErrorContains(t, err, "entity not found")
+
+	_, err = service.GetNeighbourhood(context.Background(), testWorkspaceID, "4:test:1", &NeighbourhoodQuery{RelationTypes: "KNOWS]->()"})
+	assert.ErrorContains(t, err, "invalid relation type")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestKnowledgeService_FindPaths(t *testing.T) {
+	service, mockRepo := setupKnowledgeService()
+	from := CreateTestEntity()
+	to := CreateTestEntity(func(e *Entity) { e.ID = "4:test:2" })
+
+	mockRepo.On("GetEntity", mock.Anything, testWorkspaceID, from.ID).Return(from, nil)
+	mockRepo.On("GetEntity", mock.Anything, testWorkspaceID, to.ID).Return(to, nil)
+	mockRepo.On("GetEntity", mock.Anything, testWorkspaceID, "4:missing:1").Return(nil, nil)
+	mockRepo.On("ShortestPaths", mock.Anything, testWorkspaceID, from.ID, to.ID, PathDefaultMaxDepth, true).
+		Return([]*Path{{Length: 1, Entities: []*Entity{from, to}}}, nil)
+
+	paths, err := service.FindPaths(context.Background(), testWorkspaceID, &PathQuery{From: from.ID, To: to.ID, All: true})
+	require.NoError(t, err)
+	assert.Len(t, paths, 1)
+
+	paths, err = service.FindPaths(context.Background(), testWorkspaceID, &PathQuery{From: from.ID, To: from.ID})
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	assert.Equal(t, 0, paths[0].Length)
+
+	_, err = service.FindPaths(context.Background(), testWorkspaceID, &PathQuery{From: from.ID, To: "4:missing:1"})
+	assert.ErrorContains(t, err, "entity not found")
+
+	_, err = service.FindPaths(context.Background(), testWorkspaceID, &PathQuery{From: from.ID, To: to.ID, MaxDepth: 10})
+	assert.ErrorContains(t, err, "invalid max_depth")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestKnowledgeService_DeleteEntity_Evidence(t *testing.T) {
+	service, mockRepo := setupKnowledgeService()
+
+	mockRepo.On("DeleteEntity", mock.Anything, testWorkspaceID, "4:test:1").Return(true, nil)
+
+	require.NoError(t, service.DeleteEntity(context.Background(), testWorkspaceID, "4:test:1"))
+	service.evidence.(*MockEvidenceRepository).AssertCalled(t, "DeleteByEntity", mock.Anything, testWorkspaceID, "4:test:1")
+}
@@ -0,0 +1,709 @@
+package knowledge
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/module/document"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+type MockKnowledgeRepository struct {
+	mock.Mock
+}
+
+func (m *MockKnowledgeRepository) CreateEntity(ctx context.Context, workspaceID, createdBy, entityType string, properties map[string]interface{}) (*Entity, error) {
+	args := m.Called(ctx, workspaceID, createdBy, entityType, properties)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Entity), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) GetEntity(ctx context.Context, workspaceID, id string) (*Entity, error) {
+	args := m.Called(ctx, workspaceID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Entity), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) ListEntities(ctx context.Context, workspaceID string, query *EntityListQuery) ([]*Entity, int64, error) {
+	args := m.Called(ctx, workspaceID, query)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*Entity), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockKnowledgeRepository) UpdateEntity(ctx context.Context, workspaceID, id string, properties map[string]interface{}) (*Entity, error) {
+	args := m.Called(ctx, workspaceID, id, properties)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Entity), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) DeleteEntity(ctx context.Context, workspaceID, id string) (bool, error) {
+	args := m.Called(ctx, workspaceID, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) CreateRelation(ctx context.Context, workspaceID, createdBy, sourceID, targetID, relationType string, properties map[string]interface{}) (*Relation, error) {
+	args := m.Called(ctx, workspaceID, createdBy, sourceID, targetID, relationType, properties)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Relation), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) GetRelation(ctx context.Context, workspaceID, id string) (*Relation, error) {
+	args := m.Called(ctx, workspaceID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Relation), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) UpdateRelation(ctx context.Context, workspaceID, id string, properties map[string]interface{}) (*Relation, error) {
+	args := m.Called(ctx, workspaceID, id, properties)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Relation), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) DeleteRelation(ctx context.Context, workspaceID, id string) (bool, error) {
+	args := m.Called(ctx, workspaceID, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) Neighbourhood(ctx context.Context, workspaceID, id string, depth int, direction string, relationTypes []string, limit int) (*Subgraph, error) {
+	args := m.Called(ctx, workspaceID, id, depth, direction, relationTypes, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Subgraph), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) ShortestPaths(ctx context.Context, workspaceID, fromID, toID string, maxDepth int, all bool) ([]*Path, error) {
+	args := m.Called(ctx, workspaceID, fromID, toID, maxDepth, all)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*Path), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) FindEntityByName(ctx context.Context, workspaceID, entityType, name string) (*Entity, error) {
+	args := m.Called(ctx, workspaceID, entityType, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Entity), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) FindRelation(ctx context.Context, workspaceID, sourceID, targetID, relationType string) (*Relation, error) {
+	args := m.Called(ctx, workspaceID, sourceID, targetID, relationType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Relation), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) EntityTypes(ctx context.Context, workspaceID string, ids []string) (map[string]string, error) {
+	args := m.Called(ctx, workspaceID, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) CountRelations(ctx context.Context, workspaceID, entityID, relationType string, outgoing bool) (int64, error) {
+	args := m.Called(ctx, workspaceID, entityID, relationType, outgoing)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) Degree(ctx context.Context, workspaceID, entityID string) (int64, error) {
+	args := m.Called(ctx, workspaceID, entityID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) SnapshotEntity(ctx context.Context, workspaceID, id string) (*StoredEntity, []*StoredRelation, error) {
+	args := m.Called(ctx, workspaceID, id)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*StoredEntity), args.Get(1).([]*StoredRelation), args.Error(2)
+}
+
+func (m *MockKnowledgeRepository) RestoreEntity(ctx context.Context, workspaceID string, entity *StoredEntity) (string, error) {
+	args := m.Called(ctx, workspaceID, entity)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) RestoreRelation(ctx context.Context, workspaceID string, relation *StoredRelation) (string, error) {
+	args := m.Called(ctx, workspaceID, relation)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) ReplaceEntityProperties(ctx context.Context, workspaceID, id string, properties map[string]interface{}) error {
+	args := m.Called(ctx, workspaceID, id, properties)
+	return args.Error(0)
+}
+
+func (m *MockKnowledgeRepository) SetProvenance(ctx context.Context, workspaceID string, kind FactKind, id string, provenance *Provenance) error {
+	args := m.Called(ctx, workspaceID, kind, id, provenance)
+	return args.Error(0)
+}
+
+func (m *MockKnowledgeRepository) ScanEntities(ctx context.Context, workspaceID, after string, limit int) ([]*Entity, error) {
+	args := m.Called(ctx, workspaceID, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*Entity), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) ScanRelations(ctx context.Context, workspaceID, after string, limit int) ([]*ScannedRelation, error) {
+	args := m.Called(ctx, workspaceID, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*ScannedRelation), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) RelationFanOut(ctx context.Context, workspaceID, relationType string, outgoing bool, limit int) ([]*RelationCount, error) {
+	args := m.Called(ctx, workspaceID, relationType, outgoing, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*RelationCount), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) ImportEntities(ctx context.Context, workspaceID, createdBy string, batch *ImportEntityBatch) (int64, error) {
+	args := m.Called(ctx, workspaceID, createdBy, batch)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) ImportRelations(ctx context.Context, workspaceID, createdBy string, batch *ImportRelationBatch) (int64, error) {
+	args := m.Called(ctx, workspaceID, createdBy, batch)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) MatchImportEndpoints(ctx context.Context, workspaceID string, batch *ImportRelationBatch) ([]*ImportEndpointMatch, error) {
+	args := m.Called(ctx, workspaceID, batch)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*ImportEndpointMatch), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) DeleteWorkspace(ctx context.Context, workspaceID string) error {
+	args := m.Called(ctx, workspaceID)
+	return args.Error(0)
+}
+
+func CreateTestEntity(overrides ...func(*Entity)) *Entity {
+	now := time.Now()
+	entity := &Entity{
+		ID:         "4:test:1",
+		Type:       "Person",
+		Properties: map[string]interface{}{"name": "Ada Lovelace"},
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	for _, override := range overrides {
+		override(entity)
+	}
+
+	return entity
+}
+
+type MockSchemaRepository struct {
+	mock.Mock
+}
+
+func (m *MockSchemaRepository) Create(ctx context.Context, schema *Schema) (*Schema, error) {
+	args := m.Called(ctx, schema)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Schema), args.Error(1)
+}
+
+func (m *MockSchemaRepository) GetLatest(ctx context.Context, workspaceID string) (*Schema, error) {
+	args := m.Called(ctx, workspaceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Schema), args.Error(1)
+}
+
+func (m *MockSchemaRepository) GetVersion(ctx context.Context, workspaceID string, version int) (*Schema, error) {
+	args := m.Called(ctx, workspaceID, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Schema), args.Error(1)
+}
+
+func (m *MockSchemaRepository) ListVersions(ctx context.Context, workspaceID string) ([]*Schema, error) {
+	args := m.Called(ctx, workspaceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*Schema), args.Error(1)
+}
+
+func (m *MockSchemaRepository) DeleteByWorkspace(ctx context.Context, workspaceID string) error {
+	args := m.Called(ctx, workspaceID)
+	return args.Error(0)
+}
+
+// CreateTestSchema returns a schema with Person and Organization entities and
+// a many-to-one WORKS_AT relation between them.
+func CreateTestSchema(overrides ...func(*Schema)) *Schema {
+	schema := &Schema{
+		WorkspaceID: "64b7f0c2e4b0a1a2b3c4d5e6",
+		Version:     1,
+		EntityTypes: []EntityTypeDefinition{
+			{
+				Name: "Person",
+				Properties: []PropertyDefinition{
+					{Name: "name", Type: PropertyTypeString, Required: true},
+					{Name: "born", Type: PropertyTypeDate},
+					{Name: "status", Type: PropertyTypeEnum, Values: []string{"active", "retired"}},
+					{Name: "employer", Type: PropertyTypeReference, Target: "Organization"},
+				},
+			},
+			{
+				Name: "Organization",
+				Properties: []PropertyDefinition{
+					{Name: "name", Type: PropertyTypeString, Required: true},
+					{Name: "employees", Type: PropertyTypeNumber},
+				},
+			},
+		},
+		RelationTypes: []RelationTypeDefinition{
+			{
+				Name:        "WORKS_AT",
+				SourceTypes: []string{"Person"},
+				TargetTypes: []string{"Organization"},
+				Cardinality: CardinalityManyToOne,
+				Properties: []PropertyDefinition{
+					{Name: "since", Type: PropertyTypeNumber},
+				},
+			},
+		},
+		CreatedAt: time.Now(),
+	}
+
+	for _, override := range overrides {
+		override(schema)
+	}
+
+	return schema
+}
+
+type MockRuleSetRepository struct {
+	mock.Mock
+}
+
+func (m *MockRuleSetRepository) Create(ctx context.Context, rules *RuleSet) (*RuleSet, error) {
+	args := m.Called(ctx, rules)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*RuleSet), args.Error(1)
+}
+
+func (m *MockRuleSetRepository) GetLatest(ctx context.Context, workspaceID string) (*RuleSet, error) {
+	args := m.Called(ctx, workspaceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*RuleSet), args.Error(1)
+}
+
+func (m *MockRuleSetRepository) DeleteByWorkspace(ctx context.Context, workspaceID string) error {
+	args := m.Called(ctx, workspaceID)
+	return args.Error(0)
+}
+
+type MockCandidateRepository struct {
+	mock.Mock
+}
+
+func (m *MockCandidateRepository) Create(ctx context.Context, candidate *Candidate) (*Candidate, error) {
+	args := m.Called(ctx, candidate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Candidate), args.Error(1)
+}
+
+func (m *MockCandidateRepository) GetByID(ctx context.Context, workspaceID string, id primitive.ObjectID) (*Candidate, error) {
+	args := m.Called(ctx, workspaceID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Candidate), args.Error(1)
+}
+
+func (m *MockCandidateRepository) List(ctx context.Context, filter bson.M, pagination mongo.PaginationOptions) (*mongo.PaginatedResult[Candidate], error) {
+	args := m.Called(ctx, filter, pagination)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*mongo.PaginatedResult[Candidate]), args.Error(1)
+}
+
+func (m *MockCandidateRepository) ListReviewed(ctx context.Context, workspaceID, documentID string) ([]*Candidate, error) {
+	args := m.Called(ctx, workspaceID, documentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*Candidate), args.Error(1)
+}
+
+func (m *MockCandidateRepository) ListPendingRelations(ctx context.Context, workspaceID, entityCandidateID string) ([]*Candidate, error) {
+	args := m.Called(ctx, workspaceID, entityCandidateID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*Candidate), args.Error(1)
+}
+
+func (m *MockCandidateRepository) Review(ctx context.Context, workspaceID string, id primitive.ObjectID, status CandidateStatus, factID, reviewedBy string) (*Candidate, error) {
+	args := m.Called(ctx, workspaceID, id, status, factID, reviewedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Candidate), args.Error(1)
+}
+
+func (m *MockCandidateRepository) DeletePending(ctx context.Context, workspaceID, documentID string) error {
+	args := m.Called(ctx, workspaceID, documentID)
+	return args.Error(0)
+}
+
+func (m *MockCandidateRepository) DeleteByDocument(ctx context.Context, workspaceID, documentID string) error {
+	args := m.Called(ctx, workspaceID, documentID)
+	return args.Error(0)
+}
+
+func (m *MockCandidateRepository) DeleteByWorkspace(ctx context.Context, workspaceID string) error {
+	args := m.Called(ctx, workspaceID)
+	return args.Error(0)
+}
+
+type MockExtractionSettingsRepository struct {
+	mock.Mock
+}
+
+func (m *MockExtractionSettingsRepository) Get(ctx context.Context, workspaceID string) (*ExtractionSettings, error) {
+	args := m.Called(ctx, workspaceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ExtractionSettings), args.Error(1)
+}
+
+func (m *MockExtractionSettingsRepository) Set(ctx context.Context, settings *ExtractionSettings) (*ExtractionSettings, error) {
+	args := m.Called(ctx, settings)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ExtractionSettings), args.Error(1)
+}
+
+func (m *MockExtractionSettingsRepository) DeleteByWorkspace(ctx context.Context, workspaceID string) error {
+	args := m.Called(ctx, workspaceID)
+	return args.Error(0)
+}
+
+type MockDocumentTextSource struct {
+	mock.Mock
+}
+
+func (m *MockDocumentTextSource) GetResult(ctx context.Context, workspaceID, documentID string, version int) (*document.ExtractionResult, error) {
+	args := m.Called(ctx, workspaceID, documentID, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*document.ExtractionResult), args.Error(1)
+}
+
+// CreateTestCandidate returns a pending Person candidate for Ada Lovelace.
+func CreateTestCandidate(overrides ...func(*Candidate)) *Candidate {
+	now := time.Now()
+	candidate := &Candidate{
+		ID:               primitive.NewObjectID(),
+		WorkspaceID:      "64b7f0c2e4b0a1a2b3c4d5e6",
+		DocumentID:       "64b7f0c2e4b0a1a2b3c4d5e8",
+		DocumentVersion:  1,
+		Kind:             CandidateKindEntity,
+		Status:           CandidateStatusPending,
+		Key:              entityKey("Person", "Ada Lovelace"),
+		Type:             "Person",
+		Name:             "Ada Lovelace",
+		Mentions:         []Span{{Start: 0, End: 12}},
+		Rule:             "gazetteer:Person",
+		Confidence:       1,
+		Extractor:        RuleExtractor,
+		ExtractorVersion: RuleExtractorVersion,
+		RulesVersion:     1,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+
+	for _, override := range overrides {
+		override(candidate)
+	}
+
+	return candidate
+}
+
+type MockEvidenceRepository struct {
+	mock.Mock
+}
+
+func (m *MockEvidenceRepository) Create(ctx context.Context, evidence *Evidence) (*Evidence, error) {
+	args := m.Called(ctx, evidence)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Evidence), args.Error(1)
+}
+
+func (m *MockEvidenceRepository) ListByFact(ctx context.Context, workspaceID string, kind FactKind, factID string) ([]*Evidence, error) {
+	args := m.Called(ctx, workspaceID, kind, factID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*Evidence), args.Error(1)
+}
+
+func (m *MockEvidenceRepository) ListByDocument(ctx context.Context, workspaceID, documentID string) ([]*Evidence, error) {
+	args := m.Called(ctx, workspaceID, documentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*Evidence), args.Error(1)
+}
+
+func (m *MockEvidenceRepository) IDsByFact(ctx context.Context, workspaceID string, kind FactKind, factID string) ([]primitive.ObjectID, error) {
+	args := m.Called(ctx, workspaceID, kind, factID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]primitive.ObjectID), args.Error(1)
+}
+
+func (m *MockEvidenceRepository) Reassign(ctx context.Context, workspaceID string, ids []primitive.ObjectID, kind FactKind, factID, sourceEntityID, targetEntityID string) error {
+	args := m.Called(ctx, workspaceID, ids, kind, factID, sourceEntityID, targetEntityID)
+	return args.Error(0)
+}
+
+func (m *MockEvidenceRepository) DeleteByDocument(ctx context.Context, workspaceID, documentID string) (int64, error) {
+	args := m.Called(ctx, workspaceID, documentID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockEvidenceRepository) DeleteByFact(ctx context.Context, workspaceID string, kind FactKind, factID string) error {
+	args := m.Called(ctx, workspaceID, kind, factID)
+	return args.Error(0)
+}
+
+func (m *MockEvidenceRepository) DeleteByEntity(ctx context.Context, workspaceID, entityID string) error {
+	args := m.Called(ctx, workspaceID, entityID)
+	return args.Error(0)
+}
+
+func (m *MockEvidenceRepository) DeleteByWorkspace(ctx context.Context, workspaceID string) error {
+	args := m.Called(ctx, workspaceID)
+	return args.Error(0)
+}
+
+type MockResolutionSettingsRepository struct {
+	mock.Mock
+}
+
+func (m *MockResolutionSettingsRepository) Get(ctx context.Context, workspaceID string) (*ResolutionSettings, error) {
+	args := m.Called(ctx, workspaceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ResolutionSettings), args.Error(1)
+}
+
+func (m *MockResolutionSettingsRepository) Set(ctx context.Context, settings *ResolutionSettings) (*ResolutionSettings, error) {
+	args := m.Called(ctx, settings)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ResolutionSettings), args.Error(1)
+}
+
+func (m *MockResolutionSettingsRepository) DeleteByWorkspace(ctx context.Context, workspaceID string) error {
+	args := m.Called(ctx, workspaceID)
+	return args.Error(0)
+}
+
+type MockMergeRepository struct {
+	mock.Mock
+}
+
+func (m *MockMergeRepository) Create(ctx context.Context, record *MergeRecord) (*MergeRecord, error) {
+	args := m.Called(ctx, record)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*MergeRecord), args.Error(1)
+}
+
+func (m *MockMergeRepository) GetByID(ctx context.Context, workspaceID string, id primitive.ObjectID) (*MergeRecord, error) {
+	args := m.Called(ctx, workspaceID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*MergeRecord), args.Error(1)
+}
+
+func (m *MockMergeRepository) List(ctx context.Context, filter bson.M, pagination mongo.PaginationOptions) (*mongo.PaginatedResult[MergeRecord], error) {
+	args := m.Called(ctx, filter, pagination)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*mongo.PaginatedResult[MergeRecord]), args.Error(1)
+}
+
+func (m *MockMergeRepository) Save(ctx context.Context, record *MergeRecord) (*MergeRecord, error) {
+	args := m.Called(ctx, record)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*MergeRecord), args.Error(1)
+}
+
+func (m *MockMergeRepository) MarkUndone(ctx context.Context, record *MergeRecord) (*MergeRecord, error) {
+	args := m.Called(ctx, record)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*MergeRecord), args.Error(1)
+}
+
+func (m *MockMergeRepository) DeleteByWorkspace(ctx context.Context, workspaceID string) error {
+	args := m.Called(ctx, workspaceID)
+	return args.Error(0)
+}
+
+type MockImportJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockImportJobRepository) Create(ctx context.Context, job *ImportJob) (*ImportJob, error) {
+	args := m.Called(ctx, job)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ImportJob), args.Error(1)
+}
+
+func (m *MockImportJobRepository) GetByID(ctx context.Context, workspaceID string, id primitive.ObjectID) (*ImportJob, error) {
+	args := m.Called(ctx, workspaceID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ImportJob), args.Error(1)
+}
+
+func (m *MockImportJobRepository) List(ctx context.Context, filter bson.M, pagination mongo.PaginationOptions) (*mongo.PaginatedResult[ImportJob], error) {
+	args := m.Called(ctx, filter, pagination)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*mongo.PaginatedResult[ImportJob]), args.Error(1)
+}
+
+func (m *MockImportJobRepository) Claim(ctx context.Context, now time.Time, lease time.Duration) (*ImportJob, error) {
+	args := m.Called(ctx, now, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ImportJob), args.Error(1)
+}
+
+func (m *MockImportJobRepository) UpdateAttempt(ctx context.Context, id primitive.ObjectID, attempts int, update bson.M) (*ImportJob, error) {
+	args := m.Called(ctx, id, attempts, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ImportJob), args.Error(1)
+}
+
+func (m *MockImportJobRepository) DeleteByWorkspace(ctx context.Context, workspaceID string) error {
+	args := m.Called(ctx, workspaceID)
+	return args.Error(0)
+}
+
+type MockImportStorage struct {
+	mock.Mock
+}
+
+func (m *MockImportStorage) Put(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error {
+	args := m.Called(ctx, key, reader, size, contentType)
+	return args.Error(0)
+}
+
+func (m *MockImportStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockImportStorage) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockImportStorage) DeletePrefix(ctx context.Context, prefix string) error {
+	args := m.Called(ctx, prefix)
+	return args.Error(0)
+}
+
+// CreateTestEvidence returns evidence for a relation, found in a document by
+// the rule extractor.
+func CreateTestEvidence(overrides ...func(*Evidence)) *Evidence {
+	evidence := &Evidence{
+		ID:               primitive.NewObjectID(),
+		WorkspaceID:      "64b7f0c2e4b0a1a2b3c4d5e6",
+		FactKind:         FactKindRelation,
+		FactID:           "5:test:1",
+		Source:           EvidenceSourceDocument,
+		DocumentID:       "64b7f0c2e4b0a1a2b3c4d5e8",
+		DocumentVersion:  1,
+		Spans:            []Span{{Start: 0, End: 26}},
+		Snippet:          "Ada Lovelace works at Acme.",
+		Extractor:        RuleExtractor,
+		ExtractorVersion: RuleExtractorVersion,
+		AccountID:        "64b7f0c2e4b0a1a2b3c4d5e7",
+		Confidence:       0.6,
+		CreatedAt:        time.Now(),
+	}
+
+	for _, override := range overrides {
+		override(evidence)
+	}
+
+	return evidence
+}
@@ -0,0 +1,230 @@
+package workspace
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/binding"
+)
+
+type WorkspaceHandler struct {
+	service WorkspaceService
+}
+
+func NewWorkspaceHandler(service WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		service: service,
+	}
+}
+
+// CreateWorkspace godoc
+// @Summary Create a workspace
+// @Description Create a workspace owned by the current account, which becomes its first admin.
+// @Tags workspaces
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateWorkspaceRequest true "Workspace name and description"
+// @Success 201 {object} map[string]interface{} "Workspace created successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /workspaces [post]
+func (h *WorkspaceHandler) CreateWorkspace(c *fiber.Ctx) error {
+	var req CreateWorkspaceRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	workspace, err := h.service.CreateWorkspace(c.Context(), accountIDFrom(c), &req)
+	if err != nil {
+		return workspaceError(c, "Failed to create workspace", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Workspace created successfully",
+		"data":    workspace,
+	})
+}
+
+// ListWorkspaces godoc
+// @Summary List workspaces
+// @Description List the workspaces the current account is a member of.
+// @Tags workspaces
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Workspaces retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /workspaces [get]
+func (h *WorkspaceHandler) ListWorkspaces(c *fiber.Ctx) error {
+	workspaces, err := h.service.ListWorkspaces(c.Context(), accountIDFrom(c))
+	if err != nil {
+		return workspaceError(c, "Failed to list workspaces", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Workspaces retrieved successfully",
+		"data":    workspaces,
+	})
+}
+
+// GetWorkspace godoc
+// @Summary Get a workspace
+// @Description Get a workspace with its members and the current account's role.
+// @Tags workspaces
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Success 200 {object} map[string]interface{} "Workspace retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Workspace not found"
+// @Router /workspaces/{workspaceId} [get]
+func (h *WorkspaceHandler) GetWorkspace(c *fiber.Ctx) error {
+	workspace, err := h.service.GetWorkspace(c.Context(), accountIDFrom(c), c.Params("workspaceId"))
+	if err != nil {
+		return workspaceError(c, "Failed to get workspace", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Workspace retrieved successfully",
+		"data":    workspace,
+	})
+}
+
+// UpdateWorkspace godoc
+// @Summary Update a workspace
+// @Description Rename a workspace or change its description. Requires the admin role.
+// @Tags workspaces
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param request body UpdateWorkspaceRequest true "Fields to change"
+// @Success 200 {object} map[string]interface{} "Workspace updated successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Workspace not found"
+// @Router /workspaces/{workspaceId} [patch]
+func (h *WorkspaceHandler) UpdateWorkspace(c *fiber.Ctx) error {
+	var req UpdateWorkspaceRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	workspace, err := h.service.UpdateWorkspace(c.Context(), accountIDFrom(c), c.Params("workspaceId"), &req)
+	if err != nil {
+		return workspaceError(c, "Failed to update workspace", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Workspace updated successfully",
+		"data":    workspace,
+	})
+}
+
+// DeleteWorkspace godoc
+// @Summary Delete a workspace
+// @Description Delete a workspace with its knowledge graph and pending invitations. Only the owner can delete a workspace.
+// @Tags workspaces
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Success 200 {object} map[string]interface{} "Workspace deleted successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Workspace not found"
+// @Router /workspaces/{workspaceId} [delete]
+func (h *WorkspaceHandler) DeleteWorkspace(c *fiber.Ctx) error {
+	if err := h.service.DeleteWorkspace(c.Context(), accountIDFrom(c), c.Params("workspaceId")); err != nil {
+		return workspaceError(c, "Failed to delete workspace", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Workspace deleted successfully",
+	})
+}
+
+// UpdateMemberRole godoc
+// @Summary Change a member's role
+// @Description Change the role of a workspace member. Requires the admin role; the owner's role cannot be changed.
+// @Tags workspaces
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param accountId path string true "Member account ID"
+// @Param request body UpdateMemberRequest true "New role"
+// @Success 200 {object} map[string]interface{} "Member updated successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Member not found"
+// @Router /workspaces/{workspaceId}/members/{accountId} [patch]
+func (h *WorkspaceHandler) UpdateMemberRole(c *fiber.Ctx) error {
+	var req UpdateMemberRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	workspace, err := h.service.UpdateMemberRole(c.Context(), accountIDFrom(c), c.Params("workspaceId"), c.Params("accountId"), &req)
+	if err != nil {
+		return workspaceError(c, "Failed to update member", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Member updated successfully",
+		"data":    workspace,
+	})
+}
+
+// RemoveMember godoc
+// @Summary Remove a member
+// @Description Remove a member from a workspace. Members can remove themselves; removing others requires the admin role.
+// @Tags workspaces
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param accountId path string true "Member account ID"
+// @Success 200 {object} map[string]interface{} "Member removed successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Member not found"
+// @Router /workspaces/{workspaceId}/members/{accountId} [delete]
+func (h *WorkspaceHandler) RemoveMember(c *fiber.Ctx) error {
+	if err := h.service.RemoveMember(c.Context(), accountIDFrom(c), c.Params("workspaceId"), c.Params("accountId")); err != nil {
+		return workspaceError(c, "Failed to remove member", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Member removed successfully",
+	})
+}
+
+func accountIDFrom(c *fiber.Ctx) string {
+	accountID, _ := c.Locals("account_id").(string)
+	return accountID
+}
+
+func workspaceError(c *fiber.Ctx, title string, err error) error {
+	statusCode := fiber.StatusInternalServerError
+	if strings.Contains(err.Error(), "not found") {
+		statusCode = fiber.StatusNotFound
+	} else if strings.HasPrefix(err.Error(), "only ") {
+		statusCode = fiber.StatusForbidden
+	} else if strings.Contains(err.Error(), "already") {
+		statusCode = fiber.StatusConflict
+	} else if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "cannot") {
+		statusCode = fiber.StatusBadRequest
+	}
+
+	return c.Status(statusCode).JSON(fiber.Map{
+		"error":   title,
+		"message": err.Error(),
+	})
+}
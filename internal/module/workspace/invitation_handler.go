@@ -0,0 +1,128 @@
+package workspace
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/binding"
+)
+
+type InvitationHandler struct {
+	service InvitationService
+}
+
+func NewInvitationHandler(service InvitationService) *InvitationHandler {
+	return &InvitationHandler{
+		service: service,
+	}
+}
+
+// InviteMember godoc
+// @Summary Invite a member
+// @Description Email an invitation to join the workspace with a role. Inviting the same address again replaces the earlier invitation. Requires the admin role.
+// @Tags workspaces
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param request body InviteMemberRequest true "Email address and role"
+// @Success 201 {object} map[string]interface{} "Invitation sent successfully"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Workspace not found"
+// @Router /workspaces/{workspaceId}/invitations [post]
+func (h *InvitationHandler) InviteMember(c *fiber.Ctx) error {
+	var req InviteMemberRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	invitation, err := h.service.InviteMember(c.Context(), accountIDFrom(c), c.Params("workspaceId"), &req)
+	if err != nil {
+		return workspaceError(c, "Failed to invite member", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Invitation sent successfully",
+		"data":    invitation,
+	})
+}
+
+// ListInvitations godoc
+// @Summary List pending invitations
+// @Description List the invitations of a workspace that have not been accepted or expired. Requires the admin role.
+// @Tags workspaces
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Success 200 {object} map[string]interface{} "Invitations retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Workspace not found"
+// @Router /workspaces/{workspaceId}/invitations [get]
+func (h *InvitationHandler) ListInvitations(c *fiber.Ctx) error {
+	invitations, err := h.service.ListInvitations(c.Context(), c.Params("workspaceId"))
+	if err != nil {
+		return workspaceError(c, "Failed to list invitations", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Invitations retrieved successfully",
+		"data":    invitations,
+	})
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke an invitation
+// @Description Revoke a pending invitation so that its link no longer works. Requires the admin role.
+// @Tags workspaces
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workspaceId path string true "Workspace ID"
+// @Param invitationId path string true "Invitation ID"
+// @Success 200 {object} map[string]interface{} "Invitation revoked successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Invitation not found"
+// @Router /workspaces/{workspaceId}/invitations/{invitationId} [delete]
+func (h *InvitationHandler) RevokeInvitation(c *fiber.Ctx) error {
+	if err := h.service.RevokeInvitation(c.Context(), c.Params("workspaceId"), c.Params("invitationId")); err != nil {
+		return workspaceError(c, "Failed to revoke invitation", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Invitation revoked successfully",
+	})
+}
+
+// AcceptInvitation godoc
+// @Summary Accept an invitation
+// @Description Join a workspace with the token from an invitation email. The current account must use the invited email address.
+// @Tags workspaces
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body AcceptInvitationRequest true "Invitation token"
+// @Success 200 {object} map[string]interface{} "Invitation accepted successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid or expired invitation"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 409 {object} map[string]interface{} "Already a member"
+// @Router /workspaces/invitations/accept [post]
+func (h *InvitationHandler) AcceptInvitation(c *fiber.Ctx) error {
+	var req AcceptInvitationRequest
+	if err := binding.Body(c, &req); err != nil {
+		return binding.Respond(c, err)
+	}
+
+	workspace, err := h.service.AcceptInvitation(c.Context(), accountIDFrom(c), &req)
+	if err != nil {
+		return workspaceError(c, "Failed to accept invitation", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Invitation accepted successfully",
+		"data":    workspace,
+	})
+}
@@ -0,0 +1,53 @@
+package workspace
+
+import (
+	"html"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/module/account"
+)
+
+const (
+	InvitationCollectionName = "workspace_invitations"
+	InvitationExpiry         = 7 * 24 * time.Hour
+	InvitationTokenSize      = 32
+)
+
+// Invitation lets whoever signs in with Email join a workspace with Role.
+// Only a hash of the token is stored; the token itself is emailed.
+type Invitation struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	WorkspaceID string             `json:"workspace_id" bson:"workspace_id"`
+	Email       string             `json:"email" bson:"email"`
+	Role        Role               `json:"role" bson:"role"`
+	TokenHash   string             `json:"-" bson:"token_hash"`
+	InvitedBy   string             `json:"invited_by" bson:"invited_by"`
+	ExpiresAt   time.Time          `json:"expires_at" bson:"expires_at"`
+	AcceptedAt  *time.Time         `json:"accepted_at,omitempty" bson:"accepted_at,omitempty"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
+
+func (i *Invitation) IsExpired() bool {
+	return time.Now().After(i.ExpiresAt)
+}
+
+type InviteMemberRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  Role   `json:"role" validate:"required,oneof=viewer editor admin"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+func GetWorkspaceInvitationTemplate(workspaceName string, role Role, link string) account.EmailTemplate {
+	escapedName := html.EscapeString(workspaceName)
+
+	return account.EmailTemplate{
+		Subject:  "You have been invited to " + workspaceName,
+		HtmlBody: "<h1>Workspace Invitation</h1><p>You have been invited to join <strong>" + escapedName + "</strong> as " + string(role) + ".</p><p><a href=\"" + html.EscapeString(link) + "\">Accept the invitation</a> after signing in with this email address.</p><p>This invitation will expire in 7 days.</p>",
+		TextBody: "Workspace Invitation - You have been invited to join " + workspaceName + " as " + string(role) + ". Open this link after signing in with this email address to accept: " + link + ". This invitation will expire in 7 days.",
+	}
+}
@@ -0,0 +1,125 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation *Invitation) (*Invitation, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*Invitation, error)
+	ListPending(ctx context.Context, workspaceID string) ([]*Invitation, error)
+	MarkAccepted(ctx context.Context, id primitive.ObjectID) (*Invitation, error)
+	Delete(ctx context.Context, workspaceID string, id primitive.ObjectID) error
+	DeletePending(ctx context.Context, workspaceID, email string) error
+	DeleteByWorkspace(ctx context.Context, workspaceID string) (int64, error)
+}
+
+type invitationRepository struct {
+	repo mongo.Repository[Invitation]
+}
+
+var _ InvitationRepository = (*invitationRepository)(nil)
+
+func NewInvitationRepository(mongoService *mongo.MongoService) InvitationRepository {
+	return &invitationRepository{
+		repo: mongo.NewRepository[Invitation](mongoService, InvitationCollectionName),
+	}
+}
+
+func (r *invitationRepository) Create(ctx context.Context, invitation *Invitation) (*Invitation, error) {
+	invitation.ID = primitive.NewObjectID()
+
+	result, err := r.repo.Create(ctx, *invitation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *invitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*Invitation, error) {
+	result, err := r.repo.FindOne(ctx, bson.M{"token_hash": tokenHash})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *invitationRepository) ListPending(ctx context.Context, workspaceID string) ([]*Invitation, error) {
+	filter := bson.M{
+		"workspace_id": workspaceID,
+		"accepted_at":  bson.M{"$exists": false},
+		"expires_at":   bson.M{"$gt": time.Now()},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	results, err := r.repo.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+
+	invitations := make([]*Invitation, len(results))
+	for i := range results {
+		invitations[i] = &results[i]
+	}
+
+	return invitations, nil
+}
+
+// MarkAccepted returns nil when the invitation was already accepted, so that
+// a token can only be redeemed once.
+func (r *invitationRepository) MarkAccepted(ctx context.Context, id primitive.ObjectID) (*Invitation, error) {
+	filter := bson.M{"_id": id, "accepted_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"accepted_at": time.Now()}}
+
+	result, err := r.repo.Update(ctx, filter, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *invitationRepository) Delete(ctx context.Context, workspaceID string, id primitive.ObjectID) error {
+	filter := bson.M{"_id": id, "workspace_id": workspaceID, "accepted_at": bson.M{"$exists": false}}
+
+	deleted, err := r.repo.DeleteMany(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to delete invitation: %w", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("invitation not found")
+	}
+
+	return nil
+}
+
+// DeletePending drops earlier invitations of email, so that inviting someone
+// again replaces the previous link.
+func (r *invitationRepository) DeletePending(ctx context.Context, workspaceID, email string) error {
+	filter := bson.M{"workspace_id": workspaceID, "email": email, "accepted_at": bson.M{"$exists": false}}
+
+	if _, err := r.repo.DeleteMany(ctx, filter); err != nil {
+		return fmt.Errorf("failed to delete invitations: %w", err)
+	}
+
+	return nil
+}
+
+func (r *invitationRepository) DeleteByWorkspace(ctx context.Context, workspaceID string) (int64, error) {
+	deleted, err := r.repo.DeleteMany(ctx, bson.M{"workspace_id": workspaceID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete invitations: %w", err)
+	}
+
+	return deleted, nil
+}
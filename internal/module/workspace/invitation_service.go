@@ -0,0 +1,227 @@
+package workspace
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/module/account"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/resend"
+)
+
+// AccountLookup resolves the account accepting an invitation. It is satisfied
+// by account.AccountService.
+type AccountLookup interface {
+	GetAccountByID(ctx context.Context, id string) (*account.AccountResponse, error)
+}
+
+type InvitationService interface {
+	InviteMember(ctx context.Context, accountID, workspaceID string, req *InviteMemberRequest) (*Invitation, error)
+	ListInvitations(ctx context.Context, workspaceID string) ([]*Invitation, error)
+	RevokeInvitation(ctx context.Context, workspaceID, invitationID string) error
+	AcceptInvitation(ctx context.Context, accountID string, req *AcceptInvitationRequest) (*WorkspaceResponse, error)
+}
+
+type invitationService struct {
+	repository          InvitationRepository
+	workspaceRepository WorkspaceRepository
+	accounts            AccountLookup
+	resendService       resend.ResendService
+	fromEmail           string
+	invitationURL       string
+}
+
+func NewInvitationService(
+	mongoService *mongo.MongoService,
+	accounts AccountLookup,
+	resendService resend.ResendService,
+	fromEmail string,
+	invitationURL string,
+) (InvitationService, error) {
+	if _, err := url.Parse(invitationURL); err != nil || invitationURL == "" {
+		return nil, fmt.Errorf("invalid invitation URL %q", invitationURL)
+	}
+
+	return &invitationService{
+		repository:          NewInvitationRepository(mongoService),
+		workspaceRepository: NewWorkspaceRepository(mongoService),
+		accounts:            accounts,
+		resendService:       resendService,
+		fromEmail:           fromEmail,
+		invitationURL:       invitationURL,
+	}, nil
+}
+
+// InviteMember emails a single-use link to join the workspace. Inviting the
+// same address again replaces the earlier invitation.
+func (s *invitationService) InviteMember(ctx context.Context, accountID, workspaceID string, req *InviteMemberRequest) (*Invitation, error) {
+	if !req.Role.IsValid() {
+		return nil, fmt.Errorf("invalid role %q", req.Role)
+	}
+
+	workspace, err := s.getWorkspace(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if err := s.repository.DeletePending(ctx, workspaceID, email); err != nil {
+		return nil, err
+	}
+
+	token, err := generateRandomToken(InvitationTokenSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+
+	now := time.Now()
+	invitation, err := s.repository.Create(ctx, &Invitation{
+		WorkspaceID: workspaceID,
+		Email:       email,
+		Role:        req.Role,
+		TokenHash:   hashSecret(token),
+		InvitedBy:   accountID,
+		ExpiresAt:   now.Add(InvitationExpiry),
+		CreatedAt:   now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.resendService == nil {
+		return invitation, nil
+	}
+
+	link, err := withTokenQuery(s.invitationURL, token)
+	if err != nil {
+		return nil, err
+	}
+
+	template := GetWorkspaceInvitationTemplate(workspace.Name, req.Role, link)
+	_, err = s.resendService.SendEmail(ctx, &resend.EmailRequest{
+		From:    s.fromEmail,
+		To:      []string{email},
+		Subject: template.Subject,
+		Html:    template.HtmlBody,
+		Text:    template.TextBody,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send invitation email: %w", err)
+	}
+
+	return invitation, nil
+}
+
+func (s *invitationService) ListInvitations(ctx context.Context, workspaceID string) ([]*Invitation, error) {
+	return s.repository.ListPending(ctx, workspaceID)
+}
+
+func (s *invitationService) RevokeInvitation(ctx context.Context, workspaceID, invitationID string) error {
+	objectID, err := primitive.ObjectIDFromHex(invitationID)
+	if err != nil {
+		return fmt.Errorf("invitation not found")
+	}
+
+	return s.repository.Delete(ctx, workspaceID, objectID)
+}
+
+// AcceptInvitation adds the signed-in account to the workspace. The account
+// must use the email address the invitation was sent to.
+func (s *invitationService) AcceptInvitation(ctx context.Context, accountID string, req *AcceptInvitationRequest) (*WorkspaceResponse, error) {
+	invitation, err := s.repository.GetByTokenHash(ctx, hashSecret(req.Token))
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil || invitation.AcceptedAt != nil || invitation.IsExpired() {
+		return nil, fmt.Errorf("invalid or expired invitation")
+	}
+
+	accountResponse, err := s.accounts.GetAccountByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(accountResponse.Email, invitation.Email) {
+		return nil, fmt.Errorf("cannot accept an invitation sent to another email address")
+	}
+
+	workspace, err := s.getWorkspace(ctx, invitation.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if workspace.Member(accountID) != nil {
+		return nil, fmt.Errorf("already a member of this workspace")
+	}
+
+	accepted, err := s.repository.MarkAccepted(ctx, invitation.ID)
+	if err != nil {
+		return nil, err
+	}
+	if accepted == nil {
+		return nil, fmt.Errorf("invalid or expired invitation")
+	}
+
+	updated, err := s.workspaceRepository.AddMember(ctx, workspace.ID, Member{
+		AccountID: accountID,
+		Role:      invitation.Role,
+		JoinedAt:  time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("already a member of this workspace")
+	}
+
+	return updated.ToResponse(accountID), nil
+}
+
+func (s *invitationService) getWorkspace(ctx context.Context, workspaceID string) (*Workspace, error) {
+	objectID, err := primitive.ObjectIDFromHex(workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("workspace not found")
+	}
+
+	workspace, err := s.workspaceRepository.GetByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if workspace == nil {
+		return nil, fmt.Errorf("workspace not found")
+	}
+
+	return workspace, nil
+}
+
+func withTokenQuery(baseURL, token string) (string, error) {
+	link, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid link URL %q: %w", baseURL, err)
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String(), nil
+}
+
+func hashSecret(value string) string {
+	hash := sha256.Sum256([]byte(value))
+	return fmt.Sprintf("%x", hash)
+}
+
+func generateRandomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,158 @@
+package workspace
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/module/account"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/resend"
+)
+
+func setupInvitationService() (*invitationService, *MockInvitationRepository, *MockWorkspaceRepository, *account.MockAccountService, *account.MockResendService) {
+	mockRepo := &MockInvitationRepository{}
+	mockWorkspaceRepo := &MockWorkspaceRepository{}
+	mockAccounts := &account.MockAccountService{}
+	mockResend := &account.MockResendService{}
+
+	service := &invitationService{
+		repository:          mockRepo,
+		workspaceRepository: mockWorkspaceRepo,
+		accounts:            mockAccounts,
+		resendService:       mockResend,
+		fromEmail:           "noreply@example.com",
+		invitationURL:       "https://app.example.com/invitations/accept",
+	}
+
+	return service, mockRepo, mockWorkspaceRepo, mockAccounts, mockResend
+}
+
+func TestInvitationService_InviteMember(t *testing.T) {
+	service, mockRepo, mockWorkspaceRepo, _, mockResend := setupInvitationService()
+	workspace := CreateTestWorkspace()
+	workspaceID := workspace.ID.Hex()
+
+	var storedHash string
+	var sentLink string
+
+	mockWorkspaceRepo.On("GetByID", mock.Anything, workspace.ID).Return(workspace, nil)
+	mockRepo.On("DeletePending", mock.Anything, workspaceID, "invitee@example.com").Return(nil)
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(i *Invitation) bool {
+		storedHash = i.TokenHash
+		return i.Email == "invitee@example.com" && i.Role == RoleEditor && i.InvitedBy == testOwnerID
+	})).Return(CreateTestInvitation(), nil)
+	mockResend.On("SendEmail", mock.Anything, mock.MatchedBy(func(r *resend.EmailRequest) bool {
+		for _, field := range strings.Fields(r.Text) {
+			if strings.HasPrefix(field, "https://") {
+				sentLink = strings.TrimSuffix(field, ".")
+			}
+		}
+		return r.To[0] == "invitee@example.com"
+	})).Return(&resend.EmailResponse{ID: "email"}, nil)
+
+	invitation, err := service.InviteMember(context.Background(), testOwnerID, workspaceID, &InviteMemberRequest{
+		Email: " Invitee@Example.com ",
+		Role:  RoleEditor,
+	})
+
+	require.NoError(t, err)
+	assert.NotNil(t, invitation)
+
+	link, err := url.Parse(sentLink)
+	require.NoError(t, err)
+	token := link.Query().Get("token")
+	assert.NotEmpty(t, token)
+	assert.Equal(t, hashSecret(token), storedHash)
+
+	mockRepo.AssertExpectations(t)
+	mockResend.AssertExpectations(t)
+}
+
+func TestInvitationService_AcceptInvitation(t *testing.T) {
+	workspace := CreateTestWorkspace()
+	newMemberID := "64b7f0c2e4b0a1a2b3c4d5e9"
+
+	t.Run("joins with the invited role", func(t *testing.T) {
+		service, mockRepo, mockWorkspaceRepo, mockAccounts, _ := setupInvitationService()
+		invitation := CreateTestInvitation(func(i *Invitation) {
+			i.WorkspaceID = workspace.ID.Hex()
+		})
+		joined := CreateTestWorkspace(func(w *Workspace) {
+			w.ID = workspace.ID
+			w.Members = append(w.Members, Member{AccountID: newMemberID, Role: RoleEditor})
+		})
+
+		mockRepo.On("GetByTokenHash", mock.Anything, hashSecret("invitation-token")).Return(invitation, nil)
+		mockAccounts.On("GetAccountByID", mock.Anything, newMemberID).Return(account.CreateTestAccountResponse(func(a *account.AccountResponse) {
+			a.Email = "INVITEE@example.com"
+		}), nil)
+		mockWorkspaceRepo.On("GetByID", mock.Anything, workspace.ID).Return(workspace, nil)
+		mockRepo.On("MarkAccepted", mock.Anything, invitation.ID).Return(invitation, nil)
+		mockWorkspaceRepo.On("AddMember", mock.Anything, workspace.ID, mock.MatchedBy(func(m Member) bool {
+			return m.AccountID == newMemberID && m.Role == RoleEditor
+		})).Return(joined, nil)
+
+		response, err := service.AcceptInvitation(context.Background(), newMemberID, &AcceptInvitationRequest{Token: "invitation-token"})
+
+		require.NoError(t, err)
+		assert.Equal(t, RoleEditor, response.Role)
+		mockWorkspaceRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects another email address", func(t *testing.T) {
+		service, mockRepo, _, mockAccounts, _ := setupInvitationService()
+		invitation := CreateTestInvitation(func(i *Invitation) {
+			i.WorkspaceID = workspace.ID.Hex()
+		})
+
+		mockRepo.On("GetByTokenHash", mock.Anything, hashSecret("invitation-token")).Return(invitation, nil)
+		mockAccounts.On("GetAccountByID", mock.Anything, newMemberID).Return(account.CreateTestAccountResponse(), nil)
+
+		_, err := service.AcceptInvitation(context.Background(), newMemberID, &AcceptInvitationRequest{Token: "invitation-token"})
+
+		assert.ErrorContains(t, err, "cannot accept")
+		mockRepo.AssertNotCalled(t, "MarkAccepted", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects expired and used invitations", func(t *testing.T) {
+		service, mockRepo, _, _, _ := setupInvitationService()
+		acceptedAt := time.Now()
+
+		mockRepo.On("GetByTokenHash", mock.Anything, hashSecret("expired")).Return(CreateTestInvitation(func(i *Invitation) {
+			i.ExpiresAt = time.Now().Add(-time.Minute)
+		}), nil)
+		mockRepo.On("GetByTokenHash", mock.Anything, hashSecret("used")).Return(CreateTestInvitation(func(i *Invitation) {
+			i.AcceptedAt = &acceptedAt
+		}), nil)
+		mockRepo.On("GetByTokenHash", mock.Anything, hashSecret("unknown")).Return(nil, nil)
+
+		for _, token := range []string{"expired", "used", "unknown"} {
+			_, err := service.AcceptInvitation(context.Background(), newMemberID, &AcceptInvitationRequest{Token: token})
+			assert.EqualError(t, err, "invalid or expired invitation", token)
+		}
+	})
+
+	t.Run("existing member", func(t *testing.T) {
+		service, mockRepo, mockWorkspaceRepo, mockAccounts, _ := setupInvitationService()
+		invitation := CreateTestInvitation(func(i *Invitation) {
+			i.WorkspaceID = workspace.ID.Hex()
+		})
+
+		mockRepo.On("GetByTokenHash", mock.Anything, mock.Anything).Return(invitation, nil)
+		mockAccounts.On("GetAccountByID", mock.Anything, testViewerID).Return(account.CreateTestAccountResponse(func(a *account.AccountResponse) {
+			a.Email = invitation.Email
+		}), nil)
+		mockWorkspaceRepo.On("GetByID", mock.Anything, workspace.ID).Return(workspace, nil)
+
+		_, err := service.AcceptInvitation(context.Background(), testViewerID, &AcceptInvitationRequest{Token: "invitation-token"})
+
+		assert.EqualError(t, err, "already a member of this workspace")
+		mockRepo.AssertNotCalled(t, "MarkAccepted", mock.Anything, mock.Anything)
+	})
+}
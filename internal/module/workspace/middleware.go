@@ -0,0 +1,60 @@
+package workspace
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type WorkspaceMiddleware struct {
+	service WorkspaceService
+}
+
+func NewWorkspaceMiddleware(service WorkspaceService) *WorkspaceMiddleware {
+	return &WorkspaceMiddleware{
+		service: service,
+	}
+}
+
+// RequireRole resolves the :workspaceId route parameter and admits members
+// holding at least role. It must run after RequireAuth. The workspace ID and
+// the member's role are stored as "workspace_id" and "workspace_role".
+func (m *WorkspaceMiddleware) RequireRole(role Role) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		accountID, _ := c.Locals("account_id").(string)
+		if accountID == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error":   "Unauthorized",
+				"message": "Authentication required",
+			})
+		}
+
+		workspaceID := c.Params("workspaceId")
+		memberRole, err := m.service.GetMemberRole(c.Context(), workspaceID, accountID)
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error":   "Not Found",
+					"message": "Workspace not found",
+				})
+			}
+
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "Internal Server Error",
+				"message": "Failed to resolve workspace",
+			})
+		}
+
+		if !memberRole.Includes(role) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":   "Forbidden",
+				"message": "Requires the " + string(role) + " role in this workspace",
+			})
+		}
+
+		c.Locals("workspace_id", workspaceID)
+		c.Locals("workspace_role", memberRole)
+
+		return c.Next()
+	}
+}
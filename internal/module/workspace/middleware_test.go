@@ -0,0 +1,83 @@
+package workspace
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspaceMiddleware_RequireRole(t *testing.T) {
+	const workspaceID = "64b7f0c2e4b0a1a2b3c4d600"
+
+	tests := []struct {
+		name           string
+		accountID      string
+		required       Role
+		setupMock      func(*MockWorkspaceService)
+		expectedStatus int
+	}{
+		{
+			name:      "member with enough rights",
+			accountID: testEditorID,
+			required:  RoleEditor,
+			setupMock: func(m *MockWorkspaceService) {
+				m.On("GetMemberRole", mock.Anything, workspaceID, testEditorID).Return(RoleEditor, nil)
+			},
+			expectedStatus: fiber.StatusOK,
+		},
+		{
+			name:      "viewer writing",
+			accountID: testViewerID,
+			required:  RoleEditor,
+			setupMock: func(m *MockWorkspaceService) {
+				m.On("GetMemberRole", mock.Anything, workspaceID, testViewerID).Return(RoleViewer, nil)
+			},
+			expectedStatus: fiber.StatusForbidden,
+		},
+		{
+			name:      "not a member",
+			accountID: testOwnerID,
+			required:  RoleViewer,
+			setupMock: func(m *MockWorkspaceService) {
+				m.On("GetMemberRole", mock.Anything, workspaceID, testOwnerID).Return(Role(""), errors.New("workspace not found"))
+			},
+			expectedStatus: fiber.StatusNotFound,
+		},
+		{
+			name:           "unauthenticated",
+			required:       RoleViewer,
+			setupMock:      func(m *MockWorkspaceService) {},
+			expectedStatus: fiber.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockWorkspaceService{}
+			tt.setupMock(mockService)
+			middleware := NewWorkspaceMiddleware(mockService)
+
+			app := fiber.New()
+			app.Get("/workspaces/:workspaceId", func(c *fiber.Ctx) error {
+				if tt.accountID != "" {
+					c.Locals("account_id", tt.accountID)
+				}
+				return c.Next()
+			}, middleware.RequireRole(tt.required), func(c *fiber.Ctx) error {
+				assert.Equal(t, workspaceID, c.Locals("workspace_id"))
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/workspaces/"+workspaceID, nil))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
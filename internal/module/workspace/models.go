@@ -0,0 +1,106 @@
+package workspace
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const WorkspaceCollectionName = "workspaces"
+
+// Role is what a member may do in a workspace. Each role includes the ones
+// below it: admins can edit and editors can view.
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+var roleRanks = map[Role]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+func (r Role) IsValid() bool {
+	return roleRanks[r] > 0
+}
+
+// Includes reports whether r grants at least the rights of required.
+func (r Role) Includes(required Role) bool {
+	return r.IsValid() && roleRanks[r] >= roleRanks[required]
+}
+
+type Member struct {
+	AccountID string    `json:"account_id" bson:"account_id"`
+	Role      Role      `json:"role" bson:"role"`
+	JoinedAt  time.Time `json:"joined_at" bson:"joined_at"`
+}
+
+// Workspace is an isolated knowledge base. The owner is always a member with
+// the admin role and cannot be removed or demoted.
+type Workspace struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name        string             `json:"name" bson:"name"`
+	Description string             `json:"description" bson:"description"`
+	OwnerID     string             `json:"owner_id" bson:"owner_id"`
+	Members     []Member           `json:"members" bson:"members"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+func (w *Workspace) Member(accountID string) *Member {
+	for i := range w.Members {
+		if w.Members[i].AccountID == accountID {
+			return &w.Members[i]
+		}
+	}
+
+	return nil
+}
+
+// ToResponse includes the role of the account the response is for.
+func (w *Workspace) ToResponse(accountID string) *WorkspaceResponse {
+	response := &WorkspaceResponse{
+		ID:          w.ID.Hex(),
+		Name:        w.Name,
+		Description: w.Description,
+		OwnerID:     w.OwnerID,
+		Members:     w.Members,
+		CreatedAt:   w.CreatedAt,
+		UpdatedAt:   w.UpdatedAt,
+	}
+
+	if member := w.Member(accountID); member != nil {
+		response.Role = member.Role
+	}
+
+	return response
+}
+
+type CreateWorkspaceRequest struct {
+	Name        string `json:"name" validate:"required,min=1,max=100"`
+	Description string `json:"description" validate:"max=500"`
+}
+
+type UpdateWorkspaceRequest struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=500"`
+}
+
+type UpdateMemberRequest struct {
+	Role Role `json:"role" validate:"required,oneof=viewer editor admin"`
+}
+
+type WorkspaceResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	OwnerID     string    `json:"owner_id"`
+	Role        Role      `json:"role,omitempty"`
+	Members     []Member  `json:"members"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,155 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+type WorkspaceRepository interface {
+	Create(ctx context.Context, workspace *Workspace) (*Workspace, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (*Workspace, error)
+	ListByMember(ctx context.Context, accountID string) ([]*Workspace, error)
+	Update(ctx context.Context, id primitive.ObjectID, update bson.M) (*Workspace, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	AddMember(ctx context.Context, id primitive.ObjectID, member Member) (*Workspace, error)
+	UpdateMemberRole(ctx context.Context, id primitive.ObjectID, accountID string, role Role) (*Workspace, error)
+	RemoveMember(ctx context.Context, id primitive.ObjectID, accountID string) (*Workspace, error)
+	TransferOwnership(ctx context.Context, id primitive.ObjectID, fromID, toID string) (*Workspace, error)
+}
+
+type workspaceRepository struct {
+	repo mongo.Repository[Workspace]
+}
+
+var _ WorkspaceRepository = (*workspaceRepository)(nil)
+
+func NewWorkspaceRepository(mongoService *mongo.MongoService) WorkspaceRepository {
+	return &workspaceRepository{
+		repo: mongo.NewRepository[Workspace](mongoService, WorkspaceCollectionName),
+	}
+}
+
+func (r *workspaceRepository) Create(ctx context.Context, workspace *Workspace) (*Workspace, error) {
+	workspace.ID = primitive.NewObjectID()
+
+	result, err := r.repo.Create(ctx, *workspace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *workspaceRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*Workspace, error) {
+	result, err := r.repo.FindOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *workspaceRepository) ListByMember(ctx context.Context, accountID string) ([]*Workspace, error) {
+	filter := bson.M{"members.account_id": accountID}
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+
+	results, err := r.repo.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+
+	workspaces := make([]*Workspace, len(results))
+	for i := range results {
+		workspaces[i] = &results[i]
+	}
+
+	return workspaces, nil
+}
+
+func (r *workspaceRepository) Update(ctx context.Context, id primitive.ObjectID, update bson.M) (*Workspace, error) {
+	update["updated_at"] = time.Now()
+
+	result, err := r.repo.Update(ctx, bson.M{"_id": id}, bson.M{"$set": update})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update workspace: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *workspaceRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if err := r.repo.Delete(ctx, bson.M{"_id": id}); err != nil {
+		return fmt.Errorf("failed to delete workspace: %w", err)
+	}
+
+	return nil
+}
+
+// AddMember returns nil when the workspace does not exist or the account is
+// already a member.
+func (r *workspaceRepository) AddMember(ctx context.Context, id primitive.ObjectID, member Member) (*Workspace, error) {
+	filter := bson.M{"_id": id, "members.account_id": bson.M{"$ne": member.AccountID}}
+	update := bson.M{
+		"$push": bson.M{"members": member},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+
+	result, err := r.repo.Update(ctx, filter, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add workspace member: %w", err)
+	}
+
+	return result, nil
+}
+
+// UpdateMemberRole returns nil when the account is not a member. The owner's
+// role is never changed.
+func (r *workspaceRepository) UpdateMemberRole(ctx context.Context, id primitive.ObjectID, accountID string, role Role) (*Workspace, error) {
+	filter := bson.M{"_id": id, "owner_id": bson.M{"$ne": accountID}, "members.account_id": accountID}
+	update := bson.M{"$set": bson.M{"members.$.role": role, "updated_at": time.Now()}}
+
+	result, err := r.repo.Update(ctx, filter, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update workspace member: %w", err)
+	}
+
+	return result, nil
+}
+
+// RemoveMember returns nil when the account is not a member. The owner is
+// never removed.
+func (r *workspaceRepository) RemoveMember(ctx context.Context, id primitive.ObjectID, accountID string) (*Workspace, error) {
+	filter := bson.M{"_id": id, "owner_id": bson.M{"$ne": accountID}, "members.account_id": accountID}
+	update := bson.M{
+		"$pull": bson.M{"members": bson.M{"account_id": accountID}},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+
+	result, err := r.repo.Update(ctx, filter, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove workspace member: %w", err)
+	}
+
+	return result, nil
+}
+
+// TransferOwnership makes the member toID the owner, with the admin role. It
+// returns nil when fromID is not the owner or toID is not a member.
+func (r *workspaceRepository) TransferOwnership(ctx context.Context, id primitive.ObjectID, fromID, toID string) (*Workspace, error) {
+	filter := bson.M{"_id": id, "owner_id": fromID, "members.account_id": toID}
+	update := bson.M{"$set": bson.M{"owner_id": toID, "members.$.role": RoleAdmin, "updated_at": time.Now()}}
+
+	result, err := r.repo.Update(ctx, filter, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer workspace ownership: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,311 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/mongo"
+)
+
+// WorkspaceDeleteHook removes data that other modules keep for a workspace.
+// Hooks run before the workspace itself is deleted, so a failing hook leaves
+// the workspace in place and the deletion can be retried.
+type WorkspaceDeleteHook func(ctx context.Context, workspaceID string) error
+
+type WorkspaceService interface {
+	CreateWorkspace(ctx context.Context, accountID string, req *CreateWorkspaceRequest) (*WorkspaceResponse, error)
+	ListWorkspaces(ctx context.Context, accountID string) ([]*WorkspaceResponse, error)
+	GetWorkspace(ctx context.Context, accountID, workspaceID string) (*WorkspaceResponse, error)
+	UpdateWorkspace(ctx context.Context, accountID, workspaceID string, req *UpdateWorkspaceRequest) (*WorkspaceResponse, error)
+	DeleteWorkspace(ctx context.Context, accountID, workspaceID string) error
+	UpdateMemberRole(ctx context.Context, accountID, workspaceID, memberID string, req *UpdateMemberRequest) (*WorkspaceResponse, error)
+	RemoveMember(ctx context.Context, accountID, workspaceID, memberID string) error
+	GetMemberRole(ctx context.Context, workspaceID, accountID string) (Role, error)
+	PurgeAccount(ctx context.Context, accountID string) error
+	OnDelete(hook WorkspaceDeleteHook)
+}
+
+type workspaceService struct {
+	repository           WorkspaceRepository
+	invitationRepository InvitationRepository
+
+	mu    sync.RWMutex
+	hooks []WorkspaceDeleteHook
+}
+
+func NewWorkspaceService(mongoService *mongo.MongoService) WorkspaceService {
+	return newWorkspaceService(NewWorkspaceRepository(mongoService), NewInvitationRepository(mongoService))
+}
+
+func newWorkspaceService(repository WorkspaceRepository, invitationRepository InvitationRepository) *workspaceService {
+	return &workspaceService{
+		repository:           repository,
+		invitationRepository: invitationRepository,
+	}
+}
+
+func (s *workspaceService) OnDelete(hook WorkspaceDeleteHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hooks = append(s.hooks, hook)
+}
+
+func (s *workspaceService) CreateWorkspace(ctx context.Context, accountID string, req *CreateWorkspaceRequest) (*WorkspaceResponse, error) {
+	now := time.Now()
+
+	workspace, err := s.repository.Create(ctx, &Workspace{
+		Name:        req.Name,
+		Description: req.Description,
+		OwnerID:     accountID,
+		Members: []Member{
+			{AccountID: accountID, Role: RoleAdmin, JoinedAt: now},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return workspace.ToResponse(accountID), nil
+}
+
+func (s *workspaceService) ListWorkspaces(ctx context.Context, accountID string) ([]*WorkspaceResponse, error) {
+	workspaces, err := s.repository.ListByMember(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*WorkspaceResponse, len(workspaces))
+	for i, workspace := range workspaces {
+		responses[i] = workspace.ToResponse(accountID)
+	}
+
+	return responses, nil
+}
+
+func (s *workspaceService) GetWorkspace(ctx context.Context, accountID, workspaceID string) (*WorkspaceResponse, error) {
+	workspace, err := s.getMemberWorkspace(ctx, accountID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return workspace.ToResponse(accountID), nil
+}
+
+func (s *workspaceService) UpdateWorkspace(ctx context.Context, accountID, workspaceID string, req *UpdateWorkspaceRequest) (*WorkspaceResponse, error) {
+	workspace, err := s.getMemberWorkspace(ctx, accountID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !workspace.Member(accountID).Role.Includes(RoleAdmin) {
+		return nil, fmt.Errorf("only workspace admins can update the workspace")
+	}
+
+	update := bson.M{}
+	if req.Name != nil {
+		update["name"] = *req.Name
+	}
+	if req.Description != nil {
+		update["description"] = *req.Description
+	}
+	if len(update) == 0 {
+		return workspace.ToResponse(accountID), nil
+	}
+
+	updated, err := s.repository.Update(ctx, workspace.ID, update)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("workspace not found")
+	}
+
+	return updated.ToResponse(accountID), nil
+}
+
+func (s *workspaceService) DeleteWorkspace(ctx context.Context, accountID, workspaceID string) error {
+	workspace, err := s.getMemberWorkspace(ctx, accountID, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	if workspace.OwnerID != accountID {
+		return fmt.Errorf("only the owner can delete the workspace")
+	}
+
+	return s.deleteWorkspace(ctx, workspace)
+}
+
+// UpdateMemberRole changes the role of memberID. Only admins may change roles
+// and the owner always stays an admin.
+func (s *workspaceService) UpdateMemberRole(ctx context.Context, accountID, workspaceID, memberID string, req *UpdateMemberRequest) (*WorkspaceResponse, error) {
+	if !req.Role.IsValid() {
+		return nil, fmt.Errorf("invalid role %q", req.Role)
+	}
+
+	workspace, err := s.getMemberWorkspace(ctx, accountID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !workspace.Member(accountID).Role.Includes(RoleAdmin) {
+		return nil, fmt.Errorf("only workspace admins can change member roles")
+	}
+	if memberID == workspace.OwnerID {
+		return nil, fmt.Errorf("cannot change the role of the workspace owner")
+	}
+
+	updated, err := s.repository.UpdateMemberRole(ctx, workspace.ID, memberID, req.Role)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("member not found")
+	}
+
+	return updated.ToResponse(accountID), nil
+}
+
+// RemoveMember removes memberID from the workspace. Members may always leave
+// on their own; removing someone else requires the admin role. The owner
+// cannot leave and has to delete the workspace instead.
+func (s *workspaceService) RemoveMember(ctx context.Context, accountID, workspaceID, memberID string) error {
+	workspace, err := s.getMemberWorkspace(ctx, accountID, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	if memberID != accountID && !workspace.Member(accountID).Role.Includes(RoleAdmin) {
+		return fmt.Errorf("only workspace admins can remove other members")
+	}
+	if memberID == workspace.OwnerID {
+		return fmt.Errorf("cannot remove the workspace owner")
+	}
+
+	updated, err := s.repository.RemoveMember(ctx, workspace.ID, memberID)
+	if err != nil {
+		return err
+	}
+	if updated == nil {
+		return fmt.Errorf("member not found")
+	}
+
+	return nil
+}
+
+// GetMemberRole returns the role of accountID in the workspace. Workspaces
+// the account is not a member of are reported as not found so that their
+// existence is not revealed.
+func (s *workspaceService) GetMemberRole(ctx context.Context, workspaceID, accountID string) (Role, error) {
+	workspace, err := s.getMemberWorkspace(ctx, accountID, workspaceID)
+	if err != nil {
+		return "", err
+	}
+
+	return workspace.Member(accountID).Role, nil
+}
+
+// PurgeAccount removes accountID from every workspace when the account is
+// purged. Workspaces it owns alone are deleted; shared ones are handed over
+// to a successor so that the data of the other members is kept.
+func (s *workspaceService) PurgeAccount(ctx context.Context, accountID string) error {
+	workspaces, err := s.repository.ListByMember(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	for _, workspace := range workspaces {
+		if workspace.OwnerID == accountID {
+			err = s.purgeOwner(ctx, workspace, accountID)
+		} else {
+			_, err = s.repository.RemoveMember(ctx, workspace.ID, accountID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to purge workspace %s: %w", workspace.ID.Hex(), err)
+		}
+	}
+
+	return nil
+}
+
+func (s *workspaceService) purgeOwner(ctx context.Context, workspace *Workspace, accountID string) error {
+	successor := successorOf(workspace)
+	if successor == nil {
+		return s.deleteWorkspace(ctx, workspace)
+	}
+
+	updated, err := s.repository.TransferOwnership(ctx, workspace.ID, accountID, successor.AccountID)
+	if err != nil {
+		return err
+	}
+	if updated == nil {
+		return fmt.Errorf("workspace ownership changed during the purge")
+	}
+
+	_, err = s.repository.RemoveMember(ctx, workspace.ID, accountID)
+	return err
+}
+
+// successorOf picks the member who takes over a workspace from its owner:
+// the longest-standing admin, then editor, then viewer. It returns nil when
+// the owner is the only member.
+func successorOf(workspace *Workspace) *Member {
+	var successor *Member
+	for i := range workspace.Members {
+		member := &workspace.Members[i]
+		if member.AccountID == workspace.OwnerID {
+			continue
+		}
+		if successor == nil ||
+			member.Role.Includes(successor.Role) && member.Role != successor.Role ||
+			member.Role == successor.Role && member.JoinedAt.Before(successor.JoinedAt) {
+			successor = member
+		}
+	}
+
+	return successor
+}
+
+func (s *workspaceService) deleteWorkspace(ctx context.Context, workspace *Workspace) error {
+	s.mu.RLock()
+	hooks := append([]WorkspaceDeleteHook(nil), s.hooks...)
+	s.mu.RUnlock()
+
+	workspaceID := workspace.ID.Hex()
+	for _, hook := range hooks {
+		if err := hook(ctx, workspaceID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.invitationRepository.DeleteByWorkspace(ctx, workspaceID); err != nil {
+		return err
+	}
+
+	return s.repository.Delete(ctx, workspace.ID)
+}
+
+func (s *workspaceService) getMemberWorkspace(ctx context.Context, accountID, workspaceID string) (*Workspace, error) {
+	objectID, err := primitive.ObjectIDFromHex(workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("workspace not found")
+	}
+
+	workspace, err := s.repository.GetByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if workspace == nil || workspace.Member(accountID) == nil {
+		return nil, fmt.Errorf("workspace not found")
+	}
+
+	return workspace, nil
+}
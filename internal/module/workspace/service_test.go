@@ -0,0 +1,202 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupWorkspaceService() (*workspaceService, *MockWorkspaceRepository, *MockInvitationRepository) {
+	mockRepo := &MockWorkspaceRepository{}
+	mockInvitationRepo := &MockInvitationRepository{}
+	return newWorkspaceService(mockRepo, mockInvitationRepo), mockRepo, mockInvitationRepo
+}
+
+func TestRole_Includes(t *testing.T) {
+	assert.True(t, RoleAdmin.Includes(RoleEditor))
+	assert.True(t, RoleEditor.Includes(RoleEditor))
+	assert.False(t, RoleViewer.Includes(RoleEditor))
+	assert.False(t, Role("owner").Includes(RoleViewer))
+}
+
+func TestWorkspaceService_CreateWorkspace(t *testing.T) {
+	service, mockRepo, _ := setupWorkspaceService()
+
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(w *Workspace) bool {
+		return w.OwnerID == testOwnerID && len(w.Members) == 1 && w.Members[0].Role == RoleAdmin
+	})).Return(CreateTestWorkspace(), nil)
+
+	workspace, err := service.CreateWorkspace(context.Background(), testOwnerID, &CreateWorkspaceRequest{Name: "Research"})
+
+	require.NoError(t, err)
+	assert.Equal(t, RoleAdmin, workspace.Role)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWorkspaceService_GetWorkspace_NotMember(t *testing.T) {
+	service, mockRepo, _ := setupWorkspaceService()
+	workspace := CreateTestWorkspace()
+
+	mockRepo.On("GetByID", mock.Anything, workspace.ID).Return(workspace, nil)
+
+	_, err := service.GetWorkspace(context.Background(), "64b7f0c2e4b0a1a2b3c4d5ff", workspace.ID.Hex())
+	assert.EqualError(t, err, "workspace not found")
+
+	_, err = service.GetWorkspace(context.Background(), testOwnerID, "not-an-id")
+	assert.EqualError(t, err, "workspace not found")
+}
+
+func TestWorkspaceService_UpdateWorkspace_RequiresAdmin(t *testing.T) {
+	service, mockRepo, _ := setupWorkspaceService()
+	workspace := CreateTestWorkspace()
+	name := "Renamed"
+
+	mockRepo.On("GetByID", mock.Anything, workspace.ID).Return(workspace, nil)
+
+	_, err := service.UpdateWorkspace(context.Background(), testEditorID, workspace.ID.Hex(), &UpdateWorkspaceRequest{Name: &name})
+
+	assert.EqualError(t, err, "only workspace admins can update the workspace")
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWorkspaceService_DeleteWorkspace(t *testing.T) {
+	t.Run("runs hooks before deleting", func(t *testing.T) {
+		service, mockRepo, mockInvitationRepo := setupWorkspaceService()
+		workspace := CreateTestWorkspace()
+
+		var deletedGraph string
+		service.OnDelete(func(ctx context.Context, workspaceID string) error {
+			deletedGraph = workspaceID
+			return nil
+		})
+
+		mockRepo.On("GetByID", mock.Anything, workspace.ID).Return(workspace, nil)
+		mockInvitationRepo.On("DeleteByWorkspace", mock.Anything, workspace.ID.Hex()).Return(int64(2), nil)
+		mockRepo.On("Delete", mock.Anything, workspace.ID).Return(nil)
+
+		err := service.DeleteWorkspace(context.Background(), testOwnerID, workspace.ID.Hex())
+
+		require.NoError(t, err)
+		assert.Equal(t, workspace.ID.Hex(), deletedGraph)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("keeps the workspace when a hook fails", func(t *testing.T) {
+		service, mockRepo, _ := setupWorkspaceService()
+		workspace := CreateTestWorkspace()
+
+		service.OnDelete(func(ctx context.Context, workspaceID string) error {
+			return errors.New("neo4j unavailable")
+		})
+
+		mockRepo.On("GetByID", mock.Anything, workspace.ID).Return(workspace, nil)
+
+		err := service.DeleteWorkspace(context.Background(), testOwnerID, workspace.ID.Hex())
+
+		assert.EqualError(t, err, "neo4j unavailable")
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("only the owner", func(t *testing.T) {
+		service, mockRepo, _ := setupWorkspaceService()
+		workspace := CreateTestWorkspace(func(w *Workspace) {
+			w.Members[1].Role = RoleAdmin
+		})
+
+		mockRepo.On("GetByID", mock.Anything, workspace.ID).Return(workspace, nil)
+
+		err := service.DeleteWorkspace(context.Background(), testEditorID, workspace.ID.Hex())
+		assert.EqualError(t, err, "only the owner can delete the workspace")
+	})
+}
+
+func TestWorkspaceService_UpdateMemberRole(t *testing.T) {
+	service, mockRepo, _ := setupWorkspaceService()
+	workspace := CreateTestWorkspace()
+	updated := CreateTestWorkspace(func(w *Workspace) {
+		w.ID = workspace.ID
+		w.Members[2].Role = RoleEditor
+	})
+
+	mockRepo.On("GetByID", mock.Anything, workspace.ID).Return(workspace, nil)
+	mockRepo.On("UpdateMemberRole", mock.Anything, workspace.ID, testViewerID, RoleEditor).Return(updated, nil)
+
+	response, err := service.UpdateMemberRole(context.Background(), testOwnerID, workspace.ID.Hex(), testViewerID, &UpdateMemberRequest{Role: RoleEditor})
+	require.NoError(t, err)
+	assert.Equal(t, RoleEditor, response.Members[2].Role)
+
+	_, err = service.UpdateMemberRole(context.Background(), testOwnerID, workspace.ID.Hex(), testOwnerID, &UpdateMemberRequest{Role: RoleViewer})
+	assert.EqualError(t, err, "cannot change the role of the workspace owner")
+
+	_, err = service.UpdateMemberRole(context.Background(), testEditorID, workspace.ID.Hex(), testViewerID, &UpdateMemberRequest{Role: RoleAdmin})
+	assert.EqualError(t, err, "only workspace admins can change member roles")
+}
+
+func TestWorkspaceService_RemoveMember(t *testing.T) {
+	tests := []struct {
+		name          string
+		accountID     string
+		memberID      string
+		expectRemove  bool
+		errorContains string
+	}{
+		{name: "member leaves", accountID: testViewerID, memberID: testViewerID, expectRemove: true},
+		{name: "admin removes member", accountID: testOwnerID, memberID: testEditorID, expectRemove: true},
+		{name: "editor removes member", accountID: testEditorID, memberID: testViewerID, errorContains: "only workspace admins"},
+		{name: "owner leaves", accountID: testOwnerID, memberID: testOwnerID, errorContains: "cannot remove the workspace owner"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockRepo, _ := setupWorkspaceService()
+			workspace := CreateTestWorkspace()
+
+			mockRepo.On("GetByID", mock.Anything, workspace.ID).Return(workspace, nil)
+			if tt.expectRemove {
+				mockRepo.On("RemoveMember", mock.Anything, workspace.ID, tt.memberID).Return(workspace, nil)
+			}
+
+			err := service.RemoveMember(context.Background(), tt.accountID, workspace.ID.Hex(), tt.memberID)
+
+			if tt.errorContains != "" {
+				assert.ErrorContains(t, err, tt.errorContains)
+				mockRepo.AssertNotCalled(t, "RemoveMember", mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWorkspaceService_PurgeAccount(t *testing.T) {
+	service, mockRepo, mockInvitationRepo := setupWorkspaceService()
+	alone := CreateTestWorkspace(func(w *Workspace) {
+		w.Members = w.Members[:1]
+	})
+	owned := CreateTestWorkspace(func(w *Workspace) {
+		w.Members = append(w.Members, Member{AccountID: "64b7f0c2e4b0a1a2b3c4d5e9", Role: RoleEditor, JoinedAt: w.Members[1].JoinedAt.Add(-time.Hour)})
+	})
+	shared := CreateTestWorkspace(func(w *Workspace) {
+		w.OwnerID = testEditorID
+	})
+
+	mockRepo.On("ListByMember", mock.Anything, testOwnerID).Return([]*Workspace{alone, owned, shared}, nil)
+	mockInvitationRepo.On("DeleteByWorkspace", mock.Anything, alone.ID.Hex()).Return(int64(0), nil)
+	mockRepo.On("Delete", mock.Anything, alone.ID).Return(nil)
+	mockRepo.On("TransferOwnership", mock.Anything, owned.ID, testOwnerID, "64b7f0c2e4b0a1a2b3c4d5e9").Return(owned, nil)
+	mockRepo.On("RemoveMember", mock.Anything, owned.ID, testOwnerID).Return(owned, nil)
+	mockRepo.On("RemoveMember", mock.Anything, shared.ID, testOwnerID).Return(shared, nil)
+
+	err := service.PurgeAccount(context.Background(), testOwnerID)
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, owned.ID)
+	mockInvitationRepo.AssertExpectations(t)
+}
@@ -0,0 +1,253 @@
+package workspace
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockWorkspaceRepository struct {
+	mock.Mock
+}
+
+func (m *MockWorkspaceRepository) Create(ctx context.Context, workspace *Workspace) (*Workspace, error) {
+	args := m.Called(ctx, workspace)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*Workspace, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) ListByMember(ctx context.Context, accountID string) ([]*Workspace, error) {
+	args := m.Called(ctx, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) Update(ctx context.Context, id primitive.ObjectID, update bson.M) (*Workspace, error) {
+	args := m.Called(ctx, id, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) AddMember(ctx context.Context, id primitive.ObjectID, member Member) (*Workspace, error) {
+	args := m.Called(ctx, id, member)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) UpdateMemberRole(ctx context.Context, id primitive.ObjectID, accountID string, role Role) (*Workspace, error) {
+	args := m.Called(ctx, id, accountID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) RemoveMember(ctx context.Context, id primitive.ObjectID, accountID string) (*Workspace, error) {
+	args := m.Called(ctx, id, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) TransferOwnership(ctx context.Context, id primitive.ObjectID, fromID, toID string) (*Workspace, error) {
+	args := m.Called(ctx, id, fromID, toID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Workspace), args.Error(1)
+}
+
+type MockInvitationRepository struct {
+	mock.Mock
+}
+
+func (m *MockInvitationRepository) Create(ctx context.Context, invitation *Invitation) (*Invitation, error) {
+	args := m.Called(ctx, invitation)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*Invitation, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) ListPending(ctx context.Context, workspaceID string) ([]*Invitation, error) {
+	args := m.Called(ctx, workspaceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) MarkAccepted(ctx context.Context, id primitive.ObjectID) (*Invitation, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) Delete(ctx context.Context, workspaceID string, id primitive.ObjectID) error {
+	args := m.Called(ctx, workspaceID, id)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) DeletePending(ctx context.Context, workspaceID, email string) error {
+	args := m.Called(ctx, workspaceID, email)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) DeleteByWorkspace(ctx context.Context, workspaceID string) (int64, error) {
+	args := m.Called(ctx, workspaceID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockWorkspaceService struct {
+	mock.Mock
+}
+
+func (m *MockWorkspaceService) CreateWorkspace(ctx context.Context, accountID string, req *CreateWorkspaceRequest) (*WorkspaceResponse, error) {
+	args := m.Called(ctx, accountID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*WorkspaceResponse), args.Error(1)
+}
+
+func (m *MockWorkspaceService) ListWorkspaces(ctx context.Context, accountID string) ([]*WorkspaceResponse, error) {
+	args := m.Called(ctx, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*WorkspaceResponse), args.Error(1)
+}
+
+func (m *MockWorkspaceService) GetWorkspace(ctx context.Context, accountID, workspaceID string) (*WorkspaceResponse, error) {
+	args := m.Called(ctx, accountID, workspaceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*WorkspaceResponse), args.Error(1)
+}
+
+func (m *MockWorkspaceService) UpdateWorkspace(ctx context.Context, accountID, workspaceID string, req *UpdateWorkspaceRequest) (*WorkspaceResponse, error) {
+	args := m.Called(ctx, accountID, workspaceID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*WorkspaceResponse), args.Error(1)
+}
+
+func (m *MockWorkspaceService) DeleteWorkspace(ctx context.Context, accountID, workspaceID string) error {
+	args := m.Called(ctx, accountID, workspaceID)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceService) UpdateMemberRole(ctx context.Context, accountID, workspaceID, memberID string, req *UpdateMemberRequest) (*WorkspaceResponse, error) {
+	args := m.Called(ctx, accountID, workspaceID, memberID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*WorkspaceResponse), args.Error(1)
+}
+
+func (m *MockWorkspaceService) RemoveMember(ctx context.Context, accountID, workspaceID, memberID string) error {
+	args := m.Called(ctx, accountID, workspaceID, memberID)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceService) GetMemberRole(ctx context.Context, workspaceID, accountID string) (Role, error) {
+	args := m.Called(ctx, workspaceID, accountID)
+	return args.Get(0).(Role), args.Error(1)
+}
+
+func (m *MockWorkspaceService) PurgeAccount(ctx context.Context, accountID string) error {
+	args := m.Called(ctx, accountID)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceService) OnDelete(hook WorkspaceDeleteHook) {
+	m.Called(hook)
+}
+
+const (
+	testOwnerID  = "64b7f0c2e4b0a1a2b3c4d5e6"
+	testEditorID = "64b7f0c2e4b0a1a2b3c4d5e7"
+	testViewerID = "64b7f0c2e4b0a1a2b3c4d5e8"
+)
+
+// CreateTestWorkspace returns a workspace owned by testOwnerID with an editor
+// and a viewer.
+func CreateTestWorkspace(overrides ...func(*Workspace)) *Workspace {
+	now := time.Now()
+	workspace := &Workspace{
+		ID:          primitive.NewObjectID(),
+		Name:        "Research",
+		Description: "Shared research graph",
+		OwnerID:     testOwnerID,
+		Members: []Member{
+			{AccountID: testOwnerID, Role: RoleAdmin, JoinedAt: now},
+			{AccountID: testEditorID, Role: RoleEditor, JoinedAt: now},
+			{AccountID: testViewerID, Role: RoleViewer, JoinedAt: now},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	for _, override := range overrides {
+		override(workspace)
+	}
+
+	return workspace
+}
+
+func CreateTestInvitation(overrides ...func(*Invitation)) *Invitation {
+	now := time.Now()
+	invitation := &Invitation{
+		ID:          primitive.NewObjectID(),
+		WorkspaceID: primitive.NewObjectID().Hex(),
+		Email:       "invitee@example.com",
+		Role:        RoleEditor,
+		TokenHash:   hashSecret("invitation-token"),
+		InvitedBy:   testOwnerID,
+		ExpiresAt:   now.Add(InvitationExpiry),
+		CreatedAt:   now,
+	}
+
+	for _, override := range overrides {
+		override(invitation)
+	}
+
+	return invitation
+}
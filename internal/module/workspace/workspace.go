@@ -0,0 +1,134 @@
+package workspace
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/container"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/module/account"
+)
+
+const DefaultInvitationURL = "http://localhost:3000/api/v1/workspaces/invitations/accept"
+
+type WorkspaceModule struct {
+	container.BaseModule
+	fromEmail     string
+	invitationURL string
+}
+
+func NewWorkspaceModule(fromEmail string) *WorkspaceModule {
+	return &WorkspaceModule{
+		BaseModule: container.NewBaseModule(
+			"workspace",
+			"1.0.0",
+			"Workspaces with members, roles and email invitations",
+			[]string{"account"},
+		),
+		fromEmail:     fromEmail,
+		invitationURL: DefaultInvitationURL,
+	}
+}
+
+// WithInvitationURL sets the link sent in invitation emails. The invitation
+// token is added as the token query parameter.
+func (m *WorkspaceModule) WithInvitationURL(invitationURL string) *WorkspaceModule {
+	m.invitationURL = invitationURL
+	return m
+}
+
+func (m *WorkspaceModule) RegisterServices(registry *container.ServiceRegistry) error {
+	mongoService := registry.GetMongo()
+	if mongoService == nil {
+		return container.ServiceNotFoundError{ServiceName: "mongo"}
+	}
+
+	accountServiceInterface, err := registry.GetService("account")
+	if err != nil {
+		return err
+	}
+
+	workspaceService := NewWorkspaceService(mongoService)
+	if err := registry.RegisterService("workspace", workspaceService); err != nil {
+		return err
+	}
+
+	invitationService, err := NewInvitationService(mongoService, accountServiceInterface.(account.AccountService), registry.GetResend(), m.fromEmail, m.invitationURL)
+	if err != nil {
+		return err
+	}
+	if err := registry.RegisterService("workspace_invitation", invitationService); err != nil {
+		return err
+	}
+
+	// Other modules resolve :workspaceId and check membership with the same
+	// middleware.
+	if err := registry.RegisterService("workspace_middleware", NewWorkspaceMiddleware(workspaceService)); err != nil {
+		return err
+	}
+
+	if purgerInterface, err := registry.GetService("account_purger"); err == nil {
+		purgerInterface.(account.AccountPurger).OnPurge(func(ctx context.Context, purged *account.Account) error {
+			return workspaceService.PurgeAccount(ctx, purged.ID.Hex())
+		})
+	}
+
+	return nil
+}
+
+func (m *WorkspaceModule) RegisterMiddleware(registry *container.ServiceRegistry) error {
+	return nil
+}
+
+func (m *WorkspaceModule) RegisterRoutes(router fiber.Router, registry *container.ServiceRegistry) error {
+	workspaceServiceInterface, err := registry.GetService("workspace")
+	if err != nil {
+		return err
+	}
+
+	invitationServiceInterface, err := registry.GetService("workspace_invitation")
+	if err != nil {
+		return err
+	}
+
+	handler := NewWorkspaceHandler(workspaceServiceInterface.(WorkspaceService))
+	invitationHandler := NewInvitationHandler(invitationServiceInterface.(InvitationService))
+
+	accountMiddlewareInterface, err := registry.GetService("account_middleware")
+	if err != nil {
+		return err
+	}
+
+	workspaceMiddlewareInterface, err := registry.GetService("workspace_middleware")
+	if err != nil {
+		return err
+	}
+
+	middleware := accountMiddlewareInterface.(*account.AccountMiddleware)
+	members := workspaceMiddlewareInterface.(*WorkspaceMiddleware)
+	auth := middleware.RequireAuth()
+	firstParty := middleware.RequireFirstParty()
+	read := middleware.RequireScope(account.OAuthScopeKnowledgeRead)
+	write := middleware.RequireScope(account.OAuthScopeKnowledgeWrite)
+
+	// Authentication is added per route rather than on the group, as the
+	// knowledge module adds its own routes below /workspaces/:workspaceId.
+	workspaces := router.Group("/workspaces")
+
+	workspaces.Get("/", auth, read, handler.ListWorkspaces)
+	workspaces.Post("/", auth, write, handler.CreateWorkspace)
+	workspaces.Post("/invitations/accept", auth, firstParty, invitationHandler.AcceptInvitation)
+
+	workspaces.Get("/:workspaceId", auth, read, members.RequireRole(RoleViewer), handler.GetWorkspace)
+	workspaces.Patch("/:workspaceId", auth, write, members.RequireRole(RoleAdmin), handler.UpdateWorkspace)
+	workspaces.Delete("/:workspaceId", auth, firstParty, members.RequireRole(RoleAdmin), handler.DeleteWorkspace)
+
+	workspaces.Patch("/:workspaceId/members/:accountId", auth, firstParty, members.RequireRole(RoleAdmin), handler.UpdateMemberRole)
+	workspaces.Delete("/:workspaceId/members/:accountId", auth, firstParty, members.RequireRole(RoleViewer), handler.RemoveMember)
+
+	workspaces.Get("/:workspaceId/invitations", auth, firstParty, members.RequireRole(RoleAdmin), invitationHandler.ListInvitations)
+	workspaces.Post("/:workspaceId/invitations", auth, firstParty, members.RequireRole(RoleAdmin), invitationHandler.InviteMember)
+	workspaces.Delete("/:workspaceId/invitations/:invitationId", auth, firstParty, members.RequireRole(RoleAdmin), invitationHandler.RevokeInvitation)
+
+	return nil
+}
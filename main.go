@@ -2,13 +2,20 @@ package main
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 
 	_ "github.com/yothgewalt/relational-knowledge-engineering-platform-server/docs"
 	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/container"
 	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/module/account"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/module/document"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/module/knowledge"
 	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/module/telemetry"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/internal/module/workspace"
+	"github.com/yothgewalt/relational-knowledge-engineering-platform-server/package/argon2"
 )
 
 // @title Relational Knowledge Engineering Platform API
@@ -26,7 +33,15 @@ import (
 func main() {
 	_ = godotenv.Load()
 
-	opts := container.Options{Timezone: "Asia/Bangkok"}
+	documentMaxSize := document.DefaultMaxDocumentSize
+	if size, err := strconv.ParseInt(os.Getenv("SERVER_UPLOAD_MAX_SIZE_MB"), 10, 64); err == nil && size > 0 {
+		documentMaxSize = size << 20
+	}
+
+	opts := container.Options{
+		Timezone:      "Asia/Bangkok",
+		MaxUploadSize: documentMaxSize,
+	}
 	c := container.New(&opts)
 
 	fromEmail := os.Getenv("FROM_EMAIL")
@@ -34,19 +49,204 @@ func main() {
 		fromEmail = "noreply@example.com"
 	}
 
+	passkeyConfig := account.PasskeyConfig{
+		RPID:          os.Getenv("WEBAUTHN_RP_ID"),
+		RPDisplayName: os.Getenv("WEBAUTHN_RP_DISPLAY_NAME"),
+		RPOrigins:     strings.Split(os.Getenv("WEBAUTHN_RP_ORIGINS"), ","),
+	}
+	if passkeyConfig.RPID == "" {
+		passkeyConfig.RPID = "localhost"
+	}
+	if passkeyConfig.RPDisplayName == "" {
+		passkeyConfig.RPDisplayName = "Relational Knowledge Engineering Platform"
+	}
+	if os.Getenv("WEBAUTHN_RP_ORIGINS") == "" {
+		passkeyConfig.RPOrigins = []string{"http://localhost:3000"}
+	}
+
+	magicLinkConfig := account.MagicLinkConfig{
+		URL:         os.Getenv("MAGIC_LINK_URL"),
+		IncludeCode: os.Getenv("MAGIC_LINK_INCLUDE_CODE") == "true",
+	}
+	if magicLinkConfig.URL == "" {
+		magicLinkConfig.URL = "http://localhost:3000/api/v1/accounts/login/magic/verify"
+	}
+
+	auditRetention := account.DefaultAuditRetention
+	if days, err := strconv.Atoi(os.Getenv("AUDIT_RETENTION_DAYS")); err == nil && days >= 0 {
+		auditRetention = time.Duration(days) * 24 * time.Hour
+	}
+
+	loginReportURL := os.Getenv("LOGIN_REPORT_URL")
+	if loginReportURL == "" {
+		loginReportURL = "http://localhost:3000/api/v1/accounts/login/report"
+	}
+
+	invitationURL := os.Getenv("WORKSPACE_INVITATION_URL")
+	if invitationURL == "" {
+		invitationURL = workspace.DefaultInvitationURL
+	}
+
+	passwordPolicy, err := loadPasswordPolicy()
+	if err != nil {
+		panic(err)
+	}
+
 	telemetryModule := telemetry.NewTelemetryModule()
 	if err := c.RegisterModule(telemetryModule); err != nil {
 		panic(err)
 	}
 
-	accountModule := account.NewAccountModule(fromEmail)
+	accountModule := account.NewAccountModule(fromEmail).
+		WithPasskeyConfig(passkeyConfig).
+		WithMagicLinkConfig(magicLinkConfig).
+		WithOIDCProviders(loadOIDCProviders()...).
+		WithAuditRetention(auditRetention).
+		WithLoginReportURL(loginReportURL).
+		WithPasswordPolicy(passwordPolicy).
+		WithPasswordHashConfig(loadPasswordHashConfig()).
+		WithBootstrapAdmins(strings.Split(os.Getenv("ADMIN_BOOTSTRAP_EMAILS"), ",")...)
 	if err := c.RegisterModule(accountModule); err != nil {
 		panic(err)
 	}
 
+	workspaceModule := workspace.NewWorkspaceModule(fromEmail).
+		WithInvitationURL(invitationURL)
+	if err := c.RegisterModule(workspaceModule); err != nil {
+		panic(err)
+	}
+
+	documentModule := document.NewDocumentModule().
+		WithMaxSize(documentMaxSize)
+	if err := c.RegisterModule(documentModule); err != nil {
+		panic(err)
+	}
+
+	knowledgeModule := knowledge.NewKnowledgeModule().
+		WithExtractors(loadExtractors()...).
+		WithMaxImportSize(documentMaxSize)
+	if err := c.RegisterModule(knowledgeModule); err != nil {
+		panic(err)
+	}
+
 	if err := c.Bootstrap(); err != nil {
 		panic(err)
 	}
 
 	c.WaitForShutdown()
 }
+
+// loadPasswordPolicy starts from account.DefaultPasswordPolicy and applies the
+// PASSWORD_* variables that are set.
+func loadPasswordPolicy() (account.PasswordPolicy, error) {
+	policy := account.DefaultPasswordPolicy()
+
+	if length, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_LENGTH")); err == nil && length > 0 {
+		policy.MinLength = length
+	}
+	if size, err := strconv.Atoi(os.Getenv("PASSWORD_HISTORY")); err == nil && size >= 0 {
+		policy.HistorySize = size
+	}
+	if days, err := strconv.Atoi(os.Getenv("PASSWORD_MAX_AGE_DAYS")); err == nil && days >= 0 {
+		policy.MaxAge = time.Duration(days) * 24 * time.Hour
+	}
+	policy.RequireUpper = os.Getenv("PASSWORD_REQUIRE_UPPER") == "true"
+	policy.RequireLower = os.Getenv("PASSWORD_REQUIRE_LOWER") == "true"
+	policy.RequireDigit = os.Getenv("PASSWORD_REQUIRE_DIGIT") == "true"
+	policy.RequireSymbol = os.Getenv("PASSWORD_REQUIRE_SYMBOL") == "true"
+
+	if path := os.Getenv("PASSWORD_BREACH_CORPUS"); path != "" {
+		corpus, err := account.LoadBreachCorpus(path)
+		if err != nil {
+			return policy, err
+		}
+		policy.Breaches = corpus
+	}
+
+	return policy, nil
+}
+
+// loadPasswordHashConfig starts from argon2.DefaultParams and applies the
+// ARGON2_* variables that are set. PASSWORD_PEPPER_VAULT_PATH enables the
+// pepper.
+func loadPasswordHashConfig() account.PasswordHashConfig {
+	params := argon2.DefaultParams()
+
+	if memory, err := strconv.ParseUint(os.Getenv("ARGON2_MEMORY_KIB"), 10, 32); err == nil && memory > 0 {
+		params.Memory = uint32(memory)
+	}
+	if iterations, err := strconv.ParseUint(os.Getenv("ARGON2_ITERATIONS"), 10, 32); err == nil && iterations > 0 {
+		params.Iterations = uint32(iterations)
+	}
+	if parallelism, err := strconv.ParseUint(os.Getenv("ARGON2_PARALLELISM"), 10, 8); err == nil && parallelism > 0 {
+		params.Parallelism = uint8(parallelism)
+	}
+
+	return account.PasswordHashConfig{
+		Params:     params,
+		PepperPath: os.Getenv("PASSWORD_PEPPER_VAULT_PATH"),
+	}
+}
+
+// loadOIDCProviders reads the comma-separated OIDC_PROVIDERS list and, for
+// each name, the OIDC_<NAME>_* variables describing that provider.
+func loadOIDCProviders() []account.OIDCProviderConfig {
+	var providers []account.OIDCProviderConfig
+
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		providers = append(providers, account.OIDCProviderConfig{
+			Name:         name,
+			DisplayName:  os.Getenv(prefix + "DISPLAY_NAME"),
+			IssuerURL:    os.Getenv(prefix + "ISSUER_URL"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(strings.ReplaceAll(os.Getenv(prefix+"SCOPES"), ",", " ")),
+		})
+	}
+
+	return providers
+}
+
+// loadExtractors reads the external extraction services named in EXTRACTORS
+// from their EXTRACTOR_<NAME>_* variables. Unset or invalid numbers fall back
+// to the defaults of knowledge.NewHTTPExtractor.
+func loadExtractors() []knowledge.HTTPExtractorConfig {
+	var extractors []knowledge.HTTPExtractorConfig
+
+	for _, name := range strings.Split(os.Getenv("EXTRACTORS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		prefix := "EXTRACTOR_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		config := knowledge.HTTPExtractorConfig{
+			Name:    name,
+			URL:     os.Getenv(prefix + "URL"),
+			Token:   os.Getenv(prefix + "TOKEN"),
+			Version: os.Getenv(prefix + "VERSION"),
+		}
+		if timeout, err := time.ParseDuration(os.Getenv(prefix + "TIMEOUT")); err == nil {
+			config.Timeout = timeout
+		}
+		if attempts, err := strconv.Atoi(os.Getenv(prefix + "MAX_ATTEMPTS")); err == nil {
+			config.MaxAttempts = attempts
+		}
+		if size, err := strconv.Atoi(os.Getenv(prefix + "BATCH_SIZE")); err == nil {
+			config.BatchSize = size
+		}
+		if size, err := strconv.Atoi(os.Getenv(prefix + "SEGMENT_SIZE")); err == nil {
+			config.SegmentSize = size
+		}
+		extractors = append(extractors, config)
+	}
+
+	return extractors
+}
@@ -1,11 +1,15 @@
 package argon2
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/base64"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync/atomic"
 
 	goArgon2 "golang.org/x/crypto/argon2"
 )
@@ -18,26 +22,150 @@ const (
 	Argon2KeyLength   = 32
 )
 
+// Params are the argon2id cost parameters used for new hashes. Existing
+// hashes keep the parameters they were created with, which are encoded in
+// the hash itself.
+type Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+func DefaultParams() Params {
+	return Params{
+		Memory:      Argon2Memory,
+		Iterations:  Argon2Iterations,
+		Parallelism: Argon2Parallelism,
+		SaltLength:  Argon2SaltLength,
+		KeyLength:   Argon2KeyLength,
+	}
+}
+
+func (p Params) validate() error {
+	if p.Memory < 8*uint32(p.Parallelism) {
+		return fmt.Errorf("memory must be at least 8 KiB per lane")
+	}
+	if p.Iterations < 1 {
+		return fmt.Errorf("iterations must be at least 1")
+	}
+	if p.Parallelism < 1 {
+		return fmt.Errorf("parallelism must be at least 1")
+	}
+	if p.SaltLength < 8 {
+		return fmt.Errorf("salt length must be at least 8 bytes")
+	}
+	if p.KeyLength < 16 {
+		return fmt.Errorf("key length must be at least 16 bytes")
+	}
+
+	return nil
+}
+
+// Pepper is a server-side secret mixed into every password before hashing.
+// Hashes record the version of the pepper they were made with so that a new
+// pepper can be introduced while old hashes still verify.
+type Pepper struct {
+	Version int
+	Secret  []byte
+}
+
+// Hasher hashes passwords with fixed parameters and, optionally, a pepper.
+// New hashes use the pepper with the highest version; the others are kept to
+// verify older hashes.
+type Hasher struct {
+	params  Params
+	current *Pepper
+	peppers map[int][]byte
+}
+
+func NewHasher(params Params, peppers ...Pepper) (*Hasher, error) {
+	if err := params.validate(); err != nil {
+		return nil, fmt.Errorf("invalid argon2 parameters: %w", err)
+	}
+
+	hasher := &Hasher{
+		params:  params,
+		peppers: make(map[int][]byte, len(peppers)),
+	}
+
+	for i := range peppers {
+		pepper := peppers[i]
+		if pepper.Version < 1 {
+			return nil, fmt.Errorf("invalid pepper version %d: versions start at 1", pepper.Version)
+		}
+		if len(pepper.Secret) == 0 {
+			return nil, fmt.Errorf("pepper version %d is empty", pepper.Version)
+		}
+		if _, exists := hasher.peppers[pepper.Version]; exists {
+			return nil, fmt.Errorf("duplicate pepper version %d", pepper.Version)
+		}
+
+		hasher.peppers[pepper.Version] = pepper.Secret
+		if hasher.current == nil || pepper.Version > hasher.current.Version {
+			hasher.current = &pepper
+		}
+	}
+
+	return hasher, nil
+}
+
+var defaultHasher atomic.Pointer[Hasher]
+
+func init() {
+	hasher, _ := NewHasher(DefaultParams())
+	defaultHasher.Store(hasher)
+}
+
+// SetDefault replaces the hasher behind HashPassword, VerifyPassword and
+// NeedsRehash. It is meant to be called once at startup.
+func SetDefault(hasher *Hasher) {
+	defaultHasher.Store(hasher)
+}
+
 func HashPassword(password string) (string, error) {
+	return defaultHasher.Load().Hash(password)
+}
+
+func VerifyPassword(password, encodedHash string) (bool, error) {
+	return defaultHasher.Load().Verify(password, encodedHash)
+}
+
+// NeedsRehash reports whether encodedHash was made with other parameters or
+// another pepper than the ones new hashes use.
+func NeedsRehash(encodedHash string) bool {
+	return defaultHasher.Load().NeedsRehash(encodedHash)
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
 	if password == "" {
 		return "", fmt.Errorf("password cannot be empty")
 	}
 
-	salt := make([]byte, Argon2SaltLength)
+	salt := make([]byte, h.params.SaltLength)
 	if _, err := rand.Read(salt); err != nil {
 		return "", fmt.Errorf("failed to generate salt: %w", err)
 	}
 
-	hash := goArgon2.IDKey([]byte(password), salt, Argon2Iterations, Argon2Memory, Argon2Parallelism, Argon2KeyLength)
+	decoded := decodedHash{
+		version: goArgon2.Version,
+		params:  h.params,
+		salt:    salt,
+	}
+
+	input := []byte(password)
+	if h.current != nil {
+		decoded.pepperVersion = h.current.Version
+		input = pepper(h.current.Secret, password)
+	}
 
-	saltB64 := base64.StdEncoding.EncodeToString(salt)
-	hashB64 := base64.StdEncoding.EncodeToString(hash)
+	decoded.hash = goArgon2.IDKey(input, salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
 
-	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
-		goArgon2.Version, Argon2Memory, Argon2Iterations, Argon2Parallelism, saltB64, hashB64), nil
+	return decoded.encode(), nil
 }
 
-func VerifyPassword(password, encodedHash string) (bool, error) {
+func (h *Hasher) Verify(password, encodedHash string) (bool, error) {
 	if password == "" {
 		return false, fmt.Errorf("password cannot be empty")
 	}
@@ -46,39 +174,156 @@ func VerifyPassword(password, encodedHash string) (bool, error) {
 		return false, fmt.Errorf("hash cannot be empty")
 	}
 
+	decoded, err := decodeHash(encodedHash)
+	if err != nil {
+		return false, err
+	}
+
+	input := []byte(password)
+	if decoded.pepperVersion != 0 {
+		secret, ok := h.peppers[decoded.pepperVersion]
+		if !ok {
+			return false, fmt.Errorf("unknown pepper version %d", decoded.pepperVersion)
+		}
+		input = pepper(secret, password)
+	}
+
+	params := decoded.params
+	expectedHash := goArgon2.IDKey(input, decoded.salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(decoded.hash)))
+
+	return subtle.ConstantTimeCompare(decoded.hash, expectedHash) == 1, nil
+}
+
+func (h *Hasher) NeedsRehash(encodedHash string) bool {
+	decoded, err := decodeHash(encodedHash)
+	if err != nil {
+		return false
+	}
+
+	currentPepper := 0
+	if h.current != nil {
+		currentPepper = h.current.Version
+	}
+
+	return decoded.version != goArgon2.Version ||
+		decoded.params.Memory != h.params.Memory ||
+		decoded.params.Iterations != h.params.Iterations ||
+		decoded.params.Parallelism != h.params.Parallelism ||
+		uint32(len(decoded.salt)) != h.params.SaltLength ||
+		uint32(len(decoded.hash)) != h.params.KeyLength ||
+		decoded.pepperVersion != currentPepper
+}
+
+func pepper(secret []byte, password string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(password))
+	return mac.Sum(nil)
+}
+
+// decodedHash is a hash in the PHC string format,
+// $argon2id$v=19$m=65536,t=3,p=2[,pv=1]$salt$hash, where pv is the pepper
+// version and is left out for unpeppered hashes.
+type decodedHash struct {
+	version       int
+	params        Params
+	pepperVersion int
+	salt          []byte
+	hash          []byte
+}
+
+func (d decodedHash) encode() string {
+	parameters := fmt.Sprintf("m=%d,t=%d,p=%d", d.params.Memory, d.params.Iterations, d.params.Parallelism)
+	if d.pepperVersion != 0 {
+		parameters += ",pv=" + strconv.Itoa(d.pepperVersion)
+	}
+
+	return fmt.Sprintf("$argon2id$v=%d$%s$%s$%s",
+		d.version, parameters,
+		base64.StdEncoding.EncodeToString(d.salt),
+		base64.StdEncoding.EncodeToString(d.hash))
+}
+
+func decodeHash(encodedHash string) (*decodedHash, error) {
 	parts := strings.Split(encodedHash, "$")
 	if len(parts) != 6 {
-		return false, fmt.Errorf("invalid hash format: expected 6 parts, got %d", len(parts))
+		return nil, fmt.Errorf("invalid hash format: expected 6 parts, got %d", len(parts))
 	}
 
 	if parts[1] != "argon2id" {
-		return false, fmt.Errorf("unsupported hash type: %s", parts[1])
+		return nil, fmt.Errorf("unsupported hash type: %s", parts[1])
 	}
 
-	var version int
-	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
-		return false, fmt.Errorf("failed to parse version: %w", err)
+	decoded := &decodedHash{}
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &decoded.version); err != nil {
+		return nil, fmt.Errorf("failed to parse version: %w", err)
 	}
 
-	var memory, iterations uint32
-	var parallelism uint8
-	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &parallelism); err != nil {
-		return false, fmt.Errorf("failed to parse parameters: %w", err)
+	if err := decoded.parseParameters(parts[3]); err != nil {
+		return nil, fmt.Errorf("failed to parse parameters: %w", err)
 	}
 
 	salt, err := base64.StdEncoding.DecodeString(parts[4])
 	if err != nil {
-		return false, fmt.Errorf("failed to decode salt: %w", err)
+		return nil, fmt.Errorf("failed to decode salt: %w", err)
 	}
 
 	hash, err := base64.StdEncoding.DecodeString(parts[5])
 	if err != nil {
-		return false, fmt.Errorf("failed to decode hash: %w", err)
+		return nil, fmt.Errorf("failed to decode hash: %w", err)
 	}
 
-	expectedHash := goArgon2.IDKey([]byte(password), salt, iterations, memory, parallelism, uint32(len(hash)))
+	decoded.salt = salt
+	decoded.hash = hash
+	decoded.params.SaltLength = uint32(len(salt))
+	decoded.params.KeyLength = uint32(len(hash))
+
+	return decoded, nil
+}
+
+func (d *decodedHash) parseParameters(encoded string) error {
+	seen := make(map[string]bool)
+
+	for _, parameter := range strings.Split(encoded, ",") {
+		key, value, ok := strings.Cut(parameter, "=")
+		if !ok {
+			return fmt.Errorf("malformed parameter %q", parameter)
+		}
+
+		var bits int
+		switch key {
+		case "m", "t":
+			bits = 32
+		case "p":
+			bits = 8
+		case "pv":
+			bits = 31
+		default:
+			return fmt.Errorf("unknown parameter %q", key)
+		}
+
+		number, err := strconv.ParseUint(value, 10, bits)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+
+		switch key {
+		case "m":
+			d.params.Memory = uint32(number)
+		case "t":
+			d.params.Iterations = uint32(number)
+		case "p":
+			d.params.Parallelism = uint8(number)
+		case "pv":
+			d.pepperVersion = int(number)
+		}
+		seen[key] = true
+	}
+
+	if !seen["m"] || !seen["t"] || !seen["p"] {
+		return fmt.Errorf("m, t and p are required")
+	}
 
-	return subtle.ConstantTimeCompare(hash, expectedHash) == 1, nil
+	return nil
 }
 
 func IsArgon2Hash(hash string) bool {
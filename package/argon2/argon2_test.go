@@ -350,3 +350,77 @@ func BenchmarkVerifyPassword_Wrong(b *testing.B) {
 		}
 	}
 }
+
+func TestNewHasher_InvalidConfig(t *testing.T) {
+	params := DefaultParams()
+	params.Iterations = 0
+	_, err := NewHasher(params)
+	assert.Error(t, err)
+
+	_, err = NewHasher(DefaultParams(), Pepper{Version: 1, Secret: []byte("a")}, Pepper{Version: 1, Secret: []byte("b")})
+	assert.Error(t, err)
+
+	_, err = NewHasher(DefaultParams(), Pepper{Version: 0, Secret: []byte("a")})
+	assert.Error(t, err)
+}
+
+func TestHasher_NeedsRehash(t *testing.T) {
+	legacy, err := NewHasher(Params{Memory: 16 * 1024, Iterations: 2, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	require.NoError(t, err)
+	legacyHash, err := legacy.Hash("password")
+	require.NoError(t, err)
+
+	current, err := NewHasher(DefaultParams())
+	require.NoError(t, err)
+	currentHash, err := current.Hash("password")
+	require.NoError(t, err)
+
+	assert.True(t, current.NeedsRehash(legacyHash))
+	assert.False(t, current.NeedsRehash(currentHash))
+	assert.False(t, current.NeedsRehash("not-a-hash"))
+
+	valid, err := current.Verify("password", legacyHash)
+	require.NoError(t, err)
+	assert.True(t, valid, "hashes made with older parameters still verify")
+}
+
+func TestHasher_PepperRotation(t *testing.T) {
+	v1 := Pepper{Version: 1, Secret: []byte("first-pepper")}
+	v2 := Pepper{Version: 2, Secret: []byte("second-pepper")}
+
+	unpeppered, err := NewHasher(DefaultParams())
+	require.NoError(t, err)
+	plainHash, err := unpeppered.Hash("password")
+	require.NoError(t, err)
+
+	first, err := NewHasher(DefaultParams(), v1)
+	require.NoError(t, err)
+	firstHash, err := first.Hash("password")
+	require.NoError(t, err)
+	assert.Contains(t, firstHash, ",pv=1$")
+
+	rotated, err := NewHasher(DefaultParams(), v2, v1)
+	require.NoError(t, err)
+
+	for _, hash := range []string{plainHash, firstHash} {
+		valid, err := rotated.Verify("password", hash)
+		require.NoError(t, err)
+		assert.True(t, valid)
+		assert.True(t, rotated.NeedsRehash(hash))
+	}
+
+	rotatedHash, err := rotated.Hash("password")
+	require.NoError(t, err)
+	assert.Contains(t, rotatedHash, ",pv=2$")
+	assert.False(t, rotated.NeedsRehash(rotatedHash))
+
+	valid, err := unpeppered.Verify("password", firstHash)
+	assert.False(t, valid)
+	assert.ErrorContains(t, err, "unknown pepper version 1")
+
+	wrongPepper, err := NewHasher(DefaultParams(), Pepper{Version: 1, Secret: []byte("other")})
+	require.NoError(t, err)
+	valid, err = wrongPepper.Verify("password", firstHash)
+	require.NoError(t, err)
+	assert.False(t, valid)
+}
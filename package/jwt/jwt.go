@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 var (
@@ -59,12 +60,24 @@ func NewJWTService(config JWTConfig) (*JWTService, error) {
 }
 
 func (s *JWTService) Generate(customClaims map[string]any) (string, error) {
+	return s.GenerateWithDuration(customClaims, s.GetConfig().TokenDuration)
+}
+
+// GenerateWithDuration signs a token that expires after the given duration
+// instead of the configured default. Every token carries a unique ID so that
+// two tokens issued within the same second never collide.
+func (s *JWTService) GenerateWithDuration(customClaims map[string]any, duration time.Duration) (string, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	if duration <= 0 {
+		duration = s.config.TokenDuration
+	}
+
 	claims := JWTClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.config.TokenDuration)),
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    s.config.Issuer,
@@ -56,7 +56,9 @@ type Repository[T any] interface {
 	FindOne(ctx context.Context, filter bson.M, opts ...*options.FindOneOptions) (*T, error)
 	Create(ctx context.Context, document T) (*T, error)
 	Update(ctx context.Context, filter bson.M, update bson.M, opts ...*options.UpdateOptions) (*T, error)
+	UpdateMany(ctx context.Context, filter bson.M, update bson.M, opts ...*options.UpdateOptions) (int64, error)
 	Delete(ctx context.Context, filter bson.M, opts ...*options.DeleteOptions) error
+	DeleteMany(ctx context.Context, filter bson.M, opts ...*options.DeleteOptions) (int64, error)
 	Count(ctx context.Context, filter bson.M, opts ...*options.CountOptions) (int64, error)
 }
 
@@ -229,6 +231,17 @@ func (r *GenericRepository[T]) Update(ctx context.Context, filter bson.M, update
 	return &result, nil
 }
 
+// UpdateMany applies update to every matching document and returns how many
+// were modified.
+func (r *GenericRepository[T]) UpdateMany(ctx context.Context, filter bson.M, update bson.M, opts ...*options.UpdateOptions) (int64, error) {
+	result, err := r.collection.UpdateMany(ctx, filter, update, opts...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update documents: %w", err)
+	}
+
+	return result.ModifiedCount, nil
+}
+
 func (r *GenericRepository[T]) Delete(ctx context.Context, filter bson.M, opts ...*options.DeleteOptions) error {
 	result, err := r.collection.DeleteOne(ctx, filter, opts...)
 	if err != nil {
@@ -242,6 +255,17 @@ func (r *GenericRepository[T]) Delete(ctx context.Context, filter bson.M, opts .
 	return nil
 }
 
+// DeleteMany removes every matching document and, unlike Delete, does not
+// treat an empty match as an error.
+func (r *GenericRepository[T]) DeleteMany(ctx context.Context, filter bson.M, opts ...*options.DeleteOptions) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, filter, opts...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete documents: %w", err)
+	}
+
+	return result.DeletedCount, nil
+}
+
 func (r *GenericRepository[T]) FindWithPagination(ctx context.Context, filter bson.M, pagination PaginationOptions, opts ...*options.FindOptions) (*PaginatedResult[T], error) {
 	if pagination.Page <= 0 {
 		pagination.Page = 1
@@ -36,6 +36,9 @@ type Neo4jService interface {
 	RunWrite(ctx context.Context, cypher string, params map[string]interface{}) (neo4j.ResultWithContext, error)
 	RunRead(ctx context.Context, cypher string, params map[string]interface{}) (neo4j.ResultWithContext, error)
 
+	ExecuteRead(ctx context.Context, cypher string, params map[string]interface{}) ([]*neo4j.Record, error)
+	ExecuteWrite(ctx context.Context, cypher string, params map[string]interface{}) ([]*neo4j.Record, error)
+
 	CreateNode(ctx context.Context, labels []string, properties map[string]interface{}) (string, error)
 	GetNode(ctx context.Context, id string) (map[string]interface{}, error)
 	UpdateNode(ctx context.Context, id string, properties map[string]interface{}) error
@@ -211,6 +214,52 @@ func (n *Neo4jClient) RunRead(ctx context.Context, cypher string, params map[str
 	return session.Run(ctx, cypher, params)
 }
 
+// ExecuteRead runs cypher in a managed read transaction and returns every
+// record. Unlike RunRead, the records stay usable after the session closes
+// and transient errors are retried.
+func (n *Neo4jClient) ExecuteRead(ctx context.Context, cypher string, params map[string]interface{}) ([]*neo4j.Record, error) {
+	return n.execute(ctx, neo4j.AccessModeRead, cypher, params)
+}
+
+// ExecuteWrite runs cypher in a managed write transaction and returns every
+// record. The transaction may be retried, so cypher must be safe to repeat.
+func (n *Neo4jClient) ExecuteWrite(ctx context.Context, cypher string, params map[string]interface{}) ([]*neo4j.Record, error) {
+	return n.execute(ctx, neo4j.AccessModeWrite, cypher, params)
+}
+
+func (n *Neo4jClient) execute(ctx context.Context, accessMode neo4j.AccessMode, cypher string, params map[string]interface{}) ([]*neo4j.Record, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	session := n.driver.NewSession(ctx, neo4j.SessionConfig{
+		DatabaseName: n.config.Database,
+		AccessMode:   accessMode,
+	})
+	defer session.Close(ctx)
+
+	work := func(tx neo4j.ManagedTransaction) (any, error) {
+		result, err := tx.Run(ctx, cypher, params)
+		if err != nil {
+			return nil, err
+		}
+
+		return result.Collect(ctx)
+	}
+
+	var records any
+	var err error
+	if accessMode == neo4j.AccessModeRead {
+		records, err = session.ExecuteRead(ctx, work)
+	} else {
+		records, err = session.ExecuteWrite(ctx, work)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return records.([]*neo4j.Record), nil
+}
+
 // Node operations
 func (n *Neo4jClient) CreateNode(ctx context.Context, labels []string, properties map[string]interface{}) (string, error) {
 	n.mu.RLock()
@@ -236,7 +236,7 @@ func TestNeo4jService_InterfaceCompleteness(t *testing.T) {
 
 	expectedMethods := []string{
 		"HealthCheck", "GetDriver", "Close",
-		"Run", "RunWrite", "RunRead",
+		"Run", "RunWrite", "RunRead", "ExecuteRead", "ExecuteWrite",
 		"CreateNode", "GetNode", "UpdateNode", "DeleteNode",
 		"CreateRelationship", "DeleteRelationship",
 		"FindNodes", "FindPath", "BeginTransaction",
@@ -276,6 +276,8 @@ func TestNeo4jClient_MethodSignatures(t *testing.T) {
 		{"Run", 4, 2, true},          // (receiver, context, cypher, params) -> (result, error)
 		{"RunWrite", 4, 2, true},     // (receiver, context, cypher, params) -> (result, error)
 		{"RunRead", 4, 2, true},      // (receiver, context, cypher, params) -> (result, error)
+		{"ExecuteRead", 4, 2, true},  // (receiver, context, cypher, params) -> (records, error)
+		{"ExecuteWrite", 4, 2, true}, // (receiver, context, cypher, params) -> (records, error)
 		{"CreateNode", 4, 2, true},   // (receiver, context, labels, properties) -> (string, error)
 		{"GetNode", 3, 2, true},      // (receiver, context, id) -> (map, error)
 		{"UpdateNode", 4, 1, true},   // (receiver, context, id, properties) -> error
@@ -0,0 +1,205 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Rule allows at most Limit events within any Window long period.
+type Rule struct {
+	Limit  int
+	Window time.Duration
+}
+
+type Result struct {
+	Allowed    bool          `json:"allowed"`
+	Limit      int           `json:"limit"`
+	Remaining  int           `json:"remaining"`
+	RetryAfter time.Duration `json:"retry_after"`
+}
+
+type Limiter interface {
+	Allow(ctx context.Context, key string, rule Rule) (*Result, error)
+	Reset(ctx context.Context, keys ...string) error
+}
+
+// ExceededError is returned by callers that turn a rejected Result into an
+// error. Handlers use RetryAfter for the Retry-After response header.
+type ExceededError struct {
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("too many requests: %s", e.Reason)
+}
+
+// RetryAfterSeconds rounds up to whole seconds, as required by the
+// Retry-After header, and never returns less than one.
+func RetryAfterSeconds(d time.Duration) int {
+	seconds := int(math.Ceil(d.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// slidingWindowScript keeps one sorted set member per accepted event, scored
+// by its time in milliseconds, and drops members older than the window.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+
+local count = redis.call('ZCARD', key)
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	redis.call('PEXPIRE', key, window)
+	return {1, limit - count - 1, 0}
+end
+
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+return {0, 0, tonumber(oldest[2]) + window - now}
+`)
+
+type RedisLimiter struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisLimiter(client *redis.Client, prefix string) *RedisLimiter {
+	return &RedisLimiter{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string, rule Rule) (*Result, error) {
+	if err := rule.validate(); err != nil {
+		return nil, err
+	}
+
+	values, err := slidingWindowScript.Run(ctx, l.client, []string{l.key(key)},
+		time.Now().UnixMilli(),
+		rule.Window.Milliseconds(),
+		rule.Limit,
+		uuid.NewString(),
+	).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate rate limit: %w", err)
+	}
+
+	return &Result{
+		Allowed:    values[0] == 1,
+		Limit:      rule.Limit,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
+
+func (l *RedisLimiter) Reset(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = l.key(key)
+	}
+
+	if err := l.client.Del(ctx, prefixed...).Err(); err != nil {
+		return fmt.Errorf("failed to reset rate limit: %w", err)
+	}
+
+	return nil
+}
+
+func (l *RedisLimiter) key(key string) string {
+	if l.prefix == "" {
+		return key
+	}
+	return l.prefix + ":" + key
+}
+
+// MemoryLimiter applies the same sliding window within a single process. It
+// is meant for tests and for deployments running without Redis.
+type MemoryLimiter struct {
+	mu     sync.Mutex
+	events map[string][]time.Time
+	now    func() time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		events: make(map[string][]time.Time),
+		now:    time.Now,
+	}
+}
+
+func (l *MemoryLimiter) Allow(ctx context.Context, key string, rule Rule) (*Result, error) {
+	if err := rule.validate(); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	cutoff := now.Add(-rule.Window)
+
+	events := l.events[key]
+	kept := events[:0]
+	for _, at := range events {
+		if at.After(cutoff) {
+			kept = append(kept, at)
+		}
+	}
+
+	if len(kept) >= rule.Limit {
+		l.events[key] = kept
+		return &Result{
+			Allowed:    false,
+			Limit:      rule.Limit,
+			Remaining:  0,
+			RetryAfter: kept[0].Add(rule.Window).Sub(now),
+		}, nil
+	}
+
+	l.events[key] = append(kept, now)
+
+	return &Result{
+		Allowed:   true,
+		Limit:     rule.Limit,
+		Remaining: rule.Limit - len(kept) - 1,
+	}, nil
+}
+
+func (l *MemoryLimiter) Reset(ctx context.Context, keys ...string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		delete(l.events, key)
+	}
+
+	return nil
+}
+
+func (r Rule) validate() error {
+	if r.Limit <= 0 {
+		return fmt.Errorf("rate limit must be positive")
+	}
+	if r.Window < time.Millisecond {
+		return fmt.Errorf("rate limit window must be at least one millisecond")
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedisLimiter(t *testing.T) (*RedisLimiter, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewRedisLimiter(client, "test"), server
+}
+
+func TestLimiters_SlidingWindow(t *testing.T) {
+	redisLimiter, _ := newTestRedisLimiter(t)
+
+	limiters := map[string]Limiter{
+		"redis":  redisLimiter,
+		"memory": NewMemoryLimiter(),
+	}
+
+	rule := Rule{Limit: 3, Window: time.Minute}
+
+	for name, limiter := range limiters {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			for i := 0; i < rule.Limit; i++ {
+				result, err := limiter.Allow(ctx, "login:alice", rule)
+				require.NoError(t, err)
+				assert.True(t, result.Allowed)
+				assert.Equal(t, rule.Limit-i-1, result.Remaining)
+			}
+
+			result, err := limiter.Allow(ctx, "login:alice", rule)
+			require.NoError(t, err)
+			assert.False(t, result.Allowed)
+			assert.Equal(t, 0, result.Remaining)
+			assert.Greater(t, result.RetryAfter, time.Duration(0))
+			assert.LessOrEqual(t, result.RetryAfter, rule.Window)
+
+			result, err = limiter.Allow(ctx, "login:bob", rule)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed, "keys are limited independently")
+
+			require.NoError(t, limiter.Reset(ctx, "login:alice"))
+
+			result, err = limiter.Allow(ctx, "login:alice", rule)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+		})
+	}
+}
+
+func TestRedisLimiter_KeyExpiresWithWindow(t *testing.T) {
+	limiter, server := newTestRedisLimiter(t)
+
+	_, err := limiter.Allow(context.Background(), "forgot:alice", Rule{Limit: 1, Window: 30 * time.Second})
+	require.NoError(t, err)
+
+	assert.True(t, server.Exists("test:forgot:alice"))
+	assert.Equal(t, 30*time.Second, server.TTL("test:forgot:alice"))
+}
+
+func TestMemoryLimiter_WindowSlides(t *testing.T) {
+	limiter := NewMemoryLimiter()
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+
+	rule := Rule{Limit: 2, Window: time.Minute}
+	ctx := context.Background()
+
+	_, _ = limiter.Allow(ctx, "key", rule)
+	now = now.Add(30 * time.Second)
+	_, _ = limiter.Allow(ctx, "key", rule)
+
+	result, err := limiter.Allow(ctx, "key", rule)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 30*time.Second, result.RetryAfter)
+
+	now = now.Add(31 * time.Second)
+	result, err = limiter.Allow(ctx, "key", rule)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+}
+
+func TestRule_Validate(t *testing.T) {
+	limiter := NewMemoryLimiter()
+
+	_, err := limiter.Allow(context.Background(), "key", Rule{Limit: 0, Window: time.Minute})
+	assert.Error(t, err)
+
+	_, err = limiter.Allow(context.Background(), "key", Rule{Limit: 1})
+	assert.Error(t, err)
+}
+
+func TestExceededError(t *testing.T) {
+	var err error = &ExceededError{Reason: "account is temporarily locked", RetryAfter: 1500 * time.Millisecond}
+
+	var exceeded *ExceededError
+	require.True(t, errors.As(err, &exceeded))
+	assert.Equal(t, "too many requests: account is temporarily locked", err.Error())
+	assert.Equal(t, 2, RetryAfterSeconds(exceeded.RetryAfter))
+	assert.Equal(t, 1, RetryAfterSeconds(0))
+}
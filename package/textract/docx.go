@@ -0,0 +1,203 @@
+package textract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const wordprocessingNamespace = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+
+// docxMaxDocumentXML bounds the uncompressed size of word/document.xml so a
+// small archive cannot expand without limit.
+const docxMaxDocumentXML = 256 << 20
+
+// docxParagraph is the paragraph being read. Style is the paragraph style ID,
+// such as Heading1 or Title.
+type docxParagraph struct {
+	text     strings.Builder
+	style    string
+	numbered bool
+}
+
+// docxTable is a table being read. Paragraphs inside a cell are joined with a
+// space, as are the cells of nested tables.
+type docxTable struct {
+	rows [][]string
+	row  []string
+	cell []string
+}
+
+func extractDOCX(data []byte) ([]Block, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DOCX archive: %w", err)
+	}
+
+	var document *zip.File
+	for _, file := range archive.File {
+		if file.Name == "word/document.xml" {
+			document = file
+			break
+		}
+	}
+	if document == nil {
+		return nil, errors.New("failed to read DOCX: word/document.xml is missing")
+	}
+
+	reader, err := document.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DOCX: %w", err)
+	}
+	defer reader.Close()
+
+	return parseDocumentXML(io.LimitReader(reader, docxMaxDocumentXML))
+}
+
+func parseDocumentXML(reader io.Reader) ([]Block, error) {
+	var (
+		blocks    []Block
+		paragraph *docxParagraph
+		tables    []*docxTable
+		inText    bool
+	)
+
+	decoder := xml.NewDecoder(reader)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse DOCX: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Space != wordprocessingNamespace {
+				continue
+			}
+			switch t.Name.Local {
+			case "p":
+				paragraph = &docxParagraph{}
+			case "pStyle":
+				if paragraph != nil {
+					paragraph.style = attr(t, "val")
+				}
+			case "numPr":
+				if paragraph != nil {
+					paragraph.numbered = true
+				}
+			case "t":
+				inText = true
+			case "tab":
+				if paragraph != nil {
+					paragraph.text.WriteString("\t")
+				}
+			case "br", "cr":
+				if paragraph != nil {
+					paragraph.text.WriteString(" ")
+				}
+			case "tbl":
+				tables = append(tables, &docxTable{})
+			case "tr":
+				if len(tables) > 0 {
+					tables[len(tables)-1].row = nil
+				}
+			case "tc":
+				if len(tables) > 0 {
+					tables[len(tables)-1].cell = nil
+				}
+			}
+
+		case xml.CharData:
+			if inText && paragraph != nil {
+				paragraph.text.Write(t)
+			}
+
+		case xml.EndElement:
+			if t.Name.Space != wordprocessingNamespace {
+				continue
+			}
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if paragraph == nil {
+					continue
+				}
+				text := collapseSpace(paragraph.text.String())
+				if len(tables) > 0 {
+					table := tables[len(tables)-1]
+					table.cell = append(table.cell, text)
+				} else {
+					blocks = append(blocks, paragraph.block(text))
+				}
+				paragraph = nil
+			case "tc":
+				if len(tables) > 0 {
+					table := tables[len(tables)-1]
+					table.row = append(table.row, collapseSpace(strings.Join(table.cell, " ")))
+				}
+			case "tr":
+				if len(tables) > 0 {
+					table := tables[len(tables)-1]
+					if len(table.row) > 0 {
+						table.rows = append(table.rows, table.row)
+					}
+				}
+			case "tbl":
+				if len(tables) == 0 {
+					continue
+				}
+				table := tables[len(tables)-1]
+				tables = tables[:len(tables)-1]
+				if len(table.rows) == 0 {
+					continue
+				}
+				if len(tables) > 0 {
+					outer := tables[len(tables)-1]
+					outer.cell = append(outer.cell, tableBlock(table.rows).Text)
+				} else {
+					blocks = append(blocks, tableBlock(table.rows))
+				}
+			}
+		}
+	}
+
+	return blocks, nil
+}
+
+// block maps the built-in Title and HeadingN styles to headings. Title is
+// treated as level 1 and HeadingN as level N.
+func (p *docxParagraph) block(text string) Block {
+	style := strings.ToLower(p.style)
+
+	if style == "title" {
+		return Block{Type: BlockHeading, Level: 1, Text: text}
+	}
+	if strings.HasPrefix(style, "heading") {
+		if level, err := strconv.Atoi(strings.TrimPrefix(style, "heading")); err == nil && level >= 1 && level <= 9 {
+			return Block{Type: BlockHeading, Level: level, Text: text}
+		}
+	}
+	if p.numbered || strings.HasPrefix(style, "listparagraph") {
+		return Block{Type: BlockListItem, Text: text}
+	}
+
+	return Block{Type: BlockParagraph, Text: text}
+}
+
+func attr(element xml.StartElement, local string) string {
+	for _, a := range element.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
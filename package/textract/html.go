@@ -0,0 +1,194 @@
+package textract
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var skippedElements = map[atom.Atom]bool{
+	atom.Head:     true,
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Svg:      true,
+	atom.Iframe:   true,
+}
+
+var headingLevels = map[atom.Atom]int{
+	atom.H1: 1,
+	atom.H2: 2,
+	atom.H3: 3,
+	atom.H4: 4,
+	atom.H5: 5,
+	atom.H6: 6,
+}
+
+// blockElements end the running paragraph when they start or finish.
+var blockElements = map[atom.Atom]bool{
+	atom.Address:    true,
+	atom.Article:    true,
+	atom.Aside:      true,
+	atom.Blockquote: true,
+	atom.Body:       true,
+	atom.Dd:         true,
+	atom.Div:        true,
+	atom.Dl:         true,
+	atom.Dt:         true,
+	atom.Figcaption: true,
+	atom.Figure:     true,
+	atom.Footer:     true,
+	atom.Form:       true,
+	atom.Header:     true,
+	atom.Hr:         true,
+	atom.Main:       true,
+	atom.Nav:        true,
+	atom.Ol:         true,
+	atom.P:          true,
+	atom.Pre:        true,
+	atom.Section:    true,
+	atom.Ul:         true,
+}
+
+// htmlExtractor collects inline text until a block element ends it. Text
+// inside list items, including nested lists, becomes list items.
+type htmlExtractor struct {
+	blocks    []Block
+	text      strings.Builder
+	listItems int
+}
+
+func extractHTML(data []byte) ([]Block, error) {
+	root, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	e := &htmlExtractor{}
+	e.walk(root)
+	e.flush()
+
+	return e.blocks, nil
+}
+
+func (e *htmlExtractor) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		e.text.WriteString(n.Data)
+		return
+	case html.ElementNode:
+		if skippedElements[n.DataAtom] {
+			return
+		}
+
+		if level, ok := headingLevels[n.DataAtom]; ok {
+			e.flush()
+			e.blocks = append(e.blocks, Block{Type: BlockHeading, Level: level, Text: nodeText(n)})
+			return
+		}
+
+		switch n.DataAtom {
+		case atom.Li:
+			e.flush()
+			e.listItems++
+			e.walkChildren(n)
+			e.flush()
+			e.listItems--
+			return
+		case atom.Table:
+			e.flush()
+			if rows := tableRows(n); len(rows) > 0 {
+				e.blocks = append(e.blocks, tableBlock(rows))
+			}
+			return
+		case atom.Br:
+			e.text.WriteString(" ")
+			return
+		}
+
+		if blockElements[n.DataAtom] {
+			e.flush()
+			e.walkChildren(n)
+			e.flush()
+			return
+		}
+	}
+
+	e.walkChildren(n)
+}
+
+func (e *htmlExtractor) walkChildren(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		e.walk(child)
+	}
+}
+
+func (e *htmlExtractor) flush() {
+	blockType := BlockParagraph
+	if e.listItems > 0 {
+		blockType = BlockListItem
+	}
+
+	if text := collapseSpace(e.text.String()); text != "" {
+		e.blocks = append(e.blocks, Block{Type: blockType, Text: text})
+	}
+	e.text.Reset()
+}
+
+// tableRows collects the cells of a table. Nested tables are flattened into
+// the text of the cell holding them.
+func tableRows(table *html.Node) [][]string {
+	var rows [][]string
+
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			switch child.DataAtom {
+			case atom.Tr:
+				var row []string
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type == html.ElementNode && (cell.DataAtom == atom.Td || cell.DataAtom == atom.Th) {
+						row = append(row, nodeText(cell))
+					}
+				}
+				if len(row) > 0 {
+					rows = append(rows, row)
+				}
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				visit(child)
+			}
+		}
+	}
+	visit(table)
+
+	return rows
+}
+
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			b.WriteString(n.Data)
+		case n.Type == html.ElementNode && skippedElements[n.DataAtom]:
+			return
+		case n.Type == html.ElementNode && (n.DataAtom == atom.Br || blockElements[n.DataAtom] || n.DataAtom == atom.Td || n.DataAtom == atom.Th):
+			b.WriteString(" ")
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			visit(child)
+		}
+	}
+	visit(n)
+
+	return collapseSpace(b.String())
+}
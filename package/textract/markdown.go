@@ -0,0 +1,148 @@
+package textract
+
+import (
+	"regexp"
+	"strings"
+)
+
+// extractText splits plain text into paragraphs at blank lines. Line breaks
+// inside a paragraph are kept.
+func extractText(text string) []Block {
+	var blocks []Block
+	for _, paragraph := range splitParagraphs(text) {
+		blocks = append(blocks, Block{Type: BlockParagraph, Text: paragraph})
+	}
+	return blocks
+}
+
+func splitParagraphs(text string) []string {
+	var (
+		paragraphs []string
+		lines      []string
+	)
+
+	flush := func() {
+		if len(lines) > 0 {
+			paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+			lines = nil
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t")
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+
+	return paragraphs
+}
+
+var (
+	atxHeading     = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	setextHeading  = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	listItem       = regexp.MustCompile(`^[ \t]*(?:[-*+]|\d{1,9}[.)])[ \t]+(.*)$`)
+	fence          = regexp.MustCompile("^ {0,3}(```+|~~~+)")
+	tableSeparator = regexp.MustCompile(`^[ \t]*\|?[ \t]*:?-+:?[ \t]*(\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	thematicBreak  = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+
+	inlineImage  = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	inlineLink   = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	inlineMarker = regexp.MustCompile("(\\*\\*|__|~~|`)")
+	inlineEmph   = regexp.MustCompile(`(^|[^\w*])[*_]([^*_\s][^*_]*)[*_]`)
+)
+
+// extractMarkdown recognises ATX and setext headings, list items, pipe
+// tables, fenced code and paragraphs. Inline markup is removed and links are
+// replaced by their text.
+func extractMarkdown(text string) []Block {
+	var (
+		blocks    []Block
+		paragraph []string
+	)
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			blocks = append(blocks, Block{Type: BlockParagraph, Text: stripInline(strings.Join(paragraph, " "))})
+			paragraph = nil
+		}
+	}
+
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			flush()
+
+		case fence.MatchString(line):
+			flush()
+			marker := fence.FindStringSubmatch(line)[1]
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), marker); i++ {
+				code = append(code, lines[i])
+			}
+			blocks = append(blocks, Block{Type: BlockParagraph, Text: strings.Join(code, "\n")})
+
+		case atxHeading.MatchString(line):
+			flush()
+			match := atxHeading.FindStringSubmatch(line)
+			blocks = append(blocks, Block{Type: BlockHeading, Level: len(match[1]), Text: stripInline(match[2])})
+
+		case len(paragraph) > 0 && setextHeading.MatchString(line):
+			level := 1
+			if strings.HasPrefix(trimmed, "-") {
+				level = 2
+			}
+			blocks = append(blocks, Block{Type: BlockHeading, Level: level, Text: stripInline(strings.Join(paragraph, " "))})
+			paragraph = nil
+
+		case thematicBreak.MatchString(line):
+			flush()
+
+		case strings.Contains(line, "|") && i+1 < len(lines) && tableSeparator.MatchString(lines[i+1]):
+			flush()
+			rows := [][]string{tableCells(line)}
+			for i += 2; i < len(lines) && strings.Contains(lines[i], "|") && strings.TrimSpace(lines[i]) != ""; i++ {
+				rows = append(rows, tableCells(lines[i]))
+			}
+			i--
+			blocks = append(blocks, tableBlock(rows))
+
+		case listItem.MatchString(line):
+			flush()
+			blocks = append(blocks, Block{Type: BlockListItem, Text: stripInline(listItem.FindStringSubmatch(line)[1])})
+
+		default:
+			paragraph = append(paragraph, strings.TrimLeft(strings.TrimPrefix(trimmed, ">"), " "))
+		}
+	}
+	flush()
+
+	return blocks
+}
+
+func tableCells(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	line = strings.TrimSuffix(line, "|")
+
+	cells := strings.Split(line, "|")
+	for i, cell := range cells {
+		cells[i] = stripInline(strings.TrimSpace(cell))
+	}
+	return cells
+}
+
+func stripInline(s string) string {
+	s = inlineImage.ReplaceAllString(s, "$1")
+	s = inlineLink.ReplaceAllString(s, "$1")
+	s = inlineMarker.ReplaceAllString(s, "")
+	s = inlineEmph.ReplaceAllString(s, "$1$2")
+	return collapseSpace(s)
+}
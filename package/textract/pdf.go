@@ -0,0 +1,534 @@
+package textract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/ascii85"
+	"errors"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+const (
+	// pdfMaxStreamSize bounds a decoded stream so a small file cannot expand
+	// without limit.
+	pdfMaxStreamSize = 64 << 20
+
+	pdfMaxDepth = 32
+)
+
+var pdfObjectHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+type pdfObject struct {
+	value  any
+	stream []byte
+}
+
+// pdfFile holds every object of a PDF by number. Objects are found by
+// scanning for "N G obj" rather than through the cross-reference table, so
+// files with a damaged table still open. When a number appears twice, the
+// later definition wins, as it does for incremental updates.
+type pdfFile struct {
+	objects map[int]*pdfObject
+	fonts   map[int]*pdfFont
+}
+
+func extractPDF(data []byte) ([]Block, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("%PDF-")) {
+		return nil, errors.New("failed to parse PDF: missing %PDF header")
+	}
+
+	file := parsePDF(data)
+	if file.encrypted(data) {
+		return nil, errors.New("failed to parse PDF: encrypted files are not supported")
+	}
+
+	interpreter := &pdfInterpreter{file: file}
+	for _, page := range file.pages() {
+		interpreter.page(page)
+	}
+
+	return pdfBlocks(interpreter.lines), nil
+}
+
+func parsePDF(data []byte) *pdfFile {
+	file := &pdfFile{objects: map[int]*pdfObject{}, fonts: map[int]*pdfFont{}}
+
+	for _, match := range pdfObjectHeader.FindAllSubmatchIndex(data, -1) {
+		num, err := strconv.Atoi(string(data[match[2]:match[3]]))
+		if err != nil {
+			continue
+		}
+
+		lexer := &pdfLexer{data: data, pos: match[1]}
+		object := &pdfObject{value: lexer.value()}
+
+		if dict, ok := object.value.(pdfDict); ok {
+			lexer.skipSpace()
+			if bytes.HasPrefix(lexer.rest(), []byte("stream")) {
+				object.stream = streamData(data, lexer.pos+len("stream"), dict)
+			}
+		}
+
+		file.objects[num] = object
+	}
+
+	// Objects inside object streams never replace ones stored directly.
+	for _, object := range file.objectsOfType("ObjStm") {
+		file.expandObjectStream(object)
+	}
+
+	return file
+}
+
+// streamData returns the raw bytes between "stream" and "endstream". A direct
+// /Length is trusted when it lands on endstream; otherwise the keyword is
+// searched for.
+func streamData(data []byte, start int, dict pdfDict) []byte {
+	if start < len(data) && data[start] == '\r' {
+		start++
+	}
+	if start < len(data) && data[start] == '\n' {
+		start++
+	}
+	if start > len(data) {
+		return nil
+	}
+
+	if length, ok := dict["Length"].(float64); ok && length >= 0 {
+		end := start + int(length)
+		if end <= len(data) && bytes.HasPrefix(bytes.TrimLeft(data[end:], " \t\r\n"), []byte("endstream")) {
+			return data[start:end]
+		}
+	}
+
+	end := bytes.Index(data[start:], []byte("endstream"))
+	if end < 0 {
+		return data[start:]
+	}
+	return bytes.TrimRight(data[start:start+end], "\r\n")
+}
+
+func (f *pdfFile) expandObjectStream(object *pdfObject) {
+	dict := object.value.(pdfDict)
+	first, _ := f.resolve(dict["First"]).(float64)
+	count, _ := f.resolve(dict["N"]).(float64)
+
+	data, err := f.decodeStream(object)
+	if err != nil || int(first) > len(data) {
+		return
+	}
+
+	header := &pdfLexer{data: data[:int(first)]}
+	for i := 0; i < int(count); i++ {
+		num, ok1 := header.next().(float64)
+		offset, ok2 := header.next().(float64)
+		if !ok1 || !ok2 {
+			return
+		}
+		if _, exists := f.objects[int(num)]; exists {
+			continue
+		}
+
+		start := int(first) + int(offset)
+		if start < 0 || start >= len(data) {
+			continue
+		}
+		lexer := &pdfLexer{data: data, pos: start}
+		f.objects[int(num)] = &pdfObject{value: lexer.value()}
+	}
+}
+
+func (f *pdfFile) resolve(v any) any {
+	for depth := 0; depth < pdfMaxDepth; depth++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		object, ok := f.objects[ref.num]
+		if !ok {
+			return nil
+		}
+		v = object.value
+	}
+	return nil
+}
+
+func (f *pdfFile) dict(v any) pdfDict {
+	dict, _ := f.resolve(v).(pdfDict)
+	return dict
+}
+
+func (f *pdfFile) object(v any) *pdfObject {
+	if ref, ok := v.(pdfRef); ok {
+		return f.objects[ref.num]
+	}
+	return nil
+}
+
+func (f *pdfFile) objectsOfType(name pdfName) []*pdfObject {
+	nums := make([]int, 0, len(f.objects))
+	for num, object := range f.objects {
+		if dict, ok := object.value.(pdfDict); ok && dict["Type"] == name {
+			nums = append(nums, num)
+		}
+	}
+	sort.Ints(nums)
+
+	objects := make([]*pdfObject, len(nums))
+	for i, num := range nums {
+		objects[i] = f.objects[num]
+	}
+	return objects
+}
+
+var pdfTrailer = regexp.MustCompile(`trailer\s*<<`)
+
+// encrypted looks for /Encrypt in classic trailers and in cross-reference
+// streams.
+func (f *pdfFile) encrypted(data []byte) bool {
+	for _, match := range pdfTrailer.FindAllIndex(data, -1) {
+		lexer := &pdfLexer{data: data, pos: match[1]}
+		if lexer.dict()["Encrypt"] != nil {
+			return true
+		}
+	}
+
+	for _, object := range f.objects {
+		if dict, ok := object.value.(pdfDict); ok && dict["Encrypt"] != nil && (dict["Root"] != nil || dict["Type"] == pdfName("XRef")) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeStream applies the stream filters. FlateDecode, ASCII85Decode and
+// ASCIIHexDecode are supported; image filters are not, as images carry no
+// text.
+func (f *pdfFile) decodeStream(object *pdfObject) ([]byte, error) {
+	dict, _ := object.value.(pdfDict)
+	data := object.stream
+
+	var filters []any
+	switch filter := f.resolve(dict["Filter"]).(type) {
+	case pdfName:
+		filters = []any{filter}
+	case pdfArray:
+		filters = filter
+	}
+
+	for _, filter := range filters {
+		name, _ := f.resolve(filter).(pdfName)
+		switch name {
+		case "FlateDecode", "Fl":
+			reader, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			decoded, err := io.ReadAll(io.LimitReader(reader, pdfMaxStreamSize))
+			reader.Close()
+			// Truncated streams are common; keep whatever inflated.
+			if err != nil && len(decoded) == 0 {
+				return nil, err
+			}
+			data = decoded
+		case "ASCII85Decode", "A85":
+			trimmed := bytes.TrimPrefix(bytes.TrimSpace(data), []byte("<~"))
+			if end := bytes.Index(trimmed, []byte("~>")); end >= 0 {
+				trimmed = trimmed[:end]
+			}
+			decoded := make([]byte, 4*len(trimmed))
+			n, _, err := ascii85.Decode(decoded, trimmed, true)
+			if err != nil {
+				return nil, err
+			}
+			data = decoded[:n]
+		case "ASCIIHexDecode", "AHx":
+			data = (&pdfLexer{data: append(append([]byte("<"), data...), '>')}).hexString()
+		default:
+			return nil, errors.New("unsupported stream filter " + string(name))
+		}
+	}
+
+	return data, nil
+}
+
+// pages returns the page dictionaries in reading order with inherited
+// resources filled in. Files without a usable page tree fall back to every
+// page object in object order.
+func (f *pdfFile) pages() []pdfDict {
+	var pages []pdfDict
+	visited := map[int]bool{}
+
+	var walk func(node any, resources any, depth int)
+	walk = func(node any, resources any, depth int) {
+		if depth > pdfMaxDepth {
+			return
+		}
+		if ref, ok := node.(pdfRef); ok {
+			if visited[ref.num] {
+				return
+			}
+			visited[ref.num] = true
+		}
+
+		dict := f.dict(node)
+		if dict == nil {
+			return
+		}
+		if own := dict["Resources"]; own != nil {
+			resources = own
+		}
+
+		if kids, ok := f.resolve(dict["Kids"]).(pdfArray); ok {
+			for _, kid := range kids {
+				walk(kid, resources, depth+1)
+			}
+			return
+		}
+
+		if dict["Type"] == pdfName("Page") || dict["Contents"] != nil {
+			page := pdfDict{}
+			for key, value := range dict {
+				page[key] = value
+			}
+			page["Resources"] = resources
+			pages = append(pages, page)
+		}
+	}
+
+	for _, catalog := range f.objectsOfType("Catalog") {
+		walk(catalog.value.(pdfDict)["Pages"], nil, 0)
+		if len(pages) > 0 {
+			return pages
+		}
+	}
+
+	for _, object := range f.objectsOfType("Page") {
+		pages = append(pages, object.value.(pdfDict))
+	}
+	return pages
+}
+
+// font returns the decoding information of a font resource, cached by object
+// number.
+func (f *pdfFile) font(v any) *pdfFont {
+	ref, isRef := v.(pdfRef)
+	if isRef {
+		if font, ok := f.fonts[ref.num]; ok {
+			return font
+		}
+	}
+
+	dict := f.dict(v)
+	font := &pdfFont{composite: dict["Subtype"] == pdfName("Type0")}
+	if object := f.object(dict["ToUnicode"]); object != nil {
+		if data, err := f.decodeStream(object); err == nil {
+			font.cmap = parseCMap(data)
+		}
+	}
+
+	if isRef {
+		f.fonts[ref.num] = font
+	}
+	return font
+}
+
+// pdfFont decodes the bytes of shown strings. Composite fonts are only
+// readable through their ToUnicode map; simple fonts fall back to
+// WinAnsiEncoding.
+type pdfFont struct {
+	composite bool
+	cmap      *pdfCMap
+}
+
+func (font *pdfFont) decode(s []byte) string {
+	if font == nil {
+		return winAnsi(s)
+	}
+	if font.cmap != nil {
+		return font.cmap.decode(s, font.composite)
+	}
+	if font.composite {
+		return ""
+	}
+	return winAnsi(s)
+}
+
+// pdfCMap maps character codes to Unicode text. Codes are 1 to 4 bytes long;
+// width is the length used by the mappings.
+type pdfCMap struct {
+	width   int
+	mapping map[uint32]string
+}
+
+func parseCMap(data []byte) *pdfCMap {
+	cmap := &pdfCMap{mapping: map[uint32]string{}}
+	lexer := &pdfLexer{data: data}
+
+	var operands []any
+	mode := ""
+	for !lexer.eof() {
+		v := lexer.next()
+		op, ok := v.(pdfOperator)
+		if !ok {
+			if mode != "" {
+				operands = append(operands, v)
+			}
+			continue
+		}
+
+		switch op {
+		case "beginbfchar", "beginbfrange", "begincodespacerange":
+			mode = string(op)
+			operands = nil
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, _ := operands[i].(pdfString)
+				dst, _ := operands[i+1].(pdfString)
+				cmap.set(src, utf16Text(dst))
+			}
+			mode = ""
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, _ := operands[i].(pdfString)
+				hi, _ := operands[i+1].(pdfString)
+				cmap.setRange(lo, hi, operands[i+2])
+			}
+			mode = ""
+		case "endcodespacerange":
+			if len(operands) > 0 {
+				if lo, ok := operands[0].(pdfString); ok && cmap.width == 0 {
+					cmap.width = len(lo)
+				}
+			}
+			mode = ""
+		}
+	}
+
+	if len(cmap.mapping) == 0 {
+		return nil
+	}
+	return cmap
+}
+
+func (c *pdfCMap) set(src pdfString, text string) {
+	if len(src) == 0 || len(src) > 4 {
+		return
+	}
+	if c.width == 0 {
+		c.width = len(src)
+	}
+	c.mapping[codeValue(src)] = text
+}
+
+func (c *pdfCMap) setRange(lo, hi pdfString, dst any) {
+	if len(lo) == 0 || len(lo) > 4 || len(lo) != len(hi) {
+		return
+	}
+	start, end := codeValue(lo), codeValue(hi)
+	if end < start || end-start > 0xffff {
+		return
+	}
+
+	for code := start; code <= end; code++ {
+		src := codeBytes(code, len(lo))
+		switch dst := dst.(type) {
+		case pdfString:
+			base := []rune(utf16Text(dst))
+			if len(base) == 0 {
+				continue
+			}
+			base[len(base)-1] += rune(code - start)
+			c.set(src, string(base))
+		case pdfArray:
+			if i := int(code - start); i < len(dst) {
+				if s, ok := dst[i].(pdfString); ok {
+					c.set(src, utf16Text(s))
+				}
+			}
+		}
+	}
+}
+
+func (c *pdfCMap) decode(s []byte, composite bool) string {
+	width := c.width
+	if width == 0 {
+		width = 1
+	}
+
+	var out []rune
+	for i := 0; i+width <= len(s); i += width {
+		code := codeValue(s[i : i+width])
+		if text, ok := c.mapping[code]; ok {
+			out = append(out, []rune(text)...)
+		} else if !composite && width == 1 {
+			out = append(out, []rune(winAnsi(s[i:i+1]))...)
+		}
+	}
+	return string(out)
+}
+
+func codeValue(b []byte) uint32 {
+	var v uint32
+	for _, c := range b {
+		v = v<<8 | uint32(c)
+	}
+	return v
+}
+
+func codeBytes(v uint32, width int) pdfString {
+	b := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+	return b
+}
+
+func utf16Text(b []byte) string {
+	var out []rune
+	for i := 0; i+1 < len(b); i += 2 {
+		r := rune(b[i])<<8 | rune(b[i+1])
+		if r >= 0xd800 && r < 0xdc00 && i+3 < len(b) {
+			low := rune(b[i+2])<<8 | rune(b[i+3])
+			if low >= 0xdc00 && low < 0xe000 {
+				out = append(out, (r-0xd800)<<10+(low-0xdc00)+0x10000)
+				i += 2
+				continue
+			}
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
+
+var winAnsiHigh = [32]rune{
+	'€', 0, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0, 'Ž', 0,
+	0, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0, 'ž', 'Ÿ',
+}
+
+// winAnsi decodes bytes as WinAnsiEncoding, which matches Latin-1 apart from
+// the 0x80-0x9F range. Text strings starting with a UTF-16 byte order mark
+// are decoded as UTF-16.
+func winAnsi(b []byte) string {
+	if bytes.HasPrefix(b, []byte{0xfe, 0xff}) {
+		return utf16Text(b[2:])
+	}
+
+	out := make([]rune, 0, len(b))
+	for _, c := range b {
+		switch {
+		case c >= 0x80 && c < 0xa0:
+			if r := winAnsiHigh[c-0x80]; r != 0 {
+				out = append(out, r)
+			}
+		case c < 0x20 && c != '\t':
+		default:
+			out = append(out, rune(c))
+		}
+	}
+	return string(out)
+}
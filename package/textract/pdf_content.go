@@ -0,0 +1,386 @@
+package textract
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	pdfMaxFormDepth = 8
+
+	// pdfHeadingRatio is how much larger than the body text a block must be
+	// set to count as a heading.
+	pdfHeadingRatio = 1.15
+	// pdfHeadingMaxLength keeps large-print paragraphs from becoming
+	// headings.
+	pdfHeadingMaxLength = 200
+)
+
+// pdfLine is text shown on one baseline of a page.
+type pdfLine struct {
+	page int
+	y    float64
+	size float64
+	text strings.Builder
+}
+
+type pdfTextState struct {
+	font     *pdfFont
+	fontSize float64
+	leading  float64
+}
+
+// pdfInterpreter runs page content streams and collects the text they show.
+// Only the text operators are interpreted; positions are taken from the text
+// matrix alone, which is enough to tell lines and font sizes apart.
+type pdfInterpreter struct {
+	file  *pdfFile
+	lines []*pdfLine
+
+	pageNumber int
+	state      pdfTextState
+	stack      []pdfTextState
+	tm, tlm    [6]float64
+	moved      bool
+}
+
+var pdfIdentity = [6]float64{1, 0, 0, 1, 0, 0}
+
+func (p *pdfInterpreter) page(page pdfDict) {
+	p.pageNumber++
+	p.state = pdfTextState{fontSize: 1}
+	p.stack = nil
+
+	var content [][]byte
+	contents := page["Contents"]
+	if array, ok := p.file.resolve(contents).(pdfArray); ok {
+		for _, part := range array {
+			if object := p.file.object(part); object != nil {
+				if data, err := p.file.decodeStream(object); err == nil {
+					content = append(content, data)
+				}
+			}
+		}
+	} else if object := p.file.object(contents); object != nil {
+		if data, err := p.file.decodeStream(object); err == nil {
+			content = append(content, data)
+		}
+	}
+
+	p.run(bytes.Join(content, []byte("\n")), p.file.dict(page["Resources"]), 0)
+}
+
+func (p *pdfInterpreter) run(content []byte, resources pdfDict, depth int) {
+	lexer := &pdfLexer{data: content}
+	var operands []any
+
+	for !lexer.eof() {
+		v := lexer.value()
+		op, ok := v.(pdfOperator)
+		if !ok {
+			operands = append(operands, v)
+			continue
+		}
+
+		switch op {
+		case "q":
+			p.stack = append(p.stack, p.state)
+		case "Q":
+			if n := len(p.stack); n > 0 {
+				p.state = p.stack[n-1]
+				p.stack = p.stack[:n-1]
+			}
+		case "BT":
+			p.tm, p.tlm = pdfIdentity, pdfIdentity
+			p.moved = true
+		case "Tf":
+			if len(operands) >= 2 {
+				if name, ok := operands[0].(pdfName); ok {
+					fonts := p.file.dict(resources["Font"])
+					p.state.font = p.file.font(fonts[name])
+				}
+				if size, ok := operands[1].(float64); ok {
+					p.state.fontSize = size
+				}
+			}
+		case "TL":
+			if len(operands) >= 1 {
+				p.state.leading, _ = operands[0].(float64)
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				tx, _ := operands[0].(float64)
+				ty, _ := operands[1].(float64)
+				if op == "TD" {
+					p.state.leading = -ty
+				}
+				p.translate(tx, ty)
+			}
+		case "Tm":
+			if len(operands) >= 6 {
+				for i := 0; i < 6; i++ {
+					p.tlm[i], _ = operands[i].(float64)
+				}
+				p.tm = p.tlm
+				p.moved = true
+			}
+		case "T*":
+			p.translate(0, -p.state.leading)
+		case "Tj":
+			if len(operands) >= 1 {
+				p.show(operands[0])
+			}
+		case "'":
+			p.translate(0, -p.state.leading)
+			if len(operands) >= 1 {
+				p.show(operands[0])
+			}
+		case "\"":
+			p.translate(0, -p.state.leading)
+			if len(operands) >= 3 {
+				p.show(operands[2])
+			}
+		case "TJ":
+			if len(operands) >= 1 {
+				array, _ := operands[0].(pdfArray)
+				for _, item := range array {
+					// Large negative adjustments stand in for spaces.
+					if n, ok := item.(float64); ok && n < -180 {
+						p.space()
+					} else {
+						p.show(item)
+					}
+				}
+			}
+		case "Do":
+			if len(operands) >= 1 && depth < pdfMaxFormDepth {
+				p.form(operands[0], resources, depth)
+			}
+		case "BI":
+			skipInlineImage(lexer)
+		}
+
+		operands = operands[:0]
+	}
+}
+
+// form runs a form XObject with its own resources, or the caller's when it
+// has none.
+func (p *pdfInterpreter) form(name any, resources pdfDict, depth int) {
+	xobjects := p.file.dict(resources["XObject"])
+	key, _ := name.(pdfName)
+	object := p.file.object(xobjects[key])
+	if object == nil {
+		return
+	}
+
+	dict, _ := object.value.(pdfDict)
+	if dict["Subtype"] != pdfName("Form") {
+		return
+	}
+
+	data, err := p.file.decodeStream(object)
+	if err != nil {
+		return
+	}
+
+	formResources := p.file.dict(dict["Resources"])
+	if formResources == nil {
+		formResources = resources
+	}
+
+	tm, tlm := p.tm, p.tlm
+	p.run(data, formResources, depth+1)
+	p.tm, p.tlm = tm, tlm
+}
+
+func (p *pdfInterpreter) translate(tx, ty float64) {
+	p.tlm[4] += tx*p.tlm[0] + ty*p.tlm[2]
+	p.tlm[5] += tx*p.tlm[1] + ty*p.tlm[3]
+	p.tm = p.tlm
+	p.moved = true
+}
+
+func (p *pdfInterpreter) size() float64 {
+	scale := math.Hypot(p.tm[2], p.tm[3])
+	if scale == 0 {
+		scale = 1
+	}
+	return math.Abs(p.state.fontSize * scale)
+}
+
+// line returns the line at the current baseline, starting a new one when the
+// baseline moved by more than part of the font size.
+func (p *pdfInterpreter) line() *pdfLine {
+	size := p.size()
+	y := p.tm[5]
+
+	if n := len(p.lines); n > 0 {
+		last := p.lines[n-1]
+		if last.page == p.pageNumber && math.Abs(last.y-y) <= 0.4*math.Max(size, last.size) {
+			if size > last.size {
+				last.size = size
+			}
+			return last
+		}
+	}
+
+	line := &pdfLine{page: p.pageNumber, y: y, size: size}
+	p.lines = append(p.lines, line)
+	return line
+}
+
+func (p *pdfInterpreter) show(v any) {
+	s, ok := v.(pdfString)
+	if !ok {
+		return
+	}
+
+	text := p.state.font.decode(s)
+	if text == "" {
+		return
+	}
+
+	line := p.line()
+	if p.moved {
+		writeSpace(&line.text)
+	}
+	line.text.WriteString(text)
+	p.moved = false
+}
+
+func (p *pdfInterpreter) space() {
+	if n := len(p.lines); n > 0 {
+		writeSpace(&p.lines[n-1].text)
+	}
+}
+
+func writeSpace(b *strings.Builder) {
+	if s := b.String(); s != "" && !strings.HasSuffix(s, " ") {
+		b.WriteString(" ")
+	}
+}
+
+// skipInlineImage moves past the data of an inline image, which ends at an
+// "EI" surrounded by whitespace.
+func skipInlineImage(lexer *pdfLexer) {
+	id := bytes.Index(lexer.rest(), []byte("ID"))
+	if id < 0 {
+		lexer.pos = len(lexer.data)
+		return
+	}
+
+	for i := lexer.pos + id + 3; i+2 <= len(lexer.data); i++ {
+		if lexer.data[i] == 'E' && lexer.data[i+1] == 'I' && isPDFWhitespace(lexer.data[i-1]) &&
+			(i+2 == len(lexer.data) || isPDFWhitespace(lexer.data[i+2])) {
+			lexer.pos = i + 2
+			return
+		}
+	}
+	lexer.pos = len(lexer.data)
+}
+
+// pdfBlocks groups lines into paragraphs and marks the paragraphs set in a
+// larger size than the body text as headings. A paragraph ends at a page
+// break, a change of font size or a vertical gap wider than a line.
+func pdfBlocks(lines []*pdfLine) []Block {
+	type paragraph struct {
+		text string
+		size float64
+	}
+
+	var paragraphs []paragraph
+	var last *pdfLine
+	for _, line := range lines {
+		text := collapseSpace(line.text.String())
+		if text == "" {
+			continue
+		}
+
+		joins := last != nil && len(paragraphs) > 0 &&
+			last.page == line.page &&
+			math.Abs(last.size-line.size) <= 0.15*math.Max(last.size, line.size) &&
+			last.y-line.y > 0 && last.y-line.y <= 1.8*math.Max(last.size, line.size)
+
+		if joins {
+			current := &paragraphs[len(paragraphs)-1]
+			current.text = joinLines(current.text, text)
+		} else {
+			paragraphs = append(paragraphs, paragraph{text: text, size: line.size})
+		}
+		last = line
+	}
+
+	body := bodySize(lines)
+
+	var headingSizes []float64
+	for _, paragraph := range paragraphs {
+		if isPDFHeading(paragraph.text, paragraph.size, body) {
+			headingSizes = append(headingSizes, math.Round(paragraph.size))
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(headingSizes)))
+
+	blocks := make([]Block, 0, len(paragraphs))
+	for _, paragraph := range paragraphs {
+		if isPDFHeading(paragraph.text, paragraph.size, body) {
+			blocks = append(blocks, Block{Type: BlockHeading, Level: headingLevel(headingSizes, math.Round(paragraph.size)), Text: paragraph.text})
+		} else {
+			blocks = append(blocks, Block{Type: BlockParagraph, Text: paragraph.text})
+		}
+	}
+
+	return blocks
+}
+
+// joinLines removes the hyphen of a word broken across lines.
+func joinLines(previous, next string) string {
+	if strings.HasSuffix(previous, "-") {
+		first, _ := utf8.DecodeRuneInString(next)
+		before, _ := utf8.DecodeLastRuneInString(strings.TrimSuffix(previous, "-"))
+		if unicode.IsLower(first) && unicode.IsLetter(before) {
+			return strings.TrimSuffix(previous, "-") + next
+		}
+	}
+	return previous + " " + next
+}
+
+// bodySize is the font size, rounded to a point, that most characters are
+// set in.
+func bodySize(lines []*pdfLine) float64 {
+	counts := map[float64]int{}
+	for _, line := range lines {
+		counts[math.Round(line.size)] += utf8.RuneCountInString(line.text.String())
+	}
+
+	body, most := 0.0, -1
+	for size, count := range counts {
+		if count > most || (count == most && size < body) {
+			body, most = size, count
+		}
+	}
+	return body
+}
+
+func isPDFHeading(text string, size, body float64) bool {
+	return body > 0 && size >= body*pdfHeadingRatio && utf8.RuneCountInString(text) <= pdfHeadingMaxLength
+}
+
+// headingLevel ranks a size among the distinct heading sizes, largest first.
+// Levels stop at 6.
+func headingLevel(sizes []float64, size float64) int {
+	level := 1
+	for i, s := range sizes {
+		if i > 0 && s != sizes[i-1] {
+			level++
+		}
+		if s == size {
+			break
+		}
+	}
+	return min(level, 6)
+}
@@ -0,0 +1,300 @@
+package textract
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strconv"
+)
+
+// PDF values as read by pdfLexer. Numbers are float64, booleans bool and
+// null is nil.
+type (
+	pdfName     string
+	pdfString   []byte
+	pdfArray    []any
+	pdfDict     map[pdfName]any
+	pdfOperator string
+	pdfRef      struct{ num, gen int }
+)
+
+// pdfLexer reads PDF values from object bodies and content streams. It is
+// forgiving: malformed input ends a value early rather than failing.
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func isPDFWhitespace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return c == '(' || c == ')' || c == '<' || c == '>' || c == '[' || c == ']' || c == '{' || c == '}' || c == '/' || c == '%'
+}
+
+func (l *pdfLexer) skipSpace() {
+	if l.pos > len(l.data) {
+		l.pos = len(l.data)
+	}
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isPDFWhitespace(c) {
+			l.pos++
+		} else if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		} else {
+			return
+		}
+	}
+}
+
+// rest returns the input after the current position.
+func (l *pdfLexer) rest() []byte {
+	if l.pos >= len(l.data) {
+		return nil
+	}
+	return l.data[l.pos:]
+}
+
+func (l *pdfLexer) eof() bool {
+	l.skipSpace()
+	return l.pos >= len(l.data)
+}
+
+// next returns the next value, or a pdfOperator for bare keywords. The
+// closing tokens "]" and ">>" are returned as operators as well.
+func (l *pdfLexer) next() any {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil
+	}
+
+	c := l.data[l.pos]
+	switch {
+	case c == '/':
+		return l.name()
+	case c == '(':
+		return l.literalString()
+	case c == '<':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '<' {
+			l.pos += 2
+			return l.dict()
+		}
+		return l.hexString()
+	case c == '>':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '>' {
+			l.pos += 2
+			return pdfOperator(">>")
+		}
+		l.pos++
+		return l.next()
+	case c == '[':
+		l.pos++
+		return l.array()
+	case c == ']':
+		l.pos++
+		return pdfOperator("]")
+	case c == '{' || c == '}' || c == ')':
+		l.pos++
+		return l.next()
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return l.number()
+	}
+
+	return l.keyword()
+}
+
+// value is next with references resolved from the following "gen R" tokens.
+func (l *pdfLexer) value() any {
+	v := l.next()
+
+	n, ok := v.(float64)
+	if !ok || n < 0 || n != float64(int(n)) {
+		return v
+	}
+
+	save := l.pos
+	if gen, ok := l.next().(float64); ok {
+		if op, ok := l.next().(pdfOperator); ok && op == "R" {
+			return pdfRef{num: int(n), gen: int(gen)}
+		}
+	}
+	l.pos = min(save, len(l.data))
+
+	return v
+}
+
+func (l *pdfLexer) name() pdfName {
+	l.pos++
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFWhitespace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+
+	raw := l.data[start:l.pos]
+	if bytes.IndexByte(raw, '#') < 0 {
+		return pdfName(raw)
+	}
+
+	var decoded []byte
+	for i := 0; i < len(raw); i++ {
+		if raw[i] == '#' && i+2 < len(raw) {
+			if b, err := hex.DecodeString(string(raw[i+1 : i+3])); err == nil {
+				decoded = append(decoded, b[0])
+				i += 2
+				continue
+			}
+		}
+		decoded = append(decoded, raw[i])
+	}
+	return pdfName(decoded)
+}
+
+func (l *pdfLexer) number() any {
+	start := l.pos
+	l.pos++
+	for l.pos < len(l.data) && (l.data[l.pos] == '.' || (l.data[l.pos] >= '0' && l.data[l.pos] <= '9')) {
+		l.pos++
+	}
+
+	n, err := strconv.ParseFloat(string(l.data[start:l.pos]), 64)
+	if err != nil {
+		return float64(0)
+	}
+	return n
+}
+
+func (l *pdfLexer) keyword() any {
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFWhitespace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	if l.pos == start {
+		l.pos++
+		return l.next()
+	}
+
+	switch word := string(l.data[start:l.pos]); word {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	default:
+		return pdfOperator(word)
+	}
+}
+
+func (l *pdfLexer) literalString() pdfString {
+	l.pos++
+	var out []byte
+	depth := 1
+
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+
+		switch c {
+		case '(':
+			depth++
+			out = append(out, c)
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+			out = append(out, c)
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					out = append(out, byte(v))
+				} else {
+					out = append(out, e)
+				}
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+
+	return out
+}
+
+func (l *pdfLexer) hexString() pdfString {
+	l.pos++
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		c := l.data[l.pos]
+		if (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	if l.pos < len(l.data) {
+		l.pos++
+	}
+
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	hex.Decode(out, digits)
+	return out
+}
+
+func (l *pdfLexer) array() pdfArray {
+	var out pdfArray
+	for !l.eof() {
+		v := l.value()
+		if op, ok := v.(pdfOperator); ok && op == "]" {
+			break
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+func (l *pdfLexer) dict() pdfDict {
+	out := pdfDict{}
+	for !l.eof() {
+		key := l.next()
+		if op, ok := key.(pdfOperator); ok && op == ">>" {
+			break
+		}
+		name, ok := key.(pdfName)
+		if !ok {
+			continue
+		}
+		out[name] = l.value()
+	}
+	return out
+}
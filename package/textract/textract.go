@@ -0,0 +1,182 @@
+// Package textract turns PDF, DOCX, HTML, Markdown and plain text files into
+// plain text and a list of structural blocks. Every parser is written in pure
+// Go and works offline.
+package textract
+
+import (
+	"bytes"
+	"errors"
+	"mime"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported document format")
+
+type Format string
+
+const (
+	FormatPDF      Format = "pdf"
+	FormatDOCX     Format = "docx"
+	FormatHTML     Format = "html"
+	FormatMarkdown Format = "markdown"
+	FormatText     Format = "text"
+)
+
+type BlockType string
+
+const (
+	BlockHeading   BlockType = "heading"
+	BlockParagraph BlockType = "paragraph"
+	BlockListItem  BlockType = "list_item"
+	BlockTable     BlockType = "table"
+)
+
+// Block is one structural element. Level is set on headings, from 1 for the
+// most prominent. Tables keep their cells in Rows and a tab-separated
+// rendering in Text.
+type Block struct {
+	Type  BlockType  `json:"type"`
+	Level int        `json:"level,omitempty"`
+	Text  string     `json:"text"`
+	Rows  [][]string `json:"rows,omitempty"`
+}
+
+// Document is the extracted content of one file. Offset values used by later
+// stages refer to the string returned by Text.
+type Document struct {
+	Format Format  `json:"format"`
+	Blocks []Block `json:"blocks"`
+}
+
+// Text joins the blocks with blank lines.
+func (d *Document) Text() string {
+	var b strings.Builder
+	for i, block := range d.Blocks {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(block.Text)
+	}
+	return b.String()
+}
+
+// Extract parses data in the given format.
+func Extract(format Format, data []byte) (*Document, error) {
+	var (
+		blocks []Block
+		err    error
+	)
+
+	switch format {
+	case FormatPDF:
+		blocks, err = extractPDF(data)
+	case FormatDOCX:
+		blocks, err = extractDOCX(data)
+	case FormatHTML:
+		blocks, err = extractHTML(data)
+	case FormatMarkdown:
+		blocks = extractMarkdown(decodeText(data))
+	case FormatText:
+		blocks = extractText(decodeText(data))
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Document{Format: format, Blocks: compact(blocks)}, nil
+}
+
+var extensionFormats = map[string]Format{
+	".pdf":      FormatPDF,
+	".docx":     FormatDOCX,
+	".html":     FormatHTML,
+	".htm":      FormatHTML,
+	".xhtml":    FormatHTML,
+	".md":       FormatMarkdown,
+	".markdown": FormatMarkdown,
+	".txt":      FormatText,
+	".text":     FormatText,
+	".csv":      FormatText,
+	".log":      FormatText,
+}
+
+var mediaTypeFormats = map[string]Format{
+	"application/pdf": FormatPDF,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": FormatDOCX,
+	"text/html":             FormatHTML,
+	"application/xhtml+xml": FormatHTML,
+	"text/markdown":         FormatMarkdown,
+	"text/x-markdown":       FormatMarkdown,
+	"text/plain":            FormatText,
+}
+
+// Detect picks the format from the content type, then the file extension and
+// finally the leading bytes of data. It returns an empty format when none of
+// them is recognised.
+func Detect(contentType, name string, data []byte) Format {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if format, ok := mediaTypeFormats[mediaType]; ok {
+			return format
+		}
+	}
+
+	if format, ok := extensionFormats[strings.ToLower(path.Ext(name))]; ok {
+		return format
+	}
+
+	head := data
+	if len(head) > 512 {
+		head = head[:512]
+	}
+	lower := bytes.ToLower(bytes.TrimSpace(head))
+
+	switch {
+	case bytes.HasPrefix(head, []byte("%PDF-")):
+		return FormatPDF
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")) && bytes.Contains(data, []byte("word/document.xml")):
+		return FormatDOCX
+	case bytes.HasPrefix(lower, []byte("<!doctype html")) || bytes.HasPrefix(lower, []byte("<html")):
+		return FormatHTML
+	case utf8.Valid(head) && !bytes.ContainsRune(head, 0):
+		return FormatText
+	}
+
+	return ""
+}
+
+// decodeText drops a byte order mark, normalises line endings and replaces
+// invalid UTF-8.
+func decodeText(data []byte) string {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	text := strings.ToValidUTF8(string(data), "�")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
+}
+
+// collapseSpace replaces every run of whitespace with one space.
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func tableBlock(rows [][]string) Block {
+	lines := make([]string, len(rows))
+	for i, row := range rows {
+		lines[i] = strings.Join(row, "\t")
+	}
+	return Block{Type: BlockTable, Text: strings.Join(lines, "\n"), Rows: rows}
+}
+
+// compact drops blocks without text.
+func compact(blocks []Block) []Block {
+	result := make([]Block, 0, len(blocks))
+	for _, block := range blocks {
+		if strings.TrimSpace(block.Text) != "" {
+			result = append(result, block)
+		}
+	}
+	return result
+}
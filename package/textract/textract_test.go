@@ -0,0 +1,253 @@
+package textract
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildPDF writes a single-page PDF around the given content stream. Extra
+// objects start at number 5.
+func buildPDF(t *testing.T, content []byte, compress bool, font string, extra ...string) []byte {
+	t.Helper()
+
+	filter := ""
+	if compress {
+		var buf bytes.Buffer
+		w := zlib.NewWriter(&buf)
+		_, err := w.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		content = buf.Bytes()
+		filter = " /Filter /FlateDecode"
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 /Resources << /Font << /F1 4 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 99 0 R >>",
+		font,
+	}
+	objects = append(objects, extra...)
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	for i, object := range objects {
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	fmt.Fprintf(&buf, "99 0 obj\n<< /Length %d%s >>\nstream\n", len(content), filter)
+	buf.Write(content)
+	buf.WriteString("\nendstream\nendobj\ntrailer\n<< /Root 1 0 R >>\n%%EOF\n")
+
+	return buf.Bytes()
+}
+
+func TestExtract_PDF(t *testing.T) {
+	content := []byte(`BT
+/F1 24 Tf 72 700 Td (Annual Report) Tj
+/F1 12 Tf 0 -40 Td [(The company grew) -250 (quickly.)] TJ
+0 -14 Td (Revenue doubled in the \(third\) quar-) Tj
+0 -14 Td (ter.) Tj
+0 -60 Td (Outlook) '
+ET`)
+
+	data := buildPDF(t, content, true, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>")
+
+	document, err := Extract(FormatPDF, data)
+	require.NoError(t, err)
+
+	require.Len(t, document.Blocks, 3)
+	assert.Equal(t, Block{Type: BlockHeading, Level: 1, Text: "Annual Report"}, document.Blocks[0])
+	assert.Equal(t, Block{Type: BlockParagraph, Text: "The company grew quickly. Revenue doubled in the (third) quarter."}, document.Blocks[1])
+	assert.Equal(t, "Outlook", document.Blocks[2].Text)
+}
+
+func TestExtract_PDF_ToUnicode(t *testing.T) {
+	cmap := `/CIDInit /ProcSet findresource begin
+begincmap
+1 begincodespacerange <0000> <FFFF> endcodespacerange
+2 beginbfchar
+<0001> <0048>
+<0002> <0069>
+endbfchar
+1 beginbfrange
+<0010> <0012> <0061>
+endbfrange
+endcmap`
+	content := []byte("BT /F1 12 Tf 72 700 Td [<00010002> -300 <001000110012>] TJ ET")
+
+	data := buildPDF(t, content, false,
+		"<< /Type /Font /Subtype /Type0 /BaseFont /Custom /Encoding /Identity-H /ToUnicode 5 0 R >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(cmap), cmap),
+	)
+
+	document, err := Extract(FormatPDF, data)
+	require.NoError(t, err)
+
+	require.Len(t, document.Blocks, 1)
+	assert.Equal(t, "Hi abc", document.Blocks[0].Text)
+}
+
+func TestExtract_PDF_Invalid(t *testing.T) {
+	_, err := Extract(FormatPDF, []byte("not a pdf"))
+	assert.EqualError(t, err, "failed to parse PDF: missing %PDF header")
+
+	encrypted := []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\ntrailer\n<< /Root 1 0 R /Encrypt 2 0 R >>\n")
+	_, err = Extract(FormatPDF, encrypted)
+	assert.EqualError(t, err, "failed to parse PDF: encrypted files are not supported")
+}
+
+func TestExtract_PDF_Truncated(t *testing.T) {
+	for _, data := range []string{
+		"%PDF-0 0 obj<<<",
+		"%PDF-1 0 obj<</A 2 0",
+		"%PDF-1 0 obj<</Contents 2 0 R>>stream",
+		"%PDF-1 0 obj<</Type/Page/Contents 2 0 R>>2 0 obj<<>>stream\nBI ID",
+	} {
+		assert.NotPanics(t, func() { Extract(FormatPDF, []byte(data)) }, data)
+	}
+}
+
+func FuzzExtract_PDF(f *testing.F) {
+	f.Add([]byte("%PDF-0 0 obj<<<"))
+	f.Add([]byte("%PDF-1.4\n1 0 obj\n<< /Type /Page /Contents 2 0 R >>\nendobj\n2 0 obj\n<< /Length 5 >>\nstream\nBT ET\nendstream\nendobj\n"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		Extract(FormatPDF, data)
+	})
+}
+
+func buildDOCX(t *testing.T, body string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	w, err := archive.Create("word/document.xml")
+	require.NoError(t, err)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>%s</w:body></w:document>`, body)
+	require.NoError(t, archive.Close())
+
+	return buf.Bytes()
+}
+
+func TestExtract_DOCX(t *testing.T) {
+	data := buildDOCX(t, `
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Findings</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Alice works </w:t></w:r><w:r><w:t>at Acme.</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/></w:numPr></w:pPr><w:r><w:t>First point</w:t></w:r></w:p>
+<w:tbl>
+  <w:tr><w:tc><w:p><w:r><w:t>Name</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Role</w:t></w:r></w:p></w:tc></w:tr>
+  <w:tr><w:tc><w:p><w:r><w:t>Alice</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Engineer</w:t></w:r></w:p></w:tc></w:tr>
+</w:tbl>`)
+
+	document, err := Extract(FormatDOCX, data)
+	require.NoError(t, err)
+
+	require.Len(t, document.Blocks, 4)
+	assert.Equal(t, Block{Type: BlockHeading, Level: 1, Text: "Findings"}, document.Blocks[0])
+	assert.Equal(t, Block{Type: BlockParagraph, Text: "Alice works at Acme."}, document.Blocks[1])
+	assert.Equal(t, Block{Type: BlockListItem, Text: "First point"}, document.Blocks[2])
+	assert.Equal(t, [][]string{{"Name", "Role"}, {"Alice", "Engineer"}}, document.Blocks[3].Rows)
+	assert.Equal(t, "Name\tRole\nAlice\tEngineer", document.Blocks[3].Text)
+}
+
+func TestExtract_DOCX_MissingDocument(t *testing.T) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	_, err := archive.Create("other.xml")
+	require.NoError(t, err)
+	require.NoError(t, archive.Close())
+
+	_, err = Extract(FormatDOCX, buf.Bytes())
+	assert.EqualError(t, err, "failed to read DOCX: word/document.xml is missing")
+}
+
+func TestExtract_HTML(t *testing.T) {
+	data := []byte(`<!doctype html><html><head><title>Ignored</title><style>p{}</style></head><body>
+<h2>Team <em>overview</em></h2>
+<p>Alice   leads
+the <a href="/x">platform</a> team.</p>
+<script>var ignored = 1;</script>
+<ul><li>Bob</li><li>Carol <b>(lead)</b></li></ul>
+<table><thead><tr><th>Name</th><th>Team</th></tr></thead><tbody><tr><td>Bob</td><td>Data</td></tr></tbody></table>
+<div>Loose text</div>
+</body></html>`)
+
+	document, err := Extract(FormatHTML, data)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Block{
+		{Type: BlockHeading, Level: 2, Text: "Team overview"},
+		{Type: BlockParagraph, Text: "Alice leads the platform team."},
+		{Type: BlockListItem, Text: "Bob"},
+		{Type: BlockListItem, Text: "Carol (lead)"},
+		{Type: BlockTable, Text: "Name\tTeam\nBob\tData", Rows: [][]string{{"Name", "Team"}, {"Bob", "Data"}}},
+		{Type: BlockParagraph, Text: "Loose text"},
+	}, document.Blocks)
+}
+
+func TestExtract_Markdown(t *testing.T) {
+	data := []byte("# Project **Atlas**\n\nAtlas is run by [Alice](https://example.com)\nand Bob.\n\nMilestones\n----------\n\n- Design\n1. Build\n\n| Name | Owner |\n|------|:-----:|\n| API  | Bob   |\n\n```go\nfunc main() {}\n```\n")
+
+	document, err := Extract(FormatMarkdown, data)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Block{
+		{Type: BlockHeading, Level: 1, Text: "Project Atlas"},
+		{Type: BlockParagraph, Text: "Atlas is run by Alice and Bob."},
+		{Type: BlockHeading, Level: 2, Text: "Milestones"},
+		{Type: BlockListItem, Text: "Design"},
+		{Type: BlockListItem, Text: "Build"},
+		{Type: BlockTable, Text: "Name\tOwner\nAPI\tBob", Rows: [][]string{{"Name", "Owner"}, {"API", "Bob"}}},
+		{Type: BlockParagraph, Text: "func main() {}"},
+	}, document.Blocks)
+}
+
+func TestExtract_Text(t *testing.T) {
+	data := []byte("\xef\xbb\xbfFirst line\r\nsecond line\r\n\r\n\r\nNext paragraph\n")
+
+	document, err := Extract(FormatText, data)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Block{
+		{Type: BlockParagraph, Text: "First line\nsecond line"},
+		{Type: BlockParagraph, Text: "Next paragraph"},
+	}, document.Blocks)
+	assert.Equal(t, "First line\nsecond line\n\nNext paragraph", document.Text())
+}
+
+func TestExtract_Unsupported(t *testing.T) {
+	_, err := Extract(Format("xlsx"), []byte("data"))
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		fileName    string
+		data        []byte
+		expected    Format
+	}{
+		{"content type", "application/pdf", "file", nil, FormatPDF},
+		{"content type with parameters", "text/html; charset=utf-8", "file", nil, FormatHTML},
+		{"extension", "application/octet-stream", "Notes.MD", nil, FormatMarkdown},
+		{"docx extension", "", "report.docx", nil, FormatDOCX},
+		{"pdf signature", "application/octet-stream", "upload", []byte("%PDF-1.7\n"), FormatPDF},
+		{"html signature", "", "upload", []byte("  <!DOCTYPE html><html>"), FormatHTML},
+		{"utf-8 text", "", "upload", []byte("plain words"), FormatText},
+		{"binary", "", "upload", []byte{0x00, 0x01, 0x02}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Detect(tt.contentType, tt.fileName, tt.data))
+		})
+	}
+}